@@ -0,0 +1,317 @@
+// Package calendar copies coursework due dates into Google Calendar. Each
+// piece of coursework becomes one event with an ID derived from it, so
+// syncing again updates events in place instead of adding duplicates.
+package calendar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// DefaultName is the summary of the dedicated calendar created for due
+// dates.
+const DefaultName = "Classroom due dates"
+
+// Private extended properties that tie an event to its coursework.
+const (
+	propCourseID     = "classroomCourseId"
+	propCourseWorkID = "classroomCourseWorkId"
+)
+
+// Configuration holds Calendar client settings.
+type Configuration struct {
+	// HTTPClient makes authenticated requests, usually
+	// (*api.Client).HTTPClient().
+	HTTPClient *http.Client
+	// Endpoint overrides the Calendar API base URL, for tests.
+	Endpoint string
+}
+
+// Client writes due dates to Google Calendar.
+type Client struct {
+	service *calendar.Service
+}
+
+// New creates a Calendar client.
+func New(ctx context.Context, cfg *Configuration) (*Client, error) {
+	if cfg == nil || cfg.HTTPClient == nil {
+		return nil, errors.New("calendar client needs an authenticated HTTP client")
+	}
+	opts := []option.ClientOption{option.WithHTTPClient(cfg.HTTPClient)}
+	if cfg.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+	}
+	service, err := calendar.NewService(ctx, opts...)
+	if err != nil {
+		return nil, api.WrapError(err, "failed to create calendar service")
+	}
+	return &Client{service: service}, nil
+}
+
+// Dedicated returns the ID of the user's calendar called name, creating it
+// if it does not exist.
+func (c *Client) Dedicated(ctx context.Context, name string) (string, error) {
+	id, err := c.Find(ctx, name)
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	created, err := c.service.Calendars.Insert(&calendar.Calendar{
+		Summary:     name,
+		Description: "Coursework due dates from Google Classroom",
+	}).Context(ctx).Do()
+	if err != nil {
+		return "", api.WrapError(err, fmt.Sprintf("failed to create calendar %q", name))
+	}
+	return created.Id, nil
+}
+
+// Find returns the ID of the user's own calendar called name, or "" when
+// there is none.
+func (c *Client) Find(ctx context.Context, name string) (string, error) {
+	var id string
+	err := c.service.CalendarList.List().MinAccessRole("owner").Context(ctx).Pages(ctx, func(page *calendar.CalendarList) error {
+		for _, entry := range page.Items {
+			if entry.Summary == name {
+				id = entry.Id
+				return errStop
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		return "", api.WrapError(err, "failed to list calendars")
+	}
+	return id, nil
+}
+
+// errStop ends a page iteration early.
+var errStop = errors.New("stop")
+
+// Target selects the calendar due dates are synced to.
+type Target struct {
+	// ID is a calendar ID to use as is.
+	ID string
+	// Course uses the course's own calendar. Usually only teachers can
+	// add events to it.
+	Course bool
+	// Name is the dedicated calendar used when neither ID nor Course is
+	// set. Empty means DefaultName.
+	Name string
+}
+
+// Resolve returns the calendar ID for t. A dedicated calendar that does not
+// exist yet is created when create is set, and is "" otherwise.
+func (c *Client) Resolve(ctx context.Context, src Source, courseID string, t Target, create bool) (string, error) {
+	switch {
+	case t.ID != "":
+		return t.ID, nil
+	case t.Course:
+		course, err := src.GetCourse(ctx, courseID)
+		if err != nil {
+			return "", err
+		}
+		if course.CalendarID == "" {
+			return "", fmt.Errorf("course %q has no calendar", course.Name)
+		}
+		return course.CalendarID, nil
+	}
+
+	name := t.Name
+	if name == "" {
+		name = DefaultName
+	}
+	if !create {
+		return c.Find(ctx, name)
+	}
+	return c.Dedicated(ctx, name)
+}
+
+// Source provides the coursework to sync. api.ClassroomClient satisfies it.
+type Source interface {
+	GetCourse(ctx context.Context, courseID string) (*api.Course, error)
+	ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error)
+}
+
+// Result counts what a sync changed.
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	// Deleted counts events whose coursework was deleted or lost its due
+	// date.
+	Deleted int
+}
+
+// String summarizes the result, e.g. "3 created, 1 updated, 12 unchanged".
+func (r *Result) String() string {
+	s := fmt.Sprintf("%d created, %d updated, %d unchanged", r.Created, r.Updated, r.Unchanged)
+	if r.Deleted > 0 {
+		s += fmt.Sprintf(", %d removed", r.Deleted)
+	}
+	return s
+}
+
+// Sync makes calendarID hold one event per piece of the course's coursework
+// that has a due date. Events from earlier syncs are updated in place, and
+// events for coursework that no longer has a due date are removed. With
+// dryRun nothing is written, but the result says what would change; an
+// empty calendarID then stands for a calendar that does not exist yet.
+func (c *Client) Sync(ctx context.Context, src Source, courseID, calendarID string, dryRun bool) (*Result, error) {
+	course, err := src.GetCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	coursework, err := src.ListCourseWork(ctx, courseID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*calendar.Event)
+	if calendarID != "" {
+		if err := c.listEvents(ctx, calendarID, courseID, existing); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{}
+	for _, cw := range coursework {
+		want := Event(course, cw)
+		if want == nil {
+			continue
+		}
+		have, ok := existing[want.Id]
+		delete(existing, want.Id)
+		switch {
+		case ok && have.Status != "cancelled" && same(have, want):
+			result.Unchanged++
+		case ok:
+			result.Updated++
+			if !dryRun {
+				if _, err := c.service.Events.Update(calendarID, want.Id, want).Context(ctx).Do(); err != nil {
+					return result, api.WrapError(err, fmt.Sprintf("failed to update event for %q", cw.Title))
+				}
+			}
+		default:
+			result.Created++
+			if !dryRun {
+				if _, err := c.service.Events.Insert(calendarID, want).Context(ctx).Do(); err != nil {
+					return result, api.WrapError(err, fmt.Sprintf("failed to create event for %q", cw.Title))
+				}
+			}
+		}
+	}
+
+	for id, e := range existing {
+		if e.Status == "cancelled" {
+			continue
+		}
+		result.Deleted++
+		if dryRun {
+			continue
+		}
+		err := c.service.Events.Delete(calendarID, id).Context(ctx).Do()
+		if err != nil && !isGone(err) {
+			return result, api.WrapError(err, fmt.Sprintf("failed to remove event %q", e.Summary))
+		}
+	}
+	return result, nil
+}
+
+// listEvents adds the course's synced events in calendarID to events.
+// Cancelled events are included: an event the user deleted keeps its ID,
+// so it has to be revived with an update rather than inserted.
+func (c *Client) listEvents(ctx context.Context, calendarID, courseID string, events map[string]*calendar.Event) error {
+	err := c.service.Events.List(calendarID).
+		PrivateExtendedProperty(propCourseID+"="+courseID).
+		ShowDeleted(true).
+		Context(ctx).
+		Pages(ctx, func(page *calendar.Events) error {
+			for _, e := range page.Items {
+				events[e.Id] = e
+			}
+			return nil
+		})
+	if err != nil {
+		return api.WrapError(err, "failed to list calendar events")
+	}
+	return nil
+}
+
+// Event returns the calendar event for coursework, or nil when it has no
+// due date or is not published. Timed due dates become zero-length events
+// at the due time; dates without a time become all-day events.
+func Event(course *api.Course, cw *api.CourseWork) *calendar.Event {
+	due, allDay, ok := cw.Due()
+	if !ok || (cw.State != "" && cw.State != api.CourseWorkStatePublished) {
+		return nil
+	}
+
+	e := &calendar.Event{
+		Id:           EventID(cw.CourseID, cw.ID),
+		Summary:      fmt.Sprintf("%s: %s", course.Name, cw.Title),
+		Description:  cw.Description,
+		Status:       "confirmed",
+		Transparency: "transparent",
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{
+				propCourseID:     cw.CourseID,
+				propCourseWorkID: cw.ID,
+			},
+		},
+	}
+	if allDay {
+		e.Start = &calendar.EventDateTime{Date: due.Format("2006-01-02")}
+		e.End = &calendar.EventDateTime{Date: due.AddDate(0, 0, 1).Format("2006-01-02")}
+	} else {
+		at := due.Format(time.RFC3339)
+		e.Start = &calendar.EventDateTime{DateTime: at, TimeZone: "UTC"}
+		e.End = &calendar.EventDateTime{DateTime: at, TimeZone: "UTC"}
+	}
+	return e
+}
+
+// EventID returns the event ID for coursework. Calendar IDs may only use
+// the characters 0-9 and a-v, which hex digits satisfy.
+func EventID(courseID, courseWorkID string) string {
+	sum := sha256.Sum256([]byte(courseID + "/" + courseWorkID))
+	return "classroom" + hex.EncodeToString(sum[:16])
+}
+
+// same reports whether an existing event already matches the wanted one.
+func same(have, want *calendar.Event) bool {
+	return have.Summary == want.Summary &&
+		have.Description == want.Description &&
+		sameTime(have.Start, want.Start) &&
+		sameTime(have.End, want.End)
+}
+
+// sameTime compares event times by instant, since Calendar may return a
+// timed event in the calendar's own time zone.
+func sameTime(a, b *calendar.EventDateTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Date != "" || b.Date != "" {
+		return a.Date == b.Date
+	}
+	ta, errA := time.Parse(time.RFC3339, a.DateTime)
+	tb, errB := time.Parse(time.RFC3339, b.DateTime)
+	return errA == nil && errB == nil && ta.Equal(tb)
+}
+
+// isGone reports whether err means the event was already deleted.
+func isGone(err error) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && (gerr.Code == http.StatusGone || gerr.Code == http.StatusNotFound)
+}
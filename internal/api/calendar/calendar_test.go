@@ -0,0 +1,208 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+	"google.golang.org/api/calendar/v3"
+)
+
+// calendarServer is an in-memory Calendar API with one calendar list and
+// events per calendar.
+type calendarServer struct {
+	mu        sync.Mutex
+	calendars map[string]string // ID to summary
+	events    map[string]map[string]*calendar.Event
+	writes    int
+}
+
+func newCalendarClient(t *testing.T) (*Client, *calendarServer) {
+	t.Helper()
+	s := &calendarServer{
+		calendars: map[string]string{"primary": "me@example.com"},
+		events:    make(map[string]map[string]*calendar.Event),
+	}
+	server := httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(server.Close)
+
+	client, err := New(context.Background(), &Configuration{HTTPClient: server.Client(), Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client, s
+}
+
+func (s *calendarServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.URL.Path == "/users/me/calendarList":
+		list := &calendar.CalendarList{}
+		for id, summary := range s.calendars {
+			list.Items = append(list.Items, &calendar.CalendarListEntry{Id: id, Summary: summary})
+		}
+		json.NewEncoder(w).Encode(list)
+	case r.URL.Path == "/calendars" && r.Method == http.MethodPost:
+		var cal calendar.Calendar
+		json.NewDecoder(r.Body).Decode(&cal)
+		cal.Id = "cal" + string(rune('0'+len(s.calendars)))
+		s.calendars[cal.Id] = cal.Summary
+		json.NewEncoder(w).Encode(&cal)
+	case len(parts) == 3 && parts[2] == "events" && r.Method == http.MethodGet:
+		filter := r.URL.Query().Get("privateExtendedProperty")
+		list := &calendar.Events{}
+		for _, e := range s.events[parts[1]] {
+			k, v, _ := strings.Cut(filter, "=")
+			if e.ExtendedProperties.Private[k] == v {
+				list.Items = append(list.Items, e)
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	case len(parts) == 3 && parts[2] == "events" && r.Method == http.MethodPost:
+		var e calendar.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		if _, ok := s.events[parts[1]][e.Id]; ok {
+			http.Error(w, `{"error": {"code": 409, "message": "duplicate"}}`, http.StatusConflict)
+			return
+		}
+		s.put(parts[1], &e)
+		json.NewEncoder(w).Encode(&e)
+	case len(parts) == 4 && r.Method == http.MethodPut:
+		var e calendar.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		s.put(parts[1], &e)
+		json.NewEncoder(w).Encode(&e)
+	case len(parts) == 4 && r.Method == http.MethodDelete:
+		s.writes++
+		s.events[parts[1]][parts[3]].Status = "cancelled"
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *calendarServer) put(calendarID string, e *calendar.Event) {
+	s.writes++
+	if s.events[calendarID] == nil {
+		s.events[calendarID] = make(map[string]*calendar.Event)
+	}
+	s.events[calendarID][e.Id] = e
+}
+
+// active returns the calendar's events that are not cancelled.
+func (s *calendarServer) active(calendarID string) []*calendar.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*calendar.Event
+	for _, e := range s.events[calendarID] {
+		if e.Status != "cancelled" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// TestSync tests that repeated syncs update events in place and remove
+// events for coursework without a due date.
+func TestSync(t *testing.T) {
+	client, server := newCalendarClient(t)
+	src := fake.New("u1")
+	ctx := context.Background()
+	course := src.AddCourse(&api.Course{Name: "Biology"})
+	essay := src.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Essay", State: api.CourseWorkStatePublished, DueDate: "2026-03-02", DueTime: "23:59"})
+	src.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Lab", State: api.CourseWorkStatePublished, DueDate: "2026-03-05"})
+	src.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Reading", State: api.CourseWorkStatePublished})
+
+	result, err := client.Sync(ctx, src, course.ID, "primary", false)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Created != 2 || result.Unchanged != 0 {
+		t.Errorf("Expected 2 created, got %s", result)
+	}
+
+	writes := server.writes
+	result, err = client.Sync(ctx, src, course.ID, "primary", false)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.Unchanged != 2 || server.writes != writes {
+		t.Errorf("Expected an unchanged second sync, got %s with %d writes", result, server.writes-writes)
+	}
+	if n := len(server.active("primary")); n != 2 {
+		t.Errorf("Expected 2 events without duplicates, got %d", n)
+	}
+
+	if err := src.DeleteCourseWork(ctx, course.ID, essay.ID); err != nil {
+		t.Fatalf("DeleteCourseWork failed: %v", err)
+	}
+	result, err = client.Sync(ctx, src, course.ID, "primary", false)
+	if err != nil {
+		t.Fatalf("Third sync failed: %v", err)
+	}
+	if result.Deleted != 1 || len(server.active("primary")) != 1 {
+		t.Errorf("Expected the essay's event removed, got %s", result)
+	}
+}
+
+// TestEvent tests timed and all-day events.
+func TestEvent(t *testing.T) {
+	course := &api.Course{Name: "Biology"}
+	timed := Event(course, &api.CourseWork{ID: "cw1", CourseID: "c1", Title: "Essay", DueDate: "2026-03-02", DueTime: "23:59"})
+	if timed.Start.DateTime != "2026-03-02T23:59:00Z" || timed.Summary != "Biology: Essay" {
+		t.Errorf("Expected a timed event at 23:59 UTC, got %s %q", timed.Start.DateTime, timed.Summary)
+	}
+	allDay := Event(course, &api.CourseWork{ID: "cw2", CourseID: "c1", DueDate: "2026-03-31"})
+	if allDay.Start.Date != "2026-03-31" || allDay.End.Date != "2026-04-01" {
+		t.Errorf("Expected an all-day event on 2026-03-31, got %s to %s", allDay.Start.Date, allDay.End.Date)
+	}
+	if Event(course, &api.CourseWork{ID: "cw3"}) != nil {
+		t.Error("Expected no event without a due date")
+	}
+	if timed.Id == allDay.Id || strings.Trim(timed.Id, "0123456789abcdefghijklmnopqrstuv") != "" {
+		t.Errorf("Expected distinct base32hex IDs, got %s and %s", timed.Id, allDay.Id)
+	}
+}
+
+// TestRunSync tests syncing to a dedicated calendar created on first use.
+func TestRunSync(t *testing.T) {
+	client, server := newCalendarClient(t)
+	src := fake.New("u1")
+	course := src.AddCourse(&api.Course{Name: "Biology"})
+	src.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Essay", State: api.CourseWorkStatePublished, DueDate: "2026-03-02"})
+	var stdout, stderr bytes.Buffer
+
+	if err := RunSync(context.Background(), src, client, []string{course.ID, "--dry-run"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(server.calendars) != 1 || !strings.Contains(stdout.String(), "1 created") {
+		t.Errorf("Expected a dry run to create nothing and report 1 event, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := RunSync(context.Background(), src, client, []string{course.ID}, &stdout, &stderr); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	id, err := client.Find(context.Background(), DefaultName)
+	if err != nil || id == "" {
+		t.Fatalf("Expected the dedicated calendar to exist, got %q (%v)", id, err)
+	}
+	if len(server.active(id)) != 1 {
+		t.Errorf("Expected 1 event in the dedicated calendar, got %d", len(server.active(id)))
+	}
+
+	if err := RunSync(context.Background(), src, client, []string{course.ID, "--course-calendar"}, &stdout, &stderr); err == nil {
+		t.Error("Expected an error for a course without a calendar")
+	}
+}
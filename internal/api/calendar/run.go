@@ -0,0 +1,50 @@
+package calendar
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/user/google-classroom/internal/cli"
+)
+
+// RunSync implements `classroom calendar sync <course> [--calendar id |
+// --course-calendar] [--name name] [--dry-run]`. Without --calendar or
+// --course-calendar, due dates go to a dedicated calendar that is created
+// on the first sync.
+func RunSync(ctx context.Context, src Source, c *Client, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("calendar sync", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	calendarID := fs.String("calendar", "", "sync to the calendar with this `id`")
+	courseCalendar := fs.Bool("course-calendar", false, "sync to the course's own calendar")
+	name := fs.String("name", DefaultName, "`name` of the dedicated calendar")
+	dryRun := fs.Bool("dry-run", false, "show what would change without writing")
+
+	courseID, err := cli.ParseCourseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if courseID == "" {
+		return fmt.Errorf("usage: calendar sync <course> [--calendar id | --course-calendar] [--name name] [--dry-run]")
+	}
+	if *calendarID != "" && *courseCalendar {
+		return fmt.Errorf("use either --calendar or --course-calendar, not both")
+	}
+
+	target, err := c.Resolve(ctx, src, courseID, Target{ID: *calendarID, Name: *name, Course: *courseCalendar}, !*dryRun)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.Sync(ctx, src, courseID, target, *dryRun)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would sync: %s\n", result)
+		return nil
+	}
+	fmt.Fprintf(stdout, "Synced due dates: %s\n", result)
+	return nil
+}
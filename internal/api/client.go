@@ -3,14 +3,22 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/user/google-classroom/internal/backoff"
+	"github.com/user/google-classroom/internal/ratelimit"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/classroom/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -18,43 +26,330 @@ import (
 type Client struct {
 	service    *classroom.Service
 	httpClient *http.Client
+	transport  http.RoundTripper
+	retry      *backoff.Policy
+	// writeRetry is retry narrowed for calls that change something.
+	writeRetry *backoff.Policy
+	limiter    *ratelimit.Limiter
+	metrics    *metrics
+	// recorder records fixtures when Configuration.RecordFixtures is set.
+	recorder *Recorder
+
+	maxConcurrency int
+	pageSize       int
+	pageSizes      map[string]int
 }
 
 // Configuration holds API client configuration.
 type Configuration struct {
 	RateLimitBackoff time.Duration
 	MaxRetries       int
+
+	// Retry is the shared retry policy. When nil, one is derived from
+	// RateLimitBackoff and MaxRetries.
+	Retry *backoff.Policy
+
+	// Endpoint overrides the Classroom API base URL, such as
+	// "http://localhost:8080/" for a local fake server.
+	Endpoint string
+	// Timeout bounds each Classroom request, including reading the
+	// response. Zero means no limit.
+	Timeout time.Duration
+	// Proxy is the URL of the HTTP proxy to use. When empty, the
+	// HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables apply.
+	Proxy string
+
+	// Transport tuning. Zero values fall back to the defaults.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+	DisableCompression  bool
+	DisableHTTP2        bool
+	// DialTimeout, TLSHandshakeTimeout, and ResponseHeaderTimeout bound
+	// the stages of a connection. Zero keeps the defaults.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// Transport is the shared base transport. When nil, one is built from
+	// the tuning fields and Proxy, and only the API client uses it; build
+	// one with NewTransport instead to share it with the OAuth requests.
+	Transport http.RoundTripper
+
+	// MaxConcurrency bounds how many per-course requests run at once.
+	MaxConcurrency int
+
+	// Middleware wraps every request, outermost first. It also sees OAuth
+	// token refreshes.
+	Middleware []Middleware
+	// DebugLog, when set, appends a line per request to this file.
+	DebugLog string
+
+	// DisableETags turns off conditional reads. Otherwise unchanged list
+	// and get responses are revalidated with If-None-Match and reused on
+	// 304 Not Modified.
+	DisableETags bool
+
+	// RecordFixtures, when set, records every exchange to this fixture
+	// file for replay in tests. The file is written by Client.Close. See
+	// Recorder.
+	RecordFixtures string
+
+	// QPS, Burst, and QuotaPerMinute throttle requests on the client side
+	// so bulk loads stay under the Classroom quota. Zero disables a limit.
+	QPS            float64
+	Burst          int
+	QuotaPerMinute int
+
+	// PageSize is how many items list calls ask for per page. Zero leaves
+	// it to the server. PageSizes overrides it per list, keyed by the
+	// Page* constants.
+	PageSize  int
+	PageSizes map[string]int
+}
+
+// Lists whose page size can be set in Configuration.PageSizes.
+const (
+	PageCourses       = "courses"
+	PageCourseWork    = "coursework"
+	PageSubmissions   = "submissions"
+	PageAnnouncements = "announcements"
+	PageStudents      = "students"
+	PageTeachers      = "teachers"
+	PageInvitations   = "invitations"
+	// PageAddOnAttachments is capped at 20 by the API.
+	PageAddOnAttachments = "addons"
+)
+
+// PageLists returns the keys accepted in Configuration.PageSizes.
+func PageLists() []string {
+	return []string{PageCourses, PageCourseWork, PageSubmissions, PageAnnouncements, PageStudents, PageTeachers, PageInvitations, PageAddOnAttachments}
 }
 
 // DefaultConfiguration returns the default client configuration.
 func DefaultConfiguration() *Configuration {
 	return &Configuration{
-		RateLimitBackoff: 1 * time.Second,
-		MaxRetries:       3,
+		RateLimitBackoff:    1 * time.Second,
+		MaxRetries:          3,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+		MaxConcurrency:      4,
+		QPS:                 10,
+		Burst:               10,
+		QuotaPerMinute:      1000,
 	}
 }
 
+// NewTransport builds a tuned HTTP transport suitable for sharing between the
+// OAuth, Classroom, and Drive clients so bulk operations reuse connections.
+// Build it once, pass it to auth.Authenticator.SetHTTPClient in an
+// http.Client for token refreshes, and set it as Configuration.Transport.
+func NewTransport(cfg *Configuration) *http.Transport {
+	defaults := DefaultConfiguration()
+	if cfg == nil {
+		cfg = defaults
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = defaults.MaxIdleConns
+	if cfg.MaxIdleConns > 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	t.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	t.IdleConnTimeout = defaults.IdleConnTimeout
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	t.DisableKeepAlives = cfg.DisableKeepAlives
+	t.DisableCompression = cfg.DisableCompression
+	t.ForceAttemptHTTP2 = !cfg.DisableHTTP2
+	if cfg.DisableHTTP2 {
+		// A non-nil, empty map disables the automatic HTTP/2 upgrade
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if cfg.DialTimeout > 0 {
+		t.DialContext = (&net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.Proxy != "" {
+		proxy, err := parseProxy(cfg.Proxy)
+		if err != nil {
+			// Fail every request rather than silently bypassing the proxy
+			t.Proxy = func(*http.Request) (*url.URL, error) { return nil, err }
+		} else {
+			t.Proxy = http.ProxyURL(proxy)
+		}
+	}
+
+	return t
+}
+
+// parseProxy parses a proxy URL. A bare "host:port" is taken as an HTTP
+// proxy.
+func parseProxy(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// WithTransport returns a context whose oauth2 clients send requests over
+// rt. NewClient uses it for API requests; token refreshes only use the
+// transport when it is also given to auth.Authenticator.SetHTTPClient.
+func WithTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: rt})
+}
+
 // NewClient creates a new Google Classroom API client.
 func NewClient(ctx context.Context, ts oauth2.TokenSource, cfg *Configuration) (*Client, error) {
 	if cfg == nil {
 		cfg = DefaultConfiguration()
 	}
 
-	// Create HTTP client with OAuth token source
-	httpClient := oauth2.NewClient(ctx, ts)
+	transport := cfg.Transport
+	if transport == nil {
+		if cfg.Proxy != "" {
+			if _, err := parseProxy(cfg.Proxy); err != nil {
+				return nil, err
+			}
+		}
+		transport = NewTransport(cfg)
+	}
+
+	var middleware []Middleware
+	if !cfg.DisableETags {
+		// Outermost, so debug logging and custom middleware see the real 304
+		middleware = append(middleware, ETagCache(0))
+	}
+	middleware = append(middleware, cfg.Middleware...)
+	if cfg.DebugLog != "" {
+		f, err := openDebugLog(cfg.DebugLog)
+		if err != nil {
+			return nil, err
+		}
+		middleware = append(middleware, DebugLogging(f))
+	}
+	var recorder *Recorder
+	if cfg.RecordFixtures != "" {
+		// Innermost, so fixtures hold exactly what the server sent
+		recorder = NewRecorder(cfg.RecordFixtures)
+		middleware = append(middleware, recorder.Middleware())
+	}
+	rt := Chain(transport, middleware...)
+
+	// Create HTTP client with OAuth token source on top of the shared transport
+	httpClient := oauth2.NewClient(WithTransport(ctx, rt), ts)
+
+	// The timeout only applies to Classroom calls; Drive downloads through
+	// HTTPClient may take longer
+	serviceClient := httpClient
+	if cfg.Timeout > 0 {
+		serviceClient = &http.Client{Transport: httpClient.Transport, Timeout: cfg.Timeout}
+	}
+	opts := []option.ClientOption{option.WithHTTPClient(serviceClient)}
+	if cfg.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+	}
 
 	// Create Classroom service
-	service, err := classroom.NewService(ctx, option.WithHTTPClient(httpClient))
+	service, err := classroom.NewService(ctx, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create classroom service: %w", err)
+		return nil, wrapError(err, "failed to create classroom service")
 	}
 
+	retry := retryPolicy(cfg)
 	return &Client{
 		service:    service,
 		httpClient: httpClient,
+		transport:  transport,
+		retry:      retry,
+		writeRetry: writeRetryPolicy(retry),
+		limiter:    ratelimit.New(cfg.QPS, cfg.Burst, cfg.QuotaPerMinute),
+		metrics:    newMetrics(cfg.QuotaPerMinute),
+		recorder:   recorder,
+
+		maxConcurrency: cfg.MaxConcurrency,
+		pageSize:       cfg.PageSize,
+		pageSizes:      cfg.PageSizes,
 	}, nil
 }
 
+// pageSizeFor returns the page size for a list call: the request's own, the
+// list's configured one, or the client default, in that order. Zero leaves
+// it to the server.
+func (c *Client) pageSizeFor(list string, requested int) int64 {
+	if requested > 0 {
+		return int64(requested)
+	}
+	if n := c.pageSizes[list]; n > 0 {
+		return int64(n)
+	}
+	return int64(max(c.pageSize, 0))
+}
+
+// retryPolicy returns the configured retry policy, classifying errors with
+// the client's retryable rules unless the policy brings its own.
+func retryPolicy(cfg *Configuration) *backoff.Policy {
+	p := cfg.Retry
+	if p == nil {
+		p = backoff.Default()
+		if cfg.RateLimitBackoff > 0 {
+			p.Initial = cfg.RateLimitBackoff
+		}
+		if cfg.MaxRetries > 0 {
+			p.MaxAttempts = cfg.MaxRetries
+		}
+	}
+	if p.Retryable == nil {
+		p = p.WithRetryable(isRetryable)
+	}
+	if p.RetryAfter == nil {
+		p = p.WithRetryAfter(retryAfter)
+	}
+	return p
+}
+
+// HTTPClient returns the authenticated HTTP client, for other Google APIs
+// (such as Drive) that should share the same connections and credentials.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// Close writes the fixture recorded for Configuration.RecordFixtures, if
+// any. A failed write is returned for the caller to report; the requests
+// it recorded already succeeded.
+func (c *Client) Close() error {
+	if c.recorder == nil {
+		return nil
+	}
+	return c.recorder.Close()
+}
+
+// Transport returns the shared, unauthenticated base transport.
+func (c *Client) Transport() http.RoundTripper {
+	return c.transport
+}
+
 // Course represents a Google Classroom course.
 type Course struct {
 	ID             string `json:"id"`
@@ -67,6 +362,36 @@ type Course struct {
 	CourseState    string `json:"courseState"`
 	TimeCreated    string `json:"timeCreated"`
 	UpdateTime     string `json:"updateTime"`
+	// CalendarID is the course's Google Calendar, shared with its members.
+	CalendarID string `json:"calendarId,omitempty"`
+	// AlternateLink opens the course in the Classroom web UI.
+	AlternateLink string `json:"alternateLink,omitempty"`
+	// TeacherFolder is the course's Drive folder. The API only returns it
+	// to the course's teachers.
+	TeacherFolder *DriveFolder `json:"teacherFolder,omitempty"`
+}
+
+// DriveFolder is a Google Drive folder.
+type DriveFolder struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// AlternateLink opens the folder in Drive.
+	AlternateLink string `json:"alternateLink,omitempty"`
+}
+
+// Course states.
+const (
+	CourseStateActive      = "ACTIVE"
+	CourseStateArchived    = "ARCHIVED"
+	CourseStateProvisioned = "PROVISIONED"
+	CourseStateDeclined    = "DECLINED"
+)
+
+// CoursePatch describes changes to a course's details. Nil fields are left unchanged.
+type CoursePatch struct {
+	Name    *string
+	Section *string
+	Room    *string
 }
 
 // CourseWork represents an assignment or material in a course.
@@ -81,7 +406,247 @@ type CourseWork struct {
 	DueTime       string `json:"dueTime"`
 	MaxPoints     int    `json:"maxPoints"`
 	CreatorUserID string `json:"creatorUserId"`
+	CreateTime    string `json:"createTime"`
 	UpdateTime    string `json:"updateTime"`
+	// Materials are the files and links attached by the teacher.
+	Materials []Attachment `json:"materials,omitempty"`
+	// Choices are the options of a multiple-choice question.
+	Choices []string `json:"choices,omitempty"`
+	// WorkFolder is the Drive folder an assignment's submitted files are
+	// placed in. Only teachers see it.
+	WorkFolder *Attachment `json:"workFolder,omitempty"`
+	// AlternateLink opens the coursework in the Classroom web UI.
+	AlternateLink string `json:"alternateLink,omitempty"`
+	// AssigneeMode says whether every student or only StudentIDs are
+	// assigned the work.
+	AssigneeMode string   `json:"assigneeMode,omitempty"`
+	StudentIDs   []string `json:"studentIds,omitempty"`
+	// ScheduledTime is when a draft is due to be published, in RFC 3339.
+	ScheduledTime string `json:"scheduledTime,omitempty"`
+	// TopicID is the topic the coursework is filed under, if any.
+	TopicID string `json:"topicId,omitempty"`
+}
+
+// Topic groups a course's coursework on its Classwork page.
+type Topic struct {
+	ID       string `json:"id"`
+	CourseID string `json:"courseId"`
+	Name     string `json:"name"`
+}
+
+// Scheduled returns when draft coursework is due to be published, or false
+// when it is not scheduled.
+func (cw *CourseWork) Scheduled() (time.Time, bool) {
+	return scheduledAt(cw.State, cw.ScheduledTime)
+}
+
+// scheduledAt parses the scheduled time of a draft post.
+func scheduledAt(state, scheduledTime string) (time.Time, bool) {
+	if state != CourseWorkStateDraft || scheduledTime == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, scheduledTime)
+	return t, err == nil
+}
+
+// IsQuestion reports whether the coursework is a short-answer or
+// multiple-choice question.
+func (cw *CourseWork) IsQuestion() bool {
+	return cw.WorkType == WorkTypeShortAnswer || cw.WorkType == WorkTypeMultipleChoice
+}
+
+// Due returns when the coursework is due, in UTC as Classroom stores it.
+// allDay is set when there is a due date but no time. ok is false when
+// there is no due date.
+func (cw *CourseWork) Due() (due time.Time, allDay, ok bool) {
+	if cw.DueDate == "" {
+		return time.Time{}, false, false
+	}
+	if cw.DueTime == "" {
+		d, err := time.Parse("2006-01-02", cw.DueDate)
+		return d, true, err == nil
+	}
+	d, err := time.Parse("2006-01-02 15:04", cw.DueDate+" "+cw.DueTime)
+	return d, false, err == nil
+}
+
+// Attachment kinds.
+const (
+	AttachmentDriveFile = "driveFile"
+	AttachmentLink      = "link"
+	AttachmentYouTube   = "youtubeVideo"
+	AttachmentForm      = "form"
+	AttachmentFolder    = "drive_folder"
+)
+
+// Attachment is a Drive file, link, video, or form attached to coursework
+// or a submission.
+type Attachment struct {
+	Kind string `json:"kind"`
+	// ID is the Drive file or YouTube video ID.
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// CourseWork states.
+const (
+	CourseWorkStatePublished = "PUBLISHED"
+	CourseWorkStateDraft     = "DRAFT"
+	CourseWorkStateDeleted   = "DELETED"
+)
+
+// Assignee modes of coursework and announcements.
+const (
+	AssigneeModeAll        = "ALL_STUDENTS"
+	AssigneeModeIndividual = "INDIVIDUAL_STUDENTS"
+)
+
+// IsIndividual reports whether the work is assigned to selected students
+// only.
+func (cw *CourseWork) IsIndividual() bool {
+	return cw.AssigneeMode == AssigneeModeIndividual
+}
+
+// CourseWork types.
+const (
+	WorkTypeAssignment     = "ASSIGNMENT"
+	WorkTypeShortAnswer    = "SHORT_ANSWER_QUESTION"
+	WorkTypeMultipleChoice = "MULTIPLE_CHOICE_QUESTION"
+	WorkTypeMaterial       = "MATERIAL"
+)
+
+// CourseWork list orderings.
+const (
+	CourseWorkOrderDueDateAsc     = "dueDate asc"
+	CourseWorkOrderDueDateDesc    = "dueDate desc"
+	CourseWorkOrderUpdateTimeDesc = "updateTime desc"
+	CourseWorkOrderUpdateTimeAsc  = "updateTime asc"
+)
+
+// ListCourseWorkOptions narrows a coursework listing.
+type ListCourseWorkOptions struct {
+	// States restricts results to these states. The API returns only
+	// PUBLISHED coursework when no states are given.
+	States []string
+	// OrderBy sorts results server-side, e.g. CourseWorkOrderDueDateAsc.
+	// The API default is CourseWorkOrderUpdateTimeDesc.
+	OrderBy string
+	// Fields selects a partial response. Defaults to the fields the app uses.
+	Fields []googleapi.Field
+	// PageSize overrides the configured page size for this call.
+	PageSize int
+}
+
+// ListCoursesOptions narrows a course listing.
+type ListCoursesOptions struct {
+	// CourseStates restricts results to these states. All states are
+	// returned when none are given.
+	CourseStates []string
+	// TeacherID restricts results to courses taught by this user. Accepts
+	// "me", an email address, or a user ID.
+	TeacherID string
+	// StudentID restricts results to courses this user is enrolled in.
+	// Accepts "me", an email address, or a user ID.
+	StudentID string
+	// Fields selects a partial response. Defaults to the fields the app uses.
+	Fields []googleapi.Field
+	// PageSize overrides the configured page size for this call.
+	PageSize int
+}
+
+// Submission states.
+const (
+	SubmissionStateNew       = "NEW"
+	SubmissionStateCreated   = "CREATED"
+	SubmissionStateTurnedIn  = "TURNED_IN"
+	SubmissionStateReturned  = "RETURNED"
+	SubmissionStateReclaimed = "RECLAIMED_BY_STUDENT"
+	// SubmissionStateEdited only appears in submission history, when a
+	// student changes a question's answer after turning it in.
+	SubmissionStateEdited = "STUDENT_EDITED_AFTER_TURN_IN"
+)
+
+// Grade changes in submission history.
+const (
+	GradeChangeDraft     = "DRAFT_GRADE_POINTS_EARNED_CHANGE"
+	GradeChangeAssigned  = "ASSIGNED_GRADE_POINTS_EARNED_CHANGE"
+	GradeChangeMaxPoints = "MAX_POINTS_CHANGE"
+)
+
+// ListStudentSubmissionsOptions narrows a submission listing.
+type ListStudentSubmissionsOptions struct {
+	// UserID restricts results to one student. Accepts "me", an email
+	// address, or a user ID.
+	UserID string
+	// States restricts results to these submission states.
+	States []string
+	// Fields selects a partial response. Defaults to the fields the app uses.
+	Fields []googleapi.Field
+	// PageSize overrides the configured page size for this call.
+	PageSize int
+}
+
+// ListAnnouncementsOptions narrows an announcement listing.
+type ListAnnouncementsOptions struct {
+	// States restricts results to these states. The API returns only
+	// PUBLISHED announcements when no states are given.
+	States []string
+	// Fields selects a partial response. Defaults to the fields the app uses.
+	Fields []googleapi.Field
+	// PageSize overrides the configured page size for this call.
+	PageSize int
+}
+
+// ListRosterOptions narrows a student or teacher listing.
+type ListRosterOptions struct {
+	// Fields selects a partial response. Defaults to the fields the app uses.
+	Fields []googleapi.Field
+	// PageSize overrides the configured page size for this call.
+	PageSize int
+}
+
+// SortCourseWork applies an API orderBy value. Coursework without a due
+// date sorts last in ascending order, as in Classroom.
+func SortCourseWork(items []*CourseWork, orderBy string) {
+	due := func(cw *CourseWork) string {
+		if cw.DueDate == "" {
+			return "9999"
+		}
+		return cw.DueDate + " " + cw.DueTime
+	}
+	var less func(a, b *CourseWork) bool
+	switch orderBy {
+	case CourseWorkOrderDueDateAsc:
+		less = func(a, b *CourseWork) bool { return due(a) < due(b) }
+	case CourseWorkOrderDueDateDesc:
+		less = func(a, b *CourseWork) bool { return due(a) > due(b) }
+	case CourseWorkOrderUpdateTimeAsc:
+		less = func(a, b *CourseWork) bool { return a.UpdateTime < b.UpdateTime }
+	default:
+		less = func(a, b *CourseWork) bool { return a.UpdateTime > b.UpdateTime }
+	}
+	sort.SliceStable(items, func(i, j int) bool { return less(items[i], items[j]) })
+}
+
+// VisibleCourseWork applies the coursework visibility rules: deleted items
+// are dropped unless showDeleted is set, and drafts are shown to teachers only.
+func VisibleCourseWork(items []*CourseWork, isTeacher, showDeleted bool) []*CourseWork {
+	visible := make([]*CourseWork, 0, len(items))
+	for _, cw := range items {
+		switch cw.State {
+		case CourseWorkStateDeleted:
+			if !showDeleted {
+				continue
+			}
+		case CourseWorkStateDraft:
+			if !isTeacher {
+				continue
+			}
+		}
+		visible = append(visible, cw)
+	}
+	return visible
 }
 
 // StudentSubmission represents a student's submission for coursework.
@@ -93,9 +658,138 @@ type StudentSubmission struct {
 	State         string `json:"state"`
 	AssignedGrade int    `json:"assignedGrade"`
 	DraftGrade    int    `json:"draftGrade"`
-	Late          bool   `json:"late"`
-	CreateTime    string `json:"createTime"`
-	UpdateTime    string `json:"updateTime"`
+	// HasAssignedGrade is false when the submission has no assigned grade,
+	// e.g. returned with feedback only; AssignedGrade is 0 then as well.
+	HasAssignedGrade bool   `json:"hasAssignedGrade,omitempty"`
+	Late             bool   `json:"late"`
+	CreateTime       string `json:"createTime"`
+	UpdateTime       string `json:"updateTime"`
+	// Attachments are the files and links the student attached.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Answer is the student's answer to a short-answer or multiple-choice
+	// question.
+	Answer string `json:"answer,omitempty"`
+	// AssignedRubricGrades and DraftRubricGrades are the per-criterion
+	// rubric grades, keyed by criterion ID.
+	AssignedRubricGrades map[string]RubricGrade `json:"assignedRubricGrades,omitempty"`
+	DraftRubricGrades    map[string]RubricGrade `json:"draftRubricGrades,omitempty"`
+	// AlternateLink opens the submission in the Classroom web UI.
+	AlternateLink string `json:"alternateLink,omitempty"`
+	// History lists the submission's state and grade changes, oldest
+	// first. List calls leave it out by default to keep pages small;
+	// GetStudentSubmission returns it.
+	History []HistoryEvent `json:"history,omitempty"`
+}
+
+// HistoryEvent is one state or grade change of a submission.
+type HistoryEvent struct {
+	Time string `json:"time"`
+	// ActorUserID is who made the change.
+	ActorUserID string `json:"actorUserId,omitempty"`
+	// State is set for state changes.
+	State string `json:"state,omitempty"`
+	// GradeChange is set for grade changes, with the grade after the
+	// change.
+	GradeChange  string  `json:"gradeChange,omitempty"`
+	PointsEarned float64 `json:"pointsEarned,omitempty"`
+	MaxPoints    float64 `json:"maxPoints,omitempty"`
+}
+
+// Rubric is the grading rubric attached to coursework.
+type Rubric struct {
+	ID           string      `json:"id"`
+	CourseID     string      `json:"courseId"`
+	CourseWorkID string      `json:"courseWorkId"`
+	Criteria     []Criterion `json:"criteria"`
+}
+
+// Criterion is one row of a rubric.
+type Criterion struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Levels      []Level `json:"levels"`
+}
+
+// Level is one performance level of a criterion.
+type Level struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Points      float64 `json:"points"`
+}
+
+// RubricGrade is the level a submission was given for one criterion.
+type RubricGrade struct {
+	CriterionID string  `json:"criterionId"`
+	LevelID     string  `json:"levelId,omitempty"`
+	Points      float64 `json:"points"`
+}
+
+// MaxPoints returns the rubric's total when every criterion is given its
+// highest level.
+func (r *Rubric) MaxPoints() float64 {
+	total := 0.0
+	for _, c := range r.Criteria {
+		best := 0.0
+		for _, l := range c.Levels {
+			best = max(best, l.Points)
+		}
+		total += best
+	}
+	return total
+}
+
+// Level returns the criterion's level with the given ID.
+func (c Criterion) Level(levelID string) (Level, bool) {
+	for _, l := range c.Levels {
+		if l.ID == levelID {
+			return l, true
+		}
+	}
+	return Level{}, false
+}
+
+// Score sums the points of grades, which are keyed by criterion ID. It
+// reports false unless every criterion of the rubric is graded.
+func (r *Rubric) Score(grades map[string]RubricGrade) (float64, bool) {
+	total := 0.0
+	for _, c := range r.Criteria {
+		g, ok := grades[c.ID]
+		if !ok {
+			return 0, false
+		}
+		total += g.Points
+	}
+	return total, true
+}
+
+// CanTurnIn reports whether the submission is in a state that can be turned in.
+func (s *StudentSubmission) CanTurnIn() bool {
+	switch s.State {
+	case SubmissionStateNew, SubmissionStateCreated, SubmissionStateReclaimed:
+		return true
+	default:
+		return false
+	}
+}
+
+// AddOnAttachment is an attachment a Classroom add-on created on
+// coursework, such as an interactive lesson or a third-party quiz.
+type AddOnAttachment struct {
+	ID           string `json:"id"`
+	CourseID     string `json:"courseId"`
+	CourseWorkID string `json:"courseWorkId"`
+	Title        string `json:"title"`
+	// StudentViewURL, TeacherViewURL, and StudentWorkReviewURL are the
+	// add-on's pages for students, teachers, and reviewing student work.
+	StudentViewURL       string `json:"studentViewUrl,omitempty"`
+	TeacherViewURL       string `json:"teacherViewUrl,omitempty"`
+	StudentWorkReviewURL string `json:"studentWorkReviewUrl,omitempty"`
+	// MaxPoints is set for add-ons that grade their own activity.
+	MaxPoints float64 `json:"maxPoints,omitempty"`
+	DueDate   string  `json:"dueDate,omitempty"`
+	DueTime   string  `json:"dueTime,omitempty"`
 }
 
 // Announcement represents a course announcement.
@@ -107,6 +801,33 @@ type Announcement struct {
 	CreatorUserID string `json:"creatorUserId"`
 	CreateTime    string `json:"createTime"`
 	UpdateTime    string `json:"updateTime"`
+	// AlternateLink opens the announcement in the Classroom web UI.
+	AlternateLink string `json:"alternateLink,omitempty"`
+	// AssigneeMode says whether every student or only StudentIDs can see
+	// the announcement.
+	AssigneeMode string   `json:"assigneeMode,omitempty"`
+	StudentIDs   []string `json:"studentIds,omitempty"`
+	// ScheduledTime is when a draft is due to be published, in RFC 3339.
+	ScheduledTime string `json:"scheduledTime,omitempty"`
+}
+
+// Announcement states.
+const (
+	AnnouncementStatePublished = "PUBLISHED"
+	AnnouncementStateDraft     = "DRAFT"
+	AnnouncementStateDeleted   = "DELETED"
+)
+
+// Scheduled returns when a draft announcement is due to be published, or
+// false when it is not scheduled.
+func (a *Announcement) Scheduled() (time.Time, bool) {
+	return scheduledAt(a.State, a.ScheduledTime)
+}
+
+// IsIndividual reports whether the announcement is shown to selected
+// students only.
+func (a *Announcement) IsIndividual() bool {
+	return a.AssigneeMode == AssigneeModeIndividual
 }
 
 // Student represents a course student.
@@ -131,6 +852,14 @@ type UserProfile struct {
 	PhotoURL     string `json:"photoUrl"`
 }
 
+// Invitation represents a pending invitation to join a course.
+type Invitation struct {
+	ID       string `json:"id"`
+	CourseID string `json:"courseId"`
+	UserID   string `json:"userId"`
+	Role     string `json:"role"`
+}
+
 // ListCoursesResponse represents the response from listing courses.
 type ListCoursesResponse struct {
 	Courses       []*Course `json:"courses"`
@@ -167,26 +896,59 @@ type ListTeachersResponse struct {
 	NextPageToken string     `json:"nextPageToken"`
 }
 
-// ListCourses retrieves all courses the user has access to.
-func (c *Client) ListCourses(ctx context.Context) ([]*Course, error) {
+// ListCourses retrieves all courses the user has access to. opts may be nil.
+func (c *Client) ListCourses(ctx context.Context, opts *ListCoursesOptions) ([]*Course, error) {
 	var courses []*Course
+	err := c.CoursePages(ctx, opts, func(page []*Course) error {
+		courses = append(courses, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return courses, nil
+}
+
+// CoursePages calls fn with each page of courses as it arrives.
+// An error from fn stops paging and is returned. opts may be nil.
+func (c *Client) CoursePages(ctx context.Context, opts *ListCoursesOptions, fn func([]*Course) error) error {
+	if opts == nil {
+		opts = &ListCoursesOptions{}
+	}
 	pageToken := ""
 
 	for {
 		req := c.service.Courses.List()
+		req.Fields(selectFields(opts.Fields, coursesListFields)...)
+		if n := c.pageSizeFor(PageCourses, opts.PageSize); n > 0 {
+			req.PageSize(n)
+		}
+		if len(opts.CourseStates) > 0 {
+			req.CourseStates(opts.CourseStates...)
+		}
+		if opts.TeacherID != "" {
+			req.TeacherId(opts.TeacherID)
+		}
+		if opts.StudentID != "" {
+			req.StudentId(opts.StudentID)
+		}
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		resp, err := c.executeWithRetry(ctx, func() (*classroom.ListCoursesResponse, error) {
-			return req.Do()
+		resp, err := executeWithRetry(ctx, c, "courses.list", func() (*classroom.ListCoursesResponse, error) {
+			return req.Context(ctx).Do()
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list courses: %w", err)
+			return wrapError(err, "failed to list courses")
 		}
 
-		for _, course := range resp.Courses {
-			courses = append(courses, convertCourse(course))
+		page := make([]*Course, len(resp.Courses))
+		for i, course := range resp.Courses {
+			page[i] = convertCourse(course)
+		}
+		if err := fn(page); err != nil {
+			return err
 		}
 
 		pageToken = resp.NextPageToken
@@ -195,41 +957,132 @@ func (c *Client) ListCourses(ctx context.Context) ([]*Course, error) {
 		}
 	}
 
-	return courses, nil
+	return nil
 }
 
 // GetCourse retrieves a specific course by ID.
 func (c *Client) GetCourse(ctx context.Context, courseID string) (*Course, error) {
-	resp, err := c.executeWithRetry(ctx, func() (*classroom.Course, error) {
-		return c.service.Courses.Get(courseID).Do()
+	resp, err := executeWithRetry(ctx, c, "courses.get", func() (*classroom.Course, error) {
+		return c.service.Courses.Get(courseID).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to get course %s", courseID))
+	}
+
+	return convertCourse(resp), nil
+}
+
+// CreateCourse creates a new course owned by the current user.
+func (c *Client) CreateCourse(ctx context.Context, name, section, room string) (*Course, error) {
+	resp, err := executeWrite(ctx, c, "courses.create", func() (*classroom.Course, error) {
+		return c.service.Courses.Create(&classroom.Course{
+			Name:    name,
+			Section: section,
+			Room:    room,
+			OwnerId: "me",
+		}).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to create course")
+	}
+
+	return convertCourse(resp), nil
+}
+
+// PatchCourse updates the name, section, or room of a course.
+func (c *Client) PatchCourse(ctx context.Context, courseID string, patch CoursePatch) (*Course, error) {
+	course := &classroom.Course{}
+	var mask []string
+	if patch.Name != nil {
+		course.Name = *patch.Name
+		mask = append(mask, "name")
+	}
+	if patch.Section != nil {
+		course.Section = *patch.Section
+		course.ForceSendFields = append(course.ForceSendFields, "Section")
+		mask = append(mask, "section")
+	}
+	if patch.Room != nil {
+		course.Room = *patch.Room
+		course.ForceSendFields = append(course.ForceSendFields, "Room")
+		mask = append(mask, "room")
+	}
+	if len(mask) == 0 {
+		return nil, fmt.Errorf("no course fields to update")
+	}
+
+	resp, err := executeWrite(ctx, c, "courses.patch", func() (*classroom.Course, error) {
+		return c.service.Courses.Patch(courseID, course).UpdateMask(strings.Join(mask, ",")).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to update course %s", courseID))
+	}
+
+	return convertCourse(resp), nil
+}
+
+// UpdateCourseState moves a course to a new state, such as ACTIVE or ARCHIVED.
+func (c *Client) UpdateCourseState(ctx context.Context, courseID, state string) (*Course, error) {
+	resp, err := executeWrite(ctx, c, "courses.patch", func() (*classroom.Course, error) {
+		return c.service.Courses.Patch(courseID, &classroom.Course{CourseState: state}).UpdateMask("courseState").Context(ctx).Do()
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get course %s: %w", courseID, err)
+		return nil, wrapError(err, fmt.Sprintf("failed to set course %s to %s", courseID, state))
 	}
 
 	return convertCourse(resp), nil
 }
 
-// ListCourseWork retrieves all coursework for a course.
-func (c *Client) ListCourseWork(ctx context.Context, courseID string) ([]*CourseWork, error) {
+// ListCourseWork retrieves all coursework for a course. opts may be nil.
+func (c *Client) ListCourseWork(ctx context.Context, courseID string, opts *ListCourseWorkOptions) ([]*CourseWork, error) {
 	var coursework []*CourseWork
+	err := c.CourseWorkPages(ctx, courseID, opts, func(page []*CourseWork) error {
+		coursework = append(coursework, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coursework, nil
+}
+
+// CourseWorkPages calls fn with each page of coursework as it arrives.
+// An error from fn stops paging and is returned. opts may be nil.
+func (c *Client) CourseWorkPages(ctx context.Context, courseID string, opts *ListCourseWorkOptions, fn func([]*CourseWork) error) error {
+	if opts == nil {
+		opts = &ListCourseWorkOptions{}
+	}
 	pageToken := ""
 
 	for {
 		req := c.service.Courses.CourseWork.List(courseID)
+		req.Fields(selectFields(opts.Fields, courseWorkListFields)...)
+		if n := c.pageSizeFor(PageCourseWork, opts.PageSize); n > 0 {
+			req.PageSize(n)
+		}
+		if opts.OrderBy != "" {
+			req.OrderBy(opts.OrderBy)
+		}
+		if len(opts.States) > 0 {
+			req.CourseWorkStates(opts.States...)
+		}
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		resp, err := c.executeWithRetry(ctx, func() (*classroom.ListCourseWorkResponse, error) {
-			return req.Do()
+		resp, err := executeWithRetry(ctx, c, "courses.courseWork.list", func() (*classroom.ListCourseWorkResponse, error) {
+			return req.Context(ctx).Do()
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list coursework: %w", err)
+			return wrapError(err, "failed to list coursework")
 		}
 
-		for _, cw := range resp.CourseWork {
-			coursework = append(coursework, convertCourseWork(cw))
+		page := make([]*CourseWork, len(resp.CourseWork))
+		for i, cw := range resp.CourseWork {
+			page[i] = convertCourseWork(cw)
+		}
+		if err := fn(page); err != nil {
+			return err
 		}
 
 		pageToken = resp.NextPageToken
@@ -238,41 +1091,216 @@ func (c *Client) ListCourseWork(ctx context.Context, courseID string) ([]*Course
 		}
 	}
 
-	return coursework, nil
+	return nil
 }
 
 // GetCourseWork retrieves specific coursework by ID.
 func (c *Client) GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*CourseWork, error) {
-	resp, err := c.executeWithRetry(ctx, func() (*classroom.CourseWork, error) {
-		return c.service.Courses.CourseWork.Get(courseID, courseWorkID).Do()
+	resp, err := executeWithRetry(ctx, c, "courses.courseWork.get", func() (*classroom.CourseWork, error) {
+		return c.service.Courses.CourseWork.Get(courseID, courseWorkID).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to get coursework %s", courseWorkID))
+	}
+
+	return convertCourseWork(resp), nil
+}
+
+// GetRubric returns the rubric attached to coursework, or nil when it has
+// none. Coursework has at most one rubric.
+func (c *Client) GetRubric(ctx context.Context, courseID, courseWorkID string) (*Rubric, error) {
+	resp, err := executeWithRetry(ctx, c, "courses.courseWork.rubrics.list", func() (*classroom.ListRubricsResponse, error) {
+		return c.service.Courses.CourseWork.Rubrics.List(courseID, courseWorkID).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to get rubric for coursework %s", courseWorkID))
+	}
+	if len(resp.Rubrics) == 0 {
+		return nil, nil
+	}
+
+	return convertRubric(resp.Rubrics[0]), nil
+}
+
+// ListAddOnAttachments returns the add-on attachments on coursework. The
+// API only lists them for users of the add-on that created them; others get
+// a permission error.
+func (c *Client) ListAddOnAttachments(ctx context.Context, courseID, courseWorkID string) ([]*AddOnAttachment, error) {
+	var attachments []*AddOnAttachment
+	pageToken := ""
+
+	for {
+		req := c.service.Courses.CourseWork.AddOnAttachments.List(courseID, courseWorkID).Fields(addOnAttachmentsListFields)
+		if n := c.pageSizeFor(PageAddOnAttachments, 0); n > 0 {
+			req.PageSize(n)
+		}
+		if pageToken != "" {
+			req.PageToken(pageToken)
+		}
+
+		resp, err := executeWithRetry(ctx, c, "courses.courseWork.addOnAttachments.list", func() (*classroom.ListAddOnAttachmentsResponse, error) {
+			return req.Context(ctx).Do()
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to list add-on attachments for coursework %s", courseWorkID))
+		}
+
+		for _, a := range resp.AddOnAttachments {
+			attachments = append(attachments, convertAddOnAttachment(a))
+		}
+
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return attachments, nil
+}
+
+// ListTopics returns the course's topics. Reading them needs the topics
+// scope, which is not requested at login; without it the API returns a
+// permission error.
+func (c *Client) ListTopics(ctx context.Context, courseID string) ([]*Topic, error) {
+	var topics []*Topic
+	pageToken := ""
+
+	for {
+		req := c.service.Courses.Topics.List(courseID).Fields(topicsListFields)
+		if pageToken != "" {
+			req.PageToken(pageToken)
+		}
+
+		resp, err := executeWithRetry(ctx, c, "courses.topics.list", func() (*classroom.ListTopicResponse, error) {
+			return req.Context(ctx).Do()
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to list topics for course %s", courseID))
+		}
+
+		for _, t := range resp.Topic {
+			topics = append(topics, &Topic{ID: t.TopicId, CourseID: t.CourseId, Name: t.Name})
+		}
+
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return topics, nil
+}
+
+// GetAddOnAttachment returns one add-on attachment on coursework.
+func (c *Client) GetAddOnAttachment(ctx context.Context, courseID, courseWorkID, attachmentID string) (*AddOnAttachment, error) {
+	resp, err := executeWithRetry(ctx, c, "courses.courseWork.addOnAttachments.get", func() (*classroom.AddOnAttachment, error) {
+		return c.service.Courses.CourseWork.AddOnAttachments.Get(courseID, courseWorkID, attachmentID).Fields(addOnAttachmentFields).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to get add-on attachment %s", attachmentID))
+	}
+
+	return convertAddOnAttachment(resp), nil
+}
+
+// CreateCourseWork creates coursework from the title, description, work
+// type, state, due date and time, max points, and multiple-choice options
+// of cw. The due date and time are in UTC, as the API expects.
+func (c *Client) CreateCourseWork(ctx context.Context, courseID string, cw *CourseWork) (*CourseWork, error) {
+	dueDate, err := parseDate(cw.DueDate)
+	if err != nil {
+		return nil, err
+	}
+	dueTime, err := parseTime(cw.DueTime)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &classroom.CourseWork{
+		Title:       cw.Title,
+		Description: cw.Description,
+		WorkType:    cw.WorkType,
+		State:       cw.State,
+		DueDate:     dueDate,
+		DueTime:     dueTime,
+		MaxPoints:   float64(cw.MaxPoints),
+	}
+	if cw.WorkType == WorkTypeMultipleChoice {
+		req.MultipleChoiceQuestion = &classroom.MultipleChoiceQuestion{Choices: cw.Choices}
+	}
+
+	resp, err := executeWrite(ctx, c, "courses.courseWork.create", func() (*classroom.CourseWork, error) {
+		return c.service.Courses.CourseWork.Create(courseID, req).Context(ctx).Do()
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get coursework %s: %w", courseWorkID, err)
+		return nil, wrapError(err, fmt.Sprintf("failed to create coursework %q", cw.Title))
 	}
 
 	return convertCourseWork(resp), nil
 }
 
-// ListStudentSubmissions retrieves all submissions for coursework.
-func (c *Client) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string) ([]*StudentSubmission, error) {
+// DeleteCourseWork deletes coursework.
+func (c *Client) DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error {
+	_, err := executeWrite(ctx, c, "courses.courseWork.delete", func() (*classroom.Empty, error) {
+		return c.service.Courses.CourseWork.Delete(courseID, courseWorkID).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, fmt.Sprintf("failed to delete coursework %s", courseWorkID))
+	}
+
+	return nil
+}
+
+// ListStudentSubmissions retrieves all submissions for coursework. opts may be nil.
+func (c *Client) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *ListStudentSubmissionsOptions) ([]*StudentSubmission, error) {
 	var submissions []*StudentSubmission
+	err := c.StudentSubmissionPages(ctx, courseID, courseWorkID, opts, func(page []*StudentSubmission) error {
+		submissions = append(submissions, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// StudentSubmissionPages calls fn with each page of submissions as it arrives.
+// An error from fn stops paging and is returned. opts may be nil.
+func (c *Client) StudentSubmissionPages(ctx context.Context, courseID, courseWorkID string, opts *ListStudentSubmissionsOptions, fn func([]*StudentSubmission) error) error {
+	if opts == nil {
+		opts = &ListStudentSubmissionsOptions{}
+	}
 	pageToken := ""
 
 	for {
 		req := c.service.Courses.CourseWork.StudentSubmissions.List(courseID, courseWorkID)
+		req.Fields(selectFields(opts.Fields, submissionsListFields)...)
+		if n := c.pageSizeFor(PageSubmissions, opts.PageSize); n > 0 {
+			req.PageSize(n)
+		}
+		if opts.UserID != "" {
+			req.UserId(opts.UserID)
+		}
+		if len(opts.States) > 0 {
+			req.States(opts.States...)
+		}
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		resp, err := c.executeWithRetry(ctx, func() (*classroom.ListStudentSubmissionsResponse, error) {
-			return req.Do()
+		resp, err := executeWithRetry(ctx, c, "courses.courseWork.studentSubmissions.list", func() (*classroom.ListStudentSubmissionsResponse, error) {
+			return req.Context(ctx).Do()
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list submissions: %w", err)
+			return wrapError(err, "failed to list submissions")
 		}
 
-		for _, sub := range resp.StudentSubmissions {
-			submissions = append(submissions, convertSubmission(sub))
+		page := make([]*StudentSubmission, len(resp.StudentSubmissions))
+		for i, sub := range resp.StudentSubmissions {
+			page[i] = convertSubmission(sub)
+		}
+		if err := fn(page); err != nil {
+			return err
 		}
 
 		pageToken = resp.NextPageToken
@@ -281,16 +1309,17 @@ func (c *Client) ListStudentSubmissions(ctx context.Context, courseID, courseWor
 		}
 	}
 
-	return submissions, nil
+	return nil
 }
 
-// GetStudentSubmission retrieves a specific submission.
+// GetStudentSubmission retrieves a specific submission, including its
+// history.
 func (c *Client) GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*StudentSubmission, error) {
-	resp, err := c.executeWithRetry(ctx, func() (*classroom.StudentSubmission, error) {
-		return c.service.Courses.CourseWork.StudentSubmissions.Get(courseID, courseWorkID, submissionID).Do()
+	resp, err := executeWithRetry(ctx, c, "courses.courseWork.studentSubmissions.get", func() (*classroom.StudentSubmission, error) {
+		return c.service.Courses.CourseWork.StudentSubmissions.Get(courseID, courseWorkID, submissionID).Context(ctx).Do()
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get submission %s: %w", submissionID, err)
+		return nil, wrapError(err, fmt.Sprintf("failed to get submission %s", submissionID))
 	}
 
 	return convertSubmission(resp), nil
@@ -298,36 +1327,129 @@ func (c *Client) GetStudentSubmission(ctx context.Context, courseID, courseWorkI
 
 // TurnIn turns in a student's submission.
 func (c *Client) TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error {
-	_, err := c.executeWithRetry(ctx, func() (*classroom.Empty, error) {
-		return c.service.Courses.CourseWork.StudentSubmissions.TurnIn(courseID, courseWorkID, submissionID, &classroom.TurnInStudentSubmissionRequest{}).Do()
+	_, err := executeWrite(ctx, c, "courses.courseWork.studentSubmissions.turnIn", func() (*classroom.Empty, error) {
+		return c.service.Courses.CourseWork.StudentSubmissions.TurnIn(courseID, courseWorkID, submissionID, &classroom.TurnInStudentSubmissionRequest{}).Context(ctx).Do()
 	})
 	if err != nil {
-		return fmt.Errorf("failed to turn in submission: %w", err)
+		return wrapError(err, "failed to turn in submission")
 	}
 
 	return nil
 }
 
-// ListAnnouncements retrieves all announcements for a course.
-func (c *Client) ListAnnouncements(ctx context.Context, courseID string) ([]*Announcement, error) {
+// ModifyAttachments adds Drive files to a student's submission. Only the
+// student who owns the submission can do this, and only before it is
+// turned in.
+func (c *Client) ModifyAttachments(ctx context.Context, courseID, courseWorkID, submissionID string, driveFileIDs []string) (*StudentSubmission, error) {
+	req := &classroom.ModifyAttachmentsRequest{}
+	for _, id := range driveFileIDs {
+		req.AddAttachments = append(req.AddAttachments, &classroom.Attachment{
+			DriveFile: &classroom.DriveFile{Id: id},
+		})
+	}
+	resp, err := executeWrite(ctx, c, "courses.courseWork.studentSubmissions.modifyAttachments", func() (*classroom.StudentSubmission, error) {
+		return c.service.Courses.CourseWork.StudentSubmissions.ModifyAttachments(courseID, courseWorkID, submissionID, req).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to attach files to submission")
+	}
+
+	return convertSubmission(resp), nil
+}
+
+// SetDraftGrade sets a submission's draft grade. Only teachers see it until
+// the submission is returned.
+func (c *Client) SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*StudentSubmission, error) {
+	resp, err := executeWrite(ctx, c, "courses.courseWork.studentSubmissions.patch", func() (*classroom.StudentSubmission, error) {
+		return c.service.Courses.CourseWork.StudentSubmissions.Patch(courseID, courseWorkID, submissionID, &classroom.StudentSubmission{
+			DraftGrade:      grade,
+			ForceSendFields: []string{"DraftGrade"},
+		}).UpdateMask("draftGrade").Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to set draft grade")
+	}
+
+	return convertSubmission(resp), nil
+}
+
+// SetAssignedGrade sets the grade the student sees once the submission is
+// returned. The draft grade is set to match, as Classroom does when a
+// teacher grades in the web UI.
+func (c *Client) SetAssignedGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*StudentSubmission, error) {
+	resp, err := executeWrite(ctx, c, "courses.courseWork.studentSubmissions.patch", func() (*classroom.StudentSubmission, error) {
+		return c.service.Courses.CourseWork.StudentSubmissions.Patch(courseID, courseWorkID, submissionID, &classroom.StudentSubmission{
+			DraftGrade:      grade,
+			AssignedGrade:   grade,
+			ForceSendFields: []string{"DraftGrade", "AssignedGrade"},
+		}).UpdateMask("draftGrade,assignedGrade").Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to set grade")
+	}
+
+	return convertSubmission(resp), nil
+}
+
+// ReturnSubmission returns a submission to the student, releasing its
+// assigned grade to them.
+func (c *Client) ReturnSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	_, err := executeWrite(ctx, c, "courses.courseWork.studentSubmissions.return", func() (*classroom.Empty, error) {
+		return c.service.Courses.CourseWork.StudentSubmissions.Return(courseID, courseWorkID, submissionID, &classroom.ReturnStudentSubmissionRequest{}).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, "failed to return submission")
+	}
+	return nil
+}
+
+// ListAnnouncements retrieves all announcements for a course. opts may be nil.
+func (c *Client) ListAnnouncements(ctx context.Context, courseID string, opts *ListAnnouncementsOptions) ([]*Announcement, error) {
 	var announcements []*Announcement
+	err := c.AnnouncementPages(ctx, courseID, opts, func(page []*Announcement) error {
+		announcements = append(announcements, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// AnnouncementPages calls fn with each page of announcements as it arrives.
+// An error from fn stops paging and is returned. opts may be nil.
+func (c *Client) AnnouncementPages(ctx context.Context, courseID string, opts *ListAnnouncementsOptions, fn func([]*Announcement) error) error {
+	if opts == nil {
+		opts = &ListAnnouncementsOptions{}
+	}
 	pageToken := ""
 
 	for {
 		req := c.service.Courses.Announcements.List(courseID)
+		req.Fields(selectFields(opts.Fields, announcementsListFields)...)
+		if len(opts.States) > 0 {
+			req.AnnouncementStates(opts.States...)
+		}
+		if n := c.pageSizeFor(PageAnnouncements, opts.PageSize); n > 0 {
+			req.PageSize(n)
+		}
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		resp, err := c.executeWithRetry(ctx, func() (*classroom.ListAnnouncementsResponse, error) {
-			return req.Do()
+		resp, err := executeWithRetry(ctx, c, "courses.announcements.list", func() (*classroom.ListAnnouncementsResponse, error) {
+			return req.Context(ctx).Do()
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list announcements: %w", err)
+			return wrapError(err, "failed to list announcements")
 		}
 
-		for _, ann := range resp.Announcements {
-			announcements = append(announcements, convertAnnouncement(ann))
+		page := make([]*Announcement, len(resp.Announcements))
+		for i, ann := range resp.Announcements {
+			page[i] = convertAnnouncement(ann)
+		}
+		if err := fn(page); err != nil {
+			return err
 		}
 
 		pageToken = resp.NextPageToken
@@ -336,25 +1458,44 @@ func (c *Client) ListAnnouncements(ctx context.Context, courseID string) ([]*Ann
 		}
 	}
 
-	return announcements, nil
+	return nil
 }
 
-// ListStudents retrieves all students for a course.
-func (c *Client) ListStudents(ctx context.Context, courseID string) ([]*Student, error) {
+// DeleteAnnouncement deletes an announcement.
+func (c *Client) DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error {
+	_, err := executeWrite(ctx, c, "courses.announcements.delete", func() (*classroom.Empty, error) {
+		return c.service.Courses.Announcements.Delete(courseID, announcementID).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, fmt.Sprintf("failed to delete announcement %s", announcementID))
+	}
+
+	return nil
+}
+
+// ListStudents retrieves all students for a course. opts may be nil.
+func (c *Client) ListStudents(ctx context.Context, courseID string, opts *ListRosterOptions) ([]*Student, error) {
 	var students []*Student
+	if opts == nil {
+		opts = &ListRosterOptions{}
+	}
 	pageToken := ""
 
 	for {
 		req := c.service.Courses.Students.List(courseID)
+		req.Fields(selectFields(opts.Fields, studentsListFields)...)
+		if n := c.pageSizeFor(PageStudents, opts.PageSize); n > 0 {
+			req.PageSize(n)
+		}
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		resp, err := c.executeWithRetry(ctx, func() (*classroom.ListStudentsResponse, error) {
-			return req.Do()
+		resp, err := executeWithRetry(ctx, c, "courses.students.list", func() (*classroom.ListStudentsResponse, error) {
+			return req.Context(ctx).Do()
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list students: %w", err)
+			return nil, wrapError(err, "failed to list students")
 		}
 
 		for _, s := range resp.Students {
@@ -370,22 +1511,41 @@ func (c *Client) ListStudents(ctx context.Context, courseID string) ([]*Student,
 	return students, nil
 }
 
-// ListTeachers retrieves all teachers for a course.
-func (c *Client) ListTeachers(ctx context.Context, courseID string) ([]*Teacher, error) {
+// RemoveStudent removes a student from a course.
+func (c *Client) RemoveStudent(ctx context.Context, courseID, userID string) error {
+	_, err := executeWrite(ctx, c, "courses.students.delete", func() (*classroom.Empty, error) {
+		return c.service.Courses.Students.Delete(courseID, userID).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, fmt.Sprintf("failed to remove student %s", userID))
+	}
+
+	return nil
+}
+
+// ListTeachers retrieves all teachers for a course. opts may be nil.
+func (c *Client) ListTeachers(ctx context.Context, courseID string, opts *ListRosterOptions) ([]*Teacher, error) {
 	var teachers []*Teacher
+	if opts == nil {
+		opts = &ListRosterOptions{}
+	}
 	pageToken := ""
 
 	for {
 		req := c.service.Courses.Teachers.List(courseID)
+		req.Fields(selectFields(opts.Fields, teachersListFields)...)
+		if n := c.pageSizeFor(PageTeachers, opts.PageSize); n > 0 {
+			req.PageSize(n)
+		}
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		resp, err := c.executeWithRetry(ctx, func() (*classroom.ListTeachersResponse, error) {
-			return req.Do()
+		resp, err := executeWithRetry(ctx, c, "courses.teachers.list", func() (*classroom.ListTeachersResponse, error) {
+			return req.Context(ctx).Do()
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list teachers: %w", err)
+			return nil, wrapError(err, "failed to list teachers")
 		}
 
 		for _, t := range resp.Teachers {
@@ -401,59 +1561,155 @@ func (c *Client) ListTeachers(ctx context.Context, courseID string) ([]*Teacher,
 	return teachers, nil
 }
 
-// executeWithRetry executes a function with exponential backoff on rate limit errors.
-func (c *Client) executeWithRetry(ctx context.Context, fn func() (interface{}, error)) (interface{}, error) {
-	var lastErr error
-	backoff := time.Second
+// RemoveTeacher removes a teacher from a course.
+func (c *Client) RemoveTeacher(ctx context.Context, courseID, userID string) error {
+	_, err := executeWrite(ctx, c, "courses.teachers.delete", func() (*classroom.Empty, error) {
+		return c.service.Courses.Teachers.Delete(courseID, userID).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, fmt.Sprintf("failed to remove teacher %s", userID))
+	}
 
-	for attempt := 0; attempt < 3; attempt++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	return nil
+}
+
+// IsTeacher reports whether the current user teaches the course.
+func (c *Client) IsTeacher(ctx context.Context, courseID string) (bool, error) {
+	_, err := executeWithRetry(ctx, c, "courses.teachers.get", func() (*classroom.Teacher, error) {
+		return c.service.Courses.Teachers.Get(courseID, "me").Context(ctx).Do()
+	})
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return false, nil
 		}
+		return false, wrapError(err, "failed to check teacher role")
+	}
 
-		resp, err := fn()
-		if err == nil {
-			return resp, nil
+	return true, nil
+}
+
+// GetUserProfile retrieves a user's profile. userID accepts "me", an email
+// address, or a user ID; the email address is only filled in with the
+// classroom.profile.emails scope.
+func (c *Client) GetUserProfile(ctx context.Context, userID string) (*UserProfile, error) {
+	p, err := executeWithRetry(ctx, c, "userProfiles.get", func() (*classroom.UserProfile, error) {
+		return c.service.UserProfiles.Get(userID).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to get profile of %s", userID))
+	}
+
+	profile := convertProfile(p)
+	return &profile, nil
+}
+
+// ListInvitations retrieves all pending invitations for the current user.
+func (c *Client) ListInvitations(ctx context.Context) ([]*Invitation, error) {
+	var invitations []*Invitation
+	pageToken := ""
+
+	for {
+		req := c.service.Invitations.List().UserId("me").Fields(invitationsListFields)
+		if n := c.pageSizeFor(PageInvitations, 0); n > 0 {
+			req.PageSize(n)
+		}
+		if pageToken != "" {
+			req.PageToken(pageToken)
 		}
 
-		// Check for rate limit error (429)
-		if isRateLimitError(err) {
-			lastErr = err
-			time.Sleep(backoff)
-			backoff *= 2
-			continue
+		resp, err := executeWithRetry(ctx, c, "invitations.list", func() (*classroom.ListInvitationsResponse, error) {
+			return req.Context(ctx).Do()
+		})
+		if err != nil {
+			return nil, wrapError(err, "failed to list invitations")
 		}
 
-		// Check for other API errors
-		if isAPIError(err) {
-			return nil, err
+		for _, inv := range resp.Invitations {
+			invitations = append(invitations, convertInvitation(inv))
 		}
 
-		lastErr = err
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
 	}
 
-	return nil, fmt.Errorf("after %d attempts: %w", 3, lastErr)
+	return invitations, nil
 }
 
-// isRateLimitError checks if the error is a rate limit error.
-func isRateLimitError(err error) bool {
-	if err == nil {
-		return false
+// AcceptInvitation accepts an invitation, enrolling the current user in the course.
+func (c *Client) AcceptInvitation(ctx context.Context, invitationID string) error {
+	_, err := executeWrite(ctx, c, "invitations.accept", func() (*classroom.Empty, error) {
+		return c.service.Invitations.Accept(invitationID).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, fmt.Sprintf("failed to accept invitation %s", invitationID))
 	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "429") || strings.Contains(errStr, "rate limit")
+
+	return nil
 }
 
-// isAPIError checks if the error is an API error that should not be retried.
-func isAPIError(err error) bool {
-	if err == nil {
-		return false
+// DeleteInvitation deletes (declines) an invitation.
+func (c *Client) DeleteInvitation(ctx context.Context, invitationID string) error {
+	_, err := executeWrite(ctx, c, "invitations.delete", func() (*classroom.Empty, error) {
+		return c.service.Invitations.Delete(invitationID).Context(ctx).Do()
+	})
+	if err != nil {
+		return wrapError(err, fmt.Sprintf("failed to delete invitation %s", invitationID))
+	}
+
+	return nil
+}
+
+// CreateInvitation invites a user to a course with the given role (STUDENT, TEACHER, or OWNER).
+func (c *Client) CreateInvitation(ctx context.Context, courseID, userID, role string) (*Invitation, error) {
+	resp, err := executeWrite(ctx, c, "invitations.create", func() (*classroom.Invitation, error) {
+		return c.service.Invitations.Create(&classroom.Invitation{
+			CourseId: courseID,
+			UserId:   userID,
+			Role:     role,
+		}).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to create invitation")
 	}
-	errStr := err.Error()
-	// 403 (forbidden), 404 (not found), 401 (unauthorized) should not be retried
-	return strings.Contains(errStr, "403") || strings.Contains(errStr, "404") || strings.Contains(errStr, "401")
+
+	return convertInvitation(resp), nil
+}
+
+// executeWithRetry executes a function under the client's rate limit and
+// retry policy. Every attempt, retries included, waits for the limiter and
+// is counted in the stats of endpoint, the API method name.
+func executeWithRetry[T any](ctx context.Context, c *Client, endpoint string, fn func() (T, error)) (T, error) {
+	return execute(ctx, c, c.retry, endpoint, fn)
+}
+
+// executeWrite executes a function that changes something, such as
+// creating coursework, turning in a submission, or deleting an
+// announcement. Writes are not idempotent: a request that timed out or
+// failed with a server error may still have been carried out, and
+// repeating it would create a duplicate or fail on what the first attempt
+// already did. So it is retried only when the server turned it away; see
+// isRetryableWrite.
+func executeWrite[T any](ctx context.Context, c *Client, endpoint string, fn func() (T, error)) (T, error) {
+	return execute(ctx, c, c.writeRetry, endpoint, fn)
+}
+
+// execute executes a function under the client's rate limit and policy.
+func execute[T any](ctx context.Context, c *Client, policy *backoff.Policy, endpoint string, fn func() (T, error)) (T, error) {
+	retry := false
+	return backoff.Retry(ctx, policy, func() (T, error) {
+		if err := c.limiter.Wait(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		start := time.Now()
+		resp, err := fn()
+		c.metrics.record(endpoint, retry, time.Since(start), err)
+		retry = true
+		return resp, err
+	})
 }
 
 // convertCourse converts a Classroom Course to our Course type.
@@ -469,7 +1725,19 @@ func convertCourse(c *classroom.Course) *Course {
 		CourseState:    c.CourseState,
 		TimeCreated:    c.CreationTime,
 		UpdateTime:     c.UpdateTime,
+		CalendarID:     c.CalendarId,
+		AlternateLink:  c.AlternateLink,
+		TeacherFolder:  convertDriveFolder(c.TeacherFolder),
+	}
+}
+
+// convertDriveFolder converts a Classroom DriveFolder, or returns nil when
+// there is none.
+func convertDriveFolder(f *classroom.DriveFolder) *DriveFolder {
+	if f == nil || f.Id == "" {
+		return nil
 	}
+	return &DriveFolder{ID: f.Id, Title: f.Title, AlternateLink: f.AlternateLink}
 }
 
 // convertCourseWork converts a Classroom CourseWork to our CourseWork type.
@@ -485,8 +1753,90 @@ func convertCourseWork(cw *classroom.CourseWork) *CourseWork {
 		DueTime:       formatTime(cw.DueTime),
 		MaxPoints:     int(cw.MaxPoints),
 		CreatorUserID: cw.CreatorUserId,
+		CreateTime:    cw.CreationTime,
 		UpdateTime:    cw.UpdateTime,
+		Materials:     convertMaterials(cw.Materials),
+		Choices:       questionChoices(cw),
+		WorkFolder:    workFolder(cw),
+		AlternateLink: cw.AlternateLink,
+		AssigneeMode:  cw.AssigneeMode,
+		StudentIDs:    individualStudents(cw.IndividualStudentsOptions),
+		ScheduledTime: cw.ScheduledTime,
+		TopicID:       cw.TopicId,
+	}
+}
+
+// individualStudents returns the students an item is assigned to in
+// individual mode.
+func individualStudents(o *classroom.IndividualStudentsOptions) []string {
+	if o == nil {
+		return nil
+	}
+	return o.StudentIds
+}
+
+// questionChoices returns the options of a multiple-choice question.
+func questionChoices(cw *classroom.CourseWork) []string {
+	if cw.MultipleChoiceQuestion == nil {
+		return nil
+	}
+	return cw.MultipleChoiceQuestion.Choices
+}
+
+// workFolder returns the Drive folder of an assignment's submitted files.
+func workFolder(cw *classroom.CourseWork) *Attachment {
+	if cw.Assignment == nil || cw.Assignment.StudentWorkFolder == nil {
+		return nil
 	}
+	f := cw.Assignment.StudentWorkFolder
+	return &Attachment{Kind: AttachmentFolder, ID: f.Id, Title: f.Title, URL: f.AlternateLink}
+}
+
+// convertMaterials converts coursework materials to attachments. Kinds the
+// app does not handle are skipped.
+func convertMaterials(materials []*classroom.Material) []Attachment {
+	var out []Attachment
+	for _, m := range materials {
+		switch {
+		case m.DriveFile != nil && m.DriveFile.DriveFile != nil:
+			out = append(out, convertDriveFile(m.DriveFile.DriveFile))
+		case m.Link != nil:
+			out = append(out, Attachment{Kind: AttachmentLink, Title: m.Link.Title, URL: m.Link.Url})
+		case m.YoutubeVideo != nil:
+			out = append(out, convertYouTubeVideo(m.YoutubeVideo))
+		case m.Form != nil:
+			out = append(out, Attachment{Kind: AttachmentForm, Title: m.Form.Title, URL: m.Form.FormUrl})
+		}
+	}
+	return out
+}
+
+// convertAttachments converts submission attachments.
+func convertAttachments(attachments []*classroom.Attachment) []Attachment {
+	var out []Attachment
+	for _, a := range attachments {
+		switch {
+		case a.DriveFile != nil:
+			out = append(out, convertDriveFile(a.DriveFile))
+		case a.Link != nil:
+			out = append(out, Attachment{Kind: AttachmentLink, Title: a.Link.Title, URL: a.Link.Url})
+		case a.YouTubeVideo != nil:
+			out = append(out, convertYouTubeVideo(a.YouTubeVideo))
+		case a.Form != nil:
+			out = append(out, Attachment{Kind: AttachmentForm, Title: a.Form.Title, URL: a.Form.FormUrl})
+		}
+	}
+	return out
+}
+
+// convertDriveFile converts a Drive file reference.
+func convertDriveFile(f *classroom.DriveFile) Attachment {
+	return Attachment{Kind: AttachmentDriveFile, ID: f.Id, Title: f.Title, URL: f.AlternateLink}
+}
+
+// convertYouTubeVideo converts a YouTube video reference.
+func convertYouTubeVideo(v *classroom.YouTubeVideo) Attachment {
+	return Attachment{Kind: AttachmentYouTube, ID: v.Id, Title: v.Title, URL: v.AlternateLink}
 }
 
 // convertSubmission converts a Classroom StudentSubmission to our type.
@@ -502,9 +1852,140 @@ func convertSubmission(s *classroom.StudentSubmission) *StudentSubmission {
 		Late:          s.Late,
 		CreateTime:    s.CreationTime,
 		UpdateTime:    s.UpdateTime,
+		Attachments:   submissionAttachments(s),
+		Answer:        submissionAnswer(s),
+		AlternateLink: s.AlternateLink,
+		History:       convertHistory(s.SubmissionHistory),
+
+		HasAssignedGrade: hasAssignedGrade(s),
+
+		AssignedRubricGrades: convertRubricGrades(s.AssignedRubricGrades),
+		DraftRubricGrades:    convertRubricGrades(s.DraftRubricGrades),
 	}
 }
 
+// hasAssignedGrade reports whether s has an assigned grade. The API omits
+// assignedGrade both when it is 0 and when there is none, so a 0 counts
+// only when a grade was assigned in the history.
+func hasAssignedGrade(s *classroom.StudentSubmission) bool {
+	if s.AssignedGrade != 0 {
+		return true
+	}
+	for _, h := range s.SubmissionHistory {
+		if h.GradeHistory != nil && h.GradeHistory.GradeChangeType == GradeChangeAssigned {
+			return true
+		}
+	}
+	return false
+}
+
+// convertHistory converts submission history to our type. List calls only
+// ask for the grade change types, for hasAssignedGrade; those events have
+// no time and are left out.
+func convertHistory(history []*classroom.SubmissionHistory) []HistoryEvent {
+	var out []HistoryEvent
+	for _, h := range history {
+		switch {
+		case h.GradeHistory != nil && h.GradeHistory.GradeTimestamp == "":
+			continue
+		case h.StateHistory != nil:
+			out = append(out, HistoryEvent{
+				Time:        h.StateHistory.StateTimestamp,
+				ActorUserID: h.StateHistory.ActorUserId,
+				State:       h.StateHistory.State,
+			})
+		case h.GradeHistory != nil:
+			out = append(out, HistoryEvent{
+				Time:         h.GradeHistory.GradeTimestamp,
+				ActorUserID:  h.GradeHistory.ActorUserId,
+				GradeChange:  h.GradeHistory.GradeChangeType,
+				PointsEarned: h.GradeHistory.PointsEarned,
+				MaxPoints:    h.GradeHistory.MaxPoints,
+			})
+		}
+	}
+	return out
+}
+
+// submissionAnswer returns the answer of a question submission. Other
+// work types have none.
+func submissionAnswer(s *classroom.StudentSubmission) string {
+	switch {
+	case s.ShortAnswerSubmission != nil:
+		return s.ShortAnswerSubmission.Answer
+	case s.MultipleChoiceSubmission != nil:
+		return s.MultipleChoiceSubmission.Answer
+	}
+	return ""
+}
+
+// convertRubricGrades converts per-criterion rubric grades to our type.
+func convertRubricGrades(grades map[string]classroom.RubricGrade) map[string]RubricGrade {
+	if len(grades) == 0 {
+		return nil
+	}
+	out := make(map[string]RubricGrade, len(grades))
+	for id, g := range grades {
+		criterionID := g.CriterionId
+		if criterionID == "" {
+			criterionID = id
+		}
+		out[id] = RubricGrade{CriterionID: criterionID, LevelID: g.LevelId, Points: g.Points}
+	}
+	return out
+}
+
+// convertRubric converts a Classroom Rubric to our type.
+func convertRubric(r *classroom.Rubric) *Rubric {
+	rubric := &Rubric{ID: r.Id, CourseID: r.CourseId, CourseWorkID: r.CourseWorkId}
+	for _, c := range r.Criteria {
+		criterion := Criterion{ID: c.Id, Title: c.Title, Description: c.Description}
+		for _, l := range c.Levels {
+			criterion.Levels = append(criterion.Levels, Level{ID: l.Id, Title: l.Title, Description: l.Description, Points: l.Points})
+		}
+		rubric.Criteria = append(rubric.Criteria, criterion)
+	}
+	return rubric
+}
+
+// convertAddOnAttachment converts a Classroom AddOnAttachment to our type.
+func convertAddOnAttachment(a *classroom.AddOnAttachment) *AddOnAttachment {
+	itemID := a.ItemId
+	if itemID == "" {
+		// Older responses only carry the deprecated postId
+		itemID = a.PostId
+	}
+	return &AddOnAttachment{
+		ID:                   a.Id,
+		CourseID:             a.CourseId,
+		CourseWorkID:         itemID,
+		Title:                a.Title,
+		StudentViewURL:       embedURI(a.StudentViewUri),
+		TeacherViewURL:       embedURI(a.TeacherViewUri),
+		StudentWorkReviewURL: embedURI(a.StudentWorkReviewUri),
+		MaxPoints:            a.MaxPoints,
+		DueDate:              formatDate(a.DueDate),
+		DueTime:              formatTime(a.DueTime),
+	}
+}
+
+// embedURI returns the URI of an add-on page, or "" when there is none.
+func embedURI(u *classroom.EmbedUri) string {
+	if u == nil {
+		return ""
+	}
+	return u.Uri
+}
+
+// submissionAttachments returns the attachments of an assignment
+// submission. Other work types have none.
+func submissionAttachments(s *classroom.StudentSubmission) []Attachment {
+	if s.AssignmentSubmission == nil {
+		return nil
+	}
+	return convertAttachments(s.AssignmentSubmission.Attachments)
+}
+
 // convertAnnouncement converts a Classroom Announcement to our type.
 func convertAnnouncement(a *classroom.Announcement) *Announcement {
 	return &Announcement{
@@ -515,6 +1996,10 @@ func convertAnnouncement(a *classroom.Announcement) *Announcement {
 		CreatorUserID: a.CreatorUserId,
 		CreateTime:    a.CreationTime,
 		UpdateTime:    a.UpdateTime,
+		AlternateLink: a.AlternateLink,
+		AssigneeMode:  a.AssigneeMode,
+		StudentIDs:    individualStudents(a.IndividualStudentsOptions),
+		ScheduledTime: a.ScheduledTime,
 	}
 }
 
@@ -536,17 +2021,30 @@ func convertTeacher(t *classroom.Teacher) *Teacher {
 	}
 }
 
+// convertInvitation converts a Classroom Invitation to our type.
+func convertInvitation(i *classroom.Invitation) *Invitation {
+	return &Invitation{
+		ID:       i.Id,
+		CourseID: i.CourseId,
+		UserID:   i.UserId,
+		Role:     i.Role,
+	}
+}
+
 // convertProfile converts a Classroom UserProfile to our type.
 func convertProfile(p *classroom.UserProfile) UserProfile {
 	if p == nil {
 		return UserProfile{}
 	}
-	return UserProfile{
+	profile := UserProfile{
 		ID:           p.Id,
-		Name:         p.Name.FullName,
 		EmailAddress: p.EmailAddress,
 		PhotoURL:     p.PhotoUrl,
 	}
+	if p.Name != nil {
+		profile.Name = p.Name.FullName
+	}
+	return profile
 }
 
 // formatDate formats a Classroom Date as a string.
@@ -565,6 +2063,32 @@ func formatTime(t *classroom.TimeOfDay) string {
 	return fmt.Sprintf("%02d:%02d", t.Hours, t.Minutes)
 }
 
+// parseDate parses a date formatted by formatDate. An empty string is no
+// date.
+func parseDate(s string) (*classroom.Date, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	return &classroom.Date{Year: int64(t.Year()), Month: int64(t.Month()), Day: int64(t.Day())}, nil
+}
+
+// parseTime parses a time of day formatted by formatTime. An empty string
+// is no time.
+func parseTime(s string) (*classroom.TimeOfDay, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return &classroom.TimeOfDay{Hours: int64(t.Hour()), Minutes: int64(t.Minute())}, nil
+}
+
 // PrettyPrint prints a value as JSON for debugging.
 func PrettyPrint(v interface{}) {
 	b, _ := json.MarshalIndent(v, "", "  ")
@@ -5,15 +5,19 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"golang.org/x/oauth2"
+	"google.golang.org/api/classroom/v1"
+	"google.golang.org/api/googleapi"
 )
 
-// mockServer creates a mock Classroom API server.
+// mockServer creates a mock Classroom API server. Point a client at it with
+// Configuration.Endpoint set to server.URL.
 func mockServer() *httptest.Server {
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return httptest.NewServer(http.StripPrefix("/v1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/courses":
 			courses := []*Course{
@@ -25,20 +29,23 @@ func mockServer() *httptest.Server {
 			}
 			json.NewEncoder(w).Encode(response)
 		case "/courses/123":
-			course := &Course{ID: "123", Name: "Test Course", Section: "A"}
+			course := &Course{ID: "123", Name: "Test Course", Section: "A", TeacherFolder: &DriveFolder{ID: "f1", Title: "Test Course", AlternateLink: "https://drive.google.com/drive/folders/f1"}}
 			json.NewEncoder(w).Encode(course)
+		case "/userProfiles/me":
+			profile := &classroom.UserProfile{Id: "u1", Name: &classroom.Name{FullName: "Test User"}, EmailAddress: "test@example.edu"}
+			json.NewEncoder(w).Encode(profile)
 		case "/courses/123/courseWork":
-			coursework := []*CourseWork{
-				{ID: "cw1", CourseID: "123", Title: "Assignment 1", WorkType: "ASSIGNMENT", MaxPoints: 100},
+			coursework := []*classroom.CourseWork{
+				{Id: "cw1", CourseId: "123", Title: "Assignment 1", WorkType: "ASSIGNMENT", MaxPoints: 100},
 			}
-			response := ListCourseWorkResponse{
+			response := classroom.ListCourseWorkResponse{
 				CourseWork: coursework,
 			}
 			json.NewEncoder(w).Encode(response)
 		default:
 			http.NotFound(w, r)
 		}
-	}))
+	})))
 }
 
 // mockTokenSource creates a mock token source.
@@ -91,14 +98,14 @@ func TestListCourses(t *testing.T) {
 	}
 
 	ts := &mockTokenSource{token: token}
-	cfg := &Configuration{}
+	cfg := &Configuration{Endpoint: server.URL + "/"}
 
 	client, err := NewClient(context.Background(), ts, cfg)
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	courses, err := client.ListCourses(context.Background())
+	courses, err := client.ListCourses(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("Failed to list courses: %v", err)
 	}
@@ -121,7 +128,7 @@ func TestGetCourse(t *testing.T) {
 	}
 
 	ts := &mockTokenSource{token: token}
-	cfg := &Configuration{}
+	cfg := &Configuration{Endpoint: server.URL + "/"}
 
 	client, err := NewClient(context.Background(), ts, cfg)
 	if err != nil {
@@ -140,6 +147,35 @@ func TestGetCourse(t *testing.T) {
 	if course.Name != "Test Course" {
 		t.Errorf("Expected course name 'Test Course', got '%s'", course.Name)
 	}
+
+	if course.TeacherFolder == nil || course.TeacherFolder.AlternateLink != "https://drive.google.com/drive/folders/f1" {
+		t.Errorf("Expected the course's Drive folder, got %+v", course.TeacherFolder)
+	}
+}
+
+// TestGetUserProfile tests getting the current user's profile.
+func TestGetUserProfile(t *testing.T) {
+	server := mockServer()
+	defer server.Close()
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token", Expiry: time.Now().Add(time.Hour)}}
+	client, err := NewClient(context.Background(), ts, &Configuration{Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	profile, err := client.GetUserProfile(context.Background(), "me")
+	if err != nil {
+		t.Fatalf("Failed to get profile: %v", err)
+	}
+	want := UserProfile{ID: "u1", Name: "Test User", EmailAddress: "test@example.edu"}
+	if *profile != want {
+		t.Errorf("Expected %+v, got %+v", want, *profile)
+	}
+
+	if _, err := client.GetUserProfile(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
 }
 
 // TestListCourseWork tests listing coursework.
@@ -155,14 +191,14 @@ func TestListCourseWork(t *testing.T) {
 	}
 
 	ts := &mockTokenSource{token: token}
-	cfg := &Configuration{}
+	cfg := &Configuration{Endpoint: server.URL + "/"}
 
 	client, err := NewClient(context.Background(), ts, cfg)
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	coursework, err := client.ListCourseWork(context.Background(), "123")
+	coursework, err := client.ListCourseWork(context.Background(), "123", nil)
 	if err != nil {
 		t.Fatalf("Failed to list coursework: %v", err)
 	}
@@ -176,9 +212,455 @@ func TestListCourseWork(t *testing.T) {
 	}
 }
 
+// TestPageSize tests applying the configured page sizes to list calls.
+func TestPageSize(t *testing.T) {
+	var sizes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sizes = append(sizes, r.URL.Query().Get("pageSize"))
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	client, err := NewClient(context.Background(), ts, &Configuration{
+		Endpoint:  server.URL + "/",
+		PageSize:  50,
+		PageSizes: map[string]int{PageSubmissions: 200},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.ListCourses(ctx, nil); err != nil {
+		t.Fatalf("Failed to list courses: %v", err)
+	}
+	if _, err := client.ListStudentSubmissions(ctx, "123", "cw1", nil); err != nil {
+		t.Fatalf("Failed to list submissions: %v", err)
+	}
+	if _, err := client.ListStudents(ctx, "123", &ListRosterOptions{PageSize: 10}); err != nil {
+		t.Fatalf("Failed to list students: %v", err)
+	}
+
+	want := []string{"50", "200", "10"}
+	if len(sizes) != len(want) {
+		t.Fatalf("Expected %d requests, got %d", len(want), len(sizes))
+	}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Errorf("Request %d: expected pageSize %s, got %q", i, want[i], sizes[i])
+		}
+	}
+}
+
+// TestNewTransport tests building a tuned shared transport.
+func TestNewTransport(t *testing.T) {
+	transport := NewTransport(&Configuration{
+		MaxIdleConnsPerHost: 4,
+		DisableCompression:  true,
+		DisableHTTP2:        true,
+	})
+
+	if transport.MaxIdleConnsPerHost != 4 {
+		t.Errorf("Expected 4 idle conns per host, got %d", transport.MaxIdleConnsPerHost)
+	}
+	if transport.MaxIdleConns != DefaultConfiguration().MaxIdleConns {
+		t.Errorf("Expected default max idle conns, got %d", transport.MaxIdleConns)
+	}
+	if !transport.DisableCompression {
+		t.Error("Expected compression to be disabled")
+	}
+	if transport.ForceAttemptHTTP2 {
+		t.Error("Expected HTTP/2 to be disabled")
+	}
+
+	client, err := NewClient(context.Background(), &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}, &Configuration{Transport: transport})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if client.Transport() != transport {
+		t.Error("Expected client to use the shared transport")
+	}
+}
+
+// TestNewTransportProxyAndTimeouts tests the proxy and connection timeout
+// settings.
+func TestNewTransportProxyAndTimeouts(t *testing.T) {
+	transport := NewTransport(&Configuration{
+		Proxy:                 "proxy.example.com:3128",
+		TLSHandshakeTimeout:   3 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+	})
+
+	if transport.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("Expected 3s TLS handshake timeout, got %v", transport.TLSHandshakeTimeout)
+	}
+	if transport.ResponseHeaderTimeout != 20*time.Second {
+		t.Errorf("Expected 20s response header timeout, got %v", transport.ResponseHeaderTimeout)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://classroom.googleapis.com/v1/courses", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("Proxy failed: %v", err)
+	}
+	if proxy == nil || proxy.String() != "http://proxy.example.com:3128" {
+		t.Errorf("Expected http://proxy.example.com:3128, got %v", proxy)
+	}
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	if _, err := NewClient(context.Background(), ts, &Configuration{Proxy: "http://"}); err == nil {
+		t.Error("Expected error for a proxy URL without a host")
+	}
+}
+
+// TestClientTimeout tests that Timeout bounds Classroom requests.
+func TestClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	client, err := NewClient(context.Background(), ts, &Configuration{
+		Endpoint:   server.URL + "/",
+		Timeout:    50 * time.Millisecond,
+		MaxRetries: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.GetCourse(context.Background(), "123"); err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected request to time out quickly, took %v", elapsed)
+	}
+}
+
+// TestContextCancels tests that a list stops waiting for the API once its
+// context is done.
+func TestContextCancels(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	client, err := NewClient(context.Background(), ts, &Configuration{Endpoint: server.URL + "/", MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.ListCourses(ctx, nil); err == nil {
+		t.Fatal("Expected the list to fail once its context is done")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the list to stop with its context, took %v", elapsed)
+	}
+}
+
+// TestWriteNotRetried tests that a write failing with 503 is sent once,
+// since the server may have made the change anyway, while a read is
+// retried.
+func TestWriteNotRetried(t *testing.T) {
+	var writes, reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			reads.Add(1)
+		} else {
+			writes.Add(1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	client, err := NewClient(context.Background(), ts, &Configuration{
+		Endpoint:         server.URL + "/",
+		RateLimitBackoff: time.Millisecond,
+		MaxRetries:       3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	for name, write := range map[string]func() error{
+		"create": func() error {
+			_, err := client.CreateCourseWork(ctx, "123", &CourseWork{Title: "Check-in"})
+			return err
+		},
+		"turn in": func() error { return client.TurnIn(ctx, "123", "cw1", "s1") },
+		"delete":  func() error { return client.DeleteAnnouncement(ctx, "123", "a1") },
+	} {
+		writes.Store(0)
+		if err := write(); err == nil {
+			t.Fatalf("%s: expected it to fail", name)
+		}
+		if n := writes.Load(); n != 1 {
+			t.Errorf("%s: expected it to be sent once, got %d", name, n)
+		}
+	}
+
+	if _, err := client.GetCourse(ctx, "123"); err == nil {
+		t.Fatal("Expected get to fail")
+	}
+	if n := reads.Load(); n != 3 {
+		t.Errorf("Expected the get to be tried 3 times, got %d", n)
+	}
+}
+
+// TestVisibleCourseWork tests the coursework visibility rules.
+func TestVisibleCourseWork(t *testing.T) {
+	items := []*CourseWork{
+		{ID: "published", State: CourseWorkStatePublished},
+		{ID: "draft", State: CourseWorkStateDraft},
+		{ID: "deleted", State: CourseWorkStateDeleted},
+	}
+
+	ids := func(cws []*CourseWork) []string {
+		var out []string
+		for _, cw := range cws {
+			out = append(out, cw.ID)
+		}
+		return out
+	}
+
+	if got := ids(VisibleCourseWork(items, false, false)); len(got) != 1 || got[0] != "published" {
+		t.Errorf("Expected students to see only published work, got %v", got)
+	}
+	if got := ids(VisibleCourseWork(items, true, false)); len(got) != 2 || got[1] != "draft" {
+		t.Errorf("Expected teachers to see drafts, got %v", got)
+	}
+	if got := ids(VisibleCourseWork(items, true, true)); len(got) != 3 {
+		t.Errorf("Expected tombstones when enabled, got %v", got)
+	}
+}
+
+// TestSelectFields tests choosing partial response fields.
+func TestSelectFields(t *testing.T) {
+	if got := selectFields(nil, coursesListFields); len(got) != 1 || got[0] != coursesListFields {
+		t.Errorf("Expected default fields, got %v", got)
+	}
+	custom := []googleapi.Field{"nextPageToken,courses(id,name)"}
+	if got := selectFields(custom, coursesListFields); len(got) != 1 || got[0] != custom[0] {
+		t.Errorf("Expected requested fields, got %v", got)
+	}
+}
+
+// TestCanTurnIn tests which submission states can be turned in.
+func TestCanTurnIn(t *testing.T) {
+	tests := map[string]bool{
+		SubmissionStateNew:       true,
+		SubmissionStateCreated:   true,
+		SubmissionStateReclaimed: true,
+		SubmissionStateTurnedIn:  false,
+		SubmissionStateReturned:  false,
+	}
+
+	for state, want := range tests {
+		s := &StudentSubmission{State: state}
+		if got := s.CanTurnIn(); got != want {
+			t.Errorf("CanTurnIn() for %s = %v, expected %v", state, got, want)
+		}
+	}
+}
+
 // TestConvertCourse tests course conversion.
 func TestConvertCourse(t *testing.T) {
 	// This would test the internal conversion functions
 	// For now, we'll skip detailed tests as they require mocking the actual API types
 	t.Skip("Requires detailed API type mocking")
 }
+
+// TestConvertMaterials tests that each kind of material becomes an attachment.
+func TestConvertMaterials(t *testing.T) {
+	materials := []*classroom.Material{
+		{DriveFile: &classroom.SharedDriveFile{DriveFile: &classroom.DriveFile{Id: "f1", Title: "Lab.pdf"}}},
+		{Link: &classroom.Link{Title: "Docs", Url: "https://example.com"}},
+		{YoutubeVideo: &classroom.YouTubeVideo{Id: "v1", Title: "Lecture"}},
+		{Form: &classroom.Form{Title: "Quiz", FormUrl: "https://forms.example.com"}},
+		{},
+	}
+
+	got := convertMaterials(materials)
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 attachments, got %d", len(got))
+	}
+	kinds := []string{AttachmentDriveFile, AttachmentLink, AttachmentYouTube, AttachmentForm}
+	for i, kind := range kinds {
+		if got[i].Kind != kind {
+			t.Errorf("Expected attachment %d to be %s, got %s", i, kind, got[i].Kind)
+		}
+	}
+	if got[0].ID != "f1" || got[1].URL != "https://example.com" {
+		t.Errorf("Expected IDs and URLs to be kept, got %+v", got[:2])
+	}
+}
+
+// TestConvertRubric tests converting a rubric and scoring rubric grades.
+func TestConvertRubric(t *testing.T) {
+	r := convertRubric(&classroom.Rubric{Id: "r1", CourseId: "c1", CourseWorkId: "cw1", Criteria: []*classroom.Criterion{
+		{Id: "a", Title: "Thesis", Levels: []*classroom.Level{{Id: "a1", Title: "Strong", Points: 10}, {Id: "a2", Title: "Weak", Points: 4}}},
+		{Id: "b", Title: "Evidence", Levels: []*classroom.Level{{Id: "b1", Points: 5}, {Id: "b2", Points: 2.5}}},
+	}})
+
+	if len(r.Criteria) != 2 || len(r.Criteria[1].Levels) != 2 {
+		t.Fatalf("Expected 2 criteria with 2 levels, got %+v", r.Criteria)
+	}
+	if r.MaxPoints() != 15 {
+		t.Errorf("Expected max points 15, got %v", r.MaxPoints())
+	}
+	if l, ok := r.Criteria[0].Level("a2"); !ok || l.Points != 4 {
+		t.Errorf("Expected level a2 with 4 points, got %+v, %v", l, ok)
+	}
+
+	sub := convertSubmission(&classroom.StudentSubmission{Id: "s1", DraftRubricGrades: map[string]classroom.RubricGrade{
+		"a": {CriterionId: "a", LevelId: "a2", Points: 4},
+		"b": {CriterionId: "b", LevelId: "b2", Points: 2.5},
+	}})
+	if sub.AssignedRubricGrades != nil {
+		t.Errorf("Expected no assigned rubric grades, got %v", sub.AssignedRubricGrades)
+	}
+	if score, ok := r.Score(sub.DraftRubricGrades); !ok || score != 6.5 {
+		t.Errorf("Expected draft score 6.5, got %v, %v", score, ok)
+	}
+	delete(sub.DraftRubricGrades, "b")
+	if _, ok := r.Score(sub.DraftRubricGrades); ok {
+		t.Error("Expected an incomplete rubric not to score")
+	}
+}
+
+// TestConvertQuestions tests that question choices, answers, and the work
+// folder are kept.
+func TestConvertQuestions(t *testing.T) {
+	cw := convertCourseWork(&classroom.CourseWork{
+		Id:                     "cw1",
+		WorkType:               WorkTypeMultipleChoice,
+		MultipleChoiceQuestion: &classroom.MultipleChoiceQuestion{Choices: []string{"A", "B"}},
+	})
+	if !cw.IsQuestion() || len(cw.Choices) != 2 || cw.Choices[1] != "B" {
+		t.Errorf("Expected a question with 2 choices, got %+v", cw)
+	}
+
+	cw = convertCourseWork(&classroom.CourseWork{
+		Id:         "cw2",
+		WorkType:   WorkTypeAssignment,
+		Assignment: &classroom.Assignment{StudentWorkFolder: &classroom.DriveFolder{Id: "d1", Title: "Lab (responses)", AlternateLink: "https://drive.example.com/d1"}},
+	})
+	if cw.IsQuestion() {
+		t.Error("Expected an assignment not to be a question")
+	}
+	if cw.WorkFolder == nil || cw.WorkFolder.Kind != AttachmentFolder || cw.WorkFolder.ID != "d1" {
+		t.Errorf("Expected the work folder to be kept, got %+v", cw.WorkFolder)
+	}
+
+	sub := convertSubmission(&classroom.StudentSubmission{Id: "s1", MultipleChoiceSubmission: &classroom.MultipleChoiceSubmission{Answer: "B"}})
+	if sub.Answer != "B" {
+		t.Errorf("Expected multiple-choice answer B, got %q", sub.Answer)
+	}
+	sub = convertSubmission(&classroom.StudentSubmission{Id: "s2", ShortAnswerSubmission: &classroom.ShortAnswerSubmission{Answer: "Mitochondria"}})
+	if sub.Answer != "Mitochondria" {
+		t.Errorf("Expected short answer Mitochondria, got %q", sub.Answer)
+	}
+}
+
+// TestConvertAlternateLinks tests that every entity keeps its Classroom link.
+func TestConvertAlternateLinks(t *testing.T) {
+	links := map[string]string{
+		"course":       convertCourse(&classroom.Course{AlternateLink: "https://classroom.example.com/c/1"}).AlternateLink,
+		"coursework":   convertCourseWork(&classroom.CourseWork{AlternateLink: "https://classroom.example.com/c/1/a/2"}).AlternateLink,
+		"submission":   convertSubmission(&classroom.StudentSubmission{AlternateLink: "https://classroom.example.com/c/1/a/2/s/3"}).AlternateLink,
+		"announcement": convertAnnouncement(&classroom.Announcement{AlternateLink: "https://classroom.example.com/c/1/p/4"}).AlternateLink,
+	}
+	for kind, link := range links {
+		if link == "" {
+			t.Errorf("Expected the %s link to be kept", kind)
+		}
+	}
+}
+
+// TestConvertHistory tests that state and grade changes are kept in order.
+func TestConvertHistory(t *testing.T) {
+	sub := convertSubmission(&classroom.StudentSubmission{Id: "s1", SubmissionHistory: []*classroom.SubmissionHistory{
+		{StateHistory: &classroom.StateHistory{State: SubmissionStateTurnedIn, StateTimestamp: "2026-03-01T10:00:00Z", ActorUserId: "u1"}},
+		{GradeHistory: &classroom.GradeHistory{GradeChangeType: GradeChangeDraft, GradeTimestamp: "2026-03-02T10:00:00Z", ActorUserId: "t1", PointsEarned: 8, MaxPoints: 10}},
+		{},
+	}})
+
+	if len(sub.History) != 2 {
+		t.Fatalf("Expected 2 history events, got %+v", sub.History)
+	}
+	if sub.History[0].State != SubmissionStateTurnedIn || sub.History[0].ActorUserID != "u1" {
+		t.Errorf("Expected a turn-in by u1, got %+v", sub.History[0])
+	}
+	if g := sub.History[1]; g.GradeChange != GradeChangeDraft || g.PointsEarned != 8 || g.MaxPoints != 10 {
+		t.Errorf("Expected a draft grade of 8/10, got %+v", g)
+	}
+}
+
+// TestConvertAssignees tests that individual assignees are kept.
+func TestConvertAssignees(t *testing.T) {
+	cw := convertCourseWork(&classroom.CourseWork{
+		AssigneeMode:              AssigneeModeIndividual,
+		IndividualStudentsOptions: &classroom.IndividualStudentsOptions{StudentIds: []string{"s1", "s2"}},
+	})
+	if !cw.IsIndividual() || len(cw.StudentIDs) != 2 {
+		t.Errorf("Expected work assigned to 2 students, got %q %v", cw.AssigneeMode, cw.StudentIDs)
+	}
+
+	a := convertAnnouncement(&classroom.Announcement{AssigneeMode: AssigneeModeAll})
+	if a.IsIndividual() || a.StudentIDs != nil {
+		t.Errorf("Expected an announcement to the whole class, got %q %v", a.AssigneeMode, a.StudentIDs)
+	}
+}
+
+// TestListAddOnAttachments tests listing add-on attachments across pages.
+func TestListAddOnAttachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/courses/c1/courseWork/cw1/addOnAttachments" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"addOnAttachments": [{"id": "a1", "courseId": "c1", "itemId": "cw1", "title": "Microscope", "studentViewUri": {"uri": "https://example.com/student"}, "maxPoints": 20, "dueDate": {"year": 2024, "month": 3, "day": 5}}], "nextPageToken": "p2"}`))
+			return
+		}
+		w.Write([]byte(`{"addOnAttachments": [{"id": "a2", "courseId": "c1", "postId": "cw1", "title": "Flashcards"}]}`))
+	}))
+	defer server.Close()
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	client, err := NewClient(context.Background(), ts, &Configuration{Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	addOns, err := client.ListAddOnAttachments(context.Background(), "c1", "cw1")
+	if err != nil {
+		t.Fatalf("Failed to list add-on attachments: %v", err)
+	}
+	if len(addOns) != 2 {
+		t.Fatalf("Expected 2 add-on attachments, got %d", len(addOns))
+	}
+	first := addOns[0]
+	if first.StudentViewURL != "https://example.com/student" || first.MaxPoints != 20 || first.DueDate != "2024-03-05" {
+		t.Errorf("Unexpected first attachment: %+v", first)
+	}
+	if addOns[1].CourseWorkID != "cw1" {
+		t.Errorf("Expected coursework ID from postId, got %q", addOns[1].CourseWorkID)
+	}
+}
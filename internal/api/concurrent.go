@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// Page requests within a single listing are chained by page tokens, so they
+// stay sequential. Listings that span several courses are independent and
+// run on a bounded worker pool instead.
+
+// MaxConcurrency returns how many per-course requests may run at once.
+func (c *Client) MaxConcurrency() int {
+	if c.maxConcurrency < 1 {
+		return 1
+	}
+	return c.maxConcurrency
+}
+
+// ListCourseWorkForCourses lists coursework for each course concurrently.
+// Results are in the same order as courseIDs. opts may be nil.
+func (c *Client) ListCourseWorkForCourses(ctx context.Context, courseIDs []string, opts *ListCourseWorkOptions) ([][]*CourseWork, error) {
+	return parallel.Map(ctx, c.MaxConcurrency(), courseIDs, func(ctx context.Context, courseID string) ([]*CourseWork, error) {
+		return c.ListCourseWork(ctx, courseID, opts)
+	})
+}
+
+// ListAnnouncementsForCourses lists announcements for each course
+// concurrently. Results are in the same order as courseIDs. opts may be nil.
+func (c *Client) ListAnnouncementsForCourses(ctx context.Context, courseIDs []string, opts *ListAnnouncementsOptions) ([][]*Announcement, error) {
+	return parallel.Map(ctx, c.MaxConcurrency(), courseIDs, func(ctx context.Context, courseID string) ([]*Announcement, error) {
+		return c.ListAnnouncements(ctx, courseID, opts)
+	})
+}
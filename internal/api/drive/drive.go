@@ -0,0 +1,247 @@
+// Package drive downloads Classroom attachments from Google Drive, sharing
+// the Classroom client's authenticated HTTP client.
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// googleAppsPrefix starts the MIME type of Docs, Sheets, Slides, and other
+// files that only exist inside Google and must be exported instead.
+const googleAppsPrefix = "application/vnd.google-apps."
+
+// ErrGoogleFormat means the file is a Google Docs, Sheets, or Slides file,
+// which has no content to download and must be exported instead.
+var ErrGoogleFormat = errors.New("file is in a Google format and must be exported")
+
+// Configuration holds Drive client settings.
+type Configuration struct {
+	// HTTPClient makes authenticated requests, usually
+	// (*api.Client).HTTPClient().
+	HTTPClient *http.Client
+	// Endpoint overrides the Drive API base URL, for tests.
+	Endpoint string
+	// UploadChunkSize is how many bytes each resumable upload request
+	// sends. Zero means DefaultUploadChunkSize.
+	UploadChunkSize int
+	// ExportFormat makes Download and DownloadAll export Google Docs,
+	// Sheets, and Slides in this format (FormatPDF, FormatMarkdown, or
+	// FormatText). Empty means they fail with ErrGoogleFormat.
+	ExportFormat string
+	// Retry retries file lookups, downloads, and exports that fail with a
+	// rate limit, a server error, or a network failure. It is required.
+	Retry *backoff.Policy
+}
+
+// Client downloads files from Drive and uploads files to attach to
+// submissions.
+type Client struct {
+	service      *drive.Service
+	chunkSize    int
+	exportFormat string
+	retry        *backoff.Policy
+}
+
+// New creates a Drive client.
+func New(ctx context.Context, cfg *Configuration) (*Client, error) {
+	if cfg == nil || cfg.HTTPClient == nil {
+		return nil, errors.New("drive client needs an authenticated HTTP client")
+	}
+	if cfg.Retry == nil {
+		return nil, errors.New("drive client needs a retry policy")
+	}
+	if cfg.ExportFormat != "" && !ValidFormat(cfg.ExportFormat) {
+		return nil, fmt.Errorf("unknown export format %q", cfg.ExportFormat)
+	}
+	opts := []option.ClientOption{option.WithHTTPClient(cfg.HTTPClient)}
+	if cfg.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+	}
+	service, err := drive.NewService(ctx, opts...)
+	if err != nil {
+		return nil, api.WrapError(err, "failed to create drive service")
+	}
+	chunkSize := cfg.UploadChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultUploadChunkSize
+	}
+	return &Client{
+		service:      service,
+		chunkSize:    chunkSize,
+		exportFormat: cfg.ExportFormat,
+		retry:        api.ReadRetryPolicy(cfg.Retry),
+	}, nil
+}
+
+// Progress reports how much of a file has been downloaded or uploaded.
+type Progress struct {
+	FileID string
+	Name   string
+	// Done and Total are in bytes. Total is 0 when the size is unknown.
+	Done  int64
+	Total int64
+	// Index and Count place the file within a multi-file download.
+	Index int
+	Count int
+}
+
+// Download saves the Drive file to dir under its Drive name, adding " (n)"
+// when a file of that name already exists, and returns the path written.
+// progress, if set, is called as data arrives.
+func (c *Client) Download(ctx context.Context, fileID, dir string, progress func(Progress)) (string, error) {
+	return c.download(ctx, fileID, dir, Progress{Index: 1, Count: 1}, progress)
+}
+
+// DownloadAll downloads every Drive file among attachments to dir. Links,
+// videos, and forms are skipped. It returns the paths written before any
+// error.
+func (c *Client) DownloadAll(ctx context.Context, attachments []api.Attachment, dir string, progress func(Progress)) ([]string, error) {
+	files := DriveFiles(attachments)
+	var paths []string
+	for i, a := range files {
+		path, err := c.download(ctx, a.ID, dir, Progress{Index: i + 1, Count: len(files)}, progress)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// DriveFiles returns the attachments that are Drive files.
+func DriveFiles(attachments []api.Attachment) []api.Attachment {
+	var files []api.Attachment
+	for _, a := range attachments {
+		if a.Kind == api.AttachmentDriveFile && a.ID != "" {
+			files = append(files, a)
+		}
+	}
+	return files
+}
+
+func (c *Client) download(ctx context.Context, fileID, dir string, p Progress, progress func(Progress)) (string, error) {
+	meta, err := c.metadata(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(meta.MimeType, googleAppsPrefix) {
+		if c.exportFormat != "" {
+			return c.export(ctx, meta, dir, c.exportFormat, p, progress)
+		}
+		return "", apperrors.Wrap(ErrGoogleFormat, apperrors.ErrAPI, fmt.Sprintf("cannot download %q", meta.Name)).
+			WithSuggestion("Open it in the browser, or export it to a standard format.")
+	}
+
+	resp, err := backoff.Retry(ctx, c.retry, func() (*http.Response, error) {
+		return c.service.Files.Get(fileID).Context(ctx).Download()
+	})
+	if err != nil {
+		return "", api.WrapError(err, fmt.Sprintf("failed to download %q", meta.Name))
+	}
+	defer resp.Body.Close()
+
+	p.FileID, p.Name, p.Total = fileID, meta.Name, meta.Size
+	return save(resp.Body, dir, safeName(meta.Name, fileID), p, progress)
+}
+
+// metadata fetches what download and export need to know about a file.
+func (c *Client) metadata(ctx context.Context, fileID string) (*drive.File, error) {
+	meta, err := backoff.Retry(ctx, c.retry, func() (*drive.File, error) {
+		return c.service.Files.Get(fileID).Fields("id,name,mimeType,size").Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, api.WrapError(err, fmt.Sprintf("failed to get drive file %s", fileID))
+	}
+	return meta, nil
+}
+
+// save writes r to a new file called name in dir, through a .part file so
+// an interrupted download never looks complete.
+func save(r io.Reader, dir, name string, p Progress, progress func(Progress)) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	path := uniquePath(dir, name)
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+
+	w := &progressWriter{w: f, p: p, fn: progress}
+	_, err = io.Copy(w, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to download %q: %w", p.Name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to save %q: %w", p.Name, err)
+	}
+	return path, nil
+}
+
+// safeName makes a Drive file name usable as a local file name.
+func safeName(name, fileID string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		return fileID
+	}
+	return name
+}
+
+// uniquePath returns dir/name, or dir/"base (n).ext" for the first n that
+// does not exist yet.
+func uniquePath(dir, name string) string {
+	path := filepath.Join(dir, name)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, n, ext))
+	}
+}
+
+// progressWriter reports progress as bytes are written.
+type progressWriter struct {
+	w  io.Writer
+	p  Progress
+	fn func(Progress)
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.Done += int64(n)
+	if pw.fn != nil {
+		pw.fn(pw.p)
+	}
+	return n, err
+}
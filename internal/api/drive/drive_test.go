@@ -0,0 +1,262 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+)
+
+// newTestClient serves file metadata and content for the IDs in files. A
+// file with no content is a Google Doc.
+func newTestClient(t *testing.T, files map[string][2]string) *Client {
+	t.Helper()
+	return newExportClient(t, files, "")
+}
+
+// newExportClient is newTestClient with Google files exported in format.
+func newExportClient(t *testing.T, files map[string][2]string, format string) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := filepath.Base(r.URL.Path)
+		exporting := id == "export"
+		if exporting {
+			id = filepath.Base(filepath.Dir(r.URL.Path))
+		}
+		file, ok := files[id]
+		if !ok {
+			http.Error(w, `{"error": {"code": 404, "message": "File not found"}}`, http.StatusNotFound)
+			return
+		}
+		if exporting {
+			w.Write([]byte("exported as " + r.URL.Query().Get("mimeType")))
+			return
+		}
+		if r.URL.Query().Get("alt") == "media" {
+			w.Write([]byte(file[1]))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mimeType := "application/pdf"
+		if file[1] == "" {
+			mimeType = "application/vnd.google-apps.document"
+		}
+		w.Write([]byte(`{"id": "` + id + `", "name": "` + file[0] + `", "mimeType": "` + mimeType + `", "size": "11"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(context.Background(), &Configuration{HTTPClient: server.Client(), Endpoint: server.URL + "/", ExportFormat: format, Retry: backoff.Default()})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+// TestNewNeedsRetry tests that a client is not created without a retry
+// policy.
+func TestNewNeedsRetry(t *testing.T) {
+	if _, err := New(context.Background(), &Configuration{HTTPClient: http.DefaultClient}); err == nil {
+		t.Error("Expected an error without a retry policy")
+	}
+}
+
+// TestDownload tests that a file is saved under its Drive name with progress.
+func TestDownload(t *testing.T) {
+	client := newTestClient(t, map[string][2]string{"f1": {"lab/report.pdf", "hello world"}})
+	dir := t.TempDir()
+
+	var last Progress
+	path, err := client.Download(context.Background(), "f1", dir, func(p Progress) { last = p })
+	if err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	if filepath.Base(path) != "lab_report.pdf" {
+		t.Errorf("Expected sanitized name lab_report.pdf, got %s", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("Expected downloaded content, got %q (%v)", data, err)
+	}
+	if last.Done != 11 || last.Total != 11 {
+		t.Errorf("Expected progress 11/11, got %d/%d", last.Done, last.Total)
+	}
+
+	again, err := client.Download(context.Background(), "f1", dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to download again: %v", err)
+	}
+	if filepath.Base(again) != "lab_report (1).pdf" {
+		t.Errorf("Expected a numbered copy, got %s", filepath.Base(again))
+	}
+}
+
+// TestDownloadRetries tests that a lookup or download turned away by the
+// server is tried again, while a missing file is not.
+func TestDownloadRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every other request is unavailable, so the lookup and the
+		// download each fail once
+		if requests.Add(1)%2 == 1 {
+			http.Error(w, `{"error": {"code": 503, "message": "Backend Error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		if filepath.Base(r.URL.Path) != "f1" {
+			http.Error(w, `{"error": {"code": 404, "message": "File not found"}}`, http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("alt") == "media" {
+			w.Write([]byte("hello world"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "f1", "name": "report.pdf", "mimeType": "application/pdf", "size": "11"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(context.Background(), &Configuration{
+		HTTPClient: server.Client(),
+		Endpoint:   server.URL + "/",
+		Retry:      &backoff.Policy{Initial: time.Millisecond, MaxAttempts: 3},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	path, err := client.Download(context.Background(), "f1", t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("Expected the download to be retried, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello world" {
+		t.Errorf("Expected downloaded content, got %q", data)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("Expected 4 requests, got %d", n)
+	}
+
+	requests.Store(0)
+	if _, err := client.Download(context.Background(), "missing", t.TempDir(), nil); err == nil {
+		t.Fatal("Expected a missing file to fail")
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Expected the missing file to be looked up once after the 503, got %d requests", n)
+	}
+}
+
+// TestDownloadGoogleFormat tests that native Google files are refused.
+func TestDownloadGoogleFormat(t *testing.T) {
+	client := newTestClient(t, map[string][2]string{"doc": {"Notes", ""}})
+	dir := t.TempDir()
+
+	_, err := client.Download(context.Background(), "doc", dir, nil)
+	if !errors.Is(err, ErrGoogleFormat) {
+		t.Errorf("Expected ErrGoogleFormat, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected nothing written, got %d files", len(entries))
+	}
+}
+
+// TestDownloadAll tests that only Drive files are downloaded.
+func TestDownloadAll(t *testing.T) {
+	client := newTestClient(t, map[string][2]string{
+		"f1": {"a.txt", "first file!"},
+		"f2": {"b.txt", "second one!"},
+	})
+	attachments := []api.Attachment{
+		{Kind: api.AttachmentDriveFile, ID: "f1"},
+		{Kind: api.AttachmentLink, URL: "https://example.com"},
+		{Kind: api.AttachmentDriveFile, ID: "f2"},
+	}
+
+	var counts []int
+	paths, err := client.DownloadAll(context.Background(), attachments, t.TempDir(), func(p Progress) {
+		counts = append(counts, p.Count)
+	})
+	if err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("Expected 2 files, got %d", len(paths))
+	}
+	for _, c := range counts {
+		if c != 2 {
+			t.Errorf("Expected progress to report 2 files, got %d", c)
+		}
+	}
+
+	_, err = client.DownloadAll(context.Background(), []api.Attachment{{Kind: api.AttachmentDriveFile, ID: "missing"}}, t.TempDir(), nil)
+	if err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+// TestExport tests exporting a Google Doc, and that other files and
+// unknown formats are refused.
+func TestExport(t *testing.T) {
+	client := newTestClient(t, map[string][2]string{
+		"doc": {"Lab handout", ""},
+		"pdf": {"scan.pdf", "%PDF"},
+	})
+	dir := t.TempDir()
+
+	path, err := client.Export(context.Background(), "doc", dir, FormatMarkdown, nil)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if filepath.Base(path) != "Lab handout.md" {
+		t.Errorf("Expected Lab handout.md, got %s", filepath.Base(path))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "exported as text/markdown" {
+		t.Errorf("Expected a Markdown export, got %q", data)
+	}
+
+	if _, err := client.Export(context.Background(), "pdf", dir, FormatPDF, nil); !errors.Is(err, ErrNotExportable) {
+		t.Errorf("Expected ErrNotExportable for a PDF, got %v", err)
+	}
+	if _, err := client.Export(context.Background(), "doc", dir, "docx", nil); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+}
+
+// TestDownloadExports tests that downloads export Google files when an
+// export format is configured.
+func TestDownloadExports(t *testing.T) {
+	client := newExportClient(t, map[string][2]string{"doc": {"Notes", ""}}, FormatPDF)
+
+	path, err := client.Download(context.Background(), "doc", t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	if filepath.Base(path) != "Notes.pdf" {
+		t.Errorf("Expected Notes.pdf, got %s", filepath.Base(path))
+	}
+}
+
+// TestExportAll tests that only Google files are exported.
+func TestExportAll(t *testing.T) {
+	client := newTestClient(t, map[string][2]string{
+		"doc": {"Handout", ""},
+		"pdf": {"scan.pdf", "%PDF"},
+	})
+	attachments := []api.Attachment{
+		{Kind: api.AttachmentDriveFile, ID: "pdf"},
+		{Kind: api.AttachmentDriveFile, ID: "doc"},
+	}
+
+	paths, err := client.ExportAll(context.Background(), attachments, t.TempDir(), FormatText, nil)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "Handout.txt" {
+		t.Errorf("Expected only Handout.txt, got %v", paths)
+	}
+}
@@ -0,0 +1,130 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+	"google.golang.org/api/drive/v3"
+)
+
+// Export formats for Google Docs, Sheets, Slides, and Drawings.
+const (
+	FormatPDF      = "pdf"
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// Google file MIME types that can be exported.
+const (
+	mimeDocument     = googleAppsPrefix + "document"
+	mimeSpreadsheet  = googleAppsPrefix + "spreadsheet"
+	mimePresentation = googleAppsPrefix + "presentation"
+	mimeDrawing      = googleAppsPrefix + "drawing"
+)
+
+// ErrNotExportable means the file is not a Google file, so it can only be
+// downloaded.
+var ErrNotExportable = errors.New("only Google Docs, Sheets, Slides, and Drawings can be exported")
+
+// exportType is a MIME type Drive can export to and its file extension.
+type exportType struct {
+	mimeType string
+	ext      string
+}
+
+var (
+	exportPDF      = exportType{"application/pdf", ".pdf"}
+	exportMarkdown = exportType{"text/markdown", ".md"}
+	exportText     = exportType{"text/plain", ".txt"}
+	exportCSV      = exportType{"text/csv", ".csv"}
+)
+
+// exportTypes maps a Google file type and format to the type exported.
+// Formats a file type cannot produce fall back to the closest one: Slides
+// have no Markdown export, so they export as text, and Sheets export their
+// first sheet as CSV for both text formats.
+var exportTypes = map[string]map[string]exportType{
+	mimeDocument:     {FormatPDF: exportPDF, FormatMarkdown: exportMarkdown, FormatText: exportText},
+	mimePresentation: {FormatPDF: exportPDF, FormatMarkdown: exportText, FormatText: exportText},
+	mimeSpreadsheet:  {FormatPDF: exportPDF, FormatMarkdown: exportCSV, FormatText: exportCSV},
+	mimeDrawing:      {FormatPDF: exportPDF},
+}
+
+// ValidFormat reports whether format is one of FormatPDF, FormatMarkdown,
+// or FormatText.
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatPDF, FormatMarkdown, FormatText:
+		return true
+	}
+	return false
+}
+
+// Export saves a Google Docs, Sheets, Slides, or Drawings file to dir in
+// format and returns the path written. Other files return an error; use
+// Download for them.
+func (c *Client) Export(ctx context.Context, fileID, dir, format string, progress func(Progress)) (string, error) {
+	meta, err := c.metadata(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	return c.export(ctx, meta, dir, format, Progress{Index: 1, Count: 1}, progress)
+}
+
+// ExportAll exports every Google file among attachments to dir in format,
+// skipping files that can only be downloaded. It returns the paths written
+// before any error.
+func (c *Client) ExportAll(ctx context.Context, attachments []api.Attachment, dir, format string, progress func(Progress)) ([]string, error) {
+	files := DriveFiles(attachments)
+	var paths []string
+	for i, a := range files {
+		meta, err := c.metadata(ctx, a.ID)
+		if err != nil {
+			return paths, err
+		}
+		if _, ok := exportTypes[meta.MimeType]; !ok {
+			continue
+		}
+		path, err := c.export(ctx, meta, dir, format, Progress{Index: i + 1, Count: len(files)}, progress)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (c *Client) export(ctx context.Context, meta *drive.File, dir, format string, p Progress, progress func(Progress)) (string, error) {
+	if !ValidFormat(format) {
+		return "", fmt.Errorf("unknown export format %q: use %s, %s, or %s", format, FormatPDF, FormatMarkdown, FormatText)
+	}
+	types, ok := exportTypes[meta.MimeType]
+	if !ok {
+		return "", fmt.Errorf("cannot export %q: %w", meta.Name, ErrNotExportable)
+	}
+	t, ok := types[format]
+	if !ok {
+		return "", fmt.Errorf("cannot export %q as %s", meta.Name, format)
+	}
+
+	resp, err := backoff.Retry(ctx, c.retry, func() (*http.Response, error) {
+		return c.service.Files.Export(meta.Id, t.mimeType).Context(ctx).Download()
+	})
+	if err != nil {
+		return "", api.WrapError(err, fmt.Sprintf("failed to export %q", meta.Name))
+	}
+	defer resp.Body.Close()
+
+	name := safeName(meta.Name, meta.Id)
+	if !strings.HasSuffix(strings.ToLower(name), t.ext) {
+		name += t.ext
+	}
+	// Drive does not report the size of an export.
+	p.FileID, p.Name, p.Total = meta.Id, meta.Name, 0
+	return save(resp.Body, dir, name, p, progress)
+}
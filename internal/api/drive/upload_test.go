@@ -0,0 +1,193 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+)
+
+// uploadServer accepts multipart and resumable uploads and records what it
+// received.
+type uploadServer struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	types    []string // upload type of each file
+	mimes    []string
+	received bytes.Buffer
+	chunks   int
+}
+
+func newUploadServer(t *testing.T) *uploadServer {
+	t.Helper()
+	s := &uploadServer{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *uploadServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.URL.Path == "/upload/drive/v3/files" && r.URL.Query().Get("uploadType") == "multipart":
+		s.types = append(s.types, "multipart")
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		mr.NextPart() // metadata
+		media, err := mr.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mimes = append(s.mimes, media.Header.Get("Content-Type"))
+		io.Copy(&s.received, media)
+		s.created(w)
+	case r.URL.Path == "/upload/drive/v3/files" && r.URL.Query().Get("uploadType") == "resumable":
+		s.types = append(s.types, "resumable")
+		s.mimes = append(s.mimes, r.Header.Get("X-Upload-Content-Type"))
+		w.Header().Set("Location", s.server.URL+"/session")
+	case r.URL.Path == "/session":
+		s.chunks++
+		io.Copy(&s.received, r.Body)
+		if strings.HasSuffix(r.Header.Get("Content-Range"), "/*") {
+			// The client asks for 200 with an override header instead
+			// of 308, which HTTP clients treat as a redirect.
+			w.Header().Set("X-Http-Status-Code-Override", "308")
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", s.received.Len()-1))
+			return
+		}
+		s.created(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *uploadServer) created(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"id": "up%d", "name": "uploaded", "webViewLink": "https://drive.example.com/up%d"}`, len(s.types), len(s.types))
+}
+
+func (s *uploadServer) client(t *testing.T, chunkSize int) *Client {
+	t.Helper()
+	client, err := New(context.Background(), &Configuration{
+		HTTPClient:      s.server.Client(),
+		Endpoint:        s.server.URL + "/drive/v3/",
+		UploadChunkSize: chunkSize,
+		Retry:           backoff.Default(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// TestUpload tests that a small file is sent in one request with its MIME
+// type.
+func TestUpload(t *testing.T) {
+	s := newUploadServer(t)
+	path := writeFile(t, "essay.pdf", []byte("%PDF-1.4 essay"))
+
+	var last Progress
+	file, err := s.client(t, 0).Upload(context.Background(), path, func(p Progress) { last = p })
+	if err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+	if file.Kind != api.AttachmentDriveFile || file.ID != "up1" {
+		t.Errorf("Expected Drive file up1, got %+v", file)
+	}
+	if s.types[0] != "multipart" || s.mimes[0] != "application/pdf" {
+		t.Errorf("Expected a multipart PDF upload, got %s %s", s.types[0], s.mimes[0])
+	}
+	if s.received.String() != "%PDF-1.4 essay" {
+		t.Errorf("Expected file content to be sent, got %q", s.received.String())
+	}
+	if last.Done != last.Total || last.Name != "essay.pdf" {
+		t.Errorf("Expected final progress for essay.pdf, got %+v", last)
+	}
+}
+
+// TestUploadResumable tests that a file larger than one chunk is sent in
+// resumable chunks, and that a file without an extension is sniffed.
+func TestUploadResumable(t *testing.T) {
+	s := newUploadServer(t)
+	data := bytes.Repeat([]byte("lab notes\n"), 60*1024)
+	path := writeFile(t, "notes", data)
+
+	var updates int
+	_, err := s.client(t, 256*1024).Upload(context.Background(), path, func(Progress) { updates++ })
+	if err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+	if s.types[0] != "resumable" {
+		t.Errorf("Expected a resumable upload, got %s", s.types[0])
+	}
+	if !strings.HasPrefix(s.mimes[0], "text/plain") {
+		t.Errorf("Expected sniffed text/plain, got %s", s.mimes[0])
+	}
+	if s.chunks < 2 {
+		t.Errorf("Expected several chunks, got %d", s.chunks)
+	}
+	if !bytes.Equal(s.received.Bytes(), data) {
+		t.Errorf("Expected %d bytes sent, got %d", len(data), s.received.Len())
+	}
+	if updates < 2 {
+		t.Errorf("Expected progress per chunk, got %d updates", updates)
+	}
+}
+
+// attacher records the files attached to a submission.
+type attacher struct {
+	ids []string
+}
+
+func (a *attacher) ModifyAttachments(ctx context.Context, courseID, courseWorkID, submissionID string, driveFileIDs []string) (*api.StudentSubmission, error) {
+	a.ids = driveFileIDs
+	return &api.StudentSubmission{ID: submissionID}, nil
+}
+
+// TestUploadToSubmission tests that uploaded files are attached together,
+// and that nothing is attached when an upload fails.
+func TestUploadToSubmission(t *testing.T) {
+	s := newUploadServer(t)
+	client := s.client(t, 0)
+	paths := []string{writeFile(t, "a.txt", []byte("a")), writeFile(t, "b.png", []byte("b"))}
+
+	a := &attacher{}
+	if _, err := client.UploadToSubmission(context.Background(), a, "c1", "cw1", "s1", paths, nil); err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+	if strings.Join(a.ids, ",") != "up1,up2" {
+		t.Errorf("Expected both files attached, got %v", a.ids)
+	}
+
+	a = &attacher{}
+	paths = append(paths, filepath.Join(t.TempDir(), "missing.txt"))
+	if _, err := client.UploadToSubmission(context.Background(), a, "c1", "cw1", "s1", paths, nil); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if a.ids != nil {
+		t.Errorf("Expected nothing attached, got %v", a.ids)
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// scopeReasons are 403 error reasons caused by a token missing a scope.
+var scopeReasons = map[string]bool{
+	"insufficientPermissions":         true,
+	"ACCESS_TOKEN_SCOPE_INSUFFICIENT": true,
+}
+
+// wrapError translates an error from the Classroom API into an
+// *apperrors.Error with a type, suggestion, and recoverability, keeping
+// message as context. The original error stays reachable via errors.As.
+func wrapError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		e := apperrors.Wrap(err, appErr.Type, message)
+		e.UserSuggestion = appErr.UserSuggestion
+		e.Recoverable = appErr.Recoverable
+		e.Recovery = appErr.Recovery
+		return e
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return apperrors.Wrap(err, apperrors.ErrAuthRevoked, message).NotRecoverable()
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return wrapAPIError(err, apiErr, message)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apperrors.Wrap(err, apperrors.ErrAPINetwork, message).
+			WithSuggestion("The request timed out. Check your connection and press 'r' to retry.")
+	}
+	if errors.Is(err, context.Canceled) {
+		return apperrors.Wrap(err, apperrors.ErrAPI, message)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return apperrors.Wrap(err, apperrors.ErrAPINetwork, message)
+	}
+
+	return apperrors.Wrap(err, apperrors.ErrAPI, message)
+}
+
+// WrapError translates an error from any Google API client, such as
+// Drive, the same way Classroom errors are translated.
+func WrapError(err error, message string) error {
+	return wrapError(err, message)
+}
+
+// wrapAPIError maps a googleapi.Error by HTTP status and error reason.
+func wrapAPIError(err error, apiErr *googleapi.Error, message string) error {
+	switch {
+	case apiErr.Code == http.StatusUnauthorized:
+		return apperrors.Wrap(err, apperrors.ErrAuthExpired, message).
+			NotRecoverable()
+
+	case isRateLimitError(apiErr):
+		return apperrors.Wrap(err, apperrors.ErrAPIRateLimit, message)
+
+	case apiErr.Code == http.StatusForbidden:
+		e := apperrors.Wrap(err, apperrors.ErrAPIForbidden, message).NotRecoverable()
+		if hasReason(apiErr, scopeReasons) {
+			e.WithSuggestion("Your login has not granted the permission this needs.").
+				WithAction(apperrors.ActionGrantScopes)
+		}
+		return e
+
+	case apiErr.Code == http.StatusNotFound:
+		return apperrors.Wrap(err, apperrors.ErrAPINotFound, message).
+			NotRecoverable()
+
+	case apiErr.Code == http.StatusBadRequest, apiErr.Code == http.StatusConflict:
+		return apperrors.Wrap(err, apperrors.ErrValidation, message).
+			WithSuggestion(apiErr.Message).
+			NotRecoverable()
+
+	case apiErr.Code >= http.StatusInternalServerError:
+		return apperrors.Wrap(err, apperrors.ErrAPIServerError, message)
+
+	default:
+		return apperrors.Wrap(err, apperrors.ErrAPI, message)
+	}
+}
+
+// hasReason reports whether any of the error's reasons is in reasons.
+func hasReason(apiErr *googleapi.Error, reasons map[string]bool) bool {
+	for _, item := range apiErr.Errors {
+		if reasons[item.Reason] {
+			return true
+		}
+	}
+	for _, detail := range apiErr.Details {
+		if info, ok := detail.(map[string]interface{}); ok {
+			if reason, ok := info["reason"].(string); ok && reasons[reason] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"google.golang.org/api/googleapi"
+)
+
+// TestWrapError tests translating API errors into application errors.
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		want        apperrors.ErrorType
+		recoverable bool
+	}{
+		{"unauthorized", &googleapi.Error{Code: 401}, apperrors.ErrAuthExpired, false},
+		{"rate limited", &googleapi.Error{Code: 429}, apperrors.ErrAPIRateLimit, true},
+		{"quota", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, apperrors.ErrAPIRateLimit, true},
+		{"forbidden", &googleapi.Error{Code: 403}, apperrors.ErrAPIForbidden, false},
+		{"not found", &googleapi.Error{Code: 404}, apperrors.ErrAPINotFound, false},
+		{"bad request", &googleapi.Error{Code: 400, Message: "Invalid due date"}, apperrors.ErrValidation, false},
+		{"server error", &googleapi.Error{Code: 503}, apperrors.ErrAPIServerError, true},
+		{"timeout", context.DeadlineExceeded, apperrors.ErrAPINetwork, true},
+		{"other", errors.New("boom"), apperrors.ErrAPI, true},
+	}
+
+	for _, tt := range tests {
+		err := wrapError(fmt.Errorf("wrapped: %w", tt.err), "failed to list courses")
+
+		appErr, ok := apperrors.As(err)
+		if !ok {
+			t.Fatalf("%s: Expected *errors.Error, got %T", tt.name, err)
+		}
+		if appErr.Type != tt.want {
+			t.Errorf("%s: Expected type %d, got %d", tt.name, tt.want, appErr.Type)
+		}
+		if appErr.Recoverable != tt.recoverable {
+			t.Errorf("%s: Expected recoverable %v, got %v", tt.name, tt.recoverable, appErr.Recoverable)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: Expected original error to be reachable", tt.name)
+		}
+	}
+
+	if wrapError(nil, "failed") != nil {
+		t.Error("Expected nil for nil error")
+	}
+}
+
+// TestWrapErrorSuggestions tests suggestions derived from the API response.
+func TestWrapErrorSuggestions(t *testing.T) {
+	err := wrapError(&googleapi.Error{
+		Code:   403,
+		Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}},
+	}, "failed to list students")
+	appErr, _ := apperrors.As(err)
+	if appErr == nil || !strings.Contains(appErr.GetSuggestion(), "permission") {
+		t.Errorf("Expected scope suggestion, got %v", err)
+	}
+	if appErr, _ := apperrors.As(wrapError(err, "failed to load course")); appErr == nil || appErr.Action() != apperrors.ActionGrantScopes {
+		t.Errorf("Expected the grant scopes action to survive wrapping, got %v", err)
+	}
+
+	err = wrapError(&googleapi.Error{Code: 400, Message: "Invalid due date"}, "failed to create coursework")
+	appErr, _ = apperrors.As(err)
+	if appErr == nil || appErr.GetSuggestion() != "Invalid due date" {
+		t.Errorf("Expected API message as suggestion, got %v", err)
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 400 {
+		t.Error("Expected googleapi.Error to be reachable")
+	}
+}
+
+// TestWrapErrorKeepsType tests that wrapping twice keeps the inner type.
+func TestWrapErrorKeepsType(t *testing.T) {
+	inner := wrapError(&googleapi.Error{Code: 404}, "failed to get course")
+	outer := wrapError(inner, "failed to load course")
+
+	if !apperrors.IsNotFoundError(outer) {
+		t.Errorf("Expected not found error, got %v", outer)
+	}
+	if apperrors.IsRecoverable(outer) {
+		t.Error("Expected not found error to be unrecoverable")
+	}
+}
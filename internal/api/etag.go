@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// defaultETagEntries bounds how many responses ETagCache keeps.
+const defaultETagEntries = 1000
+
+// maxETagBody is the largest response ETagCache keeps. Larger ones, and
+// anything that is not JSON, such as Drive downloads sharing the
+// transport, stream through untouched.
+const maxETagBody = 1 << 20
+
+// etagEntry is a response body kept alongside the ETag it was served with.
+type etagEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// etagStore remembers the last successful response for each URL.
+type etagStore struct {
+	mu      sync.Mutex
+	entries map[string]*etagEntry
+	max     int
+}
+
+func (s *etagStore) get(url string) (*etagEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[url]
+	return e, ok
+}
+
+func (s *etagStore) put(url string, e *etagEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[url]; !ok && len(s.entries) >= s.max {
+		// Evict an arbitrary entry; a miss only costs a full response.
+		for k := range s.entries {
+			delete(s.entries, k)
+			break
+		}
+	}
+	s.entries[url] = e
+}
+
+// ETagCache returns middleware that makes reads conditional. It remembers
+// the ETag and body of each successful GET, sends If-None-Match when the
+// same URL is requested again, and answers a 304 Not Modified with the
+// remembered body so callers see an ordinary 200. The URL includes the
+// query, so each page, field mask, and filter is tracked separately. Only
+// JSON responses up to maxETagBody are kept, and at most maxEntries of
+// them; zero uses the default.
+func ETagCache(maxEntries int) Middleware {
+	if maxEntries <= 0 {
+		maxEntries = defaultETagEntries
+	}
+	store := &etagStore{entries: make(map[string]*etagEntry), max: maxEntries}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+				return next.RoundTrip(req)
+			}
+
+			key := req.URL.String()
+			cached, ok := store.get(key)
+			if ok {
+				req = req.Clone(req.Context())
+				req.Header.Set("If-None-Match", cached.etag)
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok && resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				return cachedResponse(req, resp, cached), nil
+			}
+
+			etag := resp.Header.Get("ETag")
+			if resp.StatusCode != http.StatusOK || etag == "" || !cacheable(resp) {
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(io.LimitReader(resp.Body, maxETagBody+1))
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			if len(body) > maxETagBody {
+				// Larger than it said, or it did not say; hand back what
+				// was read followed by the rest
+				resp.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+				return resp, nil
+			}
+			resp.Body.Close()
+			store.put(key, &etagEntry{etag: etag, header: resp.Header.Clone(), body: body})
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		})
+	}
+}
+
+// cacheable reports whether resp is a JSON response small enough to keep.
+func cacheable(resp *http.Response) bool {
+	if resp.ContentLength > maxETagBody {
+		return false
+	}
+	return isJSON(resp.Header)
+}
+
+// isJSON reports whether header marks a JSON body.
+func isJSON(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// cachedResponse turns a 304 into a 200 carrying the remembered body.
+func cachedResponse(req *http.Request, notModified *http.Response, e *etagEntry) *http.Response {
+	header := e.header.Clone()
+	for k, v := range notModified.Header {
+		// A 304 may refresh metadata such as Date or the ETag itself
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestETagCache tests that repeated reads are revalidated and a 304 is
+// answered from the stored body.
+func TestETagCache(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("pageToken") == "" && r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		io.WriteString(w, `{"courses":[]}`)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Chain(http.DefaultTransport, ETagCache(0))}
+	get := func(url string) (int, string) {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	for i := 0; i < 2; i++ {
+		status, body := get(server.URL + "/v1/courses")
+		if status != http.StatusOK || body != `{"courses":[]}` {
+			t.Errorf("Expected 200 with the course list on request %d, got %d %q", i, status, body)
+		}
+	}
+	if notModified != 1 {
+		t.Errorf("Expected the second request to be revalidated, got %d 304s", notModified)
+	}
+
+	// A different page is a different URL and has no stored ETag yet
+	get(server.URL + "/v1/courses?pageToken=p2")
+	if requests != 3 || notModified != 1 {
+		t.Errorf("Expected a full response for a new page, got %d requests and %d 304s", requests, notModified)
+	}
+}
+
+// TestETagStoreEviction tests that the store stays within its size.
+func TestETagStoreEviction(t *testing.T) {
+	s := &etagStore{entries: make(map[string]*etagEntry), max: 2}
+	for _, url := range []string{"a", "b", "c"} {
+		s.put(url, &etagEntry{etag: url})
+	}
+	if len(s.entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(s.entries))
+	}
+	if _, ok := s.get("c"); !ok {
+		t.Error("Expected the newest entry to be kept")
+	}
+}
+
+// TestETagCacheSkipsDownloads tests that responses other than small JSON
+// ones are passed through whole and never revalidated.
+func TestETagCacheSkipsDownloads(t *testing.T) {
+	big := strings.Repeat("x", maxETagBody+10)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		chunked     bool
+	}{
+		{"file download", "application/pdf", "%PDF-1.7", false},
+		{"large JSON", "application/json", `"` + big + `"`, false},
+		{"large JSON of unknown length", "application/json", `"` + big + `"`, true},
+	}
+
+	for _, tt := range tests {
+		var revalidated int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") != "" {
+				revalidated++
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Type", tt.contentType)
+			if tt.chunked {
+				w.(http.Flusher).Flush()
+			}
+			io.WriteString(w, tt.body)
+		}))
+
+		client := &http.Client{Transport: Chain(http.DefaultTransport, ETagCache(0))}
+		for i := 0; i < 2; i++ {
+			resp, err := client.Get(server.URL + "/file")
+			if err != nil {
+				t.Fatalf("%s: request failed: %v", tt.name, err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != tt.body {
+				t.Errorf("%s: expected the whole body of %d bytes, got %d", tt.name, len(tt.body), len(body))
+			}
+		}
+		server.Close()
+
+		if revalidated != 0 {
+			t.Errorf("%s: expected no conditional requests, got %d", tt.name, revalidated)
+		}
+	}
+}
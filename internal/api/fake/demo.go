@@ -0,0 +1,107 @@
+package fake
+
+import (
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// DemoUserID is the current user in the demo classroom.
+const DemoUserID = "demo-user"
+
+// NewDemo returns a classroom seeded with sample data: one course the
+// user teaches, two they attend, an archived course, and a pending
+// invitation. Due dates are relative to now so the agenda and digest
+// always have something upcoming and something late.
+func NewDemo() *Client {
+	c := New(DemoUserID)
+	now := time.Now()
+	day := func(offset int) string {
+		return now.AddDate(0, 0, offset).Format("2006-01-02")
+	}
+	stamp := func(offset int) string {
+		return now.AddDate(0, 0, offset).UTC().Format(time.RFC3339)
+	}
+	me := api.UserProfile{ID: DemoUserID, Name: "Alex Rivera", EmailAddress: "alex.rivera@example.edu"}
+
+	people := []api.UserProfile{
+		{ID: "s1", Name: "Jordan Lee", EmailAddress: "jordan.lee@example.edu"},
+		{ID: "s2", Name: "Priya Natarajan", EmailAddress: "priya.natarajan@example.edu"},
+		{ID: "s3", Name: "Mateo García", EmailAddress: "mateo.garcia@example.edu"},
+		{ID: "s4", Name: "Zoë Okafor", EmailAddress: "zoe.okafor@example.edu"},
+	}
+	teachers := map[string]api.UserProfile{
+		"history": {ID: "t1", Name: "Dr. Helen Marsh", EmailAddress: "h.marsh@example.edu"},
+		"writing": {ID: "t2", Name: "Sam Whitaker", EmailAddress: "s.whitaker@example.edu"},
+	}
+
+	// A course the demo user teaches, with a roster and submissions to grade
+	bio := c.AddCourse(&api.Course{ID: "bio101", Name: "Biology 101", Section: "Period 2", Room: "Lab B", OwnerID: DemoUserID, EnrollmentCode: "bq7x2k", TeacherFolder: &api.DriveFolder{ID: "folder-bio101", Title: "Biology 101 Period 2", AlternateLink: "https://drive.google.com/drive/folders/folder-bio101"}, TimeCreated: stamp(-60), UpdateTime: stamp(-1)})
+	c.AddTeacher(&api.Teacher{CourseID: bio.ID, UserID: DemoUserID, Profile: me})
+	for _, p := range people {
+		c.AddStudent(&api.Student{CourseID: bio.ID, UserID: p.ID, Profile: p})
+	}
+	labs := c.AddTopic(&api.Topic{CourseID: bio.ID, Name: "Unit 2: Cells"})
+	cells := c.AddCourseWork(&api.CourseWork{CourseID: bio.ID, TopicID: labs.ID, Title: "Cell Structure Lab Report", Description: "Write up your observations from the onion skin lab.", WorkType: api.WorkTypeAssignment, DueDate: day(2), DueTime: "23:59", MaxPoints: 100, CreatorUserID: DemoUserID, CreateTime: stamp(-7), UpdateTime: stamp(-7)})
+	quiz := c.AddCourseWork(&api.CourseWork{CourseID: bio.ID, Title: "Photosynthesis Quiz", WorkType: api.WorkTypeShortAnswer, DueDate: day(-3), DueTime: "15:00", MaxPoints: 10, CreatorUserID: DemoUserID, CreateTime: stamp(-10), UpdateTime: stamp(-10)})
+	c.AddCourseWork(&api.CourseWork{CourseID: bio.ID, Title: "Unit 3 Reading: Genetics", WorkType: api.WorkTypeMaterial, CreatorUserID: DemoUserID, CreateTime: stamp(-2), UpdateTime: stamp(-2)})
+	c.AddCourseWork(&api.CourseWork{CourseID: bio.ID, Title: "Unit 4 Reading: Evolution", WorkType: api.WorkTypeMaterial, State: api.CourseWorkStateDraft, ScheduledTime: stamp(3), CreatorUserID: DemoUserID, CreateTime: stamp(0), UpdateTime: stamp(0)})
+	c.AddCourseWork(&api.CourseWork{CourseID: bio.ID, Title: "Ecosystem Project (draft)", WorkType: api.WorkTypeAssignment, State: api.CourseWorkStateDraft, DueDate: day(14), MaxPoints: 50, CreatorUserID: DemoUserID, CreateTime: stamp(0), UpdateTime: stamp(0)})
+	makeup := c.AddCourseWork(&api.CourseWork{CourseID: bio.ID, Title: "Make-up Quiz: Cell Division", WorkType: api.WorkTypeAssignment, DueDate: day(4), MaxPoints: 10, AssigneeMode: api.AssigneeModeIndividual, StudentIDs: []string{"s3", "s4"}, CreatorUserID: DemoUserID, CreateTime: stamp(-1), UpdateTime: stamp(-1)})
+	for _, id := range makeup.StudentIDs {
+		c.AddSubmission(&api.StudentSubmission{CourseID: bio.ID, CourseWorkID: makeup.ID, UserID: id, State: api.SubmissionStateCreated, CreateTime: stamp(-1), UpdateTime: stamp(-1)})
+	}
+	c.AddRubric(&api.Rubric{CourseID: bio.ID, CourseWorkID: cells.ID, Criteria: []api.Criterion{
+		{Title: "Observations", Levels: []api.Level{{Title: "Thorough", Points: 40}, {Title: "Partial", Points: 25}, {Title: "Missing", Points: 10}}},
+		{Title: "Analysis", Levels: []api.Level{{Title: "Insightful", Points: 40}, {Title: "Basic", Points: 25}, {Title: "Unclear", Points: 10}}},
+		{Title: "Presentation", Levels: []api.Level{{Title: "Clear", Points: 20}, {Title: "Messy", Points: 10}, {Title: "Hard to follow", Points: 5}}},
+	}})
+	c.AddAddOnAttachment(&api.AddOnAttachment{CourseID: bio.ID, CourseWorkID: cells.ID, Title: "Virtual Microscope: Onion Cells", StudentViewURL: "https://addons.example.edu/microscope/student", TeacherViewURL: "https://addons.example.edu/microscope/teacher", MaxPoints: 20})
+	quizAnswers := []string{
+		"Plants turn light into sugar.",
+		"Chlorophyll absorbs light to make glucose from carbon dioxide and water.",
+		"Light, water, and carbon dioxide become glucose and oxygen in the chloroplasts.",
+		"It happens in the leaves.",
+	}
+	cellStates := []string{api.SubmissionStateTurnedIn, api.SubmissionStateTurnedIn, api.SubmissionStateCreated, api.SubmissionStateNew}
+	for i, p := range people {
+		c.AddSubmission(&api.StudentSubmission{CourseID: bio.ID, CourseWorkID: cells.ID, UserID: p.ID, State: cellStates[i], CreateTime: stamp(-7), UpdateTime: stamp(-i)})
+		sub := &api.StudentSubmission{CourseID: bio.ID, CourseWorkID: quiz.ID, UserID: p.ID, State: api.SubmissionStateReturned, AssignedGrade: 6 + i, Answer: quizAnswers[i], CreateTime: stamp(-10), UpdateTime: stamp(-2)}
+		if i == 3 {
+			sub.State, sub.AssignedGrade, sub.Late = api.SubmissionStateTurnedIn, 0, true
+		}
+		c.AddSubmission(sub)
+	}
+	c.AddAnnouncement(&api.Announcement{CourseID: bio.ID, Text: "Field trip permission slips are due Friday.", State: api.AnnouncementStateDraft, ScheduledTime: stamp(1), CreatorUserID: DemoUserID, CreateTime: stamp(0), UpdateTime: stamp(0)})
+	c.AddAnnouncement(&api.Announcement{CourseID: bio.ID, Text: "Lab coats are required for Thursday's dissection.", State: "PUBLISHED", CreatorUserID: DemoUserID, CreateTime: stamp(-1), UpdateTime: stamp(-1)})
+	c.AddAnnouncement(&api.Announcement{CourseID: bio.ID, Text: "Welcome to Biology 101! The syllabus is posted under Classwork.", State: "PUBLISHED", CreatorUserID: DemoUserID, CreateTime: stamp(-60), UpdateTime: stamp(-60)})
+
+	// Courses the demo user attends
+	hist := c.AddCourse(&api.Course{ID: "hist210", Name: "World History", Section: "Section A", Room: "204", OwnerID: "t1", TimeCreated: stamp(-90), UpdateTime: stamp(-3)})
+	c.AddTeacher(&api.Teacher{CourseID: hist.ID, UserID: "t1", Profile: teachers["history"]})
+	c.AddStudent(&api.Student{CourseID: hist.ID, UserID: DemoUserID, Profile: me})
+	c.AddStudent(&api.Student{CourseID: hist.ID, UserID: "s2", Profile: people[1]})
+	essay := c.AddCourseWork(&api.CourseWork{CourseID: hist.ID, Title: "Essay: Causes of the French Revolution", WorkType: api.WorkTypeAssignment, DueDate: day(1), DueTime: "09:00", MaxPoints: 100, CreatorUserID: "t1", CreateTime: stamp(-14), UpdateTime: stamp(-14)})
+	timeline := c.AddCourseWork(&api.CourseWork{CourseID: hist.ID, Title: "Industrial Revolution Timeline", WorkType: api.WorkTypeAssignment, DueDate: day(-1), DueTime: "23:59", MaxPoints: 20, CreatorUserID: "t1", CreateTime: stamp(-9), UpdateTime: stamp(-9)})
+	c.AddSubmission(&api.StudentSubmission{CourseID: hist.ID, CourseWorkID: essay.ID, UserID: DemoUserID, State: api.SubmissionStateCreated, CreateTime: stamp(-14), UpdateTime: stamp(-1)})
+	exitTicket := c.AddCourseWork(&api.CourseWork{CourseID: hist.ID, Title: "Exit Ticket: The Estates-General", Description: "Which estate paid most of the taxes?", WorkType: api.WorkTypeMultipleChoice, Choices: []string{"First Estate", "Second Estate", "Third Estate"}, DueDate: day(3), MaxPoints: 1, CreatorUserID: "t1", CreateTime: stamp(-1), UpdateTime: stamp(-1)})
+	c.AddSubmission(&api.StudentSubmission{CourseID: hist.ID, CourseWorkID: exitTicket.ID, UserID: DemoUserID, State: api.SubmissionStateCreated, CreateTime: stamp(-1), UpdateTime: stamp(-1)})
+	c.AddSubmission(&api.StudentSubmission{CourseID: hist.ID, CourseWorkID: timeline.ID, UserID: DemoUserID, State: api.SubmissionStateNew, Late: true, CreateTime: stamp(-9), UpdateTime: stamp(-9)})
+	c.AddAnnouncement(&api.Announcement{CourseID: hist.ID, Text: "Reminder: essays are due tomorrow morning. Cite at least three primary sources.", State: "PUBLISHED", CreatorUserID: "t1", CreateTime: stamp(0), UpdateTime: stamp(0)})
+
+	writing := c.AddCourse(&api.Course{ID: "eng150", Name: "Creative Writing", Section: "Evening", Room: "Library 3", OwnerID: "t2", TimeCreated: stamp(-40), UpdateTime: stamp(-5)})
+	c.AddTeacher(&api.Teacher{CourseID: writing.ID, UserID: "t2", Profile: teachers["writing"]})
+	c.AddStudent(&api.Student{CourseID: writing.ID, UserID: DemoUserID, Profile: me})
+	poem := c.AddCourseWork(&api.CourseWork{CourseID: writing.ID, Title: "Poem: A Place You Remember", WorkType: api.WorkTypeAssignment, DueDate: day(-8), MaxPoints: 25, CreatorUserID: "t2", CreateTime: stamp(-20), UpdateTime: stamp(-20)})
+	c.AddSubmission(&api.StudentSubmission{CourseID: writing.ID, CourseWorkID: poem.ID, UserID: DemoUserID, State: api.SubmissionStateReturned, AssignedGrade: 23, CreateTime: stamp(-20), UpdateTime: stamp(-6)})
+
+	chem := c.AddCourse(&api.Course{ID: "chem099", Name: "Intro Chemistry (last term)", CourseState: api.CourseStateArchived, OwnerID: DemoUserID, TimeCreated: stamp(-200), UpdateTime: stamp(-100)})
+	c.AddTeacher(&api.Teacher{CourseID: chem.ID, UserID: DemoUserID, Profile: me})
+
+	// A pending invitation to a course the user is not in yet
+	astro := c.AddCourse(&api.Course{ID: "astro01", Name: "Astronomy Club", Room: "Roof Deck", OwnerID: "t2", TimeCreated: stamp(-5), UpdateTime: stamp(-5)})
+	c.AddTeacher(&api.Teacher{CourseID: astro.ID, UserID: "t2", Profile: teachers["writing"]})
+	c.AddInvitation(&api.Invitation{CourseID: astro.ID, UserID: DemoUserID, Role: "STUDENT"})
+
+	return c
+}
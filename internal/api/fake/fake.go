@@ -0,0 +1,846 @@
+// Package fake provides an in-memory api.ClassroomClient for demos, tests,
+// and developing the TUI without Google credentials.
+package fake
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// Client is an in-memory Classroom. It is safe for concurrent use, and
+// every result is a copy so callers cannot change the stored data.
+type Client struct {
+	mu sync.Mutex
+	// userID is who "me" refers to.
+	userID string
+	now    func() time.Time
+	seq    int
+
+	courses       []*api.Course
+	coursework    map[string][]*api.CourseWork
+	topics        map[string][]*api.Topic
+	rubrics       map[string]*api.Rubric
+	addOns        map[string][]*api.AddOnAttachment
+	submissions   map[string][]*api.StudentSubmission
+	announcements map[string][]*api.Announcement
+	students      map[string][]*api.Student
+	teachers      map[string][]*api.Teacher
+	invitations   []*api.Invitation
+}
+
+var _ api.ClassroomClient = (*Client)(nil)
+
+// New creates an empty classroom in which userID is the current user.
+func New(userID string) *Client {
+	return &Client{
+		userID:        userID,
+		now:           time.Now,
+		coursework:    make(map[string][]*api.CourseWork),
+		topics:        make(map[string][]*api.Topic),
+		rubrics:       make(map[string]*api.Rubric),
+		addOns:        make(map[string][]*api.AddOnAttachment),
+		submissions:   make(map[string][]*api.StudentSubmission),
+		announcements: make(map[string][]*api.Announcement),
+		students:      make(map[string][]*api.Student),
+		teachers:      make(map[string][]*api.Teacher),
+	}
+}
+
+// UserID returns the current user's ID.
+func (c *Client) UserID() string {
+	return c.userID
+}
+
+// AddCourse stores a course. A missing ID is generated.
+func (c *Client) AddCourse(course *api.Course) *api.Course {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *course
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	if cp.CourseState == "" {
+		cp.CourseState = api.CourseStateActive
+	}
+	c.courses = append(c.courses, &cp)
+	return copyOf(&cp)
+}
+
+// AddCourseWork stores coursework in its course. A missing ID is generated.
+func (c *Client) AddCourseWork(cw *api.CourseWork) *api.CourseWork {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *cw
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	if cp.State == "" {
+		cp.State = api.CourseWorkStatePublished
+	}
+	c.coursework[cp.CourseID] = append(c.coursework[cp.CourseID], &cp)
+	return copyOf(&cp)
+}
+
+// AddTopic stores a topic in its course. A missing ID is generated.
+func (c *Client) AddTopic(t *api.Topic) *api.Topic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *t
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	c.topics[cp.CourseID] = append(c.topics[cp.CourseID], &cp)
+	return copyOf(&cp)
+}
+
+// AddRubric attaches a rubric to its coursework, replacing any other.
+// Missing IDs are generated.
+func (c *Client) AddRubric(r *api.Rubric) *api.Rubric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := copyRubric(r)
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	for i := range cp.Criteria {
+		if cp.Criteria[i].ID == "" {
+			cp.Criteria[i].ID = c.nextID()
+		}
+		for j := range cp.Criteria[i].Levels {
+			if cp.Criteria[i].Levels[j].ID == "" {
+				cp.Criteria[i].Levels[j].ID = c.nextID()
+			}
+		}
+	}
+	c.rubrics[cp.CourseWorkID] = cp
+	return copyRubric(cp)
+}
+
+// AddAddOnAttachment stores an add-on attachment on its coursework. A
+// missing ID is generated.
+func (c *Client) AddAddOnAttachment(a *api.AddOnAttachment) *api.AddOnAttachment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *a
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	c.addOns[cp.CourseWorkID] = append(c.addOns[cp.CourseWorkID], &cp)
+	return copyOf(&cp)
+}
+
+// AddSubmission stores a submission. A missing ID is generated, and a
+// submission without history starts with its creation.
+func (c *Client) AddSubmission(sub *api.StudentSubmission) *api.StudentSubmission {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *sub
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	if cp.AssignedGrade != 0 {
+		cp.HasAssignedGrade = true
+	}
+	if len(cp.History) == 0 && cp.CreateTime != "" {
+		cp.History = []api.HistoryEvent{{Time: cp.CreateTime, ActorUserID: cp.UserID, State: api.SubmissionStateCreated}}
+	}
+	c.submissions[cp.CourseWorkID] = append(c.submissions[cp.CourseWorkID], &cp)
+	return copyOf(&cp)
+}
+
+// AddAnnouncement stores an announcement. A missing ID is generated, and a
+// missing state defaults to published.
+func (c *Client) AddAnnouncement(a *api.Announcement) *api.Announcement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *a
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	if cp.State == "" {
+		cp.State = api.AnnouncementStatePublished
+	}
+	c.announcements[cp.CourseID] = append(c.announcements[cp.CourseID], &cp)
+	return copyOf(&cp)
+}
+
+// AddStudent enrolls a student in a course.
+func (c *Client) AddStudent(s *api.Student) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *s
+	c.students[cp.CourseID] = append(c.students[cp.CourseID], &cp)
+}
+
+// AddTeacher adds a teacher to a course.
+func (c *Client) AddTeacher(t *api.Teacher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *t
+	c.teachers[cp.CourseID] = append(c.teachers[cp.CourseID], &cp)
+}
+
+// AddInvitation stores a pending invitation. A missing ID is generated.
+func (c *Client) AddInvitation(inv *api.Invitation) *api.Invitation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := *inv
+	if cp.ID == "" {
+		cp.ID = c.nextID()
+	}
+	c.invitations = append(c.invitations, &cp)
+	return copyOf(&cp)
+}
+
+// ListCourses returns the courses matching opts. opts may be nil.
+func (c *Client) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if opts == nil {
+		opts = &api.ListCoursesOptions{}
+	}
+
+	var out []*api.Course
+	for _, course := range c.courses {
+		if len(opts.CourseStates) > 0 && !contains(opts.CourseStates, course.CourseState) {
+			continue
+		}
+		if opts.TeacherID != "" && !c.teaches(course.ID, opts.TeacherID) {
+			continue
+		}
+		if opts.StudentID != "" && !c.enrolled(course.ID, opts.StudentID) {
+			continue
+		}
+		out = append(out, copyOf(course))
+	}
+	return out, nil
+}
+
+// GetCourse returns a course by ID.
+func (c *Client) GetCourse(ctx context.Context, courseID string) (*api.Course, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	course, err := c.course(courseID)
+	if err != nil {
+		return nil, err
+	}
+	return copyOf(course), nil
+}
+
+// CreateCourse creates a course taught by the current user.
+func (c *Client) CreateCourse(ctx context.Context, name, section, room string) (*api.Course, error) {
+	course := c.AddCourse(&api.Course{
+		Name:        name,
+		Section:     section,
+		Room:        room,
+		OwnerID:     c.userID,
+		CourseState: api.CourseStateProvisioned,
+		TimeCreated: c.timestamp(),
+		UpdateTime:  c.timestamp(),
+	})
+	c.AddTeacher(&api.Teacher{CourseID: course.ID, UserID: c.userID, Profile: api.UserProfile{ID: c.userID, Name: "You"}})
+	return course, nil
+}
+
+// PatchCourse updates a course's details.
+func (c *Client) PatchCourse(ctx context.Context, courseID string, patch api.CoursePatch) (*api.Course, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	course, err := c.course(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Name != nil {
+		course.Name = *patch.Name
+	}
+	if patch.Section != nil {
+		course.Section = *patch.Section
+	}
+	if patch.Room != nil {
+		course.Room = *patch.Room
+	}
+	course.UpdateTime = c.timestamp()
+	return copyOf(course), nil
+}
+
+// UpdateCourseState changes a course's state.
+func (c *Client) UpdateCourseState(ctx context.Context, courseID, state string) (*api.Course, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	course, err := c.course(courseID)
+	if err != nil {
+		return nil, err
+	}
+	course.CourseState = state
+	course.UpdateTime = c.timestamp()
+	return copyOf(course), nil
+}
+
+// ListCourseWork returns a course's coursework. Like the API, only
+// published coursework is returned when no states are given, and students
+// only see work assigned to them.
+func (c *Client) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.course(courseID); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &api.ListCourseWorkOptions{}
+	}
+	states := opts.States
+	if len(states) == 0 {
+		states = []string{api.CourseWorkStatePublished}
+	}
+
+	var out []*api.CourseWork
+	for _, cw := range c.coursework[courseID] {
+		if contains(states, cw.State) && c.assigned(courseID, cw.AssigneeMode, cw.StudentIDs) {
+			out = append(out, copyOf(cw))
+		}
+	}
+	api.SortCourseWork(out, opts.OrderBy)
+	return out, nil
+}
+
+// GetCourseWork returns coursework by ID.
+func (c *Client) GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*api.CourseWork, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cw, err := c.courseWork(courseID, courseWorkID)
+	if err != nil {
+		return nil, err
+	}
+	return copyOf(cw), nil
+}
+
+// ListAddOnAttachments returns the coursework's add-on attachments.
+func (c *Client) ListAddOnAttachments(ctx context.Context, courseID, courseWorkID string) ([]*api.AddOnAttachment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.courseWork(courseID, courseWorkID); err != nil {
+		return nil, err
+	}
+	var out []*api.AddOnAttachment
+	for _, a := range c.addOns[courseWorkID] {
+		out = append(out, copyOf(a))
+	}
+	return out, nil
+}
+
+// ListTopics returns the course's topics.
+func (c *Client) ListTopics(ctx context.Context, courseID string) ([]*api.Topic, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.course(courseID); err != nil {
+		return nil, err
+	}
+	var out []*api.Topic
+	for _, t := range c.topics[courseID] {
+		out = append(out, copyOf(t))
+	}
+	return out, nil
+}
+
+// GetAddOnAttachment returns one add-on attachment on the coursework.
+func (c *Client) GetAddOnAttachment(ctx context.Context, courseID, courseWorkID, attachmentID string) (*api.AddOnAttachment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.courseWork(courseID, courseWorkID); err != nil {
+		return nil, err
+	}
+	for _, a := range c.addOns[courseWorkID] {
+		if a.ID == attachmentID {
+			return copyOf(a), nil
+		}
+	}
+	return nil, notFound("add-on attachment", attachmentID)
+}
+
+// GetRubric returns the coursework's rubric, or nil when it has none.
+func (c *Client) GetRubric(ctx context.Context, courseID, courseWorkID string) (*api.Rubric, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.courseWork(courseID, courseWorkID); err != nil {
+		return nil, err
+	}
+	r := c.rubrics[courseWorkID]
+	if r == nil {
+		return nil, nil
+	}
+	return copyRubric(r), nil
+}
+
+// CreateCourseWork creates coursework by the current user, with an
+// unsubmitted submission for every enrolled student.
+func (c *Client) CreateCourseWork(ctx context.Context, courseID string, cw *api.CourseWork) (*api.CourseWork, error) {
+	c.mu.Lock()
+	if _, err := c.course(courseID); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	students := append([]*api.Student(nil), c.students[courseID]...)
+	c.mu.Unlock()
+
+	cp := *cw
+	cp.ID = ""
+	cp.CourseID = courseID
+	cp.CreatorUserID = c.userID
+	cp.CreateTime = c.timestamp()
+	cp.UpdateTime = cp.CreateTime
+	created := c.AddCourseWork(&cp)
+	for _, s := range students {
+		c.AddSubmission(&api.StudentSubmission{
+			CourseID:     courseID,
+			CourseWorkID: created.ID,
+			UserID:       s.UserID,
+			State:        api.SubmissionStateNew,
+			CreateTime:   created.CreateTime,
+			UpdateTime:   created.CreateTime,
+		})
+	}
+	return created, nil
+}
+
+// DeleteCourseWork deletes coursework and its submissions.
+func (c *Client) DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.courseWork(courseID, courseWorkID); err != nil {
+		return err
+	}
+	c.coursework[courseID] = remove(c.coursework[courseID], func(cw *api.CourseWork) bool { return cw.ID == courseWorkID })
+	delete(c.submissions, courseWorkID)
+	return nil
+}
+
+// ListStudentSubmissions returns submissions for coursework. opts may be nil.
+func (c *Client) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.courseWork(courseID, courseWorkID); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &api.ListStudentSubmissionsOptions{}
+	}
+
+	var out []*api.StudentSubmission
+	for _, sub := range c.submissions[courseWorkID] {
+		if opts.UserID != "" && !c.isUser(sub.UserID, opts.UserID) {
+			continue
+		}
+		if len(opts.States) > 0 && !contains(opts.States, sub.State) {
+			continue
+		}
+		out = append(out, copyOf(sub))
+	}
+	return out, nil
+}
+
+// GetStudentSubmission returns a submission by ID.
+func (c *Client) GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*api.StudentSubmission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub, err := c.submission(courseWorkID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	return copyOf(sub), nil
+}
+
+// TurnIn turns in the current user's submission.
+func (c *Client) TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub, err := c.submission(courseWorkID, submissionID)
+	if err != nil {
+		return err
+	}
+	if !sub.CanTurnIn() {
+		return apperrors.Newf(apperrors.ErrAPI, "submission %s cannot be turned in from state %s", submissionID, sub.State)
+	}
+	sub.State = api.SubmissionStateTurnedIn
+	sub.UpdateTime = c.timestamp()
+	sub.History = append(slices.Clip(sub.History), api.HistoryEvent{Time: sub.UpdateTime, ActorUserID: c.userID, State: sub.State})
+	return nil
+}
+
+// ModifyAttachments adds Drive files to the current user's submission.
+func (c *Client) ModifyAttachments(ctx context.Context, courseID, courseWorkID, submissionID string, driveFileIDs []string) (*api.StudentSubmission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub, err := c.submission(courseWorkID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.UserID != c.userID {
+		return nil, apperrors.Newf(apperrors.ErrAPIForbidden, "only the student can attach files to submission %s", submissionID)
+	}
+	if !sub.CanTurnIn() {
+		return nil, apperrors.Newf(apperrors.ErrAPI, "submission %s cannot be changed in state %s", submissionID, sub.State)
+	}
+	for _, id := range driveFileIDs {
+		sub.Attachments = append(sub.Attachments, api.Attachment{Kind: api.AttachmentDriveFile, ID: id})
+	}
+	sub.UpdateTime = c.timestamp()
+	return copyOf(sub), nil
+}
+
+// SetDraftGrade sets a submission's draft grade.
+func (c *Client) SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub, err := c.submission(courseWorkID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	sub.DraftGrade = int(grade)
+	sub.UpdateTime = c.timestamp()
+	event := api.HistoryEvent{Time: sub.UpdateTime, ActorUserID: c.userID, GradeChange: api.GradeChangeDraft, PointsEarned: grade}
+	if cw, err := c.courseWork(courseID, courseWorkID); err == nil {
+		event.MaxPoints = float64(cw.MaxPoints)
+	}
+	sub.History = append(slices.Clip(sub.History), event)
+	return copyOf(sub), nil
+}
+
+// SetAssignedGrade sets the submission's assigned and draft grades.
+func (c *Client) SetAssignedGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub, err := c.submission(courseWorkID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	sub.AssignedGrade, sub.DraftGrade = int(grade), int(grade)
+	sub.HasAssignedGrade = true
+	sub.UpdateTime = c.timestamp()
+	event := api.HistoryEvent{Time: sub.UpdateTime, ActorUserID: c.userID, GradeChange: api.GradeChangeAssigned, PointsEarned: grade}
+	if cw, err := c.courseWork(courseID, courseWorkID); err == nil {
+		event.MaxPoints = float64(cw.MaxPoints)
+	}
+	sub.History = append(slices.Clip(sub.History), event)
+	return copyOf(sub), nil
+}
+
+// ReturnSubmission returns a submission to its student. Only teachers of
+// the course may.
+func (c *Client) ReturnSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.teaches(courseID, "me") {
+		return apperrors.Newf(apperrors.ErrAPIForbidden, "only teachers can return submission %s", submissionID)
+	}
+	sub, err := c.submission(courseWorkID, submissionID)
+	if err != nil {
+		return err
+	}
+	sub.State = api.SubmissionStateReturned
+	sub.UpdateTime = c.timestamp()
+	sub.History = append(slices.Clip(sub.History), api.HistoryEvent{Time: sub.UpdateTime, ActorUserID: c.userID, State: api.SubmissionStateReturned})
+	return nil
+}
+
+// ListAnnouncements returns a course's announcements, newest first. Like
+// the API, only published announcements are returned when no states are
+// given, and students only see announcements addressed to them.
+func (c *Client) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.course(courseID); err != nil {
+		return nil, err
+	}
+	states := []string{api.AnnouncementStatePublished}
+	if opts != nil && len(opts.States) > 0 {
+		states = opts.States
+	}
+	out := make([]*api.Announcement, 0, len(c.announcements[courseID]))
+	for _, a := range c.announcements[courseID] {
+		if contains(states, a.State) && c.assigned(courseID, a.AssigneeMode, a.StudentIDs) {
+			out = append(out, copyOf(a))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UpdateTime > out[j].UpdateTime })
+	return out, nil
+}
+
+// DeleteAnnouncement deletes an announcement.
+func (c *Client) DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	before := len(c.announcements[courseID])
+	c.announcements[courseID] = remove(c.announcements[courseID], func(a *api.Announcement) bool { return a.ID == announcementID })
+	if len(c.announcements[courseID]) == before {
+		return notFound("announcement", announcementID)
+	}
+	return nil
+}
+
+// ListStudents returns a course's students.
+func (c *Client) ListStudents(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Student, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.course(courseID); err != nil {
+		return nil, err
+	}
+	out := make([]*api.Student, 0, len(c.students[courseID]))
+	for _, s := range c.students[courseID] {
+		out = append(out, copyOf(s))
+	}
+	return out, nil
+}
+
+// RemoveStudent removes a student from a course.
+func (c *Client) RemoveStudent(ctx context.Context, courseID, userID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	before := len(c.students[courseID])
+	c.students[courseID] = remove(c.students[courseID], func(s *api.Student) bool { return s.UserID == userID })
+	if len(c.students[courseID]) == before {
+		return notFound("student", userID)
+	}
+	return nil
+}
+
+// ListTeachers returns a course's teachers.
+func (c *Client) ListTeachers(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Teacher, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.course(courseID); err != nil {
+		return nil, err
+	}
+	out := make([]*api.Teacher, 0, len(c.teachers[courseID]))
+	for _, t := range c.teachers[courseID] {
+		out = append(out, copyOf(t))
+	}
+	return out, nil
+}
+
+// RemoveTeacher removes a teacher from a course.
+func (c *Client) RemoveTeacher(ctx context.Context, courseID, userID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	before := len(c.teachers[courseID])
+	c.teachers[courseID] = remove(c.teachers[courseID], func(t *api.Teacher) bool { return t.UserID == userID })
+	if len(c.teachers[courseID]) == before {
+		return notFound("teacher", userID)
+	}
+	return nil
+}
+
+// IsTeacher reports whether the current user teaches the course.
+func (c *Client) IsTeacher(ctx context.Context, courseID string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.teaches(courseID, "me"), nil
+}
+
+// GetUserProfile returns the profile of a student or teacher in any
+// course, matched by ID or email address. The current user has a profile
+// even when not enrolled anywhere.
+func (c *Client) GetUserProfile(ctx context.Context, userID string) (*api.UserProfile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	match := func(id string, p api.UserProfile) *api.UserProfile {
+		if !c.isUser(id, userID) && (p.EmailAddress == "" || p.EmailAddress != userID) {
+			return nil
+		}
+		p.ID = id
+		return &p
+	}
+	for _, course := range c.courses {
+		for _, s := range c.students[course.ID] {
+			if p := match(s.UserID, s.Profile); p != nil {
+				return p, nil
+			}
+		}
+		for _, t := range c.teachers[course.ID] {
+			if p := match(t.UserID, t.Profile); p != nil {
+				return p, nil
+			}
+		}
+	}
+	if c.isUser(c.userID, userID) {
+		return &api.UserProfile{ID: c.userID, Name: "You"}, nil
+	}
+	return nil, notFound("user", userID)
+}
+
+// ListInvitations returns the current user's pending invitations.
+func (c *Client) ListInvitations(ctx context.Context) ([]*api.Invitation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []*api.Invitation
+	for _, inv := range c.invitations {
+		if c.isUser(inv.UserID, "me") {
+			out = append(out, copyOf(inv))
+		}
+	}
+	return out, nil
+}
+
+// AcceptInvitation enrolls the current user in the invited course.
+func (c *Client) AcceptInvitation(ctx context.Context, invitationID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	inv, err := c.invitation(invitationID)
+	if err != nil {
+		return err
+	}
+	profile := api.UserProfile{ID: c.userID, Name: "You"}
+	if inv.Role == "STUDENT" {
+		c.students[inv.CourseID] = append(c.students[inv.CourseID], &api.Student{CourseID: inv.CourseID, UserID: c.userID, Profile: profile})
+	} else {
+		c.teachers[inv.CourseID] = append(c.teachers[inv.CourseID], &api.Teacher{CourseID: inv.CourseID, UserID: c.userID, Profile: profile})
+	}
+	c.invitations = remove(c.invitations, func(i *api.Invitation) bool { return i.ID == invitationID })
+	return nil
+}
+
+// DeleteInvitation declines an invitation.
+func (c *Client) DeleteInvitation(ctx context.Context, invitationID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.invitation(invitationID); err != nil {
+		return err
+	}
+	c.invitations = remove(c.invitations, func(i *api.Invitation) bool { return i.ID == invitationID })
+	return nil
+}
+
+// CreateInvitation invites a user to a course.
+func (c *Client) CreateInvitation(ctx context.Context, courseID, userID, role string) (*api.Invitation, error) {
+	if _, err := c.GetCourse(ctx, courseID); err != nil {
+		return nil, err
+	}
+	return c.AddInvitation(&api.Invitation{CourseID: courseID, UserID: userID, Role: role}), nil
+}
+
+// The helpers below expect c.mu to be held.
+
+func (c *Client) nextID() string {
+	c.seq++
+	return strconv.Itoa(1000 + c.seq)
+}
+
+func (c *Client) timestamp() string {
+	return c.now().UTC().Format(time.RFC3339)
+}
+
+// isUser reports whether userID matches ref, which may be "me".
+func (c *Client) isUser(userID, ref string) bool {
+	if ref == "me" {
+		ref = c.userID
+	}
+	return userID == ref
+}
+
+func (c *Client) teaches(courseID, ref string) bool {
+	for _, t := range c.teachers[courseID] {
+		if c.isUser(t.UserID, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// assigned reports whether the current user can see a post with the given
+// assignees: teachers see every post.
+func (c *Client) assigned(courseID, mode string, studentIDs []string) bool {
+	return mode != api.AssigneeModeIndividual || c.teaches(courseID, "me") || contains(studentIDs, c.userID)
+}
+
+func (c *Client) enrolled(courseID, ref string) bool {
+	for _, s := range c.students[courseID] {
+		if c.isUser(s.UserID, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) course(id string) (*api.Course, error) {
+	for _, course := range c.courses {
+		if course.ID == id {
+			return course, nil
+		}
+	}
+	return nil, notFound("course", id)
+}
+
+func (c *Client) courseWork(courseID, id string) (*api.CourseWork, error) {
+	if _, err := c.course(courseID); err != nil {
+		return nil, err
+	}
+	for _, cw := range c.coursework[courseID] {
+		if cw.ID == id {
+			return cw, nil
+		}
+	}
+	return nil, notFound("coursework", id)
+}
+
+func (c *Client) submission(courseWorkID, id string) (*api.StudentSubmission, error) {
+	for _, sub := range c.submissions[courseWorkID] {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return nil, notFound("submission", id)
+}
+
+func (c *Client) invitation(id string) (*api.Invitation, error) {
+	for _, inv := range c.invitations {
+		if inv.ID == id {
+			return inv, nil
+		}
+	}
+	return nil, notFound("invitation", id)
+}
+
+// notFound mirrors the error the real client returns for a 404.
+func notFound(kind, id string) error {
+	return apperrors.Newf(apperrors.ErrAPINotFound, "%s %s not found", kind, id)
+}
+
+func copyOf[T any](v *T) *T {
+	cp := *v
+	return &cp
+}
+
+// copyRubric returns a copy of r that shares no slices with it.
+func copyRubric(r *api.Rubric) *api.Rubric {
+	cp := *r
+	cp.Criteria = slices.Clone(r.Criteria)
+	for i := range cp.Criteria {
+		cp.Criteria[i].Levels = slices.Clone(cp.Criteria[i].Levels)
+	}
+	return &cp
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func remove[T any](items []*T, match func(*T) bool) []*T {
+	out := items[:0]
+	for _, v := range items {
+		if !match(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
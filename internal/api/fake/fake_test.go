@@ -0,0 +1,365 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// TestListCourses tests filtering courses by role and state
+func TestListCourses(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	teaching, err := c.ListCourses(ctx, &api.ListCoursesOptions{TeacherID: "me", CourseStates: []string{api.CourseStateActive}})
+	if err != nil {
+		t.Fatalf("ListCourses failed: %v", err)
+	}
+	if len(teaching) != 1 || teaching[0].ID != "bio101" {
+		t.Errorf("Expected only bio101 to be taught, got %v", teaching)
+	}
+
+	enrolled, err := c.ListCourses(ctx, &api.ListCoursesOptions{StudentID: "me"})
+	if err != nil {
+		t.Fatalf("ListCourses failed: %v", err)
+	}
+	if len(enrolled) != 2 {
+		t.Errorf("Expected 2 enrolled courses, got %d", len(enrolled))
+	}
+
+	// Results are copies
+	teaching[0].Name = "Changed"
+	course, err := c.GetCourse(ctx, "bio101")
+	if err != nil {
+		t.Fatalf("GetCourse failed: %v", err)
+	}
+	if course.Name != "Biology 101" {
+		t.Errorf("Expected stored course to be unchanged, got %q", course.Name)
+	}
+}
+
+// TestCourseWork tests that drafts are hidden unless asked for
+func TestCourseWork(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	published, err := c.ListCourseWork(ctx, "bio101", nil)
+	if err != nil {
+		t.Fatalf("ListCourseWork failed: %v", err)
+	}
+	for _, cw := range published {
+		if cw.State != api.CourseWorkStatePublished {
+			t.Errorf("Expected only published coursework, got %s in state %s", cw.Title, cw.State)
+		}
+	}
+
+	all, err := c.ListCourseWork(ctx, "bio101", &api.ListCourseWorkOptions{States: []string{api.CourseWorkStatePublished, api.CourseWorkStateDraft}})
+	if err != nil {
+		t.Fatalf("ListCourseWork failed: %v", err)
+	}
+	if len(all) != len(published)+2 {
+		t.Errorf("Expected two drafts, got %d published and %d total", len(published), len(all))
+	}
+}
+
+// TestAnnouncementStates tests that drafts, such as scheduled announcements,
+// are only listed when asked for.
+func TestAnnouncementStates(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	published, err := c.ListAnnouncements(ctx, "bio101", nil)
+	if err != nil {
+		t.Fatalf("ListAnnouncements failed: %v", err)
+	}
+	for _, a := range published {
+		if a.State != api.AnnouncementStatePublished {
+			t.Errorf("Expected only published announcements, got %q in state %s", a.Text, a.State)
+		}
+	}
+
+	drafts, err := c.ListAnnouncements(ctx, "bio101", &api.ListAnnouncementsOptions{States: []string{api.AnnouncementStateDraft}})
+	if err != nil {
+		t.Fatalf("ListAnnouncements failed: %v", err)
+	}
+	if len(drafts) != 1 {
+		t.Fatalf("Expected one draft, got %d", len(drafts))
+	}
+	if _, ok := drafts[0].Scheduled(); !ok {
+		t.Errorf("Expected the draft to be scheduled, got %q", drafts[0].ScheduledTime)
+	}
+}
+
+// TestTurnIn tests turning in a submission and the resulting state
+func TestTurnIn(t *testing.T) {
+	c := New("u1")
+	ctx := context.Background()
+	course := c.AddCourse(&api.Course{Name: "Math"})
+	cw := c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Homework"})
+	sub := c.AddSubmission(&api.StudentSubmission{CourseID: course.ID, CourseWorkID: cw.ID, UserID: "u1", State: api.SubmissionStateCreated})
+
+	subs, err := c.ListStudentSubmissions(ctx, course.ID, cw.ID, &api.ListStudentSubmissionsOptions{UserID: "me"})
+	if err != nil {
+		t.Fatalf("ListStudentSubmissions failed: %v", err)
+	}
+	if len(subs) != 1 || subs[0].ID != sub.ID {
+		t.Fatalf("Expected the user's submission, got %v", subs)
+	}
+
+	attached, err := c.ModifyAttachments(ctx, course.ID, cw.ID, sub.ID, []string{"file1"})
+	if err != nil {
+		t.Fatalf("ModifyAttachments failed: %v", err)
+	}
+	if len(attached.Attachments) != 1 || attached.Attachments[0].ID != "file1" {
+		t.Errorf("Expected file1 attached, got %v", attached.Attachments)
+	}
+
+	if err := c.TurnIn(ctx, course.ID, cw.ID, sub.ID); err != nil {
+		t.Fatalf("TurnIn failed: %v", err)
+	}
+	got, err := c.GetStudentSubmission(ctx, course.ID, cw.ID, sub.ID)
+	if err != nil {
+		t.Fatalf("GetStudentSubmission failed: %v", err)
+	}
+	if got.State != api.SubmissionStateTurnedIn {
+		t.Errorf("Expected state %s, got %s", api.SubmissionStateTurnedIn, got.State)
+	}
+	if n := len(got.History); n != 1 || got.History[0].State != api.SubmissionStateTurnedIn || got.History[0].ActorUserID != "u1" {
+		t.Errorf("Expected a turn-in by u1 in the history, got %+v", got.History)
+	}
+
+	if err := c.TurnIn(ctx, course.ID, cw.ID, sub.ID); err == nil {
+		t.Error("Expected turning in twice to fail")
+	}
+	if _, err := c.ModifyAttachments(ctx, course.ID, cw.ID, sub.ID, []string{"file2"}); err == nil {
+		t.Error("Expected attaching after turn-in to fail")
+	}
+}
+
+// TestNotFound tests that missing resources return not-found errors
+func TestNotFound(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	if _, err := c.GetCourse(ctx, "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected not-found error for course, got %v", err)
+	}
+	if _, err := c.GetCourseWork(ctx, "bio101", "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected not-found error for coursework, got %v", err)
+	}
+	if err := c.AcceptInvitation(ctx, "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected not-found error for invitation, got %v", err)
+	}
+}
+
+// TestAcceptInvitation tests that accepting an invitation enrolls the user
+func TestAcceptInvitation(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	invitations, err := c.ListInvitations(ctx)
+	if err != nil {
+		t.Fatalf("ListInvitations failed: %v", err)
+	}
+	if len(invitations) != 1 {
+		t.Fatalf("Expected 1 invitation, got %d", len(invitations))
+	}
+	if err := c.AcceptInvitation(ctx, invitations[0].ID); err != nil {
+		t.Fatalf("AcceptInvitation failed: %v", err)
+	}
+
+	enrolled, _ := c.ListCourses(ctx, &api.ListCoursesOptions{StudentID: "me"})
+	if len(enrolled) != 3 {
+		t.Errorf("Expected 3 enrolled courses after accepting, got %d", len(enrolled))
+	}
+	if invitations, _ := c.ListInvitations(ctx); len(invitations) != 0 {
+		t.Errorf("Expected no invitations after accepting, got %d", len(invitations))
+	}
+}
+
+// TestGetUserProfile tests looking up the current user and classmates
+func TestGetUserProfile(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	me, err := c.GetUserProfile(ctx, "me")
+	if err != nil {
+		t.Fatalf("GetUserProfile failed: %v", err)
+	}
+	if me.ID != DemoUserID || me.Name != "Alex Rivera" {
+		t.Errorf("Expected the demo user, got %+v", me)
+	}
+	byEmail, err := c.GetUserProfile(ctx, me.EmailAddress)
+	if err != nil || byEmail.ID != DemoUserID {
+		t.Errorf("Expected lookup by email to find the demo user, got %+v, %v", byEmail, err)
+	}
+	if _, err := c.GetUserProfile(ctx, "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected not-found error for user, got %v", err)
+	}
+
+	empty := New("u1")
+	if p, err := empty.GetUserProfile(ctx, "me"); err != nil || p.ID != "u1" {
+		t.Errorf("Expected a profile for an unenrolled user, got %+v, %v", p, err)
+	}
+}
+
+// TestRubric tests attaching and reading a rubric
+func TestRubric(t *testing.T) {
+	c := New("t1")
+	ctx := context.Background()
+	course := c.AddCourse(&api.Course{Name: "Biology"})
+	cw := c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Lab"})
+
+	r, err := c.GetRubric(ctx, course.ID, cw.ID)
+	if err != nil || r != nil {
+		t.Fatalf("Expected no rubric, got %+v, %v", r, err)
+	}
+
+	added := c.AddRubric(&api.Rubric{CourseID: course.ID, CourseWorkID: cw.ID, Criteria: []api.Criterion{
+		{Title: "Method", Levels: []api.Level{{Title: "Good", Points: 5}, {Title: "Poor", Points: 1}}},
+	}})
+	if added.ID == "" || added.Criteria[0].ID == "" || added.Criteria[0].Levels[1].ID == "" {
+		t.Errorf("Expected generated IDs, got %+v", added)
+	}
+
+	r, err = c.GetRubric(ctx, course.ID, cw.ID)
+	if err != nil {
+		t.Fatalf("GetRubric failed: %v", err)
+	}
+	r.Criteria[0].Levels[0].Points = 100
+	r, _ = c.GetRubric(ctx, course.ID, cw.ID)
+	if r.Criteria[0].Levels[0].Points != 5 {
+		t.Errorf("Expected the stored rubric to be unchanged, got %v points", r.Criteria[0].Levels[0].Points)
+	}
+
+	if _, err := c.GetRubric(ctx, course.ID, "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+// TestAddOnAttachments tests storing and reading add-on attachments.
+func TestAddOnAttachments(t *testing.T) {
+	c := New("t1")
+	ctx := context.Background()
+	course := c.AddCourse(&api.Course{Name: "Biology"})
+	cw := c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Lab"})
+
+	added := c.AddAddOnAttachment(&api.AddOnAttachment{CourseID: course.ID, CourseWorkID: cw.ID, Title: "Microscope"})
+	if added.ID == "" {
+		t.Error("Expected a generated ID")
+	}
+
+	addOns, err := c.ListAddOnAttachments(ctx, course.ID, cw.ID)
+	if err != nil || len(addOns) != 1 {
+		t.Fatalf("Expected 1 add-on attachment, got %v, %v", addOns, err)
+	}
+	got, err := c.GetAddOnAttachment(ctx, course.ID, cw.ID, added.ID)
+	if err != nil || got.Title != "Microscope" {
+		t.Errorf("Expected the Microscope attachment, got %+v, %v", got, err)
+	}
+	if _, err := c.GetAddOnAttachment(ctx, course.ID, cw.ID, "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+// TestIndividualAssignees tests that students only see posts assigned to them
+func TestIndividualAssignees(t *testing.T) {
+	c := New("s1")
+	ctx := context.Background()
+	course := c.AddCourse(&api.Course{Name: "Math"})
+	c.AddStudent(&api.Student{CourseID: course.ID, UserID: "s1"})
+	c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Everyone"})
+	c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Mine", AssigneeMode: api.AssigneeModeIndividual, StudentIDs: []string{"s1"}})
+	c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Others", AssigneeMode: api.AssigneeModeIndividual, StudentIDs: []string{"s2"}})
+	c.AddAnnouncement(&api.Announcement{CourseID: course.ID, Text: "Just for s2", AssigneeMode: api.AssigneeModeIndividual, StudentIDs: []string{"s2"}})
+
+	coursework, err := c.ListCourseWork(ctx, course.ID, nil)
+	if err != nil {
+		t.Fatalf("ListCourseWork failed: %v", err)
+	}
+	if len(coursework) != 2 {
+		t.Errorf("Expected 2 visible coursework items, got %d", len(coursework))
+	}
+	for _, cw := range coursework {
+		if cw.Title == "Others" {
+			t.Error("Expected work assigned to another student to be hidden")
+		}
+	}
+
+	announcements, err := c.ListAnnouncements(ctx, course.ID, nil)
+	if err != nil {
+		t.Fatalf("ListAnnouncements failed: %v", err)
+	}
+	if len(announcements) != 0 {
+		t.Errorf("Expected no visible announcements, got %d", len(announcements))
+	}
+}
+
+// TestTopics tests listing a course's topics and filing coursework under one
+func TestTopics(t *testing.T) {
+	c := NewDemo()
+	ctx := context.Background()
+
+	topics, err := c.ListTopics(ctx, "bio101")
+	if err != nil {
+		t.Fatalf("ListTopics failed: %v", err)
+	}
+	if len(topics) != 1 || topics[0].Name != "Unit 2: Cells" {
+		t.Fatalf("Expected the demo topic, got %v", topics)
+	}
+	coursework, err := c.ListCourseWork(ctx, "bio101", nil)
+	if err != nil {
+		t.Fatalf("ListCourseWork failed: %v", err)
+	}
+	filed := 0
+	for _, cw := range coursework {
+		if cw.TopicID == topics[0].ID {
+			filed++
+		}
+	}
+	if filed != 1 {
+		t.Errorf("Expected 1 coursework under the topic, got %d", filed)
+	}
+
+	if _, err := c.ListTopics(ctx, "missing"); !apperrors.IsNotFoundError(err) {
+		t.Errorf("Expected not found for an unknown course, got %v", err)
+	}
+}
+
+// TestGradeAndReturn tests assigning a grade and returning the submission
+func TestGradeAndReturn(t *testing.T) {
+	c := New("t1")
+	ctx := context.Background()
+	course := c.AddCourse(&api.Course{Name: "Math"})
+	cw := c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: "Homework", MaxPoints: 10})
+	sub := c.AddSubmission(&api.StudentSubmission{CourseID: course.ID, CourseWorkID: cw.ID, UserID: "s1", State: api.SubmissionStateTurnedIn})
+
+	if err := c.ReturnSubmission(ctx, course.ID, cw.ID, sub.ID); err == nil {
+		t.Error("Expected returning to fail for a non-teacher")
+	}
+	c.AddTeacher(&api.Teacher{CourseID: course.ID, UserID: "t1"})
+
+	graded, err := c.SetAssignedGrade(ctx, course.ID, cw.ID, sub.ID, 8)
+	if err != nil {
+		t.Fatalf("SetAssignedGrade failed: %v", err)
+	}
+	if graded.AssignedGrade != 8 || graded.DraftGrade != 8 {
+		t.Errorf("Expected assigned and draft grades of 8, got %d and %d", graded.AssignedGrade, graded.DraftGrade)
+	}
+
+	if err := c.ReturnSubmission(ctx, course.ID, cw.ID, sub.ID); err != nil {
+		t.Fatalf("ReturnSubmission failed: %v", err)
+	}
+	got, err := c.GetStudentSubmission(ctx, course.ID, cw.ID, sub.ID)
+	if err != nil {
+		t.Fatalf("GetStudentSubmission failed: %v", err)
+	}
+	if got.State != api.SubmissionStateReturned {
+		t.Errorf("Expected state %s, got %s", api.SubmissionStateReturned, got.State)
+	}
+	if n := len(got.History); n != 2 || got.History[0].GradeChange != api.GradeChangeAssigned || got.History[0].MaxPoints != 10 || got.History[1].State != api.SubmissionStateReturned {
+		t.Errorf("Expected a grade then a return in the history, got %+v", got.History)
+	}
+}
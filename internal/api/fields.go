@@ -0,0 +1,44 @@
+package api
+
+import "google.golang.org/api/googleapi"
+
+// FieldsAll requests complete resources instead of a partial response.
+const FieldsAll googleapi.Field = "*"
+
+// Resource field sets covering everything the converters read. List calls
+// request only these by default to keep payloads small.
+const (
+	courseFields       = "id,name,section,descriptionHeading,room,ownerId,enrollmentCode,courseState,creationTime,updateTime,calendarId,alternateLink,teacherFolder"
+	courseWorkFields   = "id,courseId,title,description,workType,state,dueDate,dueTime,maxPoints,creatorUserId,creationTime,updateTime,materials,multipleChoiceQuestion,assignment,alternateLink,assigneeMode,individualStudentsOptions,scheduledTime,topicId"
+	submissionFields   = "id,courseId,courseWorkId,userId,state,assignedGrade,draftGrade,late,creationTime,updateTime,assignmentSubmission,shortAnswerSubmission,multipleChoiceSubmission,assignedRubricGrades,draftRubricGrades,alternateLink,submissionHistory/gradeHistory/gradeChangeType"
+	announcementFields = "id,courseId,text,state,creatorUserId,creationTime,updateTime,alternateLink,assigneeMode,individualStudentsOptions,scheduledTime"
+	profileFields      = "profile(id,name/fullName,emailAddress,photoUrl)"
+	invitationFields   = "id,courseId,userId,role"
+	addOnFields        = "id,courseId,itemId,postId,title,studentViewUri,teacherViewUri,studentWorkReviewUri,maxPoints,dueDate,dueTime"
+	topicFields        = "topicId,courseId,name"
+)
+
+// addOnAttachmentFields is the partial response of an add-on attachment.
+const addOnAttachmentFields googleapi.Field = addOnFields
+
+// Default partial-response fields for each list call.
+const (
+	coursesListFields          googleapi.Field = "nextPageToken,courses(" + courseFields + ")"
+	courseWorkListFields       googleapi.Field = "nextPageToken,courseWork(" + courseWorkFields + ")"
+	submissionsListFields      googleapi.Field = "nextPageToken,studentSubmissions(" + submissionFields + ")"
+	announcementsListFields    googleapi.Field = "nextPageToken,announcements(" + announcementFields + ")"
+	studentsListFields         googleapi.Field = "nextPageToken,students(userId,courseId," + profileFields + ")"
+	teachersListFields         googleapi.Field = "nextPageToken,teachers(userId,courseId," + profileFields + ")"
+	invitationsListFields      googleapi.Field = "nextPageToken,invitations(" + invitationFields + ")"
+	addOnAttachmentsListFields googleapi.Field = "nextPageToken,addOnAttachments(" + addOnFields + ")"
+	topicsListFields           googleapi.Field = "nextPageToken,topic(" + topicFields + ")"
+)
+
+// selectFields returns the requested fields, or the default when none were
+// requested.
+func selectFields(requested []googleapi.Field, def googleapi.Field) []googleapi.Field {
+	if len(requested) == 0 {
+		return []googleapi.Field{def}
+	}
+	return requested
+}
@@ -0,0 +1,48 @@
+package api
+
+import "context"
+
+// ClassroomClient is the set of Classroom operations the app uses. *Client
+// implements it against the API; internal/api/fake implements it in memory
+// for demos and development without credentials.
+type ClassroomClient interface {
+	ListCourses(ctx context.Context, opts *ListCoursesOptions) ([]*Course, error)
+	GetCourse(ctx context.Context, courseID string) (*Course, error)
+	CreateCourse(ctx context.Context, name, section, room string) (*Course, error)
+	PatchCourse(ctx context.Context, courseID string, patch CoursePatch) (*Course, error)
+	UpdateCourseState(ctx context.Context, courseID, state string) (*Course, error)
+
+	ListCourseWork(ctx context.Context, courseID string, opts *ListCourseWorkOptions) ([]*CourseWork, error)
+	GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*CourseWork, error)
+	GetRubric(ctx context.Context, courseID, courseWorkID string) (*Rubric, error)
+	ListAddOnAttachments(ctx context.Context, courseID, courseWorkID string) ([]*AddOnAttachment, error)
+	GetAddOnAttachment(ctx context.Context, courseID, courseWorkID, attachmentID string) (*AddOnAttachment, error)
+	ListTopics(ctx context.Context, courseID string) ([]*Topic, error)
+	CreateCourseWork(ctx context.Context, courseID string, cw *CourseWork) (*CourseWork, error)
+	DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error
+
+	ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *ListStudentSubmissionsOptions) ([]*StudentSubmission, error)
+	GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*StudentSubmission, error)
+	TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error
+	ModifyAttachments(ctx context.Context, courseID, courseWorkID, submissionID string, driveFileIDs []string) (*StudentSubmission, error)
+	SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*StudentSubmission, error)
+	SetAssignedGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*StudentSubmission, error)
+	ReturnSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) error
+
+	ListAnnouncements(ctx context.Context, courseID string, opts *ListAnnouncementsOptions) ([]*Announcement, error)
+	DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error
+
+	ListStudents(ctx context.Context, courseID string, opts *ListRosterOptions) ([]*Student, error)
+	RemoveStudent(ctx context.Context, courseID, userID string) error
+	ListTeachers(ctx context.Context, courseID string, opts *ListRosterOptions) ([]*Teacher, error)
+	RemoveTeacher(ctx context.Context, courseID, userID string) error
+	IsTeacher(ctx context.Context, courseID string) (bool, error)
+	GetUserProfile(ctx context.Context, userID string) (*UserProfile, error)
+
+	ListInvitations(ctx context.Context) ([]*Invitation, error)
+	AcceptInvitation(ctx context.Context, invitationID string) error
+	DeleteInvitation(ctx context.Context, invitationID string) error
+	CreateInvitation(ctx context.Context, courseID, userID, role string) (*Invitation, error)
+}
+
+var _ ClassroomClient = (*Client)(nil)
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a RoundTripper to observe or change requests, e.g. for
+// logging, metrics, tracing, or extra headers.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps rt in the middleware. The first middleware is the outermost,
+// so it sees each request first and each response last.
+func Chain(rt http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
+
+// redactedParams are query parameters whose values are never logged.
+var redactedParams = []string{"access_token", "key", "client_secret", "code", "refresh_token"}
+
+// DebugLogging returns middleware that writes one line per request to w with
+// the method, URL, status, duration, and response size. Credentials in the
+// query string are redacted and headers are never written.
+func DebugLogging(w io.Writer) Middleware {
+	var mu sync.Mutex
+	logf := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, time.Now().Format("2006-01-02T15:04:05.000")+" "+format+"\n", args...)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			if err != nil {
+				logf("%s %s error after %s: %v", req.Method, redactURL(req.URL), elapsed, err)
+				return resp, err
+			}
+			logf("%s %s %s %s %dB", req.Method, redactURL(req.URL), resp.Status, elapsed, resp.ContentLength)
+			return resp, nil
+		})
+	}
+}
+
+// openDebugLog opens path for appending, creating its directory.
+func openDebugLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create debug log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open debug log: %w", err)
+	}
+	return f, nil
+}
+
+// redactURL returns u as a string with credential parameters hidden.
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	q := u.Query()
+	for _, name := range redactedParams {
+		if q.Has(name) {
+			q.Set(name, "REDACTED")
+		}
+	}
+	cp := *u
+	cp.RawQuery = q.Encode()
+	return cp.String()
+}
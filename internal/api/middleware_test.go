@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestChain tests that middleware runs outermost first.
+func TestChain(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		order = append(order, "base")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if _, err := Chain(base, mark("first"), mark("second")).RoundTrip(req); err != nil {
+		t.Fatalf("Round trip failed: %v", err)
+	}
+
+	if strings.Join(order, ",") != "first,second,base" {
+		t.Errorf("Expected first,second,base, got %v", order)
+	}
+}
+
+// TestDebugLogging tests request logging and credential redaction.
+func TestDebugLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: Chain(http.DefaultTransport, DebugLogging(&buf))}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/courses?access_token=secret&pageSize=5", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	line := buf.String()
+	if strings.Contains(line, "secret") {
+		t.Errorf("Expected credentials to be redacted, got %q", line)
+	}
+	for _, want := range []string{"GET", "/v1/courses", "pageSize=5", "404 Not Found"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected log line to contain %q, got %q", want, line)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// recordedHeaders are the response headers kept in fixtures. Everything
+// else, including cookies, is dropped.
+var recordedHeaders = []string{"Content-Type", "Etag", "Retry-After"}
+
+// scrubbedFields are JSON fields whose values are replaced in recorded
+// bodies, so token refreshes that pass through the client are safe to
+// commit.
+var scrubbedFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"client_secret": true,
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	// Body holds a JSON response body as-is. Text holds any other body;
+	// recordings leave it empty, but fixtures written by hand may set it.
+	Body json.RawMessage `json:"body,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// Cassette is a sequence of interactions stored as a fixture file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a fixture file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// Recorder records exchanges into a fixture. Credentials are scrubbed
+// from URLs and bodies, and request headers are never written. Only JSON
+// bodies are kept, so file downloads stream through without being read
+// into memory. Nothing is written until Close.
+type Recorder struct {
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a recorder that writes its fixture to path.
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Middleware returns middleware that records every exchange. A response
+// body that cannot be read fails the request, as it would without
+// recording.
+func (r *Recorder) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return resp, err
+			}
+
+			in := Interaction{
+				Method: req.Method,
+				URL:    scrubURL(req.URL),
+				Status: resp.StatusCode,
+				Header: http.Header{},
+			}
+			for _, name := range recordedHeaders {
+				if v := resp.Header.Values(name); len(v) > 0 {
+					in.Header[name] = v
+				}
+			}
+			if isJSON(resp.Header) {
+				body, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				in.setBody(body)
+			}
+
+			r.mu.Lock()
+			r.cassette.Interactions = append(r.cassette.Interactions, in)
+			r.mu.Unlock()
+			return resp, nil
+		})
+	}
+}
+
+// Close writes the fixture with everything recorded so far.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// setBody stores body as JSON when it parses, scrubbing credentials, and
+// as text otherwise.
+func (in *Interaction) setBody(body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		in.Text = string(body)
+		return
+	}
+	scrubbed, err := json.Marshal(scrubJSON(v))
+	if err != nil {
+		in.Text = string(body)
+		return
+	}
+	in.Body = scrubbed
+}
+
+// scrubJSON replaces the values of credential fields anywhere in v.
+func scrubJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if scrubbedFields[k] {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = scrubJSON(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = scrubJSON(child)
+		}
+	}
+	return v
+}
+
+// scrubURL returns u without credential query parameters.
+func scrubURL(u *url.URL) string {
+	cp := *u
+	q := cp.Query()
+	for _, name := range redactedParams {
+		q.Del(name)
+	}
+	cp.RawQuery = q.Encode()
+	return cp.String()
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// instead of the network. Each interaction is used once, in order, so a
+// fixture can hold a failure followed by the retried success.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer creates a replayer for c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}
+}
+
+// RoundTrip answers req with the first unused interaction that matches.
+// A request matches when the method and path are equal and every query
+// parameter in the recorded URL has the same value in the request, so
+// hand-written fixtures can leave out parameters such as fields.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || !in.matches(req) {
+			continue
+		}
+		r.used[i] = true
+		return in.response(req), nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s %s", req.Method, scrubURL(req.URL))
+}
+
+// Unused returns the interactions that were never replayed, so tests can
+// check that every expected request was made.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, in := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
+
+func (in *Interaction) matches(req *http.Request) bool {
+	if !strings.EqualFold(in.Method, req.Method) {
+		return false
+	}
+	recorded, err := url.Parse(in.URL)
+	if err != nil || recorded.Path != req.URL.Path {
+		return false
+	}
+	got := req.URL.Query()
+	for name, want := range recorded.Query() {
+		values := got[name]
+		if len(values) != len(want) {
+			return false
+		}
+		for i := range want {
+			if values[i] != want[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (in *Interaction) response(req *http.Request) *http.Response {
+	body := []byte(in.Text)
+	if len(in.Body) > 0 {
+		body = in.Body
+	}
+	header := in.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if header.Get("Content-Type") == "" && len(in.Body) > 0 {
+		header.Set("Content-Type", "application/json")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	"golang.org/x/oauth2"
+)
+
+// newReplayClient returns a client whose requests are answered from the
+// fixture in testdata.
+func newReplayClient(t *testing.T, fixture string) (*Client, *Replayer) {
+	t.Helper()
+	cassette, err := LoadCassette(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	replayer := NewReplayer(cassette)
+
+	cfg := DefaultConfiguration()
+	cfg.Transport = replayer
+	cfg.DisableETags = true
+	cfg.Retry = &backoff.Policy{Initial: time.Millisecond, Multiplier: 1, MaxAttempts: 3}
+	client, err := NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test"}), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client, replayer
+}
+
+// TestReplayPagination tests that ListCourses follows page tokens.
+func TestReplayPagination(t *testing.T) {
+	client, replayer := newReplayClient(t, "courses_paged.json")
+
+	courses, err := client.ListCourses(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to list courses: %v", err)
+	}
+	if len(courses) != 3 {
+		t.Fatalf("Expected 3 courses across 2 pages, got %d", len(courses))
+	}
+	if courses[2].Name != "Intro Chemistry" || courses[2].CourseState != CourseStateArchived {
+		t.Errorf("Expected archived Intro Chemistry last, got %+v", courses[2])
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Expected every page to be requested, %d left", len(unused))
+	}
+}
+
+// TestReplayRetry tests that a 503 is retried.
+func TestReplayRetry(t *testing.T) {
+	client, replayer := newReplayClient(t, "course_retry.json")
+
+	course, err := client.GetCourse(context.Background(), "612345678901")
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if course.Name != "Biology 101" {
+		t.Errorf("Expected Biology 101, got %q", course.Name)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Expected the failure and the retry to be replayed, %d left", len(unused))
+	}
+
+	if _, err := client.GetCourse(context.Background(), "612345678901"); err == nil {
+		t.Error("Expected error once the fixture is used up")
+	}
+}
+
+// TestRecorder tests that recordings are scrubbed, keep only JSON bodies,
+// are written on Close, and can be replayed.
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.7"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"id":"c1","access_token":"ya29.secret"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	recorder := NewRecorder(path)
+	rt := Chain(http.DefaultTransport, recorder.Middleware())
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/courses/c1?key=apikey&fields=id", nil)
+	req.Header.Set("Authorization", "Bearer ya29.secret")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "ya29.secret") {
+		t.Error("Expected the caller to receive the unmodified body")
+	}
+
+	download, _ := http.NewRequest(http.MethodGet, server.URL+"/download", nil)
+	resp, err = rt.RoundTrip(download)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "%PDF-1.7" {
+		t.Errorf("Expected the download to stream through, got %q", body)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing written before Close, got %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("Failed to load recording: %v", err)
+	}
+	if len(cassette.Interactions) != 2 {
+		t.Fatalf("Expected 2 interactions, got %d", len(cassette.Interactions))
+	}
+	if in := cassette.Interactions[1]; in.Body != nil || in.Text != "" {
+		t.Errorf("Expected no body for the download, got %s%s", in.Body, in.Text)
+	}
+	in := cassette.Interactions[0]
+	if strings.Contains(in.URL, "apikey") || strings.Contains(string(in.Body), "ya29") {
+		t.Errorf("Expected credentials to be scrubbed, got %s %s", in.URL, in.Body)
+	}
+	if in.Header.Get("Set-Cookie") != "" {
+		t.Error("Expected cookies to be dropped")
+	}
+
+	replayed, err := NewReplayer(cassette).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	body, _ = io.ReadAll(replayed.Body)
+	if !strings.Contains(string(body), `"c1"`) {
+		t.Errorf("Expected recorded body, got %s", body)
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	"google.golang.org/api/googleapi"
+)
+
+// rateLimitReasons are 403 error reasons that mean "slow down" rather than
+// "not allowed".
+var rateLimitReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+}
+
+// isRetryable reports whether an error may succeed on a later attempt:
+// rate limiting, transient server errors, and transport failures. Other API
+// errors such as 400, 401, 403, and 404 are final.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		// Connection resets, DNS failures, and the like.
+		return true
+	}
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		return true
+	case apiErr.Code == http.StatusForbidden:
+		return isRateLimitError(apiErr)
+	case apiErr.Code == http.StatusInternalServerError,
+		apiErr.Code == http.StatusBadGateway,
+		apiErr.Code == http.StatusServiceUnavailable,
+		apiErr.Code == http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableWrite reports whether a call that changes something may be
+// retried after err: only when the server turned the request away, by
+// rate limiting it or by asking to come back later with Retry-After.
+// Transport failures and other server errors leave it unknown whether the
+// change was made.
+func isRetryableWrite(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return isRateLimitError(apiErr) || retryAfter(err) > 0
+}
+
+// writeRetryPolicy returns p narrowed to the errors isRetryableWrite
+// allows.
+func writeRetryPolicy(p *backoff.Policy) *backoff.Policy {
+	retryable := p.Retryable
+	return p.WithRetryable(func(err error) bool {
+		return retryable(err) && isRetryableWrite(err)
+	})
+}
+
+// ReadRetryPolicy returns p retrying the errors a read from any Google API
+// may succeed after: rate limiting, transient server errors, and transport
+// failures, waiting as long as Retry-After asks. Other clients, such as
+// Drive, use it to retry like Client.
+func ReadRetryPolicy(p *backoff.Policy) *backoff.Policy {
+	return p.WithRetryable(isRetryable).WithRetryAfter(retryAfter)
+}
+
+// isRateLimitError reports whether an API error is a rate or quota limit.
+func isRateLimitError(apiErr *googleapi.Error) bool {
+	if apiErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	for _, item := range apiErr.Errors {
+		if rateLimitReasons[item.Reason] {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter returns the wait requested by a Retry-After header, given as
+// either seconds or an HTTP date. It returns zero when there is none.
+func retryAfter(err error) time.Duration {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0
+	}
+
+	value := apiErr.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// TestIsRetryable tests classifying API and transport errors.
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &googleapi.Error{Code: 429}, true},
+		{"quota 403", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, true},
+		{"forbidden", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}}, false},
+		{"not found", &googleapi.Error{Code: 404}, false},
+		{"unavailable", &googleapi.Error{Code: 503}, true},
+		{"wrapped server error", fmt.Errorf("failed: %w", &googleapi.Error{Code: 500}), true},
+		{"not implemented", &googleapi.Error{Code: 501}, false},
+		{"transport", errors.New("connection reset by peer"), true},
+		{"cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+// TestRetryAfter tests parsing Retry-After headers.
+func TestRetryAfter(t *testing.T) {
+	withHeader := func(value string) error {
+		h := http.Header{}
+		h.Set("Retry-After", value)
+		return &googleapi.Error{Code: 429, Header: h}
+	}
+
+	if got := retryAfter(withHeader("7")); got != 7*time.Second {
+		t.Errorf("Expected 7s, got %v", got)
+	}
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := retryAfter(withHeader(date)); got <= 0 || got > time.Minute {
+		t.Errorf("Expected up to 1m from HTTP date, got %v", got)
+	}
+
+	if got := retryAfter(withHeader("soon")); got != 0 {
+		t.Errorf("Expected no wait for invalid header, got %v", got)
+	}
+	if got := retryAfter(errors.New("plain")); got != 0 {
+		t.Errorf("Expected no wait for non-API error, got %v", got)
+	}
+}
+
+// TestIsRetryableWrite tests which errors a write may be retried after.
+func TestIsRetryableWrite(t *testing.T) {
+	retryLater := http.Header{}
+	retryLater.Set("Retry-After", "1")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &googleapi.Error{Code: 429}, true},
+		{"quota 403", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, true},
+		{"unavailable", &googleapi.Error{Code: 503}, false},
+		{"unavailable with Retry-After", &googleapi.Error{Code: 503, Header: retryLater}, true},
+		{"server error", &googleapi.Error{Code: 500}, false},
+		{"transport", errors.New("connection reset by peer"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryableWrite(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// EndpointStats counts the requests sent to one API method.
+type EndpointStats struct {
+	// Requests counts every attempt, including retries.
+	Requests int
+	// Retries counts attempts after the first of a call.
+	Retries int
+	// RateLimited counts attempts rejected with 429 or a rate-limit 403.
+	RateLimited int
+	// Errors counts failed attempts, including rate-limited ones.
+	Errors int
+	// TotalLatency and MaxLatency cover the time spent waiting for the
+	// server, not client-side throttling.
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// AverageLatency returns the mean latency of the requests.
+func (e EndpointStats) AverageLatency() time.Duration {
+	if e.Requests == 0 {
+		return 0
+	}
+	return e.TotalLatency / time.Duration(e.Requests)
+}
+
+// add merges o into e.
+func (e *EndpointStats) add(o EndpointStats) {
+	e.Requests += o.Requests
+	e.Retries += o.Retries
+	e.RateLimited += o.RateLimited
+	e.Errors += o.Errors
+	e.TotalLatency += o.TotalLatency
+	e.MaxLatency = max(e.MaxLatency, o.MaxLatency)
+}
+
+// Stats is a snapshot of a client's request metrics.
+type Stats struct {
+	// Since is when counting started.
+	Since time.Time
+	// Endpoints maps API method names, such as "courses.courseWork.list",
+	// to their counts.
+	Endpoints map[string]EndpointStats
+	// LastMinute counts requests sent in the past minute.
+	LastMinute int
+	// QuotaPerMinute is the client-side per-minute limit, or zero when
+	// there is none.
+	QuotaPerMinute int
+}
+
+// Total sums the counts of every endpoint.
+func (s Stats) Total() EndpointStats {
+	var total EndpointStats
+	for _, e := range s.Endpoints {
+		total.add(e)
+	}
+	return total
+}
+
+// EndpointNames returns the endpoint names, busiest first.
+func (s Stats) EndpointNames() []string {
+	names := make([]string, 0, len(s.Endpoints))
+	for name := range s.Endpoints {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.Endpoints[names[i]], s.Endpoints[names[j]]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// QuotaFraction returns the share of the per-minute quota used in the past
+// minute, or 0 without a quota.
+func (s Stats) QuotaFraction() float64 {
+	if s.QuotaPerMinute <= 0 {
+		return 0
+	}
+	return float64(s.LastMinute) / float64(s.QuotaPerMinute)
+}
+
+// String summarizes the stats, e.g. "42 requests, 3 retries, 1 rate
+// limited, 12/1000 in the last minute".
+func (s Stats) String() string {
+	total := s.Total()
+	parts := []string{plural(total.Requests, "request")}
+	if total.Retries > 0 {
+		parts = append(parts, plural(total.Retries, "retry"))
+	}
+	if total.RateLimited > 0 {
+		parts = append(parts, fmt.Sprintf("%d rate limited", total.RateLimited))
+	}
+	if s.QuotaPerMinute > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d in the last minute", s.LastMinute, s.QuotaPerMinute))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d in the last minute", s.LastMinute))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural formats n with noun, pluralized when n is not one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// metrics collects request counts for Client.Stats. It is safe for
+// concurrent use.
+type metrics struct {
+	quotaPerMinute int
+	now            func() time.Time
+
+	mu        sync.Mutex
+	since     time.Time
+	endpoints map[string]*EndpointStats
+	// recent holds the send times of the past minute's requests, oldest
+	// first.
+	recent []time.Time
+}
+
+// newMetrics creates an empty metrics collector.
+func newMetrics(quotaPerMinute int) *metrics {
+	return &metrics{
+		quotaPerMinute: quotaPerMinute,
+		now:            time.Now,
+		since:          time.Now(),
+		endpoints:      make(map[string]*EndpointStats),
+	}
+}
+
+// record counts one attempt at endpoint that took latency and failed with
+// err, or succeeded when err is nil.
+func (m *metrics) record(endpoint string, retry bool, latency time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.endpoints[endpoint]
+	if e == nil {
+		e = &EndpointStats{}
+		m.endpoints[endpoint] = e
+	}
+	e.Requests++
+	if retry {
+		e.Retries++
+	}
+	if err != nil {
+		e.Errors++
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && isRateLimitError(apiErr) {
+			e.RateLimited++
+		}
+	}
+	e.TotalLatency += latency
+	e.MaxLatency = max(e.MaxLatency, latency)
+
+	now := m.now()
+	m.trim(now)
+	m.recent = append(m.recent, now)
+}
+
+// trim drops request times older than a minute. Callers hold m.mu.
+func (m *metrics) trim(now time.Time) {
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(m.recent) && !m.recent[i].After(cutoff) {
+		i++
+	}
+	m.recent = m.recent[i:]
+}
+
+// snapshot returns the current stats.
+func (m *metrics) snapshot() Stats {
+	if m == nil {
+		return Stats{Endpoints: map[string]EndpointStats{}}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.trim(m.now())
+	s := Stats{
+		Since:          m.since,
+		Endpoints:      make(map[string]EndpointStats, len(m.endpoints)),
+		LastMinute:     len(m.recent),
+		QuotaPerMinute: m.quotaPerMinute,
+	}
+	for name, e := range m.endpoints {
+		s.Endpoints[name] = *e
+	}
+	return s
+}
+
+// Stats returns the client's request counts, retries, rate-limit
+// rejections, and latency per endpoint since it was created.
+func (c *Client) Stats() Stats {
+	return c.metrics.snapshot()
+}
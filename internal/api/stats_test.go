@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	"golang.org/x/oauth2"
+)
+
+// TestStats tests counting requests, retries, and rate-limit rejections
+// per endpoint.
+func TestStats(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"code": 429, "message": "slow down"}}`))
+			return
+		}
+		w.Write([]byte(`{"id": "123", "name": "Test Course"}`))
+	}))
+	defer server.Close()
+
+	ts := &mockTokenSource{token: &oauth2.Token{AccessToken: "test_token"}}
+	client, err := NewClient(context.Background(), ts, &Configuration{
+		Endpoint:       server.URL + "/",
+		Retry:          &backoff.Policy{Initial: time.Millisecond, MaxAttempts: 3},
+		QuotaPerMinute: 1000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.GetCourse(context.Background(), "123"); err != nil {
+		t.Fatalf("Failed to get course: %v", err)
+	}
+	if _, err := client.GetCourse(context.Background(), "123"); err != nil {
+		t.Fatalf("Failed to get course: %v", err)
+	}
+
+	stats := client.Stats()
+	got := stats.Endpoints["courses.get"]
+	if got.Requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", got.Requests)
+	}
+	if got.Retries != 1 {
+		t.Errorf("Expected 1 retry, got %d", got.Retries)
+	}
+	if got.RateLimited != 1 || got.Errors != 1 {
+		t.Errorf("Expected 1 rate-limited error, got %d rate limited and %d errors", got.RateLimited, got.Errors)
+	}
+	if stats.LastMinute != 3 {
+		t.Errorf("Expected 3 requests in the last minute, got %d", stats.LastMinute)
+	}
+	if want := "3 requests, 1 retry, 1 rate limited, 3/1000 in the last minute"; stats.String() != want {
+		t.Errorf("Expected %q, got %q", want, stats.String())
+	}
+}
+
+// TestStatsLastMinute tests that requests older than a minute leave the
+// recent count.
+func TestStatsLastMinute(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	m := newMetrics(0)
+	m.now = func() time.Time { return now }
+
+	m.record("courses.list", false, 10*time.Millisecond, nil)
+	now = now.Add(30 * time.Second)
+	m.record("courses.get", false, 30*time.Millisecond, nil)
+	now = now.Add(45 * time.Second)
+
+	stats := m.snapshot()
+	if stats.LastMinute != 1 {
+		t.Errorf("Expected 1 request in the last minute, got %d", stats.LastMinute)
+	}
+	total := stats.Total()
+	if total.Requests != 2 {
+		t.Errorf("Expected 2 requests in total, got %d", total.Requests)
+	}
+	if total.AverageLatency() != 20*time.Millisecond || total.MaxLatency != 30*time.Millisecond {
+		t.Errorf("Expected 20ms average and 30ms max latency, got %v and %v", total.AverageLatency(), total.MaxLatency)
+	}
+}
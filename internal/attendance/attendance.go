@@ -0,0 +1,252 @@
+// Package attendance tracks daily check-ins. A teacher posts a short-answer
+// "check-in" question each day; students who answer are counted present.
+// Check-ins are ordinary coursework titled "Check-in YYYY-MM-DD", so they
+// need no storage beyond Classroom itself.
+package attendance
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/collation"
+)
+
+// TitlePrefix starts the title of every check-in question.
+const TitlePrefix = "Check-in "
+
+// DefaultQuestion is asked when no question is given.
+const DefaultQuestion = "Checking in for today: how are you doing?"
+
+// dateLayout formats the day in a check-in title.
+const dateLayout = "2006-01-02"
+
+// ErrAlreadyPosted means the day already has a check-in.
+var ErrAlreadyPosted = errors.New("a check-in is already posted for that day")
+
+// Source provides the data attendance reads and writes. api.ClassroomClient
+// satisfies it.
+type Source interface {
+	ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error)
+	CreateCourseWork(ctx context.Context, courseID string, cw *api.CourseWork) (*api.CourseWork, error)
+	ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error)
+	ListStudents(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Student, error)
+}
+
+// Title returns the check-in title for day.
+func Title(day time.Time) string {
+	return TitlePrefix + day.Format(dateLayout)
+}
+
+// Day returns the day of a check-in title, or false if title is not one.
+func Day(title string) (string, bool) {
+	day, ok := strings.CutPrefix(title, TitlePrefix)
+	if !ok {
+		return "", false
+	}
+	if _, err := time.Parse(dateLayout, day); err != nil {
+		return "", false
+	}
+	return day, true
+}
+
+// Post publishes the check-in question for day, due at the end of that
+// day. An empty question uses DefaultQuestion.
+func Post(ctx context.Context, src Source, courseID string, day time.Time, question string) (*api.CourseWork, error) {
+	checkIns, err := list(ctx, src, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := checkIns[day.Format(dateLayout)]; ok {
+		return nil, ErrAlreadyPosted
+	}
+
+	if question == "" {
+		question = DefaultQuestion
+	}
+	// The API takes the due date and time in UTC
+	due := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 0, 0, day.Location()).UTC()
+	cw, err := src.CreateCourseWork(ctx, courseID, &api.CourseWork{
+		Title:       Title(day),
+		Description: question,
+		WorkType:    api.WorkTypeShortAnswer,
+		State:       api.CourseWorkStatePublished,
+		DueDate:     due.Format(dateLayout),
+		DueTime:     due.Format("15:04"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to post check-in: %w", err)
+	}
+	return cw, nil
+}
+
+// list returns the course's check-ins keyed by day.
+func list(ctx context.Context, src Source, courseID string) (map[string]*api.CourseWork, error) {
+	coursework, err := src.ListCourseWork(ctx, courseID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coursework: %w", err)
+	}
+	checkIns := make(map[string]*api.CourseWork)
+	for _, cw := range coursework {
+		if day, ok := Day(cw.Title); ok {
+			checkIns[day] = cw
+		}
+	}
+	return checkIns, nil
+}
+
+// Mark is a student's attendance on one day.
+type Mark int
+
+const (
+	// Absent means the student did not answer.
+	Absent Mark = iota
+	// Present means the student answered on time.
+	Present
+	// Late means the student answered after the day ended.
+	Late
+)
+
+func (m Mark) String() string {
+	switch m {
+	case Present:
+		return "present"
+	case Late:
+		return "late"
+	default:
+		return "absent"
+	}
+}
+
+// Symbol returns a one-character form of the mark for the matrix view.
+func (m Mark) Symbol() string {
+	switch m {
+	case Present:
+		return "✓"
+	case Late:
+		return "L"
+	default:
+		return "·"
+	}
+}
+
+// Row is one student's attendance across the matrix days.
+type Row struct {
+	UserID string
+	Name   string
+	Marks  []Mark
+}
+
+// Attended returns the number of days the student answered, late or not.
+func (r Row) Attended() int {
+	n := 0
+	for _, m := range r.Marks {
+		if m != Absent {
+			n++
+		}
+	}
+	return n
+}
+
+// Matrix is attendance for a course over the days that had a check-in.
+type Matrix struct {
+	CourseID string
+	// Days are the check-in days in order, formatted YYYY-MM-DD.
+	Days []string
+	// Rows are sorted by student name.
+	Rows []Row
+}
+
+// Build collects attendance for check-ins from from to to, inclusive.
+func Build(ctx context.Context, src Source, courseID string, from, to time.Time) (*Matrix, error) {
+	checkIns, err := list(ctx, src, courseID)
+	if err != nil {
+		return nil, err
+	}
+	students, err := src.ListStudents(ctx, courseID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+
+	first, last := from.Format(dateLayout), to.Format(dateLayout)
+	m := &Matrix{CourseID: courseID}
+	for day := range checkIns {
+		if day >= first && day <= last {
+			m.Days = append(m.Days, day)
+		}
+	}
+	sort.Strings(m.Days)
+
+	index := make(map[string]int, len(students))
+	for i, s := range students {
+		name := s.Profile.Name
+		if name == "" {
+			name = s.UserID
+		}
+		m.Rows = append(m.Rows, Row{UserID: s.UserID, Name: name, Marks: make([]Mark, len(m.Days))})
+		index[s.UserID] = i
+	}
+
+	for col, day := range m.Days {
+		submissions, err := src.ListStudentSubmissions(ctx, courseID, checkIns[day].ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list responses for %s: %w", day, err)
+		}
+		for _, s := range submissions {
+			row, ok := index[s.UserID]
+			if !ok {
+				continue
+			}
+			m.Rows[row].Marks[col] = markFor(s)
+		}
+	}
+
+	collation.Sort(m.Rows, func(r Row) string { return r.Name })
+	return m, nil
+}
+
+// markFor returns the mark for a check-in submission.
+func markFor(s *api.StudentSubmission) Mark {
+	switch s.State {
+	case api.SubmissionStateTurnedIn, api.SubmissionStateReturned:
+		if s.Late {
+			return Late
+		}
+		return Present
+	default:
+		return Absent
+	}
+}
+
+// WriteCSV writes the matrix with one row per student, one column per day,
+// and a total.
+func (m *Matrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := append([]string{"user_id", "name"}, m.Days...)
+	header = append(header, "attended")
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, r := range m.Rows {
+		record := []string{r.UserID, r.Name}
+		for _, mark := range r.Marks {
+			record = append(record, mark.String())
+		}
+		record = append(record, strconv.Itoa(r.Attended()))
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package attendance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+)
+
+// newCourse returns a fake course with two students.
+func newCourse(t *testing.T) *fake.Client {
+	t.Helper()
+	c := fake.New("teacher")
+	c.AddCourse(&api.Course{ID: "c1", Name: "Homeroom", OwnerID: "teacher"})
+	c.AddStudent(&api.Student{CourseID: "c1", UserID: "s1", Profile: api.UserProfile{Name: "Zoë Okafor"}})
+	c.AddStudent(&api.Student{CourseID: "c1", UserID: "s2", Profile: api.UserProfile{Name: "Jordan Lee"}})
+	return c
+}
+
+// respond turns in userID's answer to the check-in.
+func respond(t *testing.T, c *fake.Client, cw *api.CourseWork, userID string) {
+	t.Helper()
+	subs, err := c.ListStudentSubmissions(context.Background(), "c1", cw.ID, &api.ListStudentSubmissionsOptions{UserID: userID})
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("Expected one submission for %s, got %v, %v", userID, subs, err)
+	}
+	if err := c.TurnIn(context.Background(), "c1", cw.ID, subs[0].ID); err != nil {
+		t.Fatalf("TurnIn failed: %v", err)
+	}
+}
+
+// TestPost tests posting a check-in once per day.
+func TestPost(t *testing.T) {
+	c := newCourse(t)
+	ctx := context.Background()
+	day := time.Date(2025, 10, 6, 8, 0, 0, 0, time.Local)
+
+	cw, err := Post(ctx, c, "c1", day, "")
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if cw.Title != "Check-in 2025-10-06" || cw.Description != DefaultQuestion {
+		t.Errorf("Expected default check-in for 2025-10-06, got %q: %q", cw.Title, cw.Description)
+	}
+	if cw.WorkType != "SHORT_ANSWER_QUESTION" {
+		t.Errorf("Expected a short-answer question, got %s", cw.WorkType)
+	}
+
+	if _, err := Post(ctx, c, "c1", day, ""); !errors.Is(err, ErrAlreadyPosted) {
+		t.Errorf("Expected ErrAlreadyPosted, got %v", err)
+	}
+}
+
+// TestBuild tests aggregating responses into a matrix.
+func TestBuild(t *testing.T) {
+	c := newCourse(t)
+	ctx := context.Background()
+	mon := time.Date(2025, 10, 6, 8, 0, 0, 0, time.Local)
+	tue := mon.AddDate(0, 0, 1)
+
+	first, _ := Post(ctx, c, "c1", mon, "")
+	second, _ := Post(ctx, c, "c1", tue, "")
+	c.AddCourseWork(&api.CourseWork{CourseID: "c1", Title: "Essay"})
+	respond(t, c, first, "s1")
+	respond(t, c, first, "s2")
+	respond(t, c, second, "s2")
+
+	m, err := Build(ctx, c, "c1", mon, tue)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(m.Days) != 2 || m.Days[0] != "2025-10-06" {
+		t.Fatalf("Expected 2 check-in days, got %v", m.Days)
+	}
+	if m.Rows[0].Name != "Jordan Lee" {
+		t.Errorf("Expected rows sorted by name, got %s first", m.Rows[0].Name)
+	}
+	if got := m.Rows[0].Attended(); got != 2 {
+		t.Errorf("Expected Jordan to attend 2 days, got %d", got)
+	}
+	if m.Rows[1].Marks[1] != Absent {
+		t.Errorf("Expected Zoë absent on Tuesday, got %s", m.Rows[1].Marks[1])
+	}
+
+	// Only days in range are included
+	m, err = Build(ctx, c, "c1", tue, tue)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(m.Days) != 1 {
+		t.Errorf("Expected 1 day in range, got %v", m.Days)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "user_id,name,2025-10-07,attended\ns2,Jordan Lee,present,1\ns1,Zoë Okafor,absent,0\n"
+	if buf.String() != want {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+// TestRunCheckIn tests the post and report commands.
+func TestRunCheckIn(t *testing.T) {
+	c := newCourse(t)
+	ctx := context.Background()
+	var stdout, stderr bytes.Buffer
+
+	if err := RunCheckIn(ctx, c, []string{"post", "c1", "--date", "2025-10-06", "--question", "Here?"}, &stdout, &stderr); err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Check-in 2025-10-06") {
+		t.Errorf("Expected posted title, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := RunCheckIn(ctx, c, []string{"report", "c1", "--to", "2025-10-08"}, &stdout, &stderr); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "10-06") || !strings.Contains(stdout.String(), "0/1") {
+		t.Errorf("Expected matrix with one day, got:\n%s", stdout.String())
+	}
+
+	if err := RunCheckIn(ctx, c, []string{"report", "c1", "--from", "2025-10-09", "--to", "2025-10-08"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error for an empty range")
+	}
+	if err := RunCheckIn(ctx, c, []string{"list"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error for an unknown command")
+	}
+}
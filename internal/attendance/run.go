@@ -0,0 +1,139 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/user/google-classroom/internal/cli"
+)
+
+// DefaultDays is how many days a report covers when no range is given.
+const DefaultDays = 7
+
+// WriteText writes the matrix as an aligned table with a column per day
+// (month and day), ✓ present, L late, and · absent.
+func (m *Matrix) WriteText(w io.Writer) error {
+	if len(m.Days) == 0 {
+		_, err := fmt.Fprintln(w, "No check-ins in this range.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	header := []string{"STUDENT"}
+	for _, day := range m.Days {
+		header = append(header, day[5:])
+	}
+	header = append(header, "ATTENDED")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, r := range m.Rows {
+		cells := []string{r.Name}
+		for _, mark := range r.Marks {
+			cells = append(cells, mark.Symbol())
+		}
+		cells = append(cells, fmt.Sprintf("%d/%d", r.Attended(), len(m.Days)))
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// RunCheckIn implements `classroom checkin post <course> [--question text]
+// [--date YYYY-MM-DD]` and `classroom checkin report <course> [--from date]
+// [--to date] [--csv] [--output file]`.
+func RunCheckIn(ctx context.Context, src Source, args []string, stdout, stderr io.Writer) error {
+	const usage = "usage: checkin post|report <course> [flags]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	cmd, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("checkin "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		question, date, from, to, output *string
+		asCSV                            *bool
+	)
+	switch cmd {
+	case "post":
+		question = fs.String("question", DefaultQuestion, "the check-in `question`")
+		date = fs.String("date", "", "post for this `day` (YYYY-MM-DD) instead of today")
+	case "report":
+		from = fs.String("from", "", "first `day` (YYYY-MM-DD); defaults to a week before --to")
+		to = fs.String("to", "", "last `day` (YYYY-MM-DD); defaults to today")
+		asCSV = fs.Bool("csv", false, "write CSV instead of a table")
+		output = fs.String("output", "", "write to `file` instead of stdout")
+	default:
+		return fmt.Errorf("unknown command %q; %s", cmd, usage)
+	}
+
+	courseID, err := cli.ParseCourseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if courseID == "" {
+		return errors.New(usage)
+	}
+
+	today := time.Now()
+	if cmd == "post" {
+		day, err := parseDay(*date, today)
+		if err != nil {
+			return err
+		}
+		cw, err := Post(ctx, src, courseID, day, *question)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Posted %q (%s)\n", cw.Title, cw.ID)
+		return nil
+	}
+
+	last, err := parseDay(*to, today)
+	if err != nil {
+		return err
+	}
+	first, err := parseDay(*from, last.AddDate(0, 0, -(DefaultDays-1)))
+	if err != nil {
+		return err
+	}
+	if first.After(last) {
+		return fmt.Errorf("--from %s is after --to %s", first.Format(dateLayout), last.Format(dateLayout))
+	}
+
+	m, err := Build(ctx, src, courseID, first, last)
+	if err != nil {
+		return err
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *asCSV {
+		return m.WriteCSV(w)
+	}
+	return m.WriteText(w)
+}
+
+// parseDay parses a YYYY-MM-DD day in local time, or returns def for "".
+func parseDay(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	day, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", s)
+	}
+	return day, nil
+}
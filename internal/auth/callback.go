@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+// callbackPage is shown in the browser when Google redirects back to the
+// login server.
+var callbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - Google Classroom TUI</title>
+<style>
+  body { margin: 0; min-height: 100vh; display: flex; align-items: center; justify-content: center;
+         background: #282a36; color: #f8f8f2; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+  main { max-width: 32rem; padding: 2rem 2.5rem; border: 1px solid #44475a; border-radius: 8px; background: #1e1f29; }
+  .app { color: #ff79c6; font-weight: bold; letter-spacing: .05em; }
+  h1 { font-size: 1.4rem; margin: .75rem 0; color: {{if .OK}}#50fa7b{{else}}#ff5555{{end}}; }
+  p { line-height: 1.5; color: #bd93f9; }
+  .hint { color: #6272a4; }
+</style>
+</head>
+<body>
+<main>
+  <div class="app">&#9673; Google Classroom TUI</div>
+  <h1>{{if .OK}}&#10003;{{else}}&#10007;{{end}} {{.Title}}</h1>
+  <p>{{.Detail}}</p>
+  <p class="hint">{{if .OK}}You can close this tab and return to the terminal.{{else}}Close this tab and run the login again from the terminal.{{end}}</p>
+</main>
+</body>
+</html>
+`))
+
+// callbackResult fills callbackPage.
+type callbackResult struct {
+	OK     bool
+	Title  string
+	Detail string
+}
+
+// writeCallbackPage renders the result page with status.
+func writeCallbackPage(w http.ResponseWriter, status int, result callbackResult) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	callbackPage.Execute(w, result)
+}
+
+// authCode is an authorization code to exchange for a token. result,
+// when set, receives the outcome of the exchange; it has room for one.
+type authCode struct {
+	code   string
+	result chan error
+}
+
+// callbackHandler receives Google's redirect for one login attempt. It
+// sends the code on codes, or the failure on errs, and shows the result of
+// the exchange in the browser once it is known. Requests without the
+// login's state, such as a stale tab being reloaded, are turned away
+// without affecting the login. Sends never block, and the handler stops
+// waiting when stop is closed, so a late or repeated request can't hold up
+// the server's shutdown.
+func callbackHandler(state string, codes chan<- authCode, errs chan<- error, stop <-chan struct{}) http.HandlerFunc {
+	fail := func(w http.ResponseWriter, status int, err error, title, detail string) {
+		select {
+		case errs <- err:
+		default:
+		}
+		writeCallbackPage(w, status, callbackResult{Title: title, Detail: detail})
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		// Verify state
+		if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+			writeCallbackPage(w, http.StatusBadRequest, callbackResult{
+				Title:  "Login failed",
+				Detail: "This response does not belong to the login in progress.",
+			})
+			return
+		}
+
+		if e := query.Get("error"); e != "" {
+			detail, err := "Google returned "+e+".", fmt.Errorf("authorization failed: %s", e)
+			if e == "access_denied" {
+				detail, err = "Access was not granted, so the app cannot read your classes.", ErrLoginDenied
+			}
+			fail(w, http.StatusForbidden, err, "Login cancelled", detail)
+			return
+		}
+
+		// Get code
+		code := query.Get("code")
+		if code == "" {
+			fail(w, http.StatusBadRequest, fmt.Errorf("no code in callback"),
+				"Login failed", "Google's response did not include an authorization code.")
+			return
+		}
+
+		result := make(chan error, 1)
+		select {
+		case codes <- authCode{code: code, result: result}:
+		default:
+			// A code was already received, e.g. pasted or by an earlier
+			// load of this page
+			writeCallbackPage(w, http.StatusOK, callbackResult{
+				OK:     true,
+				Title:  "Code received",
+				Detail: "The login is being finished in the terminal.",
+			})
+			return
+		}
+
+		var err error
+		select {
+		case err = <-result:
+		case <-stop:
+			err = fmt.Errorf("login stopped")
+		case <-r.Context().Done():
+			return
+		}
+		if err != nil {
+			writeCallbackPage(w, http.StatusInternalServerError, callbackResult{
+				Title:  "Login failed",
+				Detail: "The app could not finish logging in; the terminal shows why.",
+			})
+			return
+		}
+		writeCallbackPage(w, http.StatusOK, callbackResult{
+			OK:     true,
+			Title:  "Logged in",
+			Detail: "Google Classroom TUI is now connected to your account.",
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// callbackServer serves callbackHandler for state "s1".
+func callbackServer(t *testing.T) (*httptest.Server, chan authCode, chan error) {
+	codes, errs, stop := make(chan authCode, 1), make(chan error, 1), make(chan struct{})
+	server := httptest.NewServer(callbackHandler("s1", codes, errs, stop))
+	t.Cleanup(func() {
+		close(stop)
+		server.Close()
+	})
+	return server, codes, errs
+}
+
+// page is a callback response.
+type page struct {
+	status int
+	body   string
+}
+
+// fetch requests url in the background.
+func fetch(t *testing.T, url string) <-chan page {
+	pages := make(chan page, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+			close(pages)
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		pages <- page{resp.StatusCode, string(body)}
+	}()
+	return pages
+}
+
+// TestCallbackWaitsForExchange tests that the browser is told the login
+// worked only once the code has been exchanged.
+func TestCallbackWaitsForExchange(t *testing.T) {
+	tests := []struct {
+		name   string
+		result error
+		status int
+		title  string
+	}{
+		{"exchanged", nil, http.StatusOK, "Logged in"},
+		{"exchange failed", errors.New("invalid_grant"), http.StatusInternalServerError, "Login failed"},
+	}
+
+	for _, tt := range tests {
+		server, codes, _ := callbackServer(t)
+		pages := fetch(t, server.URL+"/callback?state=s1&code=c1")
+
+		code := <-codes
+		if code.code != "c1" {
+			t.Errorf("%s: expected code c1, got %q", tt.name, code.code)
+		}
+		select {
+		case p := <-pages:
+			t.Fatalf("%s: expected the page to wait for the exchange, got %d", tt.name, p.status)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		code.result <- tt.result
+		p := <-pages
+		if p.status != tt.status || !strings.Contains(p.body, tt.title) {
+			t.Errorf("%s: expected %d %q, got %d", tt.name, tt.status, tt.title, p.status)
+		}
+		if tt.result != nil && strings.Contains(p.body, "Logged in") {
+			t.Errorf("%s: expected no success message", tt.name)
+		}
+	}
+}
+
+// TestCallbackIgnoresWrongState tests that requests without the login's
+// state are turned away and the login carries on.
+func TestCallbackIgnoresWrongState(t *testing.T) {
+	server, codes, errs := callbackServer(t)
+
+	for _, query := range []string{"?state=old&code=c0", "?code=c0", "?state=old&error=access_denied", ""} {
+		p := <-fetch(t, server.URL+"/callback"+query)
+		if p.status != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, p.status)
+		}
+	}
+	select {
+	case err := <-errs:
+		t.Fatalf("Expected the login to carry on, got %v", err)
+	case code := <-codes:
+		t.Fatalf("Expected no code, got %q", code.code)
+	default:
+	}
+
+	pages := fetch(t, server.URL+"/callback?state=s1&code=c1")
+	code := <-codes
+	code.result <- nil
+	if p := <-pages; p.status != http.StatusOK || code.code != "c1" {
+		t.Errorf("Expected the real callback to log in with c1, got %d with %q", p.status, code.code)
+	}
+}
+
+// TestCallbackDenied tests that a refusal on the consent page ends the
+// login.
+func TestCallbackDenied(t *testing.T) {
+	server, _, errs := callbackServer(t)
+	p := <-fetch(t, server.URL+"/callback?state=s1&error=access_denied")
+	if p.status != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d", p.status)
+	}
+	if err := <-errs; !errors.Is(err, ErrLoginDenied) {
+		t.Errorf("Expected ErrLoginDenied, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/oauth2"
+)
+
+// errDeviceScopes means Google does not allow the requested scopes in the
+// device flow. It refuses the Classroom scopes, so Login falls back to the
+// manual login.
+var errDeviceScopes = errors.New("Google does not allow Classroom access through the device login")
+
+// deviceLogin runs the OAuth device authorization flow for cfg: it shows a
+// code and a URL to enter it at, which works from any device, then polls
+// Google until the user approves and stores the resulting token, recording
+// the device client as its issuer.
+func (a *Authenticator) deviceLogin(ctx context.Context, cfg *oauth2.Config) error {
+	cfg = a.deviceConfig(cfg)
+
+	resp, err := cfg.DeviceAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start device login: %w", deviceError(err))
+	}
+
+	fmt.Println("To sign in, open this URL on any device:")
+	fmt.Println()
+	if resp.VerificationURIComplete != "" {
+		fmt.Printf("  %s\n", resp.VerificationURIComplete)
+	} else {
+		fmt.Printf("  %s\n", resp.VerificationURI)
+	}
+	fmt.Println()
+	fmt.Printf("and enter the code: %s\n", resp.UserCode)
+	if !resp.Expiry.IsZero() {
+		fmt.Printf("The code expires at %s.\n", resp.Expiry.Local().Format("15:04"))
+	}
+	fmt.Println("Waiting for approval...")
+
+	// DeviceAccessToken polls at the interval Google asks for and stops
+	// when the code expires
+	token, err := cfg.DeviceAccessToken(ctx, resp)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("%w: the code expired before it was entered", ErrLoginTimeout)
+		}
+		return fmt.Errorf("device login failed: %w", deviceError(err))
+	}
+
+	return a.saveLogin(ctx, cfg, token)
+}
+
+// deviceError explains the device flow errors users can act on.
+func deviceError(err error) error {
+	var re *oauth2.RetrieveError
+	if !errors.As(err, &re) {
+		return err
+	}
+	switch re.ErrorCode {
+	case "access_denied":
+		return ErrLoginDenied
+	case "invalid_client", "unauthorized_client":
+		return fmt.Errorf("%w (the device flow needs a \"TVs and Limited Input devices\" OAuth client; set device_client_id and device_client_secret in the config)", err)
+	case "invalid_scope":
+		return fmt.Errorf("%w: %v", errDeviceScopes, err)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// TestDeviceError tests explaining device flow errors.
+func TestDeviceError(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"access_denied", ErrLoginDenied},
+		{"invalid_scope", errDeviceScopes},
+	}
+
+	for _, tt := range tests {
+		err := deviceError(&oauth2.RetrieveError{ErrorCode: tt.code})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.code, tt.want, err)
+		}
+	}
+
+	err := deviceError(&oauth2.RetrieveError{ErrorCode: "unauthorized_client"})
+	if !strings.Contains(err.Error(), "device_client_id") {
+		t.Errorf("Expected a hint to configure the device client, got %v", err)
+	}
+}
+
+// TestDeviceLoginFallsBackToManual tests that a device login refused the
+// Classroom scopes continues by pasting a code, and that the token is
+// recorded as issued to the client that logged in.
+func TestDeviceLoginFallsBackToManual(t *testing.T) {
+	g := newFakeGoogle(t)
+	g.device = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_scope"}`))
+	}
+	a, store := newTestAuthenticator(g)
+	a.SetLoginMode(LoginDevice)
+	a.SetInput(strings.NewReader("pasted-code\n"))
+
+	if err := a.Login(g.context()); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if store.token == nil || store.token.AccessToken != "new" {
+		t.Fatalf("Expected the pasted code's token to be saved, got %+v", store.token)
+	}
+	if got := issuerOf(store.token); got != "desktop" {
+		t.Errorf("Expected the token issued to the desktop client, got %q", got)
+	}
+	if id := IdentityOf(store.token); id == nil || id.ID != "42" {
+		t.Errorf("Expected the identity to be saved, got %+v", id)
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Environment variables that configure authentication without a config
+// file, for CI jobs and containers. They override the config file.
+const (
+	EnvClientID     = "CLASSROOM_CLIENT_ID"
+	EnvClientSecret = "CLASSROOM_CLIENT_SECRET"
+	// EnvSubject is the user a service account impersonates, whether its
+	// key comes from the config or from EnvApplicationCredentials.
+	EnvSubject = "CLASSROOM_SUBJECT"
+	// EnvApplicationCredentials is the standard Application Default
+	// Credentials variable; setting it switches the app to ADC.
+	EnvApplicationCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+)
+
+// applyEnv overrides cfg with the credential environment variables.
+func applyEnv(cfg *Configuration) {
+	if v := os.Getenv(EnvClientID); v != "" {
+		cfg.ClientID = v
+	}
+	if v := os.Getenv(EnvClientSecret); v != "" {
+		cfg.ClientSecret = v
+	}
+	if v := os.Getenv(EnvSubject); v != "" {
+		cfg.Subject = v
+	}
+	if os.Getenv(EnvApplicationCredentials) != "" {
+		cfg.UseDefaultCredentials = true
+	}
+}
+
+// defaultCredentials are Application Default Credentials other than a
+// service account acting for a user: a gcloud user login, workload
+// identity federation, or the metadata server.
+type defaultCredentials struct {
+	creds *google.Credentials
+	kind  string
+}
+
+func (d *defaultCredentials) TokenSource(ctx context.Context) oauth2.TokenSource {
+	return d.creds.TokenSource
+}
+
+func (d *defaultCredentials) status() *TokenInfo {
+	return &TokenInfo{LoggedIn: true, Store: "application default credentials (" + d.kind + ")"}
+}
+
+// UseDefaultCredentials makes the Authenticator issue tokens from
+// Application Default Credentials: the key file named by
+// GOOGLE_APPLICATION_CREDENTIALS, gcloud's application default login, or
+// the metadata server on Google Cloud. Classroom data belongs to users, so
+// a service account key impersonates subject through domain-wide
+// delegation; without a subject it only sees courses it owns itself.
+func (a *Authenticator) UseDefaultCredentials(ctx context.Context, subject string) error {
+	scopes := a.credentialScopes()
+	creds, err := google.FindDefaultCredentials(ctx, scopes...)
+	if err != nil {
+		return fmt.Errorf("failed to find application default credentials: %w", err)
+	}
+
+	kind := credentialsType(creds.JSON)
+	if kind == "service_account" && subject != "" {
+		cfg, err := google.JWTConfigFromJSON(creds.JSON, scopes...)
+		if err != nil {
+			return fmt.Errorf("failed to parse service account key: %w", err)
+		}
+		cfg.Subject = subject
+		a.creds = &ServiceAccount{config: cfg}
+		return nil
+	}
+
+	a.creds = &defaultCredentials{creds: creds, kind: kind}
+	return nil
+}
+
+// credentialsType returns the type of a credentials file, or "metadata
+// server" for credentials that came from none.
+func credentialsType(data []byte) string {
+	if len(data) == 0 {
+		return "metadata server"
+	}
+	var f struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil || f.Type == "" {
+		return "unknown"
+	}
+	return f.Type
+}
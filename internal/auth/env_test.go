@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// TestApplyEnv tests that the environment overrides the config file, and
+// that unset variables leave it alone.
+func TestApplyEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"oauth": {"client_id": "file-id", "client_secret": "file-secret", "subject": "file@school.example"}}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Configuration
+	}{
+		{
+			name: "file only",
+			want: Configuration{ClientID: "file-id", ClientSecret: "file-secret", Subject: "file@school.example"},
+		},
+		{
+			name: "client from the environment",
+			env:  map[string]string{EnvClientID: "env-id", EnvClientSecret: "env-secret"},
+			want: Configuration{ClientID: "env-id", ClientSecret: "env-secret", Subject: "file@school.example"},
+		},
+		{
+			name: "subject from the environment",
+			env:  map[string]string{EnvSubject: "env@school.example"},
+			want: Configuration{ClientID: "file-id", ClientSecret: "file-secret", Subject: "env@school.example"},
+		},
+		{
+			name: "application default credentials",
+			env:  map[string]string{EnvApplicationCredentials: "/keys/ci.json"},
+			want: Configuration{ClientID: "file-id", ClientSecret: "file-secret", Subject: "file@school.example", UseDefaultCredentials: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{EnvClientID, EnvClientSecret, EnvSubject, EnvApplicationCredentials} {
+				t.Setenv(name, tt.env[name])
+			}
+			cfg, err := loadConfiguration(path)
+			if err != nil {
+				t.Fatalf("Failed to load: %v", err)
+			}
+			if *cfg != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, *cfg)
+			}
+		})
+	}
+}
+
+// TestCredentialsType tests naming where default credentials came from.
+func TestCredentialsType(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"", "metadata server"},
+		{`{"type": "service_account"}`, "service_account"},
+		{`{"type": "authorized_user"}`, "authorized_user"},
+		{`{"type": "external_account"}`, "external_account"},
+		{`{}`, "unknown"},
+		{"{not json", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := credentialsType([]byte(tt.data)); got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.data, tt.want, got)
+		}
+	}
+}
+
+// TestUseDefaultCredentials tests that a service account key found
+// through ADC impersonates the subject, and is used as itself without one.
+func TestUseDefaultCredentials(t *testing.T) {
+	t.Setenv(EnvApplicationCredentials, writeServiceAccountKey(t))
+
+	a := &Authenticator{config: &oauth2.Config{Scopes: []string{ScopeCourses}}}
+	if err := a.UseDefaultCredentials(context.Background(), "ada@school.example"); err != nil {
+		t.Fatalf("Failed to find the credentials: %v", err)
+	}
+	sa := a.ServiceAccount()
+	if sa == nil || sa.Subject() != "ada@school.example" {
+		t.Fatalf("Expected the service account to impersonate the subject, got %+v", sa)
+	}
+
+	if err := a.UseDefaultCredentials(context.Background(), ""); err != nil {
+		t.Fatalf("Failed to find the credentials: %v", err)
+	}
+	if a.ServiceAccount() != nil {
+		t.Error("Expected the key to be used as itself without a subject")
+	}
+	info, err := a.Status()
+	if err != nil || info.Store != "application default credentials (service_account)" {
+		t.Errorf("Expected the status to name the credentials, got %+v, %v", info, err)
+	}
+}
+
+// TestUseDefaultCredentialsMissing tests that a missing key file is
+// reported.
+func TestUseDefaultCredentialsMissing(t *testing.T) {
+	t.Setenv(EnvApplicationCredentials, filepath.Join(t.TempDir(), "missing.json"))
+
+	a := &Authenticator{config: &oauth2.Config{Scopes: []string{ScopeCourses}}}
+	if err := a.UseDefaultCredentials(context.Background(), ""); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// userProfileURL is Classroom's profile of the token's owner.
+const userProfileURL = "https://classroom.googleapis.com/v1/userProfiles/me"
+
+// Identity is the signed-in user as Classroom knows them. It is stored
+// with the token, so the app can tell the user's own submissions and
+// assignments apart without asking Classroom on every start.
+type Identity struct {
+	// ID is the Classroom user ID that submissions and assignees refer to.
+	ID string `json:"id"`
+	// Email is only known when the classroom.profile.emails scope is granted.
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// String returns the name and email address, whichever are known.
+func (i *Identity) String() string {
+	switch {
+	case i.Name != "" && i.Email != "":
+		return i.Name + " <" + i.Email + ">"
+	case i.Name != "":
+		return i.Name
+	case i.Email != "":
+		return i.Email
+	}
+	return i.ID
+}
+
+// fetchIdentity asks Classroom who the client's token belongs to.
+func fetchIdentity(ctx context.Context, client *http.Client) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userProfileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build profile request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user profile returned %s", resp.Status)
+	}
+
+	var profile struct {
+		ID   string `json:"id"`
+		Name struct {
+			FullName string `json:"fullName"`
+		} `json:"name"`
+		EmailAddress string `json:"emailAddress"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("failed to parse user profile: %w", err)
+	}
+	return &Identity{ID: profile.ID, Email: profile.EmailAddress, Name: profile.Name.FullName}, nil
+}
+
+// saveLogin stores a token obtained by logging in with cfg, along with the
+// identity of the user who logged in and the client it was issued to. The token is saved even when the
+// profile cannot be fetched; Identity asks again later. A login to an
+// account outside the profile's domain is rejected.
+func (a *Authenticator) saveLogin(ctx context.Context, cfg *oauth2.Config, token *oauth2.Token) error {
+	token = withIssuer(token, cfg.ClientID)
+	if id, err := fetchIdentity(ctx, cfg.Client(ctx, token)); err == nil {
+		if err := a.checkDomain(id); err != nil {
+			return err
+		}
+		token = withIdentity(token, id)
+	}
+	if err := a.SaveToken(token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// Identity returns the signed-in user. A token saved without one, e.g. by
+// an earlier version, has it fetched from Classroom and stored. Service
+// accounts and default credentials are looked up every time.
+func (a *Authenticator) Identity(ctx context.Context) (*Identity, error) {
+	ctx = a.httpContext(ctx)
+	if a.creds != nil {
+		return fetchIdentity(ctx, oauth2.NewClient(ctx, a.creds.TokenSource(ctx)))
+	}
+	token, err := a.loadToken()
+	if err != nil {
+		return nil, err
+	}
+	if id := IdentityOf(token); id != nil {
+		return id, nil
+	}
+
+	id, err := fetchIdentity(ctx, a.configFor(token).Client(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+	// The identity is fetched again next time if it cannot be saved
+	a.SaveToken(withIdentity(token, id))
+	return id, nil
+}
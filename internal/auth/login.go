@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Login timeouts used unless login_timeout or SetLoginTimeout says
+// otherwise. A device code is typed on another device, so it gets longer.
+const (
+	DefaultLoginTimeout       = 5 * time.Minute
+	DefaultDeviceLoginTimeout = 15 * time.Minute
+)
+
+var (
+	// ErrLoginTimeout means the user did not approve access in time.
+	ErrLoginTimeout = errors.New("login timed out")
+	// ErrLoginDenied means the user declined access on the consent page.
+	ErrLoginDenied = errors.New("access was denied")
+)
+
+// SetLoginTimeout sets how long Login waits for the user to approve access
+// in every mode. Zero waits until the context is done.
+func (a *Authenticator) SetLoginTimeout(d time.Duration) {
+	a.loginTimeout = &d
+}
+
+// SetInput sets where the manual login reads the pasted code from. The
+// default is os.Stdin.
+func (a *Authenticator) SetInput(in io.Reader) {
+	a.input = &lineInput{in: in}
+}
+
+// parseLoginTimeout reads the login_timeout setting, e.g. "10m". Empty
+// keeps the defaults, returned as nil, and "0" disables the timeout.
+func parseLoginTimeout(s string) (*time.Duration, error) {
+	if s == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf("invalid login_timeout %q: use a duration such as \"10m\", or \"0\" to wait until cancelled", s)
+	}
+	return &d, nil
+}
+
+// timeoutFor returns how long a login in mode waits for approval, or 0 for
+// no limit.
+func (a *Authenticator) timeoutFor(mode LoginMode) time.Duration {
+	switch {
+	case a.loginTimeout != nil:
+		return *a.loginTimeout
+	case mode == LoginDevice:
+		return DefaultDeviceLoginTimeout
+	}
+	return DefaultLoginTimeout
+}
+
+// withLoginTimeout runs a login step under the login timeout, reporting
+// the timeout as ErrLoginTimeout. Cancelling ctx stops it with ctx's error.
+func (a *Authenticator) withLoginTimeout(ctx context.Context, login func(context.Context) error) error {
+	timeout := a.timeoutFor(a.mode)
+	if timeout <= 0 {
+		return login(ctx)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := login(waitCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: no approval within %s", ErrLoginTimeout, timeout)
+	}
+	return err
+}
+
+// lineInput hands out lines read from in. One goroutine does the reading,
+// so a prompt abandoned when a login ends does not swallow the answer to
+// the next one.
+type lineInput struct {
+	in    io.Reader
+	once  sync.Once
+	lines chan string
+}
+
+// readLine returns the next line, or ctx's error if ctx is done first, or
+// io.EOF once the input is exhausted.
+func (l *lineInput) readLine(ctx context.Context) (string, error) {
+	l.once.Do(func() {
+		l.lines = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(l.in)
+			for scanner.Scan() {
+				l.lines <- scanner.Text()
+			}
+			close(l.lines)
+		}()
+	})
+	select {
+	case line, ok := <-l.lines:
+		if !ok {
+			return "", io.EOF
+		}
+		return line, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// retryable reports whether a failed login is worth trying again as is.
+func retryable(err error) bool {
+	return errors.Is(err, ErrLoginTimeout) || errors.Is(err, ErrLoginDenied)
+}
+
+// RunLogin implements `classroom auth login [--mode=M] [--timeout=D]`.
+// When the login times out or access is denied it asks on in whether to
+// try again; without an answer, as in scripts, it returns the error.
+func RunLogin(ctx context.Context, a *Authenticator, args []string, in io.Reader, out, stderr io.Writer) error {
+	fs := flag.NewFlagSet("auth login", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mode := fs.String("mode", "browser", "how to log in: browser, device, or manual")
+	timeout := fs.Duration("timeout", 0, "how long to wait for approval (default 5m, 15m for device; 0 waits until interrupted)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: auth login [--mode=browser|device|manual] [--timeout=5m]")
+	}
+	m, err := ParseLoginMode(*mode)
+	if err != nil {
+		return err
+	}
+	a.SetLoginMode(m)
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "timeout" {
+			a.SetLoginTimeout(*timeout)
+		}
+	})
+	a.SetInput(in)
+
+	for {
+		err := a.Login(ctx)
+		if err == nil {
+			fmt.Fprintln(out, "Logged in.")
+			return nil
+		}
+		if !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "%v. Try again? [Y/n] ", err)
+		answer, rerr := a.input.readLine(ctx)
+		if rerr != nil {
+			fmt.Fprintln(out)
+			return err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "" && answer != "y" && answer != "yes" {
+			return err
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseLoginTimeout tests reading the login_timeout setting.
+func TestParseLoginTimeout(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    *time.Duration
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"10m", ptr(10 * time.Minute), false},
+		{"1h30m", ptr(90 * time.Minute), false},
+		{"0", ptr(time.Duration(0)), false},
+		{"0s", ptr(time.Duration(0)), false},
+		{"-5m", nil, true},
+		{"10", nil, true},
+		{"soon", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLoginTimeout(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.input, tt.wantErr, err)
+			continue
+		}
+		switch {
+		case tt.want == nil && got != nil:
+			t.Errorf("%q: expected the defaults, got %v", tt.input, *got)
+		case tt.want != nil && (got == nil || *got != *tt.want):
+			t.Errorf("%q: expected %v, got %v", tt.input, *tt.want, got)
+		}
+	}
+}
+
+// TestTimeoutFor tests the default and configured login timeouts.
+func TestTimeoutFor(t *testing.T) {
+	a := &Authenticator{}
+	if got := a.timeoutFor(LoginBrowser); got != DefaultLoginTimeout {
+		t.Errorf("Expected %v for a browser login, got %v", DefaultLoginTimeout, got)
+	}
+	if got := a.timeoutFor(LoginDevice); got != DefaultDeviceLoginTimeout {
+		t.Errorf("Expected %v for a device login, got %v", DefaultDeviceLoginTimeout, got)
+	}
+
+	a.SetLoginTimeout(0)
+	if got := a.timeoutFor(LoginDevice); got != 0 {
+		t.Errorf("Expected no limit after setting 0, got %v", got)
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
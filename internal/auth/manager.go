@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	"golang.org/x/oauth2"
+)
+
+// ErrReauthRequired means the stored token can no longer be refreshed and
+// the user has to log in again.
+var ErrReauthRequired = errors.New("re-authentication required")
+
+const (
+	// refreshLeeway is how long before expiry the manager refreshes.
+	refreshLeeway = 5 * time.Minute
+	// refreshTimeout bounds a refresh Token has to wait for.
+	refreshTimeout = 30 * time.Second
+)
+
+// TokenManager is a token source that refreshes the access token in the
+// background before it expires and saves every refreshed token, so
+// requests never wait on a refresh and a restart starts from the newest
+// token. When the refresh token is revoked or expired it reports
+// ErrReauthRequired on ReauthRequired and waits for Reload.
+type TokenManager struct {
+	auth   *Authenticator
+	reauth chan error
+	reload chan struct{}
+	// retry paces refreshes that failed for a reason other than a revoked
+	// grant, e.g. no network.
+	retry *backoff.Policy
+	// creds serves tokens when the Authenticator uses credentials instead
+	// of a stored token; they refresh themselves.
+	creds oauth2.TokenSource
+
+	// saveMu keeps saves of refreshed tokens in order without holding mu
+	// while the store writes.
+	saveMu sync.Mutex
+
+	mu    sync.Mutex
+	token *oauth2.Token
+	// failed holds the error that requires a new login, until Reload.
+	failed error
+	// refreshing is the refresh in progress, if any. Callers that need a
+	// new token meanwhile wait for it instead of starting their own.
+	refreshing *refreshCall
+}
+
+// refreshCall is a refresh in progress. err is set before done is closed.
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewTokenManager returns a manager for the stored token, retrying failed
+// refreshes under retry, which is required. Call Run to start refreshing
+// in the background.
+func (a *Authenticator) NewTokenManager(retry *backoff.Policy) (*TokenManager, error) {
+	if retry == nil {
+		return nil, errors.New("token manager needs a retry policy")
+	}
+	m := &TokenManager{
+		auth:   a,
+		reauth: make(chan error, 1),
+		reload: make(chan struct{}, 1),
+		retry:  retry,
+	}
+	if a.creds != nil {
+		m.creds = a.creds.TokenSource(a.httpContext(context.Background()))
+		return m, nil
+	}
+	token, err := a.loadToken()
+	if err != nil {
+		return nil, err
+	}
+	m.token = token
+	return m, nil
+}
+
+// ReauthRequired receives an error wrapping ErrReauthRequired each time
+// the token can no longer be refreshed.
+func (m *TokenManager) ReauthRequired() <-chan error {
+	return m.reauth
+}
+
+// Reload picks up the token stored by a new login and resumes refreshing.
+func (m *TokenManager) Reload() error {
+	if m.creds != nil {
+		return nil
+	}
+	token, err := m.auth.loadToken()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.token, m.failed = token, nil
+	m.mu.Unlock()
+
+	select {
+	case m.reload <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Token returns a valid access token, refreshing it first if it is about
+// to expire. It implements oauth2.TokenSource.
+func (m *TokenManager) Token() (*oauth2.Token, error) {
+	if m.creds != nil {
+		return m.creds.Token()
+	}
+
+	m.mu.Lock()
+	token, failed := m.token, m.failed
+	m.mu.Unlock()
+	if failed != nil {
+		return nil, failed
+	}
+	if token.Valid() && time.Until(token.Expiry) > time.Minute {
+		return token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	if err := m.refresh(ctx); err != nil {
+		if !errors.Is(err, ErrReauthRequired) && token.Valid() {
+			// The old token has a minute left to try again in
+			return token, nil
+		}
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.token, nil
+}
+
+// Run refreshes the token ahead of expiry until ctx is done.
+func (m *TokenManager) Run(ctx context.Context) {
+	if m.creds != nil {
+		// Credential tokens are minted on demand
+		return
+	}
+
+	failures := 0
+	for {
+		m.mu.Lock()
+		failed, expiry := m.failed, m.token.Expiry
+		m.mu.Unlock()
+
+		if failed != nil || expiry.IsZero() {
+			// Wait for a new login; a token without an expiry never needs
+			// refreshing
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.reload:
+			}
+			continue
+		}
+
+		timer := time.NewTimer(max(time.Until(expiry)-refreshLeeway, 0))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-m.reload:
+			timer.Stop()
+			failures = 0
+			continue
+		case <-timer.C:
+		}
+
+		err := m.refresh(ctx)
+		if err == nil || errors.Is(err, ErrReauthRequired) {
+			failures = 0
+			continue
+		}
+
+		// A transient failure; Token still refreshes on demand meanwhile
+		if err := m.wait(ctx, m.retry.Interval(failures)); err != nil {
+			return
+		}
+		failures++
+	}
+}
+
+// wait sleeps for d, ending early when Reload brings in a new login. It
+// returns ctx's error once ctx is done.
+func (m *TokenManager) wait(ctx context.Context, d time.Duration) error {
+	sleepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-m.reload:
+			cancel()
+		case <-sleepCtx.Done():
+		}
+	}()
+	backoff.Sleep(sleepCtx, d)
+	return ctx.Err()
+}
+
+// refresh exchanges the refresh token for a new access token and saves
+// it. Only one refresh runs at a time; a caller that arrives during one
+// waits for it, until ctx is done, and gets its result. m.mu is not held
+// while the token endpoint is called or the token saved, so a slow
+// endpoint or store does not hold up callers whose token is still good.
+func (m *TokenManager) refresh(ctx context.Context) error {
+	m.mu.Lock()
+	if call := m.refreshing; call != nil {
+		m.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return fmt.Errorf("failed to refresh token: %w", ctx.Err())
+		}
+	}
+	if m.failed != nil {
+		defer m.mu.Unlock()
+		return m.failed
+	}
+	old := m.token
+	if old.RefreshToken == "" {
+		defer m.mu.Unlock()
+		return m.failLocked(errors.New("no refresh token available"))
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	m.refreshing = call
+	m.mu.Unlock()
+
+	// An expired copy forces the refresh even if the token is still valid
+	stale := *old
+	stale.Expiry = time.Now().Add(-time.Minute)
+	token, err := m.auth.configFor(old).TokenSource(m.auth.httpContext(ctx), &stale).Token()
+
+	var refreshed *oauth2.Token
+	m.mu.Lock()
+	switch {
+	case m.token != old:
+		// Reload brought in a new login meanwhile, which wins
+		err = nil
+	case err == nil:
+		refreshed = refreshedFrom(token, old)
+		m.token = refreshed
+	default:
+		var re *oauth2.RetrieveError
+		if errors.As(err, &re) && (re.ErrorCode == "invalid_grant" || re.ErrorCode == "unauthorized_client") {
+			err = m.failLocked(err)
+		} else {
+			err = fmt.Errorf("failed to refresh token: %w", err)
+		}
+	}
+	m.refreshing = nil
+	call.err = err
+	m.mu.Unlock()
+	close(call.done)
+
+	if refreshed != nil {
+		m.save(refreshed)
+	}
+	return err
+}
+
+// save stores a refreshed token unless a newer one has replaced it
+// meanwhile. A token that fails to save still works for this session.
+func (m *TokenManager) save(token *oauth2.Token) {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	m.mu.Lock()
+	current := m.token == token
+	m.mu.Unlock()
+	if current {
+		m.auth.SaveToken(token)
+	}
+}
+
+// failLocked records that a new login is needed and reports it.
+func (m *TokenManager) failLocked(cause error) error {
+	m.failed = fmt.Errorf("%w: %v", ErrReauthRequired, cause)
+	select {
+	case m.reauth <- m.failed:
+	default:
+	}
+	return m.failed
+}
@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	"golang.org/x/oauth2"
+)
+
+// TestTokenManagerNeedsRetry tests that a manager is not created without a
+// retry policy.
+func TestTokenManagerNeedsRetry(t *testing.T) {
+	g := newFakeGoogle(t)
+	a, store := newTestAuthenticator(g)
+	store.Save(expiredToken())
+	if _, err := a.NewTokenManager(nil); err == nil {
+		t.Error("Expected an error without a retry policy")
+	}
+}
+
+// TestTokenManagerSingleRefresh tests that callers arriving during a
+// refresh wait for it instead of starting their own.
+func TestTokenManagerSingleRefresh(t *testing.T) {
+	g := newFakeGoogle(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	g.token = func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":3600}`))
+	}
+	a, store := newTestAuthenticator(g)
+	store.Save(expiredToken())
+	m, err := a.NewTokenManager(backoff.Default())
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+
+	const callers = 10
+	tokens := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := m.Token(); err == nil {
+				tokens[i] = token.AccessToken
+			}
+		}()
+	}
+	<-started
+	close(release)
+	wg.Wait()
+
+	if got := g.tokenClients(); len(got) != 1 {
+		t.Errorf("Expected a single refresh, got %d", len(got))
+	}
+	for i, token := range tokens {
+		if token != "new" {
+			t.Errorf("Expected caller %d to get the new token, got %q", i, token)
+		}
+	}
+}
+
+// TestTokenManagerInvalidGrant tests that a revoked refresh token asks for
+// a new login and stops refreshing until then.
+func TestTokenManagerInvalidGrant(t *testing.T) {
+	g := newFakeGoogle(t)
+	g.token = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+	}
+	a, store := newTestAuthenticator(g)
+	store.Save(expiredToken())
+	m, err := a.NewTokenManager(backoff.Default())
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+
+	if _, err := m.Token(); !errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("Expected ErrReauthRequired, got %v", err)
+	}
+	select {
+	case err := <-m.ReauthRequired():
+		if !errors.Is(err, ErrReauthRequired) {
+			t.Errorf("Expected ErrReauthRequired on the channel, got %v", err)
+		}
+	default:
+		t.Error("Expected the re-login to be reported")
+	}
+	if _, err := m.Token(); !errors.Is(err, ErrReauthRequired) {
+		t.Errorf("Expected ErrReauthRequired again, got %v", err)
+	}
+	if got := g.tokenClients(); len(got) != 1 {
+		t.Errorf("Expected no refresh after the grant was revoked, got %d", len(got))
+	}
+
+	// A new login resumes
+	g.token = nil
+	store.Save(expiredToken())
+	if err := m.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if token, err := m.Token(); err != nil || token.AccessToken != "new" {
+		t.Errorf("Expected the new token after logging in again, got %v, %v", token, err)
+	}
+}
+
+// TestTokenManagerTransientFailure tests that a failed refresh keeps the
+// old token and is tried again on the next call.
+func TestTokenManagerTransientFailure(t *testing.T) {
+	g := newFakeGoogle(t)
+	g.token = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	a, store := newTestAuthenticator(g)
+	old := expiredToken()
+	store.Save(old)
+	m, err := a.NewTokenManager(backoff.Default())
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+
+	if _, err := m.Token(); err == nil || errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("Expected a refresh error without re-login, got %v", err)
+	}
+	if m.token != old || store.token != old {
+		t.Error("Expected the old token to be kept")
+	}
+	select {
+	case err := <-m.ReauthRequired():
+		t.Errorf("Expected no re-login, got %v", err)
+	default:
+	}
+
+	g.token = nil
+	if token, err := m.Token(); err != nil || token.AccessToken != "new" {
+		t.Errorf("Expected the next call to refresh, got %v, %v", token, err)
+	}
+}
+
+// TestTokenManagerRunRetries tests that Run tries a failed refresh again
+// at the pace of its retry policy.
+func TestTokenManagerRunRetries(t *testing.T) {
+	g := newFakeGoogle(t)
+	var mu sync.Mutex
+	failures := 2
+	g.token = func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":3600}`))
+	}
+	a, store := newTestAuthenticator(g)
+	store.Save(expiredToken())
+	m, err := a.NewTokenManager(&backoff.Policy{Initial: time.Millisecond, Multiplier: 2})
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.mu.Lock()
+		token := m.token
+		m.mu.Unlock()
+		if token.AccessToken == "new" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected Run to refresh after two failures")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := g.tokenClients(); len(got) != 3 {
+		t.Errorf("Expected 3 refreshes, got %d", len(got))
+	}
+}
+
+// lockCheckStore records whether the manager's lock was free while a
+// refreshed token was saved.
+type lockCheckStore struct {
+	memoryStore
+	m      *TokenManager
+	locked bool
+}
+
+func (s *lockCheckStore) Save(token *oauth2.Token) error {
+	if s.m != nil {
+		if s.m.mu.TryLock() {
+			s.m.mu.Unlock()
+		} else {
+			s.locked = true
+		}
+	}
+	return s.memoryStore.Save(token)
+}
+
+// TestTokenManagerSharedClient tests that refreshes go through the client
+// from SetHTTPClient and that the token is saved without holding the
+// manager's lock.
+func TestTokenManagerSharedClient(t *testing.T) {
+	g := newFakeGoogle(t)
+	a, _ := newTestAuthenticator(g)
+	// Only the shared client knows how to reach the fake endpoint
+	a.config.Endpoint.TokenURL = "https://oauth2.invalid/token"
+	target, _ := url.Parse(g.URL)
+	a.SetHTTPClient(&http.Client{Transport: rewriteHost{target: target}})
+	store := &lockCheckStore{}
+	a.store = store
+	store.Save(expiredToken())
+	m, err := a.NewTokenManager(backoff.Default())
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+	store.m = m
+
+	if token, err := m.Token(); err != nil || token.AccessToken != "new" {
+		t.Fatalf("Expected a refresh through the shared client, got %v, %v", token, err)
+	}
+	if store.saves != 2 || store.token.AccessToken != "new" {
+		t.Errorf("Expected the new token to be saved, got %d saves", store.saves)
+	}
+	if store.locked {
+		t.Error("Expected the token to be saved after unlocking")
+	}
+}
+
+// TestTokenManagerJoinDeadline tests that a caller waiting for another
+// caller's refresh gives up when its context is done.
+func TestTokenManagerJoinDeadline(t *testing.T) {
+	g := newFakeGoogle(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	g.token = func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":3600}`))
+	}
+	a, store := newTestAuthenticator(g)
+	store.Save(expiredToken())
+	m, err := a.NewTokenManager(backoff.Default())
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+
+	first := make(chan error, 1)
+	go func() { first <- m.refresh(context.Background()) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.refresh(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the waiting caller to time out, got %v", err)
+	}
+	close(release)
+	if err := <-first; err != nil {
+		t.Errorf("Expected the refresh to finish, got %v", err)
+	}
+}
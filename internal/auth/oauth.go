@@ -3,15 +3,20 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/user/google-classroom/internal/secure"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -21,14 +26,98 @@ type Configuration struct {
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	RedirectURI  string `json:"redirect_uri"`
+	// DeviceClientID and DeviceClientSecret are the "TVs and Limited Input
+	// devices" client used by the device login. ClientID is used when they
+	// are empty.
+	DeviceClientID     string `json:"device_client_id,omitempty"`
+	DeviceClientSecret string `json:"device_client_secret,omitempty"`
+	// ServiceAccountKey is the path of a service account JSON key. When
+	// set, the app impersonates Subject through domain-wide delegation
+	// instead of using interactive login.
+	ServiceAccountKey string `json:"service_account_key,omitempty"`
+	Subject           string `json:"subject,omitempty"`
+	// UseDefaultCredentials uses Application Default Credentials instead
+	// of interactive login. Setting GOOGLE_APPLICATION_CREDENTIALS turns
+	// it on.
+	UseDefaultCredentials bool `json:"use_default_credentials,omitempty"`
+	// Domain limits login to accounts in one Workspace domain, e.g.
+	// "school.example". --domain selects a profile by it.
+	Domain string `json:"domain,omitempty"`
+	// LoginTimeout is how long login waits for approval, e.g. "10m"; "0"
+	// waits until cancelled. Empty uses DefaultLoginTimeout, or
+	// DefaultDeviceLoginTimeout for the device login.
+	LoginTimeout string `json:"login_timeout,omitempty"`
+}
+
+// LoginMode selects how Login obtains consent.
+type LoginMode int
+
+const (
+	// LoginBrowser opens a browser and receives the code on a local server.
+	LoginBrowser LoginMode = iota
+	// LoginDevice shows a code to enter at google.com/device, for SSH
+	// sessions and machines without a browser.
+	LoginDevice
+	// LoginManual prints the consent URL and reads the code, or the URL
+	// the browser was redirected to, from stdin. LoginBrowser falls back
+	// to it when no browser can be opened.
+	LoginManual
+)
+
+// String returns the name ParseLoginMode accepts.
+func (m LoginMode) String() string {
+	switch m {
+	case LoginDevice:
+		return "device"
+	case LoginManual:
+		return "manual"
+	default:
+		return "browser"
+	}
+}
+
+// ParseLoginMode parses the value of the login --mode flag.
+func ParseLoginMode(s string) (LoginMode, error) {
+	switch s {
+	case "", "browser":
+		return LoginBrowser, nil
+	case "device":
+		return LoginDevice, nil
+	case "manual":
+		return LoginManual, nil
+	default:
+		return LoginBrowser, fmt.Errorf("unknown login mode %q (want browser, device, or manual)", s)
+	}
 }
 
 // TokenInfo represents stored OAuth token information.
 type TokenInfo struct {
-	Email        string    `json:"email"`
+	// LoggedIn reports whether a token or credentials are available.
+	LoggedIn bool   `json:"logged_in"`
+	Email    string `json:"email"`
+	// Name is the signed-in user's name, when the token records it.
+	Name         string    `json:"name,omitempty"`
 	AccessToken  string    `json:"access_token"`
 	Expiry       time.Time `json:"expiry"`
 	NeedsRefresh bool      `json:"needs_refresh"`
+	// Store says where the token is kept.
+	Store string `json:"store"`
+	// LastRefresh is when the token was last obtained or refreshed; zero
+	// if unknown.
+	LastRefresh time.Time `json:"last_refresh,omitzero"`
+	// Scopes are the granted scopes. Only Inspect fills them in.
+	Scopes []string `json:"scopes,omitempty"`
+	// Profile is the OAuth profile in use; empty for the default.
+	Profile string `json:"profile,omitempty"`
+}
+
+// ExpiresIn returns how long the access token stays valid after now, or 0
+// once it has expired.
+func (t *TokenInfo) ExpiresIn(now time.Time) time.Duration {
+	if t.Expiry.IsZero() {
+		return 0
+	}
+	return max(t.Expiry.Sub(now), 0)
 }
 
 // Authenticator handles OAuth 2.0 authentication flow.
@@ -36,12 +125,38 @@ type Authenticator struct {
 	config     *oauth2.Config
 	configPath string
 	tokenPath  string
+	// file is the fallback token file, which SetSealer encrypts.
+	file  *FileTokenStore
+	store TokenStore
+	mode  LoginMode
+	// device overrides the client for the device login, if configured.
+	device *Configuration
+	// creds replaces the stored token when set.
+	creds credentialSource
+	// profile names the OAuth profile in use, and domain the Workspace
+	// domain it is limited to; both are empty by default.
+	profile string
+	domain  string
+	// loginTimeout overrides the default login timeouts when set.
+	loginTimeout *time.Duration
+	// input is where the manual login reads pasted codes.
+	input *lineInput
+	// httpClient makes requests to Google's OAuth endpoints; nil uses
+	// http.DefaultClient.
+	httpClient *http.Client
 }
 
 // NewAuthenticator creates a new Authenticator instance.
 func NewAuthenticator(configPath string) (*Authenticator, error) {
+	return NewProfileAuthenticator(configPath, "")
+}
+
+// NewProfileAuthenticator creates an Authenticator for the named OAuth
+// profile, usually from ResolveProfile. Each profile has its own client
+// credentials and keeps its own token; "" is the default configuration.
+func NewProfileAuthenticator(configPath, profile string) (*Authenticator, error) {
 	// Load configuration
-	cfg, err := loadConfiguration(configPath)
+	cfg, err := loadProfile(configPath, profile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -52,109 +167,179 @@ func NewAuthenticator(configPath string) (*Authenticator, error) {
 		ClientSecret: cfg.ClientSecret,
 		RedirectURL:  cfg.RedirectURI,
 		Scopes: []string{
-			"https://www.googleapis.com/auth/classroom.courses.readonly",
-			"https://www.googleapis.com/auth/classroom.coursework.students",
-			"https://www.googleapis.com/auth/classroom.rosters.readonly",
-			"https://www.googleapis.com/auth/classroom.announcements.readonly",
-			"https://www.googleapis.com/auth/classroom.profile.emails",
-			"https://www.googleapis.com/auth/classroom.profile.photos",
+			ScopeCourses,
+			ScopeCourseWorkStudents,
+			ScopeRosters,
+			ScopeAnnouncementsReadonly,
+			ScopeProfileEmails,
+			ScopeProfilePhotos,
 		},
 		Endpoint: google.Endpoint,
 	}
 
+	loginTimeout, err := parseLoginTimeout(cfg.LoginTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	// Determine token storage path
-	homeDir, err := os.UserHomeDir()
+	tokenPath, err := ProfileTokenPath(profile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
+	}
+	file := &FileTokenStore{Path: tokenPath, Key: DefaultKeySource()}
+
+	a := &Authenticator{
+		config:       oauthConfig,
+		configPath:   configPath,
+		tokenPath:    tokenPath,
+		profile:      profile,
+		domain:       cfg.Domain,
+		file:         file,
+		loginTimeout: loginTimeout,
+		input:        &lineInput{in: os.Stdin},
+		store:        &autoTokenStore{keychain: NewProfileKeychainTokenStore(profile), file: file},
+	}
+	if cfg.DeviceClientID != "" {
+		a.device = &Configuration{ClientID: cfg.DeviceClientID, ClientSecret: cfg.DeviceClientSecret}
+	}
+	switch {
+	case cfg.ServiceAccountKey != "":
+		if err := a.UseServiceAccount(cfg.ServiceAccountKey, cfg.Subject); err != nil {
+			return nil, err
+		}
+	case cfg.UseDefaultCredentials:
+		if err := a.UseDefaultCredentials(context.Background(), cfg.Subject); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// SetLoginMode selects how Login and RequestScopes obtain consent. The
+// default is LoginBrowser.
+func (a *Authenticator) SetLoginMode(mode LoginMode) {
+	a.mode = mode
+}
+
+// SetSealer encrypts the token file with s. A nil sealer stores it in
+// plaintext; tokens written before encryption was enabled are still read.
+// Tokens kept in the OS keychain are protected by the keychain instead.
+func (a *Authenticator) SetSealer(s *secure.Sealer) {
+	a.file.Sealer = s
+}
+
+// SetHTTPClient makes requests to Google's OAuth endpoints, such as token
+// exchange, refresh, and token info, use c. Give it a client over the
+// transport from api.NewTransport and pass the same transport in
+// api.Configuration.Transport, so OAuth and API requests share
+// connections and proxy settings.
+func (a *Authenticator) SetHTTPClient(c *http.Client) {
+	a.httpClient = c
+}
+
+// httpContext returns ctx carrying the client set by SetHTTPClient, which
+// the oauth2 package uses for token requests.
+func (a *Authenticator) httpContext(ctx context.Context) context.Context {
+	if a.httpClient == nil {
+		return ctx
 	}
-	tokenPath := filepath.Join(homeDir, ".config", "google-classroom", "tokens.json")
+	return context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+}
 
-	return &Authenticator{
-		config:     oauthConfig,
-		configPath: configPath,
-		tokenPath:  tokenPath,
-	}, nil
+// SetTokenStore replaces where the token is kept. By default it goes to
+// the OS keychain, or the token file when there is no keychain.
+func (a *Authenticator) SetTokenStore(store TokenStore) {
+	a.store = store
 }
 
-// loadConfiguration reads OAuth configuration from file.
+// TokenPath returns the path of the token file, used when the OS keychain
+// is not.
+func (a *Authenticator) TokenPath() string {
+	return a.tokenPath
+}
+
+// loadConfiguration reads the default OAuth configuration; see
+// loadProfile.
 func loadConfiguration(path string) (*Configuration, error) {
-	data, err := os.ReadFile(path)
+	return loadProfile(path, "")
+}
+
+// loadProfile reads the named OAuth configuration from file, then applies
+// the credential environment variables on top. The default configuration
+// is read from the "oauth" section of the app config, or from the top
+// level of a file that holds only it, and named ones from "profiles".
+func loadProfile(path, profile string) (*Configuration, error) {
+	// A missing file leaves the defaults; an empty redirect URI picks a
+	// free loopback port at login
+	file, err := readConfigFile(path)
 	if err != nil {
-		// Return default config if file doesn't exist
-		return &Configuration{
-			ClientID:     "",
-			ClientSecret: "",
-			RedirectURI:  "http://localhost:8080/callback",
-		}, nil
+		return nil, err
 	}
-
-	var cfg Configuration
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	stored, err := file.profile(profile)
+	if err != nil {
+		return nil, err
 	}
 
+	cfg := *stored
+	applyEnv(&cfg)
 	return &cfg, nil
 }
 
-// TokenSource returns an OAuth2 token source for the stored token.
+// TokenSource returns an OAuth2 token source for the stored token, or for
+// the service account or default credentials when they are in use.
 func (a *Authenticator) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	ctx = a.httpContext(ctx)
+	if a.creds != nil {
+		return a.creds.TokenSource(ctx), nil
+	}
 	token, err := a.loadToken()
 	if err != nil {
 		return nil, err
 	}
-	return a.config.TokenSource(ctx, token), nil
+	return a.configFor(token).TokenSource(ctx, token), nil
 }
 
-// LoadToken loads the OAuth token from storage.
-func (a *Authenticator) loadToken() (*oauth2.Token, error) {
-	data, err := os.ReadFile(a.tokenPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("no stored token found")
-		}
-		return nil, fmt.Errorf("failed to read token: %w", err)
+// configFor returns the OAuth config to refresh token with: the device
+// client's for a token the device login obtained, as Google only refreshes
+// a token for the client it was issued to, and the default one otherwise.
+func (a *Authenticator) configFor(token *oauth2.Token) *oauth2.Config {
+	if a.device == nil || issuerOf(token) != a.device.ClientID {
+		return a.config
 	}
+	return a.deviceConfig(a.config)
+}
 
-	var token oauth2.Token
-	if err := json.Unmarshal(data, &token); err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+// deviceConfig returns cfg with the device client, if one is configured.
+func (a *Authenticator) deviceConfig(cfg *oauth2.Config) *oauth2.Config {
+	if a.device == nil {
+		return cfg
 	}
+	c := *cfg
+	c.ClientID, c.ClientSecret = a.device.ClientID, a.device.ClientSecret
+	return &c
+}
 
-	return &token, nil
+// loadToken loads the OAuth token from storage.
+func (a *Authenticator) loadToken() (*oauth2.Token, error) {
+	return a.store.Load()
 }
 
-// SaveToken saves the OAuth token to storage with secure permissions.
+// SaveToken saves the OAuth token to storage.
 func (a *Authenticator) SaveToken(token *oauth2.Token) error {
-	// Ensure directory exists
-	dir := filepath.Dir(a.tokenPath)
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return fmt.Errorf("failed to create token directory: %w", err)
-	}
-
-	// Marshal token to JSON
-	data, err := json.MarshalIndent(token, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal token: %w", err)
-	}
-
-	// Write with secure permissions (owner read/write only)
-	if err := os.WriteFile(a.tokenPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write token: %w", err)
-	}
-
-	return nil
+	return a.store.Save(token)
 }
 
 // DeleteToken removes the stored OAuth token.
 func (a *Authenticator) DeleteToken() error {
-	if err := os.Remove(a.tokenPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete token: %w", err)
-	}
-	return nil
+	return a.store.Delete()
 }
 
 // IsAuthenticated checks if a valid token exists.
 func (a *Authenticator) IsAuthenticated() bool {
+	if a.creds != nil {
+		return true
+	}
 	token, err := a.loadToken()
 	if err != nil {
 		return false
@@ -164,12 +349,12 @@ func (a *Authenticator) IsAuthenticated() bool {
 
 // GetAuthURL returns the OAuth consent URL.
 func (a *Authenticator) GetAuthURL(state string) string {
-	return a.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+	return a.config.AuthCodeURL(state, append([]oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.ApprovalForce}, a.domainOption()...)...)
 }
 
 // ExchangeCode exchanges an authorization code for a token.
 func (a *Authenticator) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := a.config.Exchange(ctx, code)
+	token, err := a.config.Exchange(a.httpContext(ctx), code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
@@ -178,6 +363,10 @@ func (a *Authenticator) ExchangeCode(ctx context.Context, code string) (*oauth2.
 
 // RefreshToken refreshes the access token using the refresh token.
 func (a *Authenticator) RefreshToken(ctx context.Context) (*oauth2.Token, error) {
+	ctx = a.httpContext(ctx)
+	if a.creds != nil {
+		return a.creds.TokenSource(ctx).Token()
+	}
 	token, err := a.loadToken()
 	if err != nil {
 		return nil, err
@@ -187,12 +376,13 @@ func (a *Authenticator) RefreshToken(ctx context.Context) (*oauth2.Token, error)
 		return nil, fmt.Errorf("no refresh token available")
 	}
 
-	newToken, err := a.config.TokenSource(ctx, token).Token()
+	newToken, err := a.configFor(token).TokenSource(ctx, token).Token()
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
 	// Save the new token
+	newToken = refreshedFrom(newToken, token)
 	if err := a.SaveToken(newToken); err != nil {
 		return nil, err
 	}
@@ -218,90 +408,268 @@ func OpenBrowser(url string) error {
 
 // Login performs the full OAuth login flow.
 func (a *Authenticator) Login(ctx context.Context) error {
-	// Generate state for CSRF protection
-	state := fmt.Sprintf("state_%d", time.Now().UnixNano())
+	return a.login(ctx, a.config)
+}
 
-	// Get auth URL
-	authURL := a.GetAuthURL(state)
+// login runs the consent flow for cfg in the selected mode and stores the
+// resulting token. opts only apply to the browser flow. It gives up after
+// the login timeout with ErrLoginTimeout, or when ctx is done.
+func (a *Authenticator) login(ctx context.Context, cfg *oauth2.Config, opts ...oauth2.AuthCodeOption) error {
+	if a.creds != nil {
+		return ErrServiceAccount
+	}
+	ctx = a.httpContext(ctx)
+	return a.withLoginTimeout(ctx, func(ctx context.Context) error {
+		if a.mode != LoginDevice {
+			return a.browserLogin(ctx, cfg, a.mode == LoginManual, opts...)
+		}
+		err := a.deviceLogin(ctx, cfg)
+		if !errors.Is(err, errDeviceScopes) {
+			return err
+		}
+		// Google refuses the Classroom scopes to the device flow; pasting
+		// the code works from the same places
+		fmt.Printf("%v; logging in by pasting a code instead.\n\n", errDeviceScopes)
+		return a.browserLogin(ctx, cfg, true, opts...)
+	})
+}
 
-	// Open browser for consent
-	fmt.Println("Opening browser for Google OAuth consent...")
-	if err := OpenBrowser(authURL); err != nil {
-		return fmt.Errorf("failed to open browser: %w", err)
+// browserLogin opens the consent page in a browser and receives the code
+// on a local server, or with manual set has the user paste it. The server
+// listens on 127.0.0.1 on a port the OS picks, unless redirect_uri names a
+// fixed loopback port, and the exchange is protected with PKCE.
+func (a *Authenticator) browserLogin(ctx context.Context, cfg *oauth2.Config, manual bool, opts ...oauth2.AuthCodeOption) error {
+	listener, redirectURL, err := listenLoopback(cfg.RedirectURL)
+	if err != nil {
+		return err
 	}
+	c := *cfg
+	c.RedirectURL = redirectURL
+	cfg = &c
 
-	// Start local server to receive callback
-	codeChan := make(chan string, 1)
-	errChan := make(chan error, 1)
+	// Generate state for CSRF protection and a verifier for PKCE
+	state := rand.Text()
+	verifier := oauth2.GenerateVerifier()
 
-	server := &http.Server{Addr: ":8080"}
-	http.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		// Verify state
-		if r.URL.Query().Get("state") != state {
-			errChan <- fmt.Errorf("state mismatch")
-			http.Error(w, "State mismatch", http.StatusBadRequest)
-			return
-		}
+	// Get auth URL
+	authURL := cfg.AuthCodeURL(state, slices.Concat([]oauth2.AuthCodeOption{
+		oauth2.AccessTypeOffline,
+		oauth2.ApprovalForce,
+		oauth2.S256ChallengeOption(verifier),
+	}, a.domainOption(), opts)...)
 
-		// Get code
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errChan <- fmt.Errorf("no code in callback")
-			http.Error(w, "No code", http.StatusBadRequest)
-			return
-		}
+	// Start local server to receive callback
+	codeChan := make(chan authCode, 1)
+	errChan := make(chan error, 1)
+	stop := make(chan struct{})
 
-		codeChan <- code
-		fmt.Fprintf(w, "<html><body><h1>Authentication successful!</h1><p>You can close this window.</p></body></html>")
-	})
+	mux := http.NewServeMux()
+	mux.Handle(callbackPath(redirectURL), callbackHandler(state, codeChan, errChan, stop))
+	server := &http.Server{Handler: mux}
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
+	defer server.Shutdown(context.Background())
+	defer close(stop)
+
+	// Open browser for consent. Where that is impossible, e.g. in a
+	// container or over SSH, the user opens the URL elsewhere and pastes
+	// back the code or the URL the browser was redirected to
+	manual = manual || headless()
+	if !manual {
+		fmt.Println("Opening browser for Google OAuth consent...")
+		if err := OpenBrowser(authURL); err != nil {
+			fmt.Printf("Could not open a browser: %v\n", err)
+			manual = true
+		}
+	}
+	if manual {
+		go readPastedCode(ctx, a.input, authURL, state, codeChan)
+	}
 
 	// Wait for code or error
 	select {
 	case code := <-codeChan:
-		// Exchange code for token
-		token, err := a.ExchangeCode(ctx, code)
+		err := a.exchangeCode(ctx, cfg, code.code, verifier)
+		if code.result != nil {
+			// Let the browser page show how it went
+			code.result <- err
+		}
+		return err
+
+	case err := <-errChan:
+		return err
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exchangeCode exchanges an authorization code for a token and saves it.
+func (a *Authenticator) exchangeCode(ctx context.Context, cfg *oauth2.Config, code, verifier string) error {
+	token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return a.saveLogin(ctx, cfg, token)
+}
+
+// headless reports whether there is evidently no browser to open: a Linux
+// or BSD session without a display, or an SSH session.
+func headless() bool {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return true
+	}
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return false
+	}
+	return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// readPastedCode prints the consent URL and reads lines from in until one
+// holds an authorization code, which it sends on codes, or ctx is done.
+func readPastedCode(ctx context.Context, in *lineInput, authURL, state string, codes chan<- authCode) {
+	fmt.Println("Open this URL in a browser on any device and approve access:")
+	fmt.Println()
+	fmt.Printf("  %s\n", authURL)
+	fmt.Println()
+	fmt.Println("The browser then tries to load a 127.0.0.1 page, which fails unless it")
+	fmt.Println("runs on this machine. Copy that page's URL, or just its code, and paste it here.")
+
+	for {
+		fmt.Print("Code or URL: ")
+		line, err := in.readLine(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to exchange code: %w", err)
+			// End the prompt line before the login reports why it stopped
+			fmt.Println()
+			return
 		}
+		code, err := parsePastedCode(line, state)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		select {
+		case codes <- authCode{code: code}:
+		default:
+		}
+		return
+	}
+}
 
-		// Save token
-		if err := a.SaveToken(token); err != nil {
-			return fmt.Errorf("failed to save token: %w", err)
+// parsePastedCode extracts the authorization code from what the user
+// pasted: either the bare code or the redirect URL, whose state must match.
+// Quotes picked up when copying from a terminal or chat are dropped.
+func parsePastedCode(input, state string) (string, error) {
+	input = strings.TrimSpace(strings.Trim(strings.TrimSpace(input), "\"'`"))
+	if input == "" {
+		return "", errors.New("nothing pasted; paste the code or the redirected URL")
+	}
+	if !strings.Contains(input, "code=") && !strings.Contains(input, "error=") {
+		return input, nil
+	}
+
+	query := input
+	if i := strings.IndexByte(input, '?'); i >= 0 {
+		query = input[i+1:]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("could not read the pasted URL: %w", err)
+	}
+	if e := values.Get("error"); e != "" {
+		return "", fmt.Errorf("Google returned %q; open the URL again", e)
+	}
+	if s := values.Get("state"); s != "" && s != state {
+		return "", errors.New("the pasted URL is from a different login attempt")
+	}
+	code := values.Get("code")
+	if code == "" {
+		return "", errors.New("the pasted URL has no code")
+	}
+	return code, nil
+}
+
+// listenLoopback opens the callback listener and returns the redirect URL
+// that reaches it. A configured loopback redirect with a port is used as
+// is, for OAuth clients registered with a fixed redirect URI; otherwise, or
+// when that port is taken, the OS picks a free port on 127.0.0.1, which
+// Desktop app clients accept.
+func listenLoopback(configured string) (net.Listener, string, error) {
+	addr, path := "127.0.0.1:0", "/callback"
+	if u, err := url.Parse(configured); err == nil && configured != "" {
+		if u.Port() != "" && u.Port() != "0" && isLoopback(u.Hostname()) {
+			addr = u.Host
+		}
+		if u.Path != "" {
+			path = u.Path
 		}
+	}
 
-		// Shutdown server
-		server.Shutdown(ctx)
-		return nil
+	listener, err := net.Listen("tcp", addr)
+	if err != nil && addr != "127.0.0.1:0" {
+		addr = "127.0.0.1:0"
+		listener, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start callback server: %w", err)
+	}
+	host := addr
+	if strings.HasSuffix(addr, ":0") {
+		host = listener.Addr().String()
+	}
+	return listener, "http://" + host + path, nil
+}
 
-	case err := <-errChan:
-		server.Shutdown(ctx)
-		return err
+// isLoopback reports whether host names this machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
 
-	case <-time.After(5 * time.Minute):
-		server.Shutdown(ctx)
-		return fmt.Errorf("authentication timeout")
+// callbackPath returns the path of redirectURL.
+func callbackPath(redirectURL string) string {
+	u, err := url.Parse(redirectURL)
+	if err != nil || u.Path == "" {
+		return "/callback"
 	}
+	return u.Path
 }
 
 // Status returns the current authentication status.
 func (a *Authenticator) Status() (*TokenInfo, error) {
+	if a.creds != nil {
+		info := a.creds.status()
+		info.Profile = a.profile
+		return info, nil
+	}
 	token, err := a.loadToken()
 	if err != nil {
 		return &TokenInfo{
 			NeedsRefresh: false,
+			Profile:      a.profile,
 		}, nil
 	}
 
 	info := &TokenInfo{
+		LoggedIn:     true,
 		AccessToken:  token.AccessToken,
 		Expiry:       token.Expiry,
 		NeedsRefresh: !token.Valid(),
+		Store:        a.store.Name(),
+		LastRefresh:  SavedAt(token),
+		Profile:      a.profile,
+	}
+	if id := IdentityOf(token); id != nil {
+		info.Email = id.Email
+		info.Name = id.Name
 	}
 
 	return info, nil
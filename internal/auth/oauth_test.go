@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	"golang.org/x/oauth2"
+)
+
+// memoryStore keeps the token in memory.
+type memoryStore struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+	saves int
+}
+
+func (s *memoryStore) Load() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, ErrNoToken
+	}
+	return s.token, nil
+}
+
+func (s *memoryStore) Save(token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	s.saves++
+	return nil
+}
+
+func (s *memoryStore) Delete() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	return nil
+}
+
+func (s *memoryStore) Name() string {
+	return "memory"
+}
+
+// fakeGoogle serves Google's device, token, and profile endpoints. token
+// answers token requests; by default it issues a new access token.
+type fakeGoogle struct {
+	*httptest.Server
+	device http.HandlerFunc
+	token  http.HandlerFunc
+
+	mu      sync.Mutex
+	clients []string // client_id of each token request
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	g := &fakeGoogle{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		g.device(w, r)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		g.mu.Lock()
+		g.clients = append(g.clients, r.Form.Get("client_id"))
+		g.mu.Unlock()
+		if g.token != nil {
+			g.token(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/tokeninfo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"scope":"` + ScopeCourses + `"}`))
+	})
+	mux.HandleFunc("/v1/userProfiles/me", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"42","emailAddress":"ada@school.example","name":{"fullName":"Ada"}}`))
+	})
+	g.Server = httptest.NewServer(mux)
+	t.Cleanup(g.Close)
+	return g
+}
+
+// tokenClients returns the client_id of each token request so far.
+func (g *fakeGoogle) tokenClients() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.clients...)
+}
+
+// context returns a context whose OAuth requests, and the profile request,
+// reach g.
+func (g *fakeGoogle) context() context.Context {
+	target, _ := url.Parse(g.URL)
+	client := &http.Client{Transport: rewriteHost{target: target}}
+	return context.WithValue(context.Background(), oauth2.HTTPClient, client)
+}
+
+// rewriteHost sends every request to target.
+type rewriteHost struct {
+	target *url.URL
+}
+
+func (rt rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestAuthenticator returns an authenticator using g, with a desktop
+// client and a device client, keeping its token in memory.
+func newTestAuthenticator(g *fakeGoogle) (*Authenticator, *memoryStore) {
+	store := &memoryStore{}
+	return &Authenticator{
+		config: &oauth2.Config{
+			ClientID:     "desktop",
+			ClientSecret: "desktop-secret",
+			Scopes:       []string{ScopeCourses},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:       g.URL + "/auth",
+				TokenURL:      g.URL + "/token",
+				DeviceAuthURL: g.URL + "/device",
+				AuthStyle:     oauth2.AuthStyleInParams,
+			},
+		},
+		device: &Configuration{ClientID: "tv", ClientSecret: "tv-secret"},
+		store:  store,
+		file:   &FileTokenStore{},
+	}, store
+}
+
+// expiredToken returns a token that has to be refreshed.
+func expiredToken() *oauth2.Token {
+	return &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+}
+
+// TestRefreshTokenUsesIssuingClient tests that a token is refreshed with
+// the client that issued it.
+func TestRefreshTokenUsesIssuingClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		issuer string
+		want   string
+	}{
+		{"device login", "tv", "tv"},
+		{"browser login", "desktop", "desktop"},
+		{"saved before the client was recorded", "", "desktop"},
+	}
+
+	for _, tt := range tests {
+		g := newFakeGoogle(t)
+		a, store := newTestAuthenticator(g)
+		store.Save(withIssuer(expiredToken(), tt.issuer))
+
+		if _, err := a.RefreshToken(context.Background()); err != nil {
+			t.Fatalf("%s: RefreshToken failed: %v", tt.name, err)
+		}
+		if got := g.tokenClients(); len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: expected a refresh by %q, got %v", tt.name, tt.want, got)
+		}
+		if got := issuerOf(store.token); got != tt.issuer {
+			t.Errorf("%s: expected the refreshed token issued by %q, got %q", tt.name, tt.issuer, got)
+		}
+	}
+}
+
+// TestTokenManagerUsesIssuingClient tests the background refresh of a
+// device login's token.
+func TestTokenManagerUsesIssuingClient(t *testing.T) {
+	g := newFakeGoogle(t)
+	a, store := newTestAuthenticator(g)
+	store.Save(withIssuer(expiredToken(), "tv"))
+
+	m, err := a.NewTokenManager(backoff.Default())
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+	if _, err := m.Token(); err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if got := g.tokenClients(); len(got) != 1 || got[0] != "tv" {
+		t.Errorf("Expected a refresh by the device client, got %v", got)
+	}
+}
+
+// TestEncodeTokenKeepsIssuer tests that the issuing client is stored.
+func TestEncodeTokenKeepsIssuer(t *testing.T) {
+	token := withIdentity(withIssuer(expiredToken(), "tv"), &Identity{ID: "42"})
+	data, err := encodeToken(token, "")
+	if err != nil {
+		t.Fatalf("encodeToken failed: %v", err)
+	}
+	decoded, err := decodeToken(data)
+	if err != nil {
+		t.Fatalf("decodeToken failed: %v", err)
+	}
+	if issuerOf(decoded) != "tv" {
+		t.Errorf("Expected issuer tv, got %q", issuerOf(decoded))
+	}
+	if id := IdentityOf(decoded); id == nil || id.ID != "42" {
+		t.Errorf("Expected identity 42, got %+v", id)
+	}
+}
+
+// TestParsePastedCode tests reading the code from what the user pastes.
+func TestParsePastedCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"bare code", "4/0AbcDef", "4/0AbcDef", false},
+		{"full URL", "http://127.0.0.1:8085/callback?state=s1&code=4/0AbcDef&scope=x", "4/0AbcDef", false},
+		{"URL without state", "http://127.0.0.1:8085/callback?code=4/0AbcDef", "4/0AbcDef", false},
+		{"query only", "state=s1&code=4%2F0AbcDef", "4/0AbcDef", false},
+		{"wrong state", "http://127.0.0.1:8085/callback?state=old&code=4/0AbcDef", "", true},
+		{"error", "http://127.0.0.1:8085/callback?state=s1&error=access_denied", "", true},
+		{"URL without code", "http://127.0.0.1:8085/callback?state=s1&code=", "", true},
+		{"surrounding whitespace", "  \t4/0AbcDef \n", "4/0AbcDef", false},
+		{"double quotes", `"4/0AbcDef"`, "4/0AbcDef", false},
+		{"single quotes", " 'http://127.0.0.1:8085/callback?state=s1&code=4/0AbcDef' ", "4/0AbcDef", false},
+		{"backticks", "`4/0AbcDef`", "4/0AbcDef", false},
+		{"empty", "  ", "", true},
+		{"only quotes", `""`, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePastedCode(tt.input, "s1")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+// TestListenLoopback tests choosing the callback address.
+func TestListenLoopback(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer busy.Close()
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	freeAddr := free.Addr().String()
+	free.Close()
+
+	tests := []struct {
+		name       string
+		configured string
+		want       string // exact redirect URL, or "" for any free port
+		path       string
+	}{
+		{"not configured", "", "", "/callback"},
+		{"loopback without port", "http://127.0.0.1", "", "/callback"},
+		{"fixed port", "http://" + freeAddr + "/oauth", "http://" + freeAddr + "/oauth", "/oauth"},
+		{"fixed port taken", "http://" + busy.Addr().String() + "/oauth", "", "/oauth"},
+		{"not loopback", "https://example.com:8443/callback", "", "/callback"},
+	}
+
+	for _, tt := range tests {
+		listener, redirectURL, err := listenLoopback(tt.configured)
+		if err != nil {
+			t.Errorf("%s: listenLoopback failed: %v", tt.name, err)
+			continue
+		}
+		listener.Close()
+
+		u, err := url.Parse(redirectURL)
+		if err != nil {
+			t.Errorf("%s: invalid redirect URL %q", tt.name, redirectURL)
+			continue
+		}
+		if u.Host != listener.Addr().String() {
+			t.Errorf("%s: expected the redirect to reach %s, got %q", tt.name, listener.Addr(), redirectURL)
+		}
+		if u.Path != tt.path {
+			t.Errorf("%s: expected path %s, got %q", tt.name, tt.path, redirectURL)
+		}
+		if tt.want != "" && redirectURL != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, redirectURL)
+		}
+		if tt.want == "" && (u.Hostname() != "127.0.0.1" || u.Host == busy.Addr().String()) {
+			t.Errorf("%s: expected a free port on 127.0.0.1, got %q", tt.name, redirectURL)
+		}
+	}
+}
+
+// TestSharedHTTPClient tests that token refreshes and token info requests
+// go through the client from SetHTTPClient rather than the default one.
+func TestSharedHTTPClient(t *testing.T) {
+	g := newFakeGoogle(t)
+	a, store := newTestAuthenticator(g)
+	a.config.Endpoint.TokenURL = "https://oauth2.invalid/token"
+	store.Save(expiredToken())
+
+	target, _ := url.Parse(g.URL)
+	a.SetHTTPClient(&http.Client{Transport: rewriteHost{target: target}})
+	granted, err := a.GrantedScopes(context.Background())
+	if err != nil {
+		t.Fatalf("GrantedScopes failed: %v", err)
+	}
+	if len(granted) != 1 || granted[0] != ScopeCourses {
+		t.Errorf("Expected the scopes from token info, got %v", granted)
+	}
+	if got := g.tokenClients(); len(got) != 1 {
+		t.Errorf("Expected the expired token to be refreshed once, got %d", len(got))
+	}
+}
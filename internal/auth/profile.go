@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/oauth2"
+)
+
+// EnvProfile selects the OAuth profile when neither --profile nor --domain
+// is given.
+const EnvProfile = "CLASSROOM_PROFILE"
+
+// ErrUnknownProfile means the selected OAuth profile is not in the config
+// file.
+var ErrUnknownProfile = errors.New("unknown OAuth profile")
+
+// profileName restricts profile names to what is safe in a file name and a
+// keychain account.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// configFile is the OAuth part of the config file. The default
+// configuration is the "oauth" section, or the top level of a file that
+// holds only OAuth settings; "profiles" holds named configurations, e.g.
+// one per Workspace domain.
+type configFile struct {
+	Configuration
+	OAuth    *Configuration            `json:"oauth"`
+	Profiles map[string]*Configuration `json:"profiles"`
+}
+
+// readConfigFile reads the OAuth settings from path. A missing file has
+// none.
+func readConfigFile(path string) (*configFile, error) {
+	var file configFile
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	for name := range file.Profiles {
+		if !profileName.MatchString(name) {
+			return nil, fmt.Errorf("invalid OAuth profile name %q: use letters, digits, '.', '-', and '_'", name)
+		}
+	}
+	return &file, nil
+}
+
+// profile returns the named configuration, or the default one for "".
+func (f *configFile) profile(name string) (*Configuration, error) {
+	if name == "" {
+		if f.OAuth != nil {
+			return f.OAuth, nil
+		}
+		return &f.Configuration, nil
+	}
+	cfg, ok := f.Profiles[name]
+	if !ok || cfg == nil {
+		return nil, f.unknown(name)
+	}
+	return cfg, nil
+}
+
+// unknown reports a profile that is not configured, listing the ones that
+// are.
+func (f *configFile) unknown(name string) error {
+	names := f.names()
+	if len(names) == 0 {
+		return fmt.Errorf("%w %q: the config file has no profiles", ErrUnknownProfile, name)
+	}
+	return fmt.Errorf("%w %q: choose one of %s", ErrUnknownProfile, name, strings.Join(names, ", "))
+}
+
+// names returns the profile names in order.
+func (f *configFile) names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ResolveProfile returns the profile selected by name or, when name is
+// empty, by Workspace domain: the profile whose domain is domain, or the
+// default configuration if it has that domain. With neither, EnvProfile
+// decides. "" is the default configuration.
+func ResolveProfile(configPath, name, domain string) (string, error) {
+	file, err := readConfigFile(configPath)
+	if err != nil {
+		return "", err
+	}
+	if name == "" && domain == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	if name != "" {
+		if _, err := file.profile(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	if domain == "" {
+		return "", nil
+	}
+
+	domain = strings.ToLower(domain)
+	for _, name := range file.names() {
+		if strings.EqualFold(file.Profiles[name].Domain, domain) {
+			return name, nil
+		}
+	}
+	if def, _ := file.profile(""); strings.EqualFold(def.Domain, domain) {
+		return "", nil
+	}
+	return "", fmt.Errorf("%w: no profile has domain %q", ErrUnknownProfile, domain)
+}
+
+// ProfileFlags are the --profile and --domain flags that pick an OAuth
+// profile, and with it the client credentials and where the token is kept.
+type ProfileFlags struct {
+	Profile string
+	Domain  string
+}
+
+// Register adds the flags to fs.
+func (f *ProfileFlags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.Profile, "profile", "", "OAuth profile from the config file's \"profiles\" section")
+	fs.StringVar(&f.Domain, "domain", "", "use the OAuth profile for this Workspace domain")
+}
+
+// Resolve returns the profile the flags select; see ResolveProfile.
+func (f *ProfileFlags) Resolve(configPath string) (string, error) {
+	return ResolveProfile(configPath, f.Profile, f.Domain)
+}
+
+// ProfileTokenPath returns the token file of a profile: tokens.json for
+// the default configuration and tokens-<profile>.json otherwise.
+func ProfileTokenPath(profile string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	name := "tokens.json"
+	if profile != "" {
+		name = "tokens-" + profile + ".json"
+	}
+	return filepath.Join(homeDir, ".config", "google-classroom", name), nil
+}
+
+// Profiles returns the names of the profiles in the config file at
+// configPath, in order. The default configuration is not included.
+func Profiles(configPath string) ([]string, error) {
+	file, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return file.names(), nil
+}
+
+// Profile returns the name of the profile in use; "" is the default
+// configuration.
+func (a *Authenticator) Profile() string {
+	return a.profile
+}
+
+// domainOption asks Google to offer only accounts of the profile's
+// domain at login. It is a hint; checkDomain enforces it.
+func (a *Authenticator) domainOption() []oauth2.AuthCodeOption {
+	if a.domain == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("hd", a.domain)}
+}
+
+// checkDomain rejects a login to an account outside the profile's domain,
+// so a token for one domain is never stored under another's profile.
+func (a *Authenticator) checkDomain(id *Identity) error {
+	if a.domain == "" || id == nil || id.Email == "" {
+		return nil
+	}
+	_, domain, _ := strings.Cut(id.Email, "@")
+	if !strings.EqualFold(domain, a.domain) {
+		return fmt.Errorf("signed in as %s, but this profile is for %s; log in with an account in that domain", id.Email, a.domain)
+	}
+	return nil
+}
+
+// RunProfiles implements `classroom auth profiles`: it lists the OAuth
+// profiles with their domains and client IDs.
+func RunProfiles(configPath string, out io.Writer) error {
+	file, err := readConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	if len(file.Profiles) == 0 {
+		_, err := fmt.Fprintln(out, `No profiles configured. Add them to the "profiles" section of the config file.`)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROFILE\tDOMAIN\tCLIENT ID")
+	for _, name := range file.names() {
+		p := file.Profiles[name]
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, orDash(p.Domain), orDash(p.ClientID))
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/oauth2"
+)
+
+// Classroom OAuth scopes.
+const (
+	ScopeCourses               = "https://www.googleapis.com/auth/classroom.courses"
+	ScopeCoursesReadonly       = "https://www.googleapis.com/auth/classroom.courses.readonly"
+	ScopeCourseWorkStudents    = "https://www.googleapis.com/auth/classroom.coursework.students"
+	ScopeCourseWorkStudentsRO  = "https://www.googleapis.com/auth/classroom.coursework.students.readonly"
+	ScopeCourseWorkMe          = "https://www.googleapis.com/auth/classroom.coursework.me"
+	ScopeCourseWorkMeReadonly  = "https://www.googleapis.com/auth/classroom.coursework.me.readonly"
+	ScopeRosters               = "https://www.googleapis.com/auth/classroom.rosters"
+	ScopeRostersReadonly       = "https://www.googleapis.com/auth/classroom.rosters.readonly"
+	ScopeAnnouncements         = "https://www.googleapis.com/auth/classroom.announcements"
+	ScopeAnnouncementsReadonly = "https://www.googleapis.com/auth/classroom.announcements.readonly"
+	ScopeProfileEmails         = "https://www.googleapis.com/auth/classroom.profile.emails"
+	ScopeProfilePhotos         = "https://www.googleapis.com/auth/classroom.profile.photos"
+	// ScopeTopicsReadonly lets the app show which topic coursework is
+	// filed under. It is not requested at login; `auth scopes` grants it.
+	ScopeTopicsReadonly = "https://www.googleapis.com/auth/classroom.topics.readonly"
+)
+
+// Drive OAuth scopes.
+const (
+	// ScopeDriveReadonly lets the app download files attached to coursework.
+	// It is not requested at login; the first download asks for it.
+	ScopeDriveReadonly = "https://www.googleapis.com/auth/drive.readonly"
+	// ScopeDriveFile lets the app create files to attach to submissions.
+	// It is not requested at login; the first upload asks for it.
+	ScopeDriveFile = "https://www.googleapis.com/auth/drive.file"
+)
+
+// Calendar OAuth scopes. None is requested at login; `auth scopes` grants
+// them when calendar sync is first used.
+const (
+	// ScopeCalendarAppCreated lets the app create its own due-date
+	// calendar and manage the events in it.
+	ScopeCalendarAppCreated = "https://www.googleapis.com/auth/calendar.app.created"
+	// ScopeCalendarEvents lets the app add events to existing calendars,
+	// such as a course's calendar.
+	ScopeCalendarEvents = "https://www.googleapis.com/auth/calendar.events"
+	// ScopeCalendar is full calendar access, which covers both.
+	ScopeCalendar = "https://www.googleapis.com/auth/calendar"
+)
+
+const (
+	tokenInfoURL              = "https://oauth2.googleapis.com/tokeninfo"
+	includeGrantedScopesParam = "include_granted_scopes"
+	scopePrefix               = "https://www.googleapis.com/auth/"
+)
+
+// consentScopes are not requested at login but through RequestScopes the
+// first time a feature needs them, so users who never use the feature
+// never grant them.
+var consentScopes = []string{ScopeDriveReadonly, ScopeDriveFile}
+
+// credentialScopes returns the scopes service accounts and default
+// credentials are issued with: those interactive login asks for, plus
+// consentScopes, which credentials without a user cannot grant later.
+func (a *Authenticator) credentialScopes() []string {
+	return append(slices.Clone(a.config.Scopes), consentScopes...)
+}
+
+// Feature is an app feature and the scopes that enable it. Any one of the
+// scopes is enough; the first is the one requested when it is missing.
+type Feature struct {
+	Name  string
+	AnyOf []string
+}
+
+// Features lists what the app can do and which scopes each action needs.
+var Features = []Feature{
+	{Name: "View courses", AnyOf: []string{ScopeCoursesReadonly, ScopeCourses}},
+	{Name: "Create and edit courses", AnyOf: []string{ScopeCourses}},
+	{Name: "View and turn in your coursework", AnyOf: []string{ScopeCourseWorkMe, ScopeCourseWorkStudents}},
+	{Name: "View student submissions", AnyOf: []string{ScopeCourseWorkStudentsRO, ScopeCourseWorkStudents}},
+	{Name: "Grade and delete coursework", AnyOf: []string{ScopeCourseWorkStudents}},
+	{Name: "View rosters", AnyOf: []string{ScopeRostersReadonly, ScopeRosters}},
+	{Name: "Invite and remove roster members", AnyOf: []string{ScopeRosters}},
+	{Name: "Read announcements", AnyOf: []string{ScopeAnnouncementsReadonly, ScopeAnnouncements}},
+	{Name: "Post and delete announcements", AnyOf: []string{ScopeAnnouncements}},
+	{Name: "Show email addresses", AnyOf: []string{ScopeProfileEmails}},
+	{Name: "Show profile photos", AnyOf: []string{ScopeProfilePhotos}},
+	{Name: "Show coursework topics", AnyOf: []string{ScopeTopicsReadonly}},
+	{Name: "Download attachments", AnyOf: []string{ScopeDriveReadonly}},
+	{Name: "Upload files to submissions", AnyOf: []string{ScopeDriveFile}},
+	{Name: "Sync due dates to a dedicated calendar", AnyOf: []string{ScopeCalendarAppCreated, ScopeCalendar}},
+	{Name: "Sync due dates to course calendars", AnyOf: []string{ScopeCalendarEvents, ScopeCalendar}},
+}
+
+// FeatureStatus reports whether a feature is usable with the granted scopes.
+type FeatureStatus struct {
+	Feature Feature
+	Granted bool
+}
+
+// AuditScopes checks every feature against the granted scopes.
+func AuditScopes(granted []string) []FeatureStatus {
+	have := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		have[s] = true
+	}
+
+	statuses := make([]FeatureStatus, len(Features))
+	for i, f := range Features {
+		statuses[i] = FeatureStatus{Feature: f}
+		for _, s := range f.AnyOf {
+			if have[s] {
+				statuses[i].Granted = true
+				break
+			}
+		}
+	}
+	return statuses
+}
+
+// MissingScopes returns the scopes to request so every failing feature works.
+func MissingScopes(statuses []FeatureStatus) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, st := range statuses {
+		if st.Granted || len(st.Feature.AnyOf) == 0 {
+			continue
+		}
+		s := st.Feature.AnyOf[0]
+		if !seen[s] {
+			seen[s] = true
+			missing = append(missing, s)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// GrantedScopes asks Google which scopes the stored token actually carries.
+// The token is refreshed first if it has expired.
+func (a *Authenticator) GrantedScopes(ctx context.Context) ([]string, error) {
+	info, err := a.queryTokenInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(info.Scope), nil
+}
+
+// tokenInfoResponse is Google's description of an access token.
+type tokenInfoResponse struct {
+	Scope string `json:"scope"`
+	// Email is only present when the token carries the email scope.
+	Email string `json:"email"`
+}
+
+// queryTokenInfo asks Google about the current access token.
+func (a *Authenticator) queryTokenInfo(ctx context.Context) (*tokenInfoResponse, error) {
+	ts, err := a.TokenSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		tokenInfoURL+"?access_token="+url.QueryEscape(token.AccessToken), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build token info request: %w", err)
+	}
+	client := a.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token info returned %s", resp.Status)
+	}
+
+	var info tokenInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse token info: %w", err)
+	}
+	return &info, nil
+}
+
+// RequestScopes runs incremental consent for additional scopes. Previously
+// granted scopes are kept.
+func (a *Authenticator) RequestScopes(ctx context.Context, scopes []string) error {
+	cfg := *a.config
+	cfg.Scopes = append(append([]string(nil), a.config.Scopes...), scopes...)
+	return a.login(ctx, &cfg, oauth2.SetAuthURLParam(includeGrantedScopesParam, "true"))
+}
+
+// WriteScopeReport writes a table of features and whether each will work.
+func WriteScopeReport(w io.Writer, granted []string, statuses []FeatureStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Granted scopes:")
+	if len(granted) == 0 {
+		fmt.Fprintln(tw, "  (none)")
+	}
+	for _, s := range granted {
+		fmt.Fprintf(tw, "  %s\n", strings.TrimPrefix(s, scopePrefix))
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "FEATURE\tSTATUS\tNEEDS")
+	for _, st := range statuses {
+		status := "ok"
+		if !st.Granted {
+			status = "WILL FAIL"
+		}
+		needs := make([]string, len(st.Feature.AnyOf))
+		for i, s := range st.Feature.AnyOf {
+			needs[i] = strings.TrimPrefix(s, scopePrefix)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", st.Feature.Name, status, strings.Join(needs, " or "))
+	}
+
+	return tw.Flush()
+}
+
+// RunScopes implements `classroom auth scopes`: it reports granted versus
+// needed scopes and offers incremental consent for the missing ones.
+func RunScopes(ctx context.Context, a *Authenticator, in io.Reader, out io.Writer) error {
+	granted, err := a.GrantedScopes(ctx)
+	if err != nil {
+		return err
+	}
+
+	statuses := AuditScopes(granted)
+	if err := WriteScopeReport(out, granted, statuses); err != nil {
+		return err
+	}
+
+	missing := MissingScopes(statuses)
+	if len(missing) == 0 {
+		fmt.Fprintln(out, "\nAll features are available.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "\n%d scope(s) missing. Grant them now? [y/N] ", len(missing))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return nil
+	}
+
+	if err := a.RequestScopes(ctx, missing); err != nil {
+		return fmt.Errorf("failed to grant scopes: %w", err)
+	}
+	fmt.Fprintln(out, "Additional scopes granted.")
+	return nil
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+// TestAuditScopes tests which features the granted scopes enable, and the
+// scopes requested for the rest.
+func TestAuditScopes(t *testing.T) {
+	login := []string{
+		ScopeCourses, ScopeCourseWorkStudents, ScopeRosters,
+		ScopeAnnouncementsReadonly, ScopeProfileEmails, ScopeProfilePhotos,
+	}
+	tests := []struct {
+		name        string
+		granted     []string
+		wantDenied  []string
+		wantMissing []string
+	}{
+		{
+			name:        "login scopes",
+			granted:     login,
+			wantDenied:  []string{"Post and delete announcements", "Show coursework topics", "Download attachments", "Upload files to submissions", "Sync due dates to a dedicated calendar", "Sync due dates to course calendars"},
+			wantMissing: []string{ScopeAnnouncements, ScopeCalendarAppCreated, ScopeCalendarEvents, ScopeTopicsReadonly, ScopeDriveFile, ScopeDriveReadonly},
+		},
+		{
+			name:        "read only",
+			granted:     []string{ScopeCoursesReadonly, ScopeCourseWorkStudentsRO, ScopeRostersReadonly, ScopeAnnouncementsReadonly, ScopeProfileEmails, ScopeProfilePhotos, ScopeTopicsReadonly, ScopeDriveReadonly, ScopeDriveFile, ScopeCalendar},
+			wantDenied:  []string{"Create and edit courses", "View and turn in your coursework", "Grade and delete coursework", "Invite and remove roster members", "Post and delete announcements"},
+			wantMissing: []string{ScopeAnnouncements, ScopeCourses, ScopeCourseWorkMe, ScopeCourseWorkStudents, ScopeRosters},
+		},
+		{
+			name:        "a student",
+			granted:     []string{ScopeCoursesReadonly, ScopeCourseWorkMe, ScopeRostersReadonly, ScopeAnnouncementsReadonly, ScopeProfileEmails, ScopeProfilePhotos, ScopeTopicsReadonly, ScopeDriveReadonly, ScopeDriveFile, ScopeCalendarAppCreated, ScopeCalendarEvents},
+			wantDenied:  []string{"Create and edit courses", "View student submissions", "Grade and delete coursework", "Invite and remove roster members", "Post and delete announcements"},
+			wantMissing: []string{ScopeAnnouncements, ScopeCourses, ScopeCourseWorkStudents, ScopeCourseWorkStudentsRO, ScopeRosters},
+		},
+		{
+			name:        "everything",
+			granted:     append(slices.Clone(login), ScopeAnnouncements, ScopeTopicsReadonly, ScopeDriveReadonly, ScopeDriveFile, ScopeCalendar),
+			wantDenied:  nil,
+			wantMissing: nil,
+		},
+		{
+			name:    "nothing",
+			granted: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := AuditScopes(tt.granted)
+			if len(statuses) != len(Features) {
+				t.Fatalf("Expected a status for each of %d features, got %d", len(Features), len(statuses))
+			}
+			var denied []string
+			for i, st := range statuses {
+				if st.Feature.Name != Features[i].Name {
+					t.Errorf("Expected %q in order, got %q", Features[i].Name, st.Feature.Name)
+				}
+				if !st.Granted {
+					denied = append(denied, st.Feature.Name)
+				}
+			}
+
+			wantDenied, wantMissing := tt.wantDenied, tt.wantMissing
+			if tt.granted == nil {
+				// Every feature is denied and asks for its first scope
+				wantDenied, wantMissing = nil, nil
+				for _, f := range Features {
+					wantDenied = append(wantDenied, f.Name)
+					if !slices.Contains(wantMissing, f.AnyOf[0]) {
+						wantMissing = append(wantMissing, f.AnyOf[0])
+					}
+				}
+			}
+			if !slices.Equal(denied, wantDenied) {
+				t.Errorf("Expected denied %v, got %v", wantDenied, denied)
+			}
+
+			missing := MissingScopes(statuses)
+			wantMissing = slices.Clone(wantMissing)
+			sort.Strings(wantMissing)
+			if !slices.Equal(missing, wantMissing) {
+				t.Errorf("Expected missing %v, got %v", wantMissing, missing)
+			}
+		})
+	}
+}
+
+// TestMissingScopesSkipsEmptyFeatures tests that a feature without scopes
+// requests nothing.
+func TestMissingScopesSkipsEmptyFeatures(t *testing.T) {
+	missing := MissingScopes([]FeatureStatus{
+		{Feature: Feature{Name: "Nothing needed"}},
+		{Feature: Feature{Name: "Topics", AnyOf: []string{ScopeTopicsReadonly}}},
+		{Feature: Feature{Name: "Topics again", AnyOf: []string{ScopeTopicsReadonly}}},
+	})
+	if want := []string{ScopeTopicsReadonly}; !slices.Equal(missing, want) {
+		t.Errorf("Expected %v, got %v", want, missing)
+	}
+}
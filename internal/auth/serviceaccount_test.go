@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// writeServiceAccountKey writes a service account JSON key for
+// bot@project.iam.gserviceaccount.com and returns its path.
+func writeServiceAccountKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "project",
+		"private_key_id": "k1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "bot@project.iam.gserviceaccount.com",
+		"client_id":      "1",
+		"token_uri":      "https://oauth2.googleapis.com/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestNewServiceAccount tests reading a key and impersonating a user.
+func TestNewServiceAccount(t *testing.T) {
+	path := writeServiceAccountKey(t)
+
+	sa, err := NewServiceAccount(path, "ada@school.example", ScopeCourses)
+	if err != nil {
+		t.Fatalf("Failed to read the key: %v", err)
+	}
+	if sa.Email() != "bot@project.iam.gserviceaccount.com" {
+		t.Errorf("Expected the key's address, got %q", sa.Email())
+	}
+	if sa.Subject() != "ada@school.example" {
+		t.Errorf("Expected the subject, got %q", sa.Subject())
+	}
+	if !slices.Equal(sa.config.Scopes, []string{ScopeCourses}) {
+		t.Errorf("Expected the scopes, got %v", sa.config.Scopes)
+	}
+
+	other := sa.As("bo@school.example")
+	if other.Subject() != "bo@school.example" || sa.Subject() != "ada@school.example" {
+		t.Errorf("Expected As to leave the original alone, got %q and %q", other.Subject(), sa.Subject())
+	}
+
+	info := sa.status()
+	if !info.LoggedIn || info.Email != "ada@school.example" || info.Store != "service account bot@project.iam.gserviceaccount.com" {
+		t.Errorf("Expected the status to name both accounts, got %+v", info)
+	}
+}
+
+// TestNewServiceAccountErrors tests the keys and subjects that are refused.
+func TestNewServiceAccountErrors(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyPath string
+		subject string
+	}{
+		{"no subject", writeServiceAccountKey(t), ""},
+		{"missing key", filepath.Join(t.TempDir(), "missing.json"), "ada@school.example"},
+		{"invalid key", invalid, "ada@school.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewServiceAccount(tt.keyPath, tt.subject); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// TestUseServiceAccount tests that a service account replaces interactive
+// login with the scopes login asks for and those left to consent.
+func TestUseServiceAccount(t *testing.T) {
+	a := &Authenticator{config: &oauth2.Config{Scopes: []string{ScopeCourses}}}
+	if err := a.UseServiceAccount(writeServiceAccountKey(t), "ada@school.example"); err != nil {
+		t.Fatalf("Failed to use the service account: %v", err)
+	}
+
+	sa := a.ServiceAccount()
+	if sa == nil {
+		t.Fatal("Expected the service account to be in use")
+	}
+	if want := a.credentialScopes(); !slices.Equal(sa.config.Scopes, want) {
+		t.Errorf("Expected scopes %v, got %v", want, sa.config.Scopes)
+	}
+	if err := a.Login(context.Background()); !errors.Is(err, ErrServiceAccount) {
+		t.Errorf("Expected login to be refused, got %v", err)
+	}
+	info, err := a.Status()
+	if err != nil || info.Email != "ada@school.example" {
+		t.Errorf("Expected the subject's status, got %+v, %v", info, err)
+	}
+}
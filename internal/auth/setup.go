@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/google-classroom/internal/secure"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// ConsoleCredentialsURL is where an OAuth client is created.
+const ConsoleCredentialsURL = "https://console.cloud.google.com/apis/credentials"
+
+// ErrInvalidClient means Google rejected an OAuth client ID or secret.
+var ErrInvalidClient = errors.New("Google did not accept this client ID and secret")
+
+// NeedsSetup reports whether no credentials or profiles are configured at
+// configPath or in the environment, so the first-run setup should run. A
+// config file that cannot be parsed is left for NewAuthenticator to report.
+func NeedsSetup(configPath string) bool {
+	file, err := readConfigFile(configPath)
+	if err != nil || len(file.Profiles) > 0 {
+		return false
+	}
+	cfg, err := loadConfiguration(configPath)
+	if err != nil {
+		return false
+	}
+	return cfg.ClientID == "" && cfg.ServiceAccountKey == "" && !cfg.UseDefaultCredentials
+}
+
+// ValidateClient checks an OAuth client with Google before any user logs
+// in. It exchanges a code that cannot be valid: Google checks the client
+// first, so invalid_grant means the client was accepted.
+func ValidateClient(ctx context.Context, clientID, clientSecret string) error {
+	if !strings.HasSuffix(clientID, ".apps.googleusercontent.com") {
+		return fmt.Errorf("%w: a client ID ends in .apps.googleusercontent.com", ErrInvalidClient)
+	}
+
+	cfg := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  "http://127.0.0.1",
+		Endpoint:     google.Endpoint,
+	}
+	_, err := cfg.Exchange(ctx, "setup-check")
+
+	var re *oauth2.RetrieveError
+	if !errors.As(err, &re) {
+		if err == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to reach Google: %w", err)
+	}
+	switch re.ErrorCode {
+	case "invalid_grant":
+		return nil
+	case "redirect_uri_mismatch":
+		return fmt.Errorf("%w: create a Desktop app client, not a Web application client", ErrInvalidClient)
+	case "invalid_client", "unauthorized_client":
+		return ErrInvalidClient
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidClient, re.ErrorCode)
+}
+
+// SaveClient stores the OAuth client in the "oauth" section of the config
+// file at path, keeping every other setting. The file and its directory
+// are made readable only by the owner, since the file holds the secret.
+func SaveClient(path, clientID, clientSecret string) error {
+	settings := map[string]json.RawMessage{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse configuration: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	oauthSettings := map[string]any{}
+	if raw, ok := settings["oauth"]; ok {
+		if err := json.Unmarshal(raw, &oauthSettings); err != nil {
+			return fmt.Errorf("failed to parse configuration: %w", err)
+		}
+	}
+	oauthSettings["client_id"] = clientID
+	oauthSettings["client_secret"] = clientSecret
+	raw, err := json.Marshal(oauthSettings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	settings["oauth"] = raw
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return secure.WriteFile(path, append(data, '\n'))
+}
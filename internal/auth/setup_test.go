@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestValidateClient tests how Google's answer to a code exchange is read.
+func TestValidateClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		code     string // token endpoint error; "" issues a token
+		wantErr  string // "" for no error
+		invalid  bool
+	}{
+		{name: "accepted", code: "invalid_grant"},
+		{name: "exchanged", code: ""},
+		{name: "web client", code: "redirect_uri_mismatch", wantErr: "Desktop app", invalid: true},
+		{name: "unknown client", code: "invalid_client", wantErr: ErrInvalidClient.Error(), invalid: true},
+		{name: "unauthorized", code: "unauthorized_client", wantErr: ErrInvalidClient.Error(), invalid: true},
+		{name: "other", code: "invalid_request", wantErr: "invalid_request", invalid: true},
+		{name: "not a client ID", clientID: "1234", wantErr: ".apps.googleusercontent.com", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newFakeGoogle(t)
+			if tt.code != "" {
+				g.token = func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error":"` + tt.code + `"}`))
+				}
+			}
+			clientID := tt.clientID
+			if clientID == "" {
+				clientID = "1234.apps.googleusercontent.com"
+			}
+
+			err := ValidateClient(g.context(), clientID, "secret")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected an error mentioning %q, got %v", tt.wantErr, err)
+			}
+			if got := errors.Is(err, ErrInvalidClient); got != tt.invalid {
+				t.Errorf("Expected ErrInvalidClient %v, got %v", tt.invalid, got)
+			}
+		})
+	}
+}
+
+// TestValidateClientUnreachable tests that a network failure is not
+// blamed on the client.
+func TestValidateClientUnreachable(t *testing.T) {
+	g := newFakeGoogle(t)
+	ctx := g.context()
+	g.Close()
+
+	err := ValidateClient(ctx, "1234.apps.googleusercontent.com", "secret")
+	if err == nil || errors.Is(err, ErrInvalidClient) {
+		t.Errorf("Expected a connection error, got %v", err)
+	}
+}
+
+// TestSaveClient tests that the client is merged into the config file,
+// keeping every other setting, and that only the owner can read it.
+func TestSaveClient(t *testing.T) {
+	t.Setenv(EnvClientID, "")
+	t.Setenv(EnvClientSecret, "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	existing := `{
+  "theme": "dracula",
+  "oauth": {"client_id": "old", "redirect_uri": "http://127.0.0.1:8085"},
+  "profiles": {"school": {"client_id": "school-id"}}
+}`
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveClient(path, "new.apps.googleusercontent.com", "new-secret"); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	cfg, err := loadConfiguration(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cfg.ClientID != "new.apps.googleusercontent.com" || cfg.ClientSecret != "new-secret" {
+		t.Errorf("Expected the new client, got %q and %q", cfg.ClientID, cfg.ClientSecret)
+	}
+	if cfg.RedirectURI != "http://127.0.0.1:8085" {
+		t.Errorf("Expected the redirect URI to be kept, got %q", cfg.RedirectURI)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if got := string(settings["theme"]); got != `"dracula"` {
+		t.Errorf("Expected the theme to be kept, got %s", got)
+	}
+	school, err := loadProfile(path, "school")
+	if err != nil || school.ClientID != "school-id" {
+		t.Errorf("Expected the school profile to be kept, got %+v, %v", school, err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected mode 0600, got %o", perm)
+		}
+	}
+}
+
+// TestSaveClientNewFile tests saving into a config directory that does not
+// exist yet.
+func TestSaveClientNewFile(t *testing.T) {
+	t.Setenv(EnvClientID, "")
+	t.Setenv(EnvClientSecret, "")
+	path := filepath.Join(t.TempDir(), "classroom", "config.json")
+
+	if err := SaveClient(path, "new.apps.googleusercontent.com", "new-secret"); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	cfg, err := loadConfiguration(path)
+	if err != nil || cfg.ClientID != "new.apps.googleusercontent.com" {
+		t.Errorf("Expected the new client, got %+v, %v", cfg, err)
+	}
+	if NeedsSetup(path) {
+		t.Error("Expected setup to be done")
+	}
+}
+
+// TestSaveClientInvalidFile tests that a config file that cannot be parsed
+// is left alone.
+func TestSaveClientInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveClient(path, "new.apps.googleusercontent.com", "new-secret"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{not json" {
+		t.Errorf("Expected the file to be left alone, got %q", data)
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Inspect returns Status with the granted scopes, and the email address
+// when the token carries it, asked from Google. If Google cannot be
+// reached the local status is still returned along with the error.
+func (a *Authenticator) Inspect(ctx context.Context) (*TokenInfo, error) {
+	info, err := a.Status()
+	if err != nil || !info.LoggedIn {
+		return info, err
+	}
+	remote, err := a.queryTokenInfo(ctx)
+	if err != nil {
+		return info, err
+	}
+	info.Scopes = strings.Fields(remote.Scope)
+	if info.Email == "" {
+		info.Email = remote.Email
+	}
+	return info, nil
+}
+
+// FormatExpiry describes when the access token expires relative to now,
+// e.g. "in 42m10s (15:04:05)".
+func FormatExpiry(info *TokenInfo, now time.Time) string {
+	switch {
+	case info.Expiry.IsZero():
+		return "minted on demand"
+	case !info.Expiry.After(now):
+		return "expired; refreshed on next use"
+	}
+	return fmt.Sprintf("in %s (%s)", info.ExpiresIn(now).Truncate(time.Second), info.Expiry.Local().Format("15:04:05"))
+}
+
+// FormatLastRefresh describes when the token was last refreshed.
+func FormatLastRefresh(info *TokenInfo, now time.Time) string {
+	if info.LastRefresh.IsZero() {
+		return "unknown"
+	}
+	ago := now.Sub(info.LastRefresh).Truncate(time.Second)
+	return fmt.Sprintf("%s (%s ago)", info.LastRefresh.Local().Format("2006-01-02 15:04"), ago)
+}
+
+// FormatAccount describes the signed-in account, e.g. "Alex Rivera
+// <alex@example.edu>".
+func FormatAccount(info *TokenInfo) string {
+	if info.Email == "" && info.Name == "" {
+		return "unknown"
+	}
+	return (&Identity{Name: info.Name, Email: info.Email}).String()
+}
+
+// WriteStatus writes the authentication status as a table.
+func WriteStatus(w io.Writer, info *TokenInfo, now time.Time) error {
+	if !info.LoggedIn {
+		if info.Profile != "" {
+			_, err := fmt.Fprintf(w, "Not logged in to profile %s. Run `auth login --profile=%s`.\n", info.Profile, info.Profile)
+			return err
+		}
+		_, err := fmt.Fprintln(w, "Not logged in. Run `auth login`.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if info.Profile != "" {
+		fmt.Fprintf(tw, "Profile:\t%s\n", info.Profile)
+	}
+	fmt.Fprintf(tw, "Account:\t%s\n", FormatAccount(info))
+	fmt.Fprintf(tw, "Storage:\t%s\n", info.Store)
+	fmt.Fprintf(tw, "Access token:\t%s\n", FormatExpiry(info, now))
+	fmt.Fprintf(tw, "Last refresh:\t%s\n", FormatLastRefresh(info, now))
+	if len(info.Scopes) > 0 {
+		fmt.Fprintf(tw, "Scopes:\t%s\n", strings.TrimPrefix(info.Scopes[0], scopePrefix))
+		for _, s := range info.Scopes[1:] {
+			fmt.Fprintf(tw, "\t%s\n", strings.TrimPrefix(s, scopePrefix))
+		}
+	}
+	return tw.Flush()
+}
+
+// RunStatus implements `classroom auth status`.
+func RunStatus(ctx context.Context, a *Authenticator, out io.Writer) error {
+	info, err := a.Inspect(ctx)
+	if info == nil {
+		return err
+	}
+	if werr := WriteStatus(out, info, time.Now()); werr != nil {
+		return werr
+	}
+	if err != nil {
+		fmt.Fprintf(out, "\nCould not list granted scopes: %v\n", err)
+	}
+	return nil
+}
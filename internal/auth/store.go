@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/secure"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoToken means no token has been stored.
+var ErrNoToken = errors.New("no stored token found")
+
+// TokenStore persists the OAuth token.
+type TokenStore interface {
+	// Load returns the stored token, or ErrNoToken when there is none.
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+	Delete() error
+	// Name describes where the token is kept, e.g. "system keychain".
+	Name() string
+}
+
+// PassphraseEnv names the environment variable holding the passphrase the
+// token file is encrypted with when there is no keychain.
+const PassphraseEnv = "GOOGLE_CLASSROOM_PASSPHRASE"
+
+// KeySource returns the secret a token file key is derived from.
+type KeySource func() ([]byte, error)
+
+// PassphraseKey derives the token file key from passphrase.
+func PassphraseKey(passphrase string) KeySource {
+	return func() ([]byte, error) {
+		if passphrase == "" {
+			return nil, errors.New("empty passphrase")
+		}
+		return []byte(passphrase), nil
+	}
+}
+
+// MachineKey derives the token file key from this machine's identity and
+// the current user, so the file is useless when copied elsewhere.
+func MachineKey() KeySource {
+	return secure.MachineSecret
+}
+
+// DefaultKeySource uses the passphrase in PassphraseEnv when it is set and
+// the machine identity otherwise.
+func DefaultKeySource() KeySource {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return PassphraseKey(p)
+	}
+	return MachineKey()
+}
+
+// FileTokenStore keeps the token in a file readable only by its owner. It
+// is encrypted with Sealer when set, otherwise with a key derived from Key,
+// and left in plaintext when neither is set. A plaintext file is encrypted
+// the first time it is read with a key available.
+type FileTokenStore struct {
+	Path string
+	// Sealer encrypts the file with the data key from `secure enable`.
+	Sealer *secure.Sealer
+	// Key encrypts the file when there is no Sealer.
+	Key KeySource
+
+	// keySealer seals with keys derived from Key. Deriving one takes a
+	// noticeable moment, and the token is read by every status check, so
+	// each is derived once.
+	keySealerOnce sync.Once
+	keySealer     *secure.Sealer
+}
+
+// Load reads the token file.
+func (s *FileTokenStore) Load() (*oauth2.Token, error) {
+	raw, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoToken
+		}
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	var data []byte
+	if secure.IsSecretSealed(raw) {
+		data, err = s.openWithKey(raw)
+	} else {
+		data, err = s.Sealer.Open(raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	token, err := decodeToken(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !secure.IsSealed(raw) && !secure.IsSecretSealed(raw) && (s.Sealer != nil || s.Key != nil) {
+		// Encrypt a token written by an earlier version; it stays readable
+		// if that fails
+		s.Save(token)
+	}
+	return token, nil
+}
+
+// openWithKey decrypts a file sealed with a key derived from Key.
+func (s *FileTokenStore) openWithKey(raw []byte) ([]byte, error) {
+	if s.Key == nil {
+		return nil, fmt.Errorf("token file is encrypted with a passphrase; set %s", PassphraseEnv)
+	}
+	return s.derivedSealer().Open(raw)
+}
+
+// derivedSealer returns the sealer for keys derived from Key.
+func (s *FileTokenStore) derivedSealer() *secure.Sealer {
+	s.keySealerOnce.Do(func() {
+		s.keySealer = secure.NewSecretSealer(s.Key, "token")
+	})
+	return s.keySealer
+}
+
+// Save writes the token with owner-only permissions.
+func (s *FileTokenStore) Save(token *oauth2.Token) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	data, err := encodeToken(token, "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+
+	if s.Sealer == nil && s.Key != nil {
+		sealed, err := s.derivedSealer().Seal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt token (set %s to use a passphrase): %w", PassphraseEnv, err)
+		}
+		if err := secure.WriteFile(s.Path, sealed); err != nil {
+			return fmt.Errorf("failed to write token: %w", err)
+		}
+		return nil
+	}
+
+	if err := secure.SealFile(s.Path, data, s.Sealer); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the token file.
+func (s *FileTokenStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
+// Name describes the store.
+func (s *FileTokenStore) Name() string {
+	return "token file " + s.Path
+}
+
+// KeychainTokenStore keeps the token in the OS keychain: the macOS
+// Keychain, the Windows Credential Manager, or libsecret on Linux.
+type KeychainTokenStore struct {
+	keyring *secure.Keyring
+	profile string
+}
+
+// NewKeychainTokenStore returns a store for the app's token in the OS
+// keychain.
+func NewKeychainTokenStore() *KeychainTokenStore {
+	return NewProfileKeychainTokenStore("")
+}
+
+// NewProfileKeychainTokenStore returns a store for an OAuth profile's
+// token in the OS keychain; "" is the default configuration's.
+func NewProfileKeychainTokenStore(profile string) *KeychainTokenStore {
+	account, label := "oauth-token", "Google Classroom OAuth token"
+	if profile != "" {
+		account += "-" + profile
+		label += " (" + profile + ")"
+	}
+	return &KeychainTokenStore{profile: profile, keyring: &secure.Keyring{
+		Service: "google-classroom",
+		Account: account,
+		Label:   label,
+	}}
+}
+
+// Available reports whether the keychain can be used on this system.
+func (s *KeychainTokenStore) Available() bool {
+	return s.keyring.Available()
+}
+
+// Load reads the token from the keychain.
+func (s *KeychainTokenStore) Load() (*oauth2.Token, error) {
+	data, err := s.keyring.Get()
+	if errors.Is(err, secure.ErrNoSecret) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(data)
+}
+
+// Save stores the token in the keychain, replacing any previous one.
+func (s *KeychainTokenStore) Save(token *oauth2.Token) error {
+	data, err := encodeToken(token, "")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+	return s.keyring.Set(data)
+}
+
+// Delete removes the token from the keychain.
+func (s *KeychainTokenStore) Delete() error {
+	return s.keyring.Delete()
+}
+
+// Name describes the store.
+func (s *KeychainTokenStore) Name() string {
+	if s.profile != "" {
+		return "system keychain (profile " + s.profile + ")"
+	}
+	return "system keychain"
+}
+
+// storedToken is the stored form of a token: the token's own fields, when
+// it was saved, which is when it was last obtained or refreshed, who it
+// belongs to, and the OAuth client that issued it.
+type storedToken struct {
+	*oauth2.Token
+	SavedAt time.Time `json:"saved_at,omitzero"`
+	User    *Identity `json:"user,omitempty"`
+	Client  string    `json:"client_id,omitempty"`
+}
+
+// savedAtKey, identityKey, and clientKey hold the save time, the identity,
+// and the issuing client among a loaded token's extra fields.
+const (
+	savedAtKey  = "saved_at"
+	identityKey = "user"
+	clientKey   = "client_id"
+)
+
+// encodeToken marshals a token for storage, indenting with indent. A
+// loaded token that is saved again keeps its save time and identity.
+func encodeToken(token *oauth2.Token, indent string) ([]byte, error) {
+	stored := storedToken{Token: token, SavedAt: SavedAt(token), User: IdentityOf(token), Client: issuerOf(token)}
+	if stored.SavedAt.IsZero() {
+		stored.SavedAt = time.Now()
+	}
+	if indent == "" {
+		return json.Marshal(stored)
+	}
+	return json.MarshalIndent(stored, "", indent)
+}
+
+// decodeToken parses a stored token. Tokens stored before the save time,
+// identity, or client was recorded load without them.
+func decodeToken(data []byte) (*oauth2.Token, error) {
+	stored := storedToken{Token: &oauth2.Token{}}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	return withStored(stored.Token, stored.SavedAt, stored.User, stored.Client), nil
+}
+
+// withStored returns token carrying savedAt, id, and client among its extra
+// fields.
+func withStored(token *oauth2.Token, savedAt time.Time, id *Identity, client string) *oauth2.Token {
+	extra := make(map[string]any)
+	if !savedAt.IsZero() {
+		extra[savedAtKey] = savedAt
+	}
+	if id != nil {
+		extra[identityKey] = id
+	}
+	if client != "" {
+		extra[clientKey] = client
+	}
+	if len(extra) == 0 {
+		return token
+	}
+	return token.WithExtra(extra)
+}
+
+// withIdentity returns token recording that it belongs to id. A refreshed
+// token is passed through it so the identity outlives the refresh.
+func withIdentity(token *oauth2.Token, id *Identity) *oauth2.Token {
+	return withStored(token, SavedAt(token), id, issuerOf(token))
+}
+
+// withIssuer returns token recording that the OAuth client with ID client
+// issued it, so it is refreshed with the same client.
+func withIssuer(token *oauth2.Token, client string) *oauth2.Token {
+	return withStored(token, SavedAt(token), IdentityOf(token), client)
+}
+
+// refreshedFrom returns token, obtained by refreshing old, with old's
+// identity and issuing client.
+func refreshedFrom(token, old *oauth2.Token) *oauth2.Token {
+	return withStored(token, time.Time{}, IdentityOf(old), issuerOf(old))
+}
+
+// SavedAt returns when a loaded token was saved, i.e. last refreshed, or
+// the zero time if that is unknown.
+func SavedAt(token *oauth2.Token) time.Time {
+	t, _ := token.Extra(savedAtKey).(time.Time)
+	return t
+}
+
+// IdentityOf returns who a loaded token belongs to, or nil if that is
+// unknown.
+func IdentityOf(token *oauth2.Token) *Identity {
+	id, _ := token.Extra(identityKey).(*Identity)
+	return id
+}
+
+// issuerOf returns the ID of the OAuth client that issued a loaded token,
+// or "" if that is unknown.
+func issuerOf(token *oauth2.Token) string {
+	client, _ := token.Extra(clientKey).(string)
+	return client
+}
+
+// autoTokenStore prefers the keychain and falls back to the file when the
+// keychain is missing or cannot be used, e.g. without a D-Bus session. A
+// token found in the file while the keychain works is moved into it.
+type autoTokenStore struct {
+	keychain *KeychainTokenStore
+	file     *FileTokenStore
+	// active is the store that last loaded or saved the token.
+	active TokenStore
+}
+
+func (s *autoTokenStore) Load() (*oauth2.Token, error) {
+	if s.keychain.Available() {
+		token, err := s.keychain.Load()
+		if err == nil {
+			s.active = s.keychain
+			return token, nil
+		}
+		if errors.Is(err, ErrNoToken) {
+			return s.migrate()
+		}
+	}
+	token, err := s.file.Load()
+	if err == nil {
+		s.active = s.file
+	}
+	return token, err
+}
+
+// migrate moves a token from the file into the keychain and removes the
+// file. If the keychain cannot take it, the file is kept and used.
+func (s *autoTokenStore) migrate() (*oauth2.Token, error) {
+	token, err := s.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	s.active = s.file
+	if err := s.keychain.Save(token); err == nil {
+		s.active = s.keychain
+		s.file.Delete()
+	}
+	return token, nil
+}
+
+func (s *autoTokenStore) Save(token *oauth2.Token) error {
+	if s.keychain.Available() {
+		if err := s.keychain.Save(token); err == nil {
+			s.active = s.keychain
+			// Don't leave an older copy behind in the file
+			return s.file.Delete()
+		}
+	}
+	if err := s.file.Save(token); err != nil {
+		return err
+	}
+	s.active = s.file
+	return nil
+}
+
+func (s *autoTokenStore) Delete() error {
+	if s.keychain.Available() {
+		s.keychain.Delete()
+	}
+	return s.file.Delete()
+}
+
+func (s *autoTokenStore) Name() string {
+	if s.active == nil {
+		if s.keychain.Available() {
+			return s.keychain.Name()
+		}
+		return s.file.Name()
+	}
+	return s.active.Name()
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// TestFileTokenStoreMigratesPlaintext tests that a token file written
+// before encryption is encrypted when first read, and stays readable.
+func TestFileTokenStoreMigratesPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	plain := `{"access_token":"old","token_type":"Bearer","refresh_token":"refresh"}`
+	if err := os.WriteFile(path, []byte(plain), 0600); err != nil {
+		t.Fatalf("Failed to write token: %v", err)
+	}
+
+	secretReads := 0
+	key := func() ([]byte, error) {
+		secretReads++
+		return []byte("correct horse"), nil
+	}
+	store := &FileTokenStore{Path: path, Key: key}
+
+	for i := 0; i < 3; i++ {
+		token, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load %d failed: %v", i, err)
+		}
+		if token.AccessToken != "old" || token.RefreshToken != "refresh" {
+			t.Errorf("Load %d: expected the stored token, got %+v", i, token)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read token: %v", err)
+	}
+	if !secure.IsSecretSealed(raw) {
+		t.Errorf("Expected the token file to be encrypted, got %q", raw)
+	}
+	if secretReads != 1 {
+		t.Errorf("Expected the key secret to be read once, got %d", secretReads)
+	}
+
+	// Another process with the same passphrase reads it
+	other := &FileTokenStore{Path: path, Key: PassphraseKey("correct horse")}
+	if token, err := other.Load(); err != nil || token.AccessToken != "old" {
+		t.Errorf("Expected the encrypted token to load, got %v, %v", token, err)
+	}
+	wrong := &FileTokenStore{Path: path, Key: PassphraseKey("wrong")}
+	if _, err := wrong.Load(); err == nil {
+		t.Error("Expected a wrong passphrase to fail")
+	}
+}
+
+// TestFileTokenStoreReadsRandomSalt tests reading a file encrypted with a
+// random salt, as earlier versions wrote it.
+func TestFileTokenStoreReadsRandomSalt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	sealed, err := secure.SealWithSecret([]byte("correct horse"), []byte(`{"access_token":"old"}`))
+	if err != nil {
+		t.Fatalf("SealWithSecret failed: %v", err)
+	}
+	if err := os.WriteFile(path, sealed, 0600); err != nil {
+		t.Fatalf("Failed to write token: %v", err)
+	}
+
+	store := &FileTokenStore{Path: path, Key: PassphraseKey("correct horse")}
+	if token, err := store.Load(); err != nil || token.AccessToken != "old" {
+		t.Errorf("Expected the token to load, got %v, %v", token, err)
+	}
+}
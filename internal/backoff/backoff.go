@@ -0,0 +1,175 @@
+// Package backoff provides a reusable exponential backoff and retry policy.
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Policy describes how an operation is retried.
+type Policy struct {
+	// Initial is the wait before the first retry.
+	Initial time.Duration
+	// Multiplier grows the wait after each retry.
+	Multiplier float64
+	// MaxInterval caps a single wait. Zero means no cap.
+	MaxInterval time.Duration
+	// Jitter randomizes each wait by up to this fraction (0 to 1).
+	Jitter float64
+	// MaxElapsed stops retrying once this much time has passed. Zero means no limit.
+	MaxElapsed time.Duration
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// Retryable classifies errors. A nil classifier retries every error.
+	Retryable func(error) bool
+	// RetryAfter returns a wait requested by the server for err, such as an
+	// HTTP Retry-After header. A positive result replaces the computed
+	// interval, still capped by MaxInterval.
+	RetryAfter func(error) time.Duration
+}
+
+// Default returns the default retry policy.
+func Default() *Policy {
+	return &Policy{
+		Initial:     1 * time.Second,
+		Multiplier:  2,
+		MaxInterval: 30 * time.Second,
+		Jitter:      0.2,
+		MaxElapsed:  2 * time.Minute,
+		MaxAttempts: 3,
+	}
+}
+
+// WithRetryable returns a copy of the policy using the given error classifier.
+func (p *Policy) WithRetryable(fn func(error) bool) *Policy {
+	cp := *p
+	cp.Retryable = fn
+	return &cp
+}
+
+// WithRetryAfter returns a copy of the policy using the given server wait hint.
+func (p *Policy) WithRetryAfter(fn func(error) time.Duration) *Policy {
+	cp := *p
+	cp.RetryAfter = fn
+	return &cp
+}
+
+// wait returns how long to wait after err before retry number attempt.
+func (p *Policy) wait(attempt int, err error) time.Duration {
+	if p.RetryAfter != nil {
+		if hint := p.RetryAfter(err); hint > 0 {
+			if p.MaxInterval > 0 {
+				hint = min(hint, p.MaxInterval)
+			}
+			return hint
+		}
+	}
+	return p.Interval(attempt)
+}
+
+// ShouldRetry reports whether err is worth retrying under this policy.
+func (p *Policy) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+// Interval returns the wait before retry number attempt (starting at 0), with jitter applied.
+func (p *Policy) Interval(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	wait := float64(p.Initial) * math.Pow(multiplier, float64(attempt))
+	if p.MaxInterval > 0 && wait > float64(p.MaxInterval) {
+		wait = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		delta := wait * p.Jitter
+		wait = wait - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(wait)
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, or the
+// policy's attempt or elapsed-time budget is exhausted.
+func Retry[T any](ctx context.Context, p *Policy, fn func() (T, error)) (T, error) {
+	if p == nil {
+		p = Default()
+	}
+
+	var zero T
+	var lastErr error
+	tries := 0
+	start := time.Now()
+
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+
+		tries++
+		resp, err := fn()
+		if err == nil {
+			return resp, nil
+		}
+		if !p.ShouldRetry(err) {
+			return zero, err
+		}
+		lastErr = err
+
+		if attempt == attempts-1 {
+			break
+		}
+
+		wait := p.wait(attempt, err)
+		if p.MaxElapsed > 0 && time.Since(start)+wait > p.MaxElapsed {
+			break
+		}
+		if err := Sleep(ctx, wait); err != nil {
+			return zero, err
+		}
+	}
+
+	return zero, fmt.Errorf("after %d attempts: %w", tries, lastErr)
+}
+
+// Do is Retry for operations that return only an error.
+func Do(ctx context.Context, p *Policy, fn func() error) error {
+	_, err := Retry(ctx, p, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Sleep waits for d or until ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestInterval tests exponential growth and the interval cap.
+func TestInterval(t *testing.T) {
+	p := &Policy{Initial: 100 * time.Millisecond, Multiplier: 2, MaxInterval: 300 * time.Millisecond}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for attempt, want := range expected {
+		if got := p.Interval(attempt); got != want {
+			t.Errorf("Interval(%d) = %v, expected %v", attempt, got, want)
+		}
+	}
+}
+
+// TestIntervalJitter tests that jitter stays within bounds.
+func TestIntervalJitter(t *testing.T) {
+	p := &Policy{Initial: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		got := p.Interval(0)
+		if got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Interval with jitter out of range: %v", got)
+		}
+	}
+}
+
+// TestRetrySucceeds tests retrying until success.
+func TestRetrySucceeds(t *testing.T) {
+	p := &Policy{Initial: time.Millisecond, Multiplier: 2, MaxAttempts: 3}
+
+	calls := 0
+	got, err := Retry(context.Background(), p, func() (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("transient")
+		}
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if got != "ok" || calls != 3 {
+		t.Errorf("Expected ok after 3 calls, got %q after %d", got, calls)
+	}
+}
+
+// TestRetryNonRetryable tests that the classifier stops retries.
+func TestRetryNonRetryable(t *testing.T) {
+	permanent := errors.New("permanent")
+	p := (&Policy{Initial: time.Millisecond, MaxAttempts: 5}).WithRetryable(func(err error) bool {
+		return err != permanent
+	})
+
+	calls := 0
+	err := Do(context.Background(), p, func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent {
+		t.Errorf("Expected permanent error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+// TestRetryExhausted tests the attempt budget.
+func TestRetryExhausted(t *testing.T) {
+	transient := errors.New("transient")
+	p := &Policy{Initial: time.Millisecond, MaxAttempts: 2}
+
+	calls := 0
+	err := Do(context.Background(), p, func() error {
+		calls++
+		return transient
+	})
+	if !errors.Is(err, transient) {
+		t.Errorf("Expected wrapped transient error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+// TestRetryContextCancelled tests that cancellation interrupts waiting.
+func TestRetryContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Policy{Initial: time.Hour, MaxAttempts: 3}
+
+	err := Do(ctx, p, func() error {
+		cancel()
+		return errors.New("transient")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestRetryAfterHint tests that a server wait hint replaces the computed interval.
+func TestRetryAfterHint(t *testing.T) {
+	p := &Policy{
+		Initial:     time.Hour,
+		Multiplier:  2,
+		MaxAttempts: 2,
+		RetryAfter: func(err error) time.Duration {
+			return 5 * time.Millisecond
+		},
+	}
+
+	calls := 0
+	start := time.Now()
+	_, err := Retry(context.Background(), p, func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("slow down")
+		}
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the hint to replace the 1h interval, waited %v", elapsed)
+	}
+}
+
+// TestRetryAfterHintCapped tests that a server wait hint is held to the
+// policy's limits.
+func TestRetryAfterHintCapped(t *testing.T) {
+	hint := func(err error) time.Duration { return time.Hour }
+
+	p := &Policy{MaxInterval: 5 * time.Millisecond, RetryAfter: hint}
+	if got := p.wait(0, errors.New("slow down")); got != 5*time.Millisecond {
+		t.Errorf("Expected the hint capped at MaxInterval, got %v", got)
+	}
+
+	p = &Policy{MaxElapsed: time.Minute, MaxAttempts: 3, RetryAfter: hint}
+	calls := 0
+	start := time.Now()
+	_, err := Retry(context.Background(), p, func() (int, error) {
+		calls++
+		return 0, errors.New("slow down")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected to give up after 1 call, got %d calls and %v", calls, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected no wait past MaxElapsed, waited %v", elapsed)
+	}
+}
@@ -0,0 +1,284 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backend names accepted by Configuration.Backend.
+const (
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+)
+
+// Backend stores encoded cache entries by key. Entries reach it already
+// sealed, so a backend never sees plaintext when encryption is on.
+type Backend interface {
+	// Get returns the entry stored under key, or nil when there is none.
+	Get(key string) ([]byte, error)
+	// Put stores an entry, replacing any previous one under key. The times
+	// are the entry's own; backends that index them may use them to report
+	// on entries without decoding.
+	Put(key string, data []byte, cachedAt, expiresAt time.Time) error
+	Delete(key string) error
+	DeletePrefix(prefix string) error
+	Clear() error
+	// Walk calls fn for every stored entry.
+	Walk(fn func(StoredEntry) error) error
+	Close() error
+	// Name describes the backend, e.g. "files in ~/.cache/google-classroom".
+	Name() string
+}
+
+// StoredEntry is an entry as a Backend holds it. A backend that indexes
+// the key and entry times fills Key, CachedAt, and ExpiresAt and may leave
+// Data empty; otherwise Data holds the encoded entry.
+type StoredEntry struct {
+	Key       string
+	Data      []byte
+	Size      int64
+	CachedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// privateDir creates directory readable only by its owner, or restricts
+// one made by an earlier version with 0755, so the entries inside are
+// private whatever their own permissions.
+func privateDir(directory string) error {
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.Chmod(directory, 0700); err != nil {
+		return fmt.Errorf("failed to restrict cache directory: %w", err)
+	}
+	return nil
+}
+
+// fileBackend keeps one JSON file per key in a directory. Files are named
+// by the SHA-256 of the key, so distinct keys never share a file, and are
+// written through a temporary file so a crash never leaves half an entry.
+// Files are created 0600.
+type fileBackend struct {
+	directory string
+	// keyOf returns the key stored inside an encoded entry, or "" when it
+	// has none. A file name does not give the key back, so DeletePrefix
+	// uses it.
+	keyOf func(data []byte) string
+}
+
+// newFileBackend returns a backend storing entries in directory, creating
+// it if needed.
+func newFileBackend(directory string, keyOf func(data []byte) string) (*fileBackend, error) {
+	if err := privateDir(directory); err != nil {
+		return nil, err
+	}
+	return &fileBackend{directory: directory, keyOf: keyOf}, nil
+}
+
+// Get reads the entry for key. An entry written by an earlier version
+// under its sanitized key is renamed to the hashed name first.
+func (b *fileBackend) Get(key string) ([]byte, error) {
+	path := b.path(key)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && splitNamespace(key) == "" {
+		if os.Rename(b.legacyPath(key), path) != nil {
+			return nil, nil
+		}
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	return data, nil
+}
+
+func (b *fileBackend) Put(key string, data []byte, cachedAt, expiresAt time.Time) error {
+	path := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// A unique temporary file, so concurrent writes of one key cannot
+	// interleave
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+tempSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	if splitNamespace(key) == "" {
+		os.Remove(b.legacyPath(key))
+	}
+	return nil
+}
+
+func (b *fileBackend) Delete(key string) error {
+	paths := []string{b.path(key)}
+	if splitNamespace(key) == "" {
+		paths = append(paths, b.legacyPath(key))
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeletePrefix reads the key out of every hashed entry in the prefix's
+// namespace. Entries from an earlier version are matched by their
+// sanitized file name.
+func (b *fileBackend) DeletePrefix(prefix string) error {
+	safePrefix := sanitizeKey(prefix)
+	namespace := splitNamespace(prefix)
+	return b.each(namespaceDirectory(b.directory, namespace), func(path string) error {
+		name := filepath.Base(path)
+		var match bool
+		if hashedName(name) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			match = strings.HasPrefix(b.keyOf(data), prefix)
+		} else {
+			match = namespace == "" && strings.HasPrefix(name, safePrefix)
+		}
+		if !match {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// Clear removes every entry, those of every namespace included, and any
+// temporary file a crash left behind.
+func (b *fileBackend) Clear() error {
+	err := b.each(b.directory, func(path string) error {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", filepath.Base(path), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(b.directory, namespacesDir)); err != nil {
+		return fmt.Errorf("failed to delete cache namespaces: %w", err)
+	}
+	temps, _ := filepath.Glob(filepath.Join(b.directory, "*"+tempSuffix))
+	for _, path := range temps {
+		os.Remove(path)
+	}
+	return nil
+}
+
+// Walk reads every file, those of every namespace included. The entry
+// times and key are inside the encoded data, so they are left empty.
+func (b *fileBackend) Walk(fn func(StoredEntry) error) error {
+	dirs := []string{b.directory}
+	namespaces, _ := os.ReadDir(filepath.Join(b.directory, namespacesDir))
+	for _, ns := range namespaces {
+		if ns.IsDir() {
+			dirs = append(dirs, namespaceDirectory(b.directory, ns.Name()))
+		}
+	}
+
+	for _, dir := range dirs {
+		err := b.each(dir, func(path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			return fn(StoredEntry{Data: data, Size: int64(len(data))})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *fileBackend) Close() error {
+	return nil
+}
+
+func (b *fileBackend) Name() string {
+	return "files in " + b.directory
+}
+
+// each calls fn with the path of every entry file in dir. Other files
+// kept there, such as the debug log, are skipped.
+func (b *fileBackend) each(dir string, fn func(path string) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		if err := fn(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tempSuffix marks a file being written.
+const tempSuffix = ".tmp"
+
+// path returns the file path for a cache key. Entries of a namespace are
+// kept in its own directory.
+func (b *fileBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	dir := namespaceDirectory(b.directory, splitNamespace(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// legacyPath returns where versions before hashed names kept key.
+func (b *fileBackend) legacyPath(key string) string {
+	return filepath.Join(b.directory, sanitizeKey(key)+".json")
+}
+
+// hashedName reports whether a file name is a hashed key.
+func hashedName(name string) bool {
+	hash, ok := strings.CutSuffix(name, ".json")
+	if !ok || len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// sanitizeKey makes a cache key safe to use as a file name. It was how
+// entries were named before names were hashed; distinct keys can collide.
+func sanitizeKey(key string) string {
+	safeKey := strings.ReplaceAll(key, "/", "_")
+	safeKey = strings.ReplaceAll(safeKey, ":", "_")
+	safeKey = strings.ReplaceAll(safeKey, " ", "_")
+	return safeKey
+}
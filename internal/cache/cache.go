@@ -1,20 +1,38 @@
-// Package cache provides file-based caching for API responses.
+// Package cache caches API responses in files or a SQLite database.
 package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
 )
 
-// Cache provides file-based caching for API responses.
+// Cache caches API responses in a Backend.
 type Cache struct {
-	directory     string
-	coursesTTL    time.Duration
-	courseworkTTL time.Duration
+	backend      Backend
+	ttls         map[Entity]time.Duration
+	sealer       *secure.Sealer
+	ttlScale     func() float64
+	compression  string
+	namespace    string
+	invalidation invalidation
+	flights      flightGroup
+	serveStale   bool
+	stale        staleServed
+	// directory holds the marker left by SetEnabled(false); "" when the
+	// backend was passed in, so the setting is not kept.
+	directory string
+	disabled  atomic.Bool
 }
 
 // Configuration holds cache configuration.
@@ -22,25 +40,96 @@ type Configuration struct {
 	Enabled       bool
 	CoursesTTL    time.Duration
 	CourseworkTTL time.Duration
-	Directory     string
+	// AnnouncementsTTL, RostersTTL, and SubmissionsTTL default to
+	// CourseworkTTL when zero, and UserProfilesTTL to CoursesTTL.
+	AnnouncementsTTL time.Duration
+	RostersTTL       time.Duration
+	SubmissionsTTL   time.Duration
+	UserProfilesTTL  time.Duration
+	Directory        string
+	// Backend is BackendFile, one JSON file per key in Directory, or
+	// BackendSQLite, a single database in Directory. NewCache fails when
+	// the database cannot be opened. Empty means BackendFile.
+	Backend string
+	// Driver names the database/sql driver for BackendSQLite. Empty picks
+	// "sqlite", the pure-Go driver linked in, or "sqlite3" when only that
+	// is registered.
+	Driver string
+	// Namespace keeps the entries of one account apart from other
+	// accounts', e.g. the account's email address or OAuth profile name.
+	// Keys are prefixed with it, and the file backend keeps its entries in
+	// accounts/<namespace> under Directory. Empty shares one space.
+	Namespace string
+	// Compression is CodecGzip to compress large entries, or CodecNone
+	// ("" or "none") to store them as is. Entries record how they were
+	// written, so changing it keeps existing entries readable.
+	Compression string
+	// Sealer encrypts entries on disk with the data key from `secure
+	// enable`.
+	Sealer *secure.Sealer
+	// Key, when there is no Sealer, encrypts entries with AES-256-GCM under
+	// a key derived from the secret it returns, the same source the token
+	// file uses (see auth.KeySource). With neither, entries are plaintext.
+	Key func() ([]byte, error)
+	// ServeStale keeps expired entries for a week so CachedClient can
+	// answer with them when a request fails because the network is down.
+	// StaleSince reports when that happened.
+	ServeStale bool
+	// TTLScale, when set, multiplies the lifetime of every entry at read
+	// time, e.g. to stretch the cache while the daily API budget is low.
+	TTLScale func() float64
 }
 
 // DefaultConfiguration returns the default cache configuration.
 func DefaultConfiguration() *Configuration {
 	homeDir, _ := os.UserHomeDir()
 	return &Configuration{
-		Enabled:       true,
-		CoursesTTL:    5 * time.Minute,
-		CourseworkTTL: 1 * time.Hour,
-		Directory:     filepath.Join(homeDir, ".cache", "google-classroom"),
+		Enabled:          true,
+		CoursesTTL:       5 * time.Minute,
+		CourseworkTTL:    1 * time.Hour,
+		AnnouncementsTTL: 15 * time.Minute,
+		RostersTTL:       1 * time.Hour,
+		SubmissionsTTL:   15 * time.Minute,
+		UserProfilesTTL:  24 * time.Hour,
+		ServeStale:       true,
+		Directory:        filepath.Join(homeDir, ".cache", "google-classroom"),
+		Backend:          BackendFile,
 	}
 }
 
+// entrySchema versions the on-disk cache entry. Bump Version and add a
+// migration when CacheEntry changes shape. Version 2 recorded the data
+// version; entries before it hold data of DataVersion 1.
+var entrySchema = schema.Schema{Name: "cache entry", Version: 2, Migrations: []schema.Migration{
+	nil,
+	func(doc schema.Doc) error {
+		doc["data_version"] = json.RawMessage("1")
+		return nil
+	},
+}}
+
+// DataVersion versions the cached API types. Bump it when a type in
+// internal/api changes shape, e.g. gains a field the screens rely on:
+// entries of another data version are dropped rather than decoded into the
+// new types with the field missing, and the first NewCache after an
+// upgrade clears the cache.
+const DataVersion = 2
+
 // CacheEntry represents a cached entry.
 type CacheEntry struct {
-	Data      json.RawMessage `json:"data"`
-	CachedAt  time.Time       `json:"cached_at"`
-	ExpiresAt time.Time       `json:"expires_at"`
+	Version int `json:"version"`
+	// DataVersion is the DataVersion Data was cached with.
+	DataVersion int `json:"data_version"`
+	// Key is the key the entry was stored under, prefixed with the cache's
+	// namespace. Entries written before it was recorded have none.
+	Key  string          `json:"key,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+	// Codec names how Compressed was compressed. Get decompresses it into
+	// Data, so callers only see Data.
+	Codec      string    `json:"codec,omitempty"`
+	Compressed []byte    `json:"compressed,omitempty"`
+	CachedAt   time.Time `json:"cached_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
 }
 
 // NewCache creates a new cache instance.
@@ -49,54 +138,228 @@ func NewCache(cfg *Configuration) (*Cache, error) {
 		cfg = DefaultConfiguration()
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	if err := cfg.validate(); err != nil {
+		return nil, err
 	}
 
-	return &Cache{
-		directory:     cfg.Directory,
-		coursesTTL:    cfg.CoursesTTL,
-		courseworkTTL: cfg.CourseworkTTL,
-	}, nil
+	sealer := cfg.entrySealer()
+	backend, err := openBackend(cfg, sealer)
+	if err != nil {
+		return nil, err
+	}
+	c := newCache(backend, cfg, sealer)
+	c.directory = cfg.Directory
+	c.disabled.Store(disabledMarked(cfg.Directory))
+	if err := c.flushOldData(); err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return c, nil
 }
 
-// Get retrieves a cached value.
-func (c *Cache) Get(key string) (*CacheEntry, error) {
-	path := c.getPath(key)
+// NewCacheWithBackend creates a cache storing entries in backend. The
+// Directory, Backend, and Driver settings of cfg are not used.
+func NewCacheWithBackend(backend Backend, cfg *Configuration) (*Cache, error) {
+	if cfg == nil {
+		cfg = DefaultConfiguration()
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return newCache(backend, cfg, cfg.entrySealer()), nil
+}
+
+// versionFile records the DataVersion of the entries in a cache directory.
+const versionFile = "data_version"
 
+// flushOldData clears the cache when its directory was last used with an
+// older DataVersion, then records the current one. A directory without the
+// record holds data of DataVersion 1, the version when it was introduced.
+// A newer release's entries are left alone; reads skip them.
+func (c *Cache) flushOldData() error {
+	path := filepath.Join(c.directory, versionFile)
+	current := strconv.Itoa(DataVersion)
 	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // Cache miss
+	if err == nil && strings.TrimSpace(string(data)) == current {
+		return nil
+	}
+
+	recorded := 1
+	if err == nil {
+		if v, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			recorded = v
 		}
-		return nil, fmt.Errorf("failed to read cache: %w", err)
 	}
+	if recorded > DataVersion {
+		return nil
+	}
+	if recorded < DataVersion {
+		if err := c.backend.Clear(); err != nil {
+			return fmt.Errorf("failed to clear cache of an older version: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(current+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to record cache version: %w", err)
+	}
+	return nil
+}
 
-	var entry CacheEntry
-	if err := json.Unmarshal(data, &entry); err != nil {
-		return nil, fmt.Errorf("failed to parse cache entry: %w", err)
+// validate checks the settings that do not depend on the backend.
+func (cfg *Configuration) validate() error {
+	if !ValidCodec(cfg.Compression) {
+		return fmt.Errorf("unknown cache compression %q (want %q or \"none\")", cfg.Compression, CodecGzip)
+	}
+	return validNamespace(cfg.Namespace)
+}
+
+func newCache(backend Backend, cfg *Configuration, sealer *secure.Sealer) *Cache {
+	return &Cache{
+		backend:     backend,
+		ttls:        cfg.ttls(),
+		sealer:      sealer,
+		ttlScale:    cfg.TTLScale,
+		compression: cfg.Compression,
+		namespace:   cfg.Namespace,
+		serveStale:  cfg.ServeStale,
+	}
+}
+
+// ttls returns the TTL of every entity, filling in the ones left zero.
+func (cfg *Configuration) ttls() map[Entity]time.Duration {
+	or := func(ttl, fallback time.Duration) time.Duration {
+		if ttl == 0 {
+			return fallback
+		}
+		return ttl
+	}
+	return map[Entity]time.Duration{
+		EntityCourses:       cfg.CoursesTTL,
+		EntityCoursework:    cfg.CourseworkTTL,
+		EntityAnnouncements: or(cfg.AnnouncementsTTL, cfg.CourseworkTTL),
+		EntityRosters:       or(cfg.RostersTTL, cfg.CourseworkTTL),
+		EntitySubmissions:   or(cfg.SubmissionsTTL, cfg.CourseworkTTL),
+		EntityUserProfiles:  or(cfg.UserProfilesTTL, cfg.CoursesTTL),
+	}
+}
+
+// entrySealer returns the sealer entries are encrypted with: Sealer, or
+// one keyed by Key, or nil for plaintext.
+func (cfg *Configuration) entrySealer() *secure.Sealer {
+	if cfg.Sealer == nil && cfg.Key != nil {
+		return secure.NewSecretSealer(cfg.Key, "cache")
+	}
+	return cfg.Sealer
+}
+
+// openBackend opens the backend cfg asks for.
+func openBackend(cfg *Configuration, sealer *secure.Sealer) (Backend, error) {
+	switch cfg.Backend {
+	case "", BackendFile:
+	case BackendSQLite:
+		b, err := openSQLite(filepath.Join(cfg.Directory, SQLiteFile), cfg.Driver)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open SQLite cache: %w", err)
+		}
+		// Entries this old are expired under any TTL scale; a failed prune
+		// only leaves them for next time
+		b.prune(time.Now().Add(-staleAfter))
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (want %q or %q)", cfg.Backend, BackendFile, BackendSQLite)
+	}
+	return newFileBackend(cfg.Directory, entryKey(sealer))
+}
+
+// entryKey returns a function reading the key out of an entry sealed with
+// sealer, or "" when the entry cannot be read.
+func entryKey(sealer *secure.Sealer) func(data []byte) string {
+	return func(data []byte) string {
+		plain, err := sealer.Open(data)
+		if err != nil {
+			return ""
+		}
+		var entry CacheEntry
+		if _, err := entrySchema.Decode(plain, &entry); err != nil {
+			return ""
+		}
+		return entry.Key
+	}
+}
+
+// Get retrieves a cached value.
+func (c *Cache) Get(key string) (*CacheEntry, error) {
+	entry, err := c.read(key)
+	if err != nil || entry == nil {
+		return nil, err
 	}
 
 	// Check if expired
-	if time.Now().After(entry.ExpiresAt) {
-		// Clean up expired entry
-		os.Remove(path)
+	if now := time.Now(); c.expired(entry, now) {
+		// Clean up expired entry, unless it may still be served while the
+		// network is down
+		if !c.serveStale || now.Sub(entry.ExpiresAt) > staleAfter {
+			c.backend.Delete(entry.Key)
+		}
 		return nil, nil // Cache miss (expired)
 	}
 
+	return entry, nil
+}
+
+// read returns the entry stored under key, expired or not, or nil when
+// there is none or it cannot be used. entry.Key is set to the namespaced
+// key.
+func (c *Cache) read(key string) (*CacheEntry, error) {
+	if !c.Enabled() {
+		return nil, nil // Cache miss; caching is turned off
+	}
+	key = namespacedKey(c.namespace, key)
+	raw, err := c.backend.Get(key)
+	if err != nil || raw == nil {
+		return nil, err // nil, nil is a cache miss
+	}
+	data, err := c.sealer.Open(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var entry CacheEntry
+	if _, err := entrySchema.Decode(data, &entry); err != nil {
+		if !errors.Is(err, schema.ErrTooNew) {
+			// Unreadable, e.g. cut short; it is refetched
+			c.backend.Delete(key)
+		}
+		return nil, nil // Cache miss; or a newer release owns the entry
+	}
+	if entry.Key != "" && entry.Key != key {
+		return nil, nil // Cache miss; an old file name shared by two keys
+	}
+	if entry.DataVersion != DataVersion {
+		if entry.DataVersion < DataVersion {
+			c.backend.Delete(key)
+		}
+		return nil, nil // Cache miss; the API types have changed since
+	}
+	if entry.Codec != "" {
+		data, err := decompress(entry.Codec, entry.Compressed)
+		if errors.Is(err, errUnknownCodec) {
+			return nil, nil // Cache miss; a newer release wrote the entry
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry.Data, entry.Codec, entry.Compressed = data, "", nil
+	}
+	entry.Key = key
 	return &entry, nil
 }
 
 // Set stores a value in the cache.
 func (c *Cache) Set(key string, value interface{}, ttl time.Duration) error {
-	path := c.getPath(key)
-
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %w", err)
+	if !c.Enabled() {
+		return nil
 	}
+	key = namespacedKey(c.namespace, key)
 
 	// Marshal data
 	jsonData, err := json.Marshal(value)
@@ -107,54 +370,59 @@ func (c *Cache) Set(key string, value interface{}, ttl time.Duration) error {
 	// Create entry
 	now := time.Now()
 	entry := CacheEntry{
-		Data:      jsonData,
-		CachedAt:  now,
-		ExpiresAt: now.Add(ttl),
+		Version:     entrySchema.Version,
+		DataVersion: DataVersion,
+		Key:         key,
+		Data:        jsonData,
+		CachedAt:    now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	compressed, err := compress(c.compression, jsonData)
+	if err != nil {
+		return err
+	}
+	if compressed != nil {
+		entry.Data, entry.Codec, entry.Compressed = nil, c.compression, compressed
 	}
 
-	// Write to file
 	jsonBytes, err := json.MarshalIndent(entry, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache entry: %w", err)
 	}
 
-	if err := os.WriteFile(path, jsonBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write cache: %w", err)
+	jsonBytes, err = c.sealer.Seal(jsonBytes)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cache entry: %w", err)
 	}
 
-	return nil
+	return c.backend.Put(key, jsonBytes, entry.CachedAt, entry.ExpiresAt)
 }
 
 // Delete removes a cached value.
 func (c *Cache) Delete(key string) error {
-	path := c.getPath(key)
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete cache: %w", err)
-	}
-	return nil
+	return c.backend.Delete(namespacedKey(c.namespace, key))
+}
+
+// DeletePrefix removes every cached value whose key starts with prefix.
+func (c *Cache) DeletePrefix(prefix string) error {
+	return c.backend.DeletePrefix(namespacedKey(c.namespace, prefix))
 }
 
-// Clear removes all cached values.
+// Clear removes all cached values, those of every namespace included. Use
+// ClearNamespace to remove one account's.
 func (c *Cache) Clear() error {
-	entries, err := os.ReadDir(c.directory)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("failed to read cache directory: %w", err)
-	}
+	return c.backend.Clear()
+}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		path := filepath.Join(c.directory, entry.Name())
-		if err := os.Remove(path); err != nil {
-			return fmt.Errorf("failed to delete %s: %w", entry.Name(), err)
-		}
-	}
+// Close releases the backend, e.g. the SQLite database.
+func (c *Cache) Close() error {
+	return c.backend.Close()
+}
 
-	return nil
+// Backend describes where entries are stored, e.g. "files in
+// ~/.cache/google-classroom".
+func (c *Cache) Backend() string {
+	return c.backend.Name()
 }
 
 // Stats returns cache statistics.
@@ -163,78 +431,116 @@ type CacheStats struct {
 	ValidEntries   int
 	ExpiredEntries int
 	TotalSize      int64
+	// Courses breaks down the cache's own entries by course ID. Entries
+	// not about one course, such as the course list, and entries written
+	// before keys were recorded are only in the totals.
+	Courses map[string]*CourseStats
+}
+
+// CourseStats counts the cached entries of one course.
+type CourseStats struct {
+	Entries        int
+	ExpiredEntries int
+	Size           int64
 }
 
 // GetStats returns cache statistics.
 func (c *Cache) GetStats() (*CacheStats, error) {
-	stats := &CacheStats{}
-
-	entries, err := os.ReadDir(c.directory)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return stats, nil
-		}
-		return nil, fmt.Errorf("failed to read cache directory: %w", err)
-	}
-
+	stats := &CacheStats{Courses: make(map[string]*CourseStats)}
 	now := time.Now()
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		stats.TotalEntries++
 
-		path := filepath.Join(c.directory, entry.Name())
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-
-		info, _ := os.Stat(path)
-		stats.TotalSize += info.Size()
-
-		var cacheEntry CacheEntry
-		if err := json.Unmarshal(data, &cacheEntry); err != nil {
-			continue
+	err := c.backend.Walk(func(stored StoredEntry) error {
+		stats.TotalEntries++
+		stats.TotalSize += stored.Size
+
+		cacheEntry := CacheEntry{Key: stored.Key, CachedAt: stored.CachedAt, ExpiresAt: stored.ExpiresAt}
+		if stored.ExpiresAt.IsZero() {
+			data, err := c.sealer.Open(stored.Data)
+			if err != nil {
+				return nil
+			}
+			if _, err := entrySchema.Decode(data, &cacheEntry); err != nil {
+				return nil
+			}
 		}
 
-		if now.After(cacheEntry.ExpiresAt) {
+		expired := c.expired(&cacheEntry, now)
+		if expired {
 			stats.ExpiredEntries++
 		} else {
 			stats.ValidEntries++
 		}
+
+		if courseID := c.courseOf(cacheEntry.Key); courseID != "" {
+			course := stats.Courses[courseID]
+			if course == nil {
+				course = &CourseStats{}
+				stats.Courses[courseID] = course
+			}
+			course.Entries++
+			course.Size += stored.Size
+			if expired {
+				course.ExpiredEntries++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return stats, nil
 }
 
-// GenerateKey generates a cache key from endpoint and parameters.
-func GenerateKey(endpoint string, params map[string]string) string {
-	var parts []string
-	parts = append(parts, endpoint)
-
-	for key, value := range params {
-		parts = append(parts, fmt.Sprintf("%s=%s", key, value))
+// expired reports whether entry has outlived its TTL, scaled by TTLScale.
+func (c *Cache) expired(entry *CacheEntry, now time.Time) bool {
+	expires := entry.ExpiresAt
+	if c.ttlScale != nil {
+		if scale := c.ttlScale(); scale > 1 {
+			ttl := entry.ExpiresAt.Sub(entry.CachedAt)
+			expires = entry.CachedAt.Add(time.Duration(float64(ttl) * scale))
+		}
 	}
+	return now.After(expires)
+}
 
-	return strings.Join(parts, "&")
+// GenerateKey generates a cache key from endpoint and parameters. The
+// parameters are sorted by name, so the same request always has the same
+// key, and escaped, so values containing & or = cannot make two requests
+// share one.
+func GenerateKey(endpoint string, params map[string]string) string {
+	values := make(url.Values, len(params))
+	for name, value := range params {
+		values.Set(name, value)
+	}
+	// PathEscape escapes ?, so the first one always ends the endpoint
+	return url.PathEscape(endpoint) + "?" + values.Encode()
 }
 
-// getPath returns the file path for a cache key.
-func (c *Cache) getPath(key string) string {
-	// Sanitize key for file system
-	safeKey := strings.ReplaceAll(key, "/", "_")
-	safeKey = strings.ReplaceAll(safeKey, ":", "_")
-	safeKey = strings.ReplaceAll(safeKey, " ", "_")
-	return filepath.Join(c.directory, safeKey+".json")
+// Entity is a kind of API data with its own TTL.
+type Entity string
+
+// Entities cached by CachedClient.
+const (
+	EntityCourses       Entity = "courses"
+	EntityCoursework    Entity = "coursework"
+	EntityAnnouncements Entity = "announcements"
+	EntityRosters       Entity = "rosters"
+	EntitySubmissions   Entity = "submissions"
+	EntityUserProfiles  Entity = "user_profiles"
+)
+
+// TTL returns how long entries of entity stay fresh.
+func (c *Cache) TTL(entity Entity) time.Duration {
+	return c.ttls[entity]
 }
 
 // GetCoursesTTL returns the TTL for courses.
 func (c *Cache) GetCoursesTTL() time.Duration {
-	return c.coursesTTL
+	return c.TTL(EntityCourses)
 }
 
 // GetCourseworkTTL returns the TTL for coursework.
 func (c *Cache) GetCourseworkTTL() time.Duration {
-	return c.courseworkTTL
+	return c.TTL(EntityCoursework)
 }
@@ -1,9 +1,17 @@
 package cache
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/secure"
 )
 
 // TestNewCache tests creating a new cache.
@@ -133,6 +141,41 @@ func TestCacheExpiration(t *testing.T) {
 	}
 }
 
+// TestCacheTTLScale tests that a TTL scale keeps entries past their TTL.
+func TestCacheTTLScale(t *testing.T) {
+	scale := 4.0
+	cache, err := NewCache(&Configuration{
+		Enabled:   true,
+		Directory: t.TempDir(),
+		TTLScale:  func() float64 { return scale },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if err := cache.Set("stretched", "value", 100*time.Millisecond); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	entry, err := cache.Get("stretched")
+	if err != nil {
+		t.Fatalf("Failed to get cache value: %v", err)
+	}
+	if entry == nil {
+		t.Fatal("Expected entry to outlive its TTL while scaled")
+	}
+
+	scale = 1
+	entry, err = cache.Get("stretched")
+	if err != nil {
+		t.Fatalf("Failed to get cache value: %v", err)
+	}
+	if entry != nil {
+		t.Error("Expected entry to expire once the scale is removed")
+	}
+}
+
 // TestCacheDelete tests deleting cached values.
 func TestCacheDelete(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -276,4 +319,622 @@ func TestGenerateKey(t *testing.T) {
 	if len(key) == 0 {
 		t.Error("Generated key is empty")
 	}
+
+	// Parameters are sorted, so map order never changes the key
+	if key != "courses?courseId=123&userId=456" {
+		t.Errorf("Expected sorted parameters, got %s", key)
+	}
+	for i := 0; i < 20; i++ {
+		if again := GenerateKey("courses", params); again != key {
+			t.Fatalf("Expected the same key every time, got %s and %s", key, again)
+		}
+	}
+
+	// Separators inside names and values are escaped, so these differ
+	pairs := [][2]string{
+		{GenerateKey("courses", map[string]string{"a": "1&b=2"}), GenerateKey("courses", map[string]string{"a": "1", "b": "2"})},
+		{GenerateKey("courses?a=1", nil), GenerateKey("courses", map[string]string{"a": "1"})},
+		{GenerateKey("courses", map[string]string{"a=1": ""}), GenerateKey("courses", map[string]string{"a": "1="})},
+	}
+	for _, p := range pairs {
+		if p[0] == p[1] {
+			t.Errorf("Expected different keys, both were %s", p[0])
+		}
+	}
+}
+
+// TestTypedHelpers tests the typed getters and setters.
+func TestTypedHelpers(t *testing.T) {
+	cache, err := NewCache(&Configuration{Directory: t.TempDir(), CoursesTTL: time.Minute, CourseworkTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if _, ok := cache.GetCourses("courses"); ok {
+		t.Error("Expected a miss before anything is cached")
+	}
+	if err := cache.SetCourses("courses", []*api.Course{{ID: "c1", Name: "Math"}}); err != nil {
+		t.Fatalf("Failed to set courses: %v", err)
+	}
+	courses, ok := cache.GetCourses("courses")
+	if !ok || len(courses) != 1 || courses[0].Name != "Math" {
+		t.Errorf("Expected the cached course, got %v, %v", courses, ok)
+	}
+
+	if err := cache.SetCourseWork("coursework", []*api.CourseWork{{ID: "w1", Title: "Essay"}}); err != nil {
+		t.Fatalf("Failed to set coursework: %v", err)
+	}
+	if work, ok := cache.GetCourseWork("coursework"); !ok || len(work) != 1 || work[0].Title != "Essay" {
+		t.Errorf("Expected the cached coursework, got %v, %v", work, ok)
+	}
+
+	// An entry of another type is a miss, not an error
+	if err := SetValue(cache, "count", 3, time.Minute); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if _, ok := GetValue[[]string](cache, "count"); ok {
+		t.Error("Expected a miss for a value of another type")
+	}
+	if n, ok := GetValue[int](cache, "count"); !ok || n != 3 {
+		t.Errorf("Expected 3, got %v, %v", n, ok)
+	}
+}
+
+// TestCacheSealed tests that entries are encrypted on disk and read back.
+func TestCacheSealed(t *testing.T) {
+	key, err := secure.NewKey()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	tmpDir := t.TempDir()
+	cache, err := NewCache(&Configuration{Directory: tmpDir, Sealer: secure.NewSealer(&secure.Ring{Current: key})})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if err := cache.Set("roster", "student@example.com", time.Minute); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	raw, _ := os.ReadFile((&fileBackend{directory: tmpDir}).path("roster"))
+	if !secure.IsSealed(raw) {
+		t.Error("Expected entry to be encrypted on disk")
+	}
+
+	entry, err := cache.Get("roster")
+	if err != nil || entry == nil || string(entry.Data) != `"student@example.com"` {
+		t.Errorf("Expected decrypted entry, got %+v, %v", entry, err)
+	}
+}
+
+// TestCacheVersions tests that unversioned entries are migrated, and read
+// while DataVersion is 1, and entries from a newer release are treated as
+// misses.
+func TestCacheVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	cache, err := NewCache(&Configuration{Directory: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	expires := time.Now().Add(time.Hour).Format(time.RFC3339)
+	legacy := `{"data": "old", "cached_at": "2020-01-01T00:00:00Z", "expires_at": "` + expires + `"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "legacy.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write entry: %v", err)
+	}
+	var migrated CacheEntry
+	if _, err := entrySchema.Decode([]byte(legacy), &migrated); err != nil {
+		t.Fatalf("Failed to migrate unversioned entry: %v", err)
+	}
+	if string(migrated.Data) != `"old"` || migrated.Version != entrySchema.Version || migrated.DataVersion != 1 {
+		t.Errorf("Expected migrated entry of data version 1, got %+v", migrated)
+	}
+	entry, err := cache.Get("legacy")
+	if err != nil || (entry == nil) != (DataVersion > 1) {
+		t.Fatalf("Expected the unversioned entry only while DataVersion is 1, got %v, %v", entry, err)
+	}
+
+	newer := `{"version": 99, "data": "new", "expires_at": "` + expires + `"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "newer.json"), []byte(newer), 0644); err != nil {
+		t.Fatalf("Failed to write entry: %v", err)
+	}
+	entry, err = cache.Get("newer")
+	if err != nil || entry != nil {
+		t.Errorf("Expected a miss for a newer entry, got %v, %v", entry, err)
+	}
+}
+
+// TestCacheDataVersions tests that entries of another data version and
+// unreadable entries are misses, and that opening a cache last used with
+// an older data version clears it.
+func TestCacheDataVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	cache, err := NewCache(&Configuration{Directory: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, versionFile)); strings.TrimSpace(string(data)) != fmt.Sprint(DataVersion) {
+		t.Errorf("Expected the data version to be recorded, got %q", data)
+	}
+
+	now := time.Now()
+	expires := now.Add(time.Hour).Format(time.RFC3339)
+	for k, raw := range map[string]string{
+		"older":  `{"version": 2, "data_version": 0, "data": "old", "expires_at": "` + expires + `"}`,
+		"newer":  `{"version": 2, "data_version": 99, "data": "new", "expires_at": "` + expires + `"}`,
+		"broken": `{"version": 2, "data_ver`,
+	} {
+		if err := cache.backend.Put(k, []byte(raw), now, now.Add(time.Hour)); err != nil {
+			t.Fatalf("Failed to write entry: %v", err)
+		}
+		entry, err := cache.Get(k)
+		if err != nil || entry != nil {
+			t.Errorf("Expected a miss for the %s entry, got %+v, %v", k, entry, err)
+		}
+		stored, _ := cache.backend.Get(k)
+		if kept := stored != nil; kept != (k == "newer") {
+			t.Errorf("Unexpected %s entry kept: %v", k, kept)
+		}
+	}
+
+	if err := cache.Set("current", "data", time.Hour); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, versionFile), []byte("0\n"), 0600); err != nil {
+		t.Fatalf("Failed to write version: %v", err)
+	}
+	reopened, err := NewCache(&Configuration{Directory: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to reopen cache: %v", err)
+	}
+	if entry, _ := reopened.Get("current"); entry != nil {
+		t.Error("Expected a cache of an older data version to be cleared")
+	}
+}
+
+// TestCacheSQLite tests that the SQLite backend stores entries in
+// cache.db through the linked driver, that failing to open it is
+// reported, and that unknown backends are rejected.
+func TestCacheSQLite(t *testing.T) {
+	tmpDir := t.TempDir()
+	cache, err := NewCache(&Configuration{Directory: tmpDir, Backend: BackendSQLite})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer cache.Close()
+
+	if want := "SQLite database " + filepath.Join(tmpDir, SQLiteFile); cache.Backend() != want {
+		t.Errorf("Expected %s, got %s", want, cache.Backend())
+	}
+	if err := cache.Set("courses", []string{"math"}, time.Minute); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	entry, err := cache.Get("courses")
+	if err != nil || entry == nil {
+		t.Fatalf("Expected the entry back, got %v", err)
+	}
+	var courses []string
+	if err := json.Unmarshal(entry.Data, &courses); err != nil || len(courses) != 1 || courses[0] != "math" {
+		t.Errorf("Expected [math], got %v (%v)", courses, err)
+	}
+	if _, err := os.Stat((&fileBackend{directory: tmpDir}).path("courses")); !os.IsNotExist(err) {
+		t.Errorf("Expected no entry file, got %v", err)
+	}
+
+	_, err = NewCache(&Configuration{Directory: t.TempDir(), Backend: BackendSQLite, Driver: "sqlite3"})
+	if !errors.Is(err, ErrNoSQLiteDriver) {
+		t.Errorf("Expected ErrNoSQLiteDriver, got %v", err)
+	}
+	if _, err := NewCache(&Configuration{Directory: tmpDir, Backend: "redis"}); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+}
+
+// TestSQLiteBackend tests Get, Put, DeletePrefix, Walk, and prune against
+// a real database.
+func TestSQLiteBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SQLiteFile)
+	b, err := openSQLite(path, "")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer b.Close()
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected an owner-only database, got %v (%v)", info, err)
+	}
+
+	now := time.Now().Truncate(time.Millisecond)
+	put := func(key, data string, expiresAt time.Time) {
+		t.Helper()
+		if err := b.Put(key, []byte(data), now, expiresAt); err != nil {
+			t.Fatalf("Failed to put %s: %v", key, err)
+		}
+	}
+	put("course:1", "old", now.Add(time.Hour))
+	put("course:1", "new", now.Add(time.Hour))
+	put("course:10", "ten", now.Add(time.Hour))
+	put("course_1", "underscore", now.Add(time.Hour))
+	put("Course:2", "upper", now.Add(time.Hour))
+	put("stale", "gone", now.Add(-time.Hour))
+
+	if data, err := b.Get("course:1"); err != nil || string(data) != "new" {
+		t.Errorf("Expected the replaced value, got %q (%v)", data, err)
+	}
+	if data, err := b.Get("missing"); err != nil || data != nil {
+		t.Errorf("Expected no value for a missing key, got %q (%v)", data, err)
+	}
+
+	entries := map[string]StoredEntry{}
+	if err := b.Walk(func(e StoredEntry) error {
+		entries[e.Key] = e
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to walk: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Errorf("Expected 5 entries, got %d", len(entries))
+	}
+	if e := entries["course:10"]; e.Size != 3 || !e.CachedAt.Equal(now) || !e.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected size and times from the columns, got %+v", e)
+	}
+
+	if err := b.prune(now); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if data, _ := b.Get("stale"); data != nil {
+		t.Error("Expected prune to drop the expired entry")
+	}
+
+	// The prefix matches literally and with case: neither _ nor C match
+	if err := b.DeletePrefix("course:1"); err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+	for key, want := range map[string]bool{"course:1": false, "course:10": false, "course_1": true, "Course:2": true} {
+		if data, _ := b.Get(key); (data != nil) != want {
+			t.Errorf("%s: expected kept %v, got %q", key, want, data)
+		}
+	}
+}
+
+// TestCacheHashedKeys tests that keys which sanitize to the same file name
+// are kept apart, that writes leave no temporary files behind, and that
+// DeletePrefix finds hashed entries by the key stored inside them.
+func TestCacheHashedKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	cache, err := NewCache(&Configuration{Directory: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if err := cache.Set("a/b", "slash", time.Minute); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	if err := cache.Set("a_b", "underscore", time.Minute); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	entry, err := cache.Get("a/b")
+	if err != nil || entry == nil || string(entry.Data) != `"slash"` || entry.Key != "a/b" {
+		t.Errorf("Expected the a/b entry, got %+v, %v", entry, err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(tmpDir, "*.json"))
+	temps, _ := filepath.Glob(filepath.Join(tmpDir, "*"+tempSuffix))
+	if len(files) != 2 || len(temps) != 0 {
+		t.Errorf("Expected 2 entry files and no temporary files, got %d and %d", len(files), len(temps))
+	}
+
+	if err := cache.DeletePrefix("a/"); err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+	if entry, _ := cache.Get("a/b"); entry != nil {
+		t.Error("Expected a/b to be deleted")
+	}
+	if entry, _ := cache.Get("a_b"); entry == nil {
+		t.Error("Expected a_b to be kept")
+	}
+}
+
+// TestCacheSecretKey tests encrypting entries with a key derived from a
+// secret, and that entries and the directory are private.
+func TestCacheSecretKey(t *testing.T) {
+	tmpDir := filepath.Join(t.TempDir(), "cache")
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	cache, err := NewCache(&Configuration{Directory: tmpDir, Key: func() ([]byte, error) {
+		return []byte("passphrase"), nil
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if err := cache.Set("roster", "student@example.com", time.Minute); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	path := (&fileBackend{directory: tmpDir}).path("roster")
+	raw, _ := os.ReadFile(path)
+	if !secure.IsSecretSealed(raw) || strings.Contains(string(raw), "student") {
+		t.Error("Expected entry to be encrypted on disk")
+	}
+	entry, err := cache.Get("roster")
+	if err != nil || entry == nil || string(entry.Data) != `"student@example.com"` {
+		t.Errorf("Expected decrypted entry, got %+v, %v", entry, err)
+	}
+	if err := cache.DeletePrefix("ros"); err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected DeletePrefix to read the key of an encrypted entry")
+	}
+
+	cache.Set("roster", "student@example.com", time.Minute)
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a 0600 entry, got %v, %v", info.Mode().Perm(), err)
+	}
+	if info, err := os.Stat(tmpDir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected the directory to be restricted to 0700, got %v, %v", info.Mode().Perm(), err)
+	}
+}
+
+// TestCacheCompression tests that large entries are compressed, small ones
+// are not, and entries written either way stay readable.
+func TestCacheCompression(t *testing.T) {
+	tmpDir := t.TempDir()
+	cache, err := NewCache(&Configuration{Directory: tmpDir, Compression: CodecGzip})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	b := &fileBackend{directory: tmpDir}
+
+	roster := strings.Repeat(`{"name":"Student","email":"student@example.com"},`, 100)
+	if err := cache.Set("roster", roster, time.Minute); err != nil {
+		t.Fatalf("Failed to set cache value: %v", err)
+	}
+	raw, _ := os.ReadFile(b.path("roster"))
+	if !strings.Contains(string(raw), `"codec": "gzip"`) || strings.Contains(string(raw), "student@example.com") {
+		t.Errorf("Expected a compressed entry, got %d bytes", len(raw))
+	}
+	if len(raw) >= len(roster) {
+		t.Errorf("Expected compression to shrink the entry, got %d bytes for %d", len(raw), len(roster))
+	}
+	entry, err := cache.Get("roster")
+	if err != nil || entry == nil || entry.Codec != "" {
+		t.Fatalf("Expected a decompressed entry, got %+v, %v", entry, err)
+	}
+	var got string
+	if err := json.Unmarshal(entry.Data, &got); err != nil || got != roster {
+		t.Errorf("Expected the roster back, got %v", err)
+	}
+
+	cache.Set("small", "x", time.Minute)
+	if raw, _ := os.ReadFile(b.path("small")); strings.Contains(string(raw), "codec") {
+		t.Error("Expected a small entry to be stored as is")
+	}
+
+	// Turning compression off keeps compressed entries readable
+	plain, err := NewCache(&Configuration{Directory: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if entry, err := plain.Get("roster"); err != nil || entry == nil {
+		t.Errorf("Expected the compressed entry without compression, got %v, %v", entry, err)
+	}
+
+	// An entry from a codec this version does not know is a miss
+	expires := time.Now().Add(time.Hour).Format(time.RFC3339)
+	zstd := `{"version": 1, "key": "zstd", "codec": "zstd", "compressed": "AAAA", "expires_at": "` + expires + `"}`
+	os.WriteFile(b.path("zstd"), []byte(zstd), 0600)
+	if entry, err := cache.Get("zstd"); err != nil || entry != nil {
+		t.Errorf("Expected a miss for an unknown codec, got %v, %v", entry, err)
+	}
+
+	if _, err := NewCache(&Configuration{Directory: tmpDir, Compression: "lz4"}); err == nil {
+		t.Error("Expected error for unknown compression")
+	}
+}
+
+// TestCacheNamespaces tests that accounts sharing a directory do not see
+// each other's entries and can be cleared one at a time.
+func TestCacheNamespaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	alice, err := NewCache(&Configuration{Directory: tmpDir, Namespace: "alice@school.edu"})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	bob, err := NewCache(&Configuration{Directory: tmpDir, Namespace: "bob@school.edu"})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	alice.Set("courses.", []string{"alice's"}, time.Minute)
+	bob.Set("courses.", []string{"bob's"}, time.Minute)
+
+	entry, err := alice.Get("courses.")
+	if err != nil || entry == nil || !strings.Contains(string(entry.Data), "alice's") {
+		t.Errorf("Expected alice's entry, got %+v, %v", entry, err)
+	}
+	entry, err = bob.Get("courses.")
+	if err != nil || entry == nil || !strings.Contains(string(entry.Data), "bob's") {
+		t.Errorf("Expected bob's entry, got %+v, %v", entry, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "accounts", "alice@school.edu")); err != nil {
+		t.Errorf("Expected a directory for alice: %v", err)
+	}
+
+	if err := bob.ClearNamespace(alice.Namespace()); err != nil {
+		t.Fatalf("Failed to clear namespace: %v", err)
+	}
+	if entry, _ := alice.Get("courses."); entry != nil {
+		t.Error("Expected alice's entries to be cleared")
+	}
+	if entry, _ := bob.Get("courses."); entry == nil {
+		t.Error("Expected bob's entries to be kept")
+	}
+	if stats, _ := bob.GetStats(); stats.TotalEntries != 1 {
+		t.Errorf("Expected 1 entry left, got %d", stats.TotalEntries)
+	}
+
+	if err := bob.ClearNamespace(""); err == nil {
+		t.Error("Expected error for an empty namespace")
+	}
+	if _, err := NewCache(&Configuration{Directory: tmpDir, Namespace: "../escape"}); err == nil {
+		t.Error("Expected error for an invalid namespace")
+	}
+
+	if err := bob.Clear(); err != nil {
+		t.Fatalf("Failed to clear cache: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "accounts")); !os.IsNotExist(err) {
+		t.Error("Expected Clear to remove every namespace")
+	}
+}
+
+// TestCacheManagement tests per-course stats, clearing one course, and
+// turning caching off and back on.
+func TestCacheManagement(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Configuration{
+		Enabled:       true,
+		CoursesTTL:    5 * time.Minute,
+		CourseworkTTL: 1 * time.Hour,
+		Directory:     tmpDir,
+		Namespace:     "alex@example.com",
+	}
+	c, err := NewCache(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	for _, k := range []string{key("courses"), key("course", "c1"), key("coursework", "c1", "list"), key("students", "c2")} {
+		if err := c.Set(k, "data", time.Hour); err != nil {
+			t.Fatalf("Failed to set %s: %v", k, err)
+		}
+	}
+	if err := c.Set(key("submissions", "c2", "w1"), "old", -time.Minute); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+
+	stats, err := c.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.TotalEntries != 5 || len(stats.Courses) != 2 {
+		t.Fatalf("Expected 5 entries in 2 courses, got %d in %v", stats.TotalEntries, stats.Courses)
+	}
+	if got := stats.Courses["c1"]; got.Entries != 2 || got.ExpiredEntries != 0 || got.Size == 0 {
+		t.Errorf("Unexpected stats for c1: %+v", got)
+	}
+	if got := stats.Courses["c2"]; got.Entries != 2 || got.ExpiredEntries != 1 {
+		t.Errorf("Unexpected stats for c2: %+v", got)
+	}
+
+	var out strings.Builder
+	if err := RunCache(c, []string{"clear", "--course=c1"}, &out, &out); err != nil {
+		t.Fatalf("cache clear --course failed: %v", err)
+	}
+	if e, _ := c.Get(key("course", "c1")); e != nil {
+		t.Error("Expected course c1 to be cleared")
+	}
+	if e, _ := c.Get(key("courses")); e == nil {
+		t.Error("Expected the course list to be kept")
+	}
+	if e, _ := c.Get(key("students", "c2")); e == nil {
+		t.Error("Expected course c2 to be kept")
+	}
+
+	if err := RunCache(c, []string{"off"}, &out, &out); err != nil {
+		t.Fatalf("cache off failed: %v", err)
+	}
+	if e, _ := c.Get(key("courses")); e != nil {
+		t.Error("Expected a miss while caching is off")
+	}
+	if err := c.Set(key("course", "c3"), "data", time.Hour); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+
+	// The setting outlives the cache
+	reopened, err := NewCache(cfg)
+	if err != nil {
+		t.Fatalf("Failed to reopen cache: %v", err)
+	}
+	if reopened.Enabled() {
+		t.Fatal("Expected caching to stay off after reopening")
+	}
+	if err := RunCache(reopened, []string{"on"}, &out, &out); err != nil {
+		t.Fatalf("cache on failed: %v", err)
+	}
+	if e, _ := reopened.Get(key("courses")); e == nil {
+		t.Error("Expected entries to be kept while caching was off")
+	}
+	if e, _ := reopened.Get(key("course", "c3")); e != nil {
+		t.Error("Expected nothing to be stored while caching was off")
+	}
+
+	out.Reset()
+	if err := RunCache(reopened, []string{"stats"}, &out, &out); err != nil {
+		t.Fatalf("cache stats failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Cache:    enabled") || !strings.Contains(out.String(), "c2") {
+		t.Errorf("Unexpected stats output:\n%s", out.String())
+	}
+	if err := RunCache(reopened, []string{"purge"}, &out, &out); err == nil {
+		t.Error("Expected an error for an unknown command")
+	}
+}
+
+func TestCacheContents(t *testing.T) {
+	cfg := &Configuration{
+		Enabled:       true,
+		CoursesTTL:    5 * time.Minute,
+		CourseworkTTL: 1 * time.Hour,
+		Directory:     t.TempDir(),
+		Namespace:     "alex@example.com",
+	}
+	c, err := NewCache(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	other, err := NewCache(&Configuration{Enabled: true, Directory: cfg.Directory, Namespace: "sam@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	essay := &api.CourseWork{ID: "w1", CourseID: "c1", Title: "Essay"}
+	sets := map[string]any{
+		key("courses", "ACTIVE"):                           []*api.Course{{ID: "c1", Name: "Biology"}},
+		key("course", "c1"):                                &api.Course{ID: "c1", Name: "Biology"},
+		key("coursework", "c1", "list", "PUBLISHED"):       []*api.CourseWork{essay},
+		key("coursework", "c1", "list", "PUBLISHED,DRAFT"): []*api.CourseWork{essay, {ID: "w2", CourseID: "c1", Title: "Lab"}},
+		key("announcements", "c1"):                         []*api.Announcement{{ID: "a1", CourseID: "c1", Text: "Field trip"}},
+	}
+	for k, v := range sets {
+		if err := c.Set(k, v, time.Hour); err != nil {
+			t.Fatalf("Failed to set %s: %v", k, err)
+		}
+	}
+	// Expired entries still count
+	if err := c.Set(key("course", "c2"), &api.Course{ID: "c2", Name: "History"}, -time.Minute); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	if err := other.Set(key("course", "c3"), &api.Course{ID: "c3", Name: "Chemistry"}, time.Hour); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+
+	contents, err := c.Contents()
+	if err != nil {
+		t.Fatalf("Failed to read contents: %v", err)
+	}
+	var names []string
+	for _, course := range contents.Courses {
+		names = append(names, course.Name)
+	}
+	if strings.Join(names, ",") != "Biology,History" {
+		t.Errorf("Expected Biology and History once each, got %v", names)
+	}
+	if got := contents.CourseWork["c1"]; len(got) != 2 {
+		t.Errorf("Expected 2 coursework in c1, got %d", len(got))
+	}
+	if got := contents.Announcements["c1"]; len(got) != 1 || got[0].Text != "Field trip" {
+		t.Errorf("Unexpected announcements: %v", got)
+	}
 }
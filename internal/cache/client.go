@@ -0,0 +1,363 @@
+package cache
+
+import (
+	"context"
+	"strings"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// forceRefreshKey marks a context whose reads must skip the cache.
+type forceRefreshKey struct{}
+
+// WithForceRefresh returns a context that makes CachedClient reads go to
+// the network. The fresh results are still written back to the cache.
+func WithForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+// ForceRefresh reports whether ctx asks reads to bypass the cache.
+func ForceRefresh(ctx context.Context) bool {
+	force, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return force
+}
+
+// CachedClient wraps an api.ClassroomClient so reads are served from the
+// cache while fresh and written back after a network call. Each entity,
+// such as courses, submissions, or rosters, is kept for its own TTL. Writes
+// go straight to the API and drop the cached lists they affect. Methods
+// that are not overridden pass through to the embedded client.
+type CachedClient struct {
+	api.ClassroomClient
+	cache *Cache
+}
+
+// NewCachedClient wraps client with c. A nil cache disables caching.
+func NewCachedClient(client api.ClassroomClient, c *Cache) *CachedClient {
+	return &CachedClient{ClassroomClient: client, cache: c}
+}
+
+// Cache returns the underlying cache, or nil when caching is disabled.
+func (c *CachedClient) Cache() *Cache {
+	return c.cache
+}
+
+// cached returns the value stored under key, or calls fetch and stores its
+// result for the TTL of the key's entity. Concurrent misses for the same
+// key share one fetch. Cache failures are treated as misses; the API
+// result is returned either way. When the API cannot be reached, an
+// expired entry is returned instead of the error if the cache serves stale
+// entries.
+func cached[T any](ctx context.Context, c *CachedClient, key string, fetch func() (T, error)) (T, error) {
+	if c.cache == nil || key == "" {
+		return fetch()
+	}
+
+	if !ForceRefresh(ctx) {
+		if v, ok := GetValue[T](c.cache, key); ok {
+			return v, nil
+		}
+	}
+
+	v, err := shareFetch(ctx, c.cache, key, func() (T, error) {
+		v, err := fetch()
+		if err != nil {
+			return v, err
+		}
+		SetValue(c.cache, key, v, c.cache.TTL(entityOf(key)))
+		c.cache.stale.refreshed(key)
+		return v, nil
+	})
+	if err != nil {
+		if stale, ok := fallBackToStale[T](c.cache, key, err); ok {
+			return stale, nil
+		}
+	}
+	return v, err
+}
+
+// mutated invalidates the entries a successful write made stale.
+func (c *CachedClient) mutated(m Mutation) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.Invalidate(m)
+}
+
+// key joins the parts of a cache key. Each part ends with "." so that one
+// ID is never a prefix of another.
+func key(parts ...string) string {
+	return strings.Join(parts, ".") + "."
+}
+
+// keyEntities maps the first part of a key to the entity it caches.
+var keyEntities = map[string]Entity{
+	"courses":       EntityCourses,
+	"course":        EntityCourses,
+	"coursework":    EntityCoursework,
+	"announcements": EntityAnnouncements,
+	"students":      EntityRosters,
+	"teachers":      EntityRosters,
+	"submissions":   EntitySubmissions,
+	"user":          EntityUserProfiles,
+}
+
+// entityOf returns the entity cached under key, whose TTL it is stored for.
+func entityOf(key string) Entity {
+	kind, _, _ := strings.Cut(key, ".")
+	return keyEntities[kind]
+}
+
+// ListCourses returns courses from the cache or the API. Requests with a
+// custom field selection are not cached.
+func (c *CachedClient) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	k := ""
+	if opts == nil || len(opts.Fields) == 0 {
+		o := opts
+		if o == nil {
+			o = &api.ListCoursesOptions{}
+		}
+		k = key("courses", strings.Join(o.CourseStates, ","), "teacher="+o.TeacherID, "student="+o.StudentID)
+	}
+	return cached(ctx, c, k, func() ([]*api.Course, error) {
+		return c.ClassroomClient.ListCourses(ctx, opts)
+	})
+}
+
+// GetCourse returns a course from the cache or the API.
+func (c *CachedClient) GetCourse(ctx context.Context, courseID string) (*api.Course, error) {
+	return cached(ctx, c, key("course", courseID), func() (*api.Course, error) {
+		return c.ClassroomClient.GetCourse(ctx, courseID)
+	})
+}
+
+// ListCourseWork returns coursework from the cache or the API.
+func (c *CachedClient) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	k := ""
+	if opts == nil || len(opts.Fields) == 0 {
+		o := opts
+		if o == nil {
+			o = &api.ListCourseWorkOptions{}
+		}
+		k = key("coursework", courseID, "list", strings.Join(o.States, ","), o.OrderBy)
+	}
+	return cached(ctx, c, k, func() ([]*api.CourseWork, error) {
+		return c.ClassroomClient.ListCourseWork(ctx, courseID, opts)
+	})
+}
+
+// GetCourseWork returns coursework from the cache or the API.
+func (c *CachedClient) GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*api.CourseWork, error) {
+	return cached(ctx, c, key("coursework", courseID, "item", courseWorkID), func() (*api.CourseWork, error) {
+		return c.ClassroomClient.GetCourseWork(ctx, courseID, courseWorkID)
+	})
+}
+
+// GetRubric returns a coursework's rubric from the cache or the API.
+func (c *CachedClient) GetRubric(ctx context.Context, courseID, courseWorkID string) (*api.Rubric, error) {
+	return cached(ctx, c, key("coursework", courseID, "rubric", courseWorkID), func() (*api.Rubric, error) {
+		return c.ClassroomClient.GetRubric(ctx, courseID, courseWorkID)
+	})
+}
+
+// ListAddOnAttachments returns a coursework's add-on attachments from the
+// cache or the API.
+func (c *CachedClient) ListAddOnAttachments(ctx context.Context, courseID, courseWorkID string) ([]*api.AddOnAttachment, error) {
+	return cached(ctx, c, key("coursework", courseID, "addons", courseWorkID), func() ([]*api.AddOnAttachment, error) {
+		return c.ClassroomClient.ListAddOnAttachments(ctx, courseID, courseWorkID)
+	})
+}
+
+// ListTopics returns a course's topics from the cache or the API. They are
+// kept as long as its coursework.
+func (c *CachedClient) ListTopics(ctx context.Context, courseID string) ([]*api.Topic, error) {
+	return cached(ctx, c, key("coursework", courseID, "topics"), func() ([]*api.Topic, error) {
+		return c.ClassroomClient.ListTopics(ctx, courseID)
+	})
+}
+
+// ListStudentSubmissions returns submissions from the cache or the API.
+func (c *CachedClient) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	k := ""
+	if opts == nil || len(opts.Fields) == 0 {
+		o := opts
+		if o == nil {
+			o = &api.ListStudentSubmissionsOptions{}
+		}
+		k = key("submissions", courseID, courseWorkID, "user="+o.UserID, strings.Join(o.States, ","))
+	}
+	return cached(ctx, c, k, func() ([]*api.StudentSubmission, error) {
+		return c.ClassroomClient.ListStudentSubmissions(ctx, courseID, courseWorkID, opts)
+	})
+}
+
+// ListAnnouncements returns announcements from the cache or the API.
+func (c *CachedClient) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	k := ""
+	if opts == nil || len(opts.Fields) == 0 {
+		k = key("announcements", courseID)
+		if opts != nil && len(opts.States) > 0 {
+			k = key("announcements", courseID, strings.Join(opts.States, ","))
+		}
+	}
+	return cached(ctx, c, k, func() ([]*api.Announcement, error) {
+		return c.ClassroomClient.ListAnnouncements(ctx, courseID, opts)
+	})
+}
+
+// ListStudents returns a course's students from the cache or the API.
+func (c *CachedClient) ListStudents(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Student, error) {
+	k := ""
+	if opts == nil || len(opts.Fields) == 0 {
+		k = key("students", courseID)
+	}
+	return cached(ctx, c, k, func() ([]*api.Student, error) {
+		return c.ClassroomClient.ListStudents(ctx, courseID, opts)
+	})
+}
+
+// ListTeachers returns a course's teachers from the cache or the API.
+func (c *CachedClient) ListTeachers(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Teacher, error) {
+	k := ""
+	if opts == nil || len(opts.Fields) == 0 {
+		k = key("teachers", courseID)
+	}
+	return cached(ctx, c, k, func() ([]*api.Teacher, error) {
+		return c.ClassroomClient.ListTeachers(ctx, courseID, opts)
+	})
+}
+
+// GetUserProfile returns a user's profile from the cache or the API.
+func (c *CachedClient) GetUserProfile(ctx context.Context, userID string) (*api.UserProfile, error) {
+	return cached(ctx, c, key("user", userID), func() (*api.UserProfile, error) {
+		return c.ClassroomClient.GetUserProfile(ctx, userID)
+	})
+}
+
+// CreateCourse creates a course and drops the cached course lists.
+func (c *CachedClient) CreateCourse(ctx context.Context, name, section, room string) (*api.Course, error) {
+	course, err := c.ClassroomClient.CreateCourse(ctx, name, section, room)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationCreateCourse})
+	}
+	return course, err
+}
+
+// PatchCourse updates a course and drops its cached copies.
+func (c *CachedClient) PatchCourse(ctx context.Context, courseID string, patch api.CoursePatch) (*api.Course, error) {
+	course, err := c.ClassroomClient.PatchCourse(ctx, courseID, patch)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationPatchCourse, CourseID: courseID})
+	}
+	return course, err
+}
+
+// UpdateCourseState archives or restores a course and drops its cached copies.
+func (c *CachedClient) UpdateCourseState(ctx context.Context, courseID, state string) (*api.Course, error) {
+	course, err := c.ClassroomClient.UpdateCourseState(ctx, courseID, state)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationUpdateCourseState, CourseID: courseID})
+	}
+	return course, err
+}
+
+// AcceptInvitation joins a course and drops the cached course lists.
+func (c *CachedClient) AcceptInvitation(ctx context.Context, invitationID string) error {
+	err := c.ClassroomClient.AcceptInvitation(ctx, invitationID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationAcceptInvitation})
+	}
+	return err
+}
+
+// CreateCourseWork creates coursework and drops the cached coursework
+// lists.
+func (c *CachedClient) CreateCourseWork(ctx context.Context, courseID string, cw *api.CourseWork) (*api.CourseWork, error) {
+	created, err := c.ClassroomClient.CreateCourseWork(ctx, courseID, cw)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationCreateCourseWork, CourseID: courseID})
+	}
+	return created, err
+}
+
+// DeleteCourseWork deletes coursework and drops its cached copies and
+// submissions.
+func (c *CachedClient) DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error {
+	err := c.ClassroomClient.DeleteCourseWork(ctx, courseID, courseWorkID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationDeleteCourseWork, CourseID: courseID, CourseWorkID: courseWorkID})
+	}
+	return err
+}
+
+// TurnIn turns in a submission and drops the cached submissions.
+func (c *CachedClient) TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	err := c.ClassroomClient.TurnIn(ctx, courseID, courseWorkID, submissionID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationTurnIn, CourseID: courseID, CourseWorkID: courseWorkID})
+	}
+	return err
+}
+
+// ModifyAttachments attaches files and drops the cached submissions.
+func (c *CachedClient) ModifyAttachments(ctx context.Context, courseID, courseWorkID, submissionID string, driveFileIDs []string) (*api.StudentSubmission, error) {
+	sub, err := c.ClassroomClient.ModifyAttachments(ctx, courseID, courseWorkID, submissionID, driveFileIDs)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationModifyAttachments, CourseID: courseID, CourseWorkID: courseWorkID})
+	}
+	return sub, err
+}
+
+// SetDraftGrade sets a draft grade and drops the cached submissions.
+func (c *CachedClient) SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	sub, err := c.ClassroomClient.SetDraftGrade(ctx, courseID, courseWorkID, submissionID, grade)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationDraftGrade, CourseID: courseID, CourseWorkID: courseWorkID})
+	}
+	return sub, err
+}
+
+// SetAssignedGrade sets a grade and drops the cached submissions.
+func (c *CachedClient) SetAssignedGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	sub, err := c.ClassroomClient.SetAssignedGrade(ctx, courseID, courseWorkID, submissionID, grade)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationAssignedGrade, CourseID: courseID, CourseWorkID: courseWorkID})
+	}
+	return sub, err
+}
+
+// ReturnSubmission returns a submission and drops the cached submissions.
+func (c *CachedClient) ReturnSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	err := c.ClassroomClient.ReturnSubmission(ctx, courseID, courseWorkID, submissionID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationReturnSubmission, CourseID: courseID, CourseWorkID: courseWorkID})
+	}
+	return err
+}
+
+// DeleteAnnouncement deletes an announcement and drops the cached list.
+func (c *CachedClient) DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error {
+	err := c.ClassroomClient.DeleteAnnouncement(ctx, courseID, announcementID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationDeleteAnnouncement, CourseID: courseID})
+	}
+	return err
+}
+
+// RemoveStudent removes a student and drops the cached roster.
+func (c *CachedClient) RemoveStudent(ctx context.Context, courseID, userID string) error {
+	err := c.ClassroomClient.RemoveStudent(ctx, courseID, userID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationRemoveStudent, CourseID: courseID})
+	}
+	return err
+}
+
+// RemoveTeacher removes a teacher and drops the cached roster.
+func (c *CachedClient) RemoveTeacher(ctx context.Context, courseID, userID string) error {
+	err := c.ClassroomClient.RemoveTeacher(ctx, courseID, userID)
+	if err == nil {
+		c.mutated(Mutation{Kind: MutationRemoveTeacher, CourseID: courseID})
+	}
+	return err
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"golang.org/x/oauth2"
+)
+
+// newTestClient returns an API client whose requests are answered locally
+// with a fixed course list, and a counter of requests sent.
+func newTestClient(t *testing.T) (*api.Client, *int) {
+	t.Helper()
+	calls := 0
+	transport := api.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		body := `{}`
+		if strings.HasSuffix(req.URL.Path, "/courses") {
+			body = `{"courses":[{"id":"c1","name":"Biology"}]}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+
+	cfg := api.DefaultConfiguration()
+	cfg.Transport = transport
+	cfg.DisableETags = true
+	client, err := api.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test"}), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client, &calls
+}
+
+// TestCachedClient tests cache hits, forced refreshes, and invalidation.
+func TestCachedClient(t *testing.T) {
+	c, err := NewCache(&Configuration{CoursesTTL: time.Minute, CourseworkTTL: time.Minute, Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	client, calls := newTestClient(t)
+	cc := NewCachedClient(client, c)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		courses, err := cc.ListCourses(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListCourses failed: %v", err)
+		}
+		if len(courses) != 1 || courses[0].Name != "Biology" {
+			t.Errorf("Expected the Biology course, got %+v", courses)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("Expected second read to hit the cache, got %d requests", *calls)
+	}
+
+	if _, err := cc.ListCourses(WithForceRefresh(ctx), nil); err != nil {
+		t.Fatalf("ListCourses failed: %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("Expected forced refresh to reach the API, got %d requests", *calls)
+	}
+
+	if _, err := cc.CreateCourse(ctx, "Chemistry", "", ""); err != nil {
+		t.Fatalf("CreateCourse failed: %v", err)
+	}
+	if _, err := cc.ListCourses(ctx, nil); err != nil {
+		t.Fatalf("ListCourses failed: %v", err)
+	}
+	if *calls != 4 {
+		t.Errorf("Expected a write to invalidate the course list, got %d requests", *calls)
+	}
+}
+
+// TestCachedClientEntityTTLs tests that each entity is stored for its own
+// TTL, and that unset TTLs fall back to the courses or coursework TTL.
+func TestCachedClientEntityTTLs(t *testing.T) {
+	c, err := NewCache(&Configuration{
+		CoursesTTL:     time.Minute,
+		CourseworkTTL:  time.Hour,
+		SubmissionsTTL: 2 * time.Minute,
+		Directory:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	client, _ := newTestClient(t)
+	cc := NewCachedClient(client, c)
+	ctx := context.Background()
+
+	if _, err := cc.ListStudentSubmissions(ctx, "c1", "w1", nil); err != nil {
+		t.Fatalf("ListStudentSubmissions failed: %v", err)
+	}
+	if _, err := cc.ListStudents(ctx, "c1", nil); err != nil {
+		t.Fatalf("ListStudents failed: %v", err)
+	}
+	if _, err := cc.GetUserProfile(ctx, "me"); err != nil {
+		t.Fatalf("GetUserProfile failed: %v", err)
+	}
+
+	for k, want := range map[string]time.Duration{
+		key("submissions", "c1", "w1", "user=", ""): 2 * time.Minute,
+		key("students", "c1"):                       time.Hour,
+		key("user", "me"):                           time.Minute,
+	} {
+		e, _ := c.Get(k)
+		if e == nil {
+			t.Errorf("Expected %s to be cached", k)
+			continue
+		}
+		if ttl := e.ExpiresAt.Sub(e.CachedAt); ttl != want {
+			t.Errorf("Expected %s to be kept for %v, got %v", k, want, ttl)
+		}
+	}
+}
+
+// TestCachedClientDisabled tests that a nil cache passes reads through.
+func TestCachedClientDisabled(t *testing.T) {
+	client, calls := newTestClient(t)
+	cc := NewCachedClient(client, nil)
+	for i := 0; i < 2; i++ {
+		if _, err := cc.ListCourses(context.Background(), nil); err != nil {
+			t.Fatalf("ListCourses failed: %v", err)
+		}
+	}
+	if *calls != 2 {
+		t.Errorf("Expected every read to reach the API, got %d requests", *calls)
+	}
+}
+
+// TestDeletePrefix tests removing entries by key prefix.
+func TestDeletePrefix(t *testing.T) {
+	c, err := NewCache(&Configuration{Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	for _, k := range []string{"coursework.1.list.", "coursework.1.item.9.", "coursework.12.list."} {
+		c.Set(k, "x", time.Minute)
+	}
+
+	if err := c.DeletePrefix("coursework.1."); err != nil {
+		t.Fatalf("DeletePrefix failed: %v", err)
+	}
+	if e, _ := c.Get("coursework.1.list."); e != nil {
+		t.Error("Expected coursework.1 entries to be removed")
+	}
+	if e, _ := c.Get("coursework.12.list."); e == nil {
+		t.Error("Expected coursework.12 entries to be kept")
+	}
+}
+
+// TestInvalidate tests that writes drop the entries they make stale and
+// tell the invalidation hooks.
+func TestInvalidate(t *testing.T) {
+	c, err := NewCache(&Configuration{Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	for _, k := range []string{key("submissions", "c1", "w1"), key("submissions", "c1", "w2"), key("coursework", "c1", "list")} {
+		c.Set(k, "x", time.Minute)
+	}
+	var seen []Mutation
+	c.OnInvalidate(func(m Mutation) { seen = append(seen, m) })
+
+	client, _ := newTestClient(t)
+	cc := NewCachedClient(client, c)
+	if err := cc.TurnIn(context.Background(), "c1", "w1", "s1"); err != nil {
+		t.Fatalf("TurnIn failed: %v", err)
+	}
+	if e, _ := c.Get(key("submissions", "c1", "w1")); e != nil {
+		t.Error("Expected the turned-in coursework's submissions to be dropped")
+	}
+	if e, _ := c.Get(key("submissions", "c1", "w2")); e == nil {
+		t.Error("Expected other coursework's submissions to be kept")
+	}
+	if len(seen) != 1 || seen[0] != (Mutation{Kind: MutationTurnIn, CourseID: "c1", CourseWorkID: "w1"}) {
+		t.Errorf("Expected the hook to see the turn-in, got %+v", seen)
+	}
+
+	// Writes made elsewhere are invalidated directly
+	if err := c.Invalidate(Mutation{Kind: MutationCreateCourseWork, CourseID: "c1"}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if e, _ := c.Get(key("coursework", "c1", "list")); e != nil {
+		t.Error("Expected the coursework list to be dropped")
+	}
+	if len(Mutation{Kind: "unknown"}.Stale()) != 0 {
+		t.Error("Expected an unknown mutation to make nothing stale")
+	}
+}
+
+// slowCourses answers ListCourses after a delay, counting the calls.
+type slowCourses struct {
+	api.ClassroomClient
+	calls atomic.Int32
+}
+
+func (s *slowCourses) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	s.calls.Add(1)
+	time.Sleep(100 * time.Millisecond)
+	return []*api.Course{{ID: "c1", Name: "Biology"}}, nil
+}
+
+// TestCachedClientSharesFetches tests that concurrent misses for the same
+// key make one API call and each get their own copy of the result.
+func TestCachedClientSharesFetches(t *testing.T) {
+	c, err := NewCache(&Configuration{Directory: t.TempDir(), CoursesTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	slow := &slowCourses{}
+
+	var wg sync.WaitGroup
+	results := make([][]*api.Course, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A client per caller, as each screen makes its own
+			results[i], _ = NewCachedClient(slow, c).ListCourses(context.Background(), nil)
+		}()
+	}
+	wg.Wait()
+
+	if n := slow.calls.Load(); n != 1 {
+		t.Errorf("Expected one API call, got %d", n)
+	}
+	for i, courses := range results {
+		if len(courses) != 1 || courses[0].Name != "Biology" {
+			t.Fatalf("Expected the course list for caller %d, got %v", i, courses)
+		}
+	}
+	results[0][0].Name = "Changed"
+	for _, courses := range results[1:] {
+		if courses[0].Name != "Biology" {
+			t.Error("Expected callers to get separate copies")
+		}
+	}
+}
+
+// flakyCourses answers ListCourses with err when it is set.
+type flakyCourses struct {
+	api.ClassroomClient
+	err error
+}
+
+func (f *flakyCourses) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*api.Course{{ID: "c1", Name: "Biology"}}, nil
+}
+
+// TestCachedClientServesStale tests that expired entries answer reads only
+// while the API cannot be reached, and only when ServeStale is set.
+func TestCachedClientServesStale(t *testing.T) {
+	c, err := NewCache(&Configuration{Directory: t.TempDir(), CoursesTTL: time.Millisecond, ServeStale: true})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	flaky := &flakyCourses{}
+	cc := NewCachedClient(flaky, c)
+	ctx := context.Background()
+
+	if _, err := cc.ListCourses(ctx, nil); err != nil {
+		t.Fatalf("ListCourses failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	flaky.err = errors.New("permission denied")
+	if _, err := cc.ListCourses(ctx, nil); err == nil {
+		t.Error("Expected an API error to be returned, not the expired entry")
+	}
+
+	flaky.err = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	courses, err := cc.ListCourses(ctx, nil)
+	if err != nil {
+		t.Fatalf("Expected the expired entry while offline, got %v", err)
+	}
+	if len(courses) != 1 || courses[0].Name != "Biology" {
+		t.Errorf("Expected the cached course list, got %v", courses)
+	}
+	if c.StaleSince().IsZero() {
+		t.Error("Expected StaleSince to report the expired entry")
+	}
+
+	flaky.err = nil
+	if _, err := cc.ListCourses(ctx, nil); err != nil {
+		t.Fatalf("ListCourses failed: %v", err)
+	}
+	if !c.StaleSince().IsZero() {
+		t.Error("Expected a fresh read to clear StaleSince")
+	}
+
+	strict, err := NewCache(&Configuration{Directory: t.TempDir(), CoursesTTL: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	flaky.err = nil
+	cc = NewCachedClient(flaky, strict)
+	cc.ListCourses(ctx, nil)
+	time.Sleep(5 * time.Millisecond)
+	flaky.err = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	if _, err := cc.ListCourses(ctx, nil); err == nil {
+		t.Error("Expected the network error without ServeStale")
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Codecs accepted by Configuration.Compression. Only gzip, from the
+// standard library, is available; entries record their codec, so others
+// can be added without breaking existing ones.
+const (
+	CodecNone = ""
+	CodecGzip = "gzip"
+)
+
+// compressMin is the smallest payload worth compressing. Below it the
+// gzip header and base64 encoding cost more than they save.
+const compressMin = 512
+
+// errUnknownCodec means an entry was compressed with a codec this version
+// cannot read, e.g. by a newer release.
+var errUnknownCodec = errors.New("unknown cache codec")
+
+// ValidCodec reports whether codec can be used for Compression.
+func ValidCodec(codec string) bool {
+	return codec == CodecNone || codec == "none" || codec == CodecGzip
+}
+
+// compress returns data compressed with codec, or nil when codec is off,
+// data is too small, or compressing does not make it smaller.
+func compress(codec string, data []byte) ([]byte, error) {
+	if codec != CodecGzip || len(data) < compressMin {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress cache entry: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress cache entry: %w", err)
+	}
+	if buf.Len() >= len(data) {
+		return nil, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress reverses compress for an entry written with codec.
+func decompress(codec string, data []byte) ([]byte, error) {
+	if codec != CodecGzip {
+		return nil, fmt.Errorf("%w %q", errUnknownCodec, codec)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress cache entry: %w", err)
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress cache entry: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// Contents is what the cache holds about courses, gathered from every
+// cached list and item without asking the API.
+type Contents struct {
+	Courses []*api.Course
+	// CourseWork and Announcements are keyed by course ID.
+	CourseWork    map[string][]*api.CourseWork
+	Announcements map[string][]*api.Announcement
+}
+
+// Contents reads the courses, coursework, and announcements in the
+// cache's own namespace. Expired entries are included: they are still the
+// latest copy there is. Each item appears once, however many of the
+// cached lists hold it.
+func (c *Cache) Contents() (*Contents, error) {
+	var keys []string
+	err := c.backend.Walk(func(stored StoredEntry) error {
+		k := stored.Key
+		if k == "" {
+			data, err := c.sealer.Open(stored.Data)
+			if err != nil {
+				return nil
+			}
+			var entry CacheEntry
+			if _, err := entrySchema.Decode(data, &entry); err != nil {
+				return nil
+			}
+			k = entry.Key
+		}
+		if own, ok := c.ownKey(k); ok {
+			keys = append(keys, own)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Walk order is arbitrary; sorting keeps the result stable
+	slices.Sort(keys)
+
+	contents := &Contents{
+		CourseWork:    make(map[string][]*api.CourseWork),
+		Announcements: make(map[string][]*api.Announcement),
+	}
+	seen := make(map[string]bool)
+	first := func(kind, id string) bool {
+		k := kind + "." + id
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+		return true
+	}
+
+	for _, k := range keys {
+		kind, rest, _ := strings.Cut(k, ".")
+		parts := strings.Split(rest, ".")
+		switch {
+		case kind == "courses":
+			courses, _ := readValue[[]*api.Course](c, k)
+			for _, course := range courses {
+				if first("course", course.ID) {
+					contents.Courses = append(contents.Courses, course)
+				}
+			}
+		case kind == "course":
+			if course, ok := readValue[*api.Course](c, k); ok && course != nil && first("course", course.ID) {
+				contents.Courses = append(contents.Courses, course)
+			}
+		case kind == "coursework" && len(parts) > 1 && parts[1] == "list":
+			coursework, _ := readValue[[]*api.CourseWork](c, k)
+			for _, cw := range coursework {
+				if first("coursework", cw.ID) {
+					contents.CourseWork[parts[0]] = append(contents.CourseWork[parts[0]], cw)
+				}
+			}
+		case kind == "coursework" && len(parts) > 1 && parts[1] == "item":
+			if cw, ok := readValue[*api.CourseWork](c, k); ok && cw != nil && first("coursework", cw.ID) {
+				contents.CourseWork[parts[0]] = append(contents.CourseWork[parts[0]], cw)
+			}
+		case kind == "announcements":
+			announcements, _ := readValue[[]*api.Announcement](c, k)
+			for _, a := range announcements {
+				if first("announcement", a.ID) {
+					contents.Announcements[parts[0]] = append(contents.Announcements[parts[0]], a)
+				}
+			}
+		}
+	}
+	return contents, nil
+}
+
+// readValue returns the value under key decoded as T, expired or not.
+func readValue[T any](c *Cache, key string) (T, bool) {
+	var v T
+	entry, err := c.read(key)
+	if err != nil || entry == nil {
+		return v, false
+	}
+	if err := json.Unmarshal(entry.Data, &v); err != nil {
+		return v, false
+	}
+	return v, true
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// flightGroup lets concurrent fetches of the same key share one network
+// call, e.g. a prefetch of a course and the user opening it. It belongs to
+// the Cache, so it spans every CachedClient wrapping it.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a fetch in progress. data is the result as JSON, so every
+// waiter decodes its own copy.
+type flight struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// do calls fn for key unless a call for key is already running, in which
+// case it waits for that one and reports shared. A waiter whose ctx ends
+// stops waiting; the call goes on for the others.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) (data []byte, shared bool, err error) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.data, true, f.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.data, f.err = fn()
+	return f.data, false, f.err
+}
+
+// shareFetch runs fetch through the cache's flight group. A caller that
+// joined another's call decodes its own copy of the result, so callers can
+// sort or edit what they get without affecting each other. It fetches
+// again itself if the call was cancelled by the caller that started it.
+func shareFetch[T any](ctx context.Context, c *Cache, key string, fetch func() (T, error)) (T, error) {
+	var own T
+	data, shared, err := c.flights.do(ctx, key, func() ([]byte, error) {
+		v, err := fetch()
+		own = v
+		if err != nil {
+			return nil, err
+		}
+		// Waiters fetch again themselves if the result cannot be encoded
+		data, _ := json.Marshal(v)
+		return data, nil
+	})
+	if !shared {
+		return own, err
+	}
+
+	var v T
+	if isCancelled(err) && ctx.Err() == nil {
+		return fetch()
+	}
+	if err != nil {
+		return v, err
+	}
+	if data == nil || json.Unmarshal(data, &v) != nil {
+		return fetch()
+	}
+	return v, nil
+}
+
+// isCancelled reports whether err came from a cancelled or expired
+// context.
+func isCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
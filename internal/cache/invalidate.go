@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"slices"
+	"sync"
+)
+
+// MutationKind is the type of a write to Classroom.
+type MutationKind string
+
+// Writes that make cached reads stale.
+const (
+	MutationCreateCourse       MutationKind = "create_course"
+	MutationPatchCourse        MutationKind = "patch_course"
+	MutationUpdateCourseState  MutationKind = "update_course_state"
+	MutationAcceptInvitation   MutationKind = "accept_invitation"
+	MutationCreateCourseWork   MutationKind = "create_coursework"
+	MutationDeleteCourseWork   MutationKind = "delete_coursework"
+	MutationTurnIn             MutationKind = "turn_in"
+	MutationModifyAttachments  MutationKind = "modify_attachments"
+	MutationDraftGrade         MutationKind = "draft_grade"
+	MutationAssignedGrade      MutationKind = "assigned_grade"
+	MutationReturnSubmission   MutationKind = "return_submission"
+	MutationDeleteAnnouncement MutationKind = "delete_announcement"
+	MutationRemoveStudent      MutationKind = "remove_student"
+	MutationRemoveTeacher      MutationKind = "remove_teacher"
+)
+
+// Mutation describes a write that succeeded. CourseID and CourseWorkID
+// say what it touched, when the kind concerns a course or coursework.
+type Mutation struct {
+	Kind         MutationKind
+	CourseID     string
+	CourseWorkID string
+}
+
+// Stale returns the key prefixes of the entries the mutation makes stale:
+// a new course changes the course lists, a turned-in or graded submission
+// that coursework's submissions, and so on.
+func (m Mutation) Stale() []string {
+	switch m.Kind {
+	case MutationCreateCourse, MutationAcceptInvitation:
+		return []string{key("courses")}
+	case MutationPatchCourse, MutationUpdateCourseState:
+		return []string{key("courses"), key("course", m.CourseID)}
+	case MutationCreateCourseWork:
+		return []string{key("coursework", m.CourseID, "list")}
+	case MutationDeleteCourseWork:
+		return []string{key("coursework", m.CourseID), key("submissions", m.CourseID, m.CourseWorkID)}
+	case MutationTurnIn, MutationModifyAttachments, MutationDraftGrade, MutationAssignedGrade, MutationReturnSubmission:
+		return []string{key("submissions", m.CourseID, m.CourseWorkID)}
+	case MutationDeleteAnnouncement:
+		return []string{key("announcements", m.CourseID)}
+	case MutationRemoveStudent:
+		return []string{key("students", m.CourseID)}
+	case MutationRemoveTeacher:
+		return []string{key("teachers", m.CourseID)}
+	}
+	return nil
+}
+
+// invalidation holds the hooks told about invalidated mutations.
+type invalidation struct {
+	mu    sync.Mutex
+	hooks []func(Mutation)
+}
+
+// Invalidate drops the entries a write made stale, then calls the hooks
+// registered with OnInvalidate. CachedClient calls it after every write it
+// makes; call it for writes made another way, such as by a plain
+// api.Client replaying the offline outbox.
+func (c *Cache) Invalidate(m Mutation) error {
+	var firstErr error
+	for _, prefix := range m.Stale() {
+		if err := c.DeletePrefix(prefix); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	c.invalidation.mu.Lock()
+	hooks := slices.Clone(c.invalidation.hooks)
+	c.invalidation.mu.Unlock()
+	for _, hook := range hooks {
+		hook(m)
+	}
+	return firstErr
+}
+
+// OnInvalidate registers fn to be called after each Invalidate, e.g. to
+// mark other copies of the data stale or reload what is on screen. fn runs
+// on the goroutine that made the write.
+func (c *Cache) OnInvalidate(fn func(Mutation)) {
+	c.invalidation.mu.Lock()
+	defer c.invalidation.mu.Unlock()
+	c.invalidation.hooks = append(c.invalidation.hooks, fn)
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/user/google-classroom/internal/humanize"
+)
+
+// disabledFile marks a cache directory whose cache is turned off, so the
+// setting outlives the process that changed it.
+const disabledFile = "disabled"
+
+// disabledMarked reports whether caching was turned off in directory.
+func disabledMarked(directory string) bool {
+	if directory == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(directory, disabledFile))
+	return err == nil
+}
+
+// Enabled reports whether the cache serves and stores entries.
+func (c *Cache) Enabled() bool {
+	return !c.disabled.Load()
+}
+
+// SetEnabled turns caching on or off. While off, Get misses and Set stores
+// nothing, but entries are kept and can still be cleared. A cache opened
+// by NewCache remembers the setting in its directory.
+func (c *Cache) SetEnabled(enabled bool) error {
+	if c.directory != "" {
+		marker := filepath.Join(c.directory, disabledFile)
+		if enabled {
+			if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to enable cache: %w", err)
+			}
+		} else if err := os.WriteFile(marker, nil, 0600); err != nil {
+			return fmt.Errorf("failed to disable cache: %w", err)
+		}
+	}
+	c.disabled.Store(!enabled)
+	return nil
+}
+
+// courseKinds are the key kinds whose second part is a course ID.
+var courseKinds = []string{"course", "coursework", "submissions", "announcements", "students", "teachers"}
+
+// ownKey returns a stored key without the cache's namespace, and false
+// when it is in another namespace.
+func (c *Cache) ownKey(stored string) (string, bool) {
+	if c.namespace == "" {
+		return stored, splitNamespace(stored) == ""
+	}
+	return strings.CutPrefix(stored, c.namespace+namespaceSep)
+}
+
+// courseOf returns the course a stored key belongs to, or "" when it is in
+// another namespace or not about one course.
+func (c *Cache) courseOf(stored string) string {
+	k, ok := c.ownKey(stored)
+	if !ok {
+		return ""
+	}
+	kind, rest, ok := strings.Cut(k, ".")
+	if !ok || !slices.Contains(courseKinds, kind) {
+		return ""
+	}
+	courseID, _, _ := strings.Cut(rest, ".")
+	return courseID
+}
+
+// ClearCourse removes the cache's entries about one course. The course
+// list, which may include it, is kept.
+func (c *Cache) ClearCourse(courseID string) error {
+	if courseID == "" {
+		return errors.New("no course to clear")
+	}
+	for _, kind := range courseKinds {
+		if err := c.DeletePrefix(key(kind, courseID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunCache implements `classroom cache stats|clear [--course=ID]|on|off`.
+func RunCache(c *Cache, args []string, stdout, stderr io.Writer) error {
+	const usage = "usage: cache stats|clear [--course=ID]|on|off"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "stats":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		stats, err := c.GetStats()
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+		writeStats(stdout, c, stats)
+
+	case "clear":
+		fs := flag.NewFlagSet("cache clear", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		course := fs.String("course", "", "clear only this course's entries")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return errors.New(usage)
+		}
+		if *course != "" {
+			if err := c.ClearCourse(*course); err != nil {
+				return fmt.Errorf("failed to clear course %s: %w", *course, err)
+			}
+			fmt.Fprintf(stdout, "Cleared cached data for course %s.\n", *course)
+			return nil
+		}
+		if err := c.Clear(); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		fmt.Fprintln(stdout, "Cleared the cache.")
+
+	case "on", "off":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		if err := c.SetEnabled(args[0] == "on"); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Caching %s.\n", enabledText(c))
+
+	default:
+		return fmt.Errorf("unknown command %q; %s", args[0], usage)
+	}
+	return nil
+}
+
+// writeStats prints stats with one line per course, largest first.
+func writeStats(out io.Writer, c *Cache, stats *CacheStats) {
+	fmt.Fprintf(out, "Cache:    %s, %s\n", enabledText(c), c.Backend())
+	fmt.Fprintf(out, "Entries:  %d (%d expired), %s\n", stats.TotalEntries, stats.ExpiredEntries, humanize.Bytes(stats.TotalSize))
+	if len(stats.Courses) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "Courses:")
+	for _, id := range CoursesBySize(stats) {
+		course := stats.Courses[id]
+		fmt.Fprintf(out, "  %-16s %d entries (%d expired), %s\n", id, course.Entries, course.ExpiredEntries, humanize.Bytes(course.Size))
+	}
+}
+
+// CoursesBySize returns the course IDs in stats, largest first.
+func CoursesBySize(stats *CacheStats) []string {
+	return slices.SortedFunc(maps.Keys(stats.Courses), func(a, b string) int {
+		if d := cmp.Compare(stats.Courses[b].Size, stats.Courses[a].Size); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// enabledText returns "enabled" or "disabled".
+func enabledText(c *Cache) string {
+	if c.Enabled() {
+		return "enabled"
+	}
+	return "disabled"
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// namespaceSep ends the namespace at the start of a namespaced key.
+const namespaceSep = "|"
+
+// namespacesDir is the subdirectory of the cache directory holding one
+// directory of entries per namespace.
+const namespacesDir = "accounts"
+
+// namespaceName restricts namespaces to account email addresses and
+// profile names, which are safe as directory names and never contain
+// namespaceSep.
+var namespaceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9@._+-]*$`)
+
+// validNamespace reports an error for a namespace that cannot be used. ""
+// is no namespace.
+func validNamespace(namespace string) error {
+	if namespace != "" && !namespaceName.MatchString(namespace) {
+		return fmt.Errorf("invalid cache namespace %q: use an email address or profile name", namespace)
+	}
+	return nil
+}
+
+// namespacedKey returns key in namespace. Keys without a namespace are
+// unchanged, so entries cached before namespaces existed stay readable.
+func namespacedKey(namespace, key string) string {
+	if namespace == "" {
+		return key
+	}
+	return namespace + namespaceSep + key
+}
+
+// splitNamespace returns the namespace of a stored key, or "" when it has
+// none.
+func splitNamespace(key string) string {
+	namespace, _, ok := strings.Cut(key, namespaceSep)
+	if !ok || validNamespace(namespace) != nil {
+		return ""
+	}
+	return namespace
+}
+
+// Namespace returns the namespace the cache keeps its entries in, or ""
+// when it has none.
+func (c *Cache) Namespace() string {
+	return c.namespace
+}
+
+// ClearNamespace removes every entry in namespace, e.g. when an account is
+// logged out, leaving other accounts' entries. Pass Namespace() to clear
+// the cache's own.
+func (c *Cache) ClearNamespace(namespace string) error {
+	if namespace == "" {
+		return errors.New("no cache namespace to clear")
+	}
+	if err := validNamespace(namespace); err != nil {
+		return err
+	}
+	return c.backend.DeletePrefix(namespace + namespaceSep)
+}
+
+// namespaceDirectory returns the directory a file backend rooted at
+// directory keeps namespace's entries in.
+func namespaceDirectory(directory, namespace string) string {
+	if namespace == "" {
+		return directory
+	}
+	return filepath.Join(directory, namespacesDir, namespace)
+}
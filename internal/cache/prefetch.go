@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// ErrPrefetchPaused means a prefetch stopped because PrefetchOptions.Paused
+// asked it to.
+var ErrPrefetchPaused = errors.New("prefetch paused")
+
+// PrefetchOptions controls Prefetch.
+type PrefetchOptions struct {
+	// Concurrency bounds how many courses are fetched at once. Zero uses 4.
+	Concurrency int
+	// IncludeDeleted also fetches deleted coursework, matching screens
+	// that show it as tombstones.
+	IncludeDeleted bool
+	// Refresh refetches entries that are still fresh.
+	Refresh bool
+	// Paused, when set, is checked before each course; the prefetch stops
+	// with ErrPrefetchPaused while it returns true, e.g. while conserving
+	// the daily API budget.
+	Paused func() bool
+}
+
+// PrefetchResult counts what a prefetch cached.
+type PrefetchResult struct {
+	Courses       int
+	CourseWork    int
+	Announcements int
+	Submissions   int
+}
+
+// Prefetch fills the cache for every active course: its coursework,
+// announcements, and, in courses the user takes, their own submissions.
+// It asks for them the way the screens do, so opening a course afterwards
+// is answered from the cache.
+func Prefetch(ctx context.Context, c *CachedClient, opts *PrefetchOptions) (*PrefetchResult, error) {
+	if opts == nil {
+		opts = &PrefetchOptions{}
+	}
+	if c.cache == nil {
+		return nil, errors.New("caching is not configured")
+	}
+	if !c.cache.Enabled() {
+		return nil, errors.New("caching is turned off")
+	}
+	if opts.Refresh {
+		ctx = WithForceRefresh(ctx)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	courses, err := c.ListCourses(ctx, &api.ListCoursesOptions{
+		CourseStates: []string{api.CourseStateActive, api.CourseStateProvisioned},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var courseIDs []string
+	for _, course := range courses {
+		if course.CourseState == api.CourseStateActive {
+			courseIDs = append(courseIDs, course.ID)
+		}
+	}
+
+	counts, err := parallel.Map(ctx, concurrency, courseIDs, func(ctx context.Context, courseID string) (PrefetchResult, error) {
+		if opts.Paused != nil && opts.Paused() {
+			return PrefetchResult{}, ErrPrefetchPaused
+		}
+		return prefetchCourse(ctx, c, courseID, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PrefetchResult{Courses: len(courseIDs)}
+	for _, n := range counts {
+		result.CourseWork += n.CourseWork
+		result.Announcements += n.Announcements
+		result.Submissions += n.Submissions
+	}
+	return result, nil
+}
+
+// prefetchCourse caches one course's coursework, announcements, and the
+// user's submissions.
+func prefetchCourse(ctx context.Context, c *CachedClient, courseID string, opts *PrefetchOptions) (PrefetchResult, error) {
+	var n PrefetchResult
+	isTeacher, err := c.IsTeacher(ctx, courseID)
+	if err != nil {
+		return n, err
+	}
+
+	// The coursework list and course screens sort by due date
+	states := []string{api.CourseWorkStatePublished}
+	if isTeacher {
+		states = append(states, api.CourseWorkStateDraft)
+	}
+	if opts.IncludeDeleted {
+		states = append(states, api.CourseWorkStateDeleted)
+	}
+	coursework, err := c.ListCourseWork(ctx, courseID, &api.ListCourseWorkOptions{
+		States:  states,
+		OrderBy: api.CourseWorkOrderDueDateAsc,
+	})
+	if err != nil {
+		return n, err
+	}
+	n.CourseWork = len(coursework)
+
+	// The course screen lists published announcements; the announcements
+	// screen shows teachers their drafts too
+	announcements, err := c.ListAnnouncements(ctx, courseID, nil)
+	if err != nil {
+		return n, err
+	}
+	n.Announcements = len(announcements)
+	if isTeacher {
+		drafts := &api.ListAnnouncementsOptions{States: []string{api.AnnouncementStatePublished, api.AnnouncementStateDraft}}
+		if _, err := c.ListAnnouncements(ctx, courseID, drafts); err != nil {
+			return n, err
+		}
+		return n, nil
+	}
+
+	for _, cw := range coursework {
+		if cw.State != api.CourseWorkStatePublished {
+			continue
+		}
+		if _, err := c.ListStudentSubmissions(ctx, courseID, cw.ID, &api.ListStudentSubmissionsOptions{UserID: "me"}); err != nil {
+			return n, err
+		}
+		n.Submissions++
+	}
+	return n, nil
+}
+
+// RunPrefetch implements `classroom cache warm [--refresh] [--concurrency=N]`.
+func RunPrefetch(ctx context.Context, c *CachedClient, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("cache warm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	refresh := fs.Bool("refresh", false, "refetch entries that are still fresh")
+	concurrency := fs.Int("concurrency", 4, "how many courses to fetch at once")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: cache warm [--refresh] [--concurrency=4]")
+	}
+
+	result, err := Prefetch(ctx, c, &PrefetchOptions{Concurrency: *concurrency, Refresh: *refresh})
+	if err != nil {
+		return fmt.Errorf("failed to warm cache: %w", err)
+	}
+	fmt.Fprintf(stdout, "Cached %d course(s): %d coursework, %d announcement(s), %d submission list(s).\n",
+		result.Courses, result.CourseWork, result.Announcements, result.Submissions)
+	return nil
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+)
+
+// countingClient counts the reads that reach the API.
+type countingClient struct {
+	*fake.Client
+	calls atomic.Int32
+}
+
+func (c *countingClient) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	c.calls.Add(1)
+	return c.Client.ListCourseWork(ctx, courseID, opts)
+}
+
+func (c *countingClient) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	c.calls.Add(1)
+	return c.Client.ListAnnouncements(ctx, courseID, opts)
+}
+
+func (c *countingClient) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	c.calls.Add(1)
+	return c.Client.ListStudentSubmissions(ctx, courseID, courseWorkID, opts)
+}
+
+// TestPrefetch tests that a prefetch caches what the screens read for
+// active courses only, and stops while paused.
+func TestPrefetch(t *testing.T) {
+	remote := fake.New("s1")
+	remote.AddCourse(&api.Course{ID: "c1", Name: "Biology"})
+	remote.AddCourse(&api.Course{ID: "c2", Name: "History", CourseState: api.CourseStateArchived})
+	remote.AddStudent(&api.Student{CourseID: "c1", UserID: "s1"})
+	remote.AddCourseWork(&api.CourseWork{ID: "cw1", CourseID: "c1", Title: "Essay"})
+	remote.AddCourseWork(&api.CourseWork{ID: "cw2", CourseID: "c1", Title: "Lab"})
+	remote.AddAnnouncement(&api.Announcement{CourseID: "c1", Text: "Welcome"})
+	client := &countingClient{Client: remote}
+
+	c, err := NewCache(&Configuration{Directory: t.TempDir(), CoursesTTL: time.Minute, CourseworkTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	cc := NewCachedClient(client, c)
+	ctx := context.Background()
+
+	result, err := Prefetch(ctx, cc, nil)
+	if err != nil {
+		t.Fatalf("Prefetch failed: %v", err)
+	}
+	if *result != (PrefetchResult{Courses: 1, CourseWork: 2, Announcements: 1, Submissions: 2}) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Reads shaped like the screens' are now answered from the cache
+	before := client.calls.Load()
+	cc.ListCourseWork(ctx, "c1", &api.ListCourseWorkOptions{States: []string{api.CourseWorkStatePublished}, OrderBy: api.CourseWorkOrderDueDateAsc})
+	cc.ListAnnouncements(ctx, "c1", nil)
+	cc.ListStudentSubmissions(ctx, "c1", "cw1", &api.ListStudentSubmissionsOptions{UserID: "me"})
+	if n := client.calls.Load() - before; n != 0 {
+		t.Errorf("Expected prefetched reads to hit the cache, got %d API calls", n)
+	}
+
+	_, err = Prefetch(ctx, cc, &PrefetchOptions{Refresh: true, Paused: func() bool { return true }})
+	if !errors.Is(err, ErrPrefetchPaused) {
+		t.Errorf("Expected ErrPrefetchPaused, got %v", err)
+	}
+
+	var out strings.Builder
+	if err := RunPrefetch(ctx, cc, []string{"--refresh"}, &out, &out); err != nil {
+		t.Fatalf("cache warm failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Cached 1 course(s)") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	// Registers the pure-Go "sqlite" driver, so the backend needs no cgo
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteFile is the database the SQLite backend keeps in the cache
+// directory.
+const SQLiteFile = "cache.db"
+
+// ErrNoSQLiteDriver means the requested SQLite driver is not linked into
+// the binary.
+var ErrNoSQLiteDriver = errors.New("no SQLite driver registered")
+
+// staleAfter is how long after expiring an entry is dropped when the
+// database is opened. Get drops expired entries it reads, but entries for
+// courses no longer opened would otherwise stay forever.
+const staleAfter = 7 * 24 * time.Hour
+
+// sqliteDrivers are the names SQLite drivers register under with
+// database/sql: modernc.org/sqlite, which is linked in, and
+// github.com/mattn/go-sqlite3.
+var sqliteDrivers = []string{"sqlite", "sqlite3"}
+
+// sqliteSchema creates the entries table. Keys are the primary key, so
+// lookups are indexed; expires_at is indexed so expired entries can be
+// counted and pruned without reading them.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS entries (
+	key        TEXT PRIMARY KEY,
+	data       BLOB NOT NULL,
+	cached_at  INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS entries_expires_at ON entries (expires_at);
+`
+
+// sqliteBackend keeps every entry in one SQLite database. It only uses
+// database/sql, so another driver can be linked in and picked with
+// Configuration.Driver.
+type sqliteBackend struct {
+	db   *sql.DB
+	path string
+}
+
+// sqliteDriver returns the driver to open the database with: driver when
+// set, otherwise the first known SQLite driver that is registered.
+func sqliteDriver(driver string) (string, error) {
+	registered := sql.Drivers()
+	if driver != "" {
+		if !slices.Contains(registered, driver) {
+			return "", fmt.Errorf("%w: %q", ErrNoSQLiteDriver, driver)
+		}
+		return driver, nil
+	}
+	for _, name := range sqliteDrivers {
+		if slices.Contains(registered, name) {
+			return name, nil
+		}
+	}
+	return "", ErrNoSQLiteDriver
+}
+
+// openSQLite opens or creates the database at path with driver, which may
+// be empty to pick a registered SQLite driver.
+func openSQLite(path, driver string) (*sqliteBackend, error) {
+	name, err := sqliteDriver(driver)
+	if err != nil {
+		return nil, err
+	}
+	if err := privateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	// Create the database owner-only; SQLite gives its journal files the
+	// same permissions
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache database: %w", err)
+	}
+	f.Close()
+	if err := os.Chmod(path, 0600); err != nil {
+		return nil, fmt.Errorf("failed to restrict cache database: %w", err)
+	}
+
+	db, err := sql.Open(name, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+	// One connection keeps the pragmas below in effect and avoids
+	// SQLITE_BUSY between connections of the same process
+	db.SetMaxOpenConns(1)
+	for _, stmt := range []string{"PRAGMA busy_timeout = 5000", "PRAGMA journal_mode = WAL", sqliteSchema} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to set up cache database: %w", err)
+		}
+	}
+	return &sqliteBackend{db: db, path: path}, nil
+}
+
+// OpenSQLite opens or creates a SQLite database at path as a Backend for
+// data kept alongside the cache, such as the offline store. driver may be
+// empty to pick a registered SQLite driver. Unlike the cache's own
+// database, expired entries are never pruned from it.
+func OpenSQLite(path, driver string) (Backend, error) {
+	return openSQLite(path, driver)
+}
+
+func (b *sqliteBackend) Get(key string) ([]byte, error) {
+	var data []byte
+	err := b.db.QueryRow(`SELECT data FROM entries WHERE key = ?`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	return data, nil
+}
+
+func (b *sqliteBackend) Put(key string, data []byte, cachedAt, expiresAt time.Time) error {
+	_, err := b.db.Exec(`INSERT INTO entries (key, data, cached_at, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET data = excluded.data, cached_at = excluded.cached_at, expires_at = excluded.expires_at`,
+		key, data, cachedAt.UnixMilli(), expiresAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+func (b *sqliteBackend) Delete(key string) error {
+	if _, err := b.db.Exec(`DELETE FROM entries WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete cache: %w", err)
+	}
+	return nil
+}
+
+func (b *sqliteBackend) DeletePrefix(prefix string) error {
+	// substr rather than LIKE, which ignores case and treats % and _ as
+	// wildcards. substr counts characters, not bytes
+	if _, err := b.db.Exec(`DELETE FROM entries WHERE substr(key, 1, ?) = ?`, utf8.RuneCountInString(prefix), prefix); err != nil {
+		return fmt.Errorf("failed to delete cache: %w", err)
+	}
+	return nil
+}
+
+func (b *sqliteBackend) Clear() error {
+	if _, err := b.db.Exec(`DELETE FROM entries`); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// Walk reports every entry from the indexed columns without reading its
+// data.
+func (b *sqliteBackend) Walk(fn func(StoredEntry) error) error {
+	rows, err := b.db.Query(`SELECT key, length(data), cached_at, expires_at FROM entries`)
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry StoredEntry
+		var cachedAt, expiresAt int64
+		if err := rows.Scan(&entry.Key, &entry.Size, &cachedAt, &expiresAt); err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+		entry.CachedAt = time.UnixMilli(cachedAt)
+		entry.ExpiresAt = time.UnixMilli(expiresAt)
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// prune deletes entries that expired before cutoff.
+func (b *sqliteBackend) prune(cutoff time.Time) error {
+	if _, err := b.db.Exec(`DELETE FROM entries WHERE expires_at < ?`, cutoff.UnixMilli()); err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+	return nil
+}
+
+func (b *sqliteBackend) Close() error {
+	return b.db.Close()
+}
+
+func (b *sqliteBackend) Name() string {
+	return "SQLite database " + b.path
+}
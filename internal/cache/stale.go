@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/connectivity"
+)
+
+// staleServed tracks the expired entries CachedClient answered with while
+// offline, by key, until a fresh read replaces them.
+type staleServed struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// served records that the entry under key, cached at cachedAt, was
+// served expired.
+func (s *staleServed) served(key string, cachedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]time.Time)
+	}
+	s.entries[key] = cachedAt
+}
+
+// refreshed records that key was read fresh again.
+func (s *staleServed) refreshed(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// StaleSince returns when the oldest expired entry still being shown was
+// cached, or the zero time when every read since was fresh. Screens use it
+// to say how old the data is while the network is down.
+func (c *Cache) StaleSince() time.Time {
+	c.stale.mu.Lock()
+	defer c.stale.mu.Unlock()
+	var oldest time.Time
+	for _, cachedAt := range c.stale.entries {
+		if oldest.IsZero() || cachedAt.Before(oldest) {
+			oldest = cachedAt
+		}
+	}
+	return oldest
+}
+
+// staleValue returns the value under key decoded as T even when it has
+// expired, for answering while offline, and when it was cached.
+func staleValue[T any](c *Cache, key string) (T, time.Time, bool) {
+	var v T
+	if !c.serveStale {
+		return v, time.Time{}, false
+	}
+	entry, err := c.read(key)
+	if err != nil || entry == nil {
+		return v, time.Time{}, false
+	}
+	if err := json.Unmarshal(entry.Data, &v); err != nil {
+		return v, time.Time{}, false
+	}
+	return v, entry.CachedAt, true
+}
+
+// fallBackToStale answers a read that failed with err from the expired
+// entry under key, when err means the API could not be reached.
+func fallBackToStale[T any](c *Cache, key string, err error) (T, bool) {
+	var zero T
+	if !connectivity.IsOffline(err) {
+		return zero, false
+	}
+	v, cachedAt, ok := staleValue[T](c, key)
+	if !ok {
+		return zero, false
+	}
+	c.stale.served(key, cachedAt)
+	return v, true
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// GetValue returns the value cached under key decoded as T, and whether
+// there was one. A missing, expired, unreadable, or undecodable entry is
+// a miss.
+func GetValue[T any](c *Cache, key string) (T, bool) {
+	var v T
+	entry, err := c.Get(key)
+	if err != nil || entry == nil {
+		return v, false
+	}
+	if err := json.Unmarshal(entry.Data, &v); err != nil {
+		return v, false
+	}
+	return v, true
+}
+
+// SetValue caches v under key for ttl.
+func SetValue[T any](c *Cache, key string, v T, ttl time.Duration) error {
+	return c.Set(key, v, ttl)
+}
+
+// GetCourses returns the courses cached under key.
+func (c *Cache) GetCourses(key string) ([]*api.Course, bool) {
+	return GetValue[[]*api.Course](c, key)
+}
+
+// SetCourses caches courses under key for the courses TTL.
+func (c *Cache) SetCourses(key string, courses []*api.Course) error {
+	return SetValue(c, key, courses, c.TTL(EntityCourses))
+}
+
+// GetCourseWork returns the coursework cached under key.
+func (c *Cache) GetCourseWork(key string) ([]*api.CourseWork, bool) {
+	return GetValue[[]*api.CourseWork](c, key)
+}
+
+// SetCourseWork caches coursework under key for the coursework TTL.
+func (c *Cache) SetCourseWork(key string, coursework []*api.CourseWork) error {
+	return SetValue(c, key, coursework, c.TTL(EntityCoursework))
+}
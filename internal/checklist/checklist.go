@@ -0,0 +1,200 @@
+// Package checklist keeps students' private subtask lists for assignments.
+// Checklists live only on this machine and are never sent to Classroom.
+package checklist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// Item is one subtask.
+type Item struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Progress counts the finished subtasks in a checklist.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// String formats progress as "2/5".
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d", p.Done, p.Total)
+}
+
+// Complete reports whether every subtask is done.
+func (p Progress) Complete() bool {
+	return p.Total > 0 && p.Done == p.Total
+}
+
+// Store holds checklists keyed by coursework, saved as a JSON file.
+type Store struct {
+	path   string
+	sealer *secure.Sealer
+
+	mu    sync.Mutex
+	lists map[string][]Item
+}
+
+// fileSchema versions the on-disk layout.
+var fileSchema = schema.Schema{Name: "checklists", Version: 1}
+
+// file is the on-disk layout.
+type file struct {
+	Version int `json:"version"`
+	// Checklists maps "courseID/courseWorkID" to its subtasks.
+	Checklists map[string][]Item `json:"checklists"`
+}
+
+// DefaultPath returns the default checklist location.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "google-classroom", "checklists.json"), nil
+}
+
+// Open loads the checklists at path. A missing file has no checklists.
+func Open(path string) (*Store, error) {
+	return OpenSealed(path, nil)
+}
+
+// OpenSealed loads the checklists at path and keeps them encrypted with s.
+// A plaintext file from before encryption was enabled is still read.
+func OpenSealed(path string, s *secure.Sealer) (*Store, error) {
+	st := &Store{path: path, sealer: s, lists: make(map[string][]Item)}
+
+	data, err := secure.ReadFile(path, s)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return nil, fmt.Errorf("failed to read checklists: %w", err)
+	}
+
+	var f file
+	if _, err := fileSchema.Decode(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Checklists != nil {
+		st.lists = f.Checklists
+	}
+	return st, nil
+}
+
+func key(courseID, courseWorkID string) string {
+	return courseID + "/" + courseWorkID
+}
+
+// Items returns the coursework's subtasks. It is safe to call on a nil
+// store.
+func (s *Store) Items(courseID, courseWorkID string) []Item {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Item(nil), s.lists[key(courseID, courseWorkID)]...)
+}
+
+// Progress counts the coursework's finished subtasks. It is safe to call
+// on a nil store.
+func (s *Store) Progress(courseID, courseWorkID string) Progress {
+	var p Progress
+	for _, item := range s.Items(courseID, courseWorkID) {
+		p.Total++
+		if item.Done {
+			p.Done++
+		}
+	}
+	return p
+}
+
+// Add appends a subtask and saves the store.
+func (s *Store) Add(courseID, courseWorkID, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("subtask is empty")
+	}
+	return s.update(courseID, courseWorkID, func(items []Item) ([]Item, error) {
+		return append(items, Item{Text: text}), nil
+	})
+}
+
+// Toggle marks the i'th subtask done or not done and saves the store.
+func (s *Store) Toggle(courseID, courseWorkID string, i int) error {
+	return s.update(courseID, courseWorkID, func(items []Item) ([]Item, error) {
+		if i < 0 || i >= len(items) {
+			return nil, fmt.Errorf("no subtask %d", i+1)
+		}
+		items[i].Done = !items[i].Done
+		return items, nil
+	})
+}
+
+// Remove deletes the i'th subtask and saves the store.
+func (s *Store) Remove(courseID, courseWorkID string, i int) error {
+	return s.update(courseID, courseWorkID, func(items []Item) ([]Item, error) {
+		if i < 0 || i >= len(items) {
+			return nil, fmt.Errorf("no subtask %d", i+1)
+		}
+		return append(items[:i], items[i+1:]...), nil
+	})
+}
+
+// update applies fn to a copy of the coursework's subtasks and saves the
+// result. Nothing changes if fn or the save fails.
+func (s *Store) update(courseID, courseWorkID string, fn func([]Item) ([]Item, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(courseID, courseWorkID)
+	old := s.lists[k]
+	items, err := fn(append([]Item(nil), old...))
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		delete(s.lists, k)
+	} else {
+		s.lists[k] = items
+	}
+	if err := s.save(); err != nil {
+		if old == nil {
+			delete(s.lists, k)
+		} else {
+			s.lists[k] = old
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the store through a temporary file so a crash never leaves
+// a half-written file. The caller holds s.mu.
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create checklist directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(file{Version: fileSchema.Version, Checklists: s.lists}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checklists: %w", err)
+	}
+
+	if err := secure.SealFile(s.path, data, s.sealer); err != nil {
+		return fmt.Errorf("failed to save checklists: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package checklist
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestChecklistPersists tests that subtasks and their states survive
+// reopening the store.
+func TestChecklistPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checklists.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	for _, text := range []string{"Outline", "Draft", "  ", "Cite sources"} {
+		err := s.Add("c1", "cw1", text)
+		if text == "  " {
+			if err == nil {
+				t.Error("Expected an empty subtask to be rejected")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to add %q: %v", text, err)
+		}
+	}
+	if err := s.Toggle("c1", "cw1", 0); err != nil {
+		t.Fatalf("Failed to toggle: %v", err)
+	}
+	if err := s.Remove("c1", "cw1", 1); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if err := s.Toggle("c1", "cw1", 5); err == nil {
+		t.Error("Expected toggling a missing subtask to fail")
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	items := reopened.Items("c1", "cw1")
+	if len(items) != 2 || items[0].Text != "Outline" || !items[0].Done || items[1].Text != "Cite sources" {
+		t.Errorf("Expected Outline done and Cite sources, got %+v", items)
+	}
+	if p := reopened.Progress("c1", "cw1"); p.String() != "1/2" || p.Complete() {
+		t.Errorf("Expected 1/2 and not complete, got %s", p)
+	}
+	if p := reopened.Progress("c1", "other"); p.Total != 0 {
+		t.Errorf("Expected no checklist for other coursework, got %s", p)
+	}
+}
+
+// TestNilStore tests that a nil store has no checklists.
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if p := s.Progress("c1", "cw1"); p.Total != 0 {
+		t.Errorf("Expected empty progress, got %s", p)
+	}
+}
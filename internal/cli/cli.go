@@ -0,0 +1,21 @@
+// Package cli holds helpers shared by the command-line subcommands.
+package cli
+
+import "flag"
+
+// ParseCourseArgs parses args with fs and returns the course ID, which may
+// come before or after the flags. It returns "" when there is none, so the
+// caller can report its own usage.
+func ParseCourseArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var courseID string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		courseID, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if courseID == "" && fs.NArg() > 0 {
+		courseID = fs.Arg(0)
+	}
+	return courseID, nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+// TestParseCourseArgs tests finding the course ID around the flags.
+func TestParseCourseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		output  string
+		wantErr bool
+	}{
+		{"before the flags", []string{"c1", "--output", "out.csv"}, "c1", "out.csv", false},
+		{"after the flags", []string{"--output", "out.csv", "c1"}, "c1", "out.csv", false},
+		{"no flags", []string{"c1"}, "c1", "", false},
+		{"missing", []string{"--output", "out.csv"}, "", "out.csv", false},
+		{"nothing", nil, "", "", false},
+		{"unknown flag", []string{"c1", "--nope"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		output := fs.String("output", "", "")
+
+		got, err := ParseCourseArgs(fs, tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got != tt.want || *output != tt.output {
+			t.Errorf("%s: expected %q with output %q, got %q with %q", tt.name, tt.want, tt.output, got, *output)
+		}
+	}
+}
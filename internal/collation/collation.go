@@ -0,0 +1,83 @@
+// Package collation provides locale-aware sorting and accent-insensitive
+// matching for names and titles.
+package collation
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Locale returns the user's collation locale from LC_ALL, LC_COLLATE, or
+// LANG. It falls back to the root locale when none is set or recognised.
+func Locale() language.Tag {
+	for _, env := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if i := strings.IndexAny(value, ".@"); i >= 0 {
+			value = value[:i]
+		}
+		if value == "C" || value == "POSIX" {
+			return language.Und
+		}
+		tag, err := language.Parse(strings.ReplaceAll(value, "_", "-"))
+		if err != nil {
+			return language.Und
+		}
+		return tag
+	}
+	return language.Und
+}
+
+// Sort stably sorts items by key using the collation rules of the user's locale.
+func Sort[T any](items []T, key func(T) string) {
+	c := collate.New(Locale())
+	sort.SliceStable(items, func(i, j int) bool {
+		return c.CompareString(key(items[i]), key(items[j])) < 0
+	})
+}
+
+// Fold removes diacritics and case-folds s so that "Élodie" and "elodie"
+// compare equal.
+func Fold(s string) string {
+	folded, _ := FoldIndexed(s)
+	return folded
+}
+
+// FoldIndexed folds s like Fold and also returns, for every byte of the
+// folded string, the index of the rune in s that produced it.
+func FoldIndexed(s string) (string, []int) {
+	caser := cases.Fold()
+	var b strings.Builder
+	runeIndexes := make([]int, 0, len(s))
+
+	i := 0
+	for _, r := range s {
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			folded := caser.String(string(d))
+			b.WriteString(folded)
+			for range len(folded) {
+				runeIndexes = append(runeIndexes, i)
+			}
+		}
+		i++
+	}
+
+	return b.String(), runeIndexes
+}
+
+// Contains reports whether substr is within s, ignoring case and accents.
+func Contains(s, substr string) bool {
+	return strings.Contains(Fold(s), Fold(substr))
+}
@@ -0,0 +1,57 @@
+package collation
+
+import (
+	"testing"
+)
+
+// TestFold tests removing accents and case.
+func TestFold(t *testing.T) {
+	tests := map[string]string{
+		"Élodie":      "elodie",
+		"José Núñez":  "jose nunez",
+		"Straße":      "strasse",
+		"plain ascii": "plain ascii",
+	}
+
+	for input, want := range tests {
+		if got := Fold(input); got != want {
+			t.Errorf("Fold(%q) = %q, expected %q", input, got, want)
+		}
+	}
+}
+
+// TestFoldIndexed tests mapping folded bytes back to runes.
+func TestFoldIndexed(t *testing.T) {
+	folded, indexes := FoldIndexed("Aé")
+	if folded != "ae" {
+		t.Fatalf("Expected folded string \"ae\", got %q", folded)
+	}
+	if len(indexes) != 2 || indexes[0] != 0 || indexes[1] != 1 {
+		t.Errorf("Expected rune indexes [0 1], got %v", indexes)
+	}
+}
+
+// TestContains tests accent-insensitive matching.
+func TestContains(t *testing.T) {
+	if !Contains("Français avancé", "francais") {
+		t.Error("Expected accent-insensitive match")
+	}
+	if Contains("Biology", "chem") {
+		t.Error("Expected no match")
+	}
+}
+
+// TestSort tests collation-aware sorting.
+func TestSort(t *testing.T) {
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+
+	names := []string{"Zoë", "Émile", "adam", "Eve"}
+	Sort(names, func(s string) string { return s })
+
+	want := []string{"adam", "Émile", "Eve", "Zoë"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, names)
+		}
+	}
+}
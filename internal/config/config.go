@@ -1 +1,580 @@
+// Package config loads application settings from the JSON configuration file.
 package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/calendar"
+	"github.com/user/google-classroom/internal/api/drive"
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/backoff"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/connectivity"
+	"github.com/user/google-classroom/internal/focus"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/offline"
+	"github.com/user/google-classroom/internal/schedule"
+	"github.com/user/google-classroom/internal/translate"
+	"github.com/user/google-classroom/internal/usage"
+)
+
+// Duration is a time.Duration that is written as a string such as "5m" in JSON.
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %w", err)
+	}
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config is the top-level application configuration.
+type Config struct {
+	OAuth OAuthConfig `json:"oauth"`
+	Cache CacheConfig `json:"cache"`
+	API   APIConfig   `json:"api"`
+	UI    UIConfig    `json:"ui"`
+	// Connectivity controls online/offline detection.
+	Connectivity ConnectivityConfig `json:"connectivity"`
+	// Translate configures on-demand translation of announcements and
+	// coursework descriptions.
+	Translate TranslateConfig `json:"translate"`
+	// Drive configures downloading attachments from Google Drive.
+	Drive DriveConfig `json:"drive"`
+	// Calendar selects where coursework due dates are synced.
+	Calendar CalendarConfig `json:"calendar"`
+	// Focus sets the focus timer's phase lengths.
+	Focus FocusConfig `json:"focus"`
+	// Offline keeps a local copy of all course data and serves screens
+	// from it.
+	Offline OfflineConfig `json:"offline"`
+	// Schedule maps a course ID or name to meeting times such as
+	// "Mon/Wed 10:00-11:30".
+	Schedule map[string][]string `json:"schedule"`
+}
+
+// OAuthConfig holds OAuth client settings.
+type OAuthConfig struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RedirectURI  string `json:"redirect_uri"`
+}
+
+// CacheConfig holds cache settings.
+type CacheConfig struct {
+	Enabled          bool     `json:"enabled"`
+	TTLCourses       Duration `json:"ttl_courses"`
+	TTLCoursework    Duration `json:"ttl_coursework"`
+	TTLAnnouncements Duration `json:"ttl_announcements"`
+	TTLRosters       Duration `json:"ttl_rosters"`
+	TTLSubmissions   Duration `json:"ttl_submissions"`
+	TTLUserProfiles  Duration `json:"ttl_user_profiles"`
+	Directory        string   `json:"directory"`
+	// Backend is "file" or "sqlite"; see cache.Configuration.Backend.
+	Backend string `json:"backend"`
+	// Encrypt encrypts entries with a key derived from the passphrase in
+	// GOOGLE_CLASSROOM_PASSPHRASE or the machine identity, like the token
+	// file. The data key from `secure enable` takes precedence.
+	Encrypt bool `json:"encrypt"`
+	// Compression is "gzip" to compress large entries, or "none".
+	Compression string `json:"compression"`
+	// Namespace keeps one account's entries apart, e.g. its email address
+	// or OAuth profile name; see cache.Configuration.Namespace.
+	Namespace string `json:"namespace"`
+	// ServeStale shows expired entries instead of an error while the API
+	// cannot be reached.
+	ServeStale bool `json:"serve_stale"`
+	// Prefetch warms the cache for every active course in the background
+	// when the TUI starts; `cache warm` does the same once.
+	Prefetch bool `json:"prefetch"`
+}
+
+// APIConfig holds API client settings.
+type APIConfig struct {
+	RateLimitBackoff Duration    `json:"rate_limit_backoff"`
+	MaxRetries       int         `json:"max_retries"`
+	Retry            RetryConfig `json:"retry"`
+	// MaxConcurrency bounds how many per-course requests run at once.
+	MaxConcurrency int `json:"max_concurrency"`
+	// RateLimit throttles requests on the client side.
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	// Debug logs every API request to DebugLog.
+	Debug    bool   `json:"debug"`
+	DebugLog string `json:"debug_log"`
+	// ETags revalidates reads with If-None-Match so unchanged data is not
+	// downloaded again.
+	ETags bool `json:"etags"`
+	// DailyBudget is a soft limit on API calls per day. At 80% the app
+	// warns, keeps cached data longer, and pauses prefetch. Zero disables
+	// it.
+	DailyBudget int `json:"daily_budget"`
+	// RecordFixtures records API responses, with credentials scrubbed, to
+	// this file for use as test fixtures.
+	RecordFixtures string `json:"record_fixtures"`
+	// Endpoint points the client at another Classroom API server, such as
+	// a local fake.
+	Endpoint string `json:"endpoint"`
+	// Timeout bounds each request. Zero means no limit.
+	Timeout Duration `json:"timeout"`
+	// DialTimeout and TLSHandshakeTimeout bound connection setup. Zero
+	// keeps the defaults.
+	DialTimeout         Duration `json:"dial_timeout"`
+	TLSHandshakeTimeout Duration `json:"tls_handshake_timeout"`
+	// Proxy is the HTTP proxy URL. When empty, HTTPS_PROXY and the other
+	// proxy environment variables apply.
+	Proxy string `json:"proxy"`
+	// PageSize is how many items list calls fetch per page. Zero leaves it
+	// to the server. PageSizes overrides it per list, e.g.
+	// {"submissions": 100}.
+	PageSize  int            `json:"page_size"`
+	PageSizes map[string]int `json:"page_sizes"`
+}
+
+// RateLimitConfig holds the client-side request limits. Zero disables a limit.
+type RateLimitConfig struct {
+	QPS       float64 `json:"qps"`
+	Burst     int     `json:"burst"`
+	PerMinute int     `json:"per_minute"`
+}
+
+// RetryConfig holds the retry policy shared by every subsystem that talks to
+// the network. Unset fields fall back to the backoff package defaults.
+type RetryConfig struct {
+	Initial     Duration `json:"initial"`
+	Multiplier  float64  `json:"multiplier"`
+	MaxInterval Duration `json:"max_interval"`
+	Jitter      float64  `json:"jitter"`
+	MaxElapsed  Duration `json:"max_elapsed"`
+	MaxAttempts int      `json:"max_attempts"`
+}
+
+// ConnectivityConfig holds online/offline detection settings.
+type ConnectivityConfig struct {
+	Enabled       bool     `json:"enabled"`
+	ProbeInterval Duration `json:"probe_interval"`
+	ProbeURL      string   `json:"probe_url"`
+}
+
+// UIConfig holds UI settings.
+type UIConfig struct {
+	Theme        string `json:"theme"`
+	MouseEnabled bool   `json:"mouse_enabled"`
+	// ShowDeletedCourseWork shows deleted coursework as tombstones for auditing.
+	ShowDeletedCourseWork bool `json:"show_deleted_coursework"`
+	// UndoWindow is how long deletions can be undone before they are sent.
+	UndoWindow Duration `json:"undo_window"`
+	// Confirm controls which actions ask for confirmation.
+	Confirm ConfirmConfig `json:"confirm"`
+	// Keys rebinds actions, keyed by action name (shared ones such as up,
+	// back, or refresh, and screen ones such as turn_in or sort), to lists
+	// of keys such as "ctrl+n" or "f5". Unlisted actions keep their
+	// defaults.
+	Keys map[string][]string `json:"keys,omitempty"`
+}
+
+// TranslateConfig selects a translation backend: a command that reads text
+// on stdin and writes the translation to stdout, or a Cloud Translation API
+// key. Language defaults to the one in $LANG.
+type TranslateConfig struct {
+	Language string `json:"language"`
+	// Command is run with "{lang}" replaced by the target language.
+	Command string `json:"command"`
+	APIKey  string `json:"api_key"`
+}
+
+// DriveConfig holds Drive attachment settings.
+type DriveConfig struct {
+	// DownloadDir is where attachments are saved.
+	DownloadDir string `json:"download_dir"`
+	// ExportFormat is how Google Docs, Sheets, and Slides are saved:
+	// "pdf", "markdown", or "text".
+	ExportFormat string `json:"export_format"`
+}
+
+// CalendarConfig selects the calendar due dates are synced to: a calendar
+// ID, the course's own calendar, or by default a dedicated calendar with
+// the given name.
+type CalendarConfig struct {
+	ID             string `json:"id"`
+	CourseCalendar bool   `json:"course_calendar"`
+	Name           string `json:"name"`
+}
+
+// CalendarTarget returns the configured sync target.
+func (c *Config) CalendarTarget() calendar.Target {
+	return calendar.Target{ID: c.Calendar.ID, Course: c.Calendar.CourseCalendar, Name: c.Calendar.Name}
+}
+
+// OfflineConfig holds offline store settings.
+type OfflineConfig struct {
+	Enabled bool `json:"enabled"`
+	// SyncInterval is how often the store is reconciled with the API in
+	// the background.
+	SyncInterval Duration `json:"sync_interval"`
+	Directory    string   `json:"directory"`
+}
+
+// FocusConfig holds the focus timer's phase lengths.
+type FocusConfig struct {
+	Focus Duration `json:"focus"`
+	Break Duration `json:"break"`
+}
+
+// FocusTimer returns the configured focus timer phases.
+func (c *Config) FocusTimer() focus.Config {
+	return focus.Config{Focus: time.Duration(c.Focus.Focus), Break: time.Duration(c.Focus.Break)}
+}
+
+// ConfirmConfig selects a confirmation profile ("strict" or "relaxed") and
+// per-action overrides keyed by turn_in, return, delete, or bulk.
+type ConfirmConfig struct {
+	Profile string          `json:"profile"`
+	Actions map[string]bool `json:"actions"`
+}
+
+// Default returns the default configuration.
+func Default() *Config {
+	cacheDefaults := cache.DefaultConfiguration()
+	apiDefaults := api.DefaultConfiguration()
+	connDefaults := connectivity.DefaultConfiguration()
+
+	return &Config{
+		OAuth: OAuthConfig{},
+		Cache: CacheConfig{
+			Enabled:          cacheDefaults.Enabled,
+			TTLCourses:       Duration(cacheDefaults.CoursesTTL),
+			TTLCoursework:    Duration(cacheDefaults.CourseworkTTL),
+			TTLAnnouncements: Duration(cacheDefaults.AnnouncementsTTL),
+			TTLRosters:       Duration(cacheDefaults.RostersTTL),
+			TTLSubmissions:   Duration(cacheDefaults.SubmissionsTTL),
+			TTLUserProfiles:  Duration(cacheDefaults.UserProfilesTTL),
+			Directory:        cacheDefaults.Directory,
+			Backend:          cacheDefaults.Backend,
+			ServeStale:       cacheDefaults.ServeStale,
+		},
+		API: APIConfig{
+			RateLimitBackoff: Duration(apiDefaults.RateLimitBackoff),
+			MaxRetries:       apiDefaults.MaxRetries,
+			MaxConcurrency:   apiDefaults.MaxConcurrency,
+			RateLimit: RateLimitConfig{
+				QPS:       apiDefaults.QPS,
+				Burst:     apiDefaults.Burst,
+				PerMinute: apiDefaults.QuotaPerMinute,
+			},
+			DebugLog: filepath.Join(cacheDefaults.Directory, "debug.log"),
+			ETags:    true,
+		},
+		Connectivity: ConnectivityConfig{
+			Enabled:       true,
+			ProbeInterval: Duration(connDefaults.Interval),
+			ProbeURL:      connectivity.DefaultProbeURL,
+		},
+		Drive: DriveConfig{
+			DownloadDir:  defaultDownloadDir(),
+			ExportFormat: drive.FormatPDF,
+		},
+		Offline: OfflineConfig{
+			SyncInterval: Duration(offline.DefaultConfiguration().Interval),
+			Directory:    defaultOfflineDir(),
+		},
+		Focus: FocusConfig{
+			Focus: Duration(focus.DefaultConfig().Focus),
+			Break: Duration(focus.DefaultConfig().Break),
+		},
+		UI: UIConfig{
+			Theme:        "default",
+			MouseEnabled: true,
+			UndoWindow:   Duration(5 * time.Second),
+			Confirm: ConfirmConfig{
+				Profile: confirm.ProfileStrict,
+			},
+		},
+	}
+}
+
+// DefaultPath returns the default configuration file location.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "google-classroom", "config.json"), nil
+}
+
+// Load reads the configuration file, applying it over the defaults. A missing
+// file yields the defaults.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	if _, err := cfg.CourseSchedule(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if _, err := cfg.ConfirmPolicy(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if _, err := cfg.KeyMap(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	for list := range cfg.API.PageSizes {
+		if !slices.Contains(api.PageLists(), list) {
+			return nil, fmt.Errorf("invalid configuration: unknown page size list %q (want one of %s)", list, strings.Join(api.PageLists(), ", "))
+		}
+	}
+	switch cfg.Cache.Backend {
+	case "", cache.BackendFile, cache.BackendSQLite:
+	default:
+		return nil, fmt.Errorf("invalid configuration: unknown cache backend %q (want %q or %q)", cfg.Cache.Backend, cache.BackendFile, cache.BackendSQLite)
+	}
+	if !cache.ValidCodec(cfg.Cache.Compression) {
+		return nil, fmt.Errorf("invalid configuration: unknown cache compression %q (want %q or \"none\")", cfg.Cache.Compression, cache.CodecGzip)
+	}
+	if !drive.ValidFormat(cfg.Drive.ExportFormat) {
+		return nil, fmt.Errorf("invalid configuration: unknown drive export format %q", cfg.Drive.ExportFormat)
+	}
+
+	cfg.Cache.Directory = expandHome(cfg.Cache.Directory)
+	cfg.API.DebugLog = expandHome(cfg.API.DebugLog)
+	cfg.API.RecordFixtures = expandHome(cfg.API.RecordFixtures)
+	cfg.Drive.DownloadDir = expandHome(cfg.Drive.DownloadDir)
+	cfg.Offline.Directory = expandHome(cfg.Offline.Directory)
+	return cfg, nil
+}
+
+// defaultDownloadDir returns ~/Downloads, or "Downloads" when the home
+// directory is unknown.
+func defaultDownloadDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "Downloads"
+	}
+	return filepath.Join(homeDir, "Downloads")
+}
+
+// defaultOfflineDir returns the offline store's default directory, or a
+// relative one when the home directory is unknown.
+func defaultOfflineDir() string {
+	dir, err := offline.DefaultDir()
+	if err != nil {
+		return "offline"
+	}
+	return dir
+}
+
+// RetryPolicy builds the shared retry policy. The legacy rate_limit_backoff
+// and max_retries settings are used when the retry section leaves them unset.
+func (c *APIConfig) RetryPolicy() *backoff.Policy {
+	p := backoff.Default()
+
+	if c.RateLimitBackoff > 0 {
+		p.Initial = time.Duration(c.RateLimitBackoff)
+	}
+	if c.MaxRetries > 0 {
+		p.MaxAttempts = c.MaxRetries
+	}
+
+	r := c.Retry
+	if r.Initial > 0 {
+		p.Initial = time.Duration(r.Initial)
+	}
+	if r.Multiplier > 0 {
+		p.Multiplier = r.Multiplier
+	}
+	if r.MaxInterval > 0 {
+		p.MaxInterval = time.Duration(r.MaxInterval)
+	}
+	if r.Jitter > 0 {
+		p.Jitter = r.Jitter
+	}
+	if r.MaxElapsed > 0 {
+		p.MaxElapsed = time.Duration(r.MaxElapsed)
+	}
+	if r.MaxAttempts > 0 {
+		p.MaxAttempts = r.MaxAttempts
+	}
+
+	return p
+}
+
+// APIConfiguration converts the API settings into an api.Configuration.
+func (c *Config) APIConfiguration() *api.Configuration {
+	cfg := api.DefaultConfiguration()
+	cfg.RateLimitBackoff = time.Duration(c.API.RateLimitBackoff)
+	cfg.MaxRetries = c.API.MaxRetries
+	cfg.Retry = c.API.RetryPolicy()
+	if c.API.MaxConcurrency > 0 {
+		cfg.MaxConcurrency = c.API.MaxConcurrency
+	}
+	cfg.QPS = c.API.RateLimit.QPS
+	cfg.Burst = c.API.RateLimit.Burst
+	cfg.QuotaPerMinute = c.API.RateLimit.PerMinute
+	if c.API.Debug {
+		cfg.DebugLog = c.API.DebugLog
+	}
+	cfg.DisableETags = !c.API.ETags
+	cfg.RecordFixtures = c.API.RecordFixtures
+	cfg.Endpoint = c.API.Endpoint
+	cfg.Timeout = time.Duration(c.API.Timeout)
+	cfg.DialTimeout = time.Duration(c.API.DialTimeout)
+	cfg.TLSHandshakeTimeout = time.Duration(c.API.TLSHandshakeTimeout)
+	cfg.Proxy = c.API.Proxy
+	cfg.PageSize = c.API.PageSize
+	cfg.PageSizes = c.API.PageSizes
+	return cfg
+}
+
+// CacheConfiguration converts the cache settings into a cache.Configuration.
+func (c *Config) CacheConfiguration() *cache.Configuration {
+	return &cache.Configuration{
+		Enabled:          c.Cache.Enabled,
+		CoursesTTL:       time.Duration(c.Cache.TTLCourses),
+		CourseworkTTL:    time.Duration(c.Cache.TTLCoursework),
+		AnnouncementsTTL: time.Duration(c.Cache.TTLAnnouncements),
+		RostersTTL:       time.Duration(c.Cache.TTLRosters),
+		SubmissionsTTL:   time.Duration(c.Cache.TTLSubmissions),
+		UserProfilesTTL:  time.Duration(c.Cache.TTLUserProfiles),
+		Directory:        c.Cache.Directory,
+		Backend:          c.Cache.Backend,
+		Key:              cacheKey(c.Cache.Encrypt),
+		Compression:      c.Cache.Compression,
+		Namespace:        c.Cache.Namespace,
+		ServeStale:       c.Cache.ServeStale,
+	}
+}
+
+// cacheKey returns the secret cache entries are encrypted with when
+// encrypt is set, or nil.
+func cacheKey(encrypt bool) func() ([]byte, error) {
+	if !encrypt {
+		return nil
+	}
+	return auth.DefaultKeySource()
+}
+
+// UsageConfiguration converts the daily budget into a usage.Configuration
+// whose count is kept in path.
+func (c *Config) UsageConfiguration(path string) *usage.Configuration {
+	cfg := usage.DefaultConfiguration()
+	cfg.DailyBudget = c.API.DailyBudget
+	cfg.Path = path
+	return cfg
+}
+
+// TranslateConfiguration converts the translation settings into a
+// translate.Configuration that caches results in c. It returns nil when no
+// backend is configured.
+func (c *Config) TranslateConfiguration(cc *cache.Cache) (*translate.Configuration, error) {
+	t := c.Translate
+	cfg := &translate.Configuration{Language: t.Language, Cache: cc}
+	switch {
+	case t.Command != "":
+		cmd, err := translate.ParseCommand(t.Command)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Backend = cmd
+	case t.APIKey != "":
+		cfg.Backend = &translate.GoogleAPI{Key: t.APIKey}
+	default:
+		return nil, nil
+	}
+
+	if cfg.Language == "" {
+		cfg.Language = localeLanguage(os.Getenv("LANG"))
+	}
+	if cfg.Language == "" {
+		return nil, errors.New("translate.language is not set and $LANG has no language")
+	}
+	return cfg, nil
+}
+
+// localeLanguage returns the language of a locale such as "pt_BR.UTF-8"
+// as "pt-BR", or "" for the C and POSIX locales.
+func localeLanguage(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(locale, "_", "-")
+}
+
+// ConnectivityConfiguration converts the connectivity settings into a
+// connectivity.Configuration. It returns nil when detection is disabled.
+func (c *Config) ConnectivityConfiguration() *connectivity.Configuration {
+	if !c.Connectivity.Enabled {
+		return nil
+	}
+	cfg := connectivity.DefaultConfiguration()
+	if c.Connectivity.ProbeInterval > 0 {
+		cfg.Interval = time.Duration(c.Connectivity.ProbeInterval)
+	}
+	if c.Connectivity.ProbeURL != "" {
+		cfg.Probe = connectivity.HTTPProbe(&http.Client{Timeout: cfg.Timeout}, c.Connectivity.ProbeURL)
+	}
+	return cfg
+}
+
+// CourseSchedule parses the configured course meeting times.
+func (c *Config) CourseSchedule() (schedule.Schedule, error) {
+	return schedule.Parse(c.Schedule)
+}
+
+// ConfirmPolicy builds the confirmation policy from the UI settings.
+func (c *Config) ConfirmPolicy() (*confirm.Policy, error) {
+	return confirm.NewPolicy(c.UI.Confirm.Profile, c.UI.Confirm.Actions)
+}
+
+// KeyMap builds the key bindings from the UI settings.
+func (c *Config) KeyMap() (*keymap.KeyMap, error) {
+	return keymap.New(c.UI.Keys)
+}
+
+// expandHome expands a leading "~" to the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
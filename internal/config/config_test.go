@@ -0,0 +1,264 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadMissingFile tests that a missing file yields the defaults.
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if !cfg.Cache.Enabled {
+		t.Error("Expected cache to be enabled by default")
+	}
+	if cfg.API.MaxRetries != 3 {
+		t.Errorf("Expected 3 max retries, got %d", cfg.API.MaxRetries)
+	}
+}
+
+// TestLoad tests loading settings over the defaults.
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "cache": {"ttl_courses": "10m", "ttl_submissions": "2m", "directory": "~/cache-dir"},
+  "api": {"rate_limit_backoff": "2s", "max_retries": 5, "retry": {"jitter": 0.5, "max_elapsed": "1m"}, "rate_limit": {"qps": 2}}
+}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if time.Duration(cfg.Cache.TTLCourses) != 10*time.Minute {
+		t.Errorf("Expected 10m courses TTL, got %v", time.Duration(cfg.Cache.TTLCourses))
+	}
+	if time.Duration(cfg.Cache.TTLCoursework) != time.Hour {
+		t.Errorf("Expected default coursework TTL, got %v", time.Duration(cfg.Cache.TTLCoursework))
+	}
+	if ttl := cfg.CacheConfiguration().SubmissionsTTL; ttl != 2*time.Minute {
+		t.Errorf("Expected 2m submissions TTL, got %v", ttl)
+	}
+	if ttl := cfg.CacheConfiguration().RostersTTL; ttl != time.Hour {
+		t.Errorf("Expected default rosters TTL, got %v", ttl)
+	}
+	if filepath.Base(cfg.Cache.Directory) != "cache-dir" || cfg.Cache.Directory[0] == '~' {
+		t.Errorf("Expected expanded cache directory, got %s", cfg.Cache.Directory)
+	}
+
+	policy := cfg.API.RetryPolicy()
+	if policy.Initial != 2*time.Second {
+		t.Errorf("Expected initial backoff 2s, got %v", policy.Initial)
+	}
+	if policy.MaxAttempts != 5 {
+		t.Errorf("Expected 5 attempts, got %d", policy.MaxAttempts)
+	}
+	if policy.Jitter != 0.5 || policy.MaxElapsed != time.Minute {
+		t.Errorf("Expected retry section to apply, got jitter %v max elapsed %v", policy.Jitter, policy.MaxElapsed)
+	}
+	apiCfg := cfg.APIConfiguration()
+	if apiCfg.QPS != 2 {
+		t.Errorf("Expected 2 QPS, got %v", apiCfg.QPS)
+	}
+	if apiCfg.QuotaPerMinute != 1000 {
+		t.Errorf("Expected default per-minute quota, got %d", apiCfg.QuotaPerMinute)
+	}
+	if apiCfg.DebugLog != "" {
+		t.Errorf("Expected debug logging to be off by default, got %q", apiCfg.DebugLog)
+	}
+	if apiCfg.DisableETags {
+		t.Error("Expected conditional requests to be on by default")
+	}
+	if filepath.Base(cfg.Drive.DownloadDir) != "Downloads" {
+		t.Errorf("Expected attachments to download to Downloads by default, got %s", cfg.Drive.DownloadDir)
+	}
+	if budget := cfg.UsageConfiguration("").DailyBudget; budget != 0 {
+		t.Errorf("Expected no daily budget by default, got %d", budget)
+	}
+
+	cfg.API.Debug = true
+	if cfg.APIConfiguration().DebugLog == "" {
+		t.Error("Expected debug flag to enable the debug log")
+	}
+}
+
+// TestLoadInvalidDuration tests that malformed durations are rejected.
+func TestLoadInvalidDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"cache": {"ttl_courses": "soon"}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid duration")
+	}
+}
+
+// TestLoadInvalidExportFormat tests rejecting an unknown Drive export
+// format.
+func TestLoadInvalidExportFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"drive": {"export_format": "docx"}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown export format")
+	}
+}
+
+// TestLoadInvalidSchedule tests rejecting malformed course meeting times.
+func TestLoadInvalidSchedule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"schedule": {"Biology": ["Mon 25:00-26:00"]}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid schedule")
+	}
+}
+
+// TestLoadKeys tests rebinding keys and rejecting unknown actions.
+func TestLoadKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"ui": {"keys": {"up": ["e"], "down": ["n"], "show_announcements": ["N"]}}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	keys, err := cfg.KeyMap()
+	if err != nil {
+		t.Fatalf("Failed to build key map: %v", err)
+	}
+	if a, ok := keys.Lookup("e"); !ok || a != "up" {
+		t.Errorf("Expected e bound to up, got %q, %v", a, ok)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"ui": {"keys": {"jump": ["x"]}}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for an unknown key action")
+	}
+}
+
+// TestLoadPageSizes tests per-list page sizes and rejecting unknown lists.
+func TestLoadPageSizes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"api": {"page_size": 50, "page_sizes": {"submissions": 200}}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	apiCfg := cfg.APIConfiguration()
+	if apiCfg.PageSize != 50 {
+		t.Errorf("Expected page size 50, got %d", apiCfg.PageSize)
+	}
+	if apiCfg.PageSizes["submissions"] != 200 {
+		t.Errorf("Expected submissions page size 200, got %d", apiCfg.PageSizes["submissions"])
+	}
+
+	if err := os.WriteFile(path, []byte(`{"api": {"page_sizes": {"grades": 10}}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown page size list")
+	}
+}
+
+// TestConnectivityConfiguration tests converting the connectivity settings.
+func TestConnectivityConfiguration(t *testing.T) {
+	cfg := Default()
+	cfg.Connectivity.ProbeInterval = Duration(time.Minute)
+
+	conn := cfg.ConnectivityConfiguration()
+	if conn == nil {
+		t.Fatal("Expected connectivity detection to be enabled by default")
+	}
+	if conn.Interval != time.Minute {
+		t.Errorf("Expected 1m probe interval, got %v", conn.Interval)
+	}
+
+	cfg.Connectivity.Enabled = false
+	if cfg.ConnectivityConfiguration() != nil {
+		t.Error("Expected nil configuration when detection is disabled")
+	}
+}
+
+// TestAPIConfiguration tests converting the endpoint, timeout, and proxy
+// settings.
+func TestAPIConfiguration(t *testing.T) {
+	cfg := Default()
+	cfg.API.Endpoint = "http://localhost:8080/"
+	cfg.API.Timeout = Duration(30 * time.Second)
+	cfg.API.DialTimeout = Duration(5 * time.Second)
+	cfg.API.Proxy = "http://proxy.example.com:3128"
+
+	apiCfg := cfg.APIConfiguration()
+	if apiCfg.Endpoint != "http://localhost:8080/" {
+		t.Errorf("Expected endpoint http://localhost:8080/, got %q", apiCfg.Endpoint)
+	}
+	if apiCfg.Timeout != 30*time.Second {
+		t.Errorf("Expected 30s timeout, got %v", apiCfg.Timeout)
+	}
+	if apiCfg.DialTimeout != 5*time.Second {
+		t.Errorf("Expected 5s dial timeout, got %v", apiCfg.DialTimeout)
+	}
+	if apiCfg.TLSHandshakeTimeout != 0 {
+		t.Errorf("Expected default TLS handshake timeout, got %v", apiCfg.TLSHandshakeTimeout)
+	}
+	if apiCfg.Proxy != "http://proxy.example.com:3128" {
+		t.Errorf("Expected proxy http://proxy.example.com:3128, got %q", apiCfg.Proxy)
+	}
+}
+
+// TestTranslateConfiguration tests selecting a translation backend and
+// falling back to the language in $LANG.
+func TestTranslateConfiguration(t *testing.T) {
+	cfg := Default()
+	tc, err := cfg.TranslateConfiguration(nil)
+	if err != nil || tc != nil {
+		t.Fatalf("Expected no translation by default, got %v, %v", tc, err)
+	}
+
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	cfg.Translate.Command = "trans -b :{lang}"
+	tc, err = cfg.TranslateConfiguration(nil)
+	if err != nil {
+		t.Fatalf("TranslateConfiguration failed: %v", err)
+	}
+	if tc.Language != "pt-BR" {
+		t.Errorf("Expected language pt-BR from $LANG, got %q", tc.Language)
+	}
+
+	t.Setenv("LANG", "C")
+	if _, err := cfg.TranslateConfiguration(nil); err == nil {
+		t.Error("Expected error without a target language")
+	}
+}
+
+// TestLoadInvalidCacheBackend tests rejecting an unknown cache backend.
+func TestLoadInvalidCacheBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"cache": {"backend": "redis"}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown cache backend")
+	}
+}
@@ -0,0 +1,90 @@
+// Package confirm decides which user actions need an explicit confirmation.
+package confirm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is a destructive or grade-affecting action.
+type Action string
+
+// Actions that can require confirmation.
+const (
+	TurnIn Action = "turn_in"
+	Return Action = "return"
+	Delete Action = "delete"
+	Bulk   Action = "bulk"
+)
+
+// Profile names.
+const (
+	ProfileStrict  = "strict"
+	ProfileRelaxed = "relaxed"
+)
+
+// profiles maps each profile to the actions it confirms. Strict suits
+// students; relaxed suits teachers who rely on the undo window for deletions
+// and only want a prompt before bulk changes.
+var profiles = map[string]map[Action]bool{
+	ProfileStrict: {
+		TurnIn: true,
+		Return: true,
+		Delete: true,
+		Bulk:   true,
+	},
+	ProfileRelaxed: {
+		Bulk: true,
+	},
+}
+
+// Policy decides whether an action needs confirmation. A nil Policy
+// confirms everything.
+type Policy struct {
+	required map[Action]bool
+}
+
+// NewPolicy builds a policy from a profile name and per-action overrides
+// keyed by action name. An empty profile means strict.
+func NewPolicy(profile string, overrides map[string]bool) (*Policy, error) {
+	if profile == "" {
+		profile = ProfileStrict
+	}
+	base, ok := profiles[strings.ToLower(profile)]
+	if !ok {
+		return nil, fmt.Errorf("unknown confirmation profile %q (want %s or %s)", profile, ProfileStrict, ProfileRelaxed)
+	}
+
+	required := make(map[Action]bool, len(base))
+	for a, v := range base {
+		required[a] = v
+	}
+	for name, v := range overrides {
+		a := Action(name)
+		if _, known := profiles[ProfileStrict][a]; !known {
+			return nil, fmt.Errorf("unknown confirmation action %q (want one of %s)", name, strings.Join(actionNames(), ", "))
+		}
+		required[a] = v
+	}
+
+	return &Policy{required: required}, nil
+}
+
+// Required reports whether action needs confirmation.
+func (p *Policy) Required(a Action) bool {
+	if p == nil {
+		return true
+	}
+	return p.required[a]
+}
+
+// actionNames lists the known action names.
+func actionNames() []string {
+	var names []string
+	for a := range profiles[ProfileStrict] {
+		names = append(names, string(a))
+	}
+	sort.Strings(names)
+	return names
+}
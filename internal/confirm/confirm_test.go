@@ -0,0 +1,50 @@
+package confirm
+
+import "testing"
+
+// TestProfiles tests the built-in confirmation profiles.
+func TestProfiles(t *testing.T) {
+	strict, err := NewPolicy("", nil)
+	if err != nil {
+		t.Fatalf("Failed to build strict policy: %v", err)
+	}
+	for _, a := range []Action{TurnIn, Return, Delete, Bulk} {
+		if !strict.Required(a) {
+			t.Errorf("Expected strict profile to confirm %s", a)
+		}
+	}
+
+	relaxed, err := NewPolicy(ProfileRelaxed, nil)
+	if err != nil {
+		t.Fatalf("Failed to build relaxed policy: %v", err)
+	}
+	if relaxed.Required(Delete) {
+		t.Error("Expected relaxed profile not to confirm deletes")
+	}
+	if !relaxed.Required(Bulk) {
+		t.Error("Expected relaxed profile to confirm bulk actions")
+	}
+
+	var none *Policy
+	if !none.Required(TurnIn) {
+		t.Error("Expected nil policy to confirm everything")
+	}
+}
+
+// TestOverrides tests per-action overrides and validation.
+func TestOverrides(t *testing.T) {
+	p, err := NewPolicy(ProfileRelaxed, map[string]bool{"delete": true, "bulk": false})
+	if err != nil {
+		t.Fatalf("Failed to build policy: %v", err)
+	}
+	if !p.Required(Delete) || p.Required(Bulk) {
+		t.Error("Expected overrides to apply")
+	}
+
+	if _, err := NewPolicy("lenient", nil); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if _, err := NewPolicy(ProfileStrict, map[string]bool{"explode": true}); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+}
@@ -0,0 +1,282 @@
+// Package connectivity detects whether the Classroom API is reachable and
+// tells the rest of the app when it goes offline or comes back.
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// DefaultProbeURL is the endpoint probed to decide whether the app is online.
+const DefaultProbeURL = "https://classroom.googleapis.com/"
+
+// State is the connection state seen by the monitor.
+type State int
+
+const (
+	// Unknown means no probe has finished yet. It is treated as online.
+	Unknown State = iota
+	// Online means the API answered the last probe.
+	Online
+	// Offline means the last probe or request failed to reach the API.
+	Offline
+)
+
+func (s State) String() string {
+	switch s {
+	case Online:
+		return "online"
+	case Offline:
+		return "offline"
+	default:
+		return "unknown"
+	}
+}
+
+// Probe checks whether the API can be reached. A nil error means online.
+type Probe func(ctx context.Context) error
+
+// HTTPProbe returns a probe that succeeds when url answers with any HTTP
+// response. Only transport failures count as offline.
+func HTTPProbe(client *http.Client, url string) Probe {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+}
+
+// Configuration holds monitor settings.
+type Configuration struct {
+	// Interval is the time between probes while online. While offline,
+	// probes follow the retry policy, so the app notices quickly when the
+	// connection returns, and never wait longer than this.
+	Interval time.Duration
+	// Timeout bounds a single probe.
+	Timeout time.Duration
+	// Probe checks reachability. Defaults to an HTTP probe of DefaultProbeURL.
+	Probe Probe
+	// OnReplayError is called when a queued action fails for a reason other
+	// than being offline. The action is dropped.
+	OnReplayError func(error)
+}
+
+// DefaultConfiguration returns the default monitor configuration.
+func DefaultConfiguration() *Configuration {
+	return &Configuration{
+		Interval: 30 * time.Second,
+		Timeout:  5 * time.Second,
+	}
+}
+
+// Monitor probes the API periodically and on demand, publishes state
+// changes to subscribers, and replays actions queued while offline once the
+// connection returns.
+type Monitor struct {
+	cfg   Configuration
+	retry *backoff.Policy
+
+	mu          sync.Mutex
+	state       State
+	subscribers []chan State
+	pending     []func(ctx context.Context) error
+	wake        chan struct{}
+}
+
+// NewMonitor creates a monitor that probes under retry, which is required,
+// while offline. Call Run to start probing.
+func NewMonitor(cfg *Configuration, retry *backoff.Policy) (*Monitor, error) {
+	if retry == nil {
+		return nil, errors.New("monitor needs a retry policy")
+	}
+	if cfg == nil {
+		cfg = DefaultConfiguration()
+	}
+	c := *cfg
+	defaults := DefaultConfiguration()
+	if c.Interval <= 0 {
+		c.Interval = defaults.Interval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.Probe == nil {
+		c.Probe = HTTPProbe(&http.Client{Timeout: c.Timeout}, DefaultProbeURL)
+	}
+
+	return &Monitor{
+		cfg:   c,
+		retry: retry,
+		wake:  make(chan struct{}, 1),
+	}, nil
+}
+
+// State returns the current connection state.
+func (m *Monitor) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// Online reports whether requests should be attempted. A monitor that has
+// not probed yet counts as online.
+func (m *Monitor) Online() bool {
+	return m.State() != Offline
+}
+
+// Subscribe returns a channel that receives the state after every change.
+// A slow reader only sees the latest state.
+func (m *Monitor) Subscribe() <-chan State {
+	ch := make(chan State, 1)
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, ch)
+	m.mu.Unlock()
+	return ch
+}
+
+// Run probes until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	failures := 0
+	for {
+		interval := m.cfg.Interval
+		if m.Check(ctx) == Offline {
+			interval = min(m.retry.Interval(failures), interval)
+			failures++
+		} else {
+			failures = 0
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Check probes once and updates the state.
+func (m *Monitor) Check(ctx context.Context) State {
+	probeCtx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	err := m.cfg.Probe(probeCtx)
+	cancel()
+
+	if ctx.Err() != nil {
+		return m.State()
+	}
+	if err != nil {
+		m.setState(Offline)
+		return Offline
+	}
+	if m.setState(Online) {
+		m.replay(ctx)
+	}
+	return Online
+}
+
+// Report feeds the outcome of a real request to the monitor. A connection
+// failure switches to offline right away and schedules a probe, so the app
+// does not wait for the next interval to notice.
+func (m *Monitor) Report(err error) {
+	if !IsOffline(err) {
+		return
+	}
+	m.setState(Offline)
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Queue holds fn until the connection returns. Actions run in the order
+// they were queued; one that fails because the app is still offline is kept
+// for the next attempt.
+func (m *Monitor) Queue(fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	m.pending = append(m.pending, fn)
+	m.mu.Unlock()
+}
+
+// Pending returns the number of actions waiting for the connection.
+func (m *Monitor) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// setState records s and notifies subscribers. It reports whether the app
+// just came back online.
+func (m *Monitor) setState(s State) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == s {
+		return false
+	}
+	reconnected := m.state == Offline && s == Online
+	m.state = s
+
+	for _, ch := range m.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- s
+	}
+	return reconnected
+}
+
+// replay runs the queued actions.
+func (m *Monitor) replay(ctx context.Context) {
+	m.mu.Lock()
+	queued := m.pending
+	m.pending = nil
+	m.mu.Unlock()
+
+	for i, fn := range queued {
+		err := fn(ctx)
+		if IsOffline(err) {
+			m.mu.Lock()
+			m.pending = append(append([]func(context.Context) error(nil), queued[i:]...), m.pending...)
+			m.mu.Unlock()
+			m.Report(err)
+			return
+		}
+		if err != nil && m.cfg.OnReplayError != nil {
+			m.cfg.OnReplayError(err)
+		}
+	}
+}
+
+// IsOffline reports whether err means the API could not be reached, as
+// opposed to the API rejecting the request.
+func IsOffline(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if e, ok := apperrors.As(err); ok {
+		return e.Type == apperrors.ErrAPINetwork || e.Type == apperrors.ErrAuthOffline
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,197 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/backoff"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// fakeProbe returns whatever error is currently set.
+type fakeProbe struct {
+	err error
+}
+
+func (p *fakeProbe) probe(ctx context.Context) error {
+	return p.err
+}
+
+var errUnreachable = &net.OpError{Op: "dial", Err: errors.New("network is unreachable")}
+
+// newMonitor creates a monitor with the default retry policy.
+func newMonitor(t *testing.T, cfg *Configuration) *Monitor {
+	t.Helper()
+	m, err := NewMonitor(cfg, backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	return m
+}
+
+// TestCheckTransitions tests state changes and subscriber notifications.
+func TestCheckTransitions(t *testing.T) {
+	p := &fakeProbe{}
+	m := newMonitor(t, &Configuration{Probe: p.probe})
+	updates := m.Subscribe()
+
+	if !m.Online() {
+		t.Error("Expected unknown state to count as online")
+	}
+
+	ctx := context.Background()
+	if got := m.Check(ctx); got != Online {
+		t.Errorf("Expected online, got %v", got)
+	}
+	if got := <-updates; got != Online {
+		t.Errorf("Expected online update, got %v", got)
+	}
+
+	p.err = errUnreachable
+	if got := m.Check(ctx); got != Offline {
+		t.Errorf("Expected offline, got %v", got)
+	}
+	if got := <-updates; got != Offline {
+		t.Errorf("Expected offline update, got %v", got)
+	}
+
+	m.Check(ctx)
+	select {
+	case got := <-updates:
+		t.Errorf("Expected no update without a change, got %v", got)
+	default:
+	}
+}
+
+// TestReport tests switching offline on a failed request.
+func TestReport(t *testing.T) {
+	m := newMonitor(t, &Configuration{Probe: (&fakeProbe{}).probe})
+
+	m.Report(apperrors.Wrap(errors.New("not found"), apperrors.ErrAPINotFound, "failed to get course"))
+	if m.State() == Offline {
+		t.Error("Expected API errors not to switch offline")
+	}
+
+	m.Report(apperrors.Wrap(errUnreachable, apperrors.ErrAPINetwork, "failed to list courses"))
+	if m.State() != Offline {
+		t.Errorf("Expected offline, got %v", m.State())
+	}
+}
+
+// TestQueueReplay tests running queued actions after reconnecting.
+func TestQueueReplay(t *testing.T) {
+	p := &fakeProbe{err: errUnreachable}
+	var replayErrs []error
+	m := newMonitor(t, &Configuration{
+		Probe:         p.probe,
+		OnReplayError: func(err error) { replayErrs = append(replayErrs, err) },
+	})
+	ctx := context.Background()
+	m.Check(ctx)
+
+	var ran []string
+	stillOffline := true
+	m.Queue(func(ctx context.Context) error {
+		if stillOffline {
+			return errUnreachable
+		}
+		ran = append(ran, "first")
+		return nil
+	})
+	m.Queue(func(ctx context.Context) error {
+		ran = append(ran, "second")
+		return errors.New("rejected")
+	})
+
+	// Reconnect, but the first action still cannot reach the API.
+	p.err = nil
+	m.Check(ctx)
+	if m.Pending() != 2 || len(ran) != 0 {
+		t.Fatalf("Expected both actions to stay queued, got %d pending and ran %v", m.Pending(), ran)
+	}
+	if m.State() != Offline {
+		t.Errorf("Expected failed replay to switch offline, got %v", m.State())
+	}
+
+	stillOffline = false
+	m.Check(ctx)
+	if m.Pending() != 0 {
+		t.Errorf("Expected queue to drain, got %d pending", m.Pending())
+	}
+	if len(ran) != 2 || ran[0] != "first" || ran[1] != "second" {
+		t.Errorf("Expected actions to run in order, got %v", ran)
+	}
+	if len(replayErrs) != 1 {
+		t.Errorf("Expected 1 replay error, got %d", len(replayErrs))
+	}
+}
+
+// TestRunStops tests that Run returns when its context is cancelled.
+func TestRunStops(t *testing.T) {
+	m := newMonitor(t, &Configuration{Probe: (&fakeProbe{}).probe, Interval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to stop after cancel")
+	}
+}
+
+// TestRunRetriesOffline tests that probes while offline follow the retry
+// policy rather than the online interval, and that a monitor is not
+// created without a policy.
+func TestRunRetriesOffline(t *testing.T) {
+	if _, err := NewMonitor(nil, nil); err == nil {
+		t.Error("Expected an error without a retry policy")
+	}
+
+	probes := make(chan struct{}, 3)
+	probe := func(ctx context.Context) error {
+		select {
+		case probes <- struct{}{}:
+		default:
+		}
+		return errUnreachable
+	}
+	m, err := NewMonitor(&Configuration{Probe: probe, Interval: time.Hour}, &backoff.Policy{Initial: time.Millisecond, Multiplier: 2})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+	for range 3 {
+		select {
+		case <-probes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected offline probes to be retried before the interval")
+		}
+	}
+	cancel()
+	<-done
+}
+
+// TestIsOffline tests classifying connection failures.
+func TestIsOffline(t *testing.T) {
+	if IsOffline(nil) || IsOffline(context.Canceled) || IsOffline(errors.New("boom")) {
+		t.Error("Expected non-network errors not to count as offline")
+	}
+	if !IsOffline(errUnreachable) {
+		t.Error("Expected net errors to count as offline")
+	}
+}
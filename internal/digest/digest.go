@@ -0,0 +1,286 @@
+// Package digest compiles announcements and new coursework across courses into a Markdown digest.
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// Source provides the data a digest is built from. *api.Client satisfies it.
+type Source interface {
+	ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error)
+	ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error)
+	ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error)
+}
+
+// Options controls which period a digest covers.
+type Options struct {
+	Since time.Duration
+	Now   time.Time
+	// Concurrency bounds how many courses are fetched at once. Defaults to 4.
+	Concurrency int
+}
+
+// CourseDigest holds the items posted in a single course during the period.
+type CourseDigest struct {
+	Course        *api.Course
+	Announcements []*api.Announcement
+	CourseWork    []*api.CourseWork
+}
+
+// SkippedCourse is a course left out of a digest because it failed to load.
+type SkippedCourse struct {
+	Course *api.Course
+	Err    error
+}
+
+// Digest is a compiled summary of activity across courses.
+type Digest struct {
+	From    time.Time
+	To      time.Time
+	Courses []*CourseDigest
+	Skipped []SkippedCourse
+}
+
+// Generate builds a digest of everything posted in active courses within the
+// period. A course that fails to load is skipped and noted in the digest;
+// only failing to list the courses is an error.
+func Generate(ctx context.Context, src Source, opts Options) (*Digest, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	d := &Digest{
+		From: now.Add(-opts.Since),
+		To:   now,
+	}
+
+	courses, err := src.ListCourses(ctx, &api.ListCoursesOptions{
+		CourseStates: []string{api.CourseStateActive},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list courses: %w", err)
+	}
+
+	var active []*api.Course
+	for _, course := range courses {
+		if course.CourseState == "" || course.CourseState == api.CourseStateActive {
+			active = append(active, course)
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	// A course's failure is kept with its result rather than returned, so
+	// it doesn't cancel the others
+	results, err := parallel.Map(ctx, concurrency, active, func(ctx context.Context, course *api.Course) (courseResult, error) {
+		cd, err := d.courseDigest(ctx, src, course)
+		if err != nil && ctx.Err() != nil {
+			return courseResult{}, ctx.Err()
+		}
+		return courseResult{digest: cd, err: err}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range results {
+		switch {
+		case r.err != nil:
+			d.Skipped = append(d.Skipped, SkippedCourse{Course: active[i], Err: r.err})
+		case len(r.digest.Announcements) > 0 || len(r.digest.CourseWork) > 0:
+			d.Courses = append(d.Courses, r.digest)
+		}
+	}
+
+	return d, nil
+}
+
+// courseResult is one course's digest, or why it could not be built.
+type courseResult struct {
+	digest *CourseDigest
+	err    error
+}
+
+// courseDigest collects the items posted in one course during the period.
+func (d *Digest) courseDigest(ctx context.Context, src Source, course *api.Course) (*CourseDigest, error) {
+	announcements, err := src.ListAnnouncements(ctx, course.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list announcements for %s: %w", course.Name, err)
+	}
+
+	coursework, err := src.ListCourseWork(ctx, course.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coursework for %s: %w", course.Name, err)
+	}
+
+	cd := &CourseDigest{Course: course}
+	for _, a := range announcements {
+		if a.State == "PUBLISHED" && d.inPeriod(a.CreateTime) {
+			cd.Announcements = append(cd.Announcements, a)
+		}
+	}
+	for _, cw := range coursework {
+		if cw.State == "PUBLISHED" && d.inPeriod(cw.CreateTime) {
+			cd.CourseWork = append(cd.CourseWork, cw)
+		}
+	}
+
+	sort.Slice(cd.Announcements, func(i, j int) bool {
+		return cd.Announcements[i].CreateTime > cd.Announcements[j].CreateTime
+	})
+	sort.Slice(cd.CourseWork, func(i, j int) bool {
+		return cd.CourseWork[i].CreateTime > cd.CourseWork[j].CreateTime
+	})
+
+	return cd, nil
+}
+
+// IsEmpty reports whether nothing was posted during the period. Skipped
+// courses may still have had activity.
+func (d *Digest) IsEmpty() bool {
+	return len(d.Courses) == 0
+}
+
+// WriteMarkdown writes the digest as Markdown.
+func (d *Digest) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Classroom digest: %s to %s\n\n", d.From.Format("Jan 2, 2006"), d.To.Format("Jan 2, 2006"))
+
+	if d.IsEmpty() && len(d.Skipped) == 0 {
+		b.WriteString("Nothing new was posted during this period.\n")
+	}
+
+	for _, cd := range d.Courses {
+		title := cd.Course.Name
+		if cd.Course.Section != "" {
+			title += " (" + cd.Course.Section + ")"
+		}
+		fmt.Fprintf(&b, "## %s\n\n", title)
+
+		if len(cd.CourseWork) > 0 {
+			b.WriteString("### New assignments\n\n")
+			for _, cw := range cd.CourseWork {
+				fmt.Fprintf(&b, "- **%s**", cw.Title)
+				if cw.DueDate != "" {
+					due := cw.DueDate
+					if cw.DueTime != "" {
+						due += " " + cw.DueTime
+					}
+					fmt.Fprintf(&b, " (due %s)", due)
+				}
+				if cw.MaxPoints > 0 {
+					fmt.Fprintf(&b, " - %d pts", cw.MaxPoints)
+				}
+				b.WriteString("\n")
+			}
+			b.WriteString("\n")
+		}
+
+		if len(cd.Announcements) > 0 {
+			b.WriteString("### Announcements\n\n")
+			for _, a := range cd.Announcements {
+				fmt.Fprintf(&b, "- %s: %s\n", formatDay(a.CreateTime), quoteText(a.Text))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(d.Skipped) > 0 {
+		b.WriteString("## Not included\n\n")
+		for _, sc := range d.Skipped {
+			fmt.Fprintf(&b, "- %s: %v\n", sc.Course.Name, sc.Err)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Markdown returns the digest rendered as Markdown.
+func (d *Digest) Markdown() (string, error) {
+	var buf bytes.Buffer
+	if err := d.WriteMarkdown(&buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WriteFile writes the Markdown digest to path.
+func (d *Digest) WriteFile(path string) error {
+	md, err := d.Markdown()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(md), 0644); err != nil {
+		return fmt.Errorf("failed to write digest: %w", err)
+	}
+	return nil
+}
+
+// ParseSince parses a period such as "7d", "2w", or "36h".
+func ParseSince(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty period")
+	}
+
+	unit := s[len(s)-1]
+	switch unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+		days := n
+		if unit == 'w' {
+			days *= 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q", s)
+	}
+	return d, nil
+}
+
+// inPeriod reports whether an RFC 3339 timestamp falls within the digest period.
+func (d *Digest) inPeriod(timestamp string) bool {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return false
+	}
+	return !t.Before(d.From) && !t.After(d.To)
+}
+
+// formatDay formats an RFC 3339 timestamp as a short date.
+func formatDay(timestamp string) string {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Local().Format("Mon Jan 2")
+}
+
+// quoteText collapses announcement text onto a single Markdown line.
+func quoteText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
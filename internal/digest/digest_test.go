@@ -0,0 +1,214 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// fakeSource serves fixed courses, announcements, and coursework.
+type fakeSource struct {
+	courses       []*api.Course
+	announcements map[string][]*api.Announcement
+	coursework    map[string][]*api.CourseWork
+	fail          map[string]error // coursework failures by course
+}
+
+func (f *fakeSource) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	return f.courses, nil
+}
+
+func (f *fakeSource) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	return f.announcements[courseID], nil
+}
+
+func (f *fakeSource) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	if err := f.fail[courseID]; err != nil {
+		return nil, err
+	}
+	return f.coursework[courseID], nil
+}
+
+// TestParseSince tests parsing digest periods.
+func TestParseSince(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	}
+
+	for input, want := range tests {
+		got, err := ParseSince(input)
+		if err != nil {
+			t.Fatalf("ParseSince(%q) failed: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ParseSince(%q) = %v, expected %v", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "d", "-3d", "soon"} {
+		if _, err := ParseSince(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+// TestGenerate tests compiling a digest for a period.
+func TestGenerate(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{
+		courses: []*api.Course{
+			{ID: "1", Name: "Biology", Section: "A", CourseState: "ACTIVE"},
+			{ID: "2", Name: "Old Course", CourseState: "ARCHIVED"},
+		},
+		announcements: map[string][]*api.Announcement{
+			"1": {
+				{ID: "a1", Text: "Lab   moved\nto Friday", State: "PUBLISHED", CreateTime: "2024-03-08T09:00:00Z"},
+				{ID: "a2", Text: "Welcome back", State: "PUBLISHED", CreateTime: "2024-01-08T09:00:00Z"},
+			},
+			"2": {
+				{ID: "a3", Text: "Archived news", State: "PUBLISHED", CreateTime: "2024-03-09T09:00:00Z"},
+			},
+		},
+		coursework: map[string][]*api.CourseWork{
+			"1": {
+				{ID: "cw1", Title: "Cell diagram", State: "PUBLISHED", DueDate: "2024-03-15", MaxPoints: 20, CreateTime: "2024-03-07T10:00:00.5Z"},
+				{ID: "cw2", Title: "Draft quiz", State: "DRAFT", CreateTime: "2024-03-09T10:00:00Z"},
+			},
+		},
+	}
+
+	d, err := Generate(context.Background(), src, Options{Since: 7 * 24 * time.Hour, Now: now})
+	if err != nil {
+		t.Fatalf("Failed to generate digest: %v", err)
+	}
+
+	if len(d.Courses) != 1 {
+		t.Fatalf("Expected 1 course in digest, got %d", len(d.Courses))
+	}
+
+	cd := d.Courses[0]
+	if len(cd.Announcements) != 1 || cd.Announcements[0].ID != "a1" {
+		t.Errorf("Expected only announcement a1, got %v", cd.Announcements)
+	}
+	if len(cd.CourseWork) != 1 || cd.CourseWork[0].ID != "cw1" {
+		t.Errorf("Expected only coursework cw1, got %v", cd.CourseWork)
+	}
+
+	md, err := d.Markdown()
+	if err != nil {
+		t.Fatalf("Failed to render digest: %v", err)
+	}
+	for _, want := range []string{"## Biology (A)", "**Cell diagram** (due 2024-03-15) - 20 pts", "Lab moved to Friday"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected digest to contain %q, got:\n%s", want, md)
+		}
+	}
+}
+
+// TestEmptyDigest tests rendering a digest with no activity.
+func TestEmptyDigest(t *testing.T) {
+	d, err := Generate(context.Background(), &fakeSource{}, Options{Since: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to generate digest: %v", err)
+	}
+
+	if !d.IsEmpty() {
+		t.Error("Expected empty digest")
+	}
+	if md, _ := d.Markdown(); !strings.Contains(md, "Nothing new") {
+		t.Error("Expected empty digest message")
+	}
+}
+
+// TestGenerateSkipsFailedCourse tests that a course that fails to load is
+// noted while the others are still included.
+func TestGenerateSkipsFailedCourse(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{
+		courses: []*api.Course{{ID: "1", Name: "Biology"}, {ID: "2", Name: "History"}},
+		announcements: map[string][]*api.Announcement{
+			"2": {{ID: "a1", Text: "Essay feedback is up", State: "PUBLISHED", CreateTime: "2024-03-08T09:00:00Z"}},
+		},
+		fail: map[string]error{"1": errors.New("backend error")},
+	}
+
+	d, err := Generate(context.Background(), src, Options{Since: 7 * 24 * time.Hour, Now: now})
+	if err != nil {
+		t.Fatalf("Failed to generate digest: %v", err)
+	}
+	if len(d.Courses) != 1 || d.Courses[0].Course.ID != "2" {
+		t.Errorf("Expected only History, got %v", d.Courses)
+	}
+	if len(d.Skipped) != 1 || d.Skipped[0].Course.ID != "1" {
+		t.Fatalf("Expected Biology to be skipped, got %v", d.Skipped)
+	}
+
+	md, err := d.Markdown()
+	if err != nil {
+		t.Fatalf("Failed to render digest: %v", err)
+	}
+	for _, want := range []string{"Essay feedback is up", "## Not included", "- Biology: failed to list coursework for Biology: backend error"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected digest to contain %q, got:\n%s", want, md)
+		}
+	}
+}
+
+// TestRunDigest tests the digest command.
+func TestRunDigest(t *testing.T) {
+	src := &fakeSource{
+		courses: []*api.Course{{ID: "1", Name: "Biology"}},
+		announcements: map[string][]*api.Announcement{
+			"1": {{ID: "a1", Text: "Lab moved", State: "PUBLISHED", CreateTime: time.Now().Add(-time.Hour).Format(time.RFC3339)}},
+		},
+	}
+	ctx := context.Background()
+	var stdout, stderr bytes.Buffer
+
+	if err := RunDigest(ctx, src, []string{"--since", "1d"}, &stdout, &stderr); err != nil {
+		t.Fatalf("digest failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Lab moved") {
+		t.Errorf("Expected the digest on stdout, got:\n%s", stdout.String())
+	}
+
+	out := filepath.Join(t.TempDir(), "digest.md")
+	stdout.Reset()
+	if err := RunDigest(ctx, src, []string{"--out", out}, &stdout, &stderr); err != nil {
+		t.Fatalf("digest failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(data), "Lab moved") {
+		t.Errorf("Expected the digest in %s, got %q (%v)", out, data, err)
+	}
+
+	if err := RunDigest(ctx, src, []string{"--since", "soon"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error for an invalid period")
+	}
+	if err := RunDigest(ctx, src, []string{"extra"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error for an unexpected argument")
+	}
+}
+
+// TestScheduled tests that the scheduled job writes a digest each run.
+func TestScheduled(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "digest.md")
+	job := Scheduled(&fakeSource{}, 7*24*time.Hour, out)
+
+	if err := job(context.Background()); err != nil {
+		t.Fatalf("job failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(data), "Nothing new") {
+		t.Errorf("Expected an empty digest in %s, got %q (%v)", out, data, err)
+	}
+}
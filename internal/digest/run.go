@@ -0,0 +1,65 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// digestUsage is the synopsis of the digest command.
+const digestUsage = "usage: digest [--since 7d] [--out file]"
+
+// RunDigest implements `classroom digest [--since 7d] [--out file]`. The
+// digest covers the period ending now and goes to stdout unless --out is
+// given. Courses that could not be loaded are listed on stderr.
+func RunDigest(ctx context.Context, src Source, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	since := fs.String("since", "7d", "`period` to cover, such as 7d, 2w or 36h")
+	out := fs.String("out", "", "write the Markdown to `file` instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New(digestUsage)
+	}
+	period, err := ParseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	d, err := Generate(ctx, src, Options{Since: period})
+	if err != nil {
+		return err
+	}
+	for _, sc := range d.Skipped {
+		fmt.Fprintf(stderr, "Skipped %s: %v\n", sc.Course.Name, sc.Err)
+	}
+
+	if *out == "" {
+		return d.WriteMarkdown(stdout)
+	}
+	if err := d.WriteFile(*out); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote digest to %s\n", *out)
+	return nil
+}
+
+// Scheduled returns the job the reminder scheduler runs for recurring
+// summaries: each run writes a digest of the since before it to path, so a
+// weekly reminder with since of 7 days sends parents and students the week
+// just past.
+func Scheduled(src Source, since time.Duration, path string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		d, err := Generate(ctx, src, Options{Since: since})
+		if err != nil {
+			return err
+		}
+		return d.WriteFile(path)
+	}
+}
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"io"
 	"os"
@@ -48,6 +49,9 @@ type Error struct {
 	Original       error
 	UserSuggestion string
 	Recoverable    bool
+	// Recovery is the action to offer instead of the one Action derives
+	// from Type, when set.
+	Recovery RecoveryAction
 }
 
 // New creates a new Error.
@@ -102,8 +106,13 @@ func (e *Error) Error() string {
 	return e.Message
 }
 
-// Is checks if the error is of a specific type.
-func (e *Error) Is(errType ErrorType) bool {
+// Unwrap returns the original error so errors.Is and errors.As can reach it.
+func (e *Error) Unwrap() error {
+	return e.Original
+}
+
+// IsType checks if the error is of a specific type.
+func (e *Error) IsType(errType ErrorType) bool {
 	return e.Type == errType
 }
 
@@ -113,6 +122,12 @@ func (e *Error) WithSuggestion(suggestion string) *Error {
 	return e
 }
 
+// WithAction sets the recovery action to offer for the error.
+func (e *Error) WithAction(action RecoveryAction) *Error {
+	e.Recovery = action
+	return e
+}
+
 // NotRecoverable marks the error as not recoverable.
 func (e *Error) NotRecoverable() *Error {
 	e.Recoverable = false
@@ -171,9 +186,68 @@ func (e *Error) GetSuggestion() string {
 	}
 }
 
+// RecoveryAction is an action the user can take to recover from an error.
+type RecoveryAction int
+
+const (
+	// ActionNone means there is nothing the user can do from the app.
+	ActionNone RecoveryAction = iota
+	// ActionRetry retries the failed operation.
+	ActionRetry
+	// ActionLogin runs the login flow again.
+	ActionLogin
+	// ActionOpenConfig opens the configuration file for editing.
+	ActionOpenConfig
+	// ActionGrantScopes asks the user to grant the scopes the token is
+	// missing.
+	ActionGrantScopes
+)
+
+// String returns a short description of the action.
+func (a RecoveryAction) String() string {
+	switch a {
+	case ActionRetry:
+		return "retry"
+	case ActionLogin:
+		return "log in again"
+	case ActionOpenConfig:
+		return "edit the configuration"
+	case ActionGrantScopes:
+		return "grant the missing permission"
+	default:
+		return ""
+	}
+}
+
+// Action returns the recovery action that matches the error's suggestion.
+func (e *Error) Action() RecoveryAction {
+	if e.Recovery != ActionNone {
+		return e.Recovery
+	}
+	switch e.Type {
+	case ErrAuth, ErrAuthExpired, ErrAuthRevoked:
+		return ActionLogin
+	case ErrConfig:
+		return ActionOpenConfig
+	}
+	if e.Recoverable {
+		return ActionRetry
+	}
+	return ActionNone
+}
+
+// As finds the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
 // IsRateLimitError checks if the error is a rate limit error.
 func IsRateLimitError(err error) bool {
-	if e, ok := err.(*Error); ok {
+	if e, ok := As(err); ok {
 		return e.Type == ErrAPIRateLimit
 	}
 	return false
@@ -181,7 +255,7 @@ func IsRateLimitError(err error) bool {
 
 // IsAuthError checks if the error is an authentication error.
 func IsAuthError(err error) bool {
-	if e, ok := err.(*Error); ok {
+	if e, ok := As(err); ok {
 		return e.Type == ErrAuth || e.Type == ErrAuthExpired || e.Type == ErrAuthRevoked
 	}
 	return false
@@ -189,7 +263,7 @@ func IsAuthError(err error) bool {
 
 // IsNotFoundError checks if the error is a not found error.
 func IsNotFoundError(err error) bool {
-	if e, ok := err.(*Error); ok {
+	if e, ok := As(err); ok {
 		return e.Type == ErrAPINotFound
 	}
 	return false
@@ -197,7 +271,7 @@ func IsNotFoundError(err error) bool {
 
 // IsRecoverable checks if the error is recoverable.
 func IsRecoverable(err error) bool {
-	if e, ok := err.(*Error); ok {
+	if e, ok := As(err); ok {
 		return e.Recoverable
 	}
 	return true
@@ -224,7 +298,7 @@ func (h *Handler) Handle(err error) *Error {
 	}
 
 	// If already an Error type, handle it
-	if e, ok := err.(*Error); ok {
+	if e, ok := As(err); ok {
 		h.onError(e)
 		return e
 	}
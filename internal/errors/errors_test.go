@@ -0,0 +1,86 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// TestErrorUnwrap tests that the original error stays reachable through
+// errors.Is and errors.As, and that wrapping nil gives nil.
+func TestErrorUnwrap(t *testing.T) {
+	err := Wrap(io.ErrUnexpectedEOF, ErrAPINetwork, "failed to list courses")
+	if err.Unwrap() != io.ErrUnexpectedEOF {
+		t.Errorf("Expected the original error, got %v", err.Unwrap())
+	}
+	if !stderrors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Expected errors.Is to reach the original error")
+	}
+	if err.Error() != "failed to list courses: unexpected EOF" {
+		t.Errorf("Expected message and cause, got %q", err.Error())
+	}
+	if New(ErrAPI, "failed").Unwrap() != nil {
+		t.Error("Expected no original error for New")
+	}
+	if Wrap(nil, ErrAPI, "failed") != nil || Wrapf(nil, ErrAPI, "failed %d", 1) != nil {
+		t.Error("Expected nil when wrapping nil")
+	}
+}
+
+// TestAs tests finding an *Error anywhere in the chain.
+func TestAs(t *testing.T) {
+	inner := New(ErrAPINotFound, "course not found")
+	tests := []struct {
+		name string
+		err  error
+		want *Error
+	}{
+		{"direct", inner, inner},
+		{"wrapped", fmt.Errorf("loading: %w", inner), inner},
+		{"plain", io.EOF, nil},
+		{"nil", nil, nil},
+	}
+
+	for _, tt := range tests {
+		got, ok := As(tt.err)
+		if got != tt.want || ok != (tt.want != nil) {
+			t.Errorf("%s: expected %v, got %v (%v)", tt.name, tt.want, got, ok)
+		}
+	}
+
+	if !IsNotFoundError(fmt.Errorf("loading: %w", inner)) {
+		t.Error("Expected a wrapped not found error to be found")
+	}
+	if !IsRecoverable(io.EOF) {
+		t.Error("Expected plain errors to be recoverable")
+	}
+}
+
+// TestErrorAction tests the recovery action derived from the type and
+// recoverability, and that WithAction overrides it.
+func TestErrorAction(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want RecoveryAction
+	}{
+		{"expired", New(ErrAuthExpired, "expired").NotRecoverable(), ActionLogin},
+		{"revoked", New(ErrAuthRevoked, "revoked"), ActionLogin},
+		{"config", New(ErrConfig, "bad config"), ActionOpenConfig},
+		{"recoverable", New(ErrAPINetwork, "offline"), ActionRetry},
+		{"not recoverable", New(ErrAPINotFound, "missing").NotRecoverable(), ActionNone},
+		{"missing scope", New(ErrAPIForbidden, "forbidden").NotRecoverable().WithAction(ActionGrantScopes), ActionGrantScopes},
+		{"override type", New(ErrConfig, "bad config").WithAction(ActionRetry), ActionRetry},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Action(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if ActionGrantScopes.String() == "" || ActionNone.String() != "" {
+		t.Error("Expected a description for every action but none")
+	}
+}
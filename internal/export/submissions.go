@@ -0,0 +1,155 @@
+// Package export writes Classroom data in formats suited to offline analysis.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cli"
+)
+
+// Source provides the data an export reads. *api.Client satisfies it.
+type Source interface {
+	ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error)
+	ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error)
+}
+
+// SubmissionRecord is one line of a submissions export.
+type SubmissionRecord struct {
+	CourseID        string `json:"course_id"`
+	CourseWorkID    string `json:"coursework_id"`
+	CourseWorkTitle string `json:"coursework_title"`
+	WorkType        string `json:"work_type"`
+	DueDate         string `json:"due_date,omitempty"`
+	DueTime         string `json:"due_time,omitempty"`
+	MaxPoints       int    `json:"max_points"`
+	SubmissionID    string `json:"submission_id"`
+	UserID          string `json:"user_id"`
+	UserName        string `json:"user_name,omitempty"`
+	State           string `json:"state"`
+	AssignedGrade   int    `json:"assigned_grade"`
+	DraftGrade      int    `json:"draft_grade"`
+	Late            bool   `json:"late"`
+	CreateTime      string `json:"create_time"`
+	UpdateTime      string `json:"update_time"`
+}
+
+// Submissions streams every submission in a course to w as JSON Lines, one
+// record per submission. It returns the number of records written.
+func Submissions(ctx context.Context, src Source, courseID string, w io.Writer) (int, error) {
+	coursework, err := src.ListCourseWork(ctx, courseID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list coursework: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	count := 0
+	for _, cw := range coursework {
+		submissions, err := src.ListStudentSubmissions(ctx, courseID, cw.ID, nil)
+		if err != nil {
+			return count, fmt.Errorf("failed to list submissions for %s: %w", cw.Title, err)
+		}
+
+		for _, s := range submissions {
+			record := NewSubmissionRecord(courseID, cw, s)
+			if err := enc.Encode(record); err != nil {
+				return count, fmt.Errorf("failed to write record: %w", err)
+			}
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// NewSubmissionRecord returns the export record of submission s to cw.
+func NewSubmissionRecord(courseID string, cw *api.CourseWork, s *api.StudentSubmission) SubmissionRecord {
+	return SubmissionRecord{
+		CourseID:        courseID,
+		CourseWorkID:    cw.ID,
+		CourseWorkTitle: cw.Title,
+		WorkType:        cw.WorkType,
+		DueDate:         cw.DueDate,
+		DueTime:         cw.DueTime,
+		MaxPoints:       cw.MaxPoints,
+		SubmissionID:    s.ID,
+		UserID:          s.UserID,
+		State:           s.State,
+		AssignedGrade:   s.AssignedGrade,
+		DraftGrade:      s.DraftGrade,
+		Late:            s.Late,
+		CreateTime:      s.CreateTime,
+		UpdateTime:      s.UpdateTime,
+	}
+}
+
+// csvHeader names the columns WriteCSV writes, in the order of the
+// SubmissionRecord fields.
+var csvHeader = []string{
+	"course_id", "coursework_id", "coursework_title", "work_type", "due_date", "due_time", "max_points",
+	"submission_id", "user_id", "user_name", "state", "assigned_grade", "draft_grade", "late", "create_time", "update_time",
+}
+
+// WriteCSV writes records to w as CSV with a header row.
+func WriteCSV(w io.Writer, records []SubmissionRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	for _, r := range records {
+		row := []string{
+			r.CourseID, r.CourseWorkID, r.CourseWorkTitle, r.WorkType, r.DueDate, r.DueTime, strconv.Itoa(r.MaxPoints),
+			r.SubmissionID, r.UserID, r.UserName, r.State, strconv.Itoa(r.AssignedGrade), strconv.Itoa(r.DraftGrade),
+			strconv.FormatBool(r.Late), r.CreateTime, r.UpdateTime,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RunSubmissionsExport implements `classroom submissions export <course>
+// --jsonl [--output file]`. Records go to stdout unless --output is given.
+func RunSubmissionsExport(ctx context.Context, src Source, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("submissions export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonl := fs.Bool("jsonl", false, "write JSON Lines (one submission per line)")
+	output := fs.String("output", "", "write to `file` instead of stdout")
+
+	courseID, err := cli.ParseCourseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if courseID == "" {
+		return fmt.Errorf("usage: submissions export <course> --jsonl [--output file]")
+	}
+	if !*jsonl {
+		return fmt.Errorf("no export format given: use --jsonl")
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	count, err := Submissions(ctx, src, courseID, w)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "Exported %d submissions\n", count)
+	return nil
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// fakeSource serves fixed coursework and submissions.
+type fakeSource struct {
+	coursework  []*api.CourseWork
+	submissions map[string][]*api.StudentSubmission
+}
+
+func (f *fakeSource) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	return f.coursework, nil
+}
+
+func (f *fakeSource) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	return f.submissions[courseWorkID], nil
+}
+
+// TestSubmissions tests streaming submissions as JSON Lines.
+func TestSubmissions(t *testing.T) {
+	src := &fakeSource{
+		coursework: []*api.CourseWork{
+			{ID: "cw1", Title: "Essay", MaxPoints: 100},
+			{ID: "cw2", Title: "Quiz", MaxPoints: 10},
+		},
+		submissions: map[string][]*api.StudentSubmission{
+			"cw1": {
+				{ID: "s1", UserID: "u1", State: "TURNED_IN", Late: true},
+				{ID: "s2", UserID: "u2", State: "RETURNED", AssignedGrade: 87},
+			},
+			"cw2": {
+				{ID: "s3", UserID: "u1", State: "NEW"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	count, err := Submissions(context.Background(), src, "c1", &buf)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 records, got %d", count)
+	}
+
+	var records []SubmissionRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r SubmissionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(records))
+	}
+	if records[0].CourseWorkTitle != "Essay" || !records[0].Late {
+		t.Errorf("Unexpected first record: %+v", records[0])
+	}
+	if records[1].AssignedGrade != 87 || records[1].MaxPoints != 100 {
+		t.Errorf("Unexpected second record: %+v", records[1])
+	}
+	if records[2].CourseID != "c1" || records[2].CourseWorkID != "cw2" {
+		t.Errorf("Unexpected third record: %+v", records[2])
+	}
+}
+
+// TestRunSubmissionsExportRequiresFormat tests rejecting a missing format flag.
+func TestRunSubmissionsExportRequiresFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := RunSubmissionsExport(context.Background(), &fakeSource{}, []string{"c1"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error without --jsonl")
+	}
+	if err := RunSubmissionsExport(context.Background(), &fakeSource{}, []string{"c1", "--jsonl"}, &stdout, &stderr); err != nil {
+		t.Errorf("Expected export to succeed, got %v", err)
+	}
+}
+
+// TestWriteCSV tests writing records as CSV under a header row.
+func TestWriteCSV(t *testing.T) {
+	cw := &api.CourseWork{ID: "cw1", Title: "Essay, final", MaxPoints: 100}
+	record := NewSubmissionRecord("c1", cw, &api.StudentSubmission{ID: "s1", UserID: "u1", State: "RETURNED", AssignedGrade: 87, Late: true})
+	record.UserName = "Ada Lovelace"
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []SubmissionRecord{record}); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected a header and 1 row, got %d rows", len(rows))
+	}
+	got := make(map[string]string)
+	for i, name := range rows[0] {
+		got[name] = rows[1][i]
+	}
+	if got["coursework_title"] != "Essay, final" || got["user_name"] != "Ada Lovelace" || got["assigned_grade"] != "87" || got["late"] != "true" {
+		t.Errorf("Unexpected row: %v", got)
+	}
+}
@@ -0,0 +1,143 @@
+package focus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// Record is the focus time logged against one assignment.
+type Record struct {
+	Focused time.Duration `json:"focused"`
+	// Sessions counts completed focus phases.
+	Sessions int       `json:"sessions"`
+	Last     time.Time `json:"last"`
+}
+
+// Log stores focus time per assignment on disk. It is safe for concurrent
+// use, and its read methods are safe to call on a nil log.
+type Log struct {
+	path   string
+	sealer *secure.Sealer
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// fileSchema versions the on-disk layout.
+var fileSchema = schema.Schema{Name: "focus log", Version: 1}
+
+// file is the on-disk layout.
+type file struct {
+	Version int `json:"version"`
+	// Records maps "courseID/courseWorkID" to its focus time.
+	Records map[string]Record `json:"records"`
+}
+
+// DefaultPath returns the default focus log location.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "google-classroom", "focus.json"), nil
+}
+
+// Open loads the focus log at path. A missing file is an empty log.
+func Open(path string) (*Log, error) {
+	return OpenSealed(path, nil)
+}
+
+// OpenSealed loads the focus log at path and keeps it encrypted with s. A
+// plaintext log from before encryption was enabled is still read.
+func OpenSealed(path string, s *secure.Sealer) (*Log, error) {
+	l := &Log{path: path, sealer: s, records: make(map[string]Record)}
+
+	data, err := secure.ReadFile(path, s)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("failed to read focus log: %w", err)
+	}
+
+	var f file
+	if _, err := fileSchema.Decode(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Records != nil {
+		l.records = f.Records
+	}
+	return l, nil
+}
+
+func key(courseID, courseWorkID string) string {
+	return courseID + "/" + courseWorkID
+}
+
+// Get returns the focus time logged against the coursework.
+func (l *Log) Get(courseID, courseWorkID string) Record {
+	if l == nil {
+		return Record{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.records[key(courseID, courseWorkID)]
+}
+
+// Add logs focused time against the coursework and saves the log. A
+// completed session also counts towards Sessions. Time under a second is
+// not logged.
+func (l *Log) Add(courseID, courseWorkID string, focused time.Duration, completed bool) error {
+	focused = focused.Truncate(time.Second)
+	if focused <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := key(courseID, courseWorkID)
+	old, existed := l.records[k]
+	r := old
+	r.Focused += focused
+	if completed {
+		r.Sessions++
+	}
+	r.Last = time.Now()
+	l.records[k] = r
+
+	if err := l.save(); err != nil {
+		if existed {
+			l.records[k] = old
+		} else {
+			delete(l.records, k)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the log through a temporary file so a crash never leaves a
+// half-written file. The caller holds l.mu.
+func (l *Log) save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create focus log directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(file{Version: fileSchema.Version, Records: l.records}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal focus log: %w", err)
+	}
+
+	if err := secure.SealFile(l.path, data, l.sealer); err != nil {
+		return fmt.Errorf("failed to save focus log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package focus
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLogPersists tests that focus time and sessions survive reopening
+// the log.
+func TestLogPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "focus.json")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	if err := l.Add("c1", "cw1", 25*time.Minute, true); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	if err := l.Add("c1", "cw1", 10*time.Minute+300*time.Millisecond, false); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	if err := l.Add("c1", "cw2", 500*time.Millisecond, false); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	r := reopened.Get("c1", "cw1")
+	if r.Focused != 35*time.Minute || r.Sessions != 1 || r.Last.IsZero() {
+		t.Errorf("Expected 35m over 1 session, got %+v", r)
+	}
+	if r := reopened.Get("c1", "cw2"); r.Focused != 0 {
+		t.Errorf("Expected time under a second not to be logged, got %+v", r)
+	}
+
+	var nilLog *Log
+	if r := nilLog.Get("c1", "cw1"); r.Focused != 0 {
+		t.Errorf("Expected an empty record from a nil log, got %+v", r)
+	}
+}
@@ -0,0 +1,153 @@
+// Package focus runs Pomodoro-style focus timers against assignments and
+// keeps a local log of the time spent on each.
+package focus
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config holds the length of the focus and break phases.
+type Config struct {
+	Focus time.Duration
+	Break time.Duration
+}
+
+// DefaultConfig returns 25 minute focus phases with 5 minute breaks.
+func DefaultConfig() Config {
+	return Config{Focus: 25 * time.Minute, Break: 5 * time.Minute}
+}
+
+// Phase is the part of the cycle the timer is in.
+type Phase int
+
+const (
+	PhaseFocus Phase = iota
+	PhaseBreak
+)
+
+// String returns "Focus" or "Break".
+func (p Phase) String() string {
+	if p == PhaseBreak {
+		return "Break"
+	}
+	return "Focus"
+}
+
+// Timer alternates focus and break phases. It is driven by the caller's
+// clock so it can be ticked from a UI loop and tested without sleeping.
+type Timer struct {
+	cfg     Config
+	phase   Phase
+	started time.Time
+	elapsed time.Duration // time in the phase before the last pause
+	paused  bool
+	cycles  int
+}
+
+// NewTimer starts a focus phase at now. Unset phase lengths use the
+// defaults.
+func NewTimer(cfg Config, now time.Time) *Timer {
+	defaults := DefaultConfig()
+	if cfg.Focus <= 0 {
+		cfg.Focus = defaults.Focus
+	}
+	if cfg.Break <= 0 {
+		cfg.Break = defaults.Break
+	}
+	return &Timer{cfg: cfg, started: now}
+}
+
+// Phase returns the current phase.
+func (t *Timer) Phase() Phase {
+	return t.phase
+}
+
+// Paused reports whether the timer is paused.
+func (t *Timer) Paused() bool {
+	return t.paused
+}
+
+// Cycles returns the number of completed focus phases.
+func (t *Timer) Cycles() int {
+	return t.cycles
+}
+
+// Remaining returns the time left in the current phase.
+func (t *Timer) Remaining(now time.Time) time.Duration {
+	return max(t.length()-t.inPhase(now), 0)
+}
+
+// Tick ends the current phase if its time is up and starts the next one.
+// It returns the focus time completed by the tick, which the caller logs,
+// and whether the phase changed.
+func (t *Timer) Tick(now time.Time) (focused time.Duration, changed bool) {
+	if t.paused || t.inPhase(now) < t.length() {
+		return 0, false
+	}
+	if t.phase == PhaseFocus {
+		focused = t.cfg.Focus
+		t.cycles++
+		t.phase = PhaseBreak
+	} else {
+		t.phase = PhaseFocus
+	}
+	t.started = now
+	t.elapsed = 0
+	return focused, true
+}
+
+// Pause stops the clock until Resume.
+func (t *Timer) Pause(now time.Time) {
+	if t.paused {
+		return
+	}
+	t.elapsed = t.inPhase(now)
+	t.paused = true
+}
+
+// Resume restarts the clock after Pause.
+func (t *Timer) Resume(now time.Time) {
+	if !t.paused {
+		return
+	}
+	t.started = now
+	t.paused = false
+}
+
+// Stop ends the timer and returns the focus time of the unfinished phase,
+// which the caller logs. Break time is not focus time.
+func (t *Timer) Stop(now time.Time) time.Duration {
+	if t.phase != PhaseFocus {
+		return 0
+	}
+	focused := min(t.inPhase(now), t.cfg.Focus)
+	t.Pause(now)
+	t.elapsed = 0
+	return focused
+}
+
+// inPhase returns the time spent in the current phase.
+func (t *Timer) inPhase(now time.Time) time.Duration {
+	if t.paused {
+		return t.elapsed
+	}
+	return t.elapsed + now.Sub(t.started)
+}
+
+// length returns the length of the current phase.
+func (t *Timer) length() time.Duration {
+	if t.phase == PhaseBreak {
+		return t.cfg.Break
+	}
+	return t.cfg.Focus
+}
+
+// FormatDuration renders a focus total compactly, e.g. "45m" or "2h05m".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
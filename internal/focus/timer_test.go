@@ -0,0 +1,65 @@
+package focus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTimerCycles tests that focus and break phases alternate and that
+// only focus time is reported.
+func TestTimerCycles(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	timer := NewTimer(Config{Focus: 25 * time.Minute, Break: 5 * time.Minute}, start)
+
+	if got := timer.Remaining(start.Add(10 * time.Minute)); got != 15*time.Minute {
+		t.Errorf("Expected 15m remaining, got %v", got)
+	}
+	if focused, changed := timer.Tick(start.Add(10 * time.Minute)); focused != 0 || changed {
+		t.Errorf("Expected no change mid-phase, got %v, %v", focused, changed)
+	}
+
+	now := start.Add(25 * time.Minute)
+	focused, changed := timer.Tick(now)
+	if focused != 25*time.Minute || !changed || timer.Phase() != PhaseBreak || timer.Cycles() != 1 {
+		t.Errorf("Expected a completed focus phase, got %v, %v, %s, %d", focused, changed, timer.Phase(), timer.Cycles())
+	}
+
+	now = now.Add(5 * time.Minute)
+	if focused, _ := timer.Tick(now); focused != 0 || timer.Phase() != PhaseFocus {
+		t.Errorf("Expected a break to log nothing and start focus, got %v, %s", focused, timer.Phase())
+	}
+}
+
+// TestTimerPause tests that paused time does not count.
+func TestTimerPause(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	timer := NewTimer(Config{}, start)
+
+	timer.Pause(start.Add(5 * time.Minute))
+	if !timer.Paused() {
+		t.Error("Expected timer to be paused")
+	}
+	if _, changed := timer.Tick(start.Add(time.Hour)); changed {
+		t.Error("Expected a paused timer not to advance")
+	}
+	timer.Resume(start.Add(time.Hour))
+
+	if got := timer.Stop(start.Add(time.Hour + 10*time.Minute)); got != 15*time.Minute {
+		t.Errorf("Expected 15m focused, got %v", got)
+	}
+}
+
+// TestFormatDuration tests compact focus totals.
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                               "0m",
+		45 * time.Minute:                "45m",
+		2*time.Hour + 5*time.Minute:     "2h05m",
+		59*time.Minute + 50*time.Second: "1h00m",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("Expected %q for %v, got %q", want, d, got)
+		}
+	}
+}
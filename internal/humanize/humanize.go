@@ -0,0 +1,15 @@
+// Package humanize formats quantities for people to read.
+package humanize
+
+import "fmt"
+
+// Bytes formats a byte count as B, KB, or MB.
+func Bytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
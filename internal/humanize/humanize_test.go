@@ -0,0 +1,24 @@
+package humanize
+
+import "testing"
+
+// TestBytes tests choosing the unit.
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{1 << 20, "1.0 MB"},
+		{5 << 30, "5120.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if got := Bytes(tt.n); got != tt.want {
+			t.Errorf("Bytes(%d): expected %q, got %q", tt.n, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+// Package keymap holds the keys bound to the TUI's actions: the shared
+// ones, such as navigation, selecting, going back, refreshing, and opening
+// in the browser, and those of each screen, such as downloading or sorting.
+// Users rebind them in the config file.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Action is an action that can be rebound.
+type Action string
+
+// Shared actions, which run on every screen.
+const (
+	Up      Action = "up"
+	Down    Action = "down"
+	Left    Action = "left"
+	Right   Action = "right"
+	Select  Action = "select"
+	Back    Action = "back"
+	Quit    Action = "quit"
+	Refresh Action = "refresh"
+	Open    Action = "open"
+	Search  Action = "search"
+)
+
+// Screen actions, which run on the screens listed in screens. The same
+// action, such as Download, may run on several screens.
+const (
+	// Course list
+	CourseActions Action = "course_actions"
+	Invitations   Action = "invitations"
+	View          Action = "view"
+	AllCourses    Action = "all_courses"
+	Account       Action = "account"
+	Cache         Action = "cache"
+	Agenda        Action = "agenda"
+	Archived      Action = "archived"
+	Accept        Action = "accept"
+	Decline       Action = "decline"
+
+	// Course and coursework
+	Delete            Action = "delete"
+	Undo              Action = "undo"
+	Attendance        Action = "attendance"
+	SyncCalendar      Action = "sync_calendar"
+	ShowAssignments   Action = "show_assignments"
+	ShowMaterials     Action = "show_materials"
+	ShowAnnouncements Action = "show_announcements"
+	ShowMine          Action = "show_mine"
+	ShowScheduled     Action = "show_scheduled"
+	ShowAll           Action = "show_all"
+	Sort              Action = "sort"
+	Checklist         Action = "checklist"
+	Focus             Action = "focus"
+	Submissions       Action = "submissions"
+	TurnIn            Action = "turn_in"
+	Attach            Action = "attach"
+	Translate         Action = "translate"
+	Download          Action = "download"
+	DownloadAll       Action = "download_all"
+	Read              Action = "read"
+	Recipients        Action = "recipients"
+	Scheduled         Action = "scheduled"
+
+	// Submissions and grading
+	History      Action = "history"
+	Rubric       Action = "rubric"
+	Grade        Action = "grade"
+	Mark         Action = "mark"
+	MarkAll      Action = "mark_all"
+	Bulk         Action = "bulk"
+	GradeAll     Action = "grade_all"
+	Filter       Action = "filter"
+	BulkReturn   Action = "bulk_return"
+	BulkGrade    Action = "bulk_grade"
+	BulkExport   Action = "bulk_export"
+	Assign       Action = "assign"
+	Return       Action = "return"
+	NextUngraded Action = "next_ungraded"
+
+	// Agenda, attendance, and the rest
+	PreviousPeriod Action = "previous_period"
+	NextPeriod     Action = "next_period"
+	NextItem       Action = "next_item"
+	Today          Action = "today"
+	Layout         Action = "layout"
+	PostCheckIn    Action = "post_check_in"
+	ClearCourse    Action = "clear_course"
+	ClearAll       Action = "clear_all"
+	ToggleCache    Action = "toggle_cache"
+	Toggle         Action = "toggle"
+	Add            Action = "add"
+	Pause          Action = "pause"
+)
+
+// action is an action's default keys and how its keys are shown in help.
+type action struct {
+	keys []string
+	// help formats the bound keys for footers, which show only the first
+	// for brevity, except for navigation with its arrows.
+	help func(keys []string) string
+}
+
+// first shows the first key, naming the space bar.
+func first(keys []string) string {
+	if keys[0] == " " {
+		return "space"
+	}
+	return keys[0]
+}
+
+// arrow shows the arrow for the arrow key, or the first key when the
+// action is no longer bound to it.
+func arrow(name, glyph string) func([]string) string {
+	return func(keys []string) string {
+		for _, k := range keys {
+			if k == name {
+				return glyph
+			}
+		}
+		return keys[0]
+	}
+}
+
+// defaults are the actions' default bindings.
+var defaults = map[Action]action{
+	Up:      {keys: []string{"up", "k"}, help: arrow("up", "↑")},
+	Down:    {keys: []string{"down", "j"}, help: arrow("down", "↓")},
+	Left:    {keys: []string{"left", "h"}, help: arrow("left", "←")},
+	Right:   {keys: []string{"right", "l"}, help: arrow("right", "→")},
+	Select:  {keys: []string{"enter"}, help: first},
+	Back:    {keys: []string{"esc", "b"}, help: func(keys []string) string { return keys[len(keys)-1] }},
+	Quit:    {keys: []string{"q"}, help: first},
+	Refresh: {keys: []string{"r"}, help: first},
+	Open:    {keys: []string{"o"}, help: first},
+	Search:  {keys: []string{"/"}, help: first},
+
+	CourseActions: {keys: []string{"c"}, help: first},
+	Invitations:   {keys: []string{"i"}, help: first},
+	View:          {keys: []string{"v"}, help: first},
+	AllCourses:    {keys: []string{"ctrl+f"}, help: first},
+	Account:       {keys: []string{"S"}, help: first},
+	Cache:         {keys: []string{"K"}, help: first},
+	Agenda:        {keys: []string{"a"}, help: first},
+	Archived:      {keys: []string{"A"}, help: first},
+	Accept:        {keys: []string{"a"}, help: first},
+	Decline:       {keys: []string{"x"}, help: first},
+
+	Delete:            {keys: []string{"x"}, help: first},
+	Undo:              {keys: []string{"u"}, help: first},
+	Attendance:        {keys: []string{"a"}, help: first},
+	SyncCalendar:      {keys: []string{"s"}, help: first},
+	ShowAssignments:   {keys: []string{"a"}, help: first},
+	ShowMaterials:     {keys: []string{"m"}, help: first},
+	ShowAnnouncements: {keys: []string{"n"}, help: first},
+	ShowMine:          {keys: []string{"y"}, help: first},
+	ShowScheduled:     {keys: []string{"p"}, help: first},
+	ShowAll:           {keys: []string{"A"}, help: first},
+	Sort:              {keys: []string{"s"}, help: first},
+	Checklist:         {keys: []string{"c"}, help: first},
+	Focus:             {keys: []string{"f"}, help: first},
+	Submissions:       {keys: []string{"s"}, help: first},
+	TurnIn:            {keys: []string{"t"}, help: first},
+	Attach:            {keys: []string{"a"}, help: first},
+	Translate:         {keys: []string{"T"}, help: first},
+	Download:          {keys: []string{"d"}, help: first},
+	DownloadAll:       {keys: []string{"A"}, help: first},
+	Read:              {keys: []string{"v"}, help: first},
+	Recipients:        {keys: []string{"S"}, help: first},
+	Scheduled:         {keys: []string{"p"}, help: first},
+
+	History:      {keys: []string{"H"}, help: first},
+	Rubric:       {keys: []string{"R"}, help: first},
+	Grade:        {keys: []string{"g"}, help: first},
+	Mark:         {keys: []string{" "}, help: first},
+	MarkAll:      {keys: []string{"a"}, help: first},
+	Bulk:         {keys: []string{"B"}, help: first},
+	GradeAll:     {keys: []string{"G"}, help: first},
+	Filter:       {keys: []string{"f"}, help: first},
+	BulkReturn:   {keys: []string{"r"}, help: first},
+	BulkGrade:    {keys: []string{"g"}, help: first},
+	BulkExport:   {keys: []string{"e"}, help: first},
+	Assign:       {keys: []string{"F"}, help: first},
+	Return:       {keys: []string{"R"}, help: first},
+	NextUngraded: {keys: []string{"N"}, help: first},
+
+	PreviousPeriod: {keys: []string{"["}, help: first},
+	NextPeriod:     {keys: []string{"]"}, help: first},
+	NextItem:       {keys: []string{"tab"}, help: first},
+	Today:          {keys: []string{"t"}, help: first},
+	Layout:         {keys: []string{"v"}, help: first},
+	PostCheckIn:    {keys: []string{"p"}, help: first},
+	ClearCourse:    {keys: []string{"x"}, help: first},
+	ClearAll:       {keys: []string{"X"}, help: first},
+	ToggleCache:    {keys: []string{"t"}, help: first},
+	Toggle:         {keys: []string{" "}, help: first},
+	Add:            {keys: []string{"a"}, help: first},
+	Pause:          {keys: []string{" ", "p"}, help: first},
+}
+
+// shared are the actions that run on every screen.
+var shared = []Action{Up, Down, Left, Right, Select, Back, Quit, Refresh, Open, Search}
+
+// screen is what a screen needs to tell its keys apart.
+type screen struct {
+	actions []Action
+	// fixed are keys the screen keeps for itself, which cannot be bound.
+	fixed []string
+	// modal screens take only their own keys, not the shared actions.
+	modal bool
+}
+
+// recovery are the keys that recover from an error; see
+// internal/ui/tea's recoverFromError.
+var recovery = []string{"L", "C", "D", "P"}
+
+// screens lists every screen's actions, so New can refuse a key that
+// would run two things on the same screen. A key may run different
+// actions on different screens.
+var screens = map[string]screen{
+	"course list":       {actions: []Action{CourseActions, Invitations, View, AllCourses, Account, Cache, Agenda, Archived}, fixed: recovery},
+	"course actions":    {actions: []Action{CourseActions}},
+	"invitations":       {actions: []Action{Invitations, Accept, Decline}},
+	"course":            {actions: []Action{Delete, Undo, Attendance, SyncCalendar}, fixed: recovery},
+	"coursework":        {actions: []Action{ShowAssignments, ShowMaterials, ShowAnnouncements, ShowMine, ShowScheduled, ShowAll, Sort, Checklist, Focus, Download, Read}, fixed: recovery},
+	"coursework detail": {actions: []Action{Submissions, TurnIn, Attach, Translate, Download, Read}, fixed: recovery},
+	"submissions":       {actions: []Action{TurnIn, Translate, Download, Read, Recipients, History, Rubric, Grade, Mark, MarkAll, Bulk, GradeAll, Filter}, fixed: recovery},
+	"submission detail": {actions: []Action{Download, DownloadAll, Read}, fixed: recovery},
+	"bulk actions":      {actions: []Action{BulkReturn, BulkGrade, BulkExport}, modal: true},
+	"grading":           {actions: []Action{Download, DownloadAll, Read, Assign, Return, NextUngraded}, fixed: gradingKeys, modal: true},
+	"announcements":     {actions: []Action{Scheduled, Recipients, Translate}, fixed: recovery},
+	"agenda":            {actions: []Action{NextItem, PreviousPeriod, NextPeriod, Today, Layout}, fixed: recovery},
+	"attendance":        {actions: []Action{PreviousPeriod, NextPeriod, PostCheckIn}, fixed: recovery},
+	"cache":             {actions: []Action{ClearCourse, ClearAll, ToggleCache}},
+	"checklist":         {actions: []Action{Toggle, Add, Delete}},
+	"focus":             {actions: []Action{Pause}},
+	"account":           {fixed: []string{"L", "D"}},
+	"student profile":   {fixed: recovery},
+}
+
+// gradingKeys are the keys the grading screen keeps for moving between
+// students and attachments, and for typing the grade.
+var gradingKeys = []string{
+	"esc", "enter", "?", "o", "up", "down", "k", "j",
+	"right", "left", "tab", "shift+tab", "n", "p",
+	"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", ".",
+	"backspace", "delete", "ctrl+u", "ctrl+w", "home", "end", "ctrl+a", "ctrl+e",
+}
+
+// KeyMap binds each action to its keys.
+type KeyMap struct {
+	bindings map[Action]key.Binding
+	// byKey maps each key bound to a shared action to the action.
+	byKey map[string]Action
+}
+
+// Default returns the default bindings.
+func Default() *KeyMap {
+	k, _ := New(nil)
+	return k
+}
+
+// New builds a key map from the defaults with per-action overrides keyed
+// by action name. "space" names the space bar. ctrl+c always quits and ?
+// always shows help, so neither can be bound, and no key may run two
+// things on the same screen.
+func New(overrides map[string][]string) (*KeyMap, error) {
+	k := &KeyMap{bindings: make(map[Action]key.Binding, len(defaults)), byKey: make(map[string]Action)}
+	keys := make(map[Action][]string, len(defaults))
+	for a, d := range defaults {
+		keys[a] = d.keys
+	}
+	for name, bound := range overrides {
+		a := Action(name)
+		if _, known := defaults[a]; !known {
+			return nil, fmt.Errorf("unknown key action %q (want one of %s)", name, strings.Join(actionNames(), ", "))
+		}
+		if len(bound) == 0 {
+			return nil, fmt.Errorf("no keys given for %q", name)
+		}
+		keys[a] = make([]string, len(bound))
+		for i, b := range bound {
+			if b == "" || b == "ctrl+c" || b == "?" {
+				return nil, fmt.Errorf("key %q cannot be bound to %q", b, name)
+			}
+			if b == "space" {
+				b = " "
+			}
+			keys[a][i] = b
+		}
+	}
+
+	for _, a := range shared {
+		for _, b := range keys[a] {
+			if other, taken := k.byKey[b]; taken {
+				return nil, fmt.Errorf("key %q is bound to both %q and %q", b, other, a)
+			}
+			k.byKey[b] = a
+		}
+	}
+	for _, name := range screenNames() {
+		if err := checkScreen(name, screens[name], keys); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range actions() {
+		k.bindings[a] = key.NewBinding(key.WithKeys(keys[a]...), key.WithHelp(defaults[a].help(keys[a]), string(a)))
+	}
+	return k, nil
+}
+
+// checkScreen returns an error when a key would run two things on the
+// named screen: two actions, or an action and a key the screen keeps.
+func checkScreen(name string, s screen, keys map[Action][]string) error {
+	// used maps each key to its action, or to "" when the screen keeps it
+	used := make(map[string]Action)
+	for _, b := range s.fixed {
+		used[b] = ""
+	}
+	var all []Action
+	if !s.modal {
+		all = append(all, shared...)
+	}
+	for _, a := range append(all, s.actions...) {
+		for _, b := range keys[a] {
+			other, taken := used[b]
+			switch {
+			case !taken:
+				used[b] = a
+			case other == "":
+				return fmt.Errorf("key %q cannot be bound to %q: the %s screen uses it", b, a, name)
+			case other != a:
+				return fmt.Errorf("key %q is bound to both %q and %q on the %s screen", b, other, a, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Binding returns the binding of an action.
+func (k *KeyMap) Binding(a Action) key.Binding {
+	return k.bindings[a]
+}
+
+// Lookup returns the shared action a key is bound to.
+func (k *KeyMap) Lookup(name string) (Action, bool) {
+	a, ok := k.byKey[name]
+	return a, ok
+}
+
+// actions lists the actions in a stable order.
+func actions() []Action {
+	var out []Action
+	for a := range defaults {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// screenNames lists the screens in a stable order.
+func screenNames() []string {
+	var names []string
+	for name := range screens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// actionNames lists the known action names.
+func actionNames() []string {
+	var names []string
+	for _, a := range actions() {
+		names = append(names, string(a))
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+package keymap
+
+import "testing"
+
+// TestDefault tests that every default key maps back to its action.
+func TestDefault(t *testing.T) {
+	if _, err := New(nil); err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+	k := Default()
+	for key, want := range map[string]Action{"k": Up, "down": Down, "b": Back, "esc": Back, "r": Refresh, "/": Search} {
+		if got, ok := k.Lookup(key); !ok || got != want {
+			t.Errorf("Expected %q bound to %q, got %q, %v", key, want, got, ok)
+		}
+	}
+	if got := k.Binding(Up).Help().Key; got != "↑" {
+		t.Errorf("Expected the up arrow in help, got %q", got)
+	}
+	if got := k.Binding(Back).Help().Key; got != "b" {
+		t.Errorf("Expected b in the help for back, got %q", got)
+	}
+}
+
+// TestOverrides tests rebinding actions and rejecting bad bindings.
+func TestOverrides(t *testing.T) {
+	k, err := New(map[string][]string{"up": {"e"}, "down": {"n"}, "show_announcements": {"N"}, "refresh": {"f5"}})
+	if err != nil {
+		t.Fatalf("Failed to build key map: %v", err)
+	}
+	if a, ok := k.Lookup("e"); !ok || a != Up {
+		t.Errorf("Expected e bound to up, got %q, %v", a, ok)
+	}
+	if _, ok := k.Lookup("k"); ok {
+		t.Error("Expected k unbound once up is rebound")
+	}
+	if got := k.Binding(Refresh).Help().Key; got != "f5" {
+		t.Errorf("Expected f5 in the help for refresh, got %q", got)
+	}
+
+	for name, overrides := range map[string]map[string][]string{
+		"unknown action":         {"jump": {"x"}},
+		"no keys":                {"up": {}},
+		"ctrl+c":                 {"back": {"ctrl+c"}},
+		"help":                   {"sort": {"?"}},
+		"conflict":               {"open": {"r"}},
+		"shared on a screen key": {"right": {"d"}},
+		"two screen actions":     {"sort": {"d"}},
+		"a recovery key":         {"sort": {"C"}},
+		"a grading key":          {"download": {"n"}},
+	} {
+		if _, err := New(overrides); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
+
+// TestScreenOverrides tests rebinding screen actions, which may share a
+// key across screens but not on one.
+func TestScreenOverrides(t *testing.T) {
+	k, err := New(map[string][]string{"sort": {"x"}, "download": {"w", "ctrl+d"}, "mark": {"space", "m"}})
+	if err != nil {
+		t.Fatalf("Failed to build key map: %v", err)
+	}
+	if got := k.Binding(Sort).Keys(); len(got) != 1 || got[0] != "x" {
+		t.Errorf("Expected sort on x, got %v", got)
+	}
+	if got := k.Binding(Download).Help().Key; got != "w" {
+		t.Errorf("Expected w in the help for download, got %q", got)
+	}
+	if got := k.Binding(Mark).Keys(); len(got) != 2 || got[0] != " " {
+		t.Errorf("Expected space to name the space bar, got %q", got)
+	}
+	if got := k.Binding(Mark).Help().Key; got != "space" {
+		t.Errorf("Expected space in the help for mark, got %q", got)
+	}
+	if _, ok := k.Lookup("x"); ok {
+		t.Error("Expected Lookup to find only shared actions")
+	}
+}
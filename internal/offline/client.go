@@ -0,0 +1,285 @@
+package offline
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/connectivity"
+)
+
+// Client wraps an api.ClassroomClient so course lists, coursework,
+// announcements, submissions, and teacher checks are answered from the
+// store whenever it holds them. Anything not stored yet is fetched and
+// kept. A forced refresh goes to the API but falls back to the store while
+// offline. Writes go to the API and mark the results they affect stale.
+// Methods that are not overridden pass through to the embedded client.
+type Client struct {
+	api.ClassroomClient
+	store *Store
+}
+
+// NewClient wraps client with the store.
+func NewClient(client api.ClassroomClient, s *Store) *Client {
+	return &Client{ClassroomClient: client, store: s}
+}
+
+// read answers q from the store, or fetches and stores it.
+func read[T any](ctx context.Context, c *Client, q Query) (T, error) {
+	var stored T
+	found, fresh := c.store.get(q, &stored)
+	if found && fresh && !cache.ForceRefresh(ctx) {
+		return stored, nil
+	}
+
+	v, err := fetch(ctx, c.ClassroomClient, q)
+	if err != nil {
+		if found && connectivity.IsOffline(err) {
+			return stored, nil
+		}
+		var zero T
+		return zero, err
+	}
+	c.store.put(q, v)
+	return v.(T), nil
+}
+
+// fetch runs q against the API.
+func fetch(ctx context.Context, client api.ClassroomClient, q Query) (any, error) {
+	switch q.Kind {
+	case KindCourses:
+		return client.ListCourses(ctx, &api.ListCoursesOptions{TeacherID: q.TeacherID, StudentID: q.StudentID})
+	case KindTeacher:
+		return client.IsTeacher(ctx, q.CourseID)
+	case KindCourseWork:
+		return client.ListCourseWork(ctx, q.CourseID, &api.ListCourseWorkOptions{States: q.States})
+	case KindAnnouncements:
+		return client.ListAnnouncements(ctx, q.CourseID, nil)
+	case KindSubmissions:
+		return client.ListStudentSubmissions(ctx, q.CourseID, q.CourseWorkID, &api.ListStudentSubmissionsOptions{UserID: q.UserID})
+	}
+	return nil, fmt.Errorf("unknown query kind %q", q.Kind)
+}
+
+// ListCourses returns courses from the store, filtering states locally.
+// Requests with a custom field selection go to the API.
+func (c *Client) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	if opts == nil {
+		opts = &api.ListCoursesOptions{}
+	}
+	if len(opts.Fields) > 0 {
+		return c.ClassroomClient.ListCourses(ctx, opts)
+	}
+
+	courses, err := read[[]*api.Course](ctx, c, Query{Kind: KindCourses, TeacherID: opts.TeacherID, StudentID: opts.StudentID})
+	if err != nil || len(opts.CourseStates) == 0 {
+		return courses, err
+	}
+	var out []*api.Course
+	for _, course := range courses {
+		if slices.Contains(opts.CourseStates, course.CourseState) {
+			out = append(out, course)
+		}
+	}
+	return out, nil
+}
+
+// GetCourse returns a course from any stored course list, or from the
+// API.
+func (c *Client) GetCourse(ctx context.Context, courseID string) (*api.Course, error) {
+	if !cache.ForceRefresh(ctx) {
+		for _, q := range c.store.Queries() {
+			var courses []*api.Course
+			if q.Kind != KindCourses {
+				continue
+			}
+			if found, fresh := c.store.get(q, &courses); !found || !fresh {
+				continue
+			}
+			for _, course := range courses {
+				if course.ID == courseID {
+					return course, nil
+				}
+			}
+		}
+	}
+	return c.ClassroomClient.GetCourse(ctx, courseID)
+}
+
+// IsTeacher reports from the store whether the user teaches the course.
+func (c *Client) IsTeacher(ctx context.Context, courseID string) (bool, error) {
+	return read[bool](ctx, c, Query{Kind: KindTeacher, CourseID: courseID})
+}
+
+// ListCourseWork returns coursework from the store, sorting it locally.
+// Requests with a custom field selection go to the API.
+func (c *Client) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	if opts == nil {
+		opts = &api.ListCourseWorkOptions{}
+	}
+	if len(opts.Fields) > 0 {
+		return c.ClassroomClient.ListCourseWork(ctx, courseID, opts)
+	}
+
+	states := opts.States
+	if len(states) == 0 {
+		states = []string{api.CourseWorkStatePublished}
+	}
+	coursework, err := read[[]*api.CourseWork](ctx, c, Query{Kind: KindCourseWork, CourseID: courseID, States: states})
+	if err != nil {
+		return nil, err
+	}
+	api.SortCourseWork(coursework, opts.OrderBy)
+	return coursework, nil
+}
+
+// ListAnnouncements returns announcements from the store. Requests with a
+// custom field selection go to the API.
+func (c *Client) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	if opts != nil && len(opts.Fields) > 0 {
+		return c.ClassroomClient.ListAnnouncements(ctx, courseID, opts)
+	}
+	return read[[]*api.Announcement](ctx, c, Query{Kind: KindAnnouncements, CourseID: courseID})
+}
+
+// ListStudentSubmissions returns submissions from the store, filtering
+// states locally. Requests with a custom field selection go to the API.
+// GetStudentSubmission is not stored: the outbox uses it to detect
+// conflicts and needs the server's copy.
+func (c *Client) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	if opts == nil {
+		opts = &api.ListStudentSubmissionsOptions{}
+	}
+	if len(opts.Fields) > 0 {
+		return c.ClassroomClient.ListStudentSubmissions(ctx, courseID, courseWorkID, opts)
+	}
+
+	q := Query{Kind: KindSubmissions, CourseID: courseID, CourseWorkID: courseWorkID, UserID: opts.UserID}
+	submissions, err := read[[]*api.StudentSubmission](ctx, c, q)
+	if err != nil || len(opts.States) == 0 {
+		return submissions, err
+	}
+	var out []*api.StudentSubmission
+	for _, sub := range submissions {
+		if slices.Contains(opts.States, sub.State) {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
+
+// CreateCourse creates a course and marks the stored course lists stale.
+func (c *Client) CreateCourse(ctx context.Context, name, section, room string) (*api.Course, error) {
+	course, err := c.ClassroomClient.CreateCourse(ctx, name, section, room)
+	if err == nil {
+		c.store.invalidate("", KindCourses)
+	}
+	return course, err
+}
+
+// PatchCourse updates a course and marks the stored course lists stale.
+func (c *Client) PatchCourse(ctx context.Context, courseID string, patch api.CoursePatch) (*api.Course, error) {
+	course, err := c.ClassroomClient.PatchCourse(ctx, courseID, patch)
+	if err == nil {
+		c.store.invalidate("", KindCourses)
+	}
+	return course, err
+}
+
+// UpdateCourseState archives or restores a course and marks the stored
+// course lists stale.
+func (c *Client) UpdateCourseState(ctx context.Context, courseID, state string) (*api.Course, error) {
+	course, err := c.ClassroomClient.UpdateCourseState(ctx, courseID, state)
+	if err == nil {
+		c.store.invalidate("", KindCourses)
+	}
+	return course, err
+}
+
+// AcceptInvitation joins a course and marks the stored course lists stale.
+func (c *Client) AcceptInvitation(ctx context.Context, invitationID string) error {
+	err := c.ClassroomClient.AcceptInvitation(ctx, invitationID)
+	if err == nil {
+		c.store.invalidate("", KindCourses)
+	}
+	return err
+}
+
+// CreateCourseWork creates coursework and marks the stored coursework
+// stale.
+func (c *Client) CreateCourseWork(ctx context.Context, courseID string, cw *api.CourseWork) (*api.CourseWork, error) {
+	created, err := c.ClassroomClient.CreateCourseWork(ctx, courseID, cw)
+	if err == nil {
+		c.store.invalidate(courseID, KindCourseWork)
+	}
+	return created, err
+}
+
+// DeleteCourseWork deletes coursework and marks the stored coursework and
+// submissions stale.
+func (c *Client) DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error {
+	err := c.ClassroomClient.DeleteCourseWork(ctx, courseID, courseWorkID)
+	if err == nil {
+		c.store.invalidate(courseID, KindCourseWork, KindSubmissions)
+	}
+	return err
+}
+
+// TurnIn turns in a submission and marks the stored submissions stale.
+func (c *Client) TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	err := c.ClassroomClient.TurnIn(ctx, courseID, courseWorkID, submissionID)
+	if err == nil {
+		c.store.invalidate(courseID, KindSubmissions)
+	}
+	return err
+}
+
+// ModifyAttachments attaches files and marks the stored submissions
+// stale.
+func (c *Client) ModifyAttachments(ctx context.Context, courseID, courseWorkID, submissionID string, driveFileIDs []string) (*api.StudentSubmission, error) {
+	sub, err := c.ClassroomClient.ModifyAttachments(ctx, courseID, courseWorkID, submissionID, driveFileIDs)
+	if err == nil {
+		c.store.invalidate(courseID, KindSubmissions)
+	}
+	return sub, err
+}
+
+// SetDraftGrade sets a draft grade and marks the stored submissions stale.
+func (c *Client) SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	sub, err := c.ClassroomClient.SetDraftGrade(ctx, courseID, courseWorkID, submissionID, grade)
+	if err == nil {
+		c.store.invalidate(courseID, KindSubmissions)
+	}
+	return sub, err
+}
+
+// SetAssignedGrade sets a grade and marks the stored submissions stale.
+func (c *Client) SetAssignedGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	sub, err := c.ClassroomClient.SetAssignedGrade(ctx, courseID, courseWorkID, submissionID, grade)
+	if err == nil {
+		c.store.invalidate(courseID, KindSubmissions)
+	}
+	return sub, err
+}
+
+// ReturnSubmission returns a submission and marks the stored submissions
+// stale.
+func (c *Client) ReturnSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	err := c.ClassroomClient.ReturnSubmission(ctx, courseID, courseWorkID, submissionID)
+	if err == nil {
+		c.store.invalidate(courseID, KindSubmissions)
+	}
+	return err
+}
+
+// DeleteAnnouncement deletes an announcement and marks the stored
+// announcements stale.
+func (c *Client) DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error {
+	err := c.ClassroomClient.DeleteAnnouncement(ctx, courseID, announcementID)
+	if err == nil {
+		c.store.invalidate(courseID, KindAnnouncements)
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+	"github.com/user/google-classroom/internal/cache"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// flakyClient counts reads and fails them with a network error while
+// offline.
+type flakyClient struct {
+	*fake.Client
+	offline bool
+	calls   int
+}
+
+var errUnreachable = apperrors.Wrap(&net.OpError{Op: "dial", Err: errors.New("unreachable")}, apperrors.ErrAPINetwork, "failed")
+
+func (f *flakyClient) read() error {
+	f.calls++
+	if f.offline {
+		return errUnreachable
+	}
+	return nil
+}
+
+func (f *flakyClient) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	if err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.Client.ListCourses(ctx, opts)
+}
+
+func (f *flakyClient) IsTeacher(ctx context.Context, courseID string) (bool, error) {
+	if err := f.read(); err != nil {
+		return false, err
+	}
+	return f.Client.IsTeacher(ctx, courseID)
+}
+
+func (f *flakyClient) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	if err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.Client.ListCourseWork(ctx, courseID, opts)
+}
+
+func (f *flakyClient) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	if err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.Client.ListAnnouncements(ctx, courseID, opts)
+}
+
+func (f *flakyClient) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	if err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.Client.ListStudentSubmissions(ctx, courseID, courseWorkID, opts)
+}
+
+// newFlakyClient returns a student in one active and one archived course.
+func newFlakyClient() *flakyClient {
+	c := fake.New("s1")
+	c.AddCourse(&api.Course{ID: "c1", Name: "Biology"})
+	c.AddCourse(&api.Course{ID: "c2", Name: "History", CourseState: api.CourseStateArchived})
+	c.AddStudent(&api.Student{CourseID: "c1", UserID: "s1"})
+	c.AddCourseWork(&api.CourseWork{ID: "cw1", CourseID: "c1", Title: "Essay", DueDate: "2026-03-02", UpdateTime: "1"})
+	c.AddCourseWork(&api.CourseWork{ID: "cw2", CourseID: "c1", Title: "Lab", DueDate: "2026-03-01", UpdateTime: "2"})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: "cw1", UserID: "s1", State: api.SubmissionStateCreated})
+	c.AddAnnouncement(&api.Announcement{CourseID: "c1", Text: "Welcome"})
+	return &flakyClient{Client: c}
+}
+
+// TestClientReadsFromStore tests that stored results are served without
+// the API, survive reopening the store, and are used while offline.
+func TestClientReadsFromStore(t *testing.T) {
+	dir := t.TempDir()
+	remote := newFlakyClient()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	client := NewClient(remote, store)
+	ctx := context.Background()
+
+	active := &api.ListCoursesOptions{CourseStates: []string{api.CourseStateActive}}
+	courses, err := client.ListCourses(ctx, active)
+	if err != nil || len(courses) != 1 || courses[0].ID != "c1" {
+		t.Fatalf("Expected the active course, got %v, %v", courses, err)
+	}
+	if all, _ := client.ListCourses(ctx, nil); len(all) != 2 {
+		t.Errorf("Expected both courses without a state filter, got %d", len(all))
+	}
+	if remote.calls != 1 {
+		t.Errorf("Expected 1 API call for both listings, got %d", remote.calls)
+	}
+
+	store.Close()
+	store, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+	client = NewClient(remote, store)
+	remote.offline = true
+
+	if course, err := client.GetCourse(ctx, "c1"); err != nil || course.Name != "Biology" {
+		t.Errorf("Expected course from the store, got %v, %v", course, err)
+	}
+	if courses, err := client.ListCourses(cache.WithForceRefresh(ctx), active); err != nil || len(courses) != 1 {
+		t.Errorf("Expected a refresh to fall back to the store offline, got %v, %v", courses, err)
+	}
+	if _, err := client.ListAnnouncements(ctx, "c1", nil); !errors.Is(err, errUnreachable) {
+		t.Errorf("Expected an unstored read to fail offline, got %v", err)
+	}
+}
+
+// TestClientSortsAndInvalidates tests local ordering and that writes make
+// the next read go to the API.
+func TestClientSortsAndInvalidates(t *testing.T) {
+	remote := newFlakyClient()
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	client := NewClient(remote, store)
+	ctx := context.Background()
+
+	client.ListCourseWork(ctx, "c1", nil)
+	cw, err := client.ListCourseWork(ctx, "c1", &api.ListCourseWorkOptions{OrderBy: api.CourseWorkOrderDueDateAsc})
+	if err != nil || len(cw) != 2 || cw[0].ID != "cw2" {
+		t.Errorf("Expected coursework by due date, got %v, %v", cw, err)
+	}
+	if remote.calls != 1 {
+		t.Errorf("Expected 1 API call, got %d", remote.calls)
+	}
+
+	opts := &api.ListStudentSubmissionsOptions{UserID: "me"}
+	subs, err := client.ListStudentSubmissions(ctx, "c1", "cw1", opts)
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("Expected 1 submission, got %v, %v", subs, err)
+	}
+	if err := client.TurnIn(ctx, "c1", "cw1", subs[0].ID); err != nil {
+		t.Fatalf("Failed to turn in: %v", err)
+	}
+	subs, err = client.ListStudentSubmissions(ctx, "c1", "cw1", opts)
+	if err != nil || subs[0].State != api.SubmissionStateTurnedIn {
+		t.Errorf("Expected the turned-in submission after invalidation, got %v, %v", subs, err)
+	}
+	if remote.calls != 3 {
+		t.Errorf("Expected the invalidated read to call the API, got %d calls", remote.calls)
+	}
+}
@@ -0,0 +1,337 @@
+// Package offline keeps a local copy of courses, coursework, announcements,
+// and submissions so every screen can be served without a connection. The
+// store remembers each read as a query and its result in a SQLite
+// database, using the cache's backend; Client answers reads from it and
+// Syncer replays the queries in the background to keep them current.
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// Query kinds.
+const (
+	KindCourses       = "courses"
+	KindTeacher       = "teacher"
+	KindCourseWork    = "coursework"
+	KindAnnouncements = "announcements"
+	KindSubmissions   = "submissions"
+)
+
+// Query describes a read in the form it is fetched from the API. Filters
+// the client applies locally, such as course states, are not part of it.
+type Query struct {
+	Kind         string   `json:"kind"`
+	CourseID     string   `json:"course_id,omitempty"`
+	CourseWorkID string   `json:"coursework_id,omitempty"`
+	TeacherID    string   `json:"teacher_id,omitempty"`
+	StudentID    string   `json:"student_id,omitempty"`
+	UserID       string   `json:"user_id,omitempty"`
+	States       []string `json:"states,omitempty"`
+}
+
+// key identifies the query within its course.
+func (q Query) key() string {
+	states := slices.Clone(q.States)
+	slices.Sort(states)
+	return strings.Join([]string{q.Kind, q.CourseWorkID, "teacher=" + q.TeacherID, "student=" + q.StudentID,
+		"user=" + q.UserID, strings.Join(states, ",")}, "|")
+}
+
+// result is a stored query and its last response.
+type result struct {
+	Version  int             `json:"version"`
+	Query    Query           `json:"query"`
+	SyncedAt time.Time       `json:"synced_at"`
+	Stale    bool            `json:"stale,omitempty"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// resultSchema versions a stored result.
+var resultSchema = schema.Schema{Name: "offline result", Version: 1}
+
+// DatabaseFile is the SQLite database the store keeps in its directory.
+const DatabaseFile = "offline.db"
+
+// Store persists query results in a SQLite database through the cache's
+// backend, one row per query keyed by its course. Results are held in
+// memory as well, as screens read them on every render. It is safe for
+// concurrent use.
+type Store struct {
+	backend cache.Backend
+	sealer  *secure.Sealer
+
+	mu      sync.Mutex
+	results map[string]map[string]*result // course ID, then query key
+}
+
+// DefaultDir returns the default store location.
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "google-classroom", "offline"), nil
+}
+
+// Open opens the store in dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	return OpenSealed(dir, nil)
+}
+
+// OpenSealed opens the store in dir and keeps it encrypted with s. Results
+// written before encryption was enabled are still read. The JSON files
+// earlier versions kept in dir are moved into the database.
+func OpenSealed(dir string, s *secure.Sealer) (*Store, error) {
+	backend, err := cache.OpenSQLite(filepath.Join(dir, DatabaseFile), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open offline store: %w", err)
+	}
+	st := &Store{backend: backend, sealer: s, results: make(map[string]map[string]*result)}
+
+	err = st.load()
+	if err == nil {
+		err = st.importFiles(dir)
+	}
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("failed to read offline store: %w", err)
+	}
+	return st, nil
+}
+
+// load reads every stored result into memory.
+func (s *Store) load() error {
+	var keys []string
+	if err := s.backend.Walk(func(e cache.StoredEntry) error {
+		keys = append(keys, e.Key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		data, err := s.backend.Get(key)
+		if err != nil {
+			return err
+		}
+		if data, err = s.sealer.Open(data); err != nil {
+			return err
+		}
+		var r result
+		if _, err := resultSchema.Decode(data, &r); err != nil {
+			return err
+		}
+		s.add(&r)
+	}
+	return nil
+}
+
+// Close releases the database.
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+// rowKey returns the database key of a course's query. Course IDs are
+// escaped, so the prefix of one never matches another's.
+func rowKey(courseID, queryKey string) string {
+	return coursePrefix(courseID) + queryKey
+}
+
+// coursePrefix returns the key prefix of every query of a course; queries
+// that span courses have an empty course ID.
+func coursePrefix(courseID string) string {
+	return url.PathEscape(courseID) + "/"
+}
+
+// add keeps r in memory. The caller holds s.mu or has s to itself.
+func (s *Store) add(r *result) {
+	results := s.results[r.Query.CourseID]
+	if results == nil {
+		results = make(map[string]*result)
+		s.results[r.Query.CourseID] = results
+	}
+	results[r.Query.key()] = r
+}
+
+// get decodes the stored result of q into v. It reports whether a result
+// was stored and whether it is fresh: not invalidated by a write.
+func (s *Store) get(q Query, v any) (found, fresh bool) {
+	s.mu.Lock()
+	r := s.results[q.CourseID][q.key()]
+	s.mu.Unlock()
+	if r == nil || json.Unmarshal(r.Data, v) != nil {
+		return false, false
+	}
+	return true, !r.Stale
+}
+
+// put stores v as the result of q.
+func (s *Store) put(q Query, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", q.Kind, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &result{Query: q, SyncedAt: time.Now(), Data: data}
+	s.add(r)
+	return s.save(r)
+}
+
+// invalidate marks the course's results of the given kinds stale, so they
+// are fetched again while online and still served while offline. No kinds
+// marks every result of the course.
+func (s *Store) invalidate(courseID string, kinds ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.results[courseID] {
+		if len(kinds) == 0 || slices.Contains(kinds, r.Query.Kind) {
+			r.Stale = true
+			s.save(r)
+		}
+	}
+}
+
+// remove drops the result of q.
+func (s *Store) remove(q Query) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.results[q.CourseID], q.key())
+	s.backend.Delete(rowKey(q.CourseID, q.key()))
+}
+
+// removeCourse drops every result of the course.
+func (s *Store) removeCourse(courseID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.results, courseID)
+	s.backend.DeletePrefix(coursePrefix(courseID))
+}
+
+// Queries returns every stored query, account-wide ones first.
+func (s *Store) Queries() []Query {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	courseIDs := make([]string, 0, len(s.results))
+	for id := range s.results {
+		courseIDs = append(courseIDs, id)
+	}
+	slices.Sort(courseIDs)
+
+	var out []Query
+	for _, id := range courseIDs {
+		keys := make([]string, 0, len(s.results[id]))
+		for k := range s.results[id] {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			out = append(out, s.results[id][k].Query)
+		}
+	}
+	return out
+}
+
+// LastSync returns when any result was last fetched, or the zero time for
+// an empty store.
+func (s *Store) LastSync() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last time.Time
+	for _, results := range s.results {
+		for _, r := range results {
+			if r.SyncedAt.After(last) {
+				last = r.SyncedAt
+			}
+		}
+	}
+	return last
+}
+
+// save writes r to the database. The caller holds s.mu.
+func (s *Store) save(r *result) error {
+	r.Version = resultSchema.Version
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal offline store: %w", err)
+	}
+	if data, err = s.sealer.Seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt offline store: %w", err)
+	}
+	if err := s.backend.Put(rowKey(r.Query.CourseID, r.Query.key()), data, r.SyncedAt, time.Time{}); err != nil {
+		return fmt.Errorf("failed to save offline store: %w", err)
+	}
+	return nil
+}
+
+// legacySchema versions the per-course JSON files of earlier versions.
+var legacySchema = schema.Schema{Name: "offline store", Version: 1}
+
+// legacyFile is the layout of those files: each course had its own, and
+// queries that span courses lived in courses.json.
+type legacyFile struct {
+	Version int                `json:"version"`
+	Results map[string]*result `json:"results"`
+}
+
+// importFiles moves the results of the JSON files earlier versions kept in
+// dir into the database, unless it already holds a newer one, and deletes
+// the files. A missing directory has none.
+func (s *Store) importFiles(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !legacyFileName(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := secure.ReadFile(path, s.sealer)
+		if err != nil {
+			return err
+		}
+		var f legacyFile
+		if _, err := legacySchema.Decode(data, &f); err != nil {
+			return err
+		}
+		for _, r := range f.Results {
+			if stored := s.results[r.Query.CourseID][r.Query.key()]; stored != nil && !stored.SyncedAt.Before(r.SyncedAt) {
+				continue
+			}
+			s.add(r)
+			if err := s.save(r); err != nil {
+				return err
+			}
+		}
+		os.Remove(path)
+	}
+	return nil
+}
+
+// legacyFileName reports whether name is one of those files:
+// courses.json, or course-<escaped ID>.json.
+func legacyFileName(name string) bool {
+	if name == "courses.json" {
+		return true
+	}
+	escaped, ok := strings.CutPrefix(name, "course-")
+	return ok && strings.HasSuffix(escaped, ".json")
+}
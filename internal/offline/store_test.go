@@ -0,0 +1,57 @@
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// TestStoreImportsFiles tests that the per-course JSON files of earlier
+// versions are moved into the database.
+func TestStoreImportsFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"courses.json":    `{"version": 1, "results": {"courses": {"query": {"kind": "courses"}, "synced_at": "2026-01-05T10:00:00Z", "data": [{"id": "c1", "name": "Biology"}]}}}`,
+		"course-c1.json":  `{"version": 1, "results": {"teacher": {"query": {"kind": "teacher", "course_id": "c1"}, "synced_at": "2026-01-05T10:00:00Z", "stale": true, "data": true}}}`,
+		"unrelated.json":  `{}`,
+		"course-c2.json~": `not a store file`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	store.Close()
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if imported := legacyFileName(name); imported != os.IsNotExist(err) {
+			t.Errorf("%s: expected removed %v, got %v", name, imported, err)
+		}
+	}
+
+	// The results survive reopening without the files
+	store, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	var courses []*api.Course
+	if found, fresh := store.get(Query{Kind: KindCourses}, &courses); !found || !fresh || len(courses) != 1 || courses[0].Name != "Biology" {
+		t.Errorf("Expected the stored courses, got %v (found %v, fresh %v)", courses, found, fresh)
+	}
+	var teacher bool
+	if found, fresh := store.get(Query{Kind: KindTeacher, CourseID: "c1"}, &teacher); !found || fresh || !teacher {
+		t.Errorf("Expected the stale teacher check, got %v (found %v, fresh %v)", teacher, found, fresh)
+	}
+	if want := "2026-01-05T10:00:00Z"; store.LastSync().UTC().Format("2006-01-02T15:04:05Z") != want {
+		t.Errorf("Expected last sync %s, got %v", want, store.LastSync())
+	}
+}
@@ -0,0 +1,206 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+	"github.com/user/google-classroom/internal/connectivity"
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// Configuration holds background sync settings.
+type Configuration struct {
+	// Interval is the time between sync rounds.
+	Interval time.Duration
+	// Concurrency bounds how many courses are synced at once.
+	Concurrency int
+	// Paused, when set, skips rounds while it returns true, e.g. while
+	// offline or conserving the daily API budget.
+	Paused func() bool
+	// Wake starts a round early whenever it receives, e.g. from
+	// connectivity.Monitor.Subscribe when the connection comes back.
+	Wake <-chan connectivity.State
+	// OnError receives the error of a failed round. Nil discards it.
+	OnError func(error)
+}
+
+// DefaultConfiguration returns the default sync configuration.
+func DefaultConfiguration() *Configuration {
+	return &Configuration{
+		Interval:    15 * time.Minute,
+		Concurrency: 4,
+	}
+}
+
+// Syncer reconciles the store with the API in the background.
+type Syncer struct {
+	client api.ClassroomClient
+	store  *Store
+	cfg    Configuration
+	retry  *backoff.Policy
+}
+
+// NewSyncer creates a syncer that refreshes s from client, which must be
+// the API client rather than the offline Client wrapping it. A failed
+// round is retried under retry, which is required, before the next
+// Interval comes round.
+func NewSyncer(client api.ClassroomClient, s *Store, cfg *Configuration, retry *backoff.Policy) (*Syncer, error) {
+	if retry == nil {
+		return nil, errors.New("syncer needs a retry policy")
+	}
+	if cfg == nil {
+		cfg = DefaultConfiguration()
+	}
+	c := *cfg
+	if c.Interval <= 0 {
+		c.Interval = DefaultConfiguration().Interval
+	}
+	return &Syncer{client: client, store: s, cfg: c, retry: retry}, nil
+}
+
+// Run syncs immediately and then every Interval until ctx is done. After a
+// round fails with an error the retry policy allows, the next round comes
+// after the policy's backoff instead, capped at Interval.
+func (s *Syncer) Run(ctx context.Context) {
+	failures := 0
+	for {
+		wait := s.cfg.Interval
+		if s.cfg.Paused == nil || !s.cfg.Paused() {
+			err := s.Sync(ctx)
+			if err != nil && ctx.Err() == nil && s.cfg.OnError != nil {
+				s.cfg.OnError(err)
+			}
+			if s.retry.ShouldRetry(err) {
+				wait = min(s.retry.Interval(failures), wait)
+				failures++
+			} else {
+				failures = 0
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-s.cfg.Wake:
+			timer.Stop()
+		}
+	}
+}
+
+// Sync refreshes every stored query. For each active course it then
+// stores the coursework, announcements, and submissions the screens read
+// by default, so courses that were never opened still work offline.
+// Courses that are no longer active are removed from the store.
+func (s *Syncer) Sync(ctx context.Context) error {
+	accountQueries := []Query{{Kind: KindCourses}}
+	byCourse := make(map[string][]Query)
+	for _, q := range s.store.Queries() {
+		switch {
+		case q.CourseID != "":
+			byCourse[q.CourseID] = append(byCourse[q.CourseID], q)
+		case q.key() != accountQueries[0].key():
+			accountQueries = append(accountQueries, q)
+		}
+	}
+
+	var courseIDs []string
+	for _, q := range accountQueries {
+		courses, err := s.refresh(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, course := range courses.([]*api.Course) {
+			if course.CourseState == api.CourseStateActive && !slices.Contains(courseIDs, course.ID) {
+				courseIDs = append(courseIDs, course.ID)
+			}
+		}
+	}
+	for courseID := range byCourse {
+		if !slices.Contains(courseIDs, courseID) {
+			s.store.removeCourse(courseID)
+		}
+	}
+
+	_, err := parallel.Map(ctx, s.cfg.Concurrency, courseIDs, func(ctx context.Context, courseID string) (struct{}, error) {
+		return struct{}{}, s.syncCourse(ctx, courseID, byCourse[courseID])
+	})
+	return err
+}
+
+// syncCourse refreshes a course's stored queries and adds the default
+// ones that are missing.
+func (s *Syncer) syncCourse(ctx context.Context, courseID string, stored []Query) error {
+	done := make(map[string]bool)
+	run := func(q Query) (any, error) {
+		done[q.key()] = true
+		return s.refresh(ctx, q)
+	}
+
+	v, err := run(Query{Kind: KindTeacher, CourseID: courseID})
+	if err != nil {
+		return err
+	}
+	isTeacher := v.(bool)
+
+	states := []string{api.CourseWorkStatePublished}
+	if isTeacher {
+		states = append(states, api.CourseWorkStateDraft)
+	}
+	v, err = run(Query{Kind: KindCourseWork, CourseID: courseID, States: states})
+	if err != nil {
+		return err
+	}
+	coursework := v.([]*api.CourseWork)
+
+	if _, err := run(Query{Kind: KindAnnouncements, CourseID: courseID}); err != nil {
+		return err
+	}
+
+	userID := "me"
+	if isTeacher {
+		userID = ""
+	}
+	for _, cw := range coursework {
+		if cw.State != api.CourseWorkStatePublished {
+			continue
+		}
+		if _, err := run(Query{Kind: KindSubmissions, CourseID: courseID, CourseWorkID: cw.ID, UserID: userID}); err != nil {
+			return err
+		}
+	}
+
+	// Queries for coursework that was deleted since are dropped.
+	for _, q := range stored {
+		if done[q.key()] {
+			continue
+		}
+		if _, err := run(q); err != nil {
+			if apperrors.IsNotFoundError(err) {
+				s.store.remove(q)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// refresh fetches q from the API and stores the result.
+func (s *Syncer) refresh(ctx context.Context, q Query) (any, error) {
+	v, err := fetch(ctx, s.client, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.put(q, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,121 @@
+package offline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+)
+
+// TestSync tests that a sync stores every screen of the active courses
+// for offline use and drops courses that are no longer active.
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	remote := newFlakyClient()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	syncer, err := NewSyncer(remote, store, nil, backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to create syncer: %v", err)
+	}
+
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if store.LastSync().IsZero() {
+		t.Error("Expected a sync time")
+	}
+
+	remote.offline = true
+	client := NewClient(remote, store)
+	if teacher, err := client.IsTeacher(ctx, "c1"); err != nil || teacher {
+		t.Errorf("Expected student role offline, got %v, %v", teacher, err)
+	}
+	if cw, err := client.ListCourseWork(ctx, "c1", nil); err != nil || len(cw) != 2 {
+		t.Errorf("Expected coursework offline, got %v, %v", cw, err)
+	}
+	if a, err := client.ListAnnouncements(ctx, "c1", nil); err != nil || len(a) != 1 {
+		t.Errorf("Expected announcements offline, got %v, %v", a, err)
+	}
+	if subs, err := client.ListStudentSubmissions(ctx, "c1", "cw1", &api.ListStudentSubmissionsOptions{UserID: "me"}); err != nil || len(subs) != 1 {
+		t.Errorf("Expected submissions offline, got %v, %v", subs, err)
+	}
+	if err := syncer.Sync(ctx); err == nil {
+		t.Error("Expected a sync to fail offline")
+	}
+
+	remote.offline = false
+	if _, err := remote.UpdateCourseState(ctx, "c1", api.CourseStateArchived); err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	for _, q := range store.Queries() {
+		if q.CourseID == "c1" {
+			t.Errorf("Expected the archived course to be removed, got %+v", q)
+		}
+	}
+
+	store.Close()
+	store, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	for _, q := range store.Queries() {
+		if q.CourseID == "c1" {
+			t.Errorf("Expected the archived course to stay removed, got %+v", q)
+		}
+	}
+}
+
+// TestSyncerRunRetries tests that a failed round is retried under the
+// retry policy rather than after the full interval, and that a syncer is
+// not created without a policy.
+func TestSyncerRunRetries(t *testing.T) {
+	remote := newFlakyClient()
+	remote.offline = true
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := NewSyncer(remote, store, nil, nil); err == nil {
+		t.Error("Expected an error without a retry policy")
+	}
+
+	failed := make(chan error, 3)
+	cfg := &Configuration{Interval: time.Hour, OnError: func(err error) {
+		select {
+		case failed <- err:
+		default:
+		}
+	}}
+	syncer, err := NewSyncer(remote, store, cfg, &backoff.Policy{Initial: time.Millisecond, Multiplier: 2})
+	if err != nil {
+		t.Fatalf("Failed to create syncer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		syncer.Run(ctx)
+		close(done)
+	}()
+	for range 3 {
+		select {
+		case <-failed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected failed rounds to be retried before the interval")
+		}
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,342 @@
+// Package outbox keeps write actions made while offline in a durable local
+// queue and replays them, with conflict checks, once the API is reachable.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+	"github.com/user/google-classroom/internal/connectivity"
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// Kind is the type of a queued action.
+type Kind string
+
+// Queued action kinds.
+const (
+	KindTurnIn             Kind = "turn_in"
+	KindDraftGrade         Kind = "draft_grade"
+	KindDeleteCourseWork   Kind = "delete_coursework"
+	KindDeleteAnnouncement Kind = "delete_announcement"
+	KindRemoveStudent      Kind = "remove_student"
+	KindRemoveTeacher      Kind = "remove_teacher"
+)
+
+// Entry is one queued action.
+type Entry struct {
+	ID       string `json:"id"`
+	Kind     Kind   `json:"kind"`
+	CourseID string `json:"course_id"`
+	// CourseWorkID is set for submission actions.
+	CourseWorkID string `json:"coursework_id,omitempty"`
+	// TargetID is the submission, coursework, announcement, or user acted on.
+	TargetID string  `json:"target_id"`
+	Grade    float64 `json:"grade,omitempty"`
+	// Label describes the action for the UI, e.g. `Turn in "Essay 2"`.
+	Label string `json:"label"`
+	// BaseUpdateTime is the target's updateTime when the action was queued.
+	// A different value at replay time means someone else changed it.
+	BaseUpdateTime string    `json:"base_update_time,omitempty"`
+	QueuedAt       time.Time `json:"queued_at"`
+}
+
+// Skipped is a queued action that was dropped during replay.
+type Skipped struct {
+	Entry  Entry
+	Reason string
+	// Conflict is set when the target changed on the server after the
+	// action was queued.
+	Conflict bool
+}
+
+// Result summarizes a replay.
+type Result struct {
+	Applied []Entry
+	Skipped []Skipped
+}
+
+// Executor runs queued actions. *api.Client satisfies it.
+type Executor interface {
+	GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*api.StudentSubmission, error)
+	TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error
+	SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error)
+	GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*api.CourseWork, error)
+	DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error
+	DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error
+	RemoveStudent(ctx context.Context, courseID, userID string) error
+	RemoveTeacher(ctx context.Context, courseID, userID string) error
+}
+
+// Outbox is a durable queue of actions stored as a JSON file.
+type Outbox struct {
+	path   string
+	sealer *secure.Sealer
+	retry  *backoff.Policy
+
+	mu      sync.Mutex
+	entries []Entry
+	seq     int
+
+	replayMu sync.Mutex
+}
+
+// fileSchema versions the on-disk layout.
+var fileSchema = schema.Schema{Name: "outbox", Version: 1}
+
+// file is the on-disk layout.
+type file struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// DefaultPath returns the default outbox location.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "google-classroom", "outbox.json"), nil
+}
+
+// Open loads the outbox at path. A missing file is an empty outbox.
+// Actions that fail with a rate limit or a server error during replay are
+// retried under retry, which is required.
+func Open(path string, retry *backoff.Policy) (*Outbox, error) {
+	return OpenSealed(path, nil, retry)
+}
+
+// OpenSealed loads the outbox at path and keeps it encrypted with s. A
+// plaintext outbox from before encryption was enabled is still read.
+func OpenSealed(path string, s *secure.Sealer, retry *backoff.Policy) (*Outbox, error) {
+	if retry == nil {
+		return nil, errors.New("outbox needs a retry policy")
+	}
+	o := &Outbox{path: path, sealer: s, retry: retry.WithRetryable(isTransient)}
+
+	data, err := secure.ReadFile(path, s)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return o, nil
+		}
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	// A newer release's queue is refused rather than rewritten in a
+	// format it may not read back.
+	var f file
+	if _, err := fileSchema.Decode(data, &f); err != nil {
+		return nil, err
+	}
+	o.entries = f.Entries
+	return o, nil
+}
+
+// Add queues an action and saves the outbox. ID and QueuedAt are filled in.
+func (o *Outbox) Add(e Entry) (Entry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	now := time.Now()
+	e.ID = strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.Itoa(o.seq)
+	e.QueuedAt = now
+	o.entries = append(o.entries, e)
+
+	if err := o.save(); err != nil {
+		o.entries = o.entries[:len(o.entries)-1]
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Entries returns the queued actions in order.
+func (o *Outbox) Entries() []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Entry(nil), o.entries...)
+}
+
+// Len returns the number of queued actions.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// Has reports whether an action of kind is queued for targetID.
+func (o *Outbox) Has(kind Kind, targetID string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, e := range o.entries {
+		if e.Kind == kind && e.TargetID == targetID {
+			return true
+		}
+	}
+	return false
+}
+
+// Replay runs the queued actions in order. An action that fails with a
+// rate limit or a server error is retried under the outbox's policy.
+// Applied and skipped actions are removed; if the API cannot be reached or
+// keeps failing, replay stops and the rest stay queued, and the error is
+// returned.
+func (o *Outbox) Replay(ctx context.Context, exec Executor) (*Result, error) {
+	o.replayMu.Lock()
+	defer o.replayMu.Unlock()
+
+	res := &Result{}
+	for _, e := range o.Entries() {
+		err := backoff.Do(ctx, o.retry, func() error {
+			return apply(ctx, exec, e)
+		})
+
+		var conflict *conflictError
+		switch {
+		case err == nil:
+			res.Applied = append(res.Applied, e)
+		case ctx.Err() != nil || connectivity.IsOffline(err) || isTransient(err):
+			return res, err
+		case errors.As(err, &conflict):
+			res.Skipped = append(res.Skipped, Skipped{Entry: e, Reason: conflict.reason, Conflict: true})
+		default:
+			res.Skipped = append(res.Skipped, Skipped{Entry: e, Reason: err.Error()})
+		}
+
+		if err := o.remove(e.ID); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// remove drops the entry with id and saves the outbox.
+func (o *Outbox) remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, e := range o.entries {
+		if e.ID == id {
+			o.entries = append(o.entries[:i:i], o.entries[i+1:]...)
+			break
+		}
+	}
+	return o.save()
+}
+
+// save writes the outbox through a temporary file so a crash never leaves
+// a truncated queue. The caller holds o.mu.
+func (o *Outbox) save() error {
+	if err := os.MkdirAll(filepath.Dir(o.path), 0700); err != nil {
+		return fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(file{Version: fileSchema.Version, Entries: o.entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox: %w", err)
+	}
+
+	if err := secure.SealFile(o.path, data, o.sealer); err != nil {
+		return fmt.Errorf("failed to save outbox: %w", err)
+	}
+	return nil
+}
+
+// conflictError means the target changed on the server after the action
+// was queued, so the action is not applied.
+type conflictError struct {
+	reason string
+}
+
+func (e *conflictError) Error() string {
+	return e.reason
+}
+
+// apply runs one action after checking it still makes sense.
+func apply(ctx context.Context, exec Executor, e Entry) error {
+	switch e.Kind {
+	case KindTurnIn:
+		sub, err := exec.GetStudentSubmission(ctx, e.CourseID, e.CourseWorkID, e.TargetID)
+		if err != nil {
+			return err
+		}
+		if sub.State == api.SubmissionStateTurnedIn {
+			return nil
+		}
+		if !sub.CanTurnIn() {
+			return &conflictError{reason: fmt.Sprintf("submission is now %s", sub.State)}
+		}
+		return exec.TurnIn(ctx, e.CourseID, e.CourseWorkID, e.TargetID)
+
+	case KindDraftGrade:
+		sub, err := exec.GetStudentSubmission(ctx, e.CourseID, e.CourseWorkID, e.TargetID)
+		if err != nil {
+			return err
+		}
+		if changedSince(e, sub.UpdateTime) {
+			return &conflictError{reason: "submission changed on the server"}
+		}
+		_, err = exec.SetDraftGrade(ctx, e.CourseID, e.CourseWorkID, e.TargetID, e.Grade)
+		return err
+
+	case KindDeleteCourseWork:
+		cw, err := exec.GetCourseWork(ctx, e.CourseID, e.TargetID)
+		if apperrors.IsNotFoundError(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if changedSince(e, cw.UpdateTime) {
+			return &conflictError{reason: "coursework was edited on the server"}
+		}
+		return ignoreNotFound(exec.DeleteCourseWork(ctx, e.CourseID, e.TargetID))
+
+	case KindDeleteAnnouncement:
+		return ignoreNotFound(exec.DeleteAnnouncement(ctx, e.CourseID, e.TargetID))
+
+	case KindRemoveStudent:
+		return ignoreNotFound(exec.RemoveStudent(ctx, e.CourseID, e.TargetID))
+
+	case KindRemoveTeacher:
+		return ignoreNotFound(exec.RemoveTeacher(ctx, e.CourseID, e.TargetID))
+
+	default:
+		return fmt.Errorf("unknown action %q", e.Kind)
+	}
+}
+
+// isTransient reports whether an action failed for a reason that may pass
+// on a later attempt: rate limiting or a server error. Each check and
+// write is made again on retry, so an action that went through before the
+// error is not repeated.
+func isTransient(err error) bool {
+	if e, ok := apperrors.As(err); ok {
+		return e.Type == apperrors.ErrAPIRateLimit || e.Type == apperrors.ErrAPIServerError
+	}
+	return false
+}
+
+// changedSince reports whether the target's updateTime moved after the
+// action was queued.
+func changedSince(e Entry, updateTime string) bool {
+	return e.BaseUpdateTime != "" && updateTime != e.BaseUpdateTime
+}
+
+// ignoreNotFound treats a missing target as already deleted.
+func ignoreNotFound(err error) error {
+	if apperrors.IsNotFoundError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,241 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/backoff"
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"github.com/user/google-classroom/internal/schema"
+)
+
+// fakeExecutor serves fixed submissions and coursework and records calls.
+type fakeExecutor struct {
+	submissions map[string]*api.StudentSubmission
+	coursework  map[string]*api.CourseWork
+	offline     bool
+	// busy is how many more turn-ins fail with a server error.
+	busy  int
+	calls []string
+}
+
+var errUnreachable = apperrors.Wrap(&net.OpError{Op: "dial", Err: errors.New("unreachable")}, apperrors.ErrAPINetwork, "failed")
+
+func (f *fakeExecutor) GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*api.StudentSubmission, error) {
+	if f.offline {
+		return nil, errUnreachable
+	}
+	return f.submissions[submissionID], nil
+}
+
+func (f *fakeExecutor) TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	f.calls = append(f.calls, "turn_in:"+submissionID)
+	if f.busy > 0 {
+		f.busy--
+		return apperrors.New(apperrors.ErrAPIServerError, "backend error")
+	}
+	return nil
+}
+
+func (f *fakeExecutor) SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	f.calls = append(f.calls, "draft_grade:"+submissionID)
+	return f.submissions[submissionID], nil
+}
+
+func (f *fakeExecutor) GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*api.CourseWork, error) {
+	cw, ok := f.coursework[courseWorkID]
+	if !ok {
+		return nil, apperrors.New(apperrors.ErrAPINotFound, "not found")
+	}
+	return cw, nil
+}
+
+func (f *fakeExecutor) DeleteCourseWork(ctx context.Context, courseID, courseWorkID string) error {
+	f.calls = append(f.calls, "delete_coursework:"+courseWorkID)
+	return nil
+}
+
+func (f *fakeExecutor) DeleteAnnouncement(ctx context.Context, courseID, announcementID string) error {
+	f.calls = append(f.calls, "delete_announcement:"+announcementID)
+	return nil
+}
+
+func (f *fakeExecutor) RemoveStudent(ctx context.Context, courseID, userID string) error {
+	return apperrors.New(apperrors.ErrAPIForbidden, "forbidden")
+}
+
+func (f *fakeExecutor) RemoveTeacher(ctx context.Context, courseID, userID string) error {
+	f.calls = append(f.calls, "remove_teacher:"+userID)
+	return nil
+}
+
+// TestAddPersists tests that queued actions survive reopening the outbox.
+func TestAddPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := Open(path, backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to open outbox: %v", err)
+	}
+
+	e, err := o.Add(Entry{Kind: KindTurnIn, CourseID: "c1", CourseWorkID: "cw1", TargetID: "s1", Label: "Turn in"})
+	if err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if e.ID == "" || e.QueuedAt.IsZero() {
+		t.Error("Expected ID and queue time to be set")
+	}
+
+	reopened, err := Open(path, backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to reopen outbox: %v", err)
+	}
+	if reopened.Len() != 1 || !reopened.Has(KindTurnIn, "s1") {
+		t.Errorf("Expected queued turn-in after reopening, got %v", reopened.Entries())
+	}
+}
+
+// TestReplay tests applying, skipping, and conflict detection.
+func TestReplay(t *testing.T) {
+	o, err := Open(filepath.Join(t.TempDir(), "outbox.json"), backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to open outbox: %v", err)
+	}
+
+	exec := &fakeExecutor{
+		submissions: map[string]*api.StudentSubmission{
+			"s1": {ID: "s1", State: api.SubmissionStateCreated},
+			"s2": {ID: "s2", State: api.SubmissionStateReturned},
+			"s3": {ID: "s3", State: api.SubmissionStateTurnedIn, UpdateTime: "2024-03-02T10:00:00Z"},
+		},
+		coursework: map[string]*api.CourseWork{
+			"cw1": {ID: "cw1", UpdateTime: "2024-03-01T10:00:00Z"},
+		},
+	}
+
+	for _, e := range []Entry{
+		{Kind: KindTurnIn, TargetID: "s1"},
+		{Kind: KindTurnIn, TargetID: "s2"},
+		{Kind: KindDraftGrade, TargetID: "s3", Grade: 9, BaseUpdateTime: "2024-03-01T10:00:00Z"},
+		{Kind: KindDeleteCourseWork, TargetID: "cw1", BaseUpdateTime: "2024-03-01T10:00:00Z"},
+		{Kind: KindDeleteCourseWork, TargetID: "gone"},
+		{Kind: KindRemoveStudent, TargetID: "u1"},
+	} {
+		if _, err := o.Add(e); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+
+	res, err := o.Replay(context.Background(), exec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if len(res.Applied) != 3 {
+		t.Errorf("Expected 3 applied actions, got %d", len(res.Applied))
+	}
+	if len(res.Skipped) != 3 {
+		t.Fatalf("Expected 3 skipped actions, got %d", len(res.Skipped))
+	}
+	if !res.Skipped[0].Conflict || !res.Skipped[1].Conflict || res.Skipped[2].Conflict {
+		t.Errorf("Expected two conflicts and one failure, got %+v", res.Skipped)
+	}
+	want := []string{"turn_in:s1", "delete_coursework:cw1"}
+	if len(exec.calls) != len(want) || exec.calls[0] != want[0] || exec.calls[1] != want[1] {
+		t.Errorf("Expected calls %v, got %v", want, exec.calls)
+	}
+	if o.Len() != 0 {
+		t.Errorf("Expected empty outbox, got %d entries", o.Len())
+	}
+}
+
+// TestReplayOffline tests that replay stops and keeps entries when offline.
+func TestReplayOffline(t *testing.T) {
+	o, err := Open(filepath.Join(t.TempDir(), "outbox.json"), backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to open outbox: %v", err)
+	}
+	o.Add(Entry{Kind: KindTurnIn, TargetID: "s1"})
+	o.Add(Entry{Kind: KindRemoveTeacher, TargetID: "u1"})
+
+	exec := &fakeExecutor{offline: true}
+	if _, err := o.Replay(context.Background(), exec); err == nil {
+		t.Error("Expected offline error")
+	}
+	if o.Len() != 2 {
+		t.Errorf("Expected both entries to stay queued, got %d", o.Len())
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("Expected no later actions to run, got %v", exec.calls)
+	}
+}
+
+// TestReplayRetries tests that an action failing with a server error is
+// retried, and kept queued when it keeps failing.
+func TestReplayRetries(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "outbox.json"), nil); err == nil {
+		t.Error("Expected an error without a retry policy")
+	}
+
+	tests := []struct {
+		name    string
+		busy    int
+		applied int
+		queued  int
+	}{
+		{name: "recovers", busy: 2, applied: 1, queued: 0},
+		{name: "keeps failing", busy: 5, applied: 0, queued: 1},
+	}
+
+	for _, tt := range tests {
+		o, err := Open(filepath.Join(t.TempDir(), "outbox.json"), &backoff.Policy{Initial: time.Millisecond, MaxAttempts: 3})
+		if err != nil {
+			t.Fatalf("Failed to open outbox: %v", err)
+		}
+		o.Add(Entry{Kind: KindTurnIn, TargetID: "s1"})
+
+		exec := &fakeExecutor{
+			submissions: map[string]*api.StudentSubmission{"s1": {ID: "s1", State: api.SubmissionStateCreated}},
+			busy:        tt.busy,
+		}
+		res, err := o.Replay(context.Background(), exec)
+		if (err != nil) != (tt.queued > 0) {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if len(res.Applied) != tt.applied || o.Len() != tt.queued {
+			t.Errorf("%s: expected %d applied and %d queued, got %d and %d", tt.name, tt.applied, tt.queued, len(res.Applied), o.Len())
+		}
+		if len(exec.calls) != 3 {
+			t.Errorf("%s: expected 3 attempts, got %v", tt.name, exec.calls)
+		}
+	}
+}
+
+// TestOpenVersions tests that an unversioned outbox is still read and one
+// from a newer release is refused.
+func TestOpenVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	legacy := `{"entries": [{"id": "1", "kind": "turn_in", "target_id": "s1"}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write outbox: %v", err)
+	}
+	o, err := Open(path, backoff.Default())
+	if err != nil {
+		t.Fatalf("Failed to open unversioned outbox: %v", err)
+	}
+	if !o.Has(KindTurnIn, "s1") {
+		t.Errorf("Expected queued turn-in, got %v", o.Entries())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 99, "entries": []}`), 0600); err != nil {
+		t.Fatalf("Failed to write outbox: %v", err)
+	}
+	if _, err := Open(path, backoff.Default()); !errors.Is(err, schema.ErrTooNew) {
+		t.Errorf("Expected ErrTooNew, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMapPreservesOrder tests that results come back in input order.
+func TestMapPreservesOrder(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3}
+
+	got, err := Map(context.Background(), 3, items, func(ctx context.Context, n int) (int, error) {
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n * 10, nil
+	})
+	if err != nil {
+		t.Fatalf("Map failed: %v", err)
+	}
+
+	for i, n := range items {
+		if got[i] != n*10 {
+			t.Errorf("Expected result %d to be %d, got %d", i, n*10, got[i])
+		}
+	}
+}
+
+// TestMapLimit tests that no more than limit workers run at once.
+func TestMapLimit(t *testing.T) {
+	var running, peak int32
+
+	_, err := Map(context.Background(), 2, make([]int, 10), func(ctx context.Context, _ int) (struct{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Map failed: %v", err)
+	}
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent workers, got %d", peak)
+	}
+}
+
+// TestMapError tests that the first error is returned.
+func TestMapError(t *testing.T) {
+	boom := errors.New("boom")
+
+	_, err := Map(context.Background(), 4, []int{1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, boom
+		}
+		return n, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Expected boom error, got %v", err)
+	}
+}
+
+// TestSettle tests that every function runs and errors stay per function.
+func TestSettle(t *testing.T) {
+	boom := errors.New("boom")
+	var ran int32
+
+	errs := Settle(context.Background(),
+		func(ctx context.Context) error {
+			atomic.AddInt32(&ran, 1)
+			return boom
+		},
+		func(ctx context.Context) error {
+			time.Sleep(2 * time.Millisecond)
+			if ctx.Err() != nil {
+				t.Error("Expected the context to stay live after another failure")
+			}
+			atomic.AddInt32(&ran, 1)
+			return nil
+		},
+	)
+
+	if ran != 2 {
+		t.Errorf("Expected 2 functions to run, got %d", ran)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], boom) || errs[1] != nil {
+		t.Errorf("Expected [boom <nil>], got %v", errs)
+	}
+}
@@ -0,0 +1,329 @@
+// Package purge removes the app's local data, for example before handing a
+// school machine back.
+package purge
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/checklist"
+	"github.com/user/google-classroom/internal/config"
+	"github.com/user/google-classroom/internal/focus"
+	"github.com/user/google-classroom/internal/humanize"
+	"github.com/user/google-classroom/internal/outbox"
+	"github.com/user/google-classroom/internal/readstate"
+	"github.com/user/google-classroom/internal/secure"
+	"github.com/user/google-classroom/internal/usage"
+)
+
+// Item is a file or directory of local data.
+type Item struct {
+	Label string
+	Path  string
+}
+
+// Targets lists what a purge removes.
+type Targets struct {
+	// Account is the signed-in user's data: tokens, cache, the outbox,
+	// checklists, read markers, usage counts, and logs.
+	Account []Item
+	// Settings are removed only with --all, such as the configuration
+	// file with the OAuth client.
+	Settings []Item
+	// Keys holds the encryption key ring, deleted with the account data.
+	// Nil leaves it alone.
+	Keys secure.KeyStore
+	// Tokens are the OAuth tokens kept outside the filesystem, such as in
+	// the OS keychain, one per OAuth profile, deleted with the account data.
+	Tokens []auth.TokenStore
+}
+
+// DefaultTargets returns the data written by the app with cfg, loaded from
+// configPath, the token stored at tokenPath, and the tokens of every OAuth
+// profile in the config file.
+func DefaultTargets(cfg *config.Config, configPath, tokenPath string) (*Targets, error) {
+	outboxPath, err := outbox.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	usagePath, err := usage.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	checklistPath, err := checklist.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	focusPath, err := focus.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	readPath, err := readstate.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	keyDir, err := secure.DefaultKeyDir()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Targets{
+		Account: []Item{
+			{Label: "OAuth tokens", Path: tokenPath},
+			{Label: "Cache", Path: cfg.Cache.Directory},
+			{Label: "Offline outbox", Path: outboxPath},
+			{Label: "Checklists", Path: checklistPath},
+			{Label: "Focus log", Path: focusPath},
+			{Label: "Read markers", Path: readPath},
+			{Label: "Offline store", Path: cfg.Offline.Directory},
+			{Label: "API usage", Path: usagePath},
+			{Label: "Debug log", Path: cfg.API.DebugLog},
+		},
+		Settings: []Item{
+			{Label: "Configuration", Path: configPath},
+		},
+		Keys:   secure.DefaultKeyStore(keyDir),
+		Tokens: []auth.TokenStore{auth.NewKeychainTokenStore()},
+	}
+	if cfg.API.RecordFixtures != "" {
+		t.Account = append(t.Account, Item{Label: "Recorded fixtures", Path: cfg.API.RecordFixtures})
+	}
+
+	profiles, err := auth.Profiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		path, err := auth.ProfileTokenPath(profile)
+		if err != nil {
+			return nil, err
+		}
+		t.Account = append(t.Account, Item{Label: "OAuth tokens (" + profile + ")", Path: path})
+		t.Tokens = append(t.Tokens, auth.NewProfileKeychainTokenStore(profile))
+	}
+	return t, nil
+}
+
+// Found is an item that exists on disk and what it holds.
+type Found struct {
+	Item
+	Files int
+	Bytes int64
+}
+
+// Scan returns the items that exist, skipping any inside a directory that
+// is already listed so nothing is counted twice.
+func Scan(items []Item) ([]Found, error) {
+	var found []Found
+	for _, item := range items {
+		if item.Path == "" || covered(item.Path, items) {
+			continue
+		}
+		f := Found{Item: item}
+		err := filepath.WalkDir(item.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				f.Files++
+				f.Bytes += info.Size()
+			}
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", item.Path, err)
+		}
+		found = append(found, f)
+	}
+	return found, nil
+}
+
+// covered reports whether path is inside another item's path.
+func covered(path string, items []Item) bool {
+	for _, other := range items {
+		if other.Path == "" || other.Path == path {
+			continue
+		}
+		if rel, err := filepath.Rel(other.Path, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove overwrites every file in found with zeros and then deletes it,
+// along with any directories. Overwriting only helps on disks that write in
+// place; on SSDs and copy-on-write filesystems old blocks may survive, so
+// full-disk encryption is still the real protection.
+func Remove(found []Found) error {
+	for _, f := range found {
+		err := filepath.WalkDir(f.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				return shred(path)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", f.Label, err)
+		}
+		if err := os.RemoveAll(f.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", f.Label, err)
+		}
+	}
+	return nil
+}
+
+// shred overwrites the file at path with zeros.
+func shred(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err == nil {
+		_, err = io.CopyN(f, zeros{}, info.Size())
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// zeros is an endless reader of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// RunPurge implements `classroom purge [--all] [--yes] [--dry-run]`. It
+// lists what will be deleted and asks on stdin before deleting anything.
+func RunPurge(t *Targets, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fset := flag.NewFlagSet("purge", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	all := fset.Bool("all", false, "also delete the configuration file")
+	yes := fset.Bool("yes", false, "delete without asking")
+	dryRun := fset.Bool("dry-run", false, "list what would be deleted and stop")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() > 0 {
+		return fmt.Errorf("usage: purge [--all] [--yes] [--dry-run]")
+	}
+
+	items := t.Account
+	if *all {
+		items = append(append([]Item(nil), items...), t.Settings...)
+	}
+	found, err := Scan(items)
+	if err != nil {
+		return err
+	}
+	key := t.Keys != nil && keyStored(t.Keys)
+	var tokens []auth.TokenStore
+	for _, store := range t.Tokens {
+		if tokenStored(store) {
+			tokens = append(tokens, store)
+		}
+	}
+
+	if len(found) == 0 && !key && len(tokens) == 0 {
+		fmt.Fprintln(stdout, "Nothing to delete.")
+		return nil
+	}
+	writeSummary(stdout, found, key, t.Keys, tokens)
+	if *dryRun {
+		return nil
+	}
+	if !*yes {
+		fmt.Fprint(stdout, "\nDelete all of this? This cannot be undone. [y/N] ")
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Fprintln(stdout, "Nothing deleted.")
+			return nil
+		}
+	}
+
+	if err := Remove(found); err != nil {
+		return err
+	}
+	for _, store := range tokens {
+		if err := store.Delete(); err != nil {
+			return fmt.Errorf("failed to delete OAuth token: %w", err)
+		}
+	}
+	// The key goes last so that if a file cannot be removed, what is left
+	// can still be read.
+	if key {
+		if err := t.Keys.Delete(); err != nil {
+			return fmt.Errorf("failed to delete encryption key: %w", err)
+		}
+	}
+	fmt.Fprintln(stdout, "Local data deleted.")
+	if !*all {
+		fmt.Fprintln(stdout, "The configuration file was kept; use --all to delete it too.")
+	}
+	return nil
+}
+
+// keyStored reports whether store holds a key ring.
+func keyStored(store secure.KeyStore) bool {
+	_, err := store.Load()
+	return err == nil
+}
+
+// tokenStored reports whether store holds a token.
+func tokenStored(store auth.TokenStore) bool {
+	_, err := store.Load()
+	return err == nil
+}
+
+// writeSummary lists what will be deleted. tokens are the stored tokens
+// kept outside the filesystem.
+func writeSummary(w io.Writer, found []Found, key bool, store secure.KeyStore, tokens []auth.TokenStore) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "This will permanently delete:")
+	for _, f := range found {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", f.Label, f.Path, describe(f))
+	}
+	for _, token := range tokens {
+		fmt.Fprintf(tw, "  OAuth token\t%s\t\n", token.Name())
+	}
+	if key {
+		fmt.Fprintf(tw, "  Encryption key\t%s\t\n", store.Name())
+	}
+	tw.Flush()
+}
+
+// describe summarizes the size of a found item.
+func describe(f Found) string {
+	files := "1 file"
+	if f.Files != 1 {
+		files = fmt.Sprintf("%d files", f.Files)
+	}
+	return fmt.Sprintf("%s, %s", files, humanize.Bytes(f.Bytes))
+}
@@ -0,0 +1,195 @@
+package purge
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/config"
+	"github.com/user/google-classroom/internal/secure"
+	"golang.org/x/oauth2"
+)
+
+// newTargets creates a token, a cache directory with a log inside, and a
+// configuration file under a temporary directory.
+func newTargets(t *testing.T) (*Targets, string) {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name, data string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write("tokens.json", `{"access_token": "secret"}`)
+	write("cache/courses.json", "[]")
+	write("cache/debug.log", "GET /courses")
+	write("config.json", "{}")
+
+	keys := &secure.FileStore{Path: filepath.Join(dir, "data.key")}
+	key, err := secure.NewKey()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if err := keys.Save(&secure.Ring{Current: key}); err != nil {
+		t.Fatalf("Failed to save key: %v", err)
+	}
+
+	return &Targets{
+		Account: []Item{
+			{Label: "OAuth tokens", Path: filepath.Join(dir, "tokens.json")},
+			{Label: "Cache", Path: filepath.Join(dir, "cache")},
+			{Label: "Debug log", Path: filepath.Join(dir, "cache", "debug.log")},
+			{Label: "Offline outbox", Path: filepath.Join(dir, "outbox.json")},
+		},
+		Settings: []Item{{Label: "Configuration", Path: filepath.Join(dir, "config.json")}},
+		Keys:     keys,
+	}, dir
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// TestScan tests that missing items are skipped and nested ones are only
+// counted once.
+func TestScan(t *testing.T) {
+	targets, _ := newTargets(t)
+
+	found, err := Scan(targets.Account)
+	if err != nil {
+		t.Fatalf("Failed to scan: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("Expected tokens and cache, got %v", found)
+	}
+	if found[1].Label != "Cache" || found[1].Files != 2 {
+		t.Errorf("Expected the cache with 2 files, got %+v", found[1])
+	}
+}
+
+// TestRunPurgeDeclined tests that nothing is deleted unless confirmed.
+func TestRunPurgeDeclined(t *testing.T) {
+	targets, dir := newTargets(t)
+	var stdout, stderr bytes.Buffer
+
+	if err := RunPurge(targets, nil, strings.NewReader("n\n"), &stdout, &stderr); err != nil {
+		t.Fatalf("RunPurge failed: %v", err)
+	}
+	for _, want := range []string{"OAuth tokens", "Cache", "2 files", "Encryption key"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected summary to mention %q, got:\n%s", want, stdout.String())
+		}
+	}
+	if !exists(filepath.Join(dir, "tokens.json")) || !exists(filepath.Join(dir, "data.key")) {
+		t.Error("Expected nothing deleted")
+	}
+}
+
+// TestRunPurge tests that account data and the key are deleted while the
+// configuration is kept without --all.
+func TestRunPurge(t *testing.T) {
+	targets, dir := newTargets(t)
+	var stdout, stderr bytes.Buffer
+
+	if err := RunPurge(targets, nil, strings.NewReader("yes\n"), &stdout, &stderr); err != nil {
+		t.Fatalf("RunPurge failed: %v", err)
+	}
+	for _, name := range []string{"tokens.json", "cache", "data.key"} {
+		if exists(filepath.Join(dir, name)) {
+			t.Errorf("Expected %s deleted", name)
+		}
+	}
+	if !exists(filepath.Join(dir, "config.json")) {
+		t.Error("Expected configuration kept without --all")
+	}
+
+	stdout.Reset()
+	if err := RunPurge(targets, []string{"--all", "--yes"}, strings.NewReader(""), &stdout, &stderr); err != nil {
+		t.Fatalf("RunPurge --all failed: %v", err)
+	}
+	if exists(filepath.Join(dir, "config.json")) {
+		t.Error("Expected configuration deleted with --all")
+	}
+
+	stdout.Reset()
+	if err := RunPurge(targets, []string{"--all"}, strings.NewReader(""), &stdout, &stderr); err != nil {
+		t.Fatalf("RunPurge on empty data failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Nothing to delete") {
+		t.Errorf("Expected nothing to delete, got %q", stdout.String())
+	}
+}
+
+// TestShred tests that a file is overwritten in place.
+func TestShred(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	if err := os.WriteFile(path, []byte("secret"), 0600); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := shred(path); err != nil {
+		t.Fatalf("Failed to shred: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, make([]byte, 6)) {
+		t.Errorf("Expected zeros, got %q", data)
+	}
+}
+
+// TestRunPurgeToken tests that a token kept outside the listed files, as in
+// the OS keychain, is listed and deleted.
+func TestRunPurgeToken(t *testing.T) {
+	targets, dir := newTargets(t)
+	store := &auth.FileTokenStore{Path: filepath.Join(dir, "keychain", "token.json")}
+	if err := store.Save(&oauth2.Token{AccessToken: "secret"}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	targets.Tokens = []auth.TokenStore{store}
+	var stdout, stderr bytes.Buffer
+
+	if err := RunPurge(targets, []string{"--yes"}, strings.NewReader(""), &stdout, &stderr); err != nil {
+		t.Fatalf("RunPurge failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "OAuth token  ") {
+		t.Errorf("Expected summary to list the stored token, got:\n%s", stdout.String())
+	}
+	if _, err := store.Load(); !errors.Is(err, auth.ErrNoToken) {
+		t.Errorf("Expected the token deleted, got %v", err)
+	}
+}
+
+// TestDefaultTargetsProfiles tests that every OAuth profile's token file
+// and keychain entry are purged.
+func TestDefaultTargetsProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"profiles": {"north": {"client_id": "n"}, "south": {"client_id": "s"}}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	targets, err := DefaultTargets(config.Default(), configPath, filepath.Join(home, "tokens.json"))
+	if err != nil {
+		t.Fatalf("DefaultTargets failed: %v", err)
+	}
+	paths := make(map[string]bool)
+	for _, item := range targets.Account {
+		paths[filepath.Base(item.Path)] = true
+	}
+	for _, want := range []string{"tokens.json", "tokens-north.json", "tokens-south.json"} {
+		if !paths[want] {
+			t.Errorf("Expected %s among the targets, got %v", want, targets.Account)
+		}
+	}
+	if len(targets.Tokens) != 3 {
+		t.Errorf("Expected a keychain token per profile and the default, got %d", len(targets.Tokens))
+	}
+}
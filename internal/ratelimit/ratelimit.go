@@ -0,0 +1,133 @@
+// Package ratelimit provides token-bucket limiters for outgoing API requests.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket that refills at a steady rate up to its burst
+// size. Each request takes one token; when the bucket is empty the caller
+// waits for the next token.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewBucket creates a full bucket that refills rate tokens per second and
+// holds at most burst tokens. A burst below one is raised to one.
+func NewBucket(rate float64, burst int) *Bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. The token may be borrowed from the future, which keeps waiting
+// callers in arrival order.
+func (b *Bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (b *Bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *Bucket) Wait(ctx context.Context) error {
+	return wait(ctx, b)
+}
+
+// Limiter enforces a per-second rate and a per-minute rate together.
+type Limiter struct {
+	buckets []*Bucket
+}
+
+// New creates a limiter allowing qps requests per second with bursts of up
+// to burst, and a sustained perMinute requests per minute. The per-minute
+// limit is a bucket too, so it is a rate rather than a window: after an
+// idle minute the next perMinute requests are held only by qps, and the
+// minute they take refills the bucket for more. Zero disables a limit;
+// New returns nil when both are disabled, and a nil *Limiter never blocks.
+func New(qps float64, burst int, perMinute int) *Limiter {
+	var buckets []*Bucket
+	if qps > 0 {
+		if burst < 1 {
+			burst = int(math.Ceil(qps))
+		}
+		buckets = append(buckets, NewBucket(qps, burst))
+	}
+	if perMinute > 0 {
+		buckets = append(buckets, NewBucket(float64(perMinute)/60, perMinute))
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+	return &Limiter{buckets: buckets}
+}
+
+// Wait blocks until every limit allows another request or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return wait(ctx, l.buckets...)
+}
+
+// wait reserves a token from every bucket and sleeps for the longest delay.
+// The tokens are returned if ctx ends first.
+func wait(ctx context.Context, buckets ...*Bucket) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var delay time.Duration
+	for _, b := range buckets {
+		if d := b.reserve(); d > delay {
+			delay = d
+		}
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		for _, b := range buckets {
+			b.cancel()
+		}
+		return ctx.Err()
+	}
+}
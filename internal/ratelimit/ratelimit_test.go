@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+// TestBucketReserve tests bursts, refill, and borrowing ahead.
+func TestBucketReserve(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	b := NewBucket(10, 2)
+	b.now = clock.now
+
+	for i := 0; i < 2; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Errorf("Expected burst request %d not to wait, got %v", i, d)
+		}
+	}
+	if d := b.reserve(); d != 100*time.Millisecond {
+		t.Errorf("Expected 100ms wait, got %v", d)
+	}
+	if d := b.reserve(); d != 200*time.Millisecond {
+		t.Errorf("Expected 200ms wait for the next caller, got %v", d)
+	}
+
+	clock.t = clock.t.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Errorf("Expected refilled bucket not to wait, got %v", d)
+		}
+	}
+	if d := b.reserve(); d == 0 {
+		t.Error("Expected refill to be capped at the burst size")
+	}
+}
+
+// TestNew tests which limits a limiter enforces.
+func TestNew(t *testing.T) {
+	if l := New(0, 0, 0); l != nil {
+		t.Error("Expected nil limiter when both limits are disabled")
+	}
+	if err := (*Limiter)(nil).Wait(context.Background()); err != nil {
+		t.Errorf("Expected nil limiter not to block, got %v", err)
+	}
+
+	l := New(5, 0, 600)
+	if len(l.buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(l.buckets))
+	}
+	if l.buckets[0].burst != 5 {
+		t.Errorf("Expected burst to default to the rate, got %v", l.buckets[0].burst)
+	}
+	if l.buckets[1].rate != 10 || l.buckets[1].burst != 600 {
+		t.Errorf("Expected 10/s refill with 600 burst, got %v/%v", l.buckets[1].rate, l.buckets[1].burst)
+	}
+}
+
+// TestWaitCancel tests that a cancelled wait returns its token.
+func TestWaitCancel(t *testing.T) {
+	b := NewBucket(0.001, 1)
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected first token immediately, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); err == nil {
+		t.Fatal("Expected wait to fail once the context ends")
+	}
+
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("Expected cancelled token to be returned, got %v tokens", tokens)
+	}
+}
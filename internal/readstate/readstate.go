@@ -0,0 +1,126 @@
+// Package readstate remembers when the user last looked at each course's
+// stream, so posts that appeared since can be marked unread. It lives only
+// on this machine; Classroom has no read state of its own.
+package readstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// Store holds the time each course's stream was last seen, saved as a JSON
+// file.
+type Store struct {
+	path   string
+	sealer *secure.Sealer
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// fileSchema versions the on-disk layout.
+var fileSchema = schema.Schema{Name: "read state", Version: 1}
+
+// file is the on-disk layout.
+type file struct {
+	Version int `json:"version"`
+	// Courses maps course IDs to when their stream was last seen.
+	Courses map[string]time.Time `json:"courses"`
+}
+
+// DefaultPath returns the default read state location.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "google-classroom", "read.json"), nil
+}
+
+// Open loads the read state at path. A missing file has seen nothing.
+func Open(path string) (*Store, error) {
+	return OpenSealed(path, nil)
+}
+
+// OpenSealed loads the read state at path and keeps it encrypted with s.
+// A plaintext file from before encryption was enabled is still read.
+func OpenSealed(path string, s *secure.Sealer) (*Store, error) {
+	st := &Store{path: path, sealer: s, seen: make(map[string]time.Time)}
+
+	data, err := secure.ReadFile(path, s)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return nil, fmt.Errorf("failed to read read state: %w", err)
+	}
+
+	var f file
+	if _, err := fileSchema.Decode(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Courses != nil {
+		st.seen = f.Courses
+	}
+	return st, nil
+}
+
+// LastSeen returns when the course's stream was last seen, or false when
+// it never was. It is safe to call on a nil store.
+func (s *Store) LastSeen(courseID string) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.seen[courseID]
+	return t, ok
+}
+
+// MarkSeen records that the course's stream was seen up to t and saves the
+// store. An earlier time than the one recorded is ignored, so opening an
+// older cached copy never marks posts unread again.
+func (s *Store) MarkSeen(courseID string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.seen[courseID]
+	if ok && !t.After(old) {
+		return nil
+	}
+	s.seen[courseID] = t.UTC()
+	if err := s.save(); err != nil {
+		if ok {
+			s.seen[courseID] = old
+		} else {
+			delete(s.seen, courseID)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the store through a temporary file so a crash never leaves
+// a half-written file. The caller holds s.mu.
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create read state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(file{Version: fileSchema.Version, Courses: s.seen}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal read state: %w", err)
+	}
+
+	if err := secure.SealFile(s.path, data, s.sealer); err != nil {
+		return fmt.Errorf("failed to save read state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package readstate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestMarkSeenPersists tests that seen times survive reopening the store
+// and never move backwards.
+func TestMarkSeenPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "read.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if _, ok := s.LastSeen("c1"); ok {
+		t.Error("Expected a new store to have seen nothing")
+	}
+
+	later := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	if err := s.MarkSeen("c1", later); err != nil {
+		t.Fatalf("Failed to mark seen: %v", err)
+	}
+	if err := s.MarkSeen("c1", later.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to mark seen: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	if got, ok := reopened.LastSeen("c1"); !ok || !got.Equal(later) {
+		t.Errorf("Expected c1 seen at %v, got %v, %v", later, got, ok)
+	}
+	if _, ok := reopened.LastSeen("c2"); ok {
+		t.Error("Expected c2 never seen")
+	}
+}
+
+// TestNilStore tests that a nil store has seen nothing.
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if _, ok := s.LastSeen("c1"); ok {
+		t.Error("Expected a nil store to have seen nothing")
+	}
+}
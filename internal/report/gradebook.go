@@ -0,0 +1,271 @@
+package report
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/collation"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// GradebookColumn is one assignment in the gradebook.
+type GradebookColumn struct {
+	CourseWork *api.CourseWork
+	// Graded counts returned submissions of graded work, and Average is
+	// their mean grade as a percentage of the maximum points.
+	Graded  int
+	Average float64
+}
+
+// GradebookCell is one student's result on one assignment.
+type GradebookCell struct {
+	// Assigned is false when the student has no submission, such as for
+	// work assigned to other students.
+	Assigned bool
+	State    string
+	// Points is the returned grade; it is only meaningful when Graded.
+	Points   int
+	Graded   bool
+	TurnedIn bool
+	Late     bool
+	// Missing is set for work that is past due and not turned in.
+	Missing bool
+}
+
+// GradebookRow is one student's grades, with one cell per column.
+type GradebookRow struct {
+	UserID string
+	Name   string
+	Cells  []GradebookCell
+	// Earned and Possible total the points of the graded assignments.
+	Earned   int
+	Possible int
+	// Graded counts the graded assignments, and Average is the mean of
+	// their percentages, so each assignment weighs the same.
+	Graded  int
+	Average float64
+	// Assigned counts the assignments the student has a submission for,
+	// and TurnedIn those of them turned in.
+	Assigned int
+	TurnedIn int
+	Missing  int
+	Late     int
+}
+
+// Total returns the points earned as a percentage of the points possible,
+// or false when nothing is graded yet.
+func (r GradebookRow) Total() (float64, bool) {
+	if r.Possible == 0 {
+		return 0, false
+	}
+	return float64(r.Earned) / float64(r.Possible) * 100, true
+}
+
+// GradebookReport joins a course's coursework and submissions into a grid
+// of students by assignments with per-student and per-assignment totals.
+type GradebookReport struct {
+	Course *api.Course
+	// AsOf is when the report was built; work due before it and not
+	// turned in counts as missing.
+	AsOf time.Time
+	// Columns are the assignments, ordered by due date.
+	Columns []GradebookColumn
+	// Rows are the students, ordered by name.
+	Rows []GradebookRow
+}
+
+// ClassAverage returns the mean of the students' averages, or false when
+// nothing is graded yet.
+func (g *GradebookReport) ClassAverage() (float64, bool) {
+	total, n := 0.0, 0
+	for _, r := range g.Rows {
+		if r.Graded > 0 {
+			total += r.Average
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return total / float64(n), true
+}
+
+// Row returns the row of a student, or false when they are not enrolled.
+func (g *GradebookReport) Row(userID string) (GradebookRow, bool) {
+	for _, r := range g.Rows {
+		if r.UserID == userID {
+			return r, true
+		}
+	}
+	return GradebookRow{}, false
+}
+
+// BuildGradebook builds the gradebook of a course as of now. Materials,
+// which take no submissions, are left out.
+func BuildGradebook(ctx context.Context, src Source, courseID string, now time.Time) (*GradebookReport, error) {
+	course, err := src.GetCourse(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+	coursework, err := src.ListCourseWork(ctx, courseID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coursework: %w", err)
+	}
+	students, err := src.ListStudents(ctx, courseID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+
+	var work []*api.CourseWork
+	for _, cw := range coursework {
+		if cw.WorkType != api.WorkTypeMaterial {
+			work = append(work, cw)
+		}
+	}
+	sortByDue(work)
+
+	submissions, err := parallel.Map(ctx, 4, work, func(ctx context.Context, cw *api.CourseWork) ([]*api.StudentSubmission, error) {
+		subs, err := src.ListStudentSubmissions(ctx, courseID, cw.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list submissions for %s: %w", cw.Title, err)
+		}
+		return subs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &GradebookReport{Course: course, AsOf: now}
+	rows := make(map[string]*GradebookRow, len(students))
+	for _, s := range students {
+		name := s.Profile.Name
+		if name == "" {
+			name = s.UserID
+		}
+		rows[s.UserID] = &GradebookRow{UserID: s.UserID, Name: name, Cells: make([]GradebookCell, len(work))}
+	}
+
+	for i, cw := range work {
+		due, _, hasDue := cw.Due()
+		pastDue := hasDue && due.Before(now)
+		col := GradebookColumn{CourseWork: cw}
+		total := 0.0
+		for _, sub := range submissions[i] {
+			row := rows[sub.UserID]
+			if row == nil {
+				// Students who left the course keep their submissions
+				continue
+			}
+			cell := GradebookCell{
+				Assigned: true,
+				State:    sub.State,
+				TurnedIn: turnedIn(sub),
+				Late:     sub.Late,
+			}
+			cell.Missing = pastDue && !cell.TurnedIn
+			if sub.State == api.SubmissionStateReturned && sub.HasAssignedGrade && cw.MaxPoints > 0 {
+				cell.Graded = true
+				cell.Points = sub.AssignedGrade
+				pct := float64(sub.AssignedGrade) / float64(cw.MaxPoints) * 100
+				col.Graded++
+				total += pct
+				row.Earned += sub.AssignedGrade
+				row.Possible += cw.MaxPoints
+				row.Average += pct
+				row.Graded++
+			}
+			row.Assigned++
+			if cell.TurnedIn {
+				row.TurnedIn++
+			}
+			if cell.Missing {
+				row.Missing++
+			}
+			if cell.Late {
+				row.Late++
+			}
+			row.Cells[i] = cell
+		}
+		if col.Graded > 0 {
+			col.Average = total / float64(col.Graded)
+		}
+		g.Columns = append(g.Columns, col)
+	}
+
+	for _, s := range students {
+		row := rows[s.UserID]
+		if row.Graded > 0 {
+			// Average holds the sum of percentages until here
+			row.Average /= float64(row.Graded)
+		}
+		g.Rows = append(g.Rows, *row)
+	}
+	collation.Sort(g.Rows, func(r GradebookRow) string { return r.Name })
+	return g, nil
+}
+
+// WriteCSV writes the gradebook as CSV: one row per student with a column
+// per assignment, followed by the student's totals. Cells hold the grade,
+// or "missing", "late", "turned in", or nothing.
+func (g *GradebookReport) WriteCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"Student", "User ID"}
+	for _, col := range g.Columns {
+		title := col.CourseWork.Title
+		if col.CourseWork.MaxPoints > 0 {
+			title += fmt.Sprintf(" (%d pts)", col.CourseWork.MaxPoints)
+		}
+		header = append(header, title)
+	}
+	header = append(header, "Points", "Possible", "Total %", "Average %", "Missing", "Late")
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write gradebook: %w", err)
+	}
+
+	for _, row := range g.Rows {
+		record := []string{row.Name, row.UserID}
+		for _, cell := range row.Cells {
+			record = append(record, cellText(cell))
+		}
+		total, average := "", ""
+		if pct, ok := row.Total(); ok {
+			total = strconv.FormatFloat(pct, 'f', 1, 64)
+		}
+		if row.Graded > 0 {
+			average = strconv.FormatFloat(row.Average, 'f', 1, 64)
+		}
+		record = append(record, strconv.Itoa(row.Earned), strconv.Itoa(row.Possible), total, average,
+			strconv.Itoa(row.Missing), strconv.Itoa(row.Late))
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to write gradebook: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write gradebook: %w", err)
+	}
+	return nil
+}
+
+// cellText describes a cell in the CSV export.
+func cellText(c GradebookCell) string {
+	switch {
+	case c.Graded:
+		return strconv.Itoa(c.Points)
+	case c.Missing:
+		return "missing"
+	case c.TurnedIn && c.Late:
+		return "late"
+	case c.TurnedIn:
+		return "turned in"
+	default:
+		return ""
+	}
+}
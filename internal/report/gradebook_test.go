@@ -0,0 +1,145 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// TestBuildGradebook tests per-student totals, averages, and missing and
+// late counts.
+func TestBuildGradebook(t *testing.T) {
+	asOf := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	g, err := BuildGradebook(context.Background(), newCourse(), "c1", asOf)
+	if err != nil {
+		t.Fatalf("Failed to build gradebook: %v", err)
+	}
+
+	if len(g.Columns) != 3 || g.Columns[0].CourseWork.Title != "Quiz" || g.Columns[1].CourseWork.Title != "Essay" {
+		t.Fatalf("Expected Quiz, Essay, Lab by due date, got %d columns", len(g.Columns))
+	}
+	if essay := g.Columns[1]; essay.Graded != 1 || essay.Average != 90 {
+		t.Errorf("Expected 1 graded essay averaging 90%%, got %+v", essay)
+	}
+	if len(g.Rows) != 3 || g.Rows[0].Name != "Ada Byron" || g.Rows[2].Name != "Zoë Okafor" {
+		t.Fatalf("Expected rows sorted by name, got %+v", g.Rows)
+	}
+
+	ada := g.Rows[0]
+	if ada.Earned != 18 || ada.Possible != 20 || ada.Graded != 1 || ada.Average != 90 || ada.Missing != 0 {
+		t.Errorf("Expected Ada with 18/20 and nothing missing, got %+v", ada)
+	}
+	if total, ok := ada.Total(); !ok || total != 90 {
+		t.Errorf("Expected Ada's total 90%%, got %v, %v", total, ok)
+	}
+
+	jordan, ok := g.Row("s2")
+	if !ok {
+		t.Fatal("Expected a row for s2")
+	}
+	if jordan.Missing != 1 || jordan.Late != 1 || !jordan.Cells[0].Missing || jordan.Cells[2].Assigned {
+		t.Errorf("Expected Jordan missing the quiz with a late essay and no lab, got %+v", jordan)
+	}
+	if jordan.Assigned != 2 || jordan.TurnedIn != 1 {
+		t.Errorf("Expected Jordan with 1 of 2 assignments turned in, got %d of %d", jordan.TurnedIn, jordan.Assigned)
+	}
+	if _, ok := jordan.Total(); ok {
+		t.Error("Expected no total without graded work")
+	}
+
+	zoe := g.Rows[2]
+	if zoe.Missing != 2 {
+		t.Errorf("Expected Zoë missing 2 items, got %d", zoe.Missing)
+	}
+	if avg, ok := g.ClassAverage(); !ok || avg != 90 {
+		t.Errorf("Expected class average 90%%, got %v, %v", avg, ok)
+	}
+}
+
+// TestGradebookReturnedWithoutGrade tests that work returned without a
+// grade is not counted as 0 points, while an assigned 0 is.
+func TestGradebookReturnedWithoutGrade(t *testing.T) {
+	ctx := context.Background()
+	c := newCourse()
+	work, err := c.ListCourseWork(ctx, "c1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labID string
+	for _, cw := range work {
+		if cw.Title == "Lab" {
+			labID = cw.ID
+		}
+	}
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: labID, UserID: "s1", State: api.SubmissionStateReturned})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: labID, UserID: "s2", State: api.SubmissionStateReturned, HasAssignedGrade: true})
+
+	g, err := BuildGradebook(ctx, c, "c1", time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to build gradebook: %v", err)
+	}
+
+	if lab := g.Columns[2]; lab.Graded != 1 || lab.Average != 0 {
+		t.Errorf("Expected only the assigned 0 graded, got %+v", lab)
+	}
+	ada := g.Rows[0]
+	if ada.Cells[2].Graded || ada.Earned != 18 || ada.Possible != 20 || ada.Average != 90 {
+		t.Errorf("Expected Ada's ungraded lab left out, got %+v", ada)
+	}
+	jordan := g.Rows[1]
+	if !jordan.Cells[2].Graded || jordan.Earned != 0 || jordan.Possible != 10 || jordan.Graded != 1 {
+		t.Errorf("Expected Jordan's lab graded 0/10, got %+v", jordan)
+	}
+}
+
+// TestGradebookCSV tests the CSV export of the gradebook.
+func TestGradebookCSV(t *testing.T) {
+	asOf := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	g, err := BuildGradebook(context.Background(), newCourse(), "c1", asOf)
+	if err != nil {
+		t.Fatalf("Failed to build gradebook: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"Student", "User ID", "Quiz", "Essay (20 pts)", "Lab (10 pts)", "Points", "Possible", "Total %", "Average %", "Missing", "Late"},
+		{"Ada Byron", "s1", "turned in", "18", "", "18", "20", "90.0", "90.0", "0", "0"},
+		{"Jordan Lee", "s2", "missing", "late", "", "0", "0", "", "", "1", "1"},
+		{"Zoë Okafor", "s3", "missing", "missing", "", "0", "0", "", "", "2", "0"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d:\n%s", len(want), len(records), buf.String())
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("Record %d: expected %q, got %q", i, want[i], records[i])
+		}
+	}
+}
+
+// TestRunGradebookReport tests the gradebook report command.
+func TestRunGradebookReport(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := RunReport(context.Background(), newCourse(), []string{"gradebook", "c1"}, &stdout, &stderr); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Student,User ID,") {
+		t.Errorf("Expected CSV gradebook, got:\n%s", stdout.String())
+	}
+	if err := RunReport(context.Background(), newCourse(), []string{"gradebook"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error without a course")
+	}
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/user/google-classroom/internal/cli"
+)
+
+// reportUsage is the synopsis of the report command.
+const reportUsage = "usage: report weekly <course> [--to YYYY-MM-DD] [--output file]\n       report gradebook <course> [--output file]"
+
+// RunReport implements `classroom report weekly <course> [--to YYYY-MM-DD]
+// [--output file]` and `classroom report gradebook <course> [--output
+// file]`. The weekly report covers the seven days ending on --to, today by
+// default, so a scheduled run on Friday summarizes the school week. The
+// gradebook is written as CSV.
+func RunReport(ctx context.Context, src Source, args []string, stdout, stderr io.Writer) error {
+	if len(args) < 1 {
+		return errors.New(reportUsage)
+	}
+	switch args[0] {
+	case "weekly":
+		return runWeekly(ctx, src, args[1:], stdout, stderr)
+	case "gradebook":
+		return runGradebook(ctx, src, args[1:], stdout, stderr)
+	default:
+		return fmt.Errorf("unknown report %q; %s", args[0], reportUsage)
+	}
+}
+
+// runWeekly writes the weekly report.
+func runWeekly(ctx context.Context, src Source, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("report weekly", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	to := fs.String("to", "", "last `day` of the week (YYYY-MM-DD); defaults to today")
+	output := fs.String("output", "", "write the Markdown to `file` instead of stdout")
+
+	courseID, err := parseCourseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	lastDay := time.Now()
+	if *to != "" {
+		day, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: use YYYY-MM-DD", *to)
+		}
+		lastDay = day
+	}
+
+	w, err := BuildWeekly(ctx, src, courseID, lastDay)
+	if err != nil {
+		return err
+	}
+
+	if *output != "" {
+		if err := os.WriteFile(*output, []byte(w.Markdown()), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Fprintf(stdout, "Wrote weekly report for %s to %s\n", w.Course.Name, *output)
+		return nil
+	}
+	return w.WriteMarkdown(stdout)
+}
+
+// runGradebook writes the gradebook as CSV.
+func runGradebook(ctx context.Context, src Source, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("report gradebook", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("output", "", "write the CSV to `file` instead of stdout")
+
+	courseID, err := parseCourseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	g, err := BuildGradebook(ctx, src, courseID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return g.WriteCSV(stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *output, err)
+	}
+	if err := g.WriteCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Fprintf(stdout, "Wrote gradebook for %s to %s\n", g.Course.Name, *output)
+	return nil
+}
+
+// parseCourseArgs parses flags and the course ID, which may come before or
+// after the flags.
+func parseCourseArgs(fs *flag.FlagSet, args []string) (string, error) {
+	courseID, err := cli.ParseCourseArgs(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if courseID == "" {
+		return "", errors.New(reportUsage)
+	}
+	return courseID, nil
+}
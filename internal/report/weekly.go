@@ -0,0 +1,326 @@
+// Package report builds summaries of a course for teachers: a weekly
+// Markdown report and a gradebook.
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// MissingThreshold is how many missing items put a student on the report.
+const MissingThreshold = 2
+
+// Source provides the data a report is built from. *api.Client satisfies
+// it.
+type Source interface {
+	GetCourse(ctx context.Context, courseID string) (*api.Course, error)
+	ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error)
+	ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error)
+	ListStudents(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Student, error)
+}
+
+// Assignment summarizes the submissions for one assignment.
+type Assignment struct {
+	CourseWork *api.CourseWork
+	Assigned   int
+	TurnedIn   int
+	Late       int
+	// Graded counts returned submissions of graded work, and Average is
+	// their mean grade as a percentage of the maximum points.
+	Graded  int
+	Average float64
+}
+
+// TurnInRate returns the share of students who turned the work in.
+func (a Assignment) TurnInRate() float64 {
+	if a.Assigned == 0 {
+		return 0
+	}
+	return float64(a.TurnedIn) / float64(a.Assigned)
+}
+
+// Missing lists a student's assignments that are past due and not turned
+// in.
+type Missing struct {
+	Name  string
+	Items []string
+}
+
+// Weekly is a summary of one week in a course.
+type Weekly struct {
+	Course *api.Course
+	// From is the start of the first day and To the end of the last.
+	From time.Time
+	To   time.Time
+	// Posted is the coursework created during the week.
+	Posted []*api.CourseWork
+	// Due summarizes the assignments due during the week.
+	Due []Assignment
+	// Missing lists students with at least MissingThreshold missing
+	// items across the course.
+	Missing []Missing
+	// Upcoming is the coursework due in the week after To.
+	Upcoming []*api.CourseWork
+}
+
+// BuildWeekly summarizes the seven days ending on lastDay.
+func BuildWeekly(ctx context.Context, src Source, courseID string, lastDay time.Time) (*Weekly, error) {
+	y, m, d := lastDay.Date()
+	to := time.Date(y, m, d+1, 0, 0, 0, 0, lastDay.Location())
+	w := &Weekly{From: to.AddDate(0, 0, -7), To: to}
+
+	course, err := src.GetCourse(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+	w.Course = course
+
+	coursework, err := src.ListCourseWork(ctx, courseID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coursework: %w", err)
+	}
+	students, err := src.ListStudents(ctx, courseID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+
+	// Only work that was due by the end of the week can be missing.
+	var pastDue []*api.CourseWork
+	for _, cw := range coursework {
+		if created, err := time.Parse(time.RFC3339Nano, cw.CreateTime); err == nil && !created.Before(w.From) && created.Before(w.To) {
+			w.Posted = append(w.Posted, cw)
+		}
+		due, _, ok := cw.Due()
+		switch {
+		case !ok:
+		case due.Before(w.To):
+			pastDue = append(pastDue, cw)
+		case due.Before(w.To.AddDate(0, 0, 7)):
+			w.Upcoming = append(w.Upcoming, cw)
+		}
+	}
+
+	submissions, err := parallel.Map(ctx, 4, pastDue, func(ctx context.Context, cw *api.CourseWork) ([]*api.StudentSubmission, error) {
+		subs, err := src.ListStudentSubmissions(ctx, courseID, cw.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list submissions for %s: %w", cw.Title, err)
+		}
+		return subs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string][]string)
+	for i, cw := range pastDue {
+		a := summarize(cw, submissions[i])
+		if due, _, _ := cw.Due(); !due.Before(w.From) {
+			w.Due = append(w.Due, a)
+		}
+		for _, sub := range submissions[i] {
+			if !turnedIn(sub) {
+				missing[sub.UserID] = append(missing[sub.UserID], cw.Title)
+			}
+		}
+	}
+
+	names := make(map[string]string)
+	for _, s := range students {
+		names[s.UserID] = s.Profile.Name
+	}
+	for userID, items := range missing {
+		if len(items) < MissingThreshold {
+			continue
+		}
+		name := names[userID]
+		if name == "" {
+			name = userID
+		}
+		w.Missing = append(w.Missing, Missing{Name: name, Items: items})
+	}
+
+	sortByDue(w.Posted)
+	sortByDue(w.Upcoming)
+	sort.SliceStable(w.Due, func(i, j int) bool { return dueKey(w.Due[i].CourseWork) < dueKey(w.Due[j].CourseWork) })
+	sort.Slice(w.Missing, func(i, j int) bool {
+		if len(w.Missing[i].Items) != len(w.Missing[j].Items) {
+			return len(w.Missing[i].Items) > len(w.Missing[j].Items)
+		}
+		return w.Missing[i].Name < w.Missing[j].Name
+	})
+	return w, nil
+}
+
+// summarize counts an assignment's turn-ins, late work, and grades.
+func summarize(cw *api.CourseWork, subs []*api.StudentSubmission) Assignment {
+	a := Assignment{CourseWork: cw, Assigned: len(subs)}
+	total := 0.0
+	for _, sub := range subs {
+		if turnedIn(sub) {
+			a.TurnedIn++
+		}
+		if sub.Late {
+			a.Late++
+		}
+		if sub.State == api.SubmissionStateReturned && sub.HasAssignedGrade && cw.MaxPoints > 0 {
+			a.Graded++
+			total += float64(sub.AssignedGrade) / float64(cw.MaxPoints) * 100
+		}
+	}
+	if a.Graded > 0 {
+		a.Average = total / float64(a.Graded)
+	}
+	return a
+}
+
+// turnedIn reports whether the student handed the work in.
+func turnedIn(sub *api.StudentSubmission) bool {
+	return sub.State == api.SubmissionStateTurnedIn || sub.State == api.SubmissionStateReturned
+}
+
+func sortByDue(items []*api.CourseWork) {
+	sort.SliceStable(items, func(i, j int) bool { return dueKey(items[i]) < dueKey(items[j]) })
+}
+
+// dueKey orders coursework by due date, with undated work last.
+func dueKey(cw *api.CourseWork) string {
+	if cw.DueDate == "" {
+		return "9999"
+	}
+	return cw.DueDate + " " + cw.DueTime
+}
+
+// TurnInRate returns the combined turn-in rate of the work due this week.
+func (w *Weekly) TurnInRate() (turnedIn, assigned int) {
+	for _, a := range w.Due {
+		turnedIn += a.TurnedIn
+		assigned += a.Assigned
+	}
+	return turnedIn, assigned
+}
+
+// AverageGrade returns the mean grade across the graded work due this
+// week, weighting each assignment by its graded submissions.
+func (w *Weekly) AverageGrade() (average float64, graded int) {
+	total := 0.0
+	for _, a := range w.Due {
+		total += a.Average * float64(a.Graded)
+		graded += a.Graded
+	}
+	if graded == 0 {
+		return 0, 0
+	}
+	return total / float64(graded), graded
+}
+
+// WriteMarkdown writes the report as Markdown.
+func (w *Weekly) WriteMarkdown(out io.Writer) error {
+	var b strings.Builder
+
+	title := w.Course.Name
+	if w.Course.Section != "" {
+		title += " (" + w.Course.Section + ")"
+	}
+	fmt.Fprintf(&b, "# Weekly report: %s\n\n", title)
+	fmt.Fprintf(&b, "%s to %s\n\n", w.From.Format("Jan 2, 2006"), w.To.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- Assignments posted: %d\n", len(w.Posted))
+	if in, assigned := w.TurnInRate(); assigned > 0 {
+		fmt.Fprintf(&b, "- Turn-in rate: %s (%d/%d) for work due this week\n", percent(float64(in)/float64(assigned)*100), in, assigned)
+	}
+	if avg, graded := w.AverageGrade(); graded > 0 {
+		fmt.Fprintf(&b, "- Average grade: %s across %d graded submissions\n", percent(avg), graded)
+	}
+	fmt.Fprintf(&b, "- Students missing %d or more items: %d\n\n", MissingThreshold, len(w.Missing))
+
+	if len(w.Posted) > 0 {
+		b.WriteString("## Assignments posted\n\n")
+		for _, cw := range w.Posted {
+			fmt.Fprintf(&b, "- **%s**%s\n", cw.Title, dueSuffix(cw))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(w.Due) > 0 {
+		b.WriteString("## Due this week\n\n")
+		b.WriteString("| Assignment | Due | Turned in | Late | Average grade |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, a := range w.Due {
+			avg := "-"
+			if a.Graded > 0 {
+				avg = percent(a.Average)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d/%d (%s) | %d | %s |\n", tableText(a.CourseWork.Title), dueText(a.CourseWork),
+				a.TurnedIn, a.Assigned, percent(a.TurnInRate()*100), a.Late, avg)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(w.Missing) > 0 {
+		b.WriteString("## Students with missing work\n\n")
+		for _, m := range w.Missing {
+			fmt.Fprintf(&b, "- **%s** (%d): %s\n", m.Name, len(m.Items), strings.Join(m.Items, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Upcoming deadlines\n\n")
+	if len(w.Upcoming) == 0 {
+		b.WriteString("Nothing is due next week.\n")
+	}
+	for _, cw := range w.Upcoming {
+		fmt.Fprintf(&b, "- **%s**%s\n", cw.Title, dueSuffix(cw))
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// Markdown returns the report rendered as Markdown.
+func (w *Weekly) Markdown() string {
+	var buf bytes.Buffer
+	w.WriteMarkdown(&buf)
+	return buf.String()
+}
+
+// dueText formats a due date and time as Classroom stores them.
+func dueText(cw *api.CourseWork) string {
+	if cw.DueDate == "" {
+		return "-"
+	}
+	if cw.DueTime == "" {
+		return cw.DueDate
+	}
+	return cw.DueDate + " " + cw.DueTime
+}
+
+// dueSuffix returns " (due ...)" and the points, or "" for undated work.
+func dueSuffix(cw *api.CourseWork) string {
+	s := ""
+	if cw.DueDate != "" {
+		s = " (due " + dueText(cw) + ")"
+	}
+	if cw.MaxPoints > 0 {
+		s += fmt.Sprintf(" - %d pts", cw.MaxPoints)
+	}
+	return s
+}
+
+// percent formats a percentage without decimals.
+func percent(p float64) string {
+	return fmt.Sprintf("%.0f%%", p)
+}
+
+// tableText keeps text from breaking a Markdown table row.
+func tableText(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
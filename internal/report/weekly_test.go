@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+)
+
+// newCourse returns a course with three students and a week of work:
+// an essay due this week, a quiz due last week, and a lab due next week.
+func newCourse() *fake.Client {
+	c := fake.New("teacher")
+	c.AddCourse(&api.Course{ID: "c1", Name: "Biology", Section: "Period 2", OwnerID: "teacher"})
+	for id, name := range map[string]string{"s1": "Ada Byron", "s2": "Jordan Lee", "s3": "Zoë Okafor"} {
+		c.AddStudent(&api.Student{CourseID: "c1", UserID: id, Profile: api.UserProfile{Name: name}})
+	}
+
+	essay := c.AddCourseWork(&api.CourseWork{CourseID: "c1", Title: "Essay", MaxPoints: 20,
+		CreateTime: "2026-03-02T09:00:00Z", DueDate: "2026-03-04", DueTime: "23:59"})
+	quiz := c.AddCourseWork(&api.CourseWork{CourseID: "c1", Title: "Quiz",
+		CreateTime: "2026-02-20T09:00:00Z", DueDate: "2026-02-25"})
+	c.AddCourseWork(&api.CourseWork{CourseID: "c1", Title: "Lab", MaxPoints: 10,
+		CreateTime: "2026-03-05T09:00:00Z", DueDate: "2026-03-10"})
+
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: essay.ID, UserID: "s1", State: api.SubmissionStateReturned, AssignedGrade: 18})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: essay.ID, UserID: "s2", State: api.SubmissionStateTurnedIn, Late: true})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: essay.ID, UserID: "s3", State: api.SubmissionStateCreated})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: quiz.ID, UserID: "s1", State: api.SubmissionStateTurnedIn})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: quiz.ID, UserID: "s2", State: api.SubmissionStateNew})
+	c.AddSubmission(&api.StudentSubmission{CourseID: "c1", CourseWorkID: quiz.ID, UserID: "s3", State: api.SubmissionStateNew})
+	return c
+}
+
+// TestBuildWeekly tests the week's posted work, turn-ins, grades, missing
+// work, and upcoming deadlines.
+func TestBuildWeekly(t *testing.T) {
+	lastDay := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	w, err := BuildWeekly(context.Background(), newCourse(), "c1", lastDay)
+	if err != nil {
+		t.Fatalf("Failed to build report: %v", err)
+	}
+
+	if len(w.Posted) != 2 || w.Posted[0].Title != "Essay" || w.Posted[1].Title != "Lab" {
+		t.Errorf("Expected Essay and Lab posted, got %v", w.Posted)
+	}
+	if len(w.Due) != 1 {
+		t.Fatalf("Expected 1 assignment due, got %d", len(w.Due))
+	}
+	a := w.Due[0]
+	if a.Assigned != 3 || a.TurnedIn != 2 || a.Late != 1 || a.Graded != 1 || a.Average != 90 {
+		t.Errorf("Expected 2/3 turned in, 1 late, 90%% average, got %+v", a)
+	}
+	if len(w.Missing) != 1 || w.Missing[0].Name != "Zoë Okafor" || len(w.Missing[0].Items) != 2 {
+		t.Errorf("Expected Zoë with 2 missing items, got %+v", w.Missing)
+	}
+	if len(w.Upcoming) != 1 || w.Upcoming[0].Title != "Lab" {
+		t.Errorf("Expected Lab upcoming, got %v", w.Upcoming)
+	}
+
+	md := w.Markdown()
+	for _, want := range []string{
+		"# Weekly report: Biology (Period 2)",
+		"Feb 28, 2026 to Mar 6, 2026",
+		"- Turn-in rate: 67% (2/3) for work due this week",
+		"| Essay | 2026-03-04 23:59 | 2/3 (67%) | 1 | 90% |",
+		"- **Zoë Okafor** (2): Essay, Quiz",
+		"- **Lab** (due 2026-03-10) - 10 pts",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in report:\n%s", want, md)
+		}
+	}
+}
+
+// TestRunReport tests the report command.
+func TestRunReport(t *testing.T) {
+	c := newCourse()
+	ctx := context.Background()
+	var stdout, stderr bytes.Buffer
+
+	if err := RunReport(ctx, c, []string{"weekly", "c1", "--to", "2026-03-06"}, &stdout, &stderr); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "## Upcoming deadlines") {
+		t.Errorf("Expected Markdown report, got:\n%s", stdout.String())
+	}
+
+	if err := RunReport(ctx, c, []string{"weekly", "c1", "--to", "March 6"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error for an invalid date")
+	}
+	if err := RunReport(ctx, c, []string{"monthly", "c1"}, &stdout, &stderr); err == nil {
+		t.Error("Expected error for an unknown report")
+	}
+}
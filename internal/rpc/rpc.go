@@ -0,0 +1,364 @@
+// Package rpc serves the client's operations as JSON-RPC 2.0 over a byte
+// stream, so editors and other programs can drive the tool as a subprocess.
+//
+// Messages are newline-delimited: each request, notification, or batch is
+// one line of JSON, and each response is written as one line. Requests are
+// handled in order.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/user/google-classroom/internal/api"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// Version is the JSON-RPC protocol version.
+const Version = "2.0"
+
+// Standard JSON-RPC error codes, plus ServerError for failed API calls.
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+	ServerError    = -32000
+)
+
+// maxLine bounds a single request line.
+const maxLine = 4 << 20
+
+// Service is the set of operations exposed over RPC. *api.Client satisfies it.
+type Service interface {
+	ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error)
+	GetCourse(ctx context.Context, courseID string) (*api.Course, error)
+	ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error)
+	GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*api.CourseWork, error)
+	ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error)
+	GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*api.StudentSubmission, error)
+	TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error
+	SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error)
+	ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error)
+}
+
+// Request is a JSON-RPC request. A request without an ID is a notification
+// and gets no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorData describes a failed API call in Error.Data.
+type ErrorData struct {
+	// Type is a stable name for the failure, e.g. "not_found".
+	Type        string `json:"type"`
+	Suggestion  string `json:"suggestion,omitempty"`
+	Recoverable bool   `json:"recoverable"`
+}
+
+// Params shared by the methods. Field names match the API resource fields.
+type params struct {
+	CourseID     string   `json:"courseId"`
+	CourseWorkID string   `json:"courseWorkId"`
+	SubmissionID string   `json:"submissionId"`
+	UserID       string   `json:"userId"`
+	TeacherID    string   `json:"teacherId"`
+	StudentID    string   `json:"studentId"`
+	States       []string `json:"states"`
+	OrderBy      string   `json:"orderBy"`
+	Grade        *float64 `json:"grade"`
+}
+
+// method handles one RPC method.
+type method struct {
+	// required lists the params that must be set.
+	required []string
+	call     func(ctx context.Context, s Service, p *params) (interface{}, error)
+}
+
+// methods maps method names to handlers.
+var methods = map[string]method{
+	"courses.list": {
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.ListCourses(ctx, &api.ListCoursesOptions{
+				CourseStates: p.States,
+				TeacherID:    p.TeacherID,
+				StudentID:    p.StudentID,
+			})
+		},
+	},
+	"courses.get": {
+		required: []string{"courseId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.GetCourse(ctx, p.CourseID)
+		},
+	},
+	"coursework.list": {
+		required: []string{"courseId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.ListCourseWork(ctx, p.CourseID, &api.ListCourseWorkOptions{
+				States:  p.States,
+				OrderBy: p.OrderBy,
+			})
+		},
+	},
+	"coursework.get": {
+		required: []string{"courseId", "courseWorkId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.GetCourseWork(ctx, p.CourseID, p.CourseWorkID)
+		},
+	},
+	"submissions.list": {
+		required: []string{"courseId", "courseWorkId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.ListStudentSubmissions(ctx, p.CourseID, p.CourseWorkID, &api.ListStudentSubmissionsOptions{
+				UserID: p.UserID,
+				States: p.States,
+			})
+		},
+	},
+	"submissions.get": {
+		required: []string{"courseId", "courseWorkId", "submissionId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.GetStudentSubmission(ctx, p.CourseID, p.CourseWorkID, p.SubmissionID)
+		},
+	},
+	"submissions.turnIn": {
+		required: []string{"courseId", "courseWorkId", "submissionId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			if err := s.TurnIn(ctx, p.CourseID, p.CourseWorkID, p.SubmissionID); err != nil {
+				return nil, err
+			}
+			return s.GetStudentSubmission(ctx, p.CourseID, p.CourseWorkID, p.SubmissionID)
+		},
+	},
+	"submissions.grade": {
+		required: []string{"courseId", "courseWorkId", "submissionId", "grade"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.SetDraftGrade(ctx, p.CourseID, p.CourseWorkID, p.SubmissionID, *p.Grade)
+		},
+	},
+	"announcements.list": {
+		required: []string{"courseId"},
+		call: func(ctx context.Context, s Service, p *params) (interface{}, error) {
+			return s.ListAnnouncements(ctx, p.CourseID, nil)
+		},
+	},
+}
+
+// missing returns the first required param that is unset.
+func (m method) missing(p *params) string {
+	for _, name := range m.required {
+		var set bool
+		switch name {
+		case "courseId":
+			set = p.CourseID != ""
+		case "courseWorkId":
+			set = p.CourseWorkID != ""
+		case "submissionId":
+			set = p.SubmissionID != ""
+		case "grade":
+			set = p.Grade != nil
+		}
+		if !set {
+			return name
+		}
+	}
+	return ""
+}
+
+// Server dispatches JSON-RPC requests to a Service.
+type Server struct {
+	svc Service
+}
+
+// NewServer creates a server for svc.
+func NewServer(svc Service) *Server {
+	return &Server{svc: svc}
+}
+
+// Serve reads requests from r and writes responses to w until r is
+// exhausted or ctx is done. Malformed lines are answered with an error
+// response; only read and write failures end the loop.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		reply := s.handleLine(ctx, line)
+		if reply == nil {
+			continue
+		}
+		if err := enc.Encode(reply); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handleLine handles a single request or a batch. It returns nil when
+// nothing should be written back.
+func (s *Server) handleLine(ctx context.Context, line []byte) interface{} {
+	if line[0] != '[' {
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			return errorResponse(nil, &Error{Code: ParseError, Message: "parse error"})
+		}
+		if resp := s.handle(ctx, &req); resp != nil {
+			return resp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(line, &batch); err != nil {
+		return errorResponse(nil, &Error{Code: ParseError, Message: "parse error"})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &Error{Code: InvalidRequest, Message: "empty batch"})
+	}
+
+	var replies []*Response
+	for _, raw := range batch {
+		var req Request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			replies = append(replies, errorResponse(nil, &Error{Code: InvalidRequest, Message: "invalid request"}))
+			continue
+		}
+		if resp := s.handle(ctx, &req); resp != nil {
+			replies = append(replies, resp)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+// handle runs one request. Notifications are run but get no response.
+func (s *Server) handle(ctx context.Context, req *Request) *Response {
+	result, err := s.call(ctx, req)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, err)
+	}
+	return &Response{JSONRPC: Version, ID: req.ID, Result: result}
+}
+
+// call validates a request and invokes its method.
+func (s *Server) call(ctx context.Context, req *Request) (interface{}, *Error) {
+	if req.JSONRPC != Version || req.Method == "" {
+		return nil, &Error{Code: InvalidRequest, Message: "invalid request"}
+	}
+
+	m, ok := methods[req.Method]
+	if !ok {
+		return nil, &Error{Code: MethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	p := &params{}
+	if len(req.Params) > 0 && !bytes.Equal(req.Params, []byte("null")) {
+		if err := json.Unmarshal(req.Params, p); err != nil {
+			return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+		}
+	}
+	if name := m.missing(p); name != "" {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("missing param: %s", name)}
+	}
+
+	result, err := m.call(ctx, s.svc, p)
+	if err != nil {
+		return nil, apiError(err)
+	}
+	return result, nil
+}
+
+// errorResponse builds an error response. A nil id is written as null.
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: Version, ID: id, Error: err}
+}
+
+// errorTypes names the application error types for ErrorData.
+var errorTypes = map[apperrors.ErrorType]string{
+	apperrors.ErrAuth:         "auth",
+	apperrors.ErrAuthExpired:  "auth_expired",
+	apperrors.ErrAuthRevoked:  "auth_revoked",
+	apperrors.ErrAuthOffline:  "offline",
+	apperrors.ErrAPIRateLimit: "rate_limit",
+	apperrors.ErrAPINotFound:  "not_found",
+	apperrors.ErrAPIForbidden: "forbidden",
+	apperrors.ErrAPINetwork:   "network",
+	apperrors.ErrInvalidInput: "invalid_input",
+}
+
+// apiError converts a failed call into a ServerError with typed data.
+func apiError(err error) *Error {
+	appErr, ok := apperrors.As(err)
+	if !ok {
+		return &Error{Code: ServerError, Message: err.Error(), Data: ErrorData{Type: "unknown"}}
+	}
+
+	kind, ok := errorTypes[appErr.Type]
+	if !ok {
+		kind = "api"
+	}
+	return &Error{
+		Code:    ServerError,
+		Message: appErr.Message,
+		Data: ErrorData{
+			Type:        kind,
+			Suggestion:  appErr.UserSuggestion,
+			Recoverable: appErr.Recoverable,
+		},
+	}
+}
+
+// RunRPC implements `classroom rpc`: it serves svc over stdin and stdout
+// until stdin is closed.
+func RunRPC(ctx context.Context, svc Service, stdin io.Reader, stdout io.Writer) error {
+	return NewServer(svc).Serve(ctx, stdin, stdout)
+}
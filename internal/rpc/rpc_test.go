@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/user/google-classroom/internal/api"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// fakeService serves fixed data and records grades.
+type fakeService struct {
+	submissions map[string]*api.StudentSubmission
+}
+
+func (f *fakeService) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	return []*api.Course{{ID: "c1", Name: "Biology"}}, nil
+}
+
+func (f *fakeService) GetCourse(ctx context.Context, courseID string) (*api.Course, error) {
+	return nil, apperrors.New(apperrors.ErrAPINotFound, "course not found")
+}
+
+func (f *fakeService) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	return nil, nil
+}
+
+func (f *fakeService) GetCourseWork(ctx context.Context, courseID, courseWorkID string) (*api.CourseWork, error) {
+	return &api.CourseWork{ID: courseWorkID}, nil
+}
+
+func (f *fakeService) ListStudentSubmissions(ctx context.Context, courseID, courseWorkID string, opts *api.ListStudentSubmissionsOptions) ([]*api.StudentSubmission, error) {
+	return nil, nil
+}
+
+func (f *fakeService) GetStudentSubmission(ctx context.Context, courseID, courseWorkID, submissionID string) (*api.StudentSubmission, error) {
+	return f.submissions[submissionID], nil
+}
+
+func (f *fakeService) TurnIn(ctx context.Context, courseID, courseWorkID, submissionID string) error {
+	f.submissions[submissionID].State = api.SubmissionStateTurnedIn
+	return nil
+}
+
+func (f *fakeService) SetDraftGrade(ctx context.Context, courseID, courseWorkID, submissionID string, grade float64) (*api.StudentSubmission, error) {
+	sub := f.submissions[submissionID]
+	sub.DraftGrade = int(grade)
+	return sub, nil
+}
+
+func (f *fakeService) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	return nil, nil
+}
+
+// serve runs the requests through a server and decodes each response line.
+func serve(t *testing.T, svc Service, input string) []json.RawMessage {
+	t.Helper()
+	var out strings.Builder
+	if err := RunRPC(context.Background(), svc, strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	var lines []json.RawMessage
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		lines = append(lines, json.RawMessage(scanner.Text()))
+	}
+	return lines
+}
+
+// TestServe tests results, errors, and notifications.
+func TestServe(t *testing.T) {
+	svc := &fakeService{submissions: map[string]*api.StudentSubmission{
+		"s1": {ID: "s1", State: api.SubmissionStateCreated},
+	}}
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"courses.list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"submissions.grade","params":{"courseId":"c1","courseWorkId":"cw1","submissionId":"s1","grade":9}}`,
+		`{"jsonrpc":"2.0","method":"submissions.turnIn","params":{"courseId":"c1","courseWorkId":"cw1","submissionId":"s1"}}`,
+		`{"jsonrpc":"2.0","id":"a","method":"courses.get","params":{"courseId":"x"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"submissions.grade","params":{"courseId":"c1"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"nope"}`,
+		`not json`,
+	}, "\n")
+
+	lines := serve(t, svc, input)
+	if len(lines) != 6 {
+		t.Fatalf("Expected 6 responses (notification skipped), got %d: %s", len(lines), lines)
+	}
+
+	var courses struct {
+		ID     int           `json:"id"`
+		Result []*api.Course `json:"result"`
+	}
+	json.Unmarshal(lines[0], &courses)
+	if courses.ID != 1 || len(courses.Result) != 1 || courses.Result[0].Name != "Biology" {
+		t.Errorf("Expected course list, got %s", lines[0])
+	}
+
+	if svc.submissions["s1"].DraftGrade != 9 {
+		t.Errorf("Expected draft grade 9, got %d", svc.submissions["s1"].DraftGrade)
+	}
+	if svc.submissions["s1"].State != api.SubmissionStateTurnedIn {
+		t.Errorf("Expected notification to turn in, got %s", svc.submissions["s1"].State)
+	}
+
+	wantCodes := []int{ServerError, InvalidParams, MethodNotFound, ParseError}
+	for i, want := range wantCodes {
+		var resp Response
+		json.Unmarshal(lines[i+2], &resp)
+		if resp.Error == nil || resp.Error.Code != want {
+			t.Errorf("Expected error code %d, got %s", want, lines[i+2])
+		}
+	}
+	if !strings.Contains(string(lines[2]), `"type":"not_found"`) || !strings.Contains(string(lines[2]), `"id":"a"`) {
+		t.Errorf("Expected typed not_found error for id a, got %s", lines[2])
+	}
+}
+
+// TestServeBatch tests that a batch gets one array response.
+func TestServeBatch(t *testing.T) {
+	svc := &fakeService{}
+	lines := serve(t, svc, `[{"jsonrpc":"2.0","id":1,"method":"courses.list"},{"jsonrpc":"2.0","id":2,"method":"coursework.get","params":{"courseId":"c1","courseWorkId":"cw9"}}]`)
+	if len(lines) != 1 {
+		t.Fatalf("Expected one batch response, got %d", len(lines))
+	}
+
+	var batch []Response
+	if err := json.Unmarshal(lines[0], &batch); err != nil {
+		t.Fatalf("Expected a JSON array, got %s", lines[0])
+	}
+	if len(batch) != 2 || batch[0].Error != nil || batch[1].Error != nil {
+		t.Errorf("Expected two successful responses, got %s", lines[0])
+	}
+}
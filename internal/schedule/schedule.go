@@ -0,0 +1,180 @@
+// Package schedule parses course meeting times and works out which class is
+// happening now or next.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Meeting is a recurring weekly meeting, such as "Mon/Wed 10:00-11:30".
+type Meeting struct {
+	Days  []time.Weekday
+	Start time.Duration // offset from midnight
+	End   time.Duration // offset from midnight
+}
+
+// Schedule maps a course ID or course name to its meetings.
+type Schedule map[string][]Meeting
+
+// Status describes where a course is in its schedule at a given moment.
+type Status struct {
+	// InSession is true while a meeting is under way.
+	InSession bool
+	// Start and End bound the current meeting, or the next one when the
+	// course is not in session. Both are zero when the course has no meetings.
+	Start time.Time
+	End   time.Time
+}
+
+// IsZero reports whether the course has no scheduled meetings.
+func (s Status) IsZero() bool {
+	return s.Start.IsZero()
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseMeeting parses a meeting such as "Mon/Wed 10:00-11:30". Day names may
+// be separated by "/" or "," and the time range may use "-" or "–".
+func ParseMeeting(s string) (Meeting, error) {
+	fields := strings.Fields(strings.ReplaceAll(s, "–", "-"))
+	if len(fields) != 2 {
+		return Meeting{}, fmt.Errorf("invalid meeting %q: expected \"Mon/Wed 10:00-11:30\"", s)
+	}
+
+	var m Meeting
+	for _, name := range strings.FieldsFunc(fields[0], func(r rune) bool { return r == '/' || r == ',' }) {
+		key := strings.ToLower(name)
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		day, ok := weekdays[key]
+		if !ok {
+			return Meeting{}, fmt.Errorf("invalid day %q in meeting %q", name, s)
+		}
+		m.Days = append(m.Days, day)
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return Meeting{}, fmt.Errorf("invalid time range in meeting %q", s)
+	}
+	var err error
+	if m.Start, err = parseClock(start); err != nil {
+		return Meeting{}, fmt.Errorf("invalid start in meeting %q: %w", s, err)
+	}
+	if m.End, err = parseClock(end); err != nil {
+		return Meeting{}, fmt.Errorf("invalid end in meeting %q: %w", s, err)
+	}
+	if m.End <= m.Start {
+		return Meeting{}, fmt.Errorf("meeting %q ends before it starts", s)
+	}
+
+	return m, nil
+}
+
+// Parse builds a schedule from course keys to meeting strings.
+func Parse(raw map[string][]string) (Schedule, error) {
+	sched := make(Schedule, len(raw))
+	for course, meetings := range raw {
+		for _, s := range meetings {
+			m, err := ParseMeeting(s)
+			if err != nil {
+				return nil, fmt.Errorf("schedule for %s: %w", course, err)
+			}
+			sched[course] = append(sched[course], m)
+		}
+	}
+	return sched, nil
+}
+
+// Meetings returns the meetings for a course, looked up by ID and then by name.
+func (s Schedule) Meetings(courseID, courseName string) []Meeting {
+	if m, ok := s[courseID]; ok {
+		return m
+	}
+	return s[courseName]
+}
+
+// StatusAt returns whether a course is in session at now, or when it next meets.
+func StatusAt(meetings []Meeting, now time.Time) Status {
+	var best Status
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Look one week ahead, starting today.
+	for offset := 0; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, m := range meetings {
+			if !m.meetsOn(day.Weekday()) {
+				continue
+			}
+			start := day.Add(m.Start)
+			end := day.Add(m.End)
+			if !end.After(now) {
+				continue
+			}
+			if !start.After(now) {
+				return Status{InSession: true, Start: start, End: end}
+			}
+			if best.IsZero() || start.Before(best.Start) {
+				best = Status{Start: start, End: end}
+			}
+		}
+	}
+	return best
+}
+
+// Less orders statuses for a daily agenda: classes in session first, then by
+// next start time, with unscheduled courses last.
+func Less(a, b Status) bool {
+	if a.InSession != b.InSession {
+		return a.InSession
+	}
+	if a.IsZero() != b.IsZero() {
+		return !a.IsZero()
+	}
+	return a.Start.Before(b.Start)
+}
+
+// SortBy stably orders items by their schedule status at now.
+func SortBy[T any](items []T, now time.Time, meetings func(T) []Meeting) {
+	statuses := make([]Status, len(items))
+	idx := make([]int, len(items))
+	for i, item := range items {
+		idx[i] = i
+		statuses[i] = StatusAt(meetings(item), now)
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return Less(statuses[idx[a]], statuses[idx[b]])
+	})
+
+	sorted := make([]T, len(items))
+	for i, j := range idx {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+}
+
+// meetsOn reports whether the meeting takes place on day.
+func (m Meeting) meetsOn(day time.Weekday) bool {
+	for _, d := range m.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses "HH:MM" into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
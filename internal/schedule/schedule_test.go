@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseMeeting tests parsing meeting strings.
+func TestParseMeeting(t *testing.T) {
+	m, err := ParseMeeting("Mon/Wed 10:00–11:30")
+	if err != nil {
+		t.Fatalf("ParseMeeting failed: %v", err)
+	}
+	if len(m.Days) != 2 || m.Days[0] != time.Monday || m.Days[1] != time.Wednesday {
+		t.Errorf("Expected Monday and Wednesday, got %v", m.Days)
+	}
+	if m.Start != 10*time.Hour || m.End != 11*time.Hour+30*time.Minute {
+		t.Errorf("Expected 10:00-11:30, got %v-%v", m.Start, m.End)
+	}
+
+	for _, bad := range []string{"", "Mon", "Funday 10:00-11:00", "Mon 11:00-10:00", "Mon 10-11"} {
+		if _, err := ParseMeeting(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+// TestStatusAt tests detecting the current and next class.
+func TestStatusAt(t *testing.T) {
+	m, _ := ParseMeeting("Mon/Wed 10:00-11:30")
+	meetings := []Meeting{m}
+
+	// Monday 2024-03-11 10:15 is during class.
+	now := time.Date(2024, 3, 11, 10, 15, 0, 0, time.UTC)
+	st := StatusAt(meetings, now)
+	if !st.InSession {
+		t.Fatal("Expected class to be in session")
+	}
+	if st.End.Hour() != 11 || st.End.Minute() != 30 {
+		t.Errorf("Expected session to end at 11:30, got %v", st.End)
+	}
+
+	// Monday after class: next meeting is Wednesday 10:00.
+	now = time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+	st = StatusAt(meetings, now)
+	if st.InSession {
+		t.Error("Expected class not to be in session")
+	}
+	if st.Start.Weekday() != time.Wednesday || st.Start.Hour() != 10 {
+		t.Errorf("Expected next meeting Wednesday 10:00, got %v", st.Start)
+	}
+
+	if !StatusAt(nil, now).IsZero() {
+		t.Error("Expected zero status without meetings")
+	}
+}
+
+// TestSortBy tests ordering courses by schedule.
+func TestSortBy(t *testing.T) {
+	sched, err := Parse(map[string][]string{
+		"history": {"Mon 13:00-14:00"},
+		"math":    {"Mon 10:00-11:00"},
+		"art":     {"Mon 09:00-12:00"},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	courses := []string{"none", "history", "math", "art"}
+	now := time.Date(2024, 3, 11, 9, 30, 0, 0, time.UTC)
+	SortBy(courses, now, func(c string) []Meeting { return sched.Meetings("", c) })
+
+	want := []string{"art", "math", "history", "none"}
+	for i := range want {
+		if courses[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, courses)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package schema versions the JSON files the app keeps on disk (cache
+// entries, the outbox, checklists, usage) so that a file written by an
+// older release is migrated on read instead of failing to parse.
+//
+// Every file carries a top-level "version" field. Files from before
+// versioning have none and are treated as version 0.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrTooNew means the file was written by a newer release whose format
+// this one does not understand.
+var ErrTooNew = errors.New("written by a newer version of google-classroom")
+
+// Doc is a decoded JSON object, keyed by top-level field. Migrations edit
+// it in place.
+type Doc map[string]json.RawMessage
+
+// Migration upgrades a document by one version.
+type Migration func(Doc) error
+
+// Schema describes one file format.
+type Schema struct {
+	// Name identifies the file in errors, e.g. "outbox".
+	Name string
+	// Version is the current format version.
+	Version int
+	// Migrations[i] upgrades version i to version i+1. A nil entry means
+	// the change needs no rewriting, e.g. a new optional field.
+	Migrations []Migration
+}
+
+// Decode parses data into v, migrating it from the version it was written
+// with to s.Version first. It reports the version the data had on disk.
+// Data from a newer version returns an error wrapping ErrTooNew.
+func (s Schema) Decode(data []byte, v any) (int, error) {
+	var doc Doc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", s.Name, err)
+	}
+	if doc == nil {
+		doc = Doc{}
+	}
+
+	from := 0
+	if raw, ok := doc["version"]; ok {
+		if err := json.Unmarshal(raw, &from); err != nil {
+			return 0, fmt.Errorf("failed to parse %s version: %w", s.Name, err)
+		}
+	}
+	if from > s.Version {
+		return from, fmt.Errorf("%s version %d is %w (this release reads up to %d)", s.Name, from, ErrTooNew, s.Version)
+	}
+
+	if from < s.Version {
+		if err := s.migrate(doc, from); err != nil {
+			return from, err
+		}
+		data, _ = json.Marshal(doc)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return from, fmt.Errorf("failed to parse %s: %w", s.Name, err)
+	}
+	return from, nil
+}
+
+// migrate runs the migrations from version from up to s.Version.
+func (s Schema) migrate(doc Doc, from int) error {
+	for ver := from; ver < s.Version; ver++ {
+		if ver < len(s.Migrations) && s.Migrations[ver] != nil {
+			if err := s.Migrations[ver](doc); err != nil {
+				return fmt.Errorf("failed to migrate %s from version %d: %w", s.Name, ver, err)
+			}
+		}
+	}
+	doc["version"], _ = json.Marshal(s.Version)
+	return nil
+}
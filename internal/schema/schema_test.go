@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type note struct {
+	Version int      `json:"version"`
+	Title   string   `json:"title"`
+	Tags    []string `json:"tags"`
+}
+
+// noteSchema renames "name" to "title" at version 1 and turns a single
+// "tag" into "tags" at version 2.
+var noteSchema = Schema{
+	Name:    "note",
+	Version: 2,
+	Migrations: []Migration{
+		func(d Doc) error {
+			if raw, ok := d["name"]; ok {
+				d["title"] = raw
+				delete(d, "name")
+			}
+			return nil
+		},
+		func(d Doc) error {
+			raw, ok := d["tag"]
+			if !ok {
+				return nil
+			}
+			var tag string
+			if err := json.Unmarshal(raw, &tag); err != nil {
+				return err
+			}
+			d["tags"], _ = json.Marshal([]string{tag})
+			delete(d, "tag")
+			return nil
+		},
+	},
+}
+
+// TestDecodeMigrates tests that older documents are upgraded step by step.
+func TestDecodeMigrates(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		from int
+	}{
+		{"unversioned", `{"name": "Essay", "tag": "draft"}`, 0},
+		{"version 1", `{"version": 1, "title": "Essay", "tag": "draft"}`, 1},
+		{"current", `{"version": 2, "title": "Essay", "tags": ["draft"]}`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n note
+			from, err := noteSchema.Decode([]byte(tt.data), &n)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			if from != tt.from {
+				t.Errorf("Expected version %d on disk, got %d", tt.from, from)
+			}
+			if n.Version != 2 || n.Title != "Essay" || len(n.Tags) != 1 || n.Tags[0] != "draft" {
+				t.Errorf("Expected migrated note, got %+v", n)
+			}
+		})
+	}
+}
+
+// TestDecodeTooNew tests that documents from a newer release are refused.
+func TestDecodeTooNew(t *testing.T) {
+	var n note
+	_, err := noteSchema.Decode([]byte(`{"version": 3, "title": "Essay"}`), &n)
+	if !errors.Is(err, ErrTooNew) {
+		t.Errorf("Expected ErrTooNew, got %v", err)
+	}
+}
+
+// TestDecodeMigrationError tests that a failing migration is reported.
+func TestDecodeMigrationError(t *testing.T) {
+	var n note
+	_, err := noteSchema.Decode([]byte(`{"version": 1, "tag": 7}`), &n)
+	if err == nil {
+		t.Error("Expected an error for a tag that is not a string")
+	}
+
+	if _, err := noteSchema.Decode([]byte(`not json`), &n); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
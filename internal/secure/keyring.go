@@ -0,0 +1,130 @@
+package secure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoSecret means the keyring holds no secret under the given name.
+var ErrNoSecret = errors.New("no secret in the keyring")
+
+// Keyring stores one secret in the OS keyring: the macOS Keychain through
+// security(1), libsecret through secret-tool(1) on Linux and the BSDs, and
+// the Windows Credential Manager. Other platforms report ErrNoKeyring.
+type Keyring struct {
+	// Service and Account name the secret.
+	Service string
+	Account string
+	// Label is shown for the secret in keyring managers.
+	Label string
+}
+
+// Available reports whether the keyring can be used on this system.
+func (k *Keyring) Available() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	tool := k.tool()
+	if tool == "" {
+		return false
+	}
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+func (k *Keyring) tool() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "security"
+	case "linux", "freebsd", "openbsd":
+		return "secret-tool"
+	default:
+		return ""
+	}
+}
+
+// Get returns the secret, or ErrNoSecret when there is none.
+func (k *Keyring) Get() ([]byte, error) {
+	if !k.Available() {
+		return nil, ErrNoKeyring
+	}
+	if runtime.GOOS == "windows" {
+		return credRead(k.target())
+	}
+
+	var cmd *exec.Cmd
+	if k.tool() == "security" {
+		cmd = exec.Command("security", "find-generic-password", "-s", k.Service, "-a", k.Account, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", k.Service, "account", k.Account)
+	}
+	out, err := cmd.Output()
+	secret := bytes.TrimSpace(out)
+	if err != nil || len(secret) == 0 {
+		// Both tools exit non-zero when the item does not exist
+		var exitErr *exec.ExitError
+		if err == nil || errors.As(err, &exitErr) {
+			return nil, ErrNoSecret
+		}
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(string(secret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keyring secret: %w", err)
+	}
+	return data, nil
+}
+
+// Set stores the secret, replacing any previous one. The secret is passed
+// on stdin so it never appears in the process list.
+func (k *Keyring) Set(secret []byte) error {
+	if !k.Available() {
+		return ErrNoKeyring
+	}
+	if runtime.GOOS == "windows" {
+		return credWrite(k.target(), k.Account, secret)
+	}
+	// Base64 keeps the secret free of characters the tools would interpret
+	encoded := base64.StdEncoding.EncodeToString(secret)
+
+	var cmd *exec.Cmd
+	if k.tool() == "security" {
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", k.Service, k.Account, encoded))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label="+k.Label, "service", k.Service, "account", k.Account)
+		cmd.Stdin = strings.NewReader(encoded)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write keyring: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Delete removes the secret. A missing secret is already deleted.
+func (k *Keyring) Delete() error {
+	if !k.Available() {
+		return ErrNoKeyring
+	}
+	if runtime.GOOS == "windows" {
+		return credDelete(k.target())
+	}
+	var cmd *exec.Cmd
+	if k.tool() == "security" {
+		cmd = exec.Command("security", "delete-generic-password", "-s", k.Service, "-a", k.Account)
+	} else {
+		cmd = exec.Command("secret-tool", "clear", "service", k.Service, "account", k.Account)
+	}
+	cmd.Run()
+	return nil
+}
+
+// target is the Windows credential name.
+func (k *Keyring) target() string {
+	return k.Service + ":" + k.Account
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package secure
+
+// The Windows Credential Manager is only reachable on Windows; Keyring
+// never calls these elsewhere.
+
+func credRead(target string) ([]byte, error) { return nil, ErrNoKeyring }
+
+func credWrite(target, user string, secret []byte) error { return ErrNoKeyring }
+
+func credDelete(target string) error { return ErrNoKeyring }
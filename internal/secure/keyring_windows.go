@@ -0,0 +1,105 @@
+//go:build windows
+
+package secure
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	// credMaxBlobSize is the largest secret a generic credential holds.
+	credMaxBlobSize = 5 * 512
+)
+
+// errNotFound is ERROR_NOT_FOUND, returned for a missing credential.
+const errNotFound = syscall.Errno(1168)
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credRead reads a generic credential from the Credential Manager.
+func credRead(target string) ([]byte, error) {
+	name, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return nil, err
+	}
+	var cred *credential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(err, errNotFound) {
+			return nil, ErrNoSecret
+		}
+		return nil, fmt.Errorf("failed to read credential: %w", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return append([]byte(nil), blob...), nil
+}
+
+// credWrite stores a generic credential, replacing any previous one.
+func credWrite(target, user string, secret []byte) error {
+	if len(secret) > credMaxBlobSize {
+		return fmt.Errorf("secret is %d bytes; the Credential Manager holds at most %d", len(secret), credMaxBlobSize)
+	}
+	name, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(user)
+	if err != nil {
+		return err
+	}
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         name,
+		CredentialBlobSize: uint32(len(secret)),
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(secret) > 0 {
+		cred.CredentialBlob = &secret[0]
+	}
+	if r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("failed to write credential: %w", err)
+	}
+	return nil
+}
+
+// credDelete removes a generic credential. A missing one is not an error.
+func credDelete(target string) error {
+	name, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0); r == 0 && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package secure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Keyring service and account names for the stored key ring.
+const (
+	keyringService = "google-classroom"
+	keyringAccount = "data-key"
+)
+
+// ErrNotEnabled means no key ring has been stored yet.
+var ErrNotEnabled = errors.New("encryption is not enabled")
+
+// ErrNoKeyring means the OS keyring cannot be used on this system.
+var ErrNoKeyring = errors.New("no OS keyring available")
+
+// KeyStore persists the key ring.
+type KeyStore interface {
+	// Load returns the stored ring, or ErrNotEnabled when there is none.
+	Load() (*Ring, error)
+	Save(ring *Ring) error
+	Delete() error
+	// Name describes where the ring is kept, e.g. "system keyring".
+	Name() string
+}
+
+// DefaultKeyDir returns the directory holding the fallback key file.
+func DefaultKeyDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "google-classroom"), nil
+}
+
+// DefaultKeyStore returns a store that keeps the ring in the OS keyring
+// when one is available and falls back to a 0600 key file in dir.
+func DefaultKeyStore(dir string) KeyStore {
+	return &autoStore{
+		keyring: &KeyringStore{},
+		file:    &FileStore{Path: filepath.Join(dir, "data.key")},
+	}
+}
+
+// Load returns a sealer for the ring in store, or nil when encryption is
+// not enabled.
+func Load(store KeyStore) (*Sealer, error) {
+	ring, err := store.Load()
+	if errors.Is(err, ErrNotEnabled) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewSealer(ring), nil
+}
+
+func encodeRing(ring *Ring) ([]byte, error) {
+	data, err := json.Marshal(ring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key ring: %w", err)
+	}
+	return data, nil
+}
+
+func decodeRing(data []byte) (*Ring, error) {
+	var ring Ring
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return nil, fmt.Errorf("failed to parse key ring: %w", err)
+	}
+	if ring.Current == nil || len(ring.Current.Secret) != keySize {
+		return nil, errors.New("key ring has no valid current key")
+	}
+	return &ring, nil
+}
+
+// FileStore keeps the ring in a file readable only by its owner.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the ring from the key file.
+func (s *FileStore) Load() (*Ring, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotEnabled
+		}
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return decodeRing(data)
+}
+
+// Save writes the ring through a temporary file with 0600 permissions.
+func (s *FileStore) Save(ring *Ring) error {
+	data, err := encodeRing(ring)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the key file.
+func (s *FileStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete key file: %w", err)
+	}
+	return nil
+}
+
+// Name describes the store.
+func (s *FileStore) Name() string {
+	return "key file " + s.Path
+}
+
+// KeyringStore keeps the ring in the OS keyring. Platforms without a
+// supported keyring report ErrNoKeyring.
+type KeyringStore struct{}
+
+// dataKeyring is where the ring is kept.
+var dataKeyring = &Keyring{Service: keyringService, Account: keyringAccount, Label: "Google Classroom data key"}
+
+// Available reports whether the keyring can be used.
+func (s *KeyringStore) Available() bool {
+	return dataKeyring.Available()
+}
+
+// Load reads the ring from the keyring.
+func (s *KeyringStore) Load() (*Ring, error) {
+	data, err := dataKeyring.Get()
+	if errors.Is(err, ErrNoSecret) {
+		return nil, ErrNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeRing(data)
+}
+
+// Save stores the ring in the keyring, replacing any previous one.
+func (s *KeyringStore) Save(ring *Ring) error {
+	data, err := encodeRing(ring)
+	if err != nil {
+		return err
+	}
+	return dataKeyring.Set(data)
+}
+
+// Delete removes the ring from the keyring.
+func (s *KeyringStore) Delete() error {
+	return dataKeyring.Delete()
+}
+
+// Name describes the store.
+func (s *KeyringStore) Name() string {
+	return "system keyring"
+}
+
+// autoStore prefers the keyring and falls back to the key file when the
+// keyring is missing or cannot be written, e.g. without a D-Bus session.
+type autoStore struct {
+	keyring *KeyringStore
+	file    *FileStore
+	// active is the store that last loaded or saved the ring.
+	active KeyStore
+}
+
+func (s *autoStore) Load() (*Ring, error) {
+	if ring, err := s.keyring.Load(); err == nil {
+		s.active = s.keyring
+		return ring, nil
+	}
+	ring, err := s.file.Load()
+	if err == nil {
+		s.active = s.file
+	}
+	return ring, err
+}
+
+func (s *autoStore) Save(ring *Ring) error {
+	// Keep using the file once the ring lives there, so a rotation never
+	// leaves two diverging copies.
+	if _, err := s.file.Load(); err != nil {
+		if err := s.keyring.Save(ring); err == nil {
+			s.active = s.keyring
+			return nil
+		}
+	}
+	if err := s.file.Save(ring); err != nil {
+		return err
+	}
+	s.active = s.file
+	return nil
+}
+
+func (s *autoStore) Delete() error {
+	s.keyring.Delete()
+	return s.file.Delete()
+}
+
+func (s *autoStore) Name() string {
+	if s.active == nil {
+		if s.keyring.Available() {
+			return s.keyring.Name()
+		}
+		return s.file.Name()
+	}
+	return s.active.Name()
+}
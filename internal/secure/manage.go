@@ -0,0 +1,268 @@
+package secure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Targets lists the local data protected by the key.
+type Targets struct {
+	// Files are individual files such as tokens.json and outbox.json.
+	Files []string
+	// Dirs hold many files, such as the cache; every *.json file directly
+	// inside is protected.
+	Dirs []string
+}
+
+// paths returns every existing file covered by t.
+func (t Targets) paths() ([]string, error) {
+	var paths []string
+	for _, f := range t.Files {
+		if _, err := os.Stat(f); err == nil {
+			paths = append(paths, f)
+		}
+	}
+	for _, dir := range t.Dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// Status describes the encryption state.
+type Status struct {
+	Enabled bool
+	// Key is the current key; nil when encryption is not enabled.
+	Key *Key
+	// Store says where the key is kept.
+	Store string
+	// Encrypted and Plaintext count the protected files in each state.
+	Encrypted int
+	Plaintext int
+}
+
+// Manager enables encryption and rotates the key for a set of targets.
+type Manager struct {
+	Store   KeyStore
+	Targets Targets
+}
+
+// Status reports whether encryption is enabled and how many files are
+// encrypted.
+func (m *Manager) Status() (*Status, error) {
+	st := &Status{}
+	ring, err := m.Store.Load()
+	switch {
+	case errors.Is(err, ErrNotEnabled):
+	case err != nil:
+		return nil, err
+	default:
+		st.Enabled = true
+		st.Key = ring.Current
+	}
+	st.Store = m.Store.Name()
+
+	paths, err := m.Targets.paths()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		if IsSealed(data) || IsSecretSealed(data) {
+			st.Encrypted++
+		} else {
+			st.Plaintext++
+		}
+	}
+	return st, nil
+}
+
+// Enable generates a key, stores it, and encrypts every existing target.
+// It fails if encryption is already enabled.
+func (m *Manager) Enable() (*Key, int, error) {
+	if _, err := m.Store.Load(); err == nil {
+		return nil, 0, errors.New("encryption is already enabled; use rotate to change the key")
+	} else if !errors.Is(err, ErrNotEnabled) {
+		return nil, 0, err
+	}
+
+	key, err := NewKey()
+	if err != nil {
+		return nil, 0, err
+	}
+	ring := &Ring{Current: key}
+	// Store the key before touching any data so nothing is ever encrypted
+	// with a key that was not saved.
+	if err := m.Store.Save(ring); err != nil {
+		return nil, 0, err
+	}
+
+	n, err := m.reseal(NewSealer(ring))
+	return key, n, err
+}
+
+// Rotate replaces the key and re-encrypts every target with the new one.
+// The old key stays in the ring until every file is rewritten, so an
+// interrupted rotation can simply be run again.
+func (m *Manager) Rotate() (*Key, int, error) {
+	ring, err := m.Store.Load()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, err := NewKey()
+	if err != nil {
+		return nil, 0, err
+	}
+	// An earlier interrupted rotation may have left files under Previous;
+	// those are re-encrypted before it is dropped.
+	old := ring.Current
+	if ring.Previous != nil {
+		n, err := m.reseal(NewSealer(ring))
+		if err != nil {
+			return nil, n, err
+		}
+	}
+
+	ring = &Ring{Current: key, Previous: old}
+	if err := m.Store.Save(ring); err != nil {
+		return nil, 0, err
+	}
+	n, err := m.reseal(NewSealer(ring))
+	if err != nil {
+		return nil, n, err
+	}
+
+	ring.Previous = nil
+	if err := m.Store.Save(ring); err != nil {
+		return nil, n, err
+	}
+	return key, n, nil
+}
+
+// reseal rewrites every target with the sealer's current key. It returns
+// the number of files written. Files encrypted with a passphrase or the
+// machine identity keep their own key and are left alone.
+func (m *Manager) reseal(s *Sealer) (int, error) {
+	paths, err := m.Targets.paths()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return n, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if IsSecretSealed(data) {
+			continue
+		}
+		plaintext, err := s.Open(data)
+		if err != nil {
+			return n, fmt.Errorf("failed to decrypt %s: %w", p, err)
+		}
+		sealed, err := s.Seal(plaintext)
+		if err != nil {
+			return n, err
+		}
+		if err := WriteFile(p, sealed); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ReadFile reads path and decrypts it with s. A nil sealer reads
+// plaintext only.
+func ReadFile(path string, s *Sealer) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.Open(data)
+}
+
+// SealFile encrypts data with s and writes it to path. A nil sealer
+// writes plaintext.
+func SealFile(path string, data []byte, s *Sealer) error {
+	sealed, err := s.Seal(data)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, sealed)
+}
+
+// WriteFile writes data through a temporary file with 0600 permissions so
+// a crash never leaves a half-written file.
+func WriteFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// RunSecure implements `classroom secure enable|rotate|status`.
+func RunSecure(m *Manager, args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("usage: secure enable|rotate|status")
+	}
+
+	switch args[0] {
+	case "enable":
+		key, n, err := m.Enable()
+		if err != nil {
+			return fmt.Errorf("failed to enable encryption: %w", err)
+		}
+		fmt.Fprintf(stdout, "Encryption enabled with key %s, stored in %s.\n", key.ID, m.Store.Name())
+		fmt.Fprintf(stdout, "Encrypted %d file(s).\n", n)
+
+	case "rotate":
+		key, n, err := m.Rotate()
+		if errors.Is(err, ErrNotEnabled) {
+			return errors.New("encryption is not enabled; run `secure enable` first")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rotate key: %w", err)
+		}
+		fmt.Fprintf(stdout, "Rotated to key %s. Re-encrypted %d file(s).\n", key.ID, n)
+
+	case "status":
+		st, err := m.Status()
+		if err != nil {
+			return err
+		}
+		if !st.Enabled {
+			fmt.Fprintln(stdout, "Encryption: disabled")
+			fmt.Fprintf(stdout, "Files:      %d plaintext\n", st.Plaintext)
+			fmt.Fprintln(stdout, "Run `secure enable` to encrypt tokens, cache, and the outbox.")
+			return nil
+		}
+		fmt.Fprintln(stdout, "Encryption: enabled (AES-256-GCM)")
+		fmt.Fprintf(stdout, "Key:        %s, created %s\n", st.Key.ID, st.Key.Created.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(stdout, "Stored in:  %s\n", st.Store)
+		files := fmt.Sprintf("%d encrypted", st.Encrypted)
+		if st.Plaintext > 0 {
+			files += fmt.Sprintf(", %d plaintext", st.Plaintext)
+		}
+		fmt.Fprintf(stdout, "Files:      %s\n", files)
+
+	default:
+		return fmt.Errorf("unknown command %q; usage: secure enable|rotate|status", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// secretMagic prefixes data encrypted with a key derived from a secret, so
+// it can be told apart from plaintext and from data sealed with the key
+// ring.
+var secretMagic = []byte("gcpw1\x00")
+
+// saltSize is the PBKDF2 salt length in bytes.
+const saltSize = 16
+
+// secretIterations is the PBKDF2-SHA256 work factor for new data. Data
+// records its own count, so raising it keeps older files readable.
+var secretIterations = 600_000
+
+// ErrWrongSecret means data could not be decrypted with the given
+// passphrase or machine identity.
+var ErrWrongSecret = errors.New("wrong passphrase or machine identity")
+
+// IsSecretSealed reports whether data was encrypted with SealWithSecret.
+func IsSecretSealed(data []byte) bool {
+	return bytes.HasPrefix(data, secretMagic)
+}
+
+// SealWithSecret encrypts plaintext with AES-256-GCM under a key derived
+// from secret with PBKDF2-SHA256 and a random salt. The result is magic,
+// the salt, the iteration count, the nonce, and the ciphertext.
+func SealWithSecret(secret, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	k, err := deriveKey(secret, salt, secretIterations)
+	if err != nil {
+		return nil, err
+	}
+	return sealDerived(k, salt, secretIterations, plaintext)
+}
+
+// OpenWithSecret decrypts data sealed by SealWithSecret.
+func OpenWithSecret(secret, data []byte) ([]byte, error) {
+	salt, iterations, body, err := splitSecretSealed(data)
+	if err != nil {
+		return nil, err
+	}
+	k, err := deriveKey(secret, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(k, body)
+	if err != nil {
+		return nil, ErrWrongSecret
+	}
+	return plaintext, nil
+}
+
+// sealDerived encrypts plaintext with k, derived from a secret with salt
+// and iterations, in the SealWithSecret format.
+func sealDerived(k *Key, salt []byte, iterations int, plaintext []byte) ([]byte, error) {
+	sealed, err := seal(k, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// seal adds the key ring header, which a derived key has no use for
+	body := sealed[len(magic)+1+len(k.ID):]
+	out := make([]byte, 0, len(secretMagic)+saltSize+4+len(body))
+	out = append(out, secretMagic...)
+	out = append(out, salt...)
+	out = binary.BigEndian.AppendUint32(out, uint32(iterations))
+	return append(out, body...), nil
+}
+
+// splitSecretSealed returns the salt, iteration count, and the nonce and
+// ciphertext of data sealed with a derived key.
+func splitSecretSealed(data []byte) (salt []byte, iterations int, body []byte, err error) {
+	if !IsSecretSealed(data) {
+		return nil, 0, nil, errors.New("data is not encrypted with a passphrase")
+	}
+	rest := data[len(secretMagic):]
+	if len(rest) < saltSize+4 {
+		return nil, 0, nil, errors.New("encrypted data is truncated")
+	}
+	return rest[:saltSize], int(binary.BigEndian.Uint32(rest[saltSize : saltSize+4])), rest[saltSize+4:], nil
+}
+
+// NewSecretSealer returns a sealer for data written too often to derive a
+// key per write, as SealWithSecret does, such as cache entries. The key is
+// derived from the secret source once, with a salt fixed by purpose, and
+// the data is in the SealWithSecret format, so key rotation leaves it
+// alone. The source is not called until the sealer is first used.
+func NewSecretSealer(source func() ([]byte, error), purpose string) *Sealer {
+	salt := sha256.Sum256([]byte("google-classroom\x00" + purpose))
+	return &Sealer{derived: &derivedKeys{source: source, salt: salt[:saltSize]}}
+}
+
+// derivedKeys holds the keys a secret sealer has derived, by salt and
+// iteration count, so each is derived once.
+type derivedKeys struct {
+	source func() ([]byte, error)
+	salt   []byte
+
+	mu     sync.Mutex
+	secret []byte
+	keys   map[string]*Key
+}
+
+// key returns the key for salt and iterations.
+func (d *derivedKeys) key(salt []byte, iterations int) (*Key, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.secret == nil {
+		secret, err := d.source()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get encryption secret: %w", err)
+		}
+		d.secret = secret
+	}
+	id := fmt.Sprintf("%x/%d", salt, iterations)
+	if k, ok := d.keys[id]; ok {
+		return k, nil
+	}
+	k, err := deriveKey(d.secret, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	if d.keys == nil {
+		d.keys = make(map[string]*Key)
+	}
+	d.keys[id] = k
+	return k, nil
+}
+
+func (d *derivedKeys) seal(plaintext []byte) ([]byte, error) {
+	k, err := d.key(d.salt, secretIterations)
+	if err != nil {
+		return nil, err
+	}
+	return sealDerived(k, d.salt, secretIterations, plaintext)
+}
+
+func (d *derivedKeys) open(data []byte) ([]byte, error) {
+	salt, iterations, body, err := splitSecretSealed(data)
+	if err != nil {
+		return nil, err
+	}
+	k, err := d.key(salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(k, body)
+	if err != nil {
+		return nil, ErrWrongSecret
+	}
+	return plaintext, nil
+}
+
+// deriveKey stretches secret into an AES-256 key.
+func deriveKey(secret, salt []byte, iterations int) (*Key, error) {
+	if iterations < 1 {
+		return nil, errors.New("encrypted data has an invalid iteration count")
+	}
+	k, err := pbkdf2.Key(sha256.New, string(secret), salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return &Key{Secret: k}, nil
+}
+
+// MachineSecret returns a secret tied to this machine and the current
+// user: the OS machine ID (/etc/machine-id on Linux, the hardware UUID on
+// macOS, MachineGuid on Windows) and the user ID. It keeps data from being
+// readable when copied to another machine or account, but anyone who can
+// run code as the user here can derive it too, so a passphrase is stronger.
+func MachineSecret() ([]byte, error) {
+	id, err := machineID()
+	if err != nil {
+		return nil, err
+	}
+	u, err := user.Current()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+	return []byte("google-classroom\x00" + id + "\x00" + u.Uid), nil
+}
+
+var (
+	platformUUID = regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+	machineGUID  = regexp.MustCompile(`MachineGuid\s+REG_SZ\s+(\S+)`)
+)
+
+// machineID returns the OS identifier of this machine.
+func machineID() (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read hardware UUID: %w", err)
+		}
+		if m := platformUUID.FindSubmatch(out); m != nil {
+			return string(m[1]), nil
+		}
+	case "windows":
+		out, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read MachineGuid: %w", err)
+		}
+		if m := machineGUID.FindSubmatch(out); m != nil {
+			return string(m[1]), nil
+		}
+	default:
+		for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id", "/etc/hostid"} {
+			if data, err := os.ReadFile(path); err == nil {
+				if id := strings.TrimSpace(string(data)); id != "" {
+					return id, nil
+				}
+			}
+		}
+	}
+	return "", errors.New("no machine ID available; use a passphrase instead")
+}
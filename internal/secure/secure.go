@@ -0,0 +1,172 @@
+// Package secure encrypts the app's local data (tokens, cache, and the
+// offline outbox) with a key kept in the OS keyring or a private key file,
+// and manages that key: enabling encryption, rotating the key, and
+// reporting status.
+package secure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// magic prefixes every encrypted file so plaintext files written before
+// encryption was enabled can still be read.
+var magic = []byte("gcenc1\x00")
+
+// keySize is the AES-256 key length in bytes.
+const keySize = 32
+
+// ErrUnknownKey means data was encrypted with a key that is no longer held.
+var ErrUnknownKey = errors.New("data was encrypted with an unknown key")
+
+// ErrNoKey means data is encrypted but encryption is not enabled here.
+var ErrNoKey = errors.New("data is encrypted but no encryption key is available")
+
+// Key is one AES-256 data key.
+type Key struct {
+	ID      string    `json:"id"`
+	Secret  []byte    `json:"secret"`
+	Created time.Time `json:"created"`
+}
+
+// NewKey generates a random key.
+func NewKey() (*Key, error) {
+	secret := make([]byte, keySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+	return &Key{ID: hex.EncodeToString(id), Secret: secret, Created: time.Now().UTC()}, nil
+}
+
+// Ring holds the current key and, during a rotation, the key being
+// replaced, so data sealed with either can be opened.
+type Ring struct {
+	Current  *Key `json:"current"`
+	Previous *Key `json:"previous,omitempty"`
+}
+
+// key returns the key with id.
+func (r *Ring) key(id string) *Key {
+	for _, k := range []*Key{r.Current, r.Previous} {
+		if k != nil && k.ID == id {
+			return k
+		}
+	}
+	return nil
+}
+
+// Sealer encrypts and decrypts data with a key ring, or with a key derived
+// from a secret (see NewSecretSealer). A nil *Sealer leaves data in
+// plaintext, so callers can use one unconditionally.
+type Sealer struct {
+	ring    *Ring
+	derived *derivedKeys
+}
+
+// NewSealer creates a sealer for ring.
+func NewSealer(ring *Ring) *Sealer {
+	return &Sealer{ring: ring}
+}
+
+// Seal encrypts plaintext with the current key using AES-GCM. The result
+// is magic, the key ID length and ID, the nonce, and the ciphertext.
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	if s == nil {
+		return plaintext, nil
+	}
+	if s.derived != nil {
+		return s.derived.seal(plaintext)
+	}
+	return seal(s.ring.Current, plaintext)
+}
+
+// Open decrypts data sealed with any key in the ring. Data without the
+// encryption header is returned unchanged, so files written before
+// encryption was enabled keep working until they are rewritten.
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if s != nil && s.derived != nil && IsSecretSealed(data) {
+		return s.derived.open(data)
+	}
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if s == nil || s.ring == nil {
+		return nil, ErrNoKey
+	}
+
+	rest := data[len(magic):]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	id := string(rest[1 : 1+rest[0]])
+	k := s.ring.key(id)
+	if k == nil {
+		return nil, ErrUnknownKey
+	}
+	return open(k, rest[1+rest[0]:])
+}
+
+// KeyID returns the ID of the current key, or "" for a nil sealer.
+func (s *Sealer) KeyID() string {
+	if s == nil || s.ring == nil {
+		return ""
+	}
+	return s.ring.Current.ID
+}
+
+// IsSealed reports whether data carries the encryption header.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+func seal(k *Key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(k)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(magic)+1+len(k.ID)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, magic...)
+	out = append(out, byte(len(k.ID)))
+	out = append(out, k.ID...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+func open(k *Key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(k)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(k *Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,220 @@
+package secure
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSealOpen tests round trips, plaintext passthrough, and unknown keys.
+func TestSealOpen(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	s := NewSealer(&Ring{Current: key})
+
+	sealed, err := s.Seal([]byte(`{"access_token":"secret"}`))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("secret")) {
+		t.Error("Expected sealed data to hide the plaintext")
+	}
+
+	plain, err := s.Open(sealed)
+	if err != nil || string(plain) != `{"access_token":"secret"}` {
+		t.Errorf("Expected round trip, got %q, %v", plain, err)
+	}
+
+	if plain, err := s.Open([]byte(`{"a":1}`)); err != nil || string(plain) != `{"a":1}` {
+		t.Errorf("Expected plaintext to pass through, got %q, %v", plain, err)
+	}
+
+	other, _ := NewKey()
+	if _, err := NewSealer(&Ring{Current: other}).Open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+	if _, err := (*Sealer)(nil).Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey, got %v", err)
+	}
+}
+
+// TestManager tests enabling encryption and rotating the key.
+func TestManager(t *testing.T) {
+	dir := t.TempDir()
+	tokens := filepath.Join(dir, "tokens.json")
+	cacheDir := filepath.Join(dir, "cache")
+	os.MkdirAll(cacheDir, 0700)
+	os.WriteFile(tokens, []byte(`{"access_token":"a"}`), 0600)
+	os.WriteFile(filepath.Join(cacheDir, "courses.json"), []byte(`{"data":[]}`), 0600)
+
+	store := &FileStore{Path: filepath.Join(dir, "data.key")}
+	m := &Manager{Store: store, Targets: Targets{
+		Files: []string{tokens, filepath.Join(dir, "missing.json")},
+		Dirs:  []string{cacheDir},
+	}}
+
+	st, err := m.Status()
+	if err != nil || st.Enabled || st.Plaintext != 2 {
+		t.Fatalf("Expected disabled with 2 plaintext files, got %+v, %v", st, err)
+	}
+
+	first, n, err := m.Enable()
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 files encrypted, got %d, %v", n, err)
+	}
+	if _, _, err := m.Enable(); err == nil {
+		t.Error("Expected enabling twice to fail")
+	}
+
+	second, n, err := m.Rotate()
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 files re-encrypted, got %d, %v", n, err)
+	}
+	if second.ID == first.ID {
+		t.Error("Expected a new key after rotation")
+	}
+
+	ring, err := store.Load()
+	if err != nil || ring.Previous != nil || ring.Current.ID != second.ID {
+		t.Fatalf("Expected only the new key in the ring, got %+v, %v", ring, err)
+	}
+	data, err := ReadFile(tokens, NewSealer(&Ring{Current: second}))
+	if err != nil || string(data) != `{"access_token":"a"}` {
+		t.Errorf("Expected token readable with the new key, got %q, %v", data, err)
+	}
+
+	st, err = m.Status()
+	if err != nil || !st.Enabled || st.Encrypted != 2 || st.Plaintext != 0 {
+		t.Errorf("Expected enabled with 2 encrypted files, got %+v, %v", st, err)
+	}
+}
+
+// TestRunSecure tests the command output and usage errors.
+func TestRunSecure(t *testing.T) {
+	m := &Manager{Store: &FileStore{Path: filepath.Join(t.TempDir(), "data.key")}}
+
+	var out bytes.Buffer
+	if err := RunSecure(m, []string{"rotate"}, &out); err == nil || !strings.Contains(err.Error(), "secure enable") {
+		t.Errorf("Expected rotate to require enable, got %v", err)
+	}
+	if err := RunSecure(m, []string{"enable"}, &out); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	out.Reset()
+	if err := RunSecure(m, []string{"status"}, &out); err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Encryption: enabled") {
+		t.Errorf("Expected enabled status, got %q", out.String())
+	}
+	if err := RunSecure(m, nil, &out); err == nil {
+		t.Error("Expected usage error")
+	}
+}
+
+// TestSealWithSecret tests passphrase round trips and wrong secrets.
+func TestSealWithSecret(t *testing.T) {
+	defer func(n int) { secretIterations = n }(secretIterations)
+	secretIterations = 1000
+
+	sealed, err := SealWithSecret([]byte("correct horse"), []byte(`{"access_token":"secret"}`))
+	if err != nil {
+		t.Fatalf("SealWithSecret failed: %v", err)
+	}
+	if !IsSecretSealed(sealed) || IsSealed(sealed) || bytes.Contains(sealed, []byte("secret")) {
+		t.Error("Expected sealed data to hide the plaintext behind its own header")
+	}
+
+	// Data keeps the iteration count it was written with
+	secretIterations = 2000
+	plain, err := OpenWithSecret([]byte("correct horse"), sealed)
+	if err != nil || string(plain) != `{"access_token":"secret"}` {
+		t.Errorf("Expected round trip, got %q, %v", plain, err)
+	}
+	if _, err := OpenWithSecret([]byte("wrong"), sealed); !errors.Is(err, ErrWrongSecret) {
+		t.Errorf("Expected ErrWrongSecret, got %v", err)
+	}
+	if _, err := OpenWithSecret([]byte("correct horse"), sealed[:len(secretMagic)+4]); err == nil {
+		t.Error("Expected truncated data to fail")
+	}
+}
+
+// TestManagerSkipsSecretSealed tests that files encrypted with a passphrase
+// count as encrypted and are not wrapped in the data key.
+func TestManagerSkipsSecretSealed(t *testing.T) {
+	defer func(n int) { secretIterations = n }(secretIterations)
+	secretIterations = 1000
+
+	dir := t.TempDir()
+	tokens := filepath.Join(dir, "tokens.json")
+	sealed, err := SealWithSecret([]byte("pw"), []byte(`{"access_token":"a"}`))
+	if err != nil {
+		t.Fatalf("SealWithSecret failed: %v", err)
+	}
+	os.WriteFile(tokens, sealed, 0600)
+
+	m := &Manager{Store: &FileStore{Path: filepath.Join(dir, "data.key")}, Targets: Targets{Files: []string{tokens}}}
+	if _, n, err := m.Enable(); err != nil || n != 0 {
+		t.Fatalf("Expected nothing re-encrypted, got %d, %v", n, err)
+	}
+	data, _ := os.ReadFile(tokens)
+	if !bytes.Equal(data, sealed) {
+		t.Error("Expected the passphrase-encrypted file untouched")
+	}
+	if st, err := m.Status(); err != nil || st.Encrypted != 1 {
+		t.Errorf("Expected 1 encrypted file, got %+v, %v", st, err)
+	}
+}
+
+// TestSecretSealer tests that a secret sealer derives its key once, reads
+// and writes the SealWithSecret format, and leaves plaintext readable.
+func TestSecretSealer(t *testing.T) {
+	defer func(n int) { secretIterations = n }(secretIterations)
+	secretIterations = 1000
+
+	calls := 0
+	s := NewSecretSealer(func() ([]byte, error) {
+		calls++
+		return []byte("machine"), nil
+	}, "cache")
+	for i := 0; i < 3; i++ {
+		sealed, err := s.Seal([]byte("roster"))
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		if !IsSecretSealed(sealed) {
+			t.Fatal("Expected the SealWithSecret format")
+		}
+		if plain, err := s.Open(sealed); err != nil || string(plain) != "roster" {
+			t.Errorf("Expected round trip, got %q, %v", plain, err)
+		}
+		if plain, err := OpenWithSecret([]byte("machine"), sealed); err != nil || string(plain) != "roster" {
+			t.Errorf("Expected OpenWithSecret to read it, got %q, %v", plain, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected the secret to be read once, got %d", calls)
+	}
+	if s.KeyID() != "" {
+		t.Errorf("Expected no key ID, got %q", s.KeyID())
+	}
+
+	if plain, err := s.Open([]byte("plain")); err != nil || string(plain) != "plain" {
+		t.Errorf("Expected plaintext to pass through, got %q, %v", plain, err)
+	}
+	key, _ := NewKey()
+	ringSealed, _ := NewSealer(&Ring{Current: key}).Seal([]byte("x"))
+	if _, err := s.Open(ringSealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey for data sealed with the key ring, got %v", err)
+	}
+
+	failing := NewSecretSealer(func() ([]byte, error) { return nil, errors.New("no machine ID") }, "cache")
+	if _, err := failing.Seal([]byte("x")); err == nil {
+		t.Error("Expected the secret's error")
+	}
+}
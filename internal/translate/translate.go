@@ -0,0 +1,193 @@
+// Package translate translates announcement and coursework text into the
+// user's language on demand. The backend is pluggable: a user-provided
+// command or the Google Cloud Translation API with the user's API key.
+// Results are cached so each text is translated once.
+package translate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/user/google-classroom/internal/cache"
+)
+
+// DefaultEndpoint is the Google Cloud Translation v2 endpoint.
+const DefaultEndpoint = "https://translation.googleapis.com/language/translate/v2"
+
+// cacheTTL is how long a translation is kept. The source text is part of
+// the key, so an edited announcement is translated again.
+const cacheTTL = 30 * 24 * time.Hour
+
+// Backend translates text into the target language, given as a BCP-47
+// code such as "es" or "pt-BR".
+type Backend interface {
+	Translate(ctx context.Context, text, target string) (string, error)
+}
+
+// Command runs a user-provided program with the text on stdin and reads
+// the translation from stdout. "{lang}" in Args is replaced with the target
+// language, which is also set in the TRANSLATE_TARGET environment variable.
+type Command struct {
+	Path string
+	Args []string
+}
+
+// ParseCommand splits a command line such as "trans -b :{lang}" on spaces.
+func ParseCommand(line string) (*Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, errors.New("translation command is empty")
+	}
+	return &Command{Path: fields[0], Args: fields[1:]}, nil
+}
+
+// Translate runs the command.
+func (c *Command) Translate(ctx context.Context, text, target string) (string, error) {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = strings.ReplaceAll(a, "{lang}", target)
+	}
+
+	cmd := exec.CommandContext(ctx, c.Path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Env = append(os.Environ(), "TRANSLATE_TARGET="+target)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("translation command failed: %v: %s", err, msg)
+		}
+		return "", fmt.Errorf("translation command failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GoogleAPI calls the Cloud Translation API with an API key.
+type GoogleAPI struct {
+	Key string
+	// Endpoint defaults to DefaultEndpoint.
+	Endpoint string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Translate calls the API.
+func (g *GoogleAPI) Translate(ctx context.Context, text, target string) (string, error) {
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	form := url.Values{"q": {text}, "target": {target}, "format": {"text"}, "key": {g.Key}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create translation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to translate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse translation: %w", err)
+	}
+	if body.Error != nil {
+		return "", fmt.Errorf("failed to translate: %s", body.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK || len(body.Data.Translations) == 0 {
+		return "", fmt.Errorf("failed to translate: %s", resp.Status)
+	}
+	return body.Data.Translations[0].TranslatedText, nil
+}
+
+// Configuration holds translation settings.
+type Configuration struct {
+	// Language is the target language, e.g. "es".
+	Language string
+	// Backend does the translating.
+	Backend Backend
+	// Cache keeps translations. Nil translates every time.
+	Cache *cache.Cache
+}
+
+// Translator translates text into the configured language, caching the
+// results.
+type Translator struct {
+	cfg Configuration
+}
+
+// New creates a translator.
+func New(cfg *Configuration) (*Translator, error) {
+	if cfg == nil || cfg.Backend == nil {
+		return nil, errors.New("no translation backend configured")
+	}
+	if cfg.Language == "" {
+		return nil, errors.New("no target language configured")
+	}
+	return &Translator{cfg: *cfg}, nil
+}
+
+// Language returns the target language.
+func (t *Translator) Language() string {
+	return t.cfg.Language
+}
+
+// Translate translates text, using the cached translation when there is
+// one.
+func (t *Translator) Translate(ctx context.Context, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+
+	key := cacheKey(text, t.cfg.Language)
+	if t.cfg.Cache != nil {
+		if cached, ok := cache.GetValue[string](t.cfg.Cache, key); ok {
+			return cached, nil
+		}
+	}
+
+	translated, err := t.cfg.Backend.Translate(ctx, text, t.cfg.Language)
+	if err != nil {
+		return "", err
+	}
+	if t.cfg.Cache != nil {
+		t.cfg.Cache.Set(key, translated, cacheTTL)
+	}
+	return translated, nil
+}
+
+// cacheKey hashes the text so keys stay short and the cache file names do
+// not reveal the content.
+func cacheKey(text, target string) string {
+	sum := sha256.Sum256([]byte(target + "\x00" + text))
+	return "translate." + target + "." + hex.EncodeToString(sum[:16])
+}
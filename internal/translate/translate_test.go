@@ -0,0 +1,103 @@
+package translate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/user/google-classroom/internal/cache"
+)
+
+// countingBackend tags text with the target language and counts calls.
+type countingBackend struct {
+	calls int
+}
+
+func (b *countingBackend) Translate(ctx context.Context, text, target string) (string, error) {
+	b.calls++
+	return "[" + target + "] " + text, nil
+}
+
+// TestTranslatorCaches tests that each text is translated once.
+func TestTranslatorCaches(t *testing.T) {
+	c, err := cache.NewCache(&cache.Configuration{Enabled: true, Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	backend := &countingBackend{}
+	tr, err := New(&Configuration{Language: "es", Backend: backend, Cache: c})
+	if err != nil {
+		t.Fatalf("Failed to create translator: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := tr.Translate(context.Background(), "Homework is due Friday")
+		if err != nil {
+			t.Fatalf("Translate failed: %v", err)
+		}
+		if got != "[es] Homework is due Friday" {
+			t.Errorf("Expected tagged translation, got %q", got)
+		}
+	}
+	if backend.calls != 1 {
+		t.Errorf("Expected 1 backend call, got %d", backend.calls)
+	}
+
+	if _, err := New(&Configuration{Backend: backend}); err == nil {
+		t.Error("Expected error without a target language")
+	}
+}
+
+// TestCommand tests running a translation command.
+func TestCommand(t *testing.T) {
+	cmd, err := ParseCommand("sh -c true")
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	if cmd.Path != "sh" || len(cmd.Args) != 2 {
+		t.Fatalf("Expected sh with 2 args, got %s %v", cmd.Path, cmd.Args)
+	}
+
+	cmd.Args = []string{"-c", `printf '%s:%s' "$TRANSLATE_TARGET" "$(cat)"`}
+
+	got, err := cmd.Translate(context.Background(), "hello", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got != "fr:hello" {
+		t.Errorf("Expected fr:hello, got %q", got)
+	}
+
+	cmd.Args = []string{"-c", "echo {lang}"}
+	if got, _ := cmd.Translate(context.Background(), "", "de"); got != "de" {
+		t.Errorf("Expected {lang} to be replaced, got %q", got)
+	}
+}
+
+// TestGoogleAPI tests calling the Cloud Translation API.
+func TestGoogleAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("key") != "secret" || r.FormValue("target") != "ja" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"bad request"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"translations":[{"translatedText":"こんにちは"}]}}`))
+	}))
+	defer server.Close()
+
+	g := &GoogleAPI{Key: "secret", Endpoint: server.URL}
+	got, err := g.Translate(context.Background(), "hello", "ja")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got != "こんにちは" {
+		t.Errorf("Expected こんにちは, got %q", got)
+	}
+
+	g.Key = "wrong"
+	if _, err := g.Translate(context.Background(), "hello", "ja"); err == nil {
+		t.Error("Expected error for a rejected request")
+	}
+}
@@ -1 +1,152 @@
+// Package components provides reusable Bubble Tea UI components.
 package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Form is a simple vertical form of labelled text inputs.
+type Form struct {
+	Title     string
+	labels    []string
+	inputs    []textinput.Model
+	focus     int
+	submitted bool
+	cancelled bool
+}
+
+// NewForm creates a form with one text input per label.
+func NewForm(title string, labels ...string) *Form {
+	inputs := make([]textinput.Model, len(labels))
+	for i := range labels {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Width = 40
+		inputs[i] = ti
+	}
+	if len(inputs) > 0 {
+		inputs[0].Focus()
+	}
+
+	return &Form{
+		Title:  title,
+		labels: labels,
+		inputs: inputs,
+	}
+}
+
+// SetValue pre-fills the field at index i.
+func (f *Form) SetValue(i int, value string) {
+	if i >= 0 && i < len(f.inputs) {
+		f.inputs[i].SetValue(value)
+	}
+}
+
+// SetSecret masks the field at index i, for passwords and client secrets.
+func (f *Form) SetSecret(i int) {
+	if i >= 0 && i < len(f.inputs) {
+		f.inputs[i].EchoMode = textinput.EchoPassword
+		f.inputs[i].EchoCharacter = '•'
+	}
+}
+
+// Reopen lets a submitted or cancelled form be edited again, e.g. after
+// its values were rejected.
+func (f *Form) Reopen() {
+	f.submitted = false
+	f.cancelled = false
+}
+
+// Value returns the trimmed value of the field at index i.
+func (f *Form) Value(i int) string {
+	if i < 0 || i >= len(f.inputs) {
+		return ""
+	}
+	return strings.TrimSpace(f.inputs[i].Value())
+}
+
+// Submitted reports whether the user submitted the form.
+func (f *Form) Submitted() bool {
+	return f.submitted
+}
+
+// Cancelled reports whether the user cancelled the form.
+func (f *Form) Cancelled() bool {
+	return f.cancelled
+}
+
+// Update handles key presses: tab/shift+tab move between fields, enter
+// advances or submits on the last field, and esc cancels.
+func (f *Form) Update(msg tea.Msg) tea.Cmd {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			f.cancelled = true
+			return nil
+		case "tab", "down":
+			f.setFocus(f.focus + 1)
+			return nil
+		case "shift+tab", "up":
+			f.setFocus(f.focus - 1)
+			return nil
+		case "enter":
+			if f.focus == len(f.inputs)-1 {
+				f.submitted = true
+				return nil
+			}
+			f.setFocus(f.focus + 1)
+			return nil
+		}
+	}
+
+	if len(f.inputs) == 0 {
+		return nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return cmd
+}
+
+// View renders the form.
+func (f *Form) View() string {
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Width(12)
+	activeLabelStyle := labelStyle.Foreground(lipgloss.Color("#ff79c6")).Bold(true)
+
+	lines := []string{
+		lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff79c6")).
+			Bold(true).
+			Render(f.Title),
+		"",
+	}
+	for i, label := range f.labels {
+		style := labelStyle
+		if i == f.focus {
+			style = activeLabelStyle
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, style.Render(label), f.inputs[i].View()))
+	}
+	lines = append(lines, "", lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6272a4")).
+		Render("tab next field | enter submit | esc cancel"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6272a4")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// setFocus moves focus to the field at index i, wrapping around.
+func (f *Form) setFocus(i int) {
+	if len(f.inputs) == 0 {
+		return
+	}
+	f.inputs[f.focus].Blur()
+	f.focus = (i + len(f.inputs)) % len(f.inputs)
+	f.inputs[f.focus].Focus()
+}
@@ -0,0 +1,540 @@
+package tea
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// agendaLayout is how the agenda lays out days.
+type agendaLayout int
+
+const (
+	agendaWeek agendaLayout = iota
+	agendaMonth
+)
+
+// agendaConcurrency bounds how many courses' coursework loads at once.
+const agendaConcurrency = 4
+
+// agendaItem is coursework placed on the day it is due.
+type agendaItem struct {
+	course     *api.Course
+	courseWork *api.CourseWork
+	// due is in local time; allDay items have a date only.
+	due    time.Time
+	allDay bool
+}
+
+// AgendaModel plots coursework due dates across all active courses on a
+// week or month calendar. The arrow keys move between days, and enter
+// opens the highlighted assignment.
+type AgendaModel struct {
+	apiClient api.ClassroomClient
+	courses   []*api.Course
+	// byDay holds the items due each day, keyed by dayKey and sorted by
+	// due time.
+	byDay   map[string][]agendaItem
+	layout  agendaLayout
+	day     time.Time // selected day, local midnight
+	item    int       // selected item within the day
+	refresh bool      // next load skips the cache
+	spinner spinner.Model
+	loading bool
+	err     error
+	// failed holds the courses whose coursework did not load; the rest
+	// are shown without them.
+	failed []agendaFailure
+	help   helpOverlay
+	width  int
+	height int
+}
+
+// OpenAgendaMsg is sent to open the agenda for the given courses.
+type OpenAgendaMsg struct {
+	Courses []*api.Course
+}
+
+// agendaFailure is a course whose coursework failed to load.
+type agendaFailure struct {
+	course *api.Course
+	err    error
+}
+
+// agendaLoadedMsg carries the coursework of every course that loaded.
+// err is set only when none did.
+type agendaLoadedMsg struct {
+	items  []agendaItem
+	failed []agendaFailure
+	err    error
+}
+
+// NewAgendaModel creates an agenda of the active courses among courses,
+// opened on the current week.
+func NewAgendaModel(apiClient api.ClassroomClient, courses []*api.Course) *AgendaModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6"))
+
+	var active []*api.Course
+	for _, c := range courses {
+		if c.CourseState == api.CourseStateActive {
+			active = append(active, c)
+		}
+	}
+	return &AgendaModel{
+		apiClient: cache.NewCachedClient(apiClient, options.Cache),
+		courses:   active,
+		day:       startOfDay(time.Now()),
+		spinner:   s,
+		loading:   true,
+	}
+}
+
+// Init initializes the model.
+func (m *AgendaModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.load(), watchConnectivity())
+}
+
+// load fetches the visible coursework of every course, as the coursework
+// screen does, so a warm cache answers it. A course that fails is
+// reported without holding back the others.
+func (m *AgendaModel) load() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
+	client, courses := m.apiClient, m.courses
+	return func() tea.Msg {
+		ctx, cancel := loadContext(refresh)
+		defer cancel()
+
+		// A failure is kept in its result rather than returned, so it
+		// does not cancel the other courses
+		type result struct {
+			coursework []*api.CourseWork
+			err        error
+		}
+		results, err := parallel.Map(ctx, agendaConcurrency, courses, func(ctx context.Context, course *api.Course) (result, error) {
+			_, coursework, err := loadVisibleCourseWork(ctx, client, course.ID, api.CourseWorkOrderDueDateAsc)
+			return result{coursework: coursework, err: err}, nil
+		})
+		if err != nil {
+			return agendaLoadedMsg{err: err}
+		}
+
+		var msg agendaLoadedMsg
+		for i, r := range results {
+			if r.err != nil {
+				msg.failed = append(msg.failed, agendaFailure{course: courses[i], err: r.err})
+				continue
+			}
+			for _, cw := range r.coursework {
+				due, allDay, ok := cw.Due()
+				if !ok {
+					continue
+				}
+				if !allDay {
+					due = due.Local()
+				}
+				msg.items = append(msg.items, agendaItem{course: courses[i], courseWork: cw, due: due, allDay: allDay})
+			}
+		}
+		if len(msg.failed) == len(courses) && len(courses) > 0 {
+			msg.err = msg.failed[0].err
+		}
+		return msg
+	}
+}
+
+// Update handles messages.
+func (m *AgendaModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Left)):
+			m.moveDay(-1)
+		case key.Matches(msg, bound(keymap.Right)):
+			m.moveDay(1)
+		case key.Matches(msg, bound(keymap.Up)):
+			if m.layout == agendaMonth {
+				m.moveDay(-7)
+			} else if m.item > 0 {
+				m.item--
+			}
+		case key.Matches(msg, bound(keymap.Down)):
+			if m.layout == agendaMonth {
+				m.moveDay(7)
+			} else if m.item < len(m.selectedDay())-1 {
+				m.item++
+			}
+		case key.Matches(msg, bound(keymap.Select)):
+			if it, ok := m.selectedItem(); ok {
+				return m, func() tea.Msg {
+					return CourseWorkDetailMsg{Course: it.course, CourseWork: it.courseWork}
+				}
+			}
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
+			m.loading = true
+			m.err = nil
+			return m, m.load()
+		case key.Matches(msg, bound(keymap.NextItem)):
+			if n := len(m.selectedDay()); n > 0 {
+				m.item = (m.item + 1) % n
+			}
+		case key.Matches(msg, bound(keymap.PreviousPeriod)):
+			m.movePeriod(-1)
+		case key.Matches(msg, bound(keymap.NextPeriod)):
+			m.movePeriod(1)
+		case key.Matches(msg, bound(keymap.Today)):
+			m.day, m.item = startOfDay(time.Now()), 0
+		case key.Matches(msg, bound(keymap.Layout)):
+			m.layout = (m.layout + 1) % 2
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if err := m.failure(); err != nil {
+					return m, recoverFromError(err, msg.String())
+				}
+			}
+		}
+
+	case agendaLoadedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err != nil {
+			reportError(msg.err)
+			return m, nil
+		}
+		m.failed = msg.failed
+		for _, f := range msg.failed {
+			reportError(f.err)
+		}
+		m.byDay = groupByDay(msg.items)
+		m.item = min(m.item, max(len(m.selectedDay())-1, 0))
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.load(), afterRecovery(msg))
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// moveDay selects the day n days away.
+func (m *AgendaModel) moveDay(n int) {
+	m.day, m.item = m.day.AddDate(0, 0, n), 0
+}
+
+// movePeriod selects the same weekday n weeks away in the week layout, or
+// the same day n months away in the month layout.
+func (m *AgendaModel) movePeriod(n int) {
+	if m.layout == agendaWeek {
+		m.moveDay(7 * n)
+		return
+	}
+	// Clamp to the last day of the target month, e.g. Jan 31 to Feb 28
+	first := time.Date(m.day.Year(), m.day.Month()+time.Month(n), 1, 0, 0, 0, 0, time.Local)
+	last := first.AddDate(0, 1, -1).Day()
+	m.day = time.Date(first.Year(), first.Month(), min(m.day.Day(), last), 0, 0, 0, 0, time.Local)
+	m.item = 0
+}
+
+// failure returns the error the agenda failed with, or that of the first
+// course that did not load, or nil.
+func (m *AgendaModel) failure() error {
+	if m.err == nil && len(m.failed) > 0 {
+		return m.failed[0].err
+	}
+	return m.err
+}
+
+// selectedDay returns the items due on the selected day.
+func (m *AgendaModel) selectedDay() []agendaItem {
+	return m.byDay[dayKey(m.day)]
+}
+
+// selectedItem returns the highlighted item, if the selected day has any.
+func (m *AgendaModel) selectedItem() (agendaItem, bool) {
+	items := m.selectedDay()
+	if m.item >= len(items) {
+		return agendaItem{}, false
+	}
+	return items[m.item], true
+}
+
+// View renders the model.
+func (m *AgendaModel) View() string {
+	if m.loading {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Align(lipgloss.Center).
+			Render(
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					m.spinner.View(),
+					lipgloss.NewStyle().
+						Foreground(lipgloss.Color("#f8f8f2")).
+						Render("Loading due dates..."),
+				),
+			)
+	}
+
+	if m.err != nil {
+		return renderErrorView("Error loading agenda", m.err, m.width, m.height)
+	}
+
+	title := m.day.Format("January 2006")
+	if m.layout == agendaWeek {
+		start := startOfWeek(m.day)
+		title = "Week of " + start.Format("Mon Jan 2, 2006")
+	}
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render("Agenda - " + title)
+
+	grid := m.renderWeek()
+	if m.layout == agendaMonth {
+		grid = m.renderMonth()
+	}
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, m.renderFailures()...)
+	sections = append(sections, grid, "", m.renderDay(), "")
+	keys := []key.Binding{
+		actionPair(keymap.Left, keymap.Right, "day"),
+		actionPair(keymap.Up, keymap.Down, "assignment"),
+		actionKey(keymap.NextItem, "next"),
+		actionPair(keymap.PreviousPeriod, keymap.NextPeriod, "week"),
+		actionKey(keymap.Layout, "month"),
+	}
+	if m.layout == agendaMonth {
+		keys = []key.Binding{
+			actionPair(keymap.Left, keymap.Right, "day"),
+			actionPair(keymap.Up, keymap.Down, "week"),
+			actionKey(keymap.NextItem, "assignment"),
+			actionPair(keymap.PreviousPeriod, keymap.NextPeriod, "month"),
+			actionKey(keymap.Layout, "week"),
+		}
+	}
+	refresh := refreshKey()
+	if len(m.failed) > 0 {
+		refresh = actionKey(keymap.Refresh, "retry")
+	}
+	keys = append(keys, actionKey(keymap.Today, "today"), actionKey(keymap.Select, "open"), refresh, backKey())
+	if bar := statusBar(nil, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, keys...))
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// renderFailures renders a line for each course whose coursework did not
+// load, followed by a blank line when there are any.
+func (m *AgendaModel) renderFailures() []string {
+	if len(m.failed) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, f := range m.failed {
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(fmt.Sprintf("Couldn't load %s: %s (%s)", f.course.Name, errorText(f.err), recoveryHint(f.err))))
+	}
+	return append(lines, "")
+}
+
+// columnWidth returns the width of one day's column.
+func (m *AgendaModel) columnWidth() int {
+	return max((m.width-4)/7-1, 10)
+}
+
+// renderWeek renders the selected week as seven columns listing what is
+// due each day.
+func (m *AgendaModel) renderWeek() string {
+	width := m.columnWidth()
+	start := startOfWeek(m.day)
+	today := startOfDay(time.Now())
+
+	columns := make([]string, 7)
+	for i := range columns {
+		day := start.AddDate(0, 0, i)
+		lines := []string{dayHeaderStyle(day, m.day, today).Render(day.Format("Mon 2"))}
+		for j, it := range m.byDay[dayKey(day)] {
+			style := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+			if day.Equal(m.day) && j == m.item {
+				style = style.Foreground(lipgloss.Color("#50fa7b")).Bold(true)
+			}
+			lines = append(lines, style.MaxWidth(width).Render("• "+it.courseWork.Title))
+		}
+		columns[i] = lipgloss.NewStyle().Width(width).MarginRight(1).Render(strings.Join(lines, "\n"))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// renderMonth renders the selected month as a grid of weeks, each day
+// showing how much is due.
+func (m *AgendaModel) renderMonth() string {
+	width := m.columnWidth()
+	first := time.Date(m.day.Year(), m.day.Month(), 1, 0, 0, 0, 0, time.Local)
+	today := startOfDay(time.Now())
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+
+	var names []string
+	for i := range 7 {
+		names = append(names, muted.Width(width).MarginRight(1).Render(startOfWeek(first).AddDate(0, 0, i).Format("Mon")))
+	}
+	rows := []string{lipgloss.JoinHorizontal(lipgloss.Top, names...)}
+
+	for week := startOfWeek(first); week.Before(first.AddDate(0, 1, 0)); week = week.AddDate(0, 0, 7) {
+		cells := make([]string, 7)
+		for i := range cells {
+			day := week.AddDate(0, 0, i)
+			label := fmt.Sprintf("%2d", day.Day())
+			if n := len(m.byDay[dayKey(day)]); n > 0 {
+				label += fmt.Sprintf(" • %d due", n)
+			}
+			style := dayHeaderStyle(day, m.day, today)
+			if day.Month() != m.day.Month() {
+				style = muted
+			}
+			cells[i] = style.Width(width).MarginRight(1).Render(label)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderDay lists the selected day's assignments with their course, due
+// time, and checklist progress.
+func (m *AgendaModel) renderDay() string {
+	heading := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#bd93f9")).
+		Bold(true).
+		Render(m.day.Format("Monday, January 2"))
+	items := m.selectedDay()
+	if len(items) == 0 {
+		return heading + "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272a4")).
+			Render("  Nothing due.")
+	}
+
+	lines := []string{heading}
+	for i, it := range items {
+		when := "all day"
+		if !it.allDay {
+			when = it.due.Format("15:04")
+		}
+		line := fmt.Sprintf("%-7s %s - %s", when, it.courseWork.Title, it.course.Name)
+		if badge := checklistBadge(it.course.ID, it.courseWork.ID); badge != "" {
+			line += "  " + badge
+		}
+		if i == m.item {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true).Render("> "+line))
+		} else {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2")).Render("  "+line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dayHeaderStyle styles a day's label: the selected day is highlighted
+// and today is underlined.
+func dayHeaderStyle(day, selected, today time.Time) lipgloss.Style {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd"))
+	if day.Equal(selected) {
+		style = style.Foreground(lipgloss.Color("#282a36")).Background(lipgloss.Color("#50fa7b")).Bold(true)
+	}
+	if day.Equal(today) {
+		style = style.Underline(true)
+	}
+	return style
+}
+
+// groupByDay files items under the day they are due, sorted by due time
+// with all-day items first.
+func groupByDay(items []agendaItem) map[string][]agendaItem {
+	byDay := make(map[string][]agendaItem)
+	for _, it := range items {
+		k := dayKey(it.due)
+		byDay[k] = append(byDay[k], it)
+	}
+	for _, day := range byDay {
+		slices.SortStableFunc(day, func(a, b agendaItem) int {
+			if a.allDay != b.allDay {
+				if a.allDay {
+					return -1
+				}
+				return 1
+			}
+			if c := a.due.Compare(b.due); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.courseWork.Title, b.courseWork.Title)
+		})
+	}
+	return byDay
+}
+
+// dayKey identifies the calendar day of t, e.g. "2026-03-02".
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// startOfDay returns local midnight on t's day.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek returns the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
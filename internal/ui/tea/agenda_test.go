@@ -0,0 +1,200 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+)
+
+// agendaClient is a fake classroom whose coursework fails to list for the
+// courses in fail.
+type agendaClient struct {
+	*fake.Client
+	fail map[string]error
+}
+
+func (c *agendaClient) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	if err := c.fail[courseID]; err != nil {
+		return nil, err
+	}
+	return c.Client.ListCourseWork(ctx, courseID, opts)
+}
+
+// newAgendaClient returns a classroom with courses c1 and c2, each with
+// one assignment due on 2 March 2026.
+func newAgendaClient() (*agendaClient, []*api.Course) {
+	c := &agendaClient{Client: fake.New("t1"), fail: make(map[string]error)}
+	var courses []*api.Course
+	for _, course := range []*api.Course{
+		{ID: "c1", Name: "Biology", CourseState: api.CourseStateActive},
+		{ID: "c2", Name: "Chemistry", CourseState: api.CourseStateActive},
+	} {
+		c.AddCourse(course)
+		c.AddTeacher(&api.Teacher{CourseID: course.ID, UserID: "t1"})
+		c.AddCourseWork(&api.CourseWork{CourseID: course.ID, Title: course.Name + " lab", State: api.CourseWorkStatePublished, DueDate: "2026-03-02"})
+		courses = append(courses, course)
+	}
+	return c, courses
+}
+
+// inZone runs the rest of the test with loc as the local time zone.
+func inZone(t *testing.T, loc *time.Location) {
+	t.Helper()
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+// TestAgendaLoadKeepsLoadedCourses tests that a course that fails to load
+// is reported while the others are shown.
+func TestAgendaLoadKeepsLoadedCourses(t *testing.T) {
+	client, courses := newAgendaClient()
+	client.fail["c2"] = errors.New("backend error")
+	m := NewAgendaModel(client, courses)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.day = time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	deliverTo(m, m.load())
+
+	if m.err != nil {
+		t.Fatalf("Expected no error, got %v", m.err)
+	}
+	if got := len(m.selectedDay()); got != 1 {
+		t.Fatalf("Expected the loaded course's assignment, got %d items", got)
+	}
+	if len(m.failed) != 1 || m.failed[0].course.ID != "c2" {
+		t.Fatalf("Expected c2 to have failed, got %v", m.failed)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Couldn't load Chemistry") {
+		t.Errorf("Expected the failed course in the view, got %q", view)
+	}
+	if !strings.Contains(view, "Biology lab") {
+		t.Errorf("Expected the loaded assignment in the view, got %q", view)
+	}
+
+	delete(client.fail, "c2")
+	press(m, "r")
+	if len(m.failed) != 0 || len(m.selectedDay()) != 2 {
+		t.Errorf("Expected a retry to load both courses, got %d failed and %d items", len(m.failed), len(m.selectedDay()))
+	}
+}
+
+// TestAgendaLoadAllFailed tests that the agenda shows an error when no
+// course loads.
+func TestAgendaLoadAllFailed(t *testing.T) {
+	client, courses := newAgendaClient()
+	client.fail["c1"] = errors.New("backend error")
+	client.fail["c2"] = errors.New("backend error")
+	m := NewAgendaModel(client, courses)
+	deliverTo(m, m.load())
+
+	if m.err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+// TestGroupByDay tests that items are filed under their day, all-day
+// items first and the rest by due time.
+func TestGroupByDay(t *testing.T) {
+	inZone(t, time.UTC)
+	item := func(title, due string, allDay bool) agendaItem {
+		d, _ := time.Parse("2006-01-02 15:04", due)
+		return agendaItem{courseWork: &api.CourseWork{Title: title}, due: d, allDay: allDay}
+	}
+	byDay := groupByDay([]agendaItem{
+		item("Essay", "2026-03-02 17:00", false),
+		item("Quiz", "2026-03-02 09:00", false),
+		item("Reading", "2026-03-02 00:00", true),
+		item("Lab", "2026-03-02 09:00", false),
+		item("Project", "2026-03-03 08:00", false),
+	})
+
+	if len(byDay) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(byDay))
+	}
+	var titles []string
+	for _, it := range byDay["2026-03-02"] {
+		titles = append(titles, it.courseWork.Title)
+	}
+	if got, want := strings.Join(titles, ","), "Reading,Lab,Quiz,Essay"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if got := len(byDay["2026-03-03"]); got != 1 {
+		t.Errorf("Expected 1 item on 3 March, got %d", got)
+	}
+}
+
+// TestAgendaPlacement tests that timed coursework lands on its local day
+// while all-day coursework keeps its date.
+func TestAgendaPlacement(t *testing.T) {
+	inZone(t, time.FixedZone("UTC-5", -5*60*60))
+	client, courses := newAgendaClient()
+	// 02:00 UTC on 3 March is the evening of 2 March at UTC-5
+	client.AddCourseWork(&api.CourseWork{CourseID: "c1", Title: "Late quiz", State: api.CourseWorkStatePublished, DueDate: "2026-03-03", DueTime: "02:00"})
+	m := NewAgendaModel(client, courses)
+	deliverTo(m, m.load())
+
+	var titles []string
+	for _, it := range m.byDay["2026-03-02"] {
+		titles = append(titles, it.courseWork.Title)
+	}
+	if got, want := strings.Join(titles, ","), "Biology lab,Chemistry lab,Late quiz"; got != want {
+		t.Errorf("Expected %s on 2 March, got %s", want, got)
+	}
+	if got := len(m.byDay["2026-03-03"]); got != 0 {
+		t.Errorf("Expected nothing on 3 March, got %d", got)
+	}
+}
+
+// TestMovePeriod tests moving by weeks and by months, clamping to the end
+// of shorter months.
+func TestMovePeriod(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout agendaLayout
+		from   time.Time
+		n      int
+		want   time.Time
+	}{
+		{"next week", agendaWeek, time.Date(2026, 1, 28, 0, 0, 0, 0, time.Local), 1, time.Date(2026, 2, 4, 0, 0, 0, 0, time.Local)},
+		{"previous week", agendaWeek, time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), -1, time.Date(2026, 2, 23, 0, 0, 0, 0, time.Local)},
+		{"next month", agendaMonth, time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local), 1, time.Date(2026, 4, 15, 0, 0, 0, 0, time.Local)},
+		{"into February", agendaMonth, time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local), 1, time.Date(2026, 2, 28, 0, 0, 0, 0, time.Local)},
+		{"into a leap February", agendaMonth, time.Date(2028, 3, 31, 0, 0, 0, 0, time.Local), -1, time.Date(2028, 2, 29, 0, 0, 0, 0, time.Local)},
+		{"into a 30-day month", agendaMonth, time.Date(2026, 5, 31, 0, 0, 0, 0, time.Local), -1, time.Date(2026, 4, 30, 0, 0, 0, 0, time.Local)},
+		{"across the year", agendaMonth, time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local), 2, time.Date(2027, 2, 28, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AgendaModel{layout: tt.layout, day: tt.from, item: 2}
+			m.movePeriod(tt.n)
+			if !m.day.Equal(tt.want) {
+				t.Errorf("Expected %s, got %s", dayKey(tt.want), dayKey(m.day))
+			}
+			if m.item != 0 {
+				t.Errorf("Expected the first item to be selected, got %d", m.item)
+			}
+		})
+	}
+}
+
+// TestStartOfWeek tests that weeks start on Monday.
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	for i := range 7 {
+		day := monday.AddDate(0, 0, i).Add(15 * time.Hour)
+		if got := startOfWeek(day); !got.Equal(monday) {
+			t.Errorf("Expected %s for %s, got %s", dayKey(monday), day.Weekday(), got)
+		}
+	}
+	if got := startOfWeek(monday.AddDate(0, 0, -1)); !got.Equal(monday.AddDate(0, 0, -7)) {
+		t.Errorf("Expected Sunday to belong to the week before, got %s", got)
+	}
+}
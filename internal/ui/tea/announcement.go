@@ -1,17 +1,19 @@
 package tea
 
 import (
-	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/paginator"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
 )
 
 // AnnouncementItem represents an announcement item in the list.
@@ -25,12 +27,22 @@ func (i AnnouncementItem) Title() string {
 	if len(preview) > 50 {
 		preview = preview[:47] + "..."
 	}
+	if at, ok := i.announcement.Scheduled(); ok {
+		return scheduledBadge(at) + preview
+	}
+	if i.announcement.State == api.AnnouncementStateDraft {
+		return "[DRAFT] " + preview
+	}
 	return preview
 }
 
 // Description returns the description of the announcement item.
 func (i AnnouncementItem) Description() string {
-	return fmt.Sprintf("%s | %s", i.announcement.CreatorUserID, i.announcement.CreateTime[:10])
+	desc := fmt.Sprintf("%s | %s", i.announcement.CreatorUserID, formatTime(i.announcement.CreateTime, time.DateOnly))
+	if badge := assigneeBadge(i.announcement.AssigneeMode, i.announcement.StudentIDs); badge != "" {
+		desc += " | " + badge
+	}
+	return desc
 }
 
 // FilterValue returns the filter value for the announcement item.
@@ -41,21 +53,31 @@ func (i AnnouncementItem) FilterValue() string {
 // AnnouncementModel represents the announcement TUI model.
 type AnnouncementModel struct {
 	course        *api.Course
-	apiClient     *api.Client
+	apiClient     api.ClassroomClient
+	refresh       bool // next load skips the cache
 	announcements []*api.Announcement
+	isTeacher     bool
+	scheduledOnly bool // 'p' lists only drafts and scheduled posts
 	list          list.Model
 	spinner       spinner.Model
 	paginator     paginator.Model
 	loading       bool
 	err           error
+	help          helpOverlay
 	width         int
 	height        int
 	selectedAnn   *api.Announcement
 	fullView      bool
+	// standalone is set when the screen was opened on one announcement:
+	// leaving its full view goes back instead of to the list.
+	standalone  bool
+	translation translation
+	link        browserLink
+	recipients  recipientList
 }
 
 // NewAnnouncementModel creates a new announcement model.
-func NewAnnouncementModel(course *api.Course, apiClient *api.Client) *AnnouncementModel {
+func NewAnnouncementModel(course *api.Course, apiClient api.ClassroomClient) *AnnouncementModel {
 	// Create spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
@@ -69,6 +91,8 @@ func NewAnnouncementModel(course *api.Course, apiClient *api.Client) *Announceme
 
 	// Create list
 	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	setListKeys(&l)
+	l.Filter = foldFilter
 	l.Title = "Announcements"
 	l.Styles.Title = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#ff79c6")).
@@ -77,7 +101,7 @@ func NewAnnouncementModel(course *api.Course, apiClient *api.Client) *Announceme
 
 	return &AnnouncementModel{
 		course:    course,
-		apiClient: apiClient,
+		apiClient: cache.NewCachedClient(apiClient, options.Cache),
 		list:      l,
 		spinner:   s,
 		paginator: p,
@@ -86,6 +110,16 @@ func NewAnnouncementModel(course *api.Course, apiClient *api.Client) *Announceme
 	}
 }
 
+// NewAnnouncementViewModel opens announcement a of course in the full
+// view.
+func NewAnnouncementViewModel(course *api.Course, a *api.Announcement, apiClient api.ClassroomClient) *AnnouncementModel {
+	m := NewAnnouncementModel(course, apiClient)
+	m.selectedAnn = a
+	m.fullView = true
+	m.standalone = true
+	return m
+}
+
 // Init initializes the model.
 func (m *AnnouncementModel) Init() tea.Cmd {
 	return m.loadAnnouncements()
@@ -95,14 +129,17 @@ func (m *AnnouncementModel) Init() tea.Cmd {
 func (m *AnnouncementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc", "b":
-			if m.fullView {
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			if m.fullView && !m.standalone {
 				m.fullView = false
 				return m, nil
 			}
 			return m, func() tea.Msg { return NavigateBackMsg{} }
-		case "enter":
+		case key.Matches(msg, bound(keymap.Select)):
+			if m.standalone {
+				return m, func() tea.Msg { return NavigateBackMsg{} }
+			}
 			if m.fullView {
 				m.fullView = false
 				return m, nil
@@ -113,12 +150,43 @@ func (m *AnnouncementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.fullView = true
 				}
 			}
-		case "r":
+		case key.Matches(msg, bound(keymap.Open)):
+			if m.fullView && m.selectedAnn != nil {
+				return m, m.link.open(m.selectedAnn.AlternateLink)
+			}
+			if item, ok := m.list.SelectedItem().(AnnouncementItem); ok {
+				return m, m.link.open(item.announcement.AlternateLink)
+			}
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
 			m.loading = true
 			m.err = nil
 			return m, m.loadAnnouncements()
-		case "/":
+		case key.Matches(msg, bound(keymap.Search)):
 			// TODO: Implement search
+		case key.Matches(msg, bound(keymap.Scheduled)):
+			if !m.fullView && m.isTeacher {
+				m.scheduledOnly = !m.scheduledOnly
+				m.updateList()
+			}
+		case key.Matches(msg, bound(keymap.Recipients)):
+			if m.fullView && m.selectedAnn != nil && m.selectedAnn.IsIndividual() {
+				return m, m.recipients.toggle(m.apiClient, m.course.ID)
+			}
+		case key.Matches(msg, bound(keymap.Translate)):
+			if m.fullView && m.selectedAnn != nil {
+				return m, m.translation.toggle(m.selectedAnn.Text)
+			}
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
 		}
 
 	case spinner.TickMsg:
@@ -126,6 +194,23 @@ func (m *AnnouncementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.loadAnnouncements(), afterRecovery(msg))
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -133,16 +218,33 @@ func (m *AnnouncementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case announcementsLoadedMsg:
+		m.isTeacher = msg.isTeacher
 		m.announcements = msg.announcements
 		m.loading = false
 		m.err = nil
 		m.updateList()
 		return m, nil
 
+	case translatedMsg:
+		m.translation.update(msg)
+		return m, nil
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case recipientsLoadedMsg:
+		m.recipients.update(msg)
+		return m, nil
+
 	case announcementsLoadErrorMsg:
 		m.loading = false
 		m.err = msg.err
+		reportError(msg.err)
 		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
 	}
 
 	var cmd tea.Cmd
@@ -152,7 +254,7 @@ func (m *AnnouncementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the model.
 func (m *AnnouncementModel) View() string {
-	if m.loading {
+	if m.loading && !m.standalone {
 		return lipgloss.NewStyle().
 			Width(m.width).
 			Height(m.height).
@@ -168,23 +270,8 @@ func (m *AnnouncementModel) View() string {
 			)
 	}
 
-	if m.err != nil {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center).
-			Render(
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#ff5555")).
-						Bold(true).
-						Render("Error loading announcements"),
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#f8f8f2")).
-						Render(m.err.Error()),
-				),
-			)
+	if m.err != nil && !m.standalone {
+		return renderErrorView("Error loading announcements", m.err, m.width, m.height)
 	}
 
 	if m.fullView {
@@ -195,9 +282,25 @@ func (m *AnnouncementModel) View() string {
 	listView := m.list.View()
 
 	// Render footer
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272a4")).
-		Render("↑↓ navigate | enter view | r refresh | b back | q quit")
+	keys := []key.Binding{navigateKey(), actionKey(keymap.Select, "view"), actionKey(keymap.Open, "open")}
+	if m.isTeacher {
+		label := "scheduled"
+		if m.scheduledOnly {
+			label = "all"
+		}
+		keys = append(keys, actionKey(keymap.Scheduled, label))
+	}
+	keys = append(keys, refreshKey(), backKey(), quitKey())
+	footer := m.help.render(m.width, keys...)
+
+	sections := []string{listView, ""}
+	if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
 
 	return lipgloss.NewStyle().
 		Width(m.width).
@@ -206,9 +309,7 @@ func (m *AnnouncementModel) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Left,
-				listView,
-				"",
-				footer,
+				sections...,
 			),
 		)
 }
@@ -220,7 +321,8 @@ func (m *AnnouncementModel) renderFullView() string {
 	}
 
 	// Format the announcement text with wrapping
-	lines := wrapText(m.selectedAnn.Text, m.width-4)
+	text, status := m.translation.render(m.selectedAnn.Text)
+	lines := wrapText(text, m.width-4)
 	content := strings.Join(lines, "\n")
 
 	// Render header
@@ -230,9 +332,13 @@ func (m *AnnouncementModel) renderFullView() string {
 		Render("From: " + m.selectedAnn.CreatorUserID)
 
 	// Render date
+	when := formatTime(m.selectedAnn.CreateTime, "2006-01-02 15:04")
+	if at, ok := m.selectedAnn.Scheduled(); ok {
+		when = "Scheduled for " + at.Local().Format("Mon Jan 2 15:04")
+	}
 	date := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#6272a4")).
-		Render(m.selectedAnn.CreateTime[:19])
+		Render(when)
 
 	// Render content
 	body := lipgloss.NewStyle().
@@ -241,46 +347,78 @@ func (m *AnnouncementModel) renderFullView() string {
 		Render(content)
 
 	// Render footer
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272a4")).
-		Render("Press enter or esc to go back")
+	var keys []key.Binding
+	if m.selectedAnn.IsIndividual() {
+		keys = append(keys, actionKey(keymap.Recipients, "recipients"))
+	}
+	if options.Translator != nil {
+		keys = append(keys, actionKey(keymap.Translate, "translate"))
+	}
+	keys = append(keys, actionKey(keymap.Open, "open in browser"), backKey())
+	footer := m.help.render(m.width, keys...)
+
+	sections := []string{header, date, ""}
+	if recipients := m.recipients.render(m.selectedAnn.AssigneeMode, m.selectedAnn.StudentIDs); recipients != "" {
+		sections = append(sections, recipients, "")
+	}
+	if status != "" {
+		sections = append(sections, status, "")
+	}
+	sections = append(sections, body, "")
+	if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
 
 	return lipgloss.NewStyle().
 		Width(m.width).
 		Height(m.height).
 		Padding(1).
-		Render(
-			lipgloss.JoinVertical(
-				lipgloss.Left,
-				header,
-				date,
-				"",
-				body,
-				"",
-				footer,
-			),
-		)
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
 }
 
 // loadAnnouncements loads announcements from the API.
 func (m *AnnouncementModel) loadAnnouncements() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := loadContext(refresh)
 		defer cancel()
 
-		announcements, err := m.apiClient.ListAnnouncements(ctx, m.course.ID)
+		isTeacher, err := m.apiClient.IsTeacher(ctx, m.course.ID)
+		if err != nil {
+			return announcementsLoadErrorMsg{err: err}
+		}
+		// Teachers also see drafts, which include scheduled announcements
+		var opts *api.ListAnnouncementsOptions
+		if isTeacher {
+			opts = &api.ListAnnouncementsOptions{States: []string{api.AnnouncementStatePublished, api.AnnouncementStateDraft}}
+		}
+
+		announcements, err := m.apiClient.ListAnnouncements(ctx, m.course.ID, opts)
 		if err != nil {
 			return announcementsLoadErrorMsg{err: err}
 		}
-		return announcementsLoadedMsg{announcements: announcements}
+		return announcementsLoadedMsg{announcements: announcements, isTeacher: isTeacher}
 	}
 }
 
-// updateList updates the list with announcements.
+// updateList updates the list with announcements, or only the unpublished
+// ones while scheduledOnly is set.
 func (m *AnnouncementModel) updateList() {
-	items := make([]list.Item, len(m.announcements))
-	for i, a := range m.announcements {
-		items[i] = AnnouncementItem{announcement: a}
+	items := make([]list.Item, 0, len(m.announcements))
+	for _, a := range m.announcements {
+		if m.scheduledOnly && a.State != api.AnnouncementStateDraft {
+			continue
+		}
+		items = append(items, AnnouncementItem{announcement: a})
+	}
+	m.list.Title = "Announcements"
+	if m.scheduledOnly {
+		m.list.Title = "Scheduled announcements"
 	}
 	m.list.SetItems(items)
 }
@@ -315,6 +453,7 @@ func wrapText(text string, width int) []string {
 // announcementsLoadedMsg is sent when announcements are loaded.
 type announcementsLoadedMsg struct {
 	announcements []*api.Announcement
+	isTeacher     bool
 }
 
 // announcementsLoadErrorMsg is sent when announcements fail to load.
@@ -0,0 +1,96 @@
+package tea
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/collation"
+)
+
+// assigneeBadge returns "👥 assigned to N students" for posts that target
+// individual students, or "" for posts to the whole class. Posts that
+// include the signed-in user say "assigned to you" instead.
+func assigneeBadge(mode string, studentIDs []string) string {
+	if mode != api.AssigneeModeIndividual {
+		return ""
+	}
+	if options.User != nil && slices.Contains(studentIDs, options.User.ID) {
+		if len(studentIDs) == 1 {
+			return "👥 assigned to you"
+		}
+		return fmt.Sprintf("👥 assigned to you and %d others", len(studentIDs)-1)
+	}
+	noun := "students"
+	if len(studentIDs) == 1 {
+		noun = "student"
+	}
+	return fmt.Sprintf("👥 assigned to %d %s", len(studentIDs), noun)
+}
+
+// recipientsLoadedMsg carries the names of the people in a course.
+type recipientsLoadedMsg struct {
+	names map[string]string
+}
+
+// recipientList expands a post's individual recipients by name. 'S'
+// toggles it; the roster is loaded the first time.
+type recipientList struct {
+	shown   bool
+	loading bool
+	names   map[string]string
+}
+
+// toggle shows or hides the list, loading the course roster when needed.
+func (r *recipientList) toggle(client api.ClassroomClient, courseID string) tea.Cmd {
+	r.shown = !r.shown
+	if !r.shown || r.names != nil || r.loading {
+		return nil
+	}
+	r.loading = true
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+		return recipientsLoadedMsg{names: rosterNames(ctx, client, courseID)}
+	}
+}
+
+// update stores the loaded roster.
+func (r *recipientList) update(msg recipientsLoadedMsg) {
+	r.loading = false
+	r.names = msg.names
+}
+
+// render lists the recipients of a post assigned to individual students,
+// or returns "" while hidden or for posts to the whole class.
+func (r *recipientList) render(mode string, studentIDs []string) string {
+	badge := assigneeBadge(mode, studentIDs)
+	if badge == "" {
+		return ""
+	}
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	if !r.shown {
+		return muted.Render(badge + " (S to list)")
+	}
+	if r.loading {
+		return muted.Render(badge + ": loading names...")
+	}
+
+	names := make([]string, len(studentIDs))
+	for i, id := range studentIDs {
+		names[i] = id
+		if name := r.names[id]; name != "" {
+			names[i] = name
+		}
+	}
+	collation.Sort(names, func(s string) string { return s })
+
+	lines := []string{muted.Render(badge + ":")}
+	for _, name := range names {
+		lines = append(lines, "  "+name)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,297 @@
+package tea
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/attendance"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// attendanceDays is how many days the attendance matrix shows at once.
+const attendanceDays = 14
+
+// AttendanceModel shows a teacher who answered each daily check-in.
+type AttendanceModel struct {
+	course    *api.Course
+	apiClient api.ClassroomClient
+	refresh   bool // next load skips the cache
+	matrix    *attendance.Matrix
+	// to is the last day shown; '[' and ']' move it a week at a time.
+	to        time.Time
+	table     table.Model
+	notice    string
+	actionErr error
+	loading   bool
+	err       error
+	help      helpOverlay
+	width     int
+	height    int
+}
+
+// NewAttendanceModel creates an attendance model showing the last two
+// weeks.
+func NewAttendanceModel(course *api.Course, apiClient api.ClassroomClient) *AttendanceModel {
+	t := table.New(table.WithKeyMap(tableKeyMap()))
+	t.SetHeight(15)
+
+	return &AttendanceModel{
+		course:    course,
+		apiClient: cache.NewCachedClient(apiClient, options.Cache),
+		to:        time.Now(),
+		table:     t,
+		loading:   true,
+	}
+}
+
+// Init initializes the model.
+func (m *AttendanceModel) Init() tea.Cmd {
+	return m.loadAttendance()
+}
+
+// Update handles messages.
+func (m *AttendanceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
+			m.loading = true
+			m.err = nil
+			return m, m.loadAttendance()
+		case key.Matches(msg, bound(keymap.PreviousPeriod)):
+			m.to = m.to.AddDate(0, 0, -7)
+			m.loading = true
+			return m, m.loadAttendance()
+		case key.Matches(msg, bound(keymap.NextPeriod)):
+			if next := m.to.AddDate(0, 0, 7); !next.After(time.Now()) {
+				m.to = next
+				m.loading = true
+				return m, m.loadAttendance()
+			}
+		case key.Matches(msg, bound(keymap.PostCheckIn)):
+			m.notice = ""
+			m.actionErr = nil
+			return m, m.postCheckIn()
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
+		}
+
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.loadAttendance(), afterRecovery(msg))
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.table.SetWidth(msg.Width - 4)
+		m.table.SetHeight(msg.Height - 12)
+		return m, nil
+
+	case attendanceLoadedMsg:
+		m.matrix = msg.matrix
+		m.loading = false
+		m.err = nil
+		m.updateTable()
+		return m, nil
+
+	case attendanceLoadErrorMsg:
+		m.loading = false
+		m.err = msg.err
+		reportError(msg.err)
+		return m, nil
+
+	case checkInPostedMsg:
+		if sessionExpired(msg.err) {
+			m.err = msg.err
+			return m, nil
+		}
+		if msg.err != nil {
+			m.actionErr = msg.err
+			reportError(msg.err)
+			return m, nil
+		}
+		m.notice = fmt.Sprintf("Posted %q", msg.title)
+		m.to = time.Now()
+		m.loading = true
+		return m, m.loadAttendance()
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+// View renders the model.
+func (m *AttendanceModel) View() string {
+	if m.loading {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Align(lipgloss.Center).
+			Render(
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#bd93f9")).
+					Render("Loading attendance..."),
+			)
+	}
+
+	if m.err != nil {
+		return renderErrorView("Error loading attendance", m.err, m.width, m.height)
+	}
+
+	from := m.to.AddDate(0, 0, -(attendanceDays - 1))
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(fmt.Sprintf("Attendance | %s | %s to %s", m.course.Name, from.Format("Jan 2"), m.to.Format("Jan 2")))
+
+	body := m.table.View()
+	if len(m.matrix.Days) == 0 {
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f8f8f2")).
+			Render("No check-ins in these two weeks. Press " + bound(keymap.PostCheckIn).Help().Key + " to post today's.")
+	}
+
+	legend := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6272a4")).
+		Render("✓ present | L late | · absent")
+	footer := m.help.render(m.width, actionKey(keymap.PostCheckIn, "post today's check-in"), actionPair(keymap.PreviousPeriod, keymap.NextPeriod, "previous/next week"), refreshKey(), backKey(), quitKey())
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, body, legend, "")
+	if m.actionErr != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.actionErr)))
+	} else if m.notice != "" {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#50fa7b")).
+			Render(m.notice))
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// loadAttendance builds the matrix for the shown days.
+func (m *AttendanceModel) loadAttendance() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
+	to := m.to
+	return func() tea.Msg {
+		ctx, cancel := loadContext(refresh)
+		defer cancel()
+
+		matrix, err := attendance.Build(ctx, m.apiClient, m.course.ID, to.AddDate(0, 0, -(attendanceDays-1)), to)
+		if err != nil {
+			return attendanceLoadErrorMsg{err: err}
+		}
+		return attendanceLoadedMsg{matrix: matrix}
+	}
+}
+
+// postCheckIn posts today's check-in question.
+func (m *AttendanceModel) postCheckIn() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+
+		cw, err := attendance.Post(ctx, m.apiClient, m.course.ID, time.Now(), "")
+		if errors.Is(err, attendance.ErrAlreadyPosted) {
+			return checkInPostedMsg{err: errors.New("today's check-in is already posted")}
+		}
+		if err != nil {
+			return checkInPostedMsg{err: err}
+		}
+		return checkInPostedMsg{title: cw.Title}
+	}
+}
+
+// updateTable fills the table with one row per student and a column per
+// check-in day.
+func (m *AttendanceModel) updateTable() {
+	columns := []table.Column{{Title: "Student", Width: 24}}
+	for _, day := range m.matrix.Days {
+		columns = append(columns, table.Column{Title: day[5:], Width: 5})
+	}
+	columns = append(columns, table.Column{Title: "Attended", Width: 9})
+
+	rows := make([]table.Row, len(m.matrix.Rows))
+	for i, r := range m.matrix.Rows {
+		row := table.Row{r.Name}
+		for _, mark := range r.Marks {
+			row = append(row, mark.Symbol())
+		}
+		rows[i] = append(row, fmt.Sprintf("%d/%d", r.Attended(), len(m.matrix.Days)))
+	}
+
+	// Clear the old rows so they are never rendered against the new columns
+	m.table.SetRows(nil)
+	m.table.SetColumns(columns)
+	m.table.SetRows(rows)
+}
+
+// attendanceLoadedMsg is sent when the attendance matrix is built.
+type attendanceLoadedMsg struct {
+	matrix *attendance.Matrix
+}
+
+// attendanceLoadErrorMsg is sent when attendance fails to load.
+type attendanceLoadErrorMsg struct {
+	err error
+}
+
+// checkInPostedMsg is sent when posting a check-in finishes.
+type checkInPostedMsg struct {
+	title string
+	err   error
+}
+
+// AttendanceMsg is sent when a teacher opens a course's attendance.
+type AttendanceMsg struct {
+	Course *api.Course
+}
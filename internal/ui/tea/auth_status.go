@@ -0,0 +1,185 @@
+package tea
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/auth"
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// AuthStatusModel shows who is logged in, where the token is kept, when
+// it expires and was last refreshed, and the granted scopes.
+type AuthStatusModel struct {
+	auth *auth.Authenticator
+	info *auth.TokenInfo
+	// scopeErr is why the scopes could not be listed; the rest of the
+	// status is still shown.
+	scopeErr error
+	loading  bool
+	now      time.Time
+	help     helpOverlay
+	width    int
+	height   int
+}
+
+// OpenAuthStatusMsg is sent to open the auth status screen.
+type OpenAuthStatusMsg struct{}
+
+// authStatusLoadedMsg carries the inspected status.
+type authStatusLoadedMsg struct {
+	info *auth.TokenInfo
+	err  error
+}
+
+// authStatusTickMsg updates the expiry countdown.
+type authStatusTickMsg time.Time
+
+// NewAuthStatusModel creates the auth status screen for a.
+func NewAuthStatusModel(a *auth.Authenticator) *AuthStatusModel {
+	return &AuthStatusModel{auth: a, loading: true, now: time.Now()}
+}
+
+// Init initializes the model.
+func (m *AuthStatusModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), authStatusTick())
+}
+
+// load inspects the token, asking Google for the granted scopes.
+func (m *AuthStatusModel) load() tea.Cmd {
+	a := m.auth
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+		info, err := a.Inspect(ctx)
+		return authStatusLoadedMsg{info: info, err: err}
+	}
+}
+
+// authStatusTick ticks once a second for the countdown.
+func authStatusTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return authStatusTickMsg(t)
+	})
+}
+
+// Update handles messages.
+func (m *AuthStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.loading = true
+			return m, m.load()
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "D":
+				if options.Login == nil {
+					return m, nil
+				}
+				mode := loginKeys[msg.String()]
+				return m, tea.Exec(&loginCommand{login: options.Login, mode: mode}, func(err error) tea.Msg {
+					return recoveryDoneMsg{action: apperrors.ActionLogin, err: err}
+				})
+			}
+		}
+
+	case authStatusLoadedMsg:
+		m.loading = false
+		m.info = msg.info
+		m.scopeErr = msg.err
+		return m, nil
+
+	case authStatusTickMsg:
+		m.now = time.Time(msg)
+		return m, authStatusTick()
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.scopeErr = msg.err
+			return m, nil
+		}
+		m.loading = true
+		return m, tea.Batch(m.load(), watchReauth())
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// View renders the model.
+func (m *AuthStatusModel) View() string {
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render("Account")
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Width(14)
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+
+	sections := []string{header, ""}
+	switch {
+	case m.info == nil && m.loading:
+		sections = append(sections, muted.Render("Loading..."))
+	case m.info == nil || !m.info.LoggedIn:
+		sections = append(sections, value.Render("Not logged in."))
+	default:
+		expiry := auth.FormatExpiry(m.info, m.now)
+		if left := m.info.ExpiresIn(m.now); left > 0 && left < 5*time.Minute {
+			expiry = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffb86c")).Render(expiry)
+		}
+		rows := [][2]string{
+			{"Account", auth.FormatAccount(m.info)},
+			{"Storage", m.info.Store},
+			{"Access token", expiry},
+			{"Last refresh", auth.FormatLastRefresh(m.info, m.now)},
+		}
+		if m.info.Profile != "" {
+			rows = append([][2]string{{"Profile", m.info.Profile}}, rows...)
+		}
+		for _, r := range rows {
+			sections = append(sections, label.Render(r[0])+value.Render(r[1]))
+		}
+
+		sections = append(sections, "", label.Render("Scopes"))
+		switch {
+		case m.loading:
+			sections = append(sections, muted.Render("  Loading..."))
+		case len(m.info.Scopes) == 0 && m.scopeErr != nil:
+			sections = append(sections, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#ff5555")).
+				Render("  Could not list scopes: "+errorText(m.scopeErr)))
+		case len(m.info.Scopes) == 0:
+			sections = append(sections, muted.Render("  none"))
+		default:
+			for _, s := range m.info.Scopes {
+				sections = append(sections, value.Render("  "+strings.TrimPrefix(s, "https://www.googleapis.com/auth/")))
+			}
+		}
+	}
+
+	sections = append(sections, "")
+	if bar := statusBar(nil, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, refreshKey(), bind("L", "log in again"), bind("D", "log in with a code"), backKey()))
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(sections, "\n"))
+}
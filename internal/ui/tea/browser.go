@@ -0,0 +1,57 @@
+package tea
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/auth"
+)
+
+// linkOpenedMsg reports whether the browser could be started.
+type linkOpenedMsg struct {
+	err error
+}
+
+// browserLink opens the selected item in the Classroom web UI with 'o' and
+// keeps the last failure to show it.
+type browserLink struct {
+	err error
+}
+
+// open opens url in the system browser. Items the API returned without a
+// link report an error instead.
+func (b *browserLink) open(url string) tea.Cmd {
+	return b.openOr(url, "this item has no Classroom link")
+}
+
+// openOr opens url like open, reporting missing when url is empty.
+func (b *browserLink) openOr(url, missing string) tea.Cmd {
+	b.err = nil
+	if url == "" {
+		b.err = errors.New(missing)
+		return nil
+	}
+	return func() tea.Msg {
+		if err := auth.OpenBrowser(url); err != nil {
+			return linkOpenedMsg{err: fmt.Errorf("failed to open browser: %w", err)}
+		}
+		return linkOpenedMsg{}
+	}
+}
+
+// update records the result of opening a link.
+func (b *browserLink) update(msg linkOpenedMsg) {
+	b.err = msg.err
+}
+
+// render returns the last failure, or "" when there is none.
+func (b *browserLink) render() string {
+	if b.err == nil {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff5555")).
+		Render(b.err.Error())
+}
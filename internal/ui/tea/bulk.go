@@ -0,0 +1,366 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/export"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/parallel"
+)
+
+// bulkConcurrency bounds how many submissions a bulk action updates at
+// once.
+const bulkConcurrency = 4
+
+// bulkSelection tracks the submissions a teacher has marked with space,
+// and the bulk action applied to them: 'B' offers returning them,
+// assigning them all one grade, or exporting them to CSV.
+type bulkSelection struct {
+	// marked holds the IDs of the marked submissions.
+	marked  map[string]bool
+	menu    bool
+	grading bool
+	input   textinput.Model
+	running bool
+	result  string
+	err     error
+}
+
+// bulkDoneMsg reports a bulk return or grade. failed holds the IDs of the
+// submissions it failed for, which stay marked.
+type bulkDoneMsg struct {
+	action string
+	done   int
+	failed []string
+	err    error // first failure
+}
+
+// bulkExportedMsg reports a CSV export.
+type bulkExportedMsg struct {
+	path  string
+	count int
+	err   error
+}
+
+// toggle marks or unmarks the submission with id.
+func (b *bulkSelection) toggle(id string) {
+	if b.marked == nil {
+		b.marked = make(map[string]bool)
+	}
+	if b.marked[id] {
+		delete(b.marked, id)
+	} else {
+		b.marked[id] = true
+	}
+}
+
+// toggleAll marks every submission, or unmarks them all when they already
+// are.
+func (b *bulkSelection) toggleAll(subs []*api.StudentSubmission) {
+	if len(b.selected(subs)) == len(subs) {
+		b.marked = nil
+		return
+	}
+	b.marked = make(map[string]bool, len(subs))
+	for _, s := range subs {
+		b.marked[s.ID] = true
+	}
+}
+
+// selected returns the marked submissions among subs, in order. Marks of
+// submissions no longer listed, such as after changing the filter, are
+// ignored.
+func (b *bulkSelection) selected(subs []*api.StudentSubmission) []*api.StudentSubmission {
+	var out []*api.StudentSubmission
+	for _, s := range subs {
+		if b.marked[s.ID] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// active reports whether the action menu or grade prompt takes keys.
+func (b *bulkSelection) active() bool {
+	return b.menu || b.grading
+}
+
+// open shows the action menu. It returns false when nothing is marked.
+func (b *bulkSelection) open(subs []*api.StudentSubmission) bool {
+	if b.running || len(b.selected(subs)) == 0 {
+		return false
+	}
+	b.menu = true
+	b.result, b.err = "", nil
+	return true
+}
+
+// handleKey handles a key in the action menu or the grade prompt. A
+// return or grade asks for confirmation first.
+func (b *bulkSelection) handleKey(msg tea.KeyMsg, client api.ClassroomClient, courseWork *api.CourseWork, subs []*api.StudentSubmission) (*confirmation, tea.Cmd) {
+	selected := b.selected(subs)
+	if b.grading {
+		switch msg.String() {
+		case "esc":
+			b.grading = false
+			return nil, nil
+		case "enter":
+			grade, err := parseGrade(b.input.Value())
+			if err != nil {
+				b.err = err
+				return nil, nil
+			}
+			b.grading = false
+			b.err = nil
+			prompt := fmt.Sprintf("Assign %s/%d to %d submission(s)?", formatPoints(grade), courseWork.MaxPoints, len(selected))
+			if overMax(grade, courseWork.MaxPoints) {
+				prompt = fmt.Sprintf("Assign %s/%d, including extra credit, to %d submission(s)?", formatPoints(grade), courseWork.MaxPoints, len(selected))
+			}
+			return requireConfirmation(confirm.Bulk, prompt,
+				func() tea.Cmd { return b.run("Graded", selected, b.gradeOne(client, grade)) })
+		}
+		var cmd tea.Cmd
+		b.input, cmd = b.input.Update(msg)
+		return nil, cmd
+	}
+
+	b.menu = false
+	switch {
+	case key.Matches(msg, bound(keymap.BulkReturn)):
+		if isOffline() {
+			b.err = errors.New("submissions can only be returned while online")
+			return nil, nil
+		}
+		return requireConfirmation(confirm.Bulk,
+			fmt.Sprintf("Return %d submission(s) to their students?", len(selected)),
+			func() tea.Cmd {
+				return b.run("Returned", selected, func(ctx context.Context, s *api.StudentSubmission) error {
+					return client.ReturnSubmission(ctx, s.CourseID, s.CourseWorkID, s.ID)
+				})
+			})
+	case key.Matches(msg, bound(keymap.BulkGrade)):
+		if isOffline() {
+			b.err = errors.New("grades can only be assigned while online")
+			return nil, nil
+		}
+		b.grading = true
+		b.input = textinput.New()
+		b.input.Prompt = fmt.Sprintf("Grade for %d: ", len(selected))
+		b.input.Placeholder = "points"
+		b.input.Width = 10
+		b.input.Focus()
+		return nil, textinput.Blink
+	case key.Matches(msg, bound(keymap.BulkExport)):
+		b.running = true
+		return nil, exportSubmissions(client, courseWork, selected)
+	}
+	return nil, nil
+}
+
+// gradeOne returns the action assigning grade to one submission.
+func (b *bulkSelection) gradeOne(client api.ClassroomClient, grade float64) func(context.Context, *api.StudentSubmission) error {
+	return func(ctx context.Context, s *api.StudentSubmission) error {
+		_, err := client.SetAssignedGrade(ctx, s.CourseID, s.CourseWorkID, s.ID, grade)
+		return err
+	}
+}
+
+// run applies fn to every submission, bulkConcurrency at a time. A failure
+// does not stop the rest; those that failed are reported by bulkDoneMsg.
+func (b *bulkSelection) run(action string, subs []*api.StudentSubmission, fn func(context.Context, *api.StudentSubmission) error) tea.Cmd {
+	b.running = true
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		// A failure is kept in its result rather than returned, so it does
+		// not cancel the rest. Submissions not reached before the timeout
+		// have no result and count as failed.
+		type result struct {
+			ran bool
+			err error
+		}
+		results, _ := parallel.Map(ctx, bulkConcurrency, subs, func(ctx context.Context, s *api.StudentSubmission) (result, error) {
+			return result{ran: true, err: fn(ctx, s)}, nil
+		})
+		msg := bulkDoneMsg{action: action}
+		for i, r := range results {
+			if !r.ran && r.err == nil {
+				r.err = ctx.Err()
+			}
+			if r.err != nil {
+				msg.failed = append(msg.failed, subs[i].ID)
+				if msg.err == nil {
+					msg.err = r.err
+				}
+				continue
+			}
+			msg.done++
+		}
+		return msg
+	}
+}
+
+// update applies a bulk result. Successful submissions are unmarked.
+func (b *bulkSelection) update(msg tea.Msg) {
+	b.running = false
+	switch msg := msg.(type) {
+	case bulkDoneMsg:
+		b.marked = make(map[string]bool, len(msg.failed))
+		for _, id := range msg.failed {
+			b.marked[id] = true
+		}
+		b.result = fmt.Sprintf("%s %d submission(s)", msg.action, msg.done)
+		b.err = nil
+		if msg.err != nil {
+			b.err = fmt.Errorf("%d failed and are still marked: %w", len(msg.failed), msg.err)
+		}
+	case bulkExportedMsg:
+		b.result, b.err = "", msg.err
+		if msg.err == nil {
+			b.result = fmt.Sprintf("Exported %d submission(s) to %s", msg.count, msg.path)
+		}
+	}
+}
+
+// exportSubmissions writes subs to a CSV file in the download directory,
+// naming students from the course roster.
+func exportSubmissions(client api.ClassroomClient, courseWork *api.CourseWork, subs []*api.StudentSubmission) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+
+		records := make([]export.SubmissionRecord, len(subs))
+		var names map[string]string
+		for i, s := range subs {
+			if names == nil {
+				names = rosterNames(ctx, client, s.CourseID)
+			}
+			records[i] = export.NewSubmissionRecord(s.CourseID, courseWork, s)
+			records[i].UserName = names[s.UserID]
+		}
+
+		dir := options.DownloadDir
+		if dir == "" {
+			dir = "."
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return bulkExportedMsg{err: fmt.Errorf("failed to create download directory: %w", err)}
+		}
+		name := fmt.Sprintf("%s submissions %s.csv", exportName(courseWork.Title), time.Now().Format("2006-01-02 150405"))
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			return bulkExportedMsg{err: fmt.Errorf("failed to create %s: %w", path, err)}
+		}
+		err = export.WriteCSV(f, records)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+			return bulkExportedMsg{err: err}
+		}
+		return bulkExportedMsg{path: path, count: len(records)}
+	}
+}
+
+// exportName makes a coursework title usable in a file name.
+func exportName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, title)
+	if name = strings.Trim(name, ". "); name == "" {
+		return "coursework"
+	}
+	return name
+}
+
+// parseGrade parses a typed grade. It may exceed the coursework's points,
+// as Classroom allows extra credit; gradeWarning says so while it is typed.
+func parseGrade(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("type a grade first")
+	}
+	grade, err := strconv.ParseFloat(value, 64)
+	if err != nil || grade < 0 {
+		return 0, fmt.Errorf("%q is not a grade", value)
+	}
+	return grade, nil
+}
+
+// overMax reports whether grade is more than the maxPoints of graded
+// coursework.
+func overMax(grade float64, maxPoints int) bool {
+	return maxPoints > 0 && grade > float64(maxPoints)
+}
+
+// gradeWarning returns a warning for a typed grade over maxPoints, or ""
+// when there is nothing to warn about.
+func gradeWarning(value string, maxPoints int) string {
+	grade, err := parseGrade(value)
+	if err != nil || !overMax(grade, maxPoints) {
+		return ""
+	}
+	return fmt.Sprintf("%s is over the %d points available and counts as extra credit", formatPoints(grade), maxPoints)
+}
+
+// mark returns the checkbox shown for sub in the table.
+func (b *bulkSelection) mark(sub *api.StudentSubmission) string {
+	if b.marked[sub.ID] {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// render returns the action menu, the grade prompt, or the status of the
+// last bulk action.
+func (b *bulkSelection) render(subs []*api.StudentSubmission, maxPoints int) string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	count := len(b.selected(subs))
+	switch {
+	case b.grading:
+		line := b.input.View() + muted.Render("  enter assign | esc cancel")
+		if warning := gradeWarning(b.input.Value(), maxPoints); warning != "" {
+			line += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#f1fa8c")).Render(warning)
+		}
+		if b.err != nil {
+			line += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render(errorText(b.err))
+		}
+		return line
+	case b.menu:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#f1fa8c")).Bold(true).
+			Render(fmt.Sprintf("%d selected: %s return | %s assign grade | %s export CSV | esc cancel", count,
+				bound(keymap.BulkReturn).Help().Key, bound(keymap.BulkGrade).Help().Key, bound(keymap.BulkExport).Help().Key))
+	case b.running:
+		return muted.Render(fmt.Sprintf("Working on %d submission(s)...", count))
+	case b.err != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render(errorText(b.err))
+	case b.result != "":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd")).Render(b.result)
+	case count > 0:
+		return muted.Render(fmt.Sprintf("%d selected; press %s for bulk actions", count, bound(keymap.Bulk).Help().Key))
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// bulkSubmissions returns n submissions with IDs s1 to sn.
+func bulkSubmissions(n int) []*api.StudentSubmission {
+	subs := make([]*api.StudentSubmission, n)
+	for i := range subs {
+		subs[i] = &api.StudentSubmission{ID: fmt.Sprintf("s%d", i+1)}
+	}
+	return subs
+}
+
+// ids returns the IDs of subs.
+func ids(subs []*api.StudentSubmission) []string {
+	var out []string
+	for _, s := range subs {
+		out = append(out, s.ID)
+	}
+	return out
+}
+
+// TestBulkSelection tests marking submissions and which of them are
+// selected.
+func TestBulkSelection(t *testing.T) {
+	subs := bulkSubmissions(3)
+	var b bulkSelection
+	if b.open(subs) {
+		t.Error("Expected the menu not to open with nothing marked")
+	}
+
+	b.toggle("s3")
+	b.toggle("s1")
+	if got, want := ids(b.selected(subs)), []string{"s1", "s3"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v in list order, got %v", want, got)
+	}
+	b.toggle("s1")
+	if got, want := ids(b.selected(subs)), []string{"s3"}; !slices.Equal(got, want) {
+		t.Errorf("Expected toggling again to unmark, got %v", got)
+	}
+	if got := b.selected(subs[:2]); len(got) != 0 {
+		t.Errorf("Expected marks of unlisted submissions to be ignored, got %v", ids(got))
+	}
+
+	b.toggleAll(subs)
+	if got := len(b.selected(subs)); got != 3 {
+		t.Errorf("Expected all 3 marked, got %d", got)
+	}
+	b.toggleAll(subs)
+	if got := len(b.selected(subs)); got != 0 {
+		t.Errorf("Expected all unmarked, got %d", got)
+	}
+
+	b.toggle("s2")
+	if !b.open(subs) || !b.active() {
+		t.Error("Expected the menu to open")
+	}
+	b.running = true
+	if b.open(subs) {
+		t.Error("Expected the menu not to open while an action runs")
+	}
+}
+
+// TestBulkRun tests that a bulk action is bounded, and that failures
+// neither stop the rest nor lose their marks.
+func TestBulkRun(t *testing.T) {
+	subs := bulkSubmissions(10)
+	var (
+		mu      sync.Mutex
+		running int
+		most    int
+	)
+	fn := func(ctx context.Context, s *api.StudentSubmission) error {
+		mu.Lock()
+		running++
+		most = max(most, running)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if s.ID == "s2" || s.ID == "s7" {
+			return errors.New("backend error")
+		}
+		return nil
+	}
+
+	var b bulkSelection
+	b.toggleAll(subs)
+	msg := b.run("Returned", b.selected(subs), fn)()
+	if most > bulkConcurrency {
+		t.Errorf("Expected at most %d at once, got %d", bulkConcurrency, most)
+	}
+	done, ok := msg.(bulkDoneMsg)
+	if !ok {
+		t.Fatalf("Expected bulkDoneMsg, got %T", msg)
+	}
+	if done.done != 8 {
+		t.Errorf("Expected 8 done, got %d", done.done)
+	}
+	if want := []string{"s2", "s7"}; !slices.Equal(done.failed, want) {
+		t.Errorf("Expected %v failed, got %v", want, done.failed)
+	}
+
+	b.update(done)
+	if b.running {
+		t.Error("Expected the action to have finished")
+	}
+	if got, want := ids(b.selected(subs)), []string{"s2", "s7"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the failed to stay marked, got %v", got)
+	}
+	if b.err == nil || !strings.Contains(b.err.Error(), "2 failed") {
+		t.Errorf("Expected the failures to be reported, got %v", b.err)
+	}
+	if b.result != "Returned 8 submission(s)" {
+		t.Errorf("Expected the result, got %q", b.result)
+	}
+}
+
+// TestParseGrade tests typed grades, including extra credit.
+func TestParseGrade(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+		warning bool
+	}{
+		{value: "", wantErr: true},
+		{value: "  ", wantErr: true},
+		{value: "ten", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "0", want: 0},
+		{value: " 17.5 ", want: 17.5},
+		{value: "20", want: 20},
+		{value: "22", want: 22, warning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.value), func(t *testing.T) {
+			got, err := parseGrade(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			if warning := gradeWarning(tt.value, 20); (warning != "") != tt.warning {
+				t.Errorf("Expected warning %v, got %q", tt.warning, warning)
+			}
+		})
+	}
+
+	if warning := gradeWarning("22", 0); warning != "" {
+		t.Errorf("Expected no warning for ungraded coursework, got %q", warning)
+	}
+}
+
+// TestExportName tests that titles become usable file names.
+func TestExportName(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Lab report", "Lab report"},
+		{"Unit 3: Cells/Tissues", "Unit 3_ Cells_Tissues"},
+		{`What is "life"?`, "What is _life__"},
+		{"Essay\tdraft\n", "Essaydraft"},
+		{" ..hidden. ", "hidden"},
+		{"", "coursework"},
+		{"...", "coursework"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := exportName(tt.title); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package tea
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/humanize"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// CacheModel shows how much the cache holds, overall and per course, and
+// clears it or turns caching on and off.
+type CacheModel struct {
+	stats *cache.CacheStats
+	// courses are the course IDs in stats, largest first.
+	courses []string
+	// names maps course IDs to names for display; unknown courses show
+	// their ID.
+	names   map[string]string
+	cursor  int
+	prompt  *confirmation
+	notice  string
+	err     error
+	loading bool
+	help    helpOverlay
+	width   int
+	height  int
+}
+
+// OpenCacheMsg is sent to open the cache screen.
+type OpenCacheMsg struct {
+	// CourseNames maps course IDs to the names shown for them.
+	CourseNames map[string]string
+}
+
+// cacheStatsMsg carries freshly read cache statistics.
+type cacheStatsMsg struct {
+	stats *cache.CacheStats
+	err   error
+}
+
+// cacheChangedMsg is sent after the cache was cleared or toggled.
+type cacheChangedMsg struct {
+	notice string
+	err    error
+}
+
+// NewCacheModel creates the cache screen. names maps course IDs to names.
+func NewCacheModel(names map[string]string) *CacheModel {
+	return &CacheModel{names: names, loading: true}
+}
+
+// Init initializes the model.
+func (m *CacheModel) Init() tea.Cmd {
+	return m.load()
+}
+
+// load reads the cache statistics.
+func (m *CacheModel) load() tea.Cmd {
+	c := options.Cache
+	if c == nil {
+		m.loading = false
+		return nil
+	}
+	return func() tea.Msg {
+		stats, err := c.GetStats()
+		return cacheStatsMsg{stats: stats, err: err}
+	}
+}
+
+// change runs a change to the cache, reporting notice when it succeeds.
+func (m *CacheModel) change(notice string, fn func(c *cache.Cache) error) tea.Cmd {
+	c := options.Cache
+	return func() tea.Msg {
+		return cacheChangedMsg{notice: notice, err: fn(c)}
+	}
+}
+
+// Update handles messages.
+func (m *CacheModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.prompt != nil {
+			done, cmd := m.prompt.handleKey(msg)
+			if done {
+				m.prompt = nil
+			}
+			return m, cmd
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Up)):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case key.Matches(msg, bound(keymap.Down)):
+			if m.cursor < len(m.courses)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.loading = true
+			return m, m.load()
+		case msg.String() == "?":
+			m.help.toggle()
+			return m, nil
+		}
+		if options.Cache == nil {
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, bound(keymap.ClearCourse)):
+			if m.cursor >= len(m.courses) {
+				return m, nil
+			}
+			id := m.courses[m.cursor]
+			name := m.courseName(id)
+			var cmd tea.Cmd
+			m.prompt, cmd = requireConfirmation(confirm.Delete,
+				fmt.Sprintf("Clear cached data for %s?", name),
+				func() tea.Cmd {
+					return m.change("Cleared "+name+".", func(c *cache.Cache) error {
+						return c.ClearCourse(id)
+					})
+				})
+			return m, cmd
+		case key.Matches(msg, bound(keymap.ClearAll)):
+			var cmd tea.Cmd
+			m.prompt, cmd = requireConfirmation(confirm.Delete, "Clear the whole cache?", func() tea.Cmd {
+				return m.change("Cleared the cache.", (*cache.Cache).Clear)
+			})
+			return m, cmd
+		case key.Matches(msg, bound(keymap.ToggleCache)):
+			enable := !options.Cache.Enabled()
+			notice := "Caching turned off; screens load from the API."
+			if enable {
+				notice = "Caching turned on."
+			}
+			return m, m.change(notice, func(c *cache.Cache) error {
+				return c.SetEnabled(enable)
+			})
+		}
+
+	case cacheStatsMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.stats = msg.stats
+			m.courses = cache.CoursesBySize(msg.stats)
+			m.cursor = min(m.cursor, max(len(m.courses)-1, 0))
+		}
+		return m, nil
+
+	case cacheChangedMsg:
+		m.err = msg.err
+		m.notice = ""
+		if msg.err == nil {
+			m.notice = msg.notice
+		}
+		m.loading = true
+		return m, m.load()
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// courseName returns the name of a course, or its ID when unknown.
+func (m *CacheModel) courseName(id string) string {
+	if name := m.names[id]; name != "" {
+		return name
+	}
+	return id
+}
+
+// View renders the model.
+func (m *CacheModel) View() string {
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render("Cache")
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Width(14)
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true)
+
+	sections := []string{header, ""}
+	switch {
+	case options.Cache == nil:
+		sections = append(sections, value.Render("Caching is not configured."))
+	case m.stats == nil && m.loading:
+		sections = append(sections, muted.Render("Loading..."))
+	case m.stats != nil:
+		state := "on"
+		if !options.Cache.Enabled() {
+			state = "off"
+		}
+		rows := [][2]string{
+			{"Caching", state},
+			{"Storage", options.Cache.Backend()},
+			{"Entries", fmt.Sprintf("%d (%d expired)", m.stats.TotalEntries, m.stats.ExpiredEntries)},
+			{"Size", humanize.Bytes(m.stats.TotalSize)},
+		}
+		for _, r := range rows {
+			sections = append(sections, label.Render(r[0])+value.Render(r[1]))
+		}
+
+		sections = append(sections, "", label.Render("Courses"))
+		if len(m.courses) == 0 {
+			sections = append(sections, muted.Render("  No course data cached."))
+		}
+		for i, id := range m.courses {
+			course := m.stats.Courses[id]
+			line := fmt.Sprintf("%s  %d entries (%d expired), %s",
+				m.courseName(id), course.Entries, course.ExpiredEntries, humanize.Bytes(course.Size))
+			if i == m.cursor {
+				sections = append(sections, selected.Render("> "+line))
+			} else {
+				sections = append(sections, value.Render("  "+line))
+			}
+		}
+	}
+
+	if m.prompt != nil {
+		sections = append(sections, "", m.prompt.View())
+	} else if m.err != nil {
+		sections = append(sections, "", lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.err)))
+	} else if m.notice != "" {
+		sections = append(sections, "", muted.Render(m.notice))
+	}
+
+	keys := []key.Binding{backKey()}
+	if options.Cache != nil {
+		keys = []key.Binding{navigateKey(), actionKey(keymap.ClearCourse, "clear course"), actionKey(keymap.ClearAll, "clear all"), actionKey(keymap.ToggleCache, "toggle caching"), refreshKey(), backKey()}
+	}
+	sections = append(sections, "")
+	if bar := statusBar(nil, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, keys...))
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
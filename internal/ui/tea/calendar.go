@@ -0,0 +1,70 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api/calendar"
+)
+
+// calendarSyncedMsg is sent when a calendar sync finishes.
+type calendarSyncedMsg struct {
+	courseID string
+	result   *calendar.Result
+	err      error
+}
+
+// calendarSync tracks syncing a course's due dates to Google Calendar with
+// 's'.
+type calendarSync struct {
+	courseID string
+	running  bool
+	result   *calendar.Result
+	err      error
+}
+
+// start syncs the course's due dates to the configured calendar. It
+// returns nil when calendar sync is not configured or already running.
+func (c *calendarSync) start(src calendar.Source, courseID string) tea.Cmd {
+	if options.Calendar == nil || c.running {
+		return nil
+	}
+	*c = calendarSync{courseID: courseID, running: true}
+
+	client, target := options.Calendar, options.CalendarTarget
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+
+		id, err := client.Resolve(ctx, src, courseID, target, true)
+		if err != nil {
+			return calendarSyncedMsg{courseID: courseID, err: err}
+		}
+		result, err := client.Sync(ctx, src, courseID, id, false)
+		return calendarSyncedMsg{courseID: courseID, result: result, err: err}
+	}
+}
+
+// update applies a finished sync for this course.
+func (c *calendarSync) update(msg calendarSyncedMsg) {
+	if msg.courseID != c.courseID {
+		return
+	}
+	c.running = false
+	c.result, c.err = msg.result, msg.err
+	if msg.err != nil {
+		reportError(msg.err)
+	}
+}
+
+// render returns a status line for the current or last sync.
+func (c *calendarSync) render() string {
+	switch {
+	case c.running:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd")).Render("Syncing due dates to calendar...")
+	case c.err != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render("Calendar sync failed: " + errorText(c.err))
+	case c.result != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Render("Calendar synced: " + c.result.String())
+	}
+	return ""
+}
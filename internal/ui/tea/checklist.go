@@ -0,0 +1,220 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/checklist"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// ChecklistModel lets a student keep private subtasks for an assignment.
+// The checklist is stored locally and never sent to Classroom. It needs
+// options.Checklists.
+type ChecklistModel struct {
+	course     *api.Course
+	courseWork *api.CourseWork
+	items      []checklist.Item
+	cursor     int
+	input      textinput.Model
+	adding     bool
+	actionErr  error
+	help       helpOverlay
+	width      int
+	height     int
+}
+
+// NewChecklistModel creates a checklist model for the coursework.
+func NewChecklistModel(course *api.Course, courseWork *api.CourseWork) *ChecklistModel {
+	ti := textinput.New()
+	ti.Placeholder = "New subtask..."
+	ti.Prompt = "+ "
+	ti.Width = 50
+
+	m := &ChecklistModel{
+		course:     course,
+		courseWork: courseWork,
+		input:      ti,
+	}
+	m.reload()
+	return m
+}
+
+// Init initializes the model.
+func (m *ChecklistModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages.
+func (m *ChecklistModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.adding {
+			return m, m.handleInput(msg)
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Up)):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, bound(keymap.Down)):
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, bound(keymap.Select), bound(keymap.Toggle)):
+			if len(m.items) > 0 {
+				m.apply(options.Checklists.Toggle(m.course.ID, m.courseWork.ID, m.cursor))
+			}
+		case key.Matches(msg, bound(keymap.Add)):
+			m.actionErr = nil
+			m.adding = true
+			m.input.Reset()
+			m.input.Focus()
+			return m, textinput.Blink
+		case key.Matches(msg, bound(keymap.Delete)):
+			if len(m.items) > 0 {
+				m.apply(options.Checklists.Remove(m.course.ID, m.courseWork.ID, m.cursor))
+			}
+		case msg.String() == "?":
+			m.help.toggle()
+			return m, nil
+		}
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// handleInput edits the new subtask; enter adds it and esc cancels.
+func (m *ChecklistModel) handleInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.adding = false
+		m.input.Blur()
+		return nil
+	case "enter":
+		m.adding = false
+		m.input.Blur()
+		if strings.TrimSpace(m.input.Value()) == "" {
+			return nil
+		}
+		m.apply(options.Checklists.Add(m.course.ID, m.courseWork.ID, m.input.Value()))
+		if m.actionErr == nil {
+			m.cursor = len(m.items) - 1
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+// apply records the result of a change and shows the saved checklist.
+func (m *ChecklistModel) apply(err error) {
+	m.actionErr = err
+	m.reload()
+}
+
+// reload reads the checklist from the store and keeps the cursor in range.
+func (m *ChecklistModel) reload() {
+	m.items = options.Checklists.Items(m.course.ID, m.courseWork.ID)
+	m.cursor = min(m.cursor, max(len(m.items)-1, 0))
+}
+
+// View renders the model.
+func (m *ChecklistModel) View() string {
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(fmt.Sprintf("Checklist | %s", m.courseWork.Title))
+
+	progress := options.Checklists.Progress(m.course.ID, m.courseWork.ID)
+	summary := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#bd93f9")).
+		Render(fmt.Sprintf("%s done", progress))
+
+	var lines []string
+	for i, item := range m.items {
+		box := "[ ]"
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+		if item.Done {
+			box = "[x]"
+			style = style.Foreground(lipgloss.Color("#6272a4")).Strikethrough(true)
+		}
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+			style = style.Bold(true)
+		}
+		lines = append(lines, cursor+box+" "+style.Render(item.Text))
+	}
+	body := strings.Join(lines, "\n")
+	if len(m.items) == 0 {
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f8f8f2")).
+			Render("No subtasks yet. Press " + bound(keymap.Add).Help().Key + " to add one.")
+	}
+
+	footer := m.help.render(m.width, navigateKey(), actionKey(keymap.Toggle, "toggle"), actionKey(keymap.Add, "add"), actionKey(keymap.Delete, "delete"), backKey(), quitKey())
+	if m.adding {
+		footer = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272a4")).
+			Render("enter add | esc cancel")
+	}
+
+	sections := []string{header, summary, "", body, ""}
+	if m.adding {
+		sections = append(sections, m.input.View(), "")
+	}
+	if m.actionErr != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.actionErr)))
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// checklistBadge returns the coursework's checklist progress, such as
+// "☐ 2/5", or "" when it has no checklist.
+func checklistBadge(courseID, courseWorkID string) string {
+	p := options.Checklists.Progress(courseID, courseWorkID)
+	switch {
+	case p.Total == 0:
+		return ""
+	case p.Complete():
+		return "☑ " + p.String()
+	}
+	return "☐ " + p.String()
+}
+
+// ChecklistMsg is sent when a student opens an assignment's checklist.
+type ChecklistMsg struct {
+	Course     *api.Course
+	CourseWork *api.CourseWork
+}
@@ -0,0 +1,42 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/confirm"
+)
+
+// confirmation is a pending yes/no prompt guarding an action.
+type confirmation struct {
+	prompt string
+	run    func() tea.Cmd
+}
+
+// requireConfirmation runs an action straight away when the policy allows it,
+// or returns a prompt that must be answered first.
+func requireConfirmation(action confirm.Action, prompt string, run func() tea.Cmd) (*confirmation, tea.Cmd) {
+	if !options.Confirm.Required(action) {
+		return nil, run()
+	}
+	return &confirmation{prompt: prompt, run: run}, nil
+}
+
+// handleKey answers the prompt: y or enter runs the action, n or esc drops
+// it. It reports whether the prompt is finished.
+func (c *confirmation) handleKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return true, c.run()
+	case "n", "N", "esc":
+		return true, nil
+	}
+	return false, nil
+}
+
+// View renders the prompt.
+func (c *confirmation) View() string {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f1fa8c")).
+		Bold(true).
+		Render(c.prompt + " (y/n)")
+}
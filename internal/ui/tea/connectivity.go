@@ -0,0 +1,84 @@
+package tea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/connectivity"
+)
+
+// connectivityUpdates receives connection state changes from the monitor.
+var connectivityUpdates <-chan connectivity.State
+
+// connectivityMsg is sent when the app goes offline or comes back online.
+type connectivityMsg struct {
+	state connectivity.State
+}
+
+// watchConnectivity waits for the next connection state change. Every
+// screen re-issues it on connectivityMsg so exactly one watch is pending.
+func watchConnectivity() tea.Cmd {
+	if connectivityUpdates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return connectivityMsg{state: <-connectivityUpdates}
+	}
+}
+
+// isOffline reports whether the monitor last saw the API as unreachable.
+func isOffline() bool {
+	return options.Connectivity != nil && !options.Connectivity.Online()
+}
+
+// reportError tells the monitor about a failed request so a lost connection
+// is noticed without waiting for the next probe.
+func reportError(err error) {
+	if options.Connectivity != nil {
+		options.Connectivity.Report(err)
+	}
+}
+
+// staleSince returns when the oldest expired cache entry on show was
+// cached, or the zero time when none is.
+func staleSince() time.Time {
+	if options.Cache == nil {
+		return time.Time{}
+	}
+	return options.Cache.StaleSince()
+}
+
+// formatAge describes how long ago something happened, e.g. "3h".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+}
+
+// offlineBadge renders the offline indicator, or "" while online. Reads
+// answered with expired cache entries count as offline even before the
+// monitor notices, and the badge says how old they are.
+func offlineBadge() string {
+	since := staleSince()
+	if !isOffline() && since.IsZero() {
+		return ""
+	}
+	text := "● offline - showing last loaded data"
+	if !since.IsZero() {
+		text = "● offline - showing cached data from " + formatAge(time.Since(since)) + " ago"
+	}
+	if n := pendingSyncCount(); n > 0 {
+		text += fmt.Sprintf(" | %d change(s) pending sync", n)
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff5555")).
+		Render(text)
+}
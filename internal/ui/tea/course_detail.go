@@ -1,15 +1,23 @@
 package tea
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/collation"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/connectivity"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/outbox"
 )
 
 // Tab definitions
@@ -20,6 +28,18 @@ const (
 	TabStudents
 	TabTeachers
 	TabAnnouncements
+	// TabStream interleaves coursework and announcements; it is built from
+	// the other tabs, not loaded itself.
+	TabStream
+)
+
+// tabState is how far a tab's data has loaded.
+type tabState int
+
+const (
+	tabUnloaded tabState = iota
+	tabLoading
+	tabLoaded
 )
 
 func (t Tab) String() string {
@@ -32,66 +52,166 @@ func (t Tab) String() string {
 		return "Teachers"
 	case TabAnnouncements:
 		return "Announcements"
+	case TabStream:
+		return "Stream"
 	default:
 		return "Unknown"
 	}
 }
 
+// sources returns the tabs whose data t shows: the stream's are coursework
+// and announcements, every other tab's its own.
+func (t Tab) sources() []Tab {
+	if t == TabStream {
+		return []Tab{TabCoursework, TabAnnouncements}
+	}
+	return []Tab{t}
+}
+
 // CourseDetailModel represents the course detail TUI model.
 type CourseDetailModel struct {
 	course        *api.Course
-	apiClient     *api.Client
+	apiClient     api.ClassroomClient
+	refresh       map[Tab]bool // tabs whose next load skips the cache
 	coursework    []*api.CourseWork
 	students      []*api.Student
 	teachers      []*api.Teacher
 	announcements []*api.Announcement
-	activeTab     Tab
-	table         table.Model
-	loading       bool
-	err           error
-	width         int
-	height        int
+	stream        []streamItem
+	// lastSeen is when the stream was last seen before the course was
+	// opened; seenOnce is false when it never was.
+	lastSeen   time.Time
+	seenOnce   bool
+	activeTab  Tab
+	table      table.Model
+	isTeacher  bool
+	deletions  deletionQueue
+	prompt     *confirmation
+	tabStates  map[Tab]tabState // loaded tabs are kept until a reload
+	loadedOnce bool
+	offline    bool
+	syncNotice string
+	calendar   calendarSync
+	link       browserLink
+	tabErrs    map[Tab]error // tabs that failed their last load, never TabStream
+	err        error
+	help       helpOverlay
+	width      int
+	height     int
 }
 
 // NewCourseDetailModel creates a new course detail model.
-func NewCourseDetailModel(course *api.Course, apiClient *api.Client) *CourseDetailModel {
+func NewCourseDetailModel(course *api.Course, apiClient api.ClassroomClient) *CourseDetailModel {
 	// Create table with basic configuration
-	t := table.New()
+	t := table.New(table.WithKeyMap(tableKeyMap()))
 	t.SetHeight(20)
 
+	lastSeen, seenOnce := options.ReadState.LastSeen(course.ID)
 	return &CourseDetailModel{
 		course:    course,
-		apiClient: apiClient,
+		apiClient: cache.NewCachedClient(apiClient, options.Cache),
 		activeTab: TabCoursework,
 		table:     t,
-		loading:   true,
+		refresh:   make(map[Tab]bool),
+		tabStates: make(map[Tab]tabState),
+		tabErrs:   make(map[Tab]error),
+		lastSeen:  lastSeen,
+		seenOnce:  seenOnce,
 	}
 }
 
 // Init initializes the model.
 func (m *CourseDetailModel) Init() tea.Cmd {
-	return m.loadData()
+	return tea.Batch(m.loadRole(), m.loadTab(m.activeTab))
 }
 
 // Update handles messages.
 func (m *CourseDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := m.deletions.Update(msg); handled {
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc", "b":
-			return m, func() tea.Msg { return NavigateBackMsg{} }
-		case "left", "h":
-			m.prevTab()
-		case "right", "l":
-			m.nextTab()
-		case "r":
-			m.loading = true
+		if m.prompt != nil {
+			done, cmd := m.prompt.handleKey(msg)
+			if done {
+				m.prompt = nil
+			}
+			return m, cmd
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, tea.Batch(m.deletions.Flush(), func() tea.Msg { return NavigateBackMsg{} })
+		case key.Matches(msg, bound(keymap.Left)):
+			return m, m.prevTab()
+		case key.Matches(msg, bound(keymap.Right)):
+			return m, m.nextTab()
+		case key.Matches(msg, bound(keymap.Refresh)):
+			if isOffline() && m.loadedOnce {
+				return m, nil
+			}
+			if m.err == nil && m.tabErr(m.activeTab) != nil {
+				return m, m.retry()
+			}
 			m.err = nil
-			return m, m.loadData()
-		case "enter":
+			return m, m.reload(true)
+		case key.Matches(msg, bound(keymap.Select)):
 			return m, m.handleEnter()
+		case key.Matches(msg, bound(keymap.Open)):
+			return m, m.link.open(m.selectedLink())
+		case key.Matches(msg, bound(keymap.Delete)):
+			if !m.isTeacher || m.activeTab == TabStream {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.prompt, cmd = requireConfirmation(confirm.Delete,
+				fmt.Sprintf("Delete the selected %s entry?", strings.ToLower(m.activeTab.String())),
+				m.deleteSelected)
+			return m, cmd
+		case key.Matches(msg, bound(keymap.Undo)):
+			m.deletions.Undo()
+			return m, nil
+		case key.Matches(msg, bound(keymap.Attendance)):
+			if m.isTeacher {
+				course := m.course
+				return m, func() tea.Msg { return AttendanceMsg{Course: course} }
+			}
+		case key.Matches(msg, bound(keymap.SyncCalendar)):
+			return m, m.calendar.start(m.apiClient, m.course.ID)
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "P":
+				if err := cmp.Or(m.err, m.tabErr(m.activeTab)); err != nil {
+					return m, recoverFromError(err, msg.String())
+				}
+			case "D":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+				return m, m.link.openOr(m.folderLink(), "this course has no Drive folder (only teachers can see it)")
+			}
 		}
 
+	case reauthMsg:
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.err = nil
+		return m, tea.Batch(m.reload(false), afterRecovery(msg))
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -99,19 +219,68 @@ func (m *CourseDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.table.SetHeight(msg.Height - 15)
 		return m, nil
 
-	case dataLoadedMsg:
-		m.coursework = msg.coursework
-		m.students = msg.students
-		m.teachers = msg.teachers
-		m.announcements = msg.announcements
-		m.loading = false
-		m.err = nil
+	case roleLoadedMsg:
+		// A failed check leaves teacher actions hidden until the
+		// coursework loads, which checks again
+		if msg.err == nil {
+			m.isTeacher = msg.isTeacher
+		}
+		return m, nil
+
+	case tabLoadedMsg:
+		m.tabStates[msg.tab] = tabLoaded
+		m.tabErrs[msg.tab] = msg.err
+		if msg.err != nil {
+			// A tab that fails keeps what it showed before, and the others
+			// are unaffected
+			reportError(msg.err)
+			if sessionExpired(msg.err) {
+				m.err = msg.err
+			}
+			return m, nil
+		}
+		switch msg.tab {
+		case TabCoursework:
+			m.isTeacher = msg.isTeacher
+			m.coursework = withoutPending(&m.deletions, msg.coursework, func(cw *api.CourseWork) string { return "coursework:" + cw.ID })
+			m.coursework = withoutQueued(m.coursework, outbox.KindDeleteCourseWork, func(cw *api.CourseWork) string { return cw.ID })
+		case TabStudents:
+			m.students = withoutPending(&m.deletions, msg.students, func(s *api.Student) string { return "student:" + s.UserID })
+			m.students = withoutQueued(m.students, outbox.KindRemoveStudent, func(s *api.Student) string { return s.UserID })
+		case TabTeachers:
+			m.teachers = withoutPending(&m.deletions, msg.teachers, func(t *api.Teacher) string { return "teacher:" + t.UserID })
+			m.teachers = withoutQueued(m.teachers, outbox.KindRemoveTeacher, func(t *api.Teacher) string { return t.UserID })
+		case TabAnnouncements:
+			m.announcements = withoutPending(&m.deletions, msg.announcements, func(a *api.Announcement) string { return "announcement:" + a.ID })
+			m.announcements = withoutQueued(m.announcements, outbox.KindDeleteAnnouncement, func(a *api.Announcement) string { return a.ID })
+		}
+		m.loadedOnce = true
 		m.updateTable()
+		m.markStreamSeen()
 		return m, nil
 
-	case dataLoadErrorMsg:
-		m.loading = false
-		m.err = msg.err
+	case connectivityMsg:
+		wasOffline := m.offline
+		m.offline = msg.state == connectivity.Offline
+		if wasOffline && !m.offline {
+			m.err = nil
+			return m, tea.Batch(watchConnectivity(), syncOutbox(m.apiClient), m.reload(false))
+		}
+		return m, watchConnectivity()
+
+	case outboxSyncedMsg:
+		m.syncNotice = renderSyncResult(msg)
+		if msg.result != nil && len(msg.result.Applied) > 0 {
+			return m, m.reload(false)
+		}
+		return m, nil
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case calendarSyncedMsg:
+		m.calendar.update(msg)
 		return m, nil
 	}
 
@@ -122,43 +291,8 @@ func (m *CourseDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the model.
 func (m *CourseDetailModel) View() string {
-	if m.loading {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center).
-			Render(
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#ff79c6")).
-						Bold(true).
-						Render(m.course.Name),
-					"",
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#bd93f9")).
-						Render("Loading data..."),
-				),
-			)
-	}
-
 	if m.err != nil {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center).
-			Render(
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#ff5555")).
-						Bold(true).
-						Render("Error loading data"),
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#f8f8f2")).
-						Render(m.err.Error()),
-				),
-			)
+		return renderErrorView("Error loading data", m.err, m.width, m.height)
 	}
 
 	// Render header
@@ -167,13 +301,59 @@ func (m *CourseDetailModel) View() string {
 	// Render tabs
 	tabs := m.renderTabs()
 
-	// Render table
+	// Render table, unless none of it has loaded yet
 	tableView := m.table.View()
+	if m.waiting(m.activeTab) {
+		tableView = ""
+	}
 
 	// Render footer
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272a4")).
-		Render("←→/hl change tab | enter select | b back | r refresh | q quit")
+	keys := []key.Binding{actionPair(keymap.Left, keymap.Right, "change tab"), actionKey(keymap.Select, "select")}
+	if m.isTeacher {
+		keys = append(keys, actionKey(keymap.Delete, "delete"), actionKey(keymap.Attendance, "attendance"))
+	}
+	keys = append(keys, actionKey(keymap.Open, "open"))
+	if m.folderLink() != "" {
+		keys = append(keys, bind("D", "drive folder"))
+	}
+	if options.Calendar != nil {
+		keys = append(keys, actionKey(keymap.SyncCalendar, "sync to calendar"))
+	}
+	refresh := refreshKey()
+	if m.tabErr(m.activeTab) != nil {
+		refresh = actionKey(keymap.Refresh, "retry")
+	}
+	keys = append(keys, backKey(), refresh, quitKey())
+	footer := m.help.render(m.width, keys...)
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	if badge := budgetBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, tabs, "")
+	sections = append(sections, m.renderSectionStates()...)
+	if tableView != "" {
+		sections = append(sections, tableView)
+	}
+	sections = append(sections, "")
+	if m.prompt != nil {
+		sections = append(sections, m.prompt.View())
+	} else if undo := m.deletions.View(); undo != "" {
+		sections = append(sections, undo)
+	} else if status := m.calendar.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	} else if m.syncNotice != "" {
+		sections = append(sections, m.syncNotice)
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
 
 	return lipgloss.NewStyle().
 		Width(m.width).
@@ -182,13 +362,7 @@ func (m *CourseDetailModel) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Left,
-				header,
-				"",
-				tabs,
-				"",
-				tableView,
-				"",
-				footer,
+				sections...,
 			),
 		)
 }
@@ -207,6 +381,9 @@ func (m *CourseDetailModel) renderHeader() string {
 	if m.course.Room != "" {
 		lines = append(lines, fmt.Sprintf("Room: %s", m.course.Room))
 	}
+	if f := m.course.TeacherFolder; f != nil && f.Title != "" {
+		lines = append(lines, fmt.Sprintf("Drive: %s", f.Title))
+	}
 
 	return style.Render(
 		lipgloss.NewStyle().
@@ -218,18 +395,27 @@ func (m *CourseDetailModel) renderHeader() string {
 // renderTabs renders the tab bar.
 func (m *CourseDetailModel) renderTabs() string {
 	var tabs []string
-	for i := Tab(0); i <= TabAnnouncements; i++ {
+	for i := Tab(0); i <= TabStream; i++ {
+		label := i.String()
+		if n := m.unreadCount(); i == TabStream && n > 0 {
+			label += fmt.Sprintf(" (%d new)", n)
+		}
+		if m.tabLoading(i) {
+			label += " …"
+		} else if m.tabErr(i) != nil {
+			label += " !"
+		}
 		if i == m.activeTab {
 			tabs = append(tabs, lipgloss.NewStyle().
 				Background(lipgloss.Color("#6272a4")).
 				Foreground(lipgloss.Color("#f8f8f2")).
 				Padding(0, 2).
-				Render(" "+i.String()+" "))
+				Render(" "+label+" "))
 		} else {
 			tabs = append(tabs, lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#6272a4")).
 				Padding(0, 2).
-				Render(" "+i.String()+" "))
+				Render(" "+label+" "))
 		}
 	}
 
@@ -243,45 +429,142 @@ func (m *CourseDetailModel) renderTabs() string {
 		)
 }
 
-// loadData loads all course data.
-func (m *CourseDetailModel) loadData() tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+// loadTab returns the command loading the data tab shows that isn't
+// loaded or loading yet, or nil when there is none. Each of its sources
+// loads on its own, so the stream shows whatever is loaded first.
+func (m *CourseDetailModel) loadTab(tab Tab) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, source := range tab.sources() {
+		if m.tabStates[source] != tabUnloaded {
+			continue
+		}
+		m.tabStates[source] = tabLoading
+		cmds = append(cmds, m.fetchTab(source, m.refresh[source]))
+		delete(m.refresh, source)
+	}
+	return tea.Batch(cmds...)
+}
+
+// reload drops the loaded tabs, skipping the cache when refresh is set, and
+// loads the active tab again. The others load again when next shown.
+func (m *CourseDetailModel) reload(refresh bool) tea.Cmd {
+	for tab := TabCoursework; tab < TabStream; tab++ {
+		if m.tabStates[tab] == tabLoading {
+			continue
+		}
+		m.tabStates[tab] = tabUnloaded
+		if refresh {
+			m.refresh[tab] = true
+		}
+	}
+	return m.loadTab(m.activeTab)
+}
+
+// retry loads the sections of the active tab that failed again, skipping
+// the cache, and leaves the ones that loaded as they are.
+func (m *CourseDetailModel) retry() tea.Cmd {
+	for _, source := range m.activeTab.sources() {
+		if m.tabErrs[source] != nil && m.tabStates[source] == tabLoaded {
+			m.tabStates[source] = tabUnloaded
+			m.refresh[source] = true
+		}
+	}
+	return m.loadTab(m.activeTab)
+}
 
-		coursework, err := m.apiClient.ListCourseWork(ctx, m.course.ID)
-		if err != nil {
-			return dataLoadErrorMsg{err: err}
+// tabErr returns the error of a section of tab that failed its last load, or
+// nil when none did.
+func (m *CourseDetailModel) tabErr(tab Tab) error {
+	for _, source := range tab.sources() {
+		if err := m.tabErrs[source]; err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		students, err := m.apiClient.ListStudents(ctx, m.course.ID)
-		if err != nil {
-			return dataLoadErrorMsg{err: err}
+// waiting reports whether every section of tab is loading, so there is
+// nothing of it to show yet.
+func (m *CourseDetailModel) waiting(tab Tab) bool {
+	for _, source := range tab.sources() {
+		if m.tabStates[source] != tabLoading {
+			return false
 		}
+	}
+	return true
+}
 
-		teachers, err := m.apiClient.ListTeachers(ctx, m.course.ID)
-		if err != nil {
-			return dataLoadErrorMsg{err: err}
+// tabLoading reports whether any of the data tab shows is loading.
+func (m *CourseDetailModel) tabLoading(tab Tab) bool {
+	for _, source := range tab.sources() {
+		if m.tabStates[source] == tabLoading {
+			return true
 		}
+	}
+	return false
+}
+
+// loadRole checks whether the user teaches the course, so teacher actions
+// are offered whichever tab is open and whether or not it loads.
+func (m *CourseDetailModel) loadRole() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		isTeacher, err := m.apiClient.IsTeacher(ctx, m.course.ID)
+		return roleLoadedMsg{isTeacher: isTeacher, err: err}
+	}
+}
 
-		announcements, err := m.apiClient.ListAnnouncements(ctx, m.course.ID)
-		if err != nil {
-			return dataLoadErrorMsg{err: err}
+// fetchTab loads the data of tab, which is not the stream.
+func (m *CourseDetailModel) fetchTab(tab Tab, refresh bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := loadContext(refresh)
+		defer cancel()
+
+		msg := tabLoadedMsg{tab: tab}
+		switch tab {
+		case TabCoursework:
+			msg.isTeacher, msg.coursework, msg.err = loadVisibleCourseWork(ctx, m.apiClient, m.course.ID, api.CourseWorkOrderDueDateAsc)
+		case TabStudents:
+			msg.students, msg.err = m.apiClient.ListStudents(ctx, m.course.ID, nil)
+			collation.Sort(msg.students, func(s *api.Student) string { return s.Profile.Name })
+		case TabTeachers:
+			msg.teachers, msg.err = m.apiClient.ListTeachers(ctx, m.course.ID, nil)
+			collation.Sort(msg.teachers, func(t *api.Teacher) string { return t.Profile.Name })
+		case TabAnnouncements:
+			msg.announcements, msg.err = m.apiClient.ListAnnouncements(ctx, m.course.ID, nil)
 		}
+		return msg
+	}
+}
 
-		return dataLoadedMsg{
-			coursework:    coursework,
-			students:      students,
-			teachers:      teachers,
-			announcements: announcements,
+// renderSectionStates renders a line for each section of the active tab that
+// is loading or failed to load. The stream has a section for each of its
+// sources, so one failing leaves the other's posts on screen.
+func (m *CourseDetailModel) renderSectionStates() []string {
+	var lines []string
+	for _, source := range m.activeTab.sources() {
+		name := strings.ToLower(source.String())
+		switch {
+		case m.tabStates[source] == tabLoading:
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#bd93f9")).
+				Render(fmt.Sprintf("Loading %s...", name)))
+		case m.tabErrs[source] != nil:
+			err := m.tabErrs[source]
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#ff5555")).
+				Render(fmt.Sprintf("Couldn't load %s: %s (%s)", name, errorText(err), recoveryHint(err))))
 		}
 	}
+	return lines
 }
 
 // updateTable updates the table based on the active tab.
 func (m *CourseDetailModel) updateTable() {
 	var rows []table.Row
 	var columns []table.Column
+	m.stream = buildStream(m.coursework, m.announcements)
 
 	switch m.activeTab {
 	case TabCoursework:
@@ -297,7 +580,7 @@ func (m *CourseDetailModel) updateTable() {
 				dueDate = cw.DueDate
 			}
 			rows = append(rows, table.Row{
-				cw.Title,
+				stateBadge(cw) + cw.Title,
 				cw.WorkType,
 				dueDate,
 				fmt.Sprintf("%d", cw.MaxPoints),
@@ -311,7 +594,7 @@ func (m *CourseDetailModel) updateTable() {
 		}
 		for _, s := range m.students {
 			rows = append(rows, table.Row{
-				s.Profile.Name,
+				rosterName(s.UserID, s.Profile.Name),
 				s.Profile.EmailAddress,
 			})
 		}
@@ -323,11 +606,14 @@ func (m *CourseDetailModel) updateTable() {
 		}
 		for _, t := range m.teachers {
 			rows = append(rows, table.Row{
-				t.Profile.Name,
+				rosterName(t.UserID, t.Profile.Name),
 				t.Profile.EmailAddress,
 			})
 		}
 
+	case TabStream:
+		columns, rows = m.streamTable()
+
 	case TabAnnouncements:
 		columns = []table.Column{
 			{Title: "Text", Width: 60},
@@ -340,29 +626,37 @@ func (m *CourseDetailModel) updateTable() {
 			}
 			rows = append(rows, table.Row{
 				preview,
-				a.CreateTime[:10],
+				formatTime(a.CreateTime, time.DateOnly),
 			})
 		}
 	}
 
+	// Clear the old rows so they are never rendered against the new columns
+	m.table.SetRows(nil)
 	m.table.SetColumns(columns)
 	m.table.SetRows(rows)
 }
 
-// prevTab moves to the previous tab.
-func (m *CourseDetailModel) prevTab() {
-	if m.activeTab > 0 {
-		m.activeTab--
-		m.updateTable()
+// prevTab moves to the previous tab, returning the command loading it.
+func (m *CourseDetailModel) prevTab() tea.Cmd {
+	if m.activeTab == 0 {
+		return nil
 	}
+	m.activeTab--
+	m.updateTable()
+	return m.loadTab(m.activeTab)
 }
 
-// nextTab moves to the next tab.
-func (m *CourseDetailModel) nextTab() {
-	if m.activeTab < TabAnnouncements {
-		m.activeTab++
-		m.updateTable()
+// nextTab moves to the next tab, returning the command loading it.
+func (m *CourseDetailModel) nextTab() tea.Cmd {
+	if m.activeTab == TabStream {
+		return nil
 	}
+	m.activeTab++
+	m.updateTable()
+	cmd := m.loadTab(m.activeTab)
+	m.markStreamSeen()
+	return cmd
 }
 
 // handleEnter handles enter key press.
@@ -381,6 +675,27 @@ func (m *CourseDetailModel) handleEnter() tea.Cmd {
 				}
 			}
 		}
+	case TabStream:
+		selected := m.table.Cursor()
+		if selected >= 0 && selected < len(m.stream) {
+			item := m.stream[selected]
+			if item.courseWork != nil {
+				return func() tea.Msg {
+					return CourseWorkDetailMsg{Course: m.course, CourseWork: item.courseWork}
+				}
+			}
+			return func() tea.Msg {
+				return AnnouncementSelectedMsg{Course: m.course, Announcement: item.announcement}
+			}
+		}
+	case TabStudents:
+		selected := m.table.Cursor()
+		if selected >= 0 && selected < len(m.students) {
+			student := m.students[selected]
+			return func() tea.Msg {
+				return StudentSelectedMsg{Course: m.course, Student: student}
+			}
+		}
 	case TabAnnouncements:
 		if len(m.announcements) > 0 {
 			selected := m.table.Cursor()
@@ -398,17 +713,144 @@ func (m *CourseDetailModel) handleEnter() tea.Cmd {
 	return nil
 }
 
-// dataLoadedMsg is sent when data is loaded.
-type dataLoadedMsg struct {
+// selectedLink returns the Classroom link of the highlighted coursework or
+// announcement, or of the course on the roster tabs.
+func (m *CourseDetailModel) selectedLink() string {
+	selected := m.table.Cursor()
+	switch m.activeTab {
+	case TabCoursework:
+		if selected >= 0 && selected < len(m.coursework) {
+			return m.coursework[selected].AlternateLink
+		}
+	case TabAnnouncements:
+		if selected >= 0 && selected < len(m.announcements) {
+			return m.announcements[selected].AlternateLink
+		}
+	case TabStream:
+		if selected >= 0 && selected < len(m.stream) {
+			return m.stream[selected].link()
+		}
+	default:
+		return m.course.AlternateLink
+	}
+	return ""
+}
+
+// folderLink returns the link to the course's Drive folder, or "" when the
+// API didn't return one.
+func (m *CourseDetailModel) folderLink() string {
+	if m.course.TeacherFolder == nil {
+		return ""
+	}
+	return m.course.TeacherFolder.AlternateLink
+}
+
+// deleteSelected queues the highlighted row for deletion behind the undo
+// window. Only teachers can delete.
+func (m *CourseDetailModel) deleteSelected() tea.Cmd {
+	if !m.isTeacher {
+		return nil
+	}
+
+	courseID := m.course.ID
+	i := m.table.Cursor()
+
+	switch m.activeTab {
+	case TabCoursework:
+		if i < 0 || i >= len(m.coursework) {
+			return nil
+		}
+		cw := m.coursework[i]
+		key := func(x *api.CourseWork) string { return x.ID }
+		next := keyAfter(m.coursework, i, key)
+		m.coursework = removeAt(m.coursework, i)
+		m.updateTable()
+		return m.deletions.Queue("coursework:"+cw.ID, fmt.Sprintf("%q", cw.Title),
+			outbox.Entry{Kind: outbox.KindDeleteCourseWork, CourseID: courseID, TargetID: cw.ID, BaseUpdateTime: cw.UpdateTime},
+			func(ctx context.Context) error {
+				return m.apiClient.DeleteCourseWork(ctx, courseID, cw.ID)
+			},
+			func() {
+				m.coursework = restoreItem(m.coursework, cw, next, key)
+				m.updateTable()
+			})
+
+	case TabStudents:
+		if i < 0 || i >= len(m.students) {
+			return nil
+		}
+		s := m.students[i]
+		key := func(x *api.Student) string { return x.UserID }
+		next := keyAfter(m.students, i, key)
+		m.students = removeAt(m.students, i)
+		m.updateTable()
+		return m.deletions.Queue("student:"+s.UserID, "student "+s.Profile.Name,
+			outbox.Entry{Kind: outbox.KindRemoveStudent, CourseID: courseID, TargetID: s.UserID},
+			func(ctx context.Context) error {
+				return m.apiClient.RemoveStudent(ctx, courseID, s.UserID)
+			},
+			func() {
+				m.students = restoreItem(m.students, s, next, key)
+				m.updateTable()
+			})
+
+	case TabTeachers:
+		if i < 0 || i >= len(m.teachers) {
+			return nil
+		}
+		t := m.teachers[i]
+		key := func(x *api.Teacher) string { return x.UserID }
+		next := keyAfter(m.teachers, i, key)
+		m.teachers = removeAt(m.teachers, i)
+		m.updateTable()
+		return m.deletions.Queue("teacher:"+t.UserID, "teacher "+t.Profile.Name,
+			outbox.Entry{Kind: outbox.KindRemoveTeacher, CourseID: courseID, TargetID: t.UserID},
+			func(ctx context.Context) error {
+				return m.apiClient.RemoveTeacher(ctx, courseID, t.UserID)
+			},
+			func() {
+				m.teachers = restoreItem(m.teachers, t, next, key)
+				m.updateTable()
+			})
+
+	case TabAnnouncements:
+		if i < 0 || i >= len(m.announcements) {
+			return nil
+		}
+		a := m.announcements[i]
+		key := func(x *api.Announcement) string { return x.ID }
+		next := keyAfter(m.announcements, i, key)
+		m.announcements = removeAt(m.announcements, i)
+		m.updateTable()
+		return m.deletions.Queue("announcement:"+a.ID, "announcement",
+			outbox.Entry{Kind: outbox.KindDeleteAnnouncement, CourseID: courseID, TargetID: a.ID},
+			func(ctx context.Context) error {
+				return m.apiClient.DeleteAnnouncement(ctx, courseID, a.ID)
+			},
+			func() {
+				m.announcements = restoreItem(m.announcements, a, next, key)
+				m.updateTable()
+			})
+	}
+	return nil
+}
+
+// tabLoadedMsg is sent when a tab's data is loaded. Only the field of tab
+// is set, and none when err is.
+type tabLoadedMsg struct {
+	tab           Tab
+	isTeacher     bool
 	coursework    []*api.CourseWork
 	students      []*api.Student
 	teachers      []*api.Teacher
 	announcements []*api.Announcement
+	err           error
 }
 
-// dataLoadErrorMsg is sent when data fails to load.
-type dataLoadErrorMsg struct {
-	err error
+// roleLoadedMsg is sent when the user's role in the course is known.
+type roleLoadedMsg struct {
+	isTeacher bool
+	err       error
 }
 
 // CourseWorkSelectedMsg is sent when coursework is selected.
@@ -0,0 +1,202 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+)
+
+// courseClient is a fake classroom with course c1, taught by the user,
+// that counts the lists it is asked for and fails those in fail.
+type courseClient struct {
+	*fake.Client
+
+	mu    sync.Mutex
+	calls map[string]int
+	fail  map[string]error
+}
+
+func newCourseClient() *courseClient {
+	c := &courseClient{Client: fake.New("t1"), calls: make(map[string]int), fail: make(map[string]error)}
+	c.AddCourse(&api.Course{ID: "c1", Name: "Biology"})
+	c.AddTeacher(&api.Teacher{CourseID: "c1", UserID: "t1", Profile: api.UserProfile{Name: "Ada Teacher"}})
+	c.AddStudent(&api.Student{CourseID: "c1", UserID: "s1", Profile: api.UserProfile{Name: "Bo Student"}})
+	c.AddCourseWork(&api.CourseWork{CourseID: "c1", Title: "Lab report", State: api.CourseWorkStatePublished})
+	c.AddAnnouncement(&api.Announcement{CourseID: "c1", Text: "Field trip on Friday"})
+	return c
+}
+
+// call counts a request to method and returns its failure, if any.
+func (c *courseClient) call(method string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[method]++
+	return c.fail[method]
+}
+
+// count returns how many times method was requested.
+func (c *courseClient) count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+// setFail makes method fail with err, or succeed when err is nil.
+func (c *courseClient) setFail(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fail[method] = err
+}
+
+func (c *courseClient) ListCourseWork(ctx context.Context, courseID string, opts *api.ListCourseWorkOptions) ([]*api.CourseWork, error) {
+	if err := c.call("ListCourseWork"); err != nil {
+		return nil, err
+	}
+	return c.Client.ListCourseWork(ctx, courseID, opts)
+}
+
+func (c *courseClient) ListStudents(ctx context.Context, courseID string, opts *api.ListRosterOptions) ([]*api.Student, error) {
+	if err := c.call("ListStudents"); err != nil {
+		return nil, err
+	}
+	return c.Client.ListStudents(ctx, courseID, opts)
+}
+
+func (c *courseClient) ListAnnouncements(ctx context.Context, courseID string, opts *api.ListAnnouncementsOptions) ([]*api.Announcement, error) {
+	if err := c.call("ListAnnouncements"); err != nil {
+		return nil, err
+	}
+	return c.Client.ListAnnouncements(ctx, courseID, opts)
+}
+
+// newCourseDetail opens course c1 on tab and delivers what Init loads.
+func newCourseDetail(client *courseClient, tab Tab) *CourseDetailModel {
+	m := NewCourseDetailModel(&api.Course{ID: "c1", Name: "Biology"}, client)
+	m.activeTab = tab
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	deliverTo(m, m.Init())
+	return m
+}
+
+// deliverTo runs cmd and passes its results to m.
+func deliverTo(m tea.Model, cmd tea.Cmd) {
+	for _, msg := range results(cmd) {
+		m.Update(msg)
+	}
+}
+
+// press sends a key to m and delivers what it loads.
+func press(m tea.Model, k string) {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+	switch k {
+	case "right":
+		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	}
+	_, cmd := m.Update(msg)
+	deliverTo(m, cmd)
+}
+
+// TestCourseDetailLoadsTabOnce tests that a tab loads on its first visit
+// and is kept on later ones.
+func TestCourseDetailLoadsTabOnce(t *testing.T) {
+	client := newCourseClient()
+	m := newCourseDetail(client, TabCoursework)
+
+	if n := client.count("ListStudents"); n != 0 {
+		t.Fatalf("Expected the students to wait for their tab, got %d loads", n)
+	}
+
+	press(m, "right")
+	if m.activeTab != TabStudents || client.count("ListStudents") != 1 {
+		t.Fatalf("Expected the students to load on their tab, got %d loads", client.count("ListStudents"))
+	}
+	if len(m.students) != 1 {
+		t.Errorf("Expected 1 student, got %d", len(m.students))
+	}
+
+	press(m, "left")
+	press(m, "right")
+	if n := client.count("ListStudents"); n != 1 {
+		t.Errorf("Expected the students to load once, got %d", n)
+	}
+	if n := client.count("ListCourseWork"); n != 1 {
+		t.Errorf("Expected the coursework to load once, got %d", n)
+	}
+}
+
+// TestCourseDetailRoleWithoutCoursework tests that teacher actions are
+// offered without the coursework loading.
+func TestCourseDetailRoleWithoutCoursework(t *testing.T) {
+	client := newCourseClient()
+	client.setFail("ListCourseWork", errors.New("backend error"))
+
+	for _, tab := range []Tab{TabCoursework, TabStudents} {
+		m := newCourseDetail(client, tab)
+		if !m.isTeacher {
+			t.Errorf("%s: expected the teacher's actions", tab)
+		}
+	}
+}
+
+// TestCourseDetailSectionError tests that a stream section that fails
+// leaves the other on screen, and that r fetches only the failed one.
+func TestCourseDetailSectionError(t *testing.T) {
+	client := newCourseClient()
+	client.setFail("ListCourseWork", errors.New("backend error"))
+	m := newCourseDetail(client, TabStream)
+
+	view := m.View()
+	if !strings.Contains(view, "Field trip on Friday") {
+		t.Errorf("Expected the announcements to show, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Couldn't load coursework") {
+		t.Errorf("Expected the coursework error, got:\n%s", view)
+	}
+	if m.err != nil {
+		t.Errorf("Expected the screen to stay up, got %v", m.err)
+	}
+
+	client.setFail("ListCourseWork", nil)
+	press(m, "r")
+	if n := client.count("ListCourseWork"); n != 2 {
+		t.Errorf("Expected the coursework to load again, got %d loads", n)
+	}
+	if n := client.count("ListAnnouncements"); n != 1 {
+		t.Errorf("Expected the announcements to be kept, got %d loads", n)
+	}
+
+	view = m.View()
+	if !strings.Contains(view, "Lab report") || !strings.Contains(view, "Field trip on Friday") {
+		t.Errorf("Expected both sections, got:\n%s", view)
+	}
+	if strings.Contains(view, "Couldn't load") {
+		t.Errorf("Expected the error to be gone, got:\n%s", view)
+	}
+}
+
+// TestCourseDetailTabError tests that a tab that fails leaves the others
+// working.
+func TestCourseDetailTabError(t *testing.T) {
+	client := newCourseClient()
+	client.setFail("ListStudents", errors.New("backend error"))
+	m := newCourseDetail(client, TabCoursework)
+
+	press(m, "right")
+	if view := m.View(); !strings.Contains(view, "Couldn't load students") {
+		t.Errorf("Expected the students error, got:\n%s", view)
+	}
+
+	press(m, "left")
+	view := m.View()
+	if !strings.Contains(view, "Lab report") || strings.Contains(view, "Couldn't load") {
+		t.Errorf("Expected the coursework without errors, got:\n%s", view)
+	}
+}
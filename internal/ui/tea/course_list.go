@@ -6,37 +6,113 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/collation"
+	"github.com/user/google-classroom/internal/connectivity"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/schedule"
+	"github.com/user/google-classroom/internal/ui/components"
 )
 
 // CourseListModel represents the course list TUI model.
 type CourseListModel struct {
 	list            list.Model
 	spinner         spinner.Model
-	apiClient       *api.Client
+	apiClient       api.ClassroomClient
+	refresh         bool // next load skips the cache
 	courses         []*api.Course
 	filteredCourses []*api.Course
 	searchQuery     string
 	searchInput     textinput.Model
 	loading         bool
 	err             error
+	help            helpOverlay
 	width           int
 	height          int
 	selectedCourse  *api.Course
+
+	invitations        []*api.Invitation
+	invitationCourses  map[string]string
+	invitationCursor   int
+	invitationsFocused bool
+	invitationErr      error
+
+	actionMenu   bool
+	actionCursor int
+	actionErr    error
+	form         *components.Form
+	formAction   courseAction
+
+	view            courseView
+	includeArchived bool
+	offline         bool
+	syncNotice      string
+	link            browserLink
+}
+
+// courseView selects courses by the user's role in them.
+type courseView int
+
+const (
+	viewAllCourses courseView = iota
+	viewTeaching
+	viewEnrolled
+)
+
+func (v courseView) String() string {
+	switch v {
+	case viewTeaching:
+		return "Teaching"
+	case viewEnrolled:
+		return "Enrolled"
+	default:
+		return "All"
+	}
+}
+
+// courseAction is a teacher action available from the course list.
+type courseAction int
+
+const (
+	actionCreateCourse courseAction = iota
+	actionEditCourse
+	actionArchiveCourse
+	actionRestoreCourse
+)
+
+func (a courseAction) String() string {
+	switch a {
+	case actionCreateCourse:
+		return "Create new course"
+	case actionEditCourse:
+		return "Edit name, section, and room"
+	case actionArchiveCourse:
+		return "Archive course"
+	case actionRestoreCourse:
+		return "Restore course"
+	default:
+		return "Unknown"
+	}
 }
 
 // CourseItem represents a course item in the list.
 type CourseItem struct {
 	course *api.Course
+	status schedule.Status
 }
 
 // Title returns the title of the course item.
 func (i CourseItem) Title() string {
+	if i.status.InSession {
+		return "● " + i.course.Name
+	}
 	return i.course.Name
 }
 
@@ -46,7 +122,13 @@ func (i CourseItem) Description() string {
 	if i.course.Section != "" {
 		section = fmt.Sprintf(" | %s", i.course.Section)
 	}
-	return fmt.Sprintf("%s%s", i.course.CourseState, section)
+	meeting := ""
+	if i.status.InSession {
+		meeting = fmt.Sprintf(" | Now until %s", i.status.End.Format("15:04"))
+	} else if !i.status.IsZero() {
+		meeting = fmt.Sprintf(" | Next %s", i.status.Start.Format("Mon 15:04"))
+	}
+	return fmt.Sprintf("%s%s%s", i.course.CourseState, section, meeting)
 }
 
 // FilterValue returns the filter value for the course item.
@@ -55,7 +137,7 @@ func (i CourseItem) FilterValue() string {
 }
 
 // NewCourseListModel creates a new course list model.
-func NewCourseListModel(apiClient *api.Client) *CourseListModel {
+func NewCourseListModel(apiClient api.ClassroomClient) *CourseListModel {
 	// Create spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
@@ -66,10 +148,11 @@ func NewCourseListModel(apiClient *api.Client) *CourseListModel {
 	ti.Placeholder = "Search courses..."
 	ti.Prompt = "/"
 	ti.Width = 30
-	ti.Focus()
 
 	// Create list
 	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	setListKeys(&l)
+	l.Filter = foldFilter
 	l.Title = "Your Courses"
 	l.Styles.Title = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#ff79c6")).
@@ -82,7 +165,7 @@ func NewCourseListModel(apiClient *api.Client) *CourseListModel {
 	return &CourseListModel{
 		list:        l,
 		spinner:     s,
-		apiClient:   apiClient,
+		apiClient:   cache.NewCachedClient(apiClient, options.Cache),
 		searchInput: ti,
 		loading:     true,
 	}
@@ -90,30 +173,100 @@ func NewCourseListModel(apiClient *api.Client) *CourseListModel {
 
 // Init initializes the model.
 func (m *CourseListModel) Init() tea.Cmd {
-	return m.loadCourses()
+	cmds := []tea.Cmd{m.loadCourses(), m.loadInvitations(), loadUser(m.apiClient), watchConnectivity(), watchReauth(), syncOutbox(m.apiClient)}
+	if len(options.Schedule) > 0 {
+		cmds = append(cmds, scheduleTick())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages.
 func (m *CourseListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.form != nil {
+			return m, m.handleFormKey(msg)
+		}
+		if m.actionMenu {
+			return m, m.handleActionKey(msg)
+		}
+		if m.invitationsFocused {
+			return m, m.handleInvitationKey(msg)
+		}
+		if m.searchInput.Focused() {
+			return m, m.handleSearchKey(msg)
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit)):
 			return m, tea.Quit
-		case "/":
+		case key.Matches(msg, bound(keymap.Search)):
 			m.searchInput.Focus()
 			return m, textinput.Blink
-		case "enter":
+		case key.Matches(msg, bound(keymap.Open)):
+			if i := m.list.SelectedItem(); i != nil {
+				if item, ok := i.(CourseItem); ok {
+					return m, m.link.open(item.course.AlternateLink)
+				}
+			}
+		case key.Matches(msg, bound(keymap.Select)):
 			if i := m.list.SelectedItem(); i != nil {
 				if item, ok := i.(CourseItem); ok {
 					m.selectedCourse = item.course
 					return m, func() tea.Msg { return CourseSelectedMsg{Course: item.course} }
 				}
 			}
-		case "r":
+		case key.Matches(msg, bound(keymap.Refresh)):
+			if isOffline() && len(m.courses) > 0 {
+				return m, nil
+			}
+			m.refresh = true
 			m.loading = true
 			m.err = nil
+			return m, tea.Batch(m.loadCourses(), m.loadInvitations())
+		case key.Matches(msg, bound(keymap.CourseActions)):
+			m.actionMenu = true
+			m.actionCursor = 0
+			m.actionErr = nil
+			return m, nil
+		case key.Matches(msg, bound(keymap.Invitations)):
+			if len(m.invitations) > 0 {
+				m.invitationsFocused = true
+				return m, nil
+			}
+		case key.Matches(msg, bound(keymap.View)):
+			m.view = (m.view + 1) % 3
+			m.updateTitle()
+			m.loading = true
+			return m, m.loadCourses()
+		case key.Matches(msg, bound(keymap.AllCourses)):
+			return m, func() tea.Msg { return OpenGlobalSearchMsg{} }
+		case key.Matches(msg, bound(keymap.Account)):
+			return m, func() tea.Msg { return OpenAuthStatusMsg{} }
+		case key.Matches(msg, bound(keymap.Cache)):
+			names := make(map[string]string, len(m.courses))
+			for _, course := range m.courses {
+				names[course.ID] = course.Name
+			}
+			return m, func() tea.Msg { return OpenCacheMsg{CourseNames: names} }
+		case key.Matches(msg, bound(keymap.Agenda)):
+			courses := m.courses
+			return m, func() tea.Msg { return OpenAgendaMsg{Courses: courses} }
+		case key.Matches(msg, bound(keymap.Archived)):
+			m.includeArchived = !m.includeArchived
+			m.updateTitle()
+			m.loading = true
 			return m, m.loadCourses()
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
 		}
 
 	case spinner.TickMsg:
@@ -121,6 +274,29 @@ func (m *CourseListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		// The login may have been to another account
+		options.User = nil
+		return m, tea.Batch(m.loadCourses(), m.loadInvitations(), loadUser(m.apiClient), afterRecovery(msg))
+
+	case userLoadedMsg:
+		options.User = msg.user
+		return m, nil
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -133,22 +309,84 @@ func (m *CourseListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loading = false
 		m.err = nil
 		m.updateList()
+		return m, prefetchCourses(m.apiClient)
+
+	case prefetchDoneMsg:
+		if notice := renderPrefetchResult(msg); notice != "" && m.syncNotice == "" {
+			m.syncNotice = notice
+		}
 		return m, nil
 
+	case scheduleTickMsg:
+		sortBySchedule(m.courses, time.Now())
+		m.handleSearch()
+		return m, scheduleTick()
+
 	case coursesLoadErrorMsg:
 		m.loading = false
+		reportError(msg.err)
+		if isOffline() && len(m.courses) > 0 {
+			return m, nil
+		}
 		m.err = msg.err
 		return m, nil
+
+	case connectivityMsg:
+		wasOffline := m.offline
+		m.offline = msg.state == connectivity.Offline
+		if wasOffline && !m.offline {
+			m.err = nil
+			return m, tea.Batch(watchConnectivity(), syncOutbox(m.apiClient), m.loadCourses(), m.loadInvitations())
+		}
+		return m, watchConnectivity()
+
+	case outboxSyncedMsg:
+		m.syncNotice = renderSyncResult(msg)
+		return m, nil
+
+	case invitationsLoadedMsg:
+		m.invitations = msg.invitations
+		m.invitationCourses = msg.courseNames
+		m.invitationErr = nil
+		if m.invitationCursor >= len(m.invitations) {
+			m.invitationCursor = 0
+		}
+		if len(m.invitations) == 0 {
+			m.invitationsFocused = false
+		}
+		return m, nil
+
+	case invitationsLoadErrorMsg:
+		m.invitationErr = msg.err
+		return m, nil
+
+	case courseUpdatedMsg:
+		m.loading = true
+		return m, m.loadCourses()
+
+	case courseActionErrorMsg:
+		if sessionExpired(msg.err) {
+			m.err = msg.err
+			return m, nil
+		}
+		m.actionErr = msg.err
+		return m, nil
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case invitationRespondedMsg:
+		if msg.accepted {
+			m.loading = true
+			return m, tea.Batch(m.loadCourses(), m.loadInvitations())
+		}
+		return m, m.loadInvitations()
 	}
 
 	// Update search input if focused
 	if m.searchInput.Focused() {
-		var cmd tea.Cmd
-		m.searchInput, cmd = m.searchInput.Update(msg)
-		if cmd != nil {
-			go m.handleSearch()
-		}
-		return m, cmd
+		return m, m.updateSearch(msg)
 	}
 
 	// Update list
@@ -157,6 +395,38 @@ func (m *CourseListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleSearchKey handles a key while the search box has focus. Every key
+// is typed into it, except enter, which keeps the filter and hands the keys
+// back to the list, and esc, which also clears it.
+func (m *CourseListModel) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc":
+		m.searchInput.SetValue("")
+		m.searchInput.Blur()
+		m.handleSearch()
+		return nil
+	case "enter":
+		m.searchInput.Blur()
+		return nil
+	}
+	return m.updateSearch(msg)
+}
+
+// updateSearch passes msg to the search box and filters the list when
+// the query changed. Filtering runs here rather than in a goroutine, as
+// it reads m.courses, which Update sorts in place.
+func (m *CourseListModel) updateSearch(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	query := m.searchInput.Value()
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != query {
+		m.handleSearch()
+	}
+	return cmd
+}
+
 // View renders the model.
 func (m *CourseListModel) View() string {
 	if m.loading {
@@ -176,26 +446,7 @@ func (m *CourseListModel) View() string {
 	}
 
 	if m.err != nil {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center).
-			Render(
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#ff5555")).
-						Bold(true).
-						Render("Error loading courses"),
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#f8f8f2")).
-						Render(m.err.Error()),
-					"",
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#6272a4")).
-						Render("Press 'r' to retry"),
-				),
-			)
+		return renderErrorView("Error loading courses", m.err, m.width, m.height)
 	}
 
 	// Render search input
@@ -205,61 +456,444 @@ func (m *CourseListModel) View() string {
 	} else {
 		searchView = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#6272a4")).
-			Render("/ to search")
+			Render(actionKey(keymap.Search, "search").Help().Key + " to search")
 	}
 
 	// Render list
 	listView := m.list.View()
 
 	// Render footer
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272a4")).
-		Render("↑↓ navigate | enter select | / search | r refresh | q quit")
+	var keys []key.Binding
+	switch {
+	case m.actionMenu:
+		keys = []key.Binding{navigateKey(), actionKey(keymap.Select, "run"), actionKey(keymap.Back, "cancel")}
+	case m.invitationsFocused:
+		keys = []key.Binding{navigateKey(), actionKey(keymap.Accept, "accept"), actionKey(keymap.Decline, "decline"), backKey()}
+	case m.searchInput.Focused():
+		keys = []key.Binding{bind("enter", "keep filter"), bind("esc", "clear")}
+	default:
+		keys = []key.Binding{navigateKey(), actionKey(keymap.Select, "select"), actionKey(keymap.Search, "search"), actionKey(keymap.AllCourses, "all courses"),
+			actionKey(keymap.View, "view"), actionKey(keymap.Agenda, "agenda"), actionKey(keymap.Archived, "archived"), actionKey(keymap.CourseActions, "course actions")}
+		if len(m.invitations) > 0 {
+			keys = append(keys, actionKey(keymap.Invitations, "invitations"))
+		}
+		keys = append(keys, actionKey(keymap.Open, "open"), actionKey(keymap.Account, "account"), actionKey(keymap.Cache, "cache"), refreshKey(), quitKey())
+	}
+	footer := m.help.render(m.width, keys...)
+
+	if m.form != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, m.form.View())
+	}
+
+	sections := []string{searchView, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	} else if m.syncNotice != "" {
+		sections = append(sections, m.syncNotice, "")
+	}
+	if badge := budgetBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	if panel := m.renderInvitations(); panel != "" {
+		sections = append(sections, panel, "")
+	}
+	if m.actionMenu {
+		sections = append(sections, m.renderActionMenu(), "")
+	} else if m.actionErr != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.actionErr)), "")
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status, "")
+	}
+	sections = append(sections, listView, "")
+	if bar := statusBar(nil, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// availableActions returns the actions that apply to the highlighted course.
+// Editing, archiving and restoring are only offered for courses the user
+// teaches.
+func (m *CourseListModel) availableActions() []courseAction {
+	actions := []courseAction{actionCreateCourse}
+	if course := m.highlightedCourse(); course != nil && m.teaches(course) {
+		actions = append(actions, actionEditCourse)
+		switch course.CourseState {
+		case api.CourseStateActive:
+			actions = append(actions, actionArchiveCourse)
+		case api.CourseStateArchived:
+			actions = append(actions, actionRestoreCourse)
+		}
+	}
+	return actions
+}
 
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		searchView,
-		"",
-		listView,
-		"",
-		footer,
-	)
+// teaches reports whether the user teaches course: they own it, or the
+// teaching view lists it.
+func (m *CourseListModel) teaches(course *api.Course) bool {
+	return isMe(course.OwnerID) || m.view == viewTeaching
+}
+
+// highlightedCourse returns the course under the list cursor.
+func (m *CourseListModel) highlightedCourse() *api.Course {
+	if i := m.list.SelectedItem(); i != nil {
+		if item, ok := i.(CourseItem); ok {
+			return item.course
+		}
+	}
+	return nil
+}
+
+// renderActionMenu renders the course actions menu.
+func (m *CourseListModel) renderActionMenu() string {
+	title := "Course actions"
+	if course := m.highlightedCourse(); course != nil {
+		title += ": " + course.Name
+	}
+	lines := []string{
+		lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#bd93f9")).
+			Bold(true).
+			Render(title),
+	}
+
+	for i, action := range m.availableActions() {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+		prefix := "  "
+		if i == m.actionCursor {
+			style = style.Foreground(lipgloss.Color("#ff79c6")).Bold(true)
+			prefix = "▶ "
+		}
+		lines = append(lines, style.Render(prefix+action.String()))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6272a4")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// handleActionKey handles key presses while the course actions menu is open.
+func (m *CourseListModel) handleActionKey(msg tea.KeyMsg) tea.Cmd {
+	actions := m.availableActions()
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, bound(keymap.Up)):
+		if m.actionCursor > 0 {
+			m.actionCursor--
+		}
+	case key.Matches(msg, bound(keymap.Down)):
+		if m.actionCursor < len(actions)-1 {
+			m.actionCursor++
+		}
+	case key.Matches(msg, bound(keymap.Select)):
+		if m.actionCursor < len(actions) {
+			m.actionMenu = false
+			return m.runAction(actions[m.actionCursor])
+		}
+	case key.Matches(msg, bound(keymap.Back), bound(keymap.CourseActions)):
+		m.actionMenu = false
+	}
+	return nil
+}
+
+// runAction starts the given course action.
+func (m *CourseListModel) runAction(action courseAction) tea.Cmd {
+	course := m.highlightedCourse()
+
+	switch action {
+	case actionCreateCourse:
+		m.form = components.NewForm("Create course", "Name", "Section", "Room")
+		m.formAction = action
+		return textinput.Blink
+	case actionEditCourse:
+		if course == nil {
+			return nil
+		}
+		m.form = components.NewForm("Edit "+course.Name, "Name", "Section", "Room")
+		m.form.SetValue(0, course.Name)
+		m.form.SetValue(1, course.Section)
+		m.form.SetValue(2, course.Room)
+		m.formAction = action
+		return textinput.Blink
+	case actionArchiveCourse:
+		if course != nil {
+			return m.setCourseState(course.ID, api.CourseStateArchived)
+		}
+	case actionRestoreCourse:
+		if course != nil {
+			return m.setCourseState(course.ID, api.CourseStateActive)
+		}
+	}
+	return nil
+}
+
+// handleFormKey handles key presses while the create/edit form is open.
+func (m *CourseListModel) handleFormKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+
+	cmd := m.form.Update(msg)
+	if m.form.Cancelled() {
+		m.form = nil
+		return nil
+	}
+	if !m.form.Submitted() {
+		return cmd
+	}
+
+	name, section, room := m.form.Value(0), m.form.Value(1), m.form.Value(2)
+	action := m.formAction
+	m.form = nil
+	if name == "" {
+		m.actionErr = fmt.Errorf("course name is required")
+		return nil
+	}
+
+	switch action {
+	case actionCreateCourse:
+		return m.createCourse(name, section, room)
+	case actionEditCourse:
+		if course := m.highlightedCourse(); course != nil {
+			return m.patchCourse(course.ID, api.CoursePatch{Name: &name, Section: &section, Room: &room})
+		}
+	}
+	return nil
+}
+
+// renderInvitations renders the pending invitations panel.
+func (m *CourseListModel) renderInvitations() string {
+	if len(m.invitations) == 0 && m.invitationErr == nil {
+		return ""
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f1fa8c")).
+			Bold(true).
+			Render(fmt.Sprintf("Pending invitations (%d)", len(m.invitations))),
+	}
+
+	for i, inv := range m.invitations {
+		name := m.invitationCourses[inv.CourseID]
+		if name == "" {
+			name = inv.CourseID
+		}
+		line := fmt.Sprintf("%s as %s", name, strings.ToLower(inv.Role))
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+		prefix := "  "
+		if m.invitationsFocused && i == m.invitationCursor {
+			style = style.Foreground(lipgloss.Color("#ff79c6")).Bold(true)
+			prefix = "▶ "
+		}
+		lines = append(lines, style.Render(prefix+line))
+	}
+
+	if m.invitationErr != nil {
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.invitationErr)))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6272a4")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// handleInvitationKey handles key presses while the invitations panel is focused.
+func (m *CourseListModel) handleInvitationKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, bound(keymap.Up)):
+		if m.invitationCursor > 0 {
+			m.invitationCursor--
+		}
+	case key.Matches(msg, bound(keymap.Down)):
+		if m.invitationCursor < len(m.invitations)-1 {
+			m.invitationCursor++
+		}
+	case key.Matches(msg, bound(keymap.Select), bound(keymap.Accept)):
+		if inv := m.selectedInvitation(); inv != nil {
+			return m.respondToInvitation(inv, true)
+		}
+	case key.Matches(msg, bound(keymap.Back), bound(keymap.Invitations)):
+		m.invitationsFocused = false
+	case key.Matches(msg, bound(keymap.Decline)):
+		if inv := m.selectedInvitation(); inv != nil {
+			return m.respondToInvitation(inv, false)
+		}
+	}
+	return nil
+}
+
+// selectedInvitation returns the invitation under the cursor.
+func (m *CourseListModel) selectedInvitation() *api.Invitation {
+	if m.invitationCursor >= 0 && m.invitationCursor < len(m.invitations) {
+		return m.invitations[m.invitationCursor]
+	}
+	return nil
 }
 
 // loadCourses loads courses from the API.
 func (m *CourseListModel) loadCourses() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := loadContext(refresh)
 		defer cancel()
 
-		courses, err := m.apiClient.ListCourses(ctx)
+		courses, err := m.apiClient.ListCourses(ctx, m.listOptions())
 		if err != nil {
 			return coursesLoadErrorMsg{err: err}
 		}
+		collation.Sort(courses, func(c *api.Course) string { return c.Name })
+		sortBySchedule(courses, time.Now())
 		return coursesLoadedMsg{courses: courses}
 	}
 }
 
+// listOptions builds the course query for the current view and archive toggle.
+func (m *CourseListModel) listOptions() *api.ListCoursesOptions {
+	opts := &api.ListCoursesOptions{
+		CourseStates: []string{api.CourseStateActive, api.CourseStateProvisioned},
+	}
+	if m.includeArchived {
+		opts.CourseStates = append(opts.CourseStates, api.CourseStateArchived)
+	}
+	switch m.view {
+	case viewTeaching:
+		opts.TeacherID = "me"
+	case viewEnrolled:
+		opts.StudentID = "me"
+	}
+	return opts
+}
+
+// updateTitle shows the current view and archive toggle in the list title.
+func (m *CourseListModel) updateTitle() {
+	title := "Your Courses"
+	if m.view != viewAllCourses {
+		title += " - " + m.view.String()
+	}
+	if m.includeArchived {
+		title += " (incl. archived)"
+	}
+	m.list.Title = title
+}
+
+// loadInvitations loads pending invitations and the names of their courses.
+func (m *CourseListModel) loadInvitations() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		invitations, err := m.apiClient.ListInvitations(ctx)
+		if err != nil {
+			return invitationsLoadErrorMsg{err: err}
+		}
+
+		// Course names are best effort; the panel falls back to the course ID
+		courseNames := make(map[string]string)
+		for _, inv := range invitations {
+			if course, err := m.apiClient.GetCourse(ctx, inv.CourseID); err == nil {
+				courseNames[inv.CourseID] = course.Name
+			}
+		}
+
+		return invitationsLoadedMsg{invitations: invitations, courseNames: courseNames}
+	}
+}
+
+// createCourse creates a new course.
+func (m *CourseListModel) createCourse(name, section, room string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if _, err := m.apiClient.CreateCourse(ctx, name, section, room); err != nil {
+			return courseActionErrorMsg{err: err}
+		}
+		return courseUpdatedMsg{}
+	}
+}
+
+// patchCourse updates a course's details.
+func (m *CourseListModel) patchCourse(courseID string, patch api.CoursePatch) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if _, err := m.apiClient.PatchCourse(ctx, courseID, patch); err != nil {
+			return courseActionErrorMsg{err: err}
+		}
+		return courseUpdatedMsg{}
+	}
+}
+
+// setCourseState archives or restores a course.
+func (m *CourseListModel) setCourseState(courseID, state string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if _, err := m.apiClient.UpdateCourseState(ctx, courseID, state); err != nil {
+			return courseActionErrorMsg{err: err}
+		}
+		return courseUpdatedMsg{}
+	}
+}
+
+// respondToInvitation accepts or declines an invitation.
+func (m *CourseListModel) respondToInvitation(inv *api.Invitation, accept bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var err error
+		if accept {
+			err = m.apiClient.AcceptInvitation(ctx, inv.ID)
+		} else {
+			err = m.apiClient.DeleteInvitation(ctx, inv.ID)
+		}
+		if err != nil {
+			return invitationsLoadErrorMsg{err: err}
+		}
+		return invitationRespondedMsg{accepted: accept}
+	}
+}
+
 // updateList updates the list with filtered courses.
 func (m *CourseListModel) updateList() {
+	now := time.Now()
 	items := make([]list.Item, len(m.filteredCourses))
 	for i, course := range m.filteredCourses {
-		items[i] = CourseItem{course: course}
+		items[i] = CourseItem{course: course, status: courseStatus(course, now)}
 	}
 	m.list.SetItems(items)
 }
 
 // handleSearch handles search input changes.
 func (m *CourseListModel) handleSearch() {
-	query := strings.ToLower(m.searchInput.Value())
+	query := strings.TrimSpace(m.searchInput.Value())
 
 	if query == "" {
 		m.filteredCourses = m.courses
 	} else {
 		m.filteredCourses = make([]*api.Course, 0)
 		for _, course := range m.courses {
-			if strings.Contains(strings.ToLower(course.Name), query) ||
-				strings.Contains(strings.ToLower(course.Section), query) {
+			if collation.Contains(course.Name, query) ||
+				collation.Contains(course.Section, query) {
 				m.filteredCourses = append(m.filteredCourses, course)
 			}
 		}
@@ -268,11 +902,37 @@ func (m *CourseListModel) handleSearch() {
 	m.updateList()
 }
 
+// courseStatus returns where a course is in its configured schedule.
+func courseStatus(course *api.Course, now time.Time) schedule.Status {
+	return schedule.StatusAt(options.Schedule.Meetings(course.ID, course.Name), now)
+}
+
+// sortBySchedule puts the class in session first, then upcoming classes.
+// Courses without a schedule keep their order at the end.
+func sortBySchedule(courses []*api.Course, now time.Time) {
+	if len(options.Schedule) == 0 {
+		return
+	}
+	schedule.SortBy(courses, now, func(c *api.Course) []schedule.Meeting {
+		return options.Schedule.Meetings(c.ID, c.Name)
+	})
+}
+
+// scheduleTick refreshes the schedule highlight every minute.
+func scheduleTick() tea.Cmd {
+	return tea.Tick(time.Minute, func(time.Time) tea.Msg {
+		return scheduleTickMsg{}
+	})
+}
+
 // SelectedCourse returns the currently selected course.
 func (m *CourseListModel) SelectedCourse() *api.Course {
 	return m.selectedCourse
 }
 
+// scheduleTickMsg is sent to refresh the current class highlight.
+type scheduleTickMsg struct{}
+
 // coursesLoadedMsg is sent when courses are loaded.
 type coursesLoadedMsg struct {
 	courses []*api.Course
@@ -283,6 +943,30 @@ type coursesLoadErrorMsg struct {
 	err error
 }
 
+// invitationsLoadedMsg is sent when pending invitations are loaded.
+type invitationsLoadedMsg struct {
+	invitations []*api.Invitation
+	courseNames map[string]string
+}
+
+// invitationsLoadErrorMsg is sent when invitations fail to load or update.
+type invitationsLoadErrorMsg struct {
+	err error
+}
+
+// invitationRespondedMsg is sent when an invitation is accepted or declined.
+type invitationRespondedMsg struct {
+	accepted bool
+}
+
+// courseUpdatedMsg is sent when a course is created or changed.
+type courseUpdatedMsg struct{}
+
+// courseActionErrorMsg is sent when a course action fails.
+type courseActionErrorMsg struct {
+	err error
+}
+
 // CourseSelectedMsg is sent when a course is selected.
 type CourseSelectedMsg struct {
 	Course *api.Course
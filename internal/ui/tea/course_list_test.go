@@ -0,0 +1,258 @@
+package tea
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// typeText sends each rune of text to m as a key press.
+func typeText(m tea.Model, text string) {
+	for _, r := range text {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// immediate returns the messages cmd produces without waiting, leaving out
+// timers such as the cursor blink.
+func immediate(cmd tea.Cmd) []tea.Msg {
+	done := make(chan []tea.Msg, 1)
+	go func() { done <- results(cmd) }()
+	select {
+	case msgs := <-done:
+		return msgs
+	case <-time.After(50 * time.Millisecond):
+		return nil
+	}
+}
+
+// newSearchingCourseList returns a course list with the search box opened.
+func newSearchingCourseList() *CourseListModel {
+	m := NewCourseListModel(fake.New("t1"))
+	m.loading = false
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	return m
+}
+
+// TestCourseListSearchTakesLetters tests that letters bound to actions are
+// typed into the search box while it has focus.
+func TestCourseListSearchTakesLetters(t *testing.T) {
+	m := NewCourseListModel(fake.New("t1"))
+	if m.searchInput.Focused() {
+		t.Fatal("Expected the search box to wait for /")
+	}
+
+	m = newSearchingCourseList()
+	typeText(m, "calc")
+	if got := m.searchInput.Value(); got != "calc" {
+		t.Errorf("Expected the search to read calc, got %q", got)
+	}
+	if m.actionMenu {
+		t.Error("Expected c not to open the course actions")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searchInput.Focused() || m.searchInput.Value() != "calc" {
+		t.Errorf("Expected enter to keep the filter and leave the box, got %q", m.searchInput.Value())
+	}
+	typeText(m, "c")
+	if !m.actionMenu {
+		t.Error("Expected c to open the course actions once the search is left")
+	}
+}
+
+// TestCourseListSearchFilters tests that typing filters the list before
+// Update returns, so a schedule tick sorting the courses cannot race it.
+func TestCourseListSearchFilters(t *testing.T) {
+	m := newSearchingCourseList()
+	m.courses = []*api.Course{{ID: "1", Name: "Calculus"}, {ID: "2", Name: "History"}}
+	m.handleSearch()
+
+	typeText(m, "calc")
+	if len(m.filteredCourses) != 1 || m.filteredCourses[0].ID != "1" {
+		t.Errorf("Expected only Calculus, got %d courses", len(m.filteredCourses))
+	}
+	m.Update(scheduleTickMsg{})
+	if len(m.filteredCourses) != 1 {
+		t.Errorf("Expected the filter to survive a schedule tick, got %d courses", len(m.filteredCourses))
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(m.filteredCourses) != 2 {
+		t.Errorf("Expected esc to clear the filter, got %d courses", len(m.filteredCourses))
+	}
+}
+
+// TestCourseListSearchKeepsKeys tests that letters which act on the list
+// or open another screen only type while searching. The key reaching the
+// search box means it was not handled as a shortcut.
+func TestCourseListSearchKeepsKeys(t *testing.T) {
+	tests := []struct {
+		key     string
+		changed func(m *CourseListModel) bool
+	}{
+		{"v", func(m *CourseListModel) bool { return m.view != 0 || m.loading }},
+		{"A", func(m *CourseListModel) bool { return m.includeArchived || m.loading }},
+		{"a", nil},
+		{"o", func(m *CourseListModel) bool { return m.link.err != nil }},
+		{"S", nil},
+		{"K", nil},
+		{"i", func(m *CourseListModel) bool { return m.invitationsFocused }},
+	}
+
+	for _, tt := range tests {
+		m := newSearchingCourseList()
+		// A course without a link and an invitation, for o and i to act on
+		m.courses = []*api.Course{{ID: "c1", Name: "Biology"}}
+		m.handleSearch()
+		m.invitations = []*api.Invitation{{ID: "inv1", CourseID: "c2"}}
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+		if got := m.searchInput.Value(); got != tt.key {
+			t.Errorf("%s: expected it typed into the search, got %q", tt.key, got)
+		}
+		if tt.changed != nil && tt.changed(m) {
+			t.Errorf("%s: expected the list to be left alone", tt.key)
+		}
+		for _, msg := range immediate(cmd) {
+			switch msg.(type) {
+			case OpenAgendaMsg, OpenAuthStatusMsg, OpenCacheMsg, linkOpenedMsg:
+				t.Errorf("%s: expected no screen to open, got %T", tt.key, msg)
+			}
+		}
+	}
+}
+
+// TestCourseListReboundKeys tests that a rebound action runs from its new
+// key and no longer from the default.
+func TestCourseListReboundKeys(t *testing.T) {
+	keys, err := keymap.New(map[string][]string{"search": {"ctrl+k"}, "refresh": {"f5"}})
+	if err != nil {
+		t.Fatalf("Failed to build key map: %v", err)
+	}
+	SetOptions(Options{Keys: keys})
+	t.Cleanup(func() { SetOptions(Options{}) })
+
+	m := NewCourseListModel(fake.New("t1"))
+	m.loading = false
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if m.searchInput.Focused() {
+		t.Error("Expected / to do nothing once search is rebound")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if !m.searchInput.Focused() {
+		t.Error("Expected ctrl+k to open the search")
+	}
+
+	m = NewCourseListModel(fake.New("t1"))
+	m.loading = false
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if m.loading {
+		t.Error("Expected r to do nothing once refresh is rebound")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if !m.loading {
+		t.Error("Expected f5 to refresh")
+	}
+}
+
+// TestCourseListActions tests that only courses the user teaches can be
+// edited, archived or restored.
+func TestCourseListActions(t *testing.T) {
+	user := options.User
+	options.User = &api.UserProfile{ID: "t1"}
+	t.Cleanup(func() { options.User = user })
+
+	tests := []struct {
+		name   string
+		course *api.Course
+		view   courseView
+		want   []courseAction
+	}{
+		{"owned and active", &api.Course{OwnerID: "t1", CourseState: api.CourseStateActive}, viewAllCourses,
+			[]courseAction{actionCreateCourse, actionEditCourse, actionArchiveCourse}},
+		{"owned and archived", &api.Course{OwnerID: "t1", CourseState: api.CourseStateArchived}, viewAllCourses,
+			[]courseAction{actionCreateCourse, actionEditCourse, actionRestoreCourse}},
+		{"owned and provisioned", &api.Course{OwnerID: "t1", CourseState: api.CourseStateProvisioned}, viewAllCourses,
+			[]courseAction{actionCreateCourse, actionEditCourse}},
+		{"enrolled", &api.Course{OwnerID: "t2", CourseState: api.CourseStateActive}, viewAllCourses,
+			[]courseAction{actionCreateCourse}},
+		{"co-teaching", &api.Course{OwnerID: "t2", CourseState: api.CourseStateActive}, viewTeaching,
+			[]courseAction{actionCreateCourse, actionEditCourse, actionArchiveCourse}},
+		{"no course", nil, viewAllCourses, []courseAction{actionCreateCourse}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewCourseListModel(fake.New("t1"))
+			m.loading = false
+			m.view = tt.view
+			if tt.course != nil {
+				tt.course.ID, tt.course.Name = "c1", "Biology"
+				m.courses = []*api.Course{tt.course}
+			}
+			m.handleSearch()
+			if got := m.availableActions(); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// listingClient is a fake classroom that records the options of each
+// course list.
+type listingClient struct {
+	*fake.Client
+	opts []*api.ListCoursesOptions
+}
+
+func (c *listingClient) ListCourses(ctx context.Context, opts *api.ListCoursesOptions) ([]*api.Course, error) {
+	c.opts = append(c.opts, opts)
+	return c.Client.ListCourses(ctx, opts)
+}
+
+// TestCourseListViews tests the courses asked for, and the title shown,
+// in each view with and without archived courses.
+func TestCourseListViews(t *testing.T) {
+	current := []string{api.CourseStateActive, api.CourseStateProvisioned}
+	withArchived := []string{api.CourseStateActive, api.CourseStateProvisioned, api.CourseStateArchived}
+	tests := []struct {
+		keys      string
+		want      api.ListCoursesOptions
+		wantTitle string
+	}{
+		{"v", api.ListCoursesOptions{CourseStates: current, TeacherID: "me"}, "Your Courses - Teaching"},
+		{"vv", api.ListCoursesOptions{CourseStates: current, StudentID: "me"}, "Your Courses - Enrolled"},
+		{"vvv", api.ListCoursesOptions{CourseStates: current}, "Your Courses"},
+		{"A", api.ListCoursesOptions{CourseStates: withArchived}, "Your Courses (incl. archived)"},
+		{"vA", api.ListCoursesOptions{CourseStates: withArchived, TeacherID: "me"}, "Your Courses - Teaching (incl. archived)"},
+		{"AA", api.ListCoursesOptions{CourseStates: current}, "Your Courses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			client := &listingClient{Client: fake.New("t1")}
+			m := NewCourseListModel(client)
+			m.loading = false
+			for _, k := range tt.keys {
+				_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{k}})
+				immediate(cmd)
+			}
+
+			if len(client.opts) != len(tt.keys) {
+				t.Fatalf("Expected a load for each key, got %d", len(client.opts))
+			}
+			got := client.opts[len(client.opts)-1]
+			if !slices.Equal(got.CourseStates, tt.want.CourseStates) || got.TeacherID != tt.want.TeacherID || got.StudentID != tt.want.StudentID {
+				t.Errorf("Expected %+v, got %+v", tt.want, *got)
+			}
+			if m.list.Title != tt.wantTitle {
+				t.Errorf("Expected title %q, got %q", tt.wantTitle, m.list.Title)
+			}
+		})
+	}
+}
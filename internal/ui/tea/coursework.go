@@ -3,13 +3,17 @@ package tea
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
 )
 
 // Filter type for coursework
@@ -20,6 +24,11 @@ const (
 	FilterAssignments
 	FilterMaterials
 	FilterAnnouncements
+	// FilterScheduled shows drafts, scheduled or not, for teachers to
+	// review before they are published.
+	FilterScheduled
+	// FilterMine hides posts assigned to other students only.
+	FilterMine
 )
 
 func (f CourseworkFilter) String() string {
@@ -32,6 +41,10 @@ func (f CourseworkFilter) String() string {
 		return "Materials"
 	case FilterAnnouncements:
 		return "Announcements"
+	case FilterScheduled:
+		return "Scheduled"
+	case FilterMine:
+		return "Assigned to me"
 	default:
 		return "Unknown"
 	}
@@ -45,7 +58,7 @@ type CourseworkItem struct {
 
 // Title returns the title of the coursework item.
 func (i CourseworkItem) Title() string {
-	return i.coursework.Title
+	return stateBadge(i.coursework) + i.coursework.Title
 }
 
 // Description returns the description of the coursework item.
@@ -60,6 +73,18 @@ func (i CourseworkItem) Description() string {
 		}
 		status += fmt.Sprintf("%d pts", i.coursework.MaxPoints)
 	}
+	if badge := checklistBadge(i.coursework.CourseID, i.coursework.ID); badge != "" {
+		if status != "" {
+			status += " | "
+		}
+		status += badge
+	}
+	if badge := assigneeBadge(i.coursework.AssigneeMode, i.coursework.StudentIDs); badge != "" {
+		if status != "" {
+			status += " | "
+		}
+		status += badge
+	}
 	return status
 }
 
@@ -71,7 +96,8 @@ func (i CourseworkItem) FilterValue() string {
 // CourseworkModel represents the coursework TUI model.
 type CourseworkModel struct {
 	course     *api.Course
-	apiClient  *api.Client
+	apiClient  api.ClassroomClient
+	refresh    bool // next load skips the cache
 	coursework []*api.CourseWork
 	filteredCW []*api.CourseWork
 	filter     CourseworkFilter
@@ -79,13 +105,39 @@ type CourseworkModel struct {
 	spinner    spinner.Model
 	loading    bool
 	err        error
+	help       helpOverlay
 	width      int
 	height     int
 	selectedCW *api.CourseWork
+	isTeacher  bool
+	order      string
+	download   download
+	link       browserLink
+}
+
+// courseworkOrders are the sort orders the coursework list cycles through.
+var courseworkOrders = []string{
+	api.CourseWorkOrderDueDateAsc,
+	api.CourseWorkOrderDueDateDesc,
+	api.CourseWorkOrderUpdateTimeDesc,
+}
+
+// orderLabel describes a coursework sort order.
+func orderLabel(order string) string {
+	switch order {
+	case api.CourseWorkOrderDueDateAsc:
+		return "due date, soonest first"
+	case api.CourseWorkOrderDueDateDesc:
+		return "due date, latest first"
+	case api.CourseWorkOrderUpdateTimeDesc:
+		return "recently updated"
+	default:
+		return order
+	}
 }
 
 // NewCourseworkModel creates a new coursework model.
-func NewCourseworkModel(course *api.Course, apiClient *api.Client) *CourseworkModel {
+func NewCourseworkModel(course *api.Course, apiClient api.ClassroomClient) *CourseworkModel {
 	// Create spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
@@ -93,6 +145,8 @@ func NewCourseworkModel(course *api.Course, apiClient *api.Client) *CourseworkMo
 
 	// Create list
 	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	setListKeys(&l)
+	l.Filter = foldFilter
 	l.Title = "Coursework"
 	l.Styles.Title = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#ff79c6")).
@@ -100,11 +154,12 @@ func NewCourseworkModel(course *api.Course, apiClient *api.Client) *CourseworkMo
 
 	return &CourseworkModel{
 		course:    course,
-		apiClient: apiClient,
+		apiClient: cache.NewCachedClient(apiClient, options.Cache),
 		filter:    FilterAll,
 		list:      l,
 		spinner:   s,
 		loading:   true,
+		order:     api.CourseWorkOrderDueDateAsc,
 	}
 }
 
@@ -117,37 +172,90 @@ func (m *CourseworkModel) Init() tea.Cmd {
 func (m *CourseworkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc", "b":
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
 			return m, func() tea.Msg { return NavigateBackMsg{} }
-		case "a":
-			m.filter = FilterAssignments
-			m.updateList()
-		case "m":
-			m.filter = FilterMaterials
-			m.updateList()
-		case "n":
-			m.filter = FilterAnnouncements
-			m.updateList()
-		case "all", "A":
-			m.filter = FilterAll
-			m.updateList()
-		case "r":
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
 			m.loading = true
 			m.err = nil
 			return m, m.loadCoursework()
-		case "enter":
+		case key.Matches(msg, bound(keymap.Open)):
+			if item, ok := m.list.SelectedItem().(CourseworkItem); ok {
+				return m, m.link.open(item.coursework.AlternateLink)
+			}
+		case key.Matches(msg, bound(keymap.Select)):
 			if i := m.list.SelectedItem(); i != nil {
 				if item, ok := i.(CourseworkItem); ok {
 					m.selectedCW = item.coursework
 					return m, func() tea.Msg {
-						return SubmissionListMsg{
+						return CourseWorkDetailMsg{
 							Course:     m.course,
 							CourseWork: item.coursework,
 						}
 					}
 				}
 			}
+		case key.Matches(msg, bound(keymap.ShowAssignments)):
+			m.filter = FilterAssignments
+			m.updateList()
+		case key.Matches(msg, bound(keymap.ShowMaterials)):
+			m.filter = FilterMaterials
+			m.updateList()
+		case key.Matches(msg, bound(keymap.ShowAnnouncements)):
+			m.filter = FilterAnnouncements
+			m.updateList()
+		case key.Matches(msg, bound(keymap.ShowMine)):
+			if !m.isTeacher {
+				m.filter = FilterMine
+				m.updateList()
+			}
+		case key.Matches(msg, bound(keymap.ShowScheduled)):
+			if m.isTeacher {
+				m.filter = FilterScheduled
+				m.updateList()
+			}
+		case key.Matches(msg, bound(keymap.ShowAll)):
+			m.filter = FilterAll
+			m.updateList()
+		case key.Matches(msg, bound(keymap.Sort)):
+			m.order = nextOrder(m.order)
+			m.loading = true
+			return m, m.loadCoursework()
+		case key.Matches(msg, bound(keymap.Checklist)):
+			if options.Checklists == nil || m.isTeacher {
+				break
+			}
+			if item, ok := m.list.SelectedItem().(CourseworkItem); ok {
+				course := m.course
+				return m, func() tea.Msg { return ChecklistMsg{Course: course, CourseWork: item.coursework} }
+			}
+		case key.Matches(msg, bound(keymap.Focus)):
+			if options.Focus == nil || m.isTeacher {
+				break
+			}
+			if item, ok := m.list.SelectedItem().(CourseworkItem); ok {
+				course := m.course
+				return m, func() tea.Msg { return FocusMsg{Course: course, CourseWork: item.coursework} }
+			}
+		case key.Matches(msg, bound(keymap.Download)):
+			if item, ok := m.list.SelectedItem().(CourseworkItem); ok {
+				return m, m.download.start(item.coursework.Materials)
+			}
+		case key.Matches(msg, bound(keymap.Read)):
+			if item, ok := m.list.SelectedItem().(CourseworkItem); ok {
+				return m, m.download.read(item.coursework.Materials)
+			}
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
 		}
 
 	case spinner.TickMsg:
@@ -155,6 +263,27 @@ func (m *CourseworkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.loadCoursework(), afterRecovery(msg))
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -162,6 +291,7 @@ func (m *CourseworkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case courseworkLoadedMsg:
+		m.isTeacher = msg.isTeacher
 		m.coursework = msg.coursework
 		m.filteredCW = msg.coursework
 		m.loading = false
@@ -172,7 +302,14 @@ func (m *CourseworkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case courseworkLoadErrorMsg:
 		m.loading = false
 		m.err = msg.err
+		reportError(msg.err)
 		return m, nil
+
+	case downloadProgressMsg, downloadDoneMsg, pagerReadyMsg, pagerDoneMsg, scopeGrantedMsg:
+		return m, m.download.update(msg)
+
+	case connectivityMsg:
+		return m, watchConnectivity()
 	}
 
 	var cmd tea.Cmd
@@ -199,36 +336,50 @@ func (m *CourseworkModel) View() string {
 	}
 
 	if m.err != nil {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center).
-			Render(
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#ff5555")).
-						Bold(true).
-						Render("Error loading coursework"),
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#f8f8f2")).
-						Render(m.err.Error()),
-				),
-			)
+		return renderErrorView("Error loading coursework", m.err, m.width, m.height)
 	}
 
 	// Render filter status
+	filters := []keymap.Action{keymap.ShowAssignments, keymap.ShowMaterials, keymap.ShowAnnouncements, keymap.ShowMine}
+	if m.isTeacher {
+		filters[3] = keymap.ShowScheduled
+	}
+	var shown []string
+	for _, a := range filters {
+		shown = append(shown, bound(a).Help().Key)
+	}
+	keys := strings.Join(shown, "/")
 	filterInfo := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#bd93f9")).
-		Render(fmt.Sprintf("Filter: %s (press a/m/n/all)", m.filter))
+		Render(fmt.Sprintf("Filter: %s (press %s/%s) | Sort: %s", m.filter, keys, bound(keymap.ShowAll).Help().Key, orderLabel(m.order)))
 
 	// Render list
 	listView := m.list.View()
 
 	// Render footer
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272a4")).
-		Render("↑↓ navigate | enter select | a/m/n filter | r refresh | b back | q quit")
+	bindings := []key.Binding{navigateKey(), actionKey(keymap.Select, "select"), bind(keys, "filter"), actionKey(keymap.Sort, "sort"), actionKey(keymap.Open, "open")}
+	if options.Checklists != nil && !m.isTeacher {
+		bindings = append(bindings, actionKey(keymap.Checklist, "checklist"))
+	}
+	if options.Focus != nil && !m.isTeacher {
+		bindings = append(bindings, actionKey(keymap.Focus, "focus"))
+	}
+	if options.Drive != nil {
+		bindings = append(bindings, actionKey(keymap.Download, "download"), actionKey(keymap.Read, "read"))
+	}
+	bindings = append(bindings, refreshKey(), backKey(), quitKey())
+	footer := m.help.render(m.width, bindings...)
+
+	sections := []string{filterInfo, "", listView, ""}
+	if status := m.download.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
 
 	return lipgloss.NewStyle().
 		Width(m.width).
@@ -237,26 +388,24 @@ func (m *CourseworkModel) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Left,
-				filterInfo,
-				"",
-				listView,
-				"",
-				footer,
+				sections...,
 			),
 		)
 }
 
 // loadCoursework loads coursework from the API.
 func (m *CourseworkModel) loadCoursework() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := loadContext(refresh)
 		defer cancel()
 
-		coursework, err := m.apiClient.ListCourseWork(ctx, m.course.ID)
+		isTeacher, coursework, err := loadVisibleCourseWork(ctx, m.apiClient, m.course.ID, m.order)
 		if err != nil {
 			return courseworkLoadErrorMsg{err: err}
 		}
-		return courseworkLoadedMsg{coursework: coursework}
+		return courseworkLoadedMsg{coursework: coursework, isTeacher: isTeacher}
 	}
 }
 
@@ -268,11 +417,15 @@ func (m *CourseworkModel) updateList() {
 	} else {
 		m.filteredCW = make([]*api.CourseWork, 0)
 		for _, cw := range m.coursework {
-			if m.filter == FilterAssignments && cw.WorkType == "ASSIGNMENT" {
+			if m.filter == FilterAssignments && cw.WorkType == api.WorkTypeAssignment {
+				m.filteredCW = append(m.filteredCW, cw)
+			} else if m.filter == FilterMaterials && cw.WorkType == api.WorkTypeMaterial {
 				m.filteredCW = append(m.filteredCW, cw)
-			} else if m.filter == FilterMaterials && cw.WorkType == "MATERIAL" {
+			} else if m.filter == FilterAnnouncements && cw.WorkType == api.WorkTypeShortAnswer {
 				m.filteredCW = append(m.filteredCW, cw)
-			} else if m.filter == FilterAnnouncements && cw.WorkType == "SHORT_ANSWER_QUESTION" {
+			} else if m.filter == FilterScheduled && cw.State == api.CourseWorkStateDraft {
+				m.filteredCW = append(m.filteredCW, cw)
+			} else if m.filter == FilterMine && assignedToMe(cw) {
 				m.filteredCW = append(m.filteredCW, cw)
 			}
 		}
@@ -291,9 +444,69 @@ func (m *CourseworkModel) SelectedCourseWork() *api.CourseWork {
 	return m.selectedCW
 }
 
+// loadVisibleCourseWork determines the user's role in a course and loads the
+// coursework that role may see: drafts for teachers only, and deleted items
+// only when tombstones are enabled. orderBy sorts server-side; empty keeps the
+// API default.
+func loadVisibleCourseWork(ctx context.Context, client api.ClassroomClient, courseID, orderBy string) (bool, []*api.CourseWork, error) {
+	isTeacher, err := client.IsTeacher(ctx, courseID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	states := []string{api.CourseWorkStatePublished}
+	if isTeacher {
+		states = append(states, api.CourseWorkStateDraft)
+	}
+	if options.ShowDeletedCourseWork {
+		states = append(states, api.CourseWorkStateDeleted)
+	}
+
+	coursework, err := client.ListCourseWork(ctx, courseID, &api.ListCourseWorkOptions{
+		States:  states,
+		OrderBy: orderBy,
+	})
+	if err != nil {
+		return false, nil, err
+	}
+
+	return isTeacher, api.VisibleCourseWork(coursework, isTeacher, options.ShowDeletedCourseWork), nil
+}
+
+// nextOrder returns the sort order after order.
+func nextOrder(order string) string {
+	for i, o := range courseworkOrders {
+		if o == order {
+			return courseworkOrders[(i+1)%len(courseworkOrders)]
+		}
+	}
+	return courseworkOrders[0]
+}
+
+// stateBadge returns a badge for coursework that is not published.
+func stateBadge(cw *api.CourseWork) string {
+	if at, ok := cw.Scheduled(); ok {
+		return scheduledBadge(at)
+	}
+	switch cw.State {
+	case api.CourseWorkStateDraft:
+		return "[DRAFT] "
+	case api.CourseWorkStateDeleted:
+		return "[DELETED] "
+	default:
+		return ""
+	}
+}
+
+// scheduledBadge returns a badge for a draft due to be published at t.
+func scheduledBadge(t time.Time) string {
+	return fmt.Sprintf("[SCHEDULED %s] ", t.Local().Format("Jan 2 15:04"))
+}
+
 // courseworkLoadedMsg is sent when coursework is loaded.
 type courseworkLoadedMsg struct {
 	coursework []*api.CourseWork
+	isTeacher  bool
 }
 
 // courseworkLoadErrorMsg is sent when coursework fails to load.
@@ -301,7 +514,7 @@ type courseworkLoadErrorMsg struct {
 	err error
 }
 
-// SubmissionListMsg is sent when coursework is selected.
+// SubmissionListMsg is sent to open the submissions of coursework.
 type SubmissionListMsg struct {
 	Course     *api.Course
 	CourseWork *api.CourseWork
@@ -0,0 +1,487 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/outbox"
+)
+
+// CourseWorkDetailModel shows one piece of coursework in full: its
+// description, due date, points, topic, and materials, and for students
+// their own submission. From here students turn in or attach files, and
+// anyone opens the submissions table.
+type CourseWorkDetailModel struct {
+	course     *api.Course
+	courseWork *api.CourseWork
+	apiClient  api.ClassroomClient
+	refresh    bool // next load skips the cache
+	isTeacher  bool
+	// submission is the user's own, or nil for teachers and when there is
+	// none.
+	submission  *api.StudentSubmission
+	topic       string
+	addOns      []*api.AddOnAttachment
+	spinner     spinner.Model
+	loading     bool
+	err         error
+	actionErr   error
+	prompt      *confirmation
+	syncNotice  string
+	download    download
+	upload      upload
+	link        browserLink
+	translation translation
+	help        helpOverlay
+	width       int
+	height      int
+}
+
+// CourseWorkDetailMsg is sent to open the detail screen of coursework.
+type CourseWorkDetailMsg struct {
+	Course     *api.Course
+	CourseWork *api.CourseWork
+}
+
+// courseWorkDetailLoadedMsg carries what the detail screen shows besides
+// the coursework itself.
+type courseWorkDetailLoadedMsg struct {
+	isTeacher  bool
+	submission *api.StudentSubmission
+	topic      string
+	addOns     []*api.AddOnAttachment
+	err        error
+}
+
+// NewCourseWorkDetailModel creates the detail screen of courseWork.
+func NewCourseWorkDetailModel(course *api.Course, courseWork *api.CourseWork, apiClient api.ClassroomClient) *CourseWorkDetailModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6"))
+
+	return &CourseWorkDetailModel{
+		course:     course,
+		courseWork: courseWork,
+		apiClient:  cache.NewCachedClient(apiClient, options.Cache),
+		spinner:    s,
+		loading:    true,
+	}
+}
+
+// Init initializes the model.
+func (m *CourseWorkDetailModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.load(), watchConnectivity())
+}
+
+// load fetches the user's role, their submission, and the coursework's
+// topic and add-ons.
+func (m *CourseWorkDetailModel) load() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
+	client, courseID, cw := m.apiClient, m.course.ID, m.courseWork
+	return func() tea.Msg {
+		ctx, cancel := loadContext(refresh)
+		defer cancel()
+
+		isTeacher, err := client.IsTeacher(ctx, courseID)
+		if err != nil {
+			return courseWorkDetailLoadedMsg{err: err}
+		}
+
+		var submission *api.StudentSubmission
+		if !isTeacher && cw.State == api.CourseWorkStatePublished {
+			// The same listing the submissions screen makes, so either
+			// screen warms the cache for the other
+			subs, err := client.ListStudentSubmissions(ctx, courseID, cw.ID, &api.ListStudentSubmissionsOptions{UserID: "me"})
+			if err != nil {
+				return courseWorkDetailLoadedMsg{err: err}
+			}
+			if len(subs) > 0 {
+				submission = subs[0]
+			}
+		}
+
+		// Topics need a scope that may not be granted, and add-on
+		// attachments are only listed for users of the add-on, so the
+		// detail is shown without them when they cannot be read.
+		topic := ""
+		if cw.TopicID != "" {
+			if topics, err := client.ListTopics(ctx, courseID); err == nil {
+				for _, t := range topics {
+					if t.ID == cw.TopicID {
+						topic = t.Name
+					}
+				}
+			}
+		}
+		addOns, err := client.ListAddOnAttachments(ctx, courseID, cw.ID)
+		if err != nil {
+			addOns = nil
+		}
+		return courseWorkDetailLoadedMsg{isTeacher: isTeacher, submission: submission, topic: topic, addOns: addOns}
+	}
+}
+
+// Update handles messages.
+func (m *CourseWorkDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.prompt != nil {
+			done, cmd := m.prompt.handleKey(msg)
+			if done {
+				m.prompt = nil
+			}
+			return m, cmd
+		}
+		if m.upload.editing {
+			return m, m.upload.handleKey(msg, m.apiClient, m.submission)
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
+			m.loading = true
+			m.err = nil
+			return m, m.load()
+		case key.Matches(msg, bound(keymap.Open)):
+			return m, m.link.open(m.courseWork.AlternateLink)
+		case key.Matches(msg, bound(keymap.Select), bound(keymap.Submissions)):
+			course, cw := m.course, m.courseWork
+			return m, func() tea.Msg { return SubmissionListMsg{Course: course, CourseWork: cw} }
+		case key.Matches(msg, bound(keymap.TurnIn)):
+			return m, m.turnIn()
+		case key.Matches(msg, bound(keymap.Attach)):
+			m.actionErr = m.canAttach()
+			if m.actionErr == nil && m.upload.begin() {
+				return m, textinput.Blink
+			}
+		case key.Matches(msg, bound(keymap.Translate)):
+			return m, m.translation.toggle(m.courseWork.Description)
+		case key.Matches(msg, bound(keymap.Download)):
+			return m, m.download.start(m.courseWork.Materials)
+		case key.Matches(msg, bound(keymap.Read)):
+			return m, m.download.read(m.courseWork.Materials)
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
+		}
+
+	case courseWorkDetailLoadedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err != nil {
+			reportError(msg.err)
+			return m, nil
+		}
+		m.isTeacher = msg.isTeacher
+		m.submission = msg.submission
+		m.topic = msg.topic
+		m.addOns = msg.addOns
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.load(), afterRecovery(msg))
+
+	case submissionUpdatedMsg:
+		m.actionErr = nil
+		m.loading = true
+		return m, tea.Batch(m.load(), notify(toastSuccess, msg.notice))
+
+	case submissionQueuedMsg:
+		m.actionErr = nil
+		return m, notify(toastInfo, msg.notice)
+
+	case errorMsg:
+		if sessionExpired(msg.err) {
+			m.err = msg.err
+			return m, nil
+		}
+		return m, notifyError(msg.err)
+
+	case uploadProgressMsg:
+		return m, m.upload.update(msg)
+
+	case uploadDoneMsg:
+		cmd := m.upload.update(msg)
+		if msg.err == nil {
+			m.loading = true
+			return m, tea.Batch(cmd, m.load())
+		}
+		return m, cmd
+
+	case scopeGrantedMsg:
+		return m, tea.Batch(m.download.update(msg), m.upload.update(msg))
+
+	case downloadProgressMsg, downloadDoneMsg, pagerReadyMsg, pagerDoneMsg:
+		return m, m.download.update(msg)
+
+	case translatedMsg:
+		m.translation.update(msg)
+		return m, nil
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case outboxSyncedMsg:
+		m.syncNotice = renderSyncResult(msg)
+		m.loading = true
+		return m, m.load()
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// turnIn asks to turn in the user's submission.
+func (m *CourseWorkDetailModel) turnIn() tea.Cmd {
+	if m.isTeacher {
+		m.actionErr = fmt.Errorf("only students can turn in their own work")
+		return nil
+	}
+	if m.submission == nil {
+		m.actionErr = fmt.Errorf("you have no submission for this coursework")
+		return nil
+	}
+	prompt, cmd, err := confirmTurnIn(m.apiClient, m.course.ID, m.courseWork, m.submission)
+	m.actionErr = err
+	m.prompt = prompt
+	return cmd
+}
+
+// canAttach reports why files cannot be attached to the user's
+// submission, or nil when they can.
+func (m *CourseWorkDetailModel) canAttach() error {
+	switch {
+	case options.Drive == nil:
+		return fmt.Errorf("attaching files needs Google Drive access; run `auth scopes`")
+	case m.isTeacher:
+		return fmt.Errorf("only students can attach files to their own work")
+	case m.submission == nil:
+		return fmt.Errorf("you have no submission for this coursework")
+	case m.courseWork.WorkType != api.WorkTypeAssignment:
+		return fmt.Errorf("files can only be attached to assignments")
+	case !m.submission.CanTurnIn():
+		return fmt.Errorf("submission is %s; unsubmit it in Classroom to attach files", m.submission.State)
+	}
+	return nil
+}
+
+// View renders the model.
+func (m *CourseWorkDetailModel) View() string {
+	if m.loading {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Align(lipgloss.Center).
+			Render(
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					m.spinner.View(),
+					lipgloss.NewStyle().
+						Foreground(lipgloss.Color("#f8f8f2")).
+						Render("Loading coursework..."),
+				),
+			)
+	}
+
+	if m.err != nil {
+		return renderErrorView("Error loading coursework", m.err, m.width, m.height)
+	}
+
+	title := stateBadge(m.courseWork) + m.courseWork.Title
+	if badge := focusBadge(m.course.ID, m.courseWork.ID); badge != "" && !m.isTeacher {
+		title += " | " + badge
+	}
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(title)
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, m.renderFacts(), "")
+	if desc := m.renderDescription(); desc != "" {
+		sections = append(sections, desc, "")
+	}
+	if materials := renderMaterials(m.courseWork.Materials, m.addOns); materials != "" {
+		sections = append(sections, materials, "")
+	}
+	if work := m.renderOwnWork(); work != "" {
+		sections = append(sections, work, "")
+	}
+
+	if m.prompt != nil {
+		sections = append(sections, m.prompt.View())
+	} else if m.actionErr != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.actionErr)))
+	} else if status := m.upload.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.download.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	} else if m.syncNotice != "" {
+		sections = append(sections, m.syncNotice)
+	}
+
+	keys := []key.Binding{actionKey(keymap.Select, "submissions")}
+	if !m.isTeacher {
+		keys = append(keys, actionKey(keymap.TurnIn, "turn in"), actionKey(keymap.Attach, "attach"))
+	}
+	keys = append(keys, actionKey(keymap.Open, "open"))
+	if options.Translator != nil && m.courseWork.Description != "" {
+		keys = append(keys, actionKey(keymap.Translate, "translate"))
+	}
+	if options.Drive != nil && len(m.courseWork.Materials) > 0 {
+		keys = append(keys, actionKey(keymap.Download, "download"), actionKey(keymap.Read, "read"))
+	}
+	keys = append(keys, refreshKey(), backKey())
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, keys...))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// renderFacts renders the course, due date, points, topic, and status.
+func (m *CourseWorkDetailModel) renderFacts() string {
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Width(10)
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+
+	rows := [][2]string{{"Course", m.course.Name}, {"Due", formatDueDate(m.courseWork)}}
+	if m.courseWork.MaxPoints > 0 {
+		rows = append(rows, [2]string{"Points", fmt.Sprint(m.courseWork.MaxPoints)})
+	}
+	if m.topic != "" {
+		rows = append(rows, [2]string{"Topic", m.topic})
+	}
+	if status := m.status(); status != "" {
+		rows = append(rows, [2]string{"Status", status})
+	}
+	if badge := checklistBadge(m.course.ID, m.courseWork.ID); badge != "" {
+		rows = append(rows, [2]string{"Checklist", badge})
+	}
+
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		lines[i] = label.Render(r[0]) + value.Render(r[1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDueDate describes when cw is due in local time, e.g. "Mon Mar 2,
+// 23:59 CET", or "No due date".
+func formatDueDate(cw *api.CourseWork) string {
+	due, allDay, ok := cw.Due()
+	if !ok {
+		return "No due date"
+	}
+	if allDay {
+		return due.Format("Mon Jan 2, 2006")
+	}
+	due = due.Local()
+	s := due.Format("Mon Jan 2, 2006 15:04 MST")
+	if left := time.Until(due); left > 0 && left < 48*time.Hour {
+		s += fmt.Sprintf(" (in %s)", left.Round(time.Minute))
+	}
+	return s
+}
+
+// status describes the user's submission: its state, grade, and whether
+// it was late. Teachers have none.
+func (m *CourseWorkDetailModel) status() string {
+	sub := m.submission
+	if m.isTeacher || sub == nil {
+		return ""
+	}
+	state := strings.ToLower(strings.ReplaceAll(sub.State, "_", " "))
+	if pendingSync(outbox.KindTurnIn, sub.ID) {
+		state = "turn-in pending sync"
+	}
+	parts := []string{state}
+	if sub.AssignedGrade > 0 {
+		parts = append(parts, fmt.Sprintf("graded %d/%d", sub.AssignedGrade, m.courseWork.MaxPoints))
+	}
+	if sub.Late {
+		parts = append(parts, "late")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderDescription renders the whole description, or its translation
+// after 'T'.
+func (m *CourseWorkDetailModel) renderDescription() string {
+	if m.courseWork.Description == "" {
+		return ""
+	}
+	text, status := m.translation.render(m.courseWork.Description)
+	desc := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f8f8f2")).
+		Render(strings.Join(wrapText(text, max(m.width-4, 20)), "\n"))
+	if status != "" {
+		desc = lipgloss.JoinVertical(lipgloss.Left, desc, status)
+	}
+	return desc
+}
+
+// renderOwnWork lists the files attached to the user's submission.
+func (m *CourseWorkDetailModel) renderOwnWork() string {
+	if m.submission == nil || len(m.submission.Attachments) == 0 {
+		return ""
+	}
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{muted.Render("Your work:")}
+	for _, a := range m.submission.Attachments {
+		lines = append(lines, "  "+a.Title)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,35 @@
+package tea
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/fake"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// TestCourseworkReboundKeys tests that a screen action runs from its new
+// key, and that the key it gave up can be taken by another action.
+func TestCourseworkReboundKeys(t *testing.T) {
+	keys, err := keymap.New(map[string][]string{"sort": {"z"}, "show_assignments": {"s"}})
+	if err != nil {
+		t.Fatalf("Failed to build key map: %v", err)
+	}
+	SetOptions(Options{Keys: keys})
+	t.Cleanup(func() { SetOptions(Options{}) })
+
+	m := NewCourseworkModel(&api.Course{ID: "c1", Name: "Biology"}, fake.New("t1"))
+	m.loading = false
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.filter != FilterAssignments {
+		t.Errorf("Expected s to show assignments, got filter %v", m.filter)
+	}
+	if m.order != api.CourseWorkOrderDueDateAsc {
+		t.Errorf("Expected s not to sort, got order %v", m.order)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	if m.order == api.CourseWorkOrderDueDateAsc {
+		t.Error("Expected z to sort")
+	}
+}
@@ -0,0 +1,256 @@
+package tea
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/drive"
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/humanize"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// downloadTimeout bounds a whole batch of attachment downloads, which can
+// take far longer than a screen load.
+const downloadTimeout = 10 * time.Minute
+
+// downloadEvent is progress or the final result of a download, sent from
+// the download goroutine.
+type downloadEvent struct {
+	progress *drive.Progress
+	paths    []string
+	err      error
+}
+
+// downloadProgressMsg reports progress and carries the channel to keep
+// listening on.
+type downloadProgressMsg struct {
+	events   <-chan downloadEvent
+	progress drive.Progress
+}
+
+// downloadDoneMsg is sent when all attachments are saved or one failed.
+type downloadDoneMsg struct {
+	paths []string
+	err   error
+}
+
+// pagerReadyMsg carries handouts exported for reading in the pager.
+type pagerReadyMsg struct {
+	dir   string
+	paths []string
+	err   error
+}
+
+// pagerDoneMsg is sent when the pager exits.
+type pagerDoneMsg struct {
+	err error
+}
+
+// download tracks saving attachments to the downloads directory with 'd'
+// and reading Google Docs handouts in the pager with 'v'.
+type download struct {
+	active   bool
+	reading  bool
+	progress drive.Progress
+	paths    []string
+	err      error
+	empty    bool
+	// pending starts the download once its scope is granted.
+	pending func() tea.Cmd
+}
+
+// start downloads the Drive files among attachments. It returns nil when
+// Drive is not configured or a download is already running.
+func (d *download) start(attachments []api.Attachment) tea.Cmd {
+	if options.Drive == nil || d.active {
+		return nil
+	}
+	files := drive.DriveFiles(attachments)
+	*d = download{empty: len(files) == 0}
+	if d.empty {
+		return nil
+	}
+	d.active = true
+	return d.withScope(func() tea.Cmd { return fetchFiles(files) })
+}
+
+// fetchFiles saves files to the downloads directory, reporting progress.
+func fetchFiles(files []api.Attachment) tea.Cmd {
+	// Progress is sent without blocking so the download never stalls if
+	// the screen stops listening; only the latest update matters.
+	events := make(chan downloadEvent, 1)
+	client, dir := options.Drive, options.DownloadDir
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+		defer cancel()
+		paths, err := client.DownloadAll(ctx, files, dir, func(p drive.Progress) {
+			select {
+			case events <- downloadEvent{progress: &p}:
+			default:
+			}
+		})
+		// Drain a pending progress event so the result always fits.
+		select {
+		case <-events:
+		default:
+		}
+		events <- downloadEvent{paths: paths, err: err}
+	}()
+	return waitDownload(events)
+}
+
+// withScope returns next, or when the Drive read scope has not been
+// confirmed yet, a command asking for it that runs next once granted.
+func (d *download) withScope(next func() tea.Cmd) tea.Cmd {
+	if !needsScopes(auth.ScopeDriveReadonly) {
+		return next()
+	}
+	d.pending = next
+	return requestScopes(auth.ScopeDriveReadonly)
+}
+
+// read exports the Google Docs and Slides among attachments as Markdown or
+// text and opens them in $PAGER. It returns nil when Drive is not
+// configured or a download is already running.
+func (d *download) read(attachments []api.Attachment) tea.Cmd {
+	if options.Drive == nil || d.active {
+		return nil
+	}
+	files := drive.DriveFiles(attachments)
+	*d = download{reading: true, empty: len(files) == 0}
+	if d.empty {
+		return nil
+	}
+	d.active = true
+	return d.withScope(func() tea.Cmd { return exportHandouts(files) })
+}
+
+// exportHandouts exports files as Markdown to a temporary directory for
+// the pager.
+func exportHandouts(files []api.Attachment) tea.Cmd {
+	client := options.Drive
+	return func() tea.Msg {
+		dir, err := os.MkdirTemp("", "classroom-handouts-")
+		if err != nil {
+			return pagerReadyMsg{err: fmt.Errorf("failed to create temporary directory: %w", err)}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+		defer cancel()
+		paths, err := client.ExportAll(ctx, files, dir, drive.FormatMarkdown, nil)
+		if err == nil && len(paths) == 0 {
+			err = fmt.Errorf("no Google Docs or Slides to read; press %s to download the files", bound(keymap.Download).Help().Key)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return pagerReadyMsg{err: err}
+		}
+		return pagerReadyMsg{dir: dir, paths: paths}
+	}
+}
+
+// pagerCommand returns the $PAGER command for paths, defaulting to less.
+func pagerCommand(paths []string) *exec.Cmd {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{"less"}
+	}
+	return exec.Command(args[0], append(args[1:], paths...)...)
+}
+
+// waitDownload waits for the next download event.
+func waitDownload(events <-chan downloadEvent) tea.Cmd {
+	return func() tea.Msg {
+		e := <-events
+		if e.progress != nil {
+			return downloadProgressMsg{events: events, progress: *e.progress}
+		}
+		return downloadDoneMsg{paths: e.paths, err: e.err}
+	}
+}
+
+// update applies a download message, returning the command that keeps
+// listening for progress.
+func (d *download) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case scopeGrantedMsg:
+		if d.pending == nil || !slices.Contains(msg.scopes, auth.ScopeDriveReadonly) {
+			return nil
+		}
+		next := d.pending
+		d.pending = nil
+		if msg.err != nil {
+			d.active = false
+			d.err = msg.err
+			return nil
+		}
+		return next()
+	case downloadProgressMsg:
+		d.progress = msg.progress
+		return waitDownload(msg.events)
+	case downloadDoneMsg:
+		d.active = false
+		d.paths, d.err = msg.paths, msg.err
+	case pagerReadyMsg:
+		if msg.err != nil {
+			d.active = false
+			d.err = msg.err
+			return nil
+		}
+		// The exported copies are only for reading, so they are removed
+		// once the pager exits.
+		return tea.ExecProcess(pagerCommand(msg.paths), func(err error) tea.Msg {
+			os.RemoveAll(msg.dir)
+			return pagerDoneMsg{err: err}
+		})
+	case pagerDoneMsg:
+		d.active = false
+		d.err = msg.err
+	}
+	return nil
+}
+
+// render returns a status line for the current or last download.
+func (d *download) render() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd"))
+	switch {
+	case d.active && d.reading:
+		return style.Render("Exporting handouts for the pager...")
+	case d.err != nil && d.reading:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render("Cannot read handouts: " + errorText(d.err))
+	case d.empty && d.reading:
+		return style.Render("No Drive files to read")
+	case d.active:
+		p := d.progress
+		if p.Name == "" {
+			return style.Render("Downloading...")
+		}
+		status := fmt.Sprintf("Downloading %s (%d/%d) %s", p.Name, p.Index, p.Count, humanize.Bytes(p.Done))
+		if p.Total > 0 {
+			status += fmt.Sprintf(" of %s (%d%%)", humanize.Bytes(p.Total), p.Done*100/p.Total)
+		}
+		return style.Render(status)
+	case d.err != nil:
+		status := "Download failed: " + errorText(d.err)
+		if len(d.paths) > 0 {
+			status = fmt.Sprintf("Saved %d file(s) before an error. %s", len(d.paths), status)
+		}
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render(status)
+	case d.empty:
+		return style.Render("No Drive files to download")
+	case len(d.paths) == 1:
+		return style.Render("Saved " + d.paths[0])
+	case len(d.paths) > 1:
+		return style.Render(fmt.Sprintf("Saved %d files to %s", len(d.paths), filepath.Dir(d.paths[0])))
+	}
+	return ""
+}
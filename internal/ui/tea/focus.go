@@ -0,0 +1,170 @@
+package tea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/focus"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// focusTickMsg advances a focus timer. It carries the timer so ticks from
+// a pane that was closed are ignored.
+type focusTickMsg struct {
+	timer *focus.Timer
+	at    time.Time
+}
+
+// FocusModel runs a focus timer against an assignment, logging focused
+// time to options.Focus. Leaving the pane stops the timer and logs the
+// unfinished focus phase.
+type FocusModel struct {
+	course     *api.Course
+	courseWork *api.CourseWork
+	timer      *focus.Timer
+	now        time.Time
+	actionErr  error
+	help       helpOverlay
+	width      int
+	height     int
+}
+
+// NewFocusModel creates a focus model and starts a focus phase.
+func NewFocusModel(course *api.Course, courseWork *api.CourseWork) *FocusModel {
+	now := time.Now()
+	return &FocusModel{
+		course:     course,
+		courseWork: courseWork,
+		timer:      focus.NewTimer(options.FocusTimer, now),
+		now:        now,
+	}
+}
+
+// Init starts the clock.
+func (m *FocusModel) Init() tea.Cmd {
+	return m.tick()
+}
+
+// tick schedules the next clock update.
+func (m *FocusModel) tick() tea.Cmd {
+	timer := m.timer
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return focusTickMsg{timer: timer, at: t}
+	})
+}
+
+// Update handles messages.
+func (m *FocusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			m.log(m.timer.Stop(time.Now()), false)
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Pause)):
+			m.now = time.Now()
+			if m.timer.Paused() {
+				m.timer.Resume(m.now)
+			} else {
+				m.timer.Pause(m.now)
+			}
+		case msg.String() == "?":
+			m.help.toggle()
+			return m, nil
+		}
+
+	case focusTickMsg:
+		if msg.timer != m.timer {
+			return m, nil
+		}
+		m.now = msg.at
+		if focused, _ := m.timer.Tick(msg.at); focused > 0 {
+			m.log(focused, true)
+		}
+		return m, m.tick()
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// log adds focused time to the assignment's total.
+func (m *FocusModel) log(focused time.Duration, completed bool) {
+	if err := options.Focus.Add(m.course.ID, m.courseWork.ID, focused, completed); err != nil {
+		m.actionErr = err
+	}
+}
+
+// View renders the model.
+func (m *FocusModel) View() string {
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(fmt.Sprintf("Focus | %s", m.courseWork.Title))
+
+	phaseColor := lipgloss.Color("#50fa7b")
+	if m.timer.Phase() == focus.PhaseBreak {
+		phaseColor = lipgloss.Color("#8be9fd")
+	}
+	remaining := m.timer.Remaining(m.now).Round(time.Second)
+	clock := fmt.Sprintf("%s  %02d:%02d", m.timer.Phase(), int(remaining.Minutes()), int(remaining.Seconds())%60)
+	if m.timer.Paused() {
+		clock += "  (paused)"
+	}
+	clockView := lipgloss.NewStyle().
+		Foreground(phaseColor).
+		Bold(true).
+		Render(clock)
+
+	record := options.Focus.Get(m.course.ID, m.courseWork.ID)
+	summary := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#bd93f9")).
+		Render(fmt.Sprintf("Cycles this sitting: %d | Logged: %s over %d sessions",
+			m.timer.Cycles(), focus.FormatDuration(record.Focused), record.Sessions))
+
+	sections := []string{header, "", clockView, summary, ""}
+	if m.actionErr != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render("Failed to log focus time: "+errorText(m.actionErr)), "")
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, actionKey(keymap.Pause, "pause/resume"), actionKey(keymap.Back, "stop and back"), quitKey()))
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// focusBadge returns the focus time logged against the coursework, such
+// as "⏱ 1h05m focused", or "" when none is logged.
+func focusBadge(courseID, courseWorkID string) string {
+	r := options.Focus.Get(courseID, courseWorkID)
+	if r.Focused <= 0 {
+		return ""
+	}
+	return "⏱ " + focus.FormatDuration(r.Focused) + " focused"
+}
+
+// FocusMsg is sent when a student starts a focus timer on an assignment.
+type FocusMsg struct {
+	Course     *api.Course
+	CourseWork *api.CourseWork
+}
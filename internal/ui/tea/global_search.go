@@ -0,0 +1,281 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+)
+
+// searchKind is what a global search result points at.
+type searchKind int
+
+const (
+	searchCourse searchKind = iota
+	searchCourseWork
+	searchAnnouncement
+)
+
+// searchLabels name each kind in the results.
+var searchLabels = map[searchKind]string{
+	searchCourse:       "course",
+	searchCourseWork:   "work",
+	searchAnnouncement: "post",
+}
+
+// searchTarget is one course, coursework item, or announcement that
+// global search can find.
+type searchTarget struct {
+	kind         searchKind
+	text         string // what the query is matched against
+	course       *api.Course
+	courseWork   *api.CourseWork
+	announcement *api.Announcement
+}
+
+// searchResult is a target that matched, with the runes of its text that
+// matched.
+type searchResult struct {
+	target  *searchTarget
+	matched []int
+}
+
+// GlobalSearchModel fuzzy-searches course names, coursework titles, and
+// announcement text across every course in the cache. It never calls the
+// API, so it covers what has been opened or prefetched, and works offline.
+type GlobalSearchModel struct {
+	input   textinput.Model
+	targets []*searchTarget
+	results []searchResult
+	cursor  int
+	loading bool
+	err     error
+	width   int
+	height  int
+}
+
+// OpenGlobalSearchMsg is sent to open global search.
+type OpenGlobalSearchMsg struct{}
+
+// searchIndexMsg carries the searchable contents of the cache.
+type searchIndexMsg struct {
+	targets []*searchTarget
+	err     error
+}
+
+// NewGlobalSearchModel creates the global search screen.
+func NewGlobalSearchModel() *GlobalSearchModel {
+	ti := textinput.New()
+	ti.Placeholder = "Search all courses..."
+	ti.Prompt = "/"
+	ti.Width = 40
+	ti.Focus()
+	return &GlobalSearchModel{input: ti, loading: options.Cache != nil}
+}
+
+// Init initializes the model.
+func (m *GlobalSearchModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.load())
+}
+
+// load reads everything searchable from the cache.
+func (m *GlobalSearchModel) load() tea.Cmd {
+	c := options.Cache
+	if c == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		contents, err := c.Contents()
+		if err != nil {
+			return searchIndexMsg{err: err}
+		}
+		return searchIndexMsg{targets: searchTargets(contents)}
+	}
+}
+
+// searchTargets lists what can be found in contents. Coursework and
+// announcements of courses that are not cached themselves are skipped, as
+// there is no course to open them in.
+func searchTargets(contents *cache.Contents) []*searchTarget {
+	var targets []*searchTarget
+	for _, course := range contents.Courses {
+		targets = append(targets, &searchTarget{kind: searchCourse, text: course.Name, course: course})
+	}
+	for _, course := range contents.Courses {
+		for _, cw := range contents.CourseWork[course.ID] {
+			targets = append(targets, &searchTarget{kind: searchCourseWork, text: cw.Title, course: course, courseWork: cw})
+		}
+		for _, a := range contents.Announcements[course.ID] {
+			text := strings.Join(strings.Fields(a.Text), " ")
+			targets = append(targets, &searchTarget{kind: searchAnnouncement, text: text, course: course, announcement: a})
+		}
+	}
+	return targets
+}
+
+// search ranks the targets against the query, best match first.
+func (m *GlobalSearchModel) search() {
+	m.cursor = 0
+	m.results = nil
+	query := strings.TrimSpace(m.input.Value())
+	if query == "" {
+		return
+	}
+	texts := make([]string, len(m.targets))
+	for i, t := range m.targets {
+		texts[i] = t.text
+	}
+	for _, rank := range foldFilter(query, texts) {
+		m.results = append(m.results, searchResult{target: m.targets[rank.Index], matched: rank.MatchedIndexes})
+	}
+}
+
+// Update handles messages.
+func (m *GlobalSearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "ctrl+r":
+			m.loading = options.Cache != nil
+			return m, m.load()
+		case "enter":
+			if m.cursor < len(m.results) {
+				return m, openSearchResult(m.results[m.cursor].target)
+			}
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		m.search()
+		return m, cmd
+
+	case searchIndexMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.targets = msg.targets
+			m.search()
+		}
+		return m, nil
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// openSearchResult opens the screen for a result.
+func openSearchResult(t *searchTarget) tea.Cmd {
+	switch t.kind {
+	case searchCourseWork:
+		return func() tea.Msg { return CourseWorkDetailMsg{Course: t.course, CourseWork: t.courseWork} }
+	case searchAnnouncement:
+		return func() tea.Msg { return AnnouncementSelectedMsg{Course: t.course, Announcement: t.announcement} }
+	}
+	return func() tea.Msg { return CourseSelectedMsg{Course: t.course} }
+}
+
+// View renders the model.
+func (m *GlobalSearchModel) View() string {
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render("Search All Courses")
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, m.input.View(), "")
+
+	query := strings.TrimSpace(m.input.Value())
+	switch {
+	case options.Cache == nil:
+		sections = append(sections, muted.Render("Caching is not configured; search covers cached data only."))
+	case m.err != nil:
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.err)))
+	case m.loading:
+		sections = append(sections, muted.Render("Reading cache..."))
+	case query == "":
+		sections = append(sections, muted.Render(fmt.Sprintf("Searching %d cached item(s). Open courses or run `cache warm` to search more.", len(m.targets))))
+	case len(m.results) == 0:
+		sections = append(sections, muted.Render("No matches."))
+	default:
+		sections = append(sections, m.renderResults()...)
+	}
+
+	sections = append(sections, "")
+	if bar := statusBar(nil, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, muted.Render("type to search | ↑↓ navigate | enter open | ctrl+r reload | esc back"))
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// renderResults renders the results that fit on screen, scrolled to keep
+// the cursor visible.
+func (m *GlobalSearchModel) renderResults() []string {
+	visible := max(m.height-12, 5)
+	start := max(m.cursor-visible+1, 0)
+	end := min(start+visible, len(m.results))
+	width := max(m.width-30, 20)
+
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#bd93f9")).Width(7)
+	text := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+	match := text.Foreground(lipgloss.Color("#50fa7b")).Bold(true)
+	course := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+
+	var lines []string
+	for i := start; i < end; i++ {
+		r := m.results[i]
+		title := r.target.text
+		if runes := []rune(title); len(runes) > width {
+			title = string(runes[:width-1]) + "…"
+		}
+		line := label.Render(searchLabels[r.target.kind]) +
+			lipgloss.StyleRunes(title, r.matched, match, text)
+		if r.target.kind != searchCourse {
+			line += course.Render("  " + r.target.course.Name)
+		}
+		prefix := "  "
+		if i == m.cursor {
+			prefix = match.Render("> ")
+		}
+		lines = append(lines, prefix+line)
+	}
+	if len(m.results) > end-start {
+		lines = append(lines, course.Render(fmt.Sprintf("%d matches", len(m.results))))
+	}
+	return lines
+}
@@ -0,0 +1,532 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/outbox"
+)
+
+// GradingModel steps a teacher through submissions one student at a time.
+// Digits type a grade; enter saves it as a draft, F assigns it, and R
+// returns the submission, each moving on to the next student.
+type GradingModel struct {
+	course      *api.Course
+	courseWork  *api.CourseWork
+	apiClient   api.ClassroomClient
+	submissions []*api.StudentSubmission
+	index       int // submission being graded
+	cursor      int // selected attachment
+	names       map[string]string
+	input       textinput.Model
+	prompt      *confirmation
+	// pending is the save in flight, applied to the submission once it
+	// succeeds. Only one save runs at a time.
+	pending   *gradeChange
+	actionErr error
+	download  download
+	link      browserLink
+	help      helpOverlay
+	width     int
+	height    int
+}
+
+// gradeChange is a grade saved, or a return, for one submission.
+type gradeChange struct {
+	index    int
+	grade    float64
+	assigned bool // grade is the assigned grade, not a draft
+	returned bool
+}
+
+// OpenGradingMsg is sent to grade the given submissions, in order.
+type OpenGradingMsg struct {
+	Course      *api.Course
+	CourseWork  *api.CourseWork
+	Submissions []*api.StudentSubmission
+}
+
+// gradingNamesMsg carries the names of the students in the course.
+type gradingNamesMsg struct {
+	names map[string]string
+}
+
+// NewGradingModel creates the grading screen for submissions. The
+// submissions are copied, so grades saved here do not change the caller's.
+func NewGradingModel(course *api.Course, courseWork *api.CourseWork, submissions []*api.StudentSubmission, apiClient api.ClassroomClient) *GradingModel {
+	ti := textinput.New()
+	ti.Placeholder = "grade"
+	ti.Prompt = "Grade: "
+	ti.Width = 10
+	ti.CharLimit = 8
+	ti.Focus()
+
+	subs := make([]*api.StudentSubmission, len(submissions))
+	for i, s := range submissions {
+		cp := *s
+		subs[i] = &cp
+	}
+	m := &GradingModel{
+		course:      course,
+		courseWork:  courseWork,
+		apiClient:   cache.NewCachedClient(apiClient, options.Cache),
+		submissions: subs,
+		input:       ti,
+	}
+	m.show(m.nextUngraded(-1))
+	return m
+}
+
+// Init initializes the model.
+func (m *GradingModel) Init() tea.Cmd {
+	client, courseID := m.apiClient, m.course.ID
+	loadNames := func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+		return gradingNamesMsg{names: rosterNames(ctx, client, courseID)}
+	}
+	return tea.Batch(textinput.Blink, loadNames, watchConnectivity())
+}
+
+// Update handles messages.
+func (m *GradingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.prompt != nil {
+			done, cmd := m.prompt.handleKey(msg)
+			if done {
+				m.prompt = nil
+			}
+			return m, cmd
+		}
+		return m, m.handleKey(msg)
+
+	case gradingNamesMsg:
+		m.names = msg.names
+		return m, nil
+
+	case submissionUpdatedMsg:
+		return m, m.finishSave(false)
+
+	case submissionQueuedMsg:
+		return m, m.finishSave(true)
+
+	case errorMsg:
+		m.pending = nil
+		return m, notifyError(msg.err)
+
+	case downloadProgressMsg, downloadDoneMsg, pagerReadyMsg, pagerDoneMsg, scopeGrantedMsg:
+		return m, m.download.update(msg)
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// handleKey handles a key. The grade input only takes digits and a
+// decimal point, leaving letters free for commands.
+func (m *GradingModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, bound(keymap.NextUngraded)):
+		m.show(m.nextUngraded(m.index))
+		return nil
+	case key.Matches(msg, bound(keymap.Download)):
+		return m.download.start(m.selectedAttachments())
+	case key.Matches(msg, bound(keymap.DownloadAll)):
+		if sub := m.current(); sub != nil {
+			return m.download.start(sub.Attachments)
+		}
+		return nil
+	case key.Matches(msg, bound(keymap.Read)):
+		return m.download.read(m.selectedAttachments())
+	case key.Matches(msg, bound(keymap.Assign)):
+		return m.save(true)
+	case key.Matches(msg, bound(keymap.Return)):
+		return m.confirmReturn()
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		return func() tea.Msg { return NavigateBackMsg{} }
+	case "right", "tab", "n":
+		m.show(min(m.index+1, len(m.submissions)-1))
+	case "left", "shift+tab", "p":
+		m.show(max(m.index-1, 0))
+	case "?":
+		m.help.toggle()
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if sub := m.current(); sub != nil && m.cursor < len(sub.Attachments)-1 {
+			m.cursor++
+		}
+	case "o":
+		if a := m.selectedAttachments(); len(a) > 0 {
+			return m.link.openOr(a[0].URL, "this attachment has no link")
+		}
+		if sub := m.current(); sub != nil {
+			return m.link.open(sub.AlternateLink)
+		}
+	case "enter":
+		return m.save(false)
+	case "backspace", "delete", "ctrl+u", "ctrl+w", "home", "end", "ctrl+a", "ctrl+e":
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return cmd
+	default:
+		if msg.Type == tea.KeyRunes && strings.Trim(string(msg.Runes), "0123456789.") == "" {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return cmd
+		}
+	}
+	return nil
+}
+
+// current returns the submission being graded, or nil when there are none.
+func (m *GradingModel) current() *api.StudentSubmission {
+	if m.index >= 0 && m.index < len(m.submissions) {
+		return m.submissions[m.index]
+	}
+	return nil
+}
+
+// show moves to the submission at i, filling the input with its grade.
+func (m *GradingModel) show(i int) {
+	m.index = i
+	m.cursor = 0
+	m.input.SetValue("")
+	if sub := m.current(); sub != nil {
+		if grade := currentGrade(sub); grade > 0 {
+			m.input.SetValue(strconv.Itoa(grade))
+		}
+	}
+	m.input.CursorEnd()
+}
+
+// currentGrade returns the draft grade of sub, or its assigned grade when
+// it has no draft.
+func currentGrade(sub *api.StudentSubmission) int {
+	if sub.DraftGrade > 0 {
+		return sub.DraftGrade
+	}
+	return sub.AssignedGrade
+}
+
+// graded reports whether sub has a grade, draft or assigned, or one
+// waiting to sync.
+func graded(sub *api.StudentSubmission) bool {
+	return currentGrade(sub) > 0 || pendingSync(outbox.KindDraftGrade, sub.ID)
+}
+
+// nextUngraded returns the first ungraded submission after from, wrapping
+// around, or from itself when every other one is graded. A from of -1
+// starts at the beginning.
+func (m *GradingModel) nextUngraded(from int) int {
+	n := len(m.submissions)
+	for step := 1; step <= n; step++ {
+		i := (from + step) % n
+		if !graded(m.submissions[i]) {
+			return i
+		}
+	}
+	return max(from, 0)
+}
+
+// gradedCount returns how many submissions have a grade.
+func (m *GradingModel) gradedCount() int {
+	count := 0
+	for _, s := range m.submissions {
+		if graded(s) {
+			count++
+		}
+	}
+	return count
+}
+
+// grade parses the grade typed in the input.
+func (m *GradingModel) grade() (float64, error) {
+	return parseGrade(m.input.Value())
+}
+
+// save saves the typed grade to the current submission: as a draft, which
+// is queued while offline, or as the assigned grade, which needs the
+// connection as the outbox only holds drafts.
+func (m *GradingModel) save(assigned bool) tea.Cmd {
+	sub := m.current()
+	if sub == nil || m.pending != nil {
+		return nil
+	}
+	grade, err := m.grade()
+	if err != nil {
+		m.actionErr = err
+		return nil
+	}
+	if assigned && isOffline() {
+		m.actionErr = errors.New("grades can only be assigned while online; press enter to save a draft")
+		return nil
+	}
+
+	m.actionErr = nil
+	m.pending = &gradeChange{index: m.index, grade: grade, assigned: assigned}
+	if !assigned {
+		return saveDraftGrade(m.apiClient, m.course, m.courseWork, sub, grade)
+	}
+	return m.commit(func(ctx context.Context) error {
+		_, err := m.apiClient.SetAssignedGrade(ctx, sub.CourseID, sub.CourseWorkID, sub.ID, grade)
+		return err
+	})
+}
+
+// confirmReturn asks to return the current submission to its student,
+// first assigning the typed grade when there is one.
+func (m *GradingModel) confirmReturn() tea.Cmd {
+	sub := m.current()
+	if sub == nil || m.pending != nil {
+		return nil
+	}
+	if isOffline() {
+		m.actionErr = errors.New("submissions can only be returned while online")
+		return nil
+	}
+	var grade float64
+	if strings.TrimSpace(m.input.Value()) != "" {
+		g, err := m.grade()
+		if err != nil {
+			m.actionErr = err
+			return nil
+		}
+		grade = g
+	}
+
+	client, index := m.apiClient, m.index
+	run := func() tea.Cmd {
+		m.actionErr = nil
+		m.pending = &gradeChange{index: index, grade: grade, assigned: grade > 0, returned: true}
+		return m.commit(func(ctx context.Context) error {
+			if grade > 0 {
+				if _, err := client.SetAssignedGrade(ctx, sub.CourseID, sub.CourseWorkID, sub.ID, grade); err != nil {
+					return err
+				}
+			}
+			return client.ReturnSubmission(ctx, sub.CourseID, sub.CourseWorkID, sub.ID)
+		})
+	}
+
+	prompt := fmt.Sprintf("Return %s's submission?", m.name(sub.UserID))
+	if grade > 0 {
+		prompt = fmt.Sprintf("Return %s's submission with %s/%d?", m.name(sub.UserID), formatPoints(grade), m.courseWork.MaxPoints)
+	}
+	var cmd tea.Cmd
+	m.prompt, cmd = requireConfirmation(confirm.Return, prompt, run)
+	return cmd
+}
+
+// commit runs fn with a timeout, reporting the result as
+// submissionUpdatedMsg or errorMsg.
+func (m *GradingModel) commit(fn func(ctx context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			return errorMsg{err: err}
+		}
+		return submissionUpdatedMsg{}
+	}
+}
+
+// finishSave applies the save in flight to its submission and moves on
+// to the next ungraded one, returning a toast describing the save. queued
+// is set when the draft grade waits for the connection.
+func (m *GradingModel) finishSave(queued bool) tea.Cmd {
+	change := m.pending
+	m.pending = nil
+	if change == nil {
+		return nil
+	}
+	sub := m.submissions[change.index]
+	if change.grade > 0 {
+		sub.DraftGrade = int(change.grade)
+	}
+	if change.assigned {
+		sub.AssignedGrade = int(change.grade)
+	}
+	if change.returned {
+		sub.State = api.SubmissionStateReturned
+	}
+	if change.index == m.index {
+		m.show(m.nextUngraded(m.index))
+	}
+
+	name := m.name(sub.UserID)
+	grade := fmt.Sprintf("%s/%d", formatPoints(change.grade), m.courseWork.MaxPoints)
+	switch {
+	case queued:
+		return notify(toastInfo, fmt.Sprintf("Draft grade for %s will sync when back online", name))
+	case change.returned && change.grade > 0:
+		return notify(toastSuccess, fmt.Sprintf("Returned %s's submission with %s", name, grade))
+	case change.returned:
+		return notify(toastSuccess, fmt.Sprintf("Returned %s's submission", name))
+	case change.assigned:
+		return notify(toastSuccess, fmt.Sprintf("Assigned %s to %s", grade, name))
+	}
+	return notify(toastSuccess, fmt.Sprintf("Saved draft grade %s for %s", grade, name))
+}
+
+// selectedAttachments returns the attachment under the cursor, or none.
+func (m *GradingModel) selectedAttachments() []api.Attachment {
+	sub := m.current()
+	if sub != nil && m.cursor < len(sub.Attachments) {
+		return sub.Attachments[m.cursor : m.cursor+1]
+	}
+	return nil
+}
+
+// name returns a student's full name, or their ID when it is unknown.
+func (m *GradingModel) name(userID string) string {
+	if name := m.names[userID]; name != "" {
+		return name
+	}
+	return userID
+}
+
+// View renders the model.
+func (m *GradingModel) View() string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+
+	title := "Grading | " + m.courseWork.Title
+	sub := m.current()
+	if sub != nil {
+		title += fmt.Sprintf(" | %d/%d graded", m.gradedCount(), len(m.submissions))
+	}
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(title)
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	if sub == nil {
+		sections = append(sections, muted.Render("No submissions to grade."), "", muted.Render("esc back"))
+		return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+	}
+
+	student := lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true).Render(m.name(sub.UserID))
+	sections = append(sections,
+		fmt.Sprintf("%s  %s", student, muted.Render(fmt.Sprintf("(%d of %d)", m.index+1, len(m.submissions)))),
+		m.renderStatus(sub), "")
+	if answer := sub.Answer; answer != "" {
+		sections = append(sections, muted.Render("Answer:"), value.Render(strings.Join(wrapText(answer, max(m.width-4, 20)), "\n")), "")
+	}
+	sections = append(sections, m.renderAttachments(sub), "")
+
+	input := m.input.View()
+	if m.courseWork.MaxPoints > 0 {
+		input += muted.Render(fmt.Sprintf(" / %d", m.courseWork.MaxPoints))
+	}
+	if warning := gradeWarning(m.input.Value(), m.courseWork.MaxPoints); warning != "" {
+		input += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#f1fa8c")).Render(warning)
+	}
+	sections = append(sections, input, "")
+
+	switch {
+	case m.prompt != nil:
+		sections = append(sections, m.prompt.View())
+	case m.pending != nil:
+		sections = append(sections, muted.Render("Saving..."))
+	case m.actionErr != nil:
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.actionErr)))
+	}
+	if status := m.download.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	}
+
+	keys := []key.Binding{bind("0-9", "grade"), bind("enter", "save draft"), actionKey(keymap.Assign, "assign"), actionKey(keymap.Return, "return"), bind("←→", "student"), actionKey(keymap.NextUngraded, "next ungraded")}
+	if options.Drive != nil && len(sub.Attachments) > 0 {
+		keys = append(keys, bind("↑↓", "attachment"), actionKey(keymap.Download, "download"), actionKey(keymap.DownloadAll, "all"), actionKey(keymap.Read, "read"))
+	}
+	keys = append(keys, bind("o", "open"), bind("esc", "back"))
+	sections = append(sections, "")
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, keys...))
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// renderStatus renders the state, grades, and lateness of sub on one line.
+func (m *GradingModel) renderStatus(sub *api.StudentSubmission) string {
+	parts := []string{describeEvent(api.HistoryEvent{State: sub.State})}
+	if sub.Late {
+		parts = append(parts, "Late")
+	}
+	if sub.DraftGrade > 0 {
+		parts = append(parts, fmt.Sprintf("Draft %d/%d", sub.DraftGrade, m.courseWork.MaxPoints))
+	}
+	if pendingSync(outbox.KindDraftGrade, sub.ID) {
+		parts = append(parts, "Draft pending sync")
+	}
+	if sub.AssignedGrade > 0 {
+		parts = append(parts, fmt.Sprintf("Grade %d/%d", sub.AssignedGrade, m.courseWork.MaxPoints))
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Render(strings.Join(parts, " · "))
+}
+
+// renderAttachments lists the attachments of sub with the selected one
+// highlighted.
+func (m *GradingModel) renderAttachments(sub *api.StudentSubmission) string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{muted.Render("Attachments:")}
+	if len(sub.Attachments) == 0 {
+		return strings.Join(append(lines, muted.Render("  None")), "\n")
+	}
+	for i, a := range sub.Attachments {
+		line := a.Title
+		if kind := attachmentKinds[a.Kind]; kind != "" {
+			line += muted.Render(" (" + kind + ")")
+		}
+		if i == m.cursor {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true).Render("> ")+line)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
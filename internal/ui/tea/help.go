@@ -0,0 +1,126 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// helpRows is the number of keys per column of the expanded help.
+const helpRows = 6
+
+// helpOverlay shows the keys of the current screen: a footer line that
+// fits the screen's width, or on '?' every key in columns.
+type helpOverlay struct {
+	expanded bool
+}
+
+// screenKeys is a screen's key bindings, in the order they are shown.
+type screenKeys []key.Binding
+
+// ShortHelp returns the footer line's keys.
+func (k screenKeys) ShortHelp() []key.Binding {
+	return append([]key.Binding{bind("?", "more")}, k...)
+}
+
+// FullHelp returns the keys in columns of helpRows.
+func (k screenKeys) FullHelp() [][]key.Binding {
+	var enabled []key.Binding
+	for _, b := range k {
+		if b.Enabled() {
+			enabled = append(enabled, b)
+		}
+	}
+	enabled = append(enabled, bind("?", "less"))
+	var columns [][]key.Binding
+	for len(enabled) > 0 {
+		n := min(helpRows, len(enabled))
+		columns = append(columns, enabled[:n])
+		enabled = enabled[n:]
+	}
+	return columns
+}
+
+// toggle switches between the footer line and the expanded help.
+func (h *helpOverlay) toggle() {
+	h.expanded = !h.expanded
+}
+
+// render renders keys to fit width, which is 0 when unknown.
+func (h helpOverlay) render(width int, keys ...key.Binding) string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	m := help.New()
+	m.Width = max(width-4, 0)
+	m.ShowAll = h.expanded
+	m.ShortSeparator = " | "
+	m.Styles = help.Styles{
+		Ellipsis:       muted,
+		ShortKey:       muted,
+		ShortDesc:      muted,
+		ShortSeparator: muted,
+		FullKey:        lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd")),
+		FullDesc:       lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2")),
+		FullSeparator:  muted,
+	}
+	if !h.expanded {
+		return m.View(screenKeys(keys))
+	}
+	m.Width = max(m.Width-4, 0)
+	title := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6")).Bold(true).Render("Keys")
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6272a4")).
+		Padding(0, 1).
+		Render(title + "\n" + m.View(screenKeys(keys)))
+}
+
+// bind returns a screen's own key, shown as keys.
+func bind(keys, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys), key.WithHelp(keys, desc))
+}
+
+// actionKey returns the binding of an action, described as desc.
+func actionKey(a keymap.Action, desc string) key.Binding {
+	b := boundKeys().Binding(a)
+	return key.NewBinding(key.WithKeys(b.Keys()...), key.WithHelp(b.Help().Key, desc))
+}
+
+// actionPair returns the bindings of two actions as one, such as
+// "↑↓ navigate".
+func actionPair(a, b keymap.Action, desc string) key.Binding {
+	first, second := boundKeys().Binding(a), boundKeys().Binding(b)
+	shown := first.Help().Key + "/" + second.Help().Key
+	if arrows := first.Help().Key + second.Help().Key; arrows == "↑↓" || arrows == "←→" {
+		shown = arrows
+	}
+	return key.NewBinding(key.WithKeys(append(first.Keys(), second.Keys()...)...), key.WithHelp(shown, desc))
+}
+
+// navigateKey returns the binding moving the cursor up and down.
+func navigateKey() key.Binding {
+	return actionPair(keymap.Up, keymap.Down, "navigate")
+}
+
+// backKey returns the binding going back to the previous screen.
+func backKey() key.Binding {
+	return actionKey(keymap.Back, "back")
+}
+
+// quitKey returns the binding quitting the app.
+func quitKey() key.Binding {
+	return actionKey(keymap.Quit, "quit")
+}
+
+// refreshKey returns the binding reloading from the API.
+func refreshKey() key.Binding {
+	return actionKey(keymap.Refresh, "refresh")
+}
+
+// boundKeys returns the configured key map, or the defaults.
+func boundKeys() *keymap.KeyMap {
+	if options.Keys != nil {
+		return options.Keys
+	}
+	return defaultKeys
+}
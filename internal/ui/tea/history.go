@@ -0,0 +1,178 @@
+package tea
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+)
+
+// historyLoadedMsg carries the history of one submission and the names of
+// the people in the course.
+type historyLoadedMsg struct {
+	submissionID string
+	events       []api.HistoryEvent
+	names        map[string]string
+	err          error
+}
+
+// submissionHistory is the history pane of the submissions screen. 'H'
+// loads the selected submission's history, which list calls leave out,
+// and hides it again.
+type submissionHistory struct {
+	submissionID string
+	events       []api.HistoryEvent
+	names        map[string]string
+	loading      bool
+	err          error
+}
+
+// toggle hides the pane when it shows sub, and otherwise loads sub's
+// history.
+func (h *submissionHistory) toggle(client api.ClassroomClient, sub *api.StudentSubmission) tea.Cmd {
+	if sub == nil {
+		return nil
+	}
+	if h.submissionID == sub.ID {
+		*h = submissionHistory{names: h.names}
+		return nil
+	}
+
+	h.submissionID = sub.ID
+	h.events = nil
+	h.loading = true
+	h.err = nil
+	names := h.names
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+
+		full, err := client.GetStudentSubmission(ctx, sub.CourseID, sub.CourseWorkID, sub.ID)
+		if err != nil {
+			return historyLoadedMsg{submissionID: sub.ID, err: err}
+		}
+		if names == nil {
+			names = rosterNames(ctx, client, sub.CourseID)
+		}
+		return historyLoadedMsg{submissionID: sub.ID, events: full.History, names: names}
+	}
+}
+
+// update applies loaded history if it is still for the shown submission.
+func (h *submissionHistory) update(msg historyLoadedMsg) {
+	if msg.submissionID != h.submissionID {
+		return
+	}
+	h.loading = false
+	h.events, h.err = msg.events, msg.err
+	if msg.names != nil {
+		h.names = msg.names
+	}
+}
+
+// render renders the pane for sub, or "" when it shows another
+// submission.
+func (h *submissionHistory) render(sub *api.StudentSubmission) string {
+	if sub == nil || h.submissionID != sub.ID {
+		return ""
+	}
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render("History: " + h.name(sub.UserID))}
+
+	switch {
+	case h.loading:
+		lines = append(lines, muted.Render("Loading history..."))
+	case h.err != nil:
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(h.err)))
+	case len(h.events) == 0:
+		lines = append(lines, muted.Render("No changes yet"))
+	}
+	for _, e := range h.events {
+		line := fmt.Sprintf("%-13s %s", historyTime(e.Time), describeEvent(e))
+		if e.ActorUserID != "" {
+			line += " by " + h.name(e.ActorUserID)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// name returns a user's full name, or their ID when it is unknown.
+func (h *submissionHistory) name(userID string) string {
+	if name := h.names[userID]; name != "" {
+		return name
+	}
+	return userID
+}
+
+// rosterNames maps the course's students and teachers to their names. A
+// roster the user cannot read leaves those people shown by ID.
+func rosterNames(ctx context.Context, client api.ClassroomClient, courseID string) map[string]string {
+	names := make(map[string]string)
+	if students, err := client.ListStudents(ctx, courseID, nil); err == nil {
+		for _, s := range students {
+			names[s.UserID] = s.Profile.Name
+		}
+	}
+	if teachers, err := client.ListTeachers(ctx, courseID, nil); err == nil {
+		for _, t := range teachers {
+			names[t.UserID] = t.Profile.Name
+		}
+	}
+	return names
+}
+
+// describeEvent describes a history event in words.
+func describeEvent(e api.HistoryEvent) string {
+	switch e.State {
+	case api.SubmissionStateCreated:
+		return "Created"
+	case api.SubmissionStateTurnedIn:
+		return "Turned in"
+	case api.SubmissionStateReturned:
+		return "Returned"
+	case api.SubmissionStateReclaimed:
+		return "Unsubmitted"
+	case api.SubmissionStateEdited:
+		return "Answer edited after turn-in"
+	case "":
+	default:
+		return e.State
+	}
+
+	grade := formatPoints(e.PointsEarned) + "/" + formatPoints(e.MaxPoints)
+	switch e.GradeChange {
+	case api.GradeChangeDraft:
+		return "Draft grade " + grade
+	case api.GradeChangeAssigned:
+		return "Graded " + grade
+	case api.GradeChangeMaxPoints:
+		return "Out of " + formatPoints(e.MaxPoints) + " points"
+	}
+	return e.GradeChange
+}
+
+// historyTime formats an event time in local time.
+func historyTime(stamp string) string {
+	return formatTime(stamp, "Jan 2 15:04")
+}
+
+// formatTime formats an RFC 3339 timestamp from the API in local time
+// with layout. A stamp that does not parse, such as an empty one, is
+// shown as it is.
+func formatTime(stamp, layout string) string {
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return stamp
+	}
+	return t.Local().Format(layout)
+}
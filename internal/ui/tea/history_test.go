@@ -0,0 +1,36 @@
+package tea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// TestFormatTime tests that timestamps are shown in local time and that
+// short or malformed ones are shown as they are instead of panicking.
+func TestFormatTime(t *testing.T) {
+	stamp := "2024-03-01T10:30:00.123Z"
+	at, _ := time.Parse(time.RFC3339Nano, stamp)
+
+	tests := []struct {
+		stamp string
+		want  string
+	}{
+		{stamp: stamp, want: at.Local().Format(time.DateOnly)},
+		{stamp: "", want: ""},
+		{stamp: "2024-03", want: "2024-03"},
+		{stamp: "not a time", want: "not a time"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTime(tt.stamp, time.DateOnly); got != tt.want {
+			t.Errorf("formatTime(%q): expected %q, got %q", tt.stamp, tt.want, got)
+		}
+	}
+
+	item := AnnouncementItem{announcement: &api.Announcement{CreatorUserID: "t1"}}
+	if got := item.Description(); got != "t1 | " {
+		t.Errorf("Expected an announcement without a time to render, got %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package tea
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/auth"
+)
+
+// UserFromIdentity converts the identity stored with the token for
+// Options.User. A nil identity stays nil.
+func UserFromIdentity(id *auth.Identity) *api.UserProfile {
+	if id == nil {
+		return nil
+	}
+	return &api.UserProfile{ID: id.ID, Name: id.Name, EmailAddress: id.Email}
+}
+
+// userLoadedMsg carries the signed-in user's profile.
+type userLoadedMsg struct {
+	user *api.UserProfile
+}
+
+// loadUser asks Classroom who is signed in when Options.User is not set.
+// Failing to find out only hides the account and "you" markers, so errors
+// are dropped.
+func loadUser(client api.ClassroomClient) tea.Cmd {
+	if options.User != nil {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		user, err := client.GetUserProfile(ctx, "me")
+		if err != nil {
+			return nil
+		}
+		return userLoadedMsg{user: user}
+	}
+}
+
+// isMe reports whether userID is the signed-in user. It is always false
+// while the user is unknown.
+func isMe(userID string) bool {
+	return options.User != nil && userID != "" && userID == options.User.ID
+}
+
+// rosterName returns a roster member's name, marking the signed-in user.
+func rosterName(userID, name string) string {
+	if isMe(userID) {
+		return name + " (you)"
+	}
+	return name
+}
+
+// assignedToMe reports whether coursework is assigned to the signed-in
+// user: posts for the whole class are, individual ones only when the user
+// is among their students.
+func assignedToMe(cw *api.CourseWork) bool {
+	if !cw.IsIndividual() {
+		return true
+	}
+	return options.User != nil && slices.Contains(cw.StudentIDs, options.User.ID)
+}
+
+// accountLabel names the signed-in user for the status line, or "" while
+// the user is unknown.
+func accountLabel() string {
+	if options.User == nil {
+		return ""
+	}
+	switch {
+	case options.User.Name != "":
+		return options.User.Name
+	case options.User.EmailAddress != "":
+		return options.User.EmailAddress
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// defaultKeys are the bindings used while Options.Keys is nil.
+var defaultKeys = keymap.Default()
+
+// bound returns the keys bound to an action. Screens match them with
+// key.Matches, so a key the user binds to an action runs it, and a
+// default key bound elsewhere no longer does. Only the keys a screen
+// keeps, such as ? for help and the recovery keys, and text inputs and
+// prompts read msg.String() directly.
+func bound(a keymap.Action) key.Binding {
+	return boundKeys().Binding(a)
+}
+
+// tableKeyMap returns the table key map with the bound navigation keys.
+func tableKeyMap() table.KeyMap {
+	km := table.DefaultKeyMap()
+	if options.Keys != nil {
+		km.LineUp = withHelp(options.Keys.Binding(keymap.Up))
+		km.LineDown = withHelp(options.Keys.Binding(keymap.Down))
+	}
+	return km
+}
+
+// setListKeys binds a list's cursor to the bound navigation keys.
+func setListKeys(l *list.Model) {
+	if options.Keys == nil {
+		return
+	}
+	l.KeyMap.CursorUp = withHelp(options.Keys.Binding(keymap.Up))
+	l.KeyMap.CursorDown = withHelp(options.Keys.Binding(keymap.Down))
+}
+
+// withHelp copies a binding, so bubbles components that disable or rebind
+// theirs do not change the shared key map.
+func withHelp(b key.Binding) key.Binding {
+	return key.NewBinding(key.WithKeys(b.Keys()...), key.WithHelp(b.Help().Key, b.Help().Desc))
+}
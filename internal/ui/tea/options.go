@@ -0,0 +1,132 @@
+package tea
+
+import (
+	"context"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/calendar"
+	"github.com/user/google-classroom/internal/api/drive"
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/checklist"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/connectivity"
+	"github.com/user/google-classroom/internal/focus"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/outbox"
+	"github.com/user/google-classroom/internal/readstate"
+	"github.com/user/google-classroom/internal/schedule"
+	"github.com/user/google-classroom/internal/translate"
+	"github.com/user/google-classroom/internal/usage"
+)
+
+// Options holds user settings that affect how screens load and render data.
+type Options struct {
+	// ShowDeletedCourseWork shows deleted coursework as tombstones for auditing.
+	ShowDeletedCourseWork bool
+	// UndoWindow is how long deletions can be undone before they are sent.
+	UndoWindow time.Duration
+	// Confirm decides which actions ask for confirmation. Nil confirms
+	// everything.
+	Confirm *confirm.Policy
+	// Schedule holds course meeting times; the course list puts the class
+	// in session or meeting next first.
+	Schedule schedule.Schedule
+	// Connectivity tracks whether the API is reachable. Nil assumes the app
+	// is always online.
+	Connectivity *connectivity.Monitor
+	// Cache serves reads from disk while fresh; 'r' bypasses it. Nil
+	// always loads from the API.
+	Cache *cache.Cache
+	// Prefetch warms the cache for every active course in the background
+	// once the course list has loaded, so opening a course is instant.
+	// It needs Cache.
+	Prefetch bool
+	// Outbox stores write actions made while offline so they survive a
+	// restart. Nil keeps them in memory only.
+	Outbox *outbox.Outbox
+	// Usage counts API calls against the daily budget; screens warn when
+	// most of it is used. Nil means no budget.
+	Usage *usage.Meter
+	// Stats reports the API client's request counts for the status line,
+	// usually api.Client.Stats. Nil hides the line.
+	Stats func() api.Stats
+	// Translator translates announcements and descriptions on 'T'. Nil
+	// disables translation.
+	Translator *translate.Translator
+	// Checklists holds students' local subtasks for assignments. Nil
+	// disables checklists.
+	Checklists *checklist.Store
+	// Focus logs time spent in focus timers against assignments. Nil
+	// disables the timer.
+	Focus *focus.Log
+	// FocusTimer sets the focus and break lengths.
+	FocusTimer focus.Config
+	// ReadState remembers when each course's stream was last seen, to
+	// mark newer posts unread. Nil marks nothing unread.
+	ReadState *readstate.Store
+	// Keys binds shared actions such as navigation and refresh, and screen
+	// actions such as turning in, to the keys the user configured. Nil
+	// keeps the default keys.
+	Keys *keymap.KeyMap
+	// Drive downloads attachments on 'd'. Nil disables downloads.
+	Drive *drive.Client
+	// DownloadDir is where downloaded attachments are saved.
+	DownloadDir string
+	// Calendar syncs a course's due dates on 's'. Nil disables calendar
+	// sync.
+	Calendar *calendar.Client
+	// CalendarTarget selects the calendar due dates are synced to.
+	CalendarTarget calendar.Target
+
+	// Login runs the login flow in the given mode; the session expired
+	// screen offers it for auth errors without leaving the app.
+	Login func(ctx context.Context, mode auth.LoginMode) error
+	// GrantedScopes asks which scopes the token carries, usually
+	// auth.Authenticator.GrantedScopes. With RequestScopes it lets a
+	// feature ask for its scope the first time it is used; when either is
+	// nil every scope is assumed granted.
+	GrantedScopes func(ctx context.Context) ([]string, error)
+	// RequestScopes runs incremental consent for scopes, usually
+	// auth.Authenticator.RequestScopes. Like Login, it should call
+	// TokenManager.Reload.
+	RequestScopes func(ctx context.Context, scopes []string) error
+	// Reauth reports when the token can no longer be refreshed, usually
+	// auth.TokenManager.ReauthRequired. Screens then offer Login instead of
+	// failing request by request. Login should call TokenManager.Reload.
+	Reauth <-chan error
+	// ConfigPath is the configuration file opened for configuration errors.
+	ConfigPath string
+	// User is the signed-in user, usually from auth.Authenticator.Identity.
+	// It marks the user's own submissions and roster entries and is shown
+	// in the status line. Nil looks the user up when the course list loads.
+	User *api.UserProfile
+}
+
+// options is the active set of user settings.
+var options Options
+
+// SetOptions sets the user settings used by all screens.
+func SetOptions(o Options) {
+	options = o
+	connectivityUpdates = nil
+	if o.Connectivity != nil {
+		connectivityUpdates = o.Connectivity.Subscribe()
+	}
+	reauthUpdates = o.Reauth
+}
+
+// loadContext returns the context for a load command. A refresh skips the
+// cache so 'r' always shows current data. Cancelling it marks the load
+// finished for the status bar.
+func loadContext(refresh bool) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if refresh {
+		ctx = cache.WithForceRefresh(ctx)
+	}
+	return ctx, func() {
+		cancel()
+		markLoaded()
+	}
+}
@@ -0,0 +1,117 @@
+package tea
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/connectivity"
+	"github.com/user/google-classroom/internal/outbox"
+)
+
+// outboxSyncedMsg is sent after queued offline actions were replayed.
+type outboxSyncedMsg struct {
+	result *outbox.Result
+	err    error
+}
+
+// syncOutbox replays actions queued while offline.
+func syncOutbox(client api.ClassroomClient) tea.Cmd {
+	if options.Outbox == nil || options.Outbox.Len() == 0 {
+		return nil
+	}
+	return inBackground(func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		result, err := options.Outbox.Replay(ctx, client)
+		reportError(err)
+		return outboxSyncedMsg{result: result, err: err}
+	})
+}
+
+// queueOffline holds an action until the connection returns. With an outbox
+// configured it is stored durably as entry; otherwise fn is kept in memory
+// by the connectivity monitor. It reports whether the action was queued.
+func queueOffline(entry outbox.Entry, fn func(ctx context.Context) error) bool {
+	if options.Outbox != nil {
+		_, err := options.Outbox.Add(entry)
+		return err == nil
+	}
+	if options.Connectivity != nil {
+		options.Connectivity.Queue(fn)
+		return true
+	}
+	return false
+}
+
+// deferIfOffline queues an action when err shows the API could not be
+// reached. It reports whether the action was queued.
+func deferIfOffline(err error, entry outbox.Entry, fn func(ctx context.Context) error) bool {
+	if !connectivity.IsOffline(err) {
+		return false
+	}
+	reportError(err)
+	return queueOffline(entry, fn)
+}
+
+// pendingSync reports whether an action of kind is queued for id.
+func pendingSync(kind outbox.Kind, id string) bool {
+	return options.Outbox != nil && options.Outbox.Has(kind, id)
+}
+
+// pendingSyncCount returns the number of actions waiting for the connection.
+func pendingSyncCount() int {
+	n := 0
+	if options.Outbox != nil {
+		n += options.Outbox.Len()
+	}
+	if options.Connectivity != nil {
+		n += options.Connectivity.Pending()
+	}
+	return n
+}
+
+// withoutQueued returns items minus those with a queued action of kind.
+func withoutQueued[T any](items []T, kind outbox.Kind, id func(T) string) []T {
+	if options.Outbox == nil || options.Outbox.Len() == 0 {
+		return items
+	}
+	kept := make([]T, 0, len(items))
+	for _, item := range items {
+		if !options.Outbox.Has(kind, id(item)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// renderSyncResult summarizes a replay, or returns "" when nothing happened.
+func renderSyncResult(msg outboxSyncedMsg) string {
+	if msg.result == nil {
+		return ""
+	}
+
+	var parts []string
+	if n := len(msg.result.Applied); n > 0 {
+		parts = append(parts, fmt.Sprintf("Synced %d queued change(s)", n))
+	}
+	for _, s := range msg.result.Skipped {
+		parts = append(parts, fmt.Sprintf("Skipped %s: %s", s.Entry.Label, s.Reason))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	color := "#f1fa8c"
+	if len(msg.result.Skipped) > 0 {
+		color = "#ff5555"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(color)).
+		Render(strings.Join(parts, " | "))
+}
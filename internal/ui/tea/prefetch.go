@@ -0,0 +1,63 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+)
+
+// prefetchTimeout bounds the background prefetch, which walks every
+// active course.
+const prefetchTimeout = 10 * time.Minute
+
+// prefetchStarted is set once the background prefetch has run, so it runs
+// once per session however often the course list reloads.
+var prefetchStarted bool
+
+// prefetchDoneMsg carries the outcome of the background prefetch.
+type prefetchDoneMsg struct {
+	result *cache.PrefetchResult
+	err    error
+}
+
+// prefetchCourses warms the cache for every active course in the
+// background when Options.Prefetch is set. It is skipped while offline and
+// stops while the daily API budget is being conserved.
+func prefetchCourses(client api.ClassroomClient) tea.Cmd {
+	cc, ok := client.(*cache.CachedClient)
+	if !options.Prefetch || prefetchStarted || !ok || cc.Cache() == nil || isOffline() {
+		return nil
+	}
+	prefetchStarted = true
+	return inBackground(func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		defer cancel()
+		result, err := cache.Prefetch(ctx, cc, &cache.PrefetchOptions{
+			IncludeDeleted: options.ShowDeletedCourseWork,
+			Paused:         func() bool { return options.Usage.Conserving() || isOffline() },
+		})
+		return prefetchDoneMsg{result: result, err: err}
+	})
+}
+
+// renderPrefetchResult describes a finished prefetch, or "" when it was
+// paused or cached nothing.
+func renderPrefetchResult(msg prefetchDoneMsg) string {
+	if errors.Is(msg.err, cache.ErrPrefetchPaused) || (msg.err == nil && msg.result.Courses == 0) {
+		return ""
+	}
+	if msg.err != nil {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render("Could not cache courses: " + errorText(msg.err))
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6272a4")).
+		Render(fmt.Sprintf("✓ %d course(s) cached for quick browsing", msg.result.Courses))
+}
@@ -0,0 +1,258 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/auth"
+	apperrors "github.com/user/google-classroom/internal/errors"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// recoveryKeys maps recovery actions to the keys that run them from an error screen.
+var recoveryKeys = map[apperrors.RecoveryAction]string{
+	apperrors.ActionRetry:       "r",
+	apperrors.ActionLogin:       "L",
+	apperrors.ActionOpenConfig:  "C",
+	apperrors.ActionGrantScopes: "P",
+}
+
+// recoveryAction returns the recovery action offered for an error.
+func recoveryAction(err error) apperrors.RecoveryAction {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return appErr.Action()
+	}
+	return apperrors.ActionRetry
+}
+
+// loginKeys maps the keys on the session expired screen to login modes.
+var loginKeys = map[string]auth.LoginMode{
+	"L": auth.LoginBrowser,
+	"D": auth.LoginDevice,
+}
+
+// recoverFromError runs the recovery action bound to key, if the error offers it.
+// Retry is handled by each screen's own refresh, so only login, config,
+// and permission actions are run here.
+func recoverFromError(err error, key string) tea.Cmd {
+	action := recoveryAction(err)
+	mode, isLoginKey := loginKeys[key]
+	if recoveryKeys[action] != key && !(action == apperrors.ActionLogin && isLoginKey) {
+		return nil
+	}
+
+	switch action {
+	case apperrors.ActionLogin:
+		if options.Login == nil {
+			return nil
+		}
+		return tea.Exec(&loginCommand{login: options.Login, mode: mode}, func(err error) tea.Msg {
+			if err != nil {
+				// Stay on the session expired screen so another login can be tried
+				err = apperrors.Wrap(err, apperrors.ErrAuth, "login failed").
+					WithSuggestion(loginFailure(err))
+			}
+			return recoveryDoneMsg{action: action, err: err}
+		})
+	case apperrors.ActionOpenConfig:
+		if options.ConfigPath == "" {
+			return nil
+		}
+		return tea.ExecProcess(editorCommand(options.ConfigPath), func(err error) tea.Msg {
+			return recoveryDoneMsg{action: action, err: err}
+		})
+	case apperrors.ActionGrantScopes:
+		if !recoveryAvailable(action) {
+			return nil
+		}
+		return grantMissingScopes()
+	}
+	return nil
+}
+
+// loginFailure explains a failed login on the session expired screen,
+// which offers to log in again.
+func loginFailure(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Login cancelled. Log in again when you are ready."
+	case errors.Is(err, auth.ErrLoginTimeout):
+		return "Login timed out before access was approved. Try again."
+	case errors.Is(err, auth.ErrLoginDenied):
+		return "Access was not granted. Try again and allow access to continue."
+	}
+	return "Login failed: " + err.Error()
+}
+
+// renderErrorView renders a full-screen error with its suggestion and recovery key.
+func renderErrorView(title string, err error, width, height int) string {
+	if recoveryAction(err) == apperrors.ActionLogin && recoveryAvailable(apperrors.ActionLogin) {
+		return renderAuthExpiredView(err, width, height)
+	}
+	message := err.Error()
+	hint := fmt.Sprintf("Press '%s' to retry", bound(keymap.Refresh).Help().Key)
+
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		message = appErr.UserMessage()
+		hint = appErr.GetSuggestion()
+		action := appErr.Action()
+		if key, ok := recoveryKeys[action]; ok && recoveryAvailable(action) {
+			hint = fmt.Sprintf("Press '%s' to %s", key, action)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		Align(lipgloss.Center).
+		Render(
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#ff5555")).
+					Bold(true).
+					Render(title),
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#f8f8f2")).
+					Render(message),
+				"",
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#6272a4")).
+					Render(hint),
+			),
+		)
+}
+
+// renderAuthExpiredView renders the screen shown when the session has
+// expired: it offers both login flows, which run without leaving the app,
+// and the screen reloads in place afterwards.
+func renderAuthExpiredView(err error, width, height int) string {
+	message := err.Error()
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		message = appErr.UserMessage()
+		if appErr.UserSuggestion != "" {
+			message += "\n" + appErr.UserSuggestion
+		}
+	}
+
+	key := lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true)
+	text := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+	choices := lipgloss.JoinVertical(lipgloss.Left,
+		key.Render("L")+text.Render("  Log in with a browser"),
+		key.Render("D")+text.Render("  Log in with a code on another device (SSH, no browser)"),
+	)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		Align(lipgloss.Center).
+		Render(
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#ffb86c")).
+					Bold(true).
+					Render("Session expired"),
+				text.Render(message),
+				"",
+				choices,
+				"",
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#6272a4")).
+					Render("You will return to this screen once you are logged in."),
+			),
+		)
+}
+
+// errorText returns a one-line message for an error shown inline, using the
+// typed error's user message and any specific suggestion.
+func errorText(err error) string {
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		return err.Error()
+	}
+	if appErr.UserSuggestion != "" {
+		return appErr.UserMessage() + " " + appErr.UserSuggestion
+	}
+	return appErr.UserMessage()
+}
+
+// recoveryHint returns the key offered for err where it is shown inline
+// beside data that did load, such as "r to retry".
+func recoveryHint(err error) string {
+	action := recoveryAction(err)
+	if key, ok := recoveryKeys[action]; ok && recoveryAvailable(action) {
+		return fmt.Sprintf("%s to %s", key, action)
+	}
+	return bound(keymap.Refresh).Help().Key + " to retry"
+}
+
+// recoveryAvailable reports whether the app is able to run the action itself.
+func recoveryAvailable(action apperrors.RecoveryAction) bool {
+	switch action {
+	case apperrors.ActionLogin:
+		return options.Login != nil
+	case apperrors.ActionOpenConfig:
+		return options.ConfigPath != ""
+	case apperrors.ActionGrantScopes:
+		return options.GrantedScopes != nil && options.RequestScopes != nil
+	default:
+		return true
+	}
+}
+
+// editorCommand returns a command that opens path in the user's editor.
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	return exec.Command(editor, path)
+}
+
+// loginCommand runs the login flow while the TUI has released the terminal.
+type loginCommand struct {
+	login func(ctx context.Context, mode auth.LoginMode) error
+	mode  auth.LoginMode
+}
+
+// Run performs the login. The login flow applies its own timeout, and
+// Ctrl+C cancels it and returns to the app rather than quitting.
+func (c *loginCommand) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return c.login(ctx, c.mode)
+}
+
+// SetStdin is part of tea.ExecCommand; a login that asks for a pasted code
+// reads the terminal directly.
+func (c *loginCommand) SetStdin(io.Reader) {}
+
+// SetStdout is part of tea.ExecCommand; the login flow writes to the terminal directly.
+func (c *loginCommand) SetStdout(io.Writer) {}
+
+// SetStderr is part of tea.ExecCommand.
+func (c *loginCommand) SetStderr(io.Writer) {}
+
+// recoveryDoneMsg is sent when a recovery action finishes.
+type recoveryDoneMsg struct {
+	action apperrors.RecoveryAction
+	err    error
+}
@@ -0,0 +1,128 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/user/google-classroom/internal/auth"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// recoveryErrors are errors offering each recovery action.
+var recoveryErrors = map[apperrors.RecoveryAction]error{
+	apperrors.ActionRetry:       errors.New("connection reset"),
+	apperrors.ActionLogin:       apperrors.New(apperrors.ErrAuthExpired, "session expired"),
+	apperrors.ActionOpenConfig:  apperrors.New(apperrors.ErrConfig, "invalid configuration"),
+	apperrors.ActionGrantScopes: apperrors.New(apperrors.ErrAPIForbidden, "permission missing").WithAction(apperrors.ActionGrantScopes),
+}
+
+// withRecoveryOptions sets options able to run every recovery action, or
+// none of them.
+func withRecoveryOptions(t *testing.T, available bool) {
+	t.Helper()
+	o := Options{}
+	if available {
+		o.Login = func(context.Context, auth.LoginMode) error { return nil }
+		o.ConfigPath = "config.json"
+		o.GrantedScopes = func(context.Context) ([]string, error) { return nil, nil }
+		o.RequestScopes = func(context.Context, []string) error { return nil }
+	}
+	SetOptions(o)
+	t.Cleanup(func() { SetOptions(Options{}) })
+}
+
+// TestRecoveryAction tests the action offered for each kind of error.
+func TestRecoveryAction(t *testing.T) {
+	for want, err := range recoveryErrors {
+		if got := recoveryAction(err); got != want {
+			t.Errorf("%v: expected %v, got %v", err, want, got)
+		}
+	}
+	wrapped := apperrors.Wrap(recoveryErrors[apperrors.ActionLogin], apperrors.ErrAuth, "failed to load courses")
+	if got := recoveryAction(wrapped); got != apperrors.ActionLogin {
+		t.Errorf("Expected a wrapped auth error to offer login, got %v", got)
+	}
+}
+
+// TestRecoverFromError tests that each action runs only from its key, and
+// is refused when the app cannot run it.
+func TestRecoverFromError(t *testing.T) {
+	tests := []struct {
+		action apperrors.RecoveryAction
+		key    string
+		want   bool // with every option set
+	}{
+		{apperrors.ActionLogin, "L", true},
+		{apperrors.ActionLogin, "D", true},
+		{apperrors.ActionLogin, "C", false},
+		{apperrors.ActionLogin, "P", false},
+		{apperrors.ActionOpenConfig, "C", true},
+		{apperrors.ActionOpenConfig, "L", false},
+		{apperrors.ActionOpenConfig, "D", false},
+		{apperrors.ActionGrantScopes, "P", true},
+		{apperrors.ActionGrantScopes, "L", false},
+		// Retry is left to each screen's refresh
+		{apperrors.ActionRetry, "r", false},
+		{apperrors.ActionRetry, "L", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action.String()+" "+tt.key, func(t *testing.T) {
+			err := recoveryErrors[tt.action]
+
+			withRecoveryOptions(t, true)
+			if got := recoverFromError(err, tt.key) != nil; got != tt.want {
+				t.Errorf("Expected a command %v, got %v", tt.want, got)
+			}
+
+			withRecoveryOptions(t, false)
+			if cmd := recoverFromError(err, tt.key); cmd != nil {
+				t.Error("Expected no command without the option that runs it")
+			}
+		})
+	}
+}
+
+// TestRenderErrorView tests the hint shown for each action, and that a
+// key is only offered when pressing it does something.
+func TestRenderErrorView(t *testing.T) {
+	tests := []struct {
+		action      apperrors.RecoveryAction
+		available   bool
+		want        string
+		notExpected string
+	}{
+		{apperrors.ActionRetry, true, "Press 'r' to retry", ""},
+		{apperrors.ActionLogin, true, "Session expired", ""},
+		{apperrors.ActionLogin, false, "auth login", "Session expired"},
+		{apperrors.ActionOpenConfig, true, "Press 'C' to edit the configuration", ""},
+		{apperrors.ActionOpenConfig, false, "Press 'r' to retry", "Press 'C'"},
+		{apperrors.ActionGrantScopes, true, "Press 'P' to grant the missing permission", ""},
+		{apperrors.ActionGrantScopes, false, "Press 'r' to retry", "Press 'P'"},
+	}
+
+	for _, tt := range tests {
+		withRecoveryOptions(t, tt.available)
+		view := renderErrorView("Error loading courses", recoveryErrors[tt.action], 100, 20)
+		if !strings.Contains(view, tt.want) {
+			t.Errorf("%v (available %v): expected %q, got %q", tt.action, tt.available, tt.want, view)
+		}
+		if tt.notExpected != "" && strings.Contains(view, tt.notExpected) {
+			t.Errorf("%v (available %v): expected no %q, got %q", tt.action, tt.available, tt.notExpected, view)
+		}
+	}
+}
+
+// TestRecoveryHint tests the inline hint beside data that did load.
+func TestRecoveryHint(t *testing.T) {
+	withRecoveryOptions(t, true)
+	if got := recoveryHint(recoveryErrors[apperrors.ActionOpenConfig]); got != "C to edit the configuration" {
+		t.Errorf("Expected the config key, got %q", got)
+	}
+	withRecoveryOptions(t, false)
+	if got := recoveryHint(recoveryErrors[apperrors.ActionOpenConfig]); got != "r to retry" {
+		t.Errorf("Expected retry when the config cannot be opened, got %q", got)
+	}
+}
@@ -0,0 +1,297 @@
+package tea
+
+import (
+	"reflect"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/auth"
+)
+
+// Router is the root model. It keeps the open screens on a stack: each
+// navigation message, such as CourseSelectedMsg, pushes the screen it
+// opens, and NavigateBackMsg pops back to the screen below, which keeps
+// its state, such as the selected row and scroll position, as it was left.
+//
+// Keys go to the screen on top, as do connection and session changes,
+// which every screen answers by watching for the next one. The results of
+// a screen's commands, such as loads, go back to that screen only, so a
+// screen that was still loading when another was opened over it is ready
+// when it is revealed, and the screens above it never see its results.
+type Router struct {
+	stack     []screen
+	lastID    int
+	apiClient api.ClassroomClient
+	auth      *auth.Authenticator
+	// size is the last window size, given to screens as they open.
+	size *tea.WindowSizeMsg
+}
+
+// screen is an open screen and the ID its commands' results carry.
+type screen struct {
+	id    int
+	model tea.Model
+}
+
+// screenMsg is the result of a command run by the screen with id. model
+// is kept so a result that arrives after the screen closed still reaches
+// it.
+type screenMsg struct {
+	id    int
+	model tea.Model
+	msg   tea.Msg
+}
+
+// NewRouter creates a router showing root, usually the course list or the
+// setup screen. a opens the account screen; nil disables it.
+func NewRouter(root tea.Model, apiClient api.ClassroomClient, a *auth.Authenticator) *Router {
+	r := &Router{apiClient: apiClient, auth: a}
+	r.stack = []screen{r.open(root)}
+	return r
+}
+
+// Init initializes the root screen.
+func (r *Router) Init() tea.Cmd {
+	return r.init(r.stack[0])
+}
+
+// Depth returns the number of open screens.
+func (r *Router) Depth() int {
+	return len(r.stack)
+}
+
+// top returns the entry of the screen on top.
+func (r *Router) top() screen {
+	return r.stack[len(r.stack)-1]
+}
+
+// open gives model an ID.
+func (r *Router) open(model tea.Model) screen {
+	r.lastID++
+	return screen{id: r.lastID, model: model}
+}
+
+// Push opens model over the current screen.
+func (r *Router) Push(model tea.Model) tea.Cmd {
+	s, cmd := r.resize(r.open(model))
+	r.stack = append(r.stack, s)
+	return tea.Batch(r.init(s), cmd)
+}
+
+// Pop closes the screen on top, revealing the one below as it was left.
+// The root screen is never closed.
+func (r *Router) Pop() tea.Cmd {
+	if len(r.stack) > 1 {
+		r.stack[len(r.stack)-1] = screen{}
+		r.stack = r.stack[:len(r.stack)-1]
+	}
+	return nil
+}
+
+// Replace swaps the screen on top for model.
+func (r *Router) Replace(model tea.Model) tea.Cmd {
+	s, cmd := r.resize(r.open(model))
+	r.stack[len(r.stack)-1] = s
+	return tea.Batch(r.init(s), cmd)
+}
+
+// init initializes s.
+func (r *Router) init(s screen) tea.Cmd {
+	return tag(s, s.model.Init())
+}
+
+// resize gives s the window size before it first renders.
+func (r *Router) resize(s screen) (screen, tea.Cmd) {
+	if r.size == nil {
+		return s, nil
+	}
+	return r.update(s, *r.size)
+}
+
+// update passes msg to s, tagging the command it returns with s.
+func (r *Router) update(s screen, msg tea.Msg) (screen, tea.Cmd) {
+	var cmd tea.Cmd
+	s.model, cmd = s.model.Update(msg)
+	return s, tag(s, cmd)
+}
+
+// Update routes a message.
+func (r *Router) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// from is the screen that sent msg; it is 0 for keys and messages
+	// from outside, which go to the screen on top.
+	from := 0
+	if sm, ok := msg.(screenMsg); ok {
+		from, msg = sm.id, sm.msg
+		if !r.isOpen(from) {
+			return r, r.closed(sm)
+		}
+	}
+	fromTop := from == 0 || from == r.top().id
+
+	switch msg := msg.(type) {
+	case NavigateBackMsg:
+		if fromTop {
+			return r, r.Pop()
+		}
+		return r, nil
+
+	case SetupDoneMsg:
+		return r, r.Replace(NewCourseListModel(r.apiClient))
+
+	case tea.KeyMsg, tea.MouseMsg, connectivityMsg, reauthMsg, recoveryDoneMsg:
+		from = 0
+
+	case tea.WindowSizeMsg:
+		r.size = &msg
+		cmds := make([]tea.Cmd, len(r.stack))
+		for i, s := range r.stack {
+			r.stack[i], cmds[i] = r.update(s, msg)
+		}
+		return r, tea.Batch(cmds...)
+
+	case toastMsg, toastExpiredMsg:
+		return r, updateToasts(msg)
+	}
+
+	if model := r.screenFor(msg); model != nil {
+		if fromTop {
+			return r, r.Push(model)
+		}
+		return r, nil
+	}
+
+	i := len(r.stack) - 1
+	if from != 0 {
+		i = r.index(from)
+	}
+	var cmd tea.Cmd
+	r.stack[i], cmd = r.update(r.stack[i], msg)
+	return r, cmd
+}
+
+// isOpen reports whether the screen with id is on the stack.
+func (r *Router) isOpen(id int) bool {
+	return r.index(id) >= 0
+}
+
+// index returns the position of the screen with id on the stack, or -1.
+func (r *Router) index(id int) int {
+	for i, s := range r.stack {
+		if s.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// closed passes a result to the screen it was for after that screen has
+// closed, so a result it reports as a toast still shows. The commands it
+// returns cannot be told apart before they run, so they still run, but
+// only their toasts are kept: a reload's result or the next timer tick is
+// dropped, which ends the screen's watches.
+func (r *Router) closed(sm screenMsg) tea.Cmd {
+	switch sm.msg.(type) {
+	case toastMsg, toastExpiredMsg:
+		return updateToasts(sm.msg)
+	}
+	_, cmd := sm.model.Update(sm.msg)
+	return toastsOnly(cmd)
+}
+
+// tag returns cmd with its result tagged with s, so it reaches s alone.
+// Batches are tagged command by command; Bubble Tea's own messages, such
+// as the one tea.ExecProcess returns, are passed on as they are.
+func tag(s screen, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = tag(s, c)
+			}
+			return cmds
+		default:
+			if runtimeMsg(msg) {
+				return msg
+			}
+			return screenMsg{id: s.id, model: s.model, msg: msg}
+		}
+	}
+}
+
+// toastsOnly returns cmd keeping only the toasts among its results.
+func toastsOnly(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case toastMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = toastsOnly(c)
+			}
+			return cmds
+		}
+		return nil
+	}
+}
+
+// runtimeMsg reports whether msg is one of Bubble Tea's own messages,
+// which the program handles rather than a screen.
+func runtimeMsg(msg tea.Msg) bool {
+	return reflect.TypeOf(msg).PkgPath() == reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+}
+
+// screenFor returns the screen a navigation message opens, or nil for
+// other messages.
+func (r *Router) screenFor(msg tea.Msg) tea.Model {
+	client := r.apiClient
+	switch msg := msg.(type) {
+	case CourseSelectedMsg:
+		return NewCourseDetailModel(msg.Course, client)
+	case CourseWorkSelectedMsg:
+		return NewCourseWorkDetailModel(msg.Course, msg.CourseWork, client)
+	case CourseWorkDetailMsg:
+		return NewCourseWorkDetailModel(msg.Course, msg.CourseWork, client)
+	case SubmissionListMsg:
+		return NewSubmissionModel(msg.Course, msg.CourseWork, client)
+	case SubmissionDetailMsg:
+		return NewSubmissionDetailModel(msg.Course, msg.CourseWork, msg.Submission, client)
+	case OpenGradingMsg:
+		return NewGradingModel(msg.Course, msg.CourseWork, msg.Submissions, client)
+	case AnnouncementSelectedMsg:
+		return NewAnnouncementViewModel(msg.Course, msg.Announcement, client)
+	case StudentSelectedMsg:
+		return NewStudentProfileModel(msg.Course, msg.Student, client)
+	case AttendanceMsg:
+		return NewAttendanceModel(msg.Course, client)
+	case ChecklistMsg:
+		return NewChecklistModel(msg.Course, msg.CourseWork)
+	case FocusMsg:
+		return NewFocusModel(msg.Course, msg.CourseWork)
+	case OpenAgendaMsg:
+		return NewAgendaModel(client, msg.Courses)
+	case OpenGlobalSearchMsg:
+		return NewGlobalSearchModel()
+	case OpenCacheMsg:
+		return NewCacheModel(msg.CourseNames)
+	case OpenAuthStatusMsg:
+		if r.auth != nil {
+			return NewAuthStatusModel(r.auth)
+		}
+	}
+	return nil
+}
+
+// View renders the screen on top.
+func (r *Router) View() string {
+	return r.top().model.View()
+}
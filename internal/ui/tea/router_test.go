@@ -0,0 +1,189 @@
+package tea
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// loadedMsg stands in for a screen's load result.
+type loadedMsg struct{}
+
+// fakeScreen records the messages it gets. Its Init loads, and it returns
+// reply from Update.
+type fakeScreen struct {
+	name   string
+	cursor int
+	got    []tea.Msg
+	reply  tea.Cmd
+}
+
+func (s *fakeScreen) Init() tea.Cmd {
+	return func() tea.Msg { return loadedMsg{} }
+}
+
+func (s *fakeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s.got = append(s.got, msg)
+	if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyDown {
+		s.cursor++
+	}
+	return s, s.reply
+}
+
+func (s *fakeScreen) View() string {
+	return s.name
+}
+
+// received reports how many times s got msg.
+func (s *fakeScreen) received(msg tea.Msg) int {
+	n := 0
+	for _, got := range s.got {
+		if got == msg {
+			n++
+		}
+	}
+	return n
+}
+
+// results runs cmd and returns its messages, with batches flattened.
+func results(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, results(c)...)
+		}
+		return msgs
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+// deliver runs cmd and passes its results to r.
+func deliver(r *Router, cmd tea.Cmd) {
+	for _, msg := range results(cmd) {
+		r.Update(msg)
+	}
+}
+
+// TestRouterPushPopReplace tests the stack operations.
+func TestRouterPushPopReplace(t *testing.T) {
+	root, a, b := &fakeScreen{name: "root"}, &fakeScreen{name: "a"}, &fakeScreen{name: "b"}
+	r := NewRouter(root, nil, nil)
+
+	r.Push(a)
+	if r.Depth() != 2 || r.View() != "a" {
+		t.Fatalf("Expected a on top of 2 screens, got %q of %d", r.View(), r.Depth())
+	}
+
+	r.Replace(b)
+	if r.Depth() != 2 || r.View() != "b" {
+		t.Fatalf("Expected b to replace a, got %q of %d", r.View(), r.Depth())
+	}
+
+	r.Update(NavigateBackMsg{})
+	if r.Depth() != 1 || r.View() != "root" {
+		t.Fatalf("Expected back at root, got %q of %d", r.View(), r.Depth())
+	}
+
+	r.Pop()
+	if r.Depth() != 1 || r.View() != "root" {
+		t.Errorf("Expected the root screen to stay, got %q of %d", r.View(), r.Depth())
+	}
+}
+
+// TestRouterPopKeepsState tests that the revealed screen is as it was left.
+func TestRouterPopKeepsState(t *testing.T) {
+	root := &fakeScreen{name: "root"}
+	r := NewRouter(root, nil, nil)
+	r.Update(tea.KeyMsg{Type: tea.KeyDown})
+	r.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	r.Push(&fakeScreen{name: "a"})
+	r.Update(tea.KeyMsg{Type: tea.KeyDown})
+	r.Pop()
+
+	if root.cursor != 2 {
+		t.Errorf("Expected the root's cursor to stay at 2, got %d", root.cursor)
+	}
+	if r.View() != "root" {
+		t.Errorf("Expected the root on top, got %q", r.View())
+	}
+}
+
+// TestRouterResultsGoToSender tests that a command's result reaches the
+// screen that ran it, even when another screen is on top.
+func TestRouterResultsGoToSender(t *testing.T) {
+	root, a := &fakeScreen{name: "root"}, &fakeScreen{name: "a"}
+	r := NewRouter(root, nil, nil)
+	rootLoad := r.Init()
+	deliver(r, r.Push(a))
+	deliver(r, rootLoad)
+
+	if n := root.received(loadedMsg{}); n != 1 {
+		t.Errorf("Expected the root to get its load once, got %d", n)
+	}
+	if n := a.received(loadedMsg{}); n != 1 {
+		t.Errorf("Expected a to get only its own load, got %d", n)
+	}
+}
+
+// TestRouterClosedScreenKeepsToasts tests that a screen closed before its
+// result arrived still reports it as a toast, and nothing else it asks for
+// reaches the screens left open.
+func TestRouterClosedScreenKeepsToasts(t *testing.T) {
+	defer func() { toasts = nil }()
+	toasts = nil
+
+	root := &fakeScreen{name: "root"}
+	a := &fakeScreen{name: "a", reply: tea.Batch(
+		notify(toastSuccess, "Grade saved"),
+		func() tea.Msg { return loadedMsg{} },
+	)}
+	r := NewRouter(root, nil, nil)
+	load := r.Push(a)
+	r.Pop()
+	for _, msg := range results(load) {
+		_, cmd := r.Update(msg)
+		deliver(r, cmd)
+	}
+
+	if len(toasts) != 1 || toasts[0].text != "Grade saved" {
+		t.Errorf("Expected the closed screen's toast, got %+v", toasts)
+	}
+	if n := root.received(loadedMsg{}); n != 0 {
+		t.Errorf("Expected the root not to get the closed screen's results, got %d", n)
+	}
+}
+
+// TestRouterBackFromScreenBelow tests that only the screen on top closes
+// itself.
+func TestRouterBackFromScreenBelow(t *testing.T) {
+	root := &fakeScreen{name: "root", reply: func() tea.Msg { return NavigateBackMsg{} }}
+	r := NewRouter(root, nil, nil)
+	r.Push(&fakeScreen{name: "a"})
+	r.Push(&fakeScreen{name: "b"})
+	_, cmd := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	deliver(r, cmd)
+
+	if r.Depth() != 3 {
+		t.Errorf("Expected 3 screens open, got %d", r.Depth())
+	}
+}
+
+// TestToastsRepeat tests that the same result reported twice shows twice.
+func TestToastsRepeat(t *testing.T) {
+	defer func() { toasts = nil }()
+	toasts = nil
+
+	updateToasts(toastMsg{kind: toastSuccess, text: "Grade saved"})
+	updateToasts(toastMsg{kind: toastSuccess, text: "Grade saved"})
+	if len(toasts) != 2 {
+		t.Errorf("Expected 2 toasts, got %d", len(toasts))
+	}
+}
@@ -0,0 +1,223 @@
+package tea
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/outbox"
+)
+
+// rubricView shows the coursework's rubric for the selected submission.
+// 'R' toggles it, and teachers press 'g' to pick a level per criterion;
+// saving sets the summed points as the draft grade.
+type rubricView struct {
+	rubric *api.Rubric
+	shown  bool
+
+	// grading is the submission being graded, or nil.
+	grading *api.StudentSubmission
+	cursor  int
+	// levels maps criterion IDs to the index of the chosen level.
+	levels map[string]int
+}
+
+// toggle shows or hides the rubric.
+func (r *rubricView) toggle() {
+	if r.rubric != nil && r.grading == nil {
+		r.shown = !r.shown
+	}
+}
+
+// startGrading begins grading sub, starting from its rubric grades.
+func (r *rubricView) startGrading(sub *api.StudentSubmission) {
+	r.shown = true
+	r.grading = sub
+	r.cursor = 0
+	r.levels = make(map[string]int)
+	for id, level := range r.chosen(sub) {
+		r.levels[id] = level
+	}
+}
+
+// handleKey handles a key while grading. It reports whether the levels
+// are complete and should be saved, and whether grading ended.
+func (r *rubricView) handleKey(key string) (save, done bool) {
+	criteria := r.rubric.Criteria
+	switch key {
+	case "esc", "b":
+		r.grading = nil
+		return false, true
+	case "enter":
+		return true, false
+	case "up", "k":
+		if r.cursor > 0 {
+			r.cursor--
+		}
+	case "down", "j", "tab":
+		if r.cursor < len(criteria)-1 {
+			r.cursor++
+		}
+	case "left", "h", "right", "l":
+		if len(criteria) == 0 {
+			break
+		}
+		c := criteria[r.cursor]
+		if len(c.Levels) == 0 {
+			break
+		}
+		level, ok := r.levels[c.ID]
+		switch {
+		case !ok:
+			level = 0
+		case key == "left" || key == "h":
+			level = max(level-1, 0)
+		default:
+			level = min(level+1, len(c.Levels)-1)
+		}
+		r.levels[c.ID] = level
+	}
+	return false, false
+}
+
+// total returns the points of the chosen levels, or an error naming the
+// first criterion without one.
+func (r *rubricView) total() (float64, error) {
+	total := 0.0
+	for _, c := range r.rubric.Criteria {
+		level, ok := r.levels[c.ID]
+		if !ok {
+			return 0, fmt.Errorf("choose a level for %q", c.Title)
+		}
+		total += c.Levels[level].Points
+	}
+	return total, nil
+}
+
+// chosen returns the level index per criterion of the submission's
+// assigned rubric grades, or its draft ones when none are assigned.
+func (r *rubricView) chosen(sub *api.StudentSubmission) map[string]int {
+	grades := sub.AssignedRubricGrades
+	if len(grades) == 0 {
+		grades = sub.DraftRubricGrades
+	}
+	levels := make(map[string]int)
+	for _, c := range r.rubric.Criteria {
+		g, ok := grades[c.ID]
+		if !ok {
+			continue
+		}
+		for i, l := range c.Levels {
+			if l.ID == g.LevelID || (g.LevelID == "" && l.Points == g.Points) {
+				levels[c.ID] = i
+				break
+			}
+		}
+	}
+	return levels
+}
+
+// render renders the rubric with the levels chosen for sub, or "" while
+// it is hidden.
+func (r *rubricView) render(sub *api.StudentSubmission) string {
+	if r.rubric == nil || !r.shown {
+		return ""
+	}
+
+	levels := r.levels
+	if r.grading == nil {
+		levels = map[string]int{}
+		if sub != nil {
+			levels = r.chosen(sub)
+		}
+	}
+
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true)
+	lines := []string{lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(fmt.Sprintf("Rubric (%s pts)", formatPoints(r.rubric.MaxPoints())))}
+
+	width := 0
+	for _, c := range r.rubric.Criteria {
+		width = max(width, lipgloss.Width(c.Title))
+	}
+	scored := 0.0
+	for i, c := range r.rubric.Criteria {
+		cursor := "  "
+		if r.grading != nil && i == r.cursor {
+			cursor = "> "
+		}
+		parts := make([]string, len(c.Levels))
+		for j, l := range c.Levels {
+			label := formatPoints(l.Points)
+			if l.Title != "" {
+				label = l.Title + " " + label
+			}
+			if level, ok := levels[c.ID]; ok && level == j {
+				parts[j] = selected.Render("[" + label + "]")
+				scored += l.Points
+			} else {
+				parts[j] = muted.Render(" " + label + " ")
+			}
+		}
+		lines = append(lines, cursor+c.Title+strings.Repeat(" ", width-lipgloss.Width(c.Title))+"  "+strings.Join(parts, " "))
+	}
+
+	if len(levels) > 0 {
+		lines = append(lines, muted.Render(fmt.Sprintf("Rubric score: %s/%s", formatPoints(scored), formatPoints(r.rubric.MaxPoints()))))
+	}
+	if r.grading != nil {
+		lines = append(lines, muted.Render("↑↓ criterion | ←→ level | enter save draft grade | esc cancel"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatPoints formats points without trailing zeros.
+func formatPoints(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// saveDraftGrade sets the submission's draft grade, queueing it while
+// offline. Rubric grading saves its total this way: the Classroom API does
+// not accept per-criterion grades from clients.
+func saveDraftGrade(client api.ClassroomClient, course *api.Course, courseWork *api.CourseWork, sub *api.StudentSubmission, total float64) tea.Cmd {
+	courseID, courseWorkID := course.ID, courseWork.ID
+	entry := outbox.Entry{
+		Kind:           outbox.KindDraftGrade,
+		CourseID:       courseID,
+		CourseWorkID:   courseWorkID,
+		TargetID:       sub.ID,
+		Label:          fmt.Sprintf("Grade %q: %s", courseWork.Title, formatPoints(total)),
+		Grade:          total,
+		BaseUpdateTime: sub.UpdateTime,
+	}
+	commit := func(ctx context.Context) error {
+		_, err := client.SetDraftGrade(ctx, courseID, courseWorkID, sub.ID, total)
+		return err
+	}
+
+	queued := submissionQueuedMsg{notice: fmt.Sprintf("Draft grade %s will sync when back online", formatPoints(total))}
+	return func() tea.Msg {
+		if isOffline() && queueOffline(entry, commit) {
+			return queued
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := commit(ctx); err != nil {
+			if deferIfOffline(err, entry, commit) {
+				return queued
+			}
+			return errorMsg{err: err}
+		}
+		return submissionUpdatedMsg{notice: fmt.Sprintf("Saved draft grade %s", formatPoints(total))}
+	}
+}
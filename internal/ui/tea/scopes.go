@@ -0,0 +1,146 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/auth"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// scopeGrantedMsg is sent when the scopes a feature asked for are
+// granted, or with err when they were not.
+type scopeGrantedMsg struct {
+	scopes []string
+	err    error
+}
+
+// grantedScopes remembers the scopes confirmed this session, so the token
+// is only checked the first time a feature needs them.
+var grantedScopes = struct {
+	sync.Mutex
+	scopes map[string]bool
+}{scopes: map[string]bool{}}
+
+// markGranted records scopes as granted.
+func markGranted(scopes []string) {
+	grantedScopes.Lock()
+	defer grantedScopes.Unlock()
+	for _, s := range scopes {
+		grantedScopes.scopes[s] = true
+	}
+}
+
+// needsScopes reports whether scopes must be confirmed before they are
+// used. It is false when the app cannot ask for them.
+func needsScopes(scopes ...string) bool {
+	if options.GrantedScopes == nil || options.RequestScopes == nil {
+		return false
+	}
+	grantedScopes.Lock()
+	defer grantedScopes.Unlock()
+	for _, s := range scopes {
+		if !grantedScopes.scopes[s] {
+			return true
+		}
+	}
+	return false
+}
+
+// requestScopes returns a command that checks the token for scopes and,
+// when any is missing, runs incremental consent for it with the terminal
+// released. It ends with a scopeGrantedMsg.
+func requestScopes(scopes ...string) tea.Cmd {
+	granted, request := options.GrantedScopes, options.RequestScopes
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		have, err := granted(ctx)
+		cancel()
+		if err != nil {
+			return scopeGrantedMsg{scopes: scopes, err: fmt.Errorf("failed to check permissions: %w", err)}
+		}
+		var missing []string
+		for _, s := range scopes {
+			if !slices.Contains(have, s) {
+				missing = append(missing, s)
+			}
+		}
+		if len(missing) == 0 {
+			markGranted(scopes)
+			return scopeGrantedMsg{scopes: scopes}
+		}
+		return consent(request, missing, func(err error) tea.Msg {
+			if err != nil {
+				return scopeGrantedMsg{scopes: scopes, err: fmt.Errorf("permission not granted: %w", err)}
+			}
+			markGranted(scopes)
+			return scopeGrantedMsg{scopes: scopes}
+		})
+	}
+}
+
+// consent returns the message that runs request for scopes with the
+// terminal released, reporting the result through done. Consent runs like
+// a login, so it reuses the login command.
+func consent(request func(context.Context, []string) error, scopes []string, done func(error) tea.Msg) tea.Msg {
+	login := func(ctx context.Context, _ auth.LoginMode) error {
+		return request(ctx, scopes)
+	}
+	// The Exec command only builds the message, so it is safe to call
+	// from a command
+	return tea.Exec(&loginCommand{login: login}, done)()
+}
+
+// grantMissingScopes returns a command that asks for every scope the app
+// uses that the token lacks, for errors caused by a missing scope. It ends
+// with a recoveryDoneMsg.
+func grantMissingScopes() tea.Cmd {
+	granted, request := options.GrantedScopes, options.RequestScopes
+	return func() tea.Msg {
+		done := func(err error) tea.Msg {
+			if err != nil {
+				// Stay on the error screen so the permission can be asked
+				// for again
+				err = apperrors.Wrap(err, apperrors.ErrAPIForbidden, "permission not granted").
+					WithSuggestion(scopeFailure(err)).
+					WithAction(apperrors.ActionGrantScopes)
+			}
+			return recoveryDoneMsg{action: apperrors.ActionGrantScopes, err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		have, err := granted(ctx)
+		cancel()
+		if err != nil {
+			return done(fmt.Errorf("failed to check permissions: %w", err))
+		}
+		missing := auth.MissingScopes(auth.AuditScopes(have))
+		if len(missing) == 0 {
+			return recoveryDoneMsg{action: apperrors.ActionGrantScopes,
+				err: errors.New("every permission the app uses is already granted")}
+		}
+		return consent(request, missing, func(err error) tea.Msg {
+			if err == nil {
+				markGranted(missing)
+			}
+			return done(err)
+		})
+	}
+}
+
+// scopeFailure explains why a missing permission was not granted.
+func scopeFailure(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Cancelled before the permission was granted."
+	case errors.Is(err, auth.ErrLoginTimeout):
+		return "Timed out before the permission was granted."
+	case errors.Is(err, auth.ErrLoginDenied):
+		return "The permission was not granted."
+	}
+	return err.Error()
+}
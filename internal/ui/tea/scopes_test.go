@@ -0,0 +1,148 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/drive"
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/backoff"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// withScopeOptions sets options with a Drive client and a token carrying
+// the scopes granted returns, forgetting scopes confirmed by other tests.
+func withScopeOptions(t *testing.T, granted func() ([]string, error)) {
+	t.Helper()
+	client, err := drive.New(context.Background(), &drive.Configuration{HTTPClient: http.DefaultClient, Retry: backoff.Default()})
+	if err != nil {
+		t.Fatalf("Failed to create drive client: %v", err)
+	}
+	SetOptions(Options{
+		Drive: client,
+		GrantedScopes: func(context.Context) ([]string, error) {
+			return granted()
+		},
+		RequestScopes: func(context.Context, []string) error {
+			t.Error("Expected consent to wait for the terminal")
+			return nil
+		},
+	})
+	grantedScopes.scopes = map[string]bool{}
+	t.Cleanup(func() {
+		SetOptions(Options{})
+		grantedScopes.scopes = map[string]bool{}
+	})
+}
+
+// TestDownloadAsksForScope tests that the first download checks for the
+// Drive read scope, asks for it only when it is missing, and starts once
+// it is granted.
+func TestDownloadAsksForScope(t *testing.T) {
+	attachments := []api.Attachment{{Kind: api.AttachmentDriveFile, ID: "file1", Title: "notes"}}
+
+	withScopeOptions(t, func() ([]string, error) { return nil, errors.New("offline") })
+	var d download
+	msg := d.start(attachments)()
+	if granted, ok := msg.(scopeGrantedMsg); !ok || granted.err == nil {
+		t.Fatalf("Expected a failed scope check, got %#v", msg)
+	}
+	d.update(msg)
+	if d.active || d.err == nil {
+		t.Errorf("Expected the download to fail with the scope check, got active %v, err %v", d.active, d.err)
+	}
+
+	withScopeOptions(t, func() ([]string, error) { return []string{auth.ScopeCourses}, nil })
+	d = download{}
+	if msg := d.start(attachments)(); isScopeGranted(msg) {
+		t.Errorf("Expected consent to run for a missing scope, got %#v", msg)
+	}
+
+	withScopeOptions(t, func() ([]string, error) { return []string{auth.ScopeDriveReadonly}, nil })
+	d = download{}
+	msg = d.read(attachments)()
+	if !isScopeGranted(msg) {
+		t.Fatalf("Expected the granted scope to be confirmed, got %#v", msg)
+	}
+	if cmd := d.update(msg); cmd == nil || !d.active {
+		t.Error("Expected the export to start once the scope is granted")
+	}
+	if needsScopes(auth.ScopeDriveReadonly) {
+		t.Error("Expected the scope to be remembered for later downloads")
+	}
+}
+
+// isScopeGranted reports whether msg confirms a granted scope.
+func isScopeGranted(msg any) bool {
+	granted, ok := msg.(scopeGrantedMsg)
+	return ok && granted.err == nil
+}
+
+// TestUploadAsksForScope tests that the first upload asks for the Drive
+// file scope, and that a download waiting for its own scope ignores it.
+func TestUploadAsksForScope(t *testing.T) {
+	withScopeOptions(t, func() ([]string, error) { return []string{auth.ScopeCourses}, nil })
+	sub := &api.StudentSubmission{ID: "s1", CourseID: "c1", CourseWorkID: "w1"}
+
+	var u upload
+	if msg := u.start(nil, sub, []string{"report.pdf"})(); isScopeGranted(msg) {
+		t.Errorf("Expected consent to run for a missing scope, got %#v", msg)
+	}
+	if !u.active || u.pending == nil {
+		t.Error("Expected the upload to wait for the scope")
+	}
+
+	d := download{active: true, pending: func() tea.Cmd { return nil }}
+	d.update(scopeGrantedMsg{scopes: []string{auth.ScopeDriveFile}})
+	if d.pending == nil {
+		t.Error("Expected the download to keep waiting for its own scope")
+	}
+
+	u.update(scopeGrantedMsg{scopes: []string{auth.ScopeDriveFile}, err: errors.New("denied")})
+	if u.active || u.err == nil || u.pending != nil {
+		t.Errorf("Expected the upload to fail when the scope is refused, got active %v, err %v", u.active, u.err)
+	}
+}
+
+// TestRecoverMissingScope tests that P on the error screen of a missing
+// scope checks the token and asks for what it lacks.
+func TestRecoverMissingScope(t *testing.T) {
+	err := apperrors.New(apperrors.ErrAPIForbidden, "failed to list topics").
+		NotRecoverable().
+		WithAction(apperrors.ActionGrantScopes)
+
+	SetOptions(Options{})
+	if recoverFromError(err, "P") != nil {
+		t.Error("Expected no recovery when the app cannot ask for scopes")
+	}
+
+	withScopeOptions(t, func() ([]string, error) { return []string{auth.ScopeCourses}, nil })
+	if view := renderErrorView("Error", err, 80, 10); !strings.Contains(view, "Press 'P' to grant the missing permission") {
+		t.Errorf("Expected the P hint, got %q", view)
+	}
+	if recoverFromError(err, "L") != nil {
+		t.Error("Expected L to do nothing for a missing scope")
+	}
+	cmd := recoverFromError(err, "P")
+	if cmd == nil {
+		t.Fatal("Expected P to ask for the missing scopes")
+	}
+	if _, done := cmd().(recoveryDoneMsg); done {
+		t.Error("Expected consent to run for the missing scopes")
+	}
+
+	var every []string
+	for _, f := range auth.Features {
+		every = append(every, f.AnyOf...)
+	}
+	withScopeOptions(t, func() ([]string, error) { return every, nil })
+	msg, ok := recoverFromError(err, "P")().(recoveryDoneMsg)
+	if !ok || msg.err == nil {
+		t.Errorf("Expected an error when nothing is missing, got %#v", msg)
+	}
+}
@@ -0,0 +1,30 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/user/google-classroom/internal/collation"
+)
+
+// foldFilter is a list.FilterFunc that matches accent-insensitively. Matched
+// indexes point back into the original targets so highlighting still lines up.
+func foldFilter(term string, targets []string) []list.Rank {
+	folded := make([]string, len(targets))
+	runeIndexes := make([][]int, len(targets))
+	for i, target := range targets {
+		folded[i], runeIndexes[i] = collation.FoldIndexed(target)
+	}
+
+	ranks := list.DefaultFilter(collation.Fold(term), folded)
+	for i, rank := range ranks {
+		matched := make([]int, 0, len(rank.MatchedIndexes))
+		last := -1
+		for _, idx := range rank.MatchedIndexes {
+			if r := runeIndexes[rank.Index][idx]; r != last {
+				matched = append(matched, r)
+				last = r
+			}
+		}
+		ranks[i].MatchedIndexes = matched
+	}
+	return ranks
+}
@@ -0,0 +1,48 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbletea"
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// reauthUpdates receives an error when the session's token can no longer
+// be refreshed.
+var reauthUpdates <-chan error
+
+// reauthMsg is sent when the user has to log in again.
+type reauthMsg struct {
+	err error
+}
+
+// watchReauth waits for the token manager to report that a new login is
+// needed. It is issued when the app starts and again after each login.
+func watchReauth() tea.Cmd {
+	if reauthUpdates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return reauthMsg{err: <-reauthUpdates}
+	}
+}
+
+// reauthError turns a reauthMsg into an error whose screen offers 'L' to
+// log in again.
+func reauthError(msg reauthMsg) error {
+	return apperrors.Wrap(msg.err, apperrors.ErrAuthExpired, "session expired")
+}
+
+// sessionExpired reports whether err means the user has to log in again.
+// Screens show such errors from actions and partial loads as the session
+// expired screen rather than inline, since nothing else will work either.
+func sessionExpired(err error) bool {
+	return apperrors.IsAuthError(err)
+}
+
+// afterRecovery returns the commands to run once a recovery action has
+// succeeded: a new login needs watching again.
+func afterRecovery(msg recoveryDoneMsg) tea.Cmd {
+	if msg.action == apperrors.ActionLogin {
+		return watchReauth()
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/ui/components"
+)
+
+// SetupModel is the first-run wizard. It collects an OAuth client,
+// checks it with Google, writes it to the config file, and runs the login.
+type SetupModel struct {
+	configPath string
+	form       *components.Form
+	spinner    spinner.Model
+	// step is what the wizard is doing now.
+	step   setupStep
+	err    error
+	width  int
+	height int
+}
+
+// setupStep is a stage of the first-run wizard.
+type setupStep int
+
+const (
+	setupEnter setupStep = iota
+	setupValidating
+	setupLoggingIn
+)
+
+// SetupDoneMsg is sent when the wizard has saved the client and the user
+// has logged in; the app then shows the course list.
+type SetupDoneMsg struct{}
+
+// setupValidatedMsg reports the result of checking the client.
+type setupValidatedMsg struct {
+	err error
+}
+
+// NewSetupModel creates the wizard, which saves to configPath. Run it when
+// auth.NeedsSetup reports no credentials.
+func NewSetupModel(configPath string) *SetupModel {
+	form := components.NewForm("Connect to Google Classroom", "Client ID", "Secret")
+	form.SetSecret(1)
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6"))
+
+	return &SetupModel{
+		configPath: configPath,
+		form:       form,
+		spinner:    s,
+	}
+}
+
+// Init initializes the model.
+func (m *SetupModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages.
+func (m *SetupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.step != setupEnter {
+			return m, nil
+		}
+		cmd := m.form.Update(msg)
+		switch {
+		case m.form.Cancelled():
+			return m, tea.Quit
+		case m.form.Submitted():
+			m.form.Reopen()
+			if m.form.Value(0) == "" || m.form.Value(1) == "" {
+				m.err = errors.New("enter both the client ID and the secret")
+				return m, nil
+			}
+			m.err = nil
+			m.step = setupValidating
+			return m, tea.Batch(m.spinner.Tick, m.validate())
+		}
+		return m, cmd
+
+	case spinner.TickMsg:
+		if m.step != setupValidating {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case setupValidatedMsg:
+		if msg.err != nil {
+			m.step = setupEnter
+			m.err = msg.err
+			return m, nil
+		}
+		m.step = setupLoggingIn
+		return m, m.login()
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			// The client is saved; only the login needs another try
+			m.step = setupEnter
+			m.err = msg.err
+			return m, nil
+		}
+		return m, func() tea.Msg { return SetupDoneMsg{} }
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	}
+	return m, nil
+}
+
+// validate checks the client with Google and saves it when it is accepted.
+func (m *SetupModel) validate() tea.Cmd {
+	clientID, secret := m.form.Value(0), m.form.Value(1)
+	path := m.configPath
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := auth.ValidateClient(ctx, clientID, secret); err != nil {
+			return setupValidatedMsg{err: err}
+		}
+		return setupValidatedMsg{err: auth.SaveClient(path, clientID, secret)}
+	}
+}
+
+// login runs the login with the saved client while the TUI has released
+// the terminal.
+func (m *SetupModel) login() tea.Cmd {
+	path := m.configPath
+	login := func(ctx context.Context, mode auth.LoginMode) error {
+		a, err := auth.NewAuthenticator(path)
+		if err != nil {
+			return err
+		}
+		a.SetLoginMode(mode)
+		return a.Login(ctx)
+	}
+	return tea.Exec(&loginCommand{login: login, mode: auth.LoginBrowser}, func(err error) tea.Msg {
+		return recoveryDoneMsg{err: err}
+	})
+}
+
+// View renders the model.
+func (m *SetupModel) View() string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	text := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+
+	intro := strings.Join([]string{
+		text.Render("The app needs an OAuth client from your Google Cloud project:"),
+		"",
+		text.Render("1. Enable the Google Classroom API in the project"),
+		text.Render("2. Open " + auth.ConsoleCredentialsURL),
+		text.Render("3. Create credentials → OAuth client ID → Desktop app"),
+		text.Render("4. Copy the client ID and secret below"),
+	}, "\n")
+
+	sections := []string{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6")).Bold(true).Render("Welcome to Google Classroom"),
+		"",
+		intro,
+		"",
+		m.form.View(),
+	}
+
+	switch m.step {
+	case setupValidating:
+		sections = append(sections, "", m.spinner.View()+text.Render(" Checking the client with Google..."))
+	case setupLoggingIn:
+		sections = append(sections, "", text.Render("Logging in..."))
+	}
+	if m.err != nil {
+		sections = append(sections, "", lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.err)))
+	}
+	sections = append(sections, "", muted.Render("The client is saved to "+m.configPath+" (readable only by you)."))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(sections, "\n"))
+}
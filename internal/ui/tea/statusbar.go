@@ -0,0 +1,96 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+)
+
+// quotaWarnAt is the share of the per-minute quota at which the status bar
+// turns into a warning.
+const quotaWarnAt = 0.8
+
+// lastLoad holds when a load last finished, in Unix nanoseconds, for the
+// status bar.
+var lastLoad atomic.Int64
+
+// backgroundTasks counts the tasks running in the background, such as the
+// prefetch and the outbox replay.
+var backgroundTasks atomic.Int32
+
+// markLoaded records that a load finished now.
+func markLoaded() {
+	lastLoad.Store(time.Now().UnixNano())
+}
+
+// inBackground runs fn as a background task counted by the status bar.
+func inBackground(fn func() tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		backgroundTasks.Add(1)
+		defer backgroundTasks.Add(-1)
+		return fn()
+	}
+}
+
+// statusBar renders the bar every screen shows above its keys: the
+// signed-in account, the course on screen (nil for none), when data was
+// last loaded, whether reads come from the cache or the app is offline,
+// the count of pending background tasks and queued changes, and the API
+// client's request counts. It is cut to width, and highlighted after rate
+// limiting or when the per-minute quota is nearly used. Toasts are shown
+// above it.
+func statusBar(course *api.Course, width int) string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	var parts []string
+	if account := accountLabel(); account != "" {
+		parts = append(parts, muted.Render("👤 "+account))
+	}
+	if course != nil {
+		parts = append(parts, muted.Render(course.Name))
+	}
+	if at := lastLoad.Load(); at != 0 {
+		parts = append(parts, muted.Render("↻ "+formatAge(time.Since(time.Unix(0, at)))+" ago"))
+	}
+	if state := dataSource(); state != "" {
+		parts = append(parts, state)
+	}
+	if n := int(backgroundTasks.Load()) + pendingSyncCount(); n > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd")).Render(fmt.Sprintf("⧗ %d pending", n)))
+	}
+	if options.Stats != nil {
+		stats := options.Stats()
+		style := muted
+		if stats.Total().RateLimited > 0 || stats.QuotaFraction() >= quotaWarnAt {
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffb86c"))
+		}
+		parts = append(parts, style.Render("API: "+stats.String()))
+	}
+	bar := strings.Join(parts, muted.Render(" | "))
+	if width > 4 {
+		bar = lipgloss.NewStyle().MaxWidth(width - 4).Render(bar)
+	}
+	if toasts := renderToasts(width); toasts != "" {
+		return strings.TrimSuffix(toasts+"\n"+bar, "\n")
+	}
+	return bar
+}
+
+// dataSource describes where screens read from: offline, the cache, or
+// the API directly. It is "" when neither a cache nor a connectivity
+// monitor is configured.
+func dataSource() string {
+	switch {
+	case isOffline() || !staleSince().IsZero():
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render("● offline")
+	case options.Cache != nil && options.Cache.Enabled():
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Render("● online, cached")
+	case options.Cache != nil || options.Connectivity != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Render("● online")
+	}
+	return ""
+}
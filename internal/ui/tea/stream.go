@@ -0,0 +1,126 @@
+package tea
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/user/google-classroom/internal/api"
+)
+
+// streamItem is one post in a course's stream: coursework, including
+// materials, or an announcement.
+type streamItem struct {
+	created      time.Time
+	courseWork   *api.CourseWork
+	announcement *api.Announcement
+}
+
+// streamKinds label each kind of post in the stream, with its icon.
+var streamKinds = map[string]string{
+	api.WorkTypeAssignment:     "✎ Assignment",
+	api.WorkTypeShortAnswer:    "? Question",
+	api.WorkTypeMultipleChoice: "? Question",
+	api.WorkTypeMaterial:       "▤ Material",
+}
+
+// buildStream interleaves coursework and announcements newest first, as
+// the Classroom stream shows them.
+func buildStream(coursework []*api.CourseWork, announcements []*api.Announcement) []streamItem {
+	items := make([]streamItem, 0, len(coursework)+len(announcements))
+	for _, cw := range coursework {
+		created, _ := time.Parse(time.RFC3339, cw.CreateTime)
+		items = append(items, streamItem{created: created, courseWork: cw})
+	}
+	for _, a := range announcements {
+		created, _ := time.Parse(time.RFC3339, a.CreateTime)
+		items = append(items, streamItem{created: created, announcement: a})
+	}
+	slices.SortStableFunc(items, func(a, b streamItem) int { return b.created.Compare(a.created) })
+	return items
+}
+
+// kind returns the icon and label of the item's kind.
+func (s streamItem) kind() string {
+	if s.announcement != nil {
+		return "✉ Post"
+	}
+	if kind := streamKinds[s.courseWork.WorkType]; kind != "" {
+		return kind
+	}
+	return "✎ " + s.courseWork.WorkType
+}
+
+// title returns the coursework title, or the announcement's first line.
+func (s streamItem) title() string {
+	if s.courseWork != nil {
+		return stateBadge(s.courseWork) + s.courseWork.Title
+	}
+	text, _, _ := strings.Cut(strings.TrimSpace(s.announcement.Text), "\n")
+	return text
+}
+
+// link returns the item's Classroom link.
+func (s streamItem) link() string {
+	if s.courseWork != nil {
+		return s.courseWork.AlternateLink
+	}
+	return s.announcement.AlternateLink
+}
+
+// unread reports whether the item was posted after the stream was last
+// seen. Before the stream is first seen nothing is unread, so a new
+// install does not mark every post.
+func (m *CourseDetailModel) unread(s streamItem) bool {
+	return m.seenOnce && s.created.After(m.lastSeen)
+}
+
+// unreadCount returns the number of unread stream items.
+func (m *CourseDetailModel) unreadCount() int {
+	n := 0
+	for _, s := range m.stream {
+		if m.unread(s) {
+			n++
+		}
+	}
+	return n
+}
+
+// markStreamSeen records the stream as seen up to its newest post while
+// it is on screen. The markers shown stay until the course is reopened.
+func (m *CourseDetailModel) markStreamSeen() {
+	if m.activeTab != TabStream || m.tabLoading(TabStream) || len(m.stream) == 0 || options.ReadState == nil {
+		return
+	}
+	// Read markers are a convenience; failing to save one only means
+	// the posts show as unread again next time.
+	_ = options.ReadState.MarkSeen(m.course.ID, m.stream[0].created)
+}
+
+// streamTable returns the stream tab's columns and rows.
+func (m *CourseDetailModel) streamTable() ([]table.Column, []table.Row) {
+	columns := []table.Column{
+		{Title: "", Width: 2},
+		{Title: "Type", Width: 14},
+		{Title: "Title", Width: 50},
+		{Title: "Posted", Width: 16},
+	}
+	rows := make([]table.Row, len(m.stream))
+	for i, s := range m.stream {
+		marker := ""
+		if m.unread(s) {
+			marker = "●"
+		}
+		title := s.title()
+		if runes := []rune(title); len(runes) > 48 {
+			title = string(runes[:47]) + "…"
+		}
+		posted := ""
+		if !s.created.IsZero() {
+			posted = s.created.Local().Format("Jan 2 15:04")
+		}
+		rows[i] = table.Row{marker, s.kind(), title, posted}
+	}
+	return columns, rows
+}
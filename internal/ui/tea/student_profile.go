@@ -0,0 +1,339 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/report"
+)
+
+// StudentProfileModel shows a student's profile and how they are doing in
+// the course: their submissions turned in, missing, and late, and their
+// average grade, taken from the course gradebook.
+type StudentProfileModel struct {
+	course    *api.Course
+	student   *api.Student
+	apiClient api.ClassroomClient
+	refresh   bool // next load skips the cache
+	gradebook *report.GradebookReport
+	row       report.GradebookRow
+	// hidden is set when the user may not see the student's submissions:
+	// students only see their own.
+	hidden  bool
+	spinner spinner.Model
+	loading bool
+	err     error
+	link    browserLink
+	help    helpOverlay
+	width   int
+	height  int
+}
+
+// StudentSelectedMsg is sent when a student is selected on the roster.
+type StudentSelectedMsg struct {
+	Course  *api.Course
+	Student *api.Student
+}
+
+// studentProfileLoadedMsg carries the course gradebook, or hidden when the
+// user may not see the student's work.
+type studentProfileLoadedMsg struct {
+	gradebook *report.GradebookReport
+	hidden    bool
+	err       error
+}
+
+// NewStudentProfileModel creates the profile screen of student.
+func NewStudentProfileModel(course *api.Course, student *api.Student, apiClient api.ClassroomClient) *StudentProfileModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6"))
+
+	return &StudentProfileModel{
+		course:    course,
+		student:   student,
+		apiClient: cache.NewCachedClient(apiClient, options.Cache),
+		spinner:   s,
+		loading:   true,
+	}
+}
+
+// Init initializes the model.
+func (m *StudentProfileModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.load(), watchConnectivity())
+}
+
+// load builds the course gradebook, which holds the student's row.
+// Students other than the user are hidden from students, as the API only
+// lists a student's own submissions to them.
+func (m *StudentProfileModel) load() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
+	client, courseID, userID := m.apiClient, m.course.ID, m.student.UserID
+	return func() tea.Msg {
+		ctx, cancel := loadContext(refresh)
+		defer cancel()
+
+		isTeacher, err := client.IsTeacher(ctx, courseID)
+		if err != nil {
+			return studentProfileLoadedMsg{err: err}
+		}
+		if !isTeacher && !isMe(userID) {
+			return studentProfileLoadedMsg{hidden: true}
+		}
+		g, err := report.BuildGradebook(ctx, client, courseID, time.Now())
+		if err != nil {
+			return studentProfileLoadedMsg{err: err}
+		}
+		return studentProfileLoadedMsg{gradebook: g}
+	}
+}
+
+// Update handles messages.
+func (m *StudentProfileModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
+			m.loading = true
+			m.err = nil
+			return m, m.load()
+		case key.Matches(msg, bound(keymap.Open)):
+			return m, m.link.openOr(photoLink(m.student.Profile.PhotoURL), "this student has no photo")
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
+		}
+
+	case studentProfileLoadedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err != nil {
+			reportError(msg.err)
+			return m, nil
+		}
+		m.hidden = msg.hidden
+		m.gradebook = msg.gradebook
+		if m.gradebook != nil {
+			m.row, _ = m.gradebook.Row(m.student.UserID)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.load(), afterRecovery(msg))
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// photoLink makes a profile photo URL openable. Classroom returns them
+// without a scheme, as "//lh3.googleusercontent.com/...".
+func photoLink(url string) string {
+	if strings.HasPrefix(url, "//") {
+		return "https:" + url
+	}
+	return url
+}
+
+// View renders the model.
+func (m *StudentProfileModel) View() string {
+	if m.loading {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Align(lipgloss.Center).
+			Render(
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					m.spinner.View(),
+					lipgloss.NewStyle().
+						Foreground(lipgloss.Color("#f8f8f2")).
+						Render("Loading student..."),
+				),
+			)
+	}
+
+	if m.err != nil {
+		return renderErrorView("Error loading student", m.err, m.width, m.height)
+	}
+
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(rosterName(m.student.UserID, m.student.Profile.Name) + " | " + m.course.Name)
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, m.renderProfile(), "")
+	switch {
+	case m.hidden:
+		sections = append(sections, muted.Render("Only teachers can see other students' submissions."), "")
+	case m.gradebook != nil:
+		sections = append(sections, m.renderSummary(), "", m.renderAssignments(), "")
+	}
+
+	if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, actionKey(keymap.Open, "open photo"), refreshKey(), backKey()))
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// renderFacts renders label and value pairs as aligned lines.
+func renderFacts(rows [][2]string) string {
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Width(14)
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		lines[i] = label.Render(r[0]) + value.Render(r[1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderProfile renders the student's email, photo, and ID.
+func (m *StudentProfileModel) renderProfile() string {
+	profile := m.student.Profile
+	orNone := func(s string) string {
+		if s == "" {
+			return "Not shared"
+		}
+		return s
+	}
+	return renderFacts([][2]string{
+		{"Email", orNone(profile.EmailAddress)},
+		{"Photo", orNone(photoLink(profile.PhotoURL))},
+		{"User ID", m.student.UserID},
+	})
+}
+
+// renderSummary renders the student's totals from the gradebook.
+func (m *StudentProfileModel) renderSummary() string {
+	row := m.row
+	average := "No graded work"
+	if row.Graded > 0 {
+		average = fmt.Sprintf("%.1f%% over %d graded", row.Average, row.Graded)
+	}
+	points := "-"
+	if row.Possible > 0 {
+		points = fmt.Sprintf("%d/%d", row.Earned, row.Possible)
+	}
+	return renderFacts([][2]string{
+		{"Turned in", fmt.Sprintf("%d of %d", row.TurnedIn, row.Assigned)},
+		{"Missing", fmt.Sprintf("%d", row.Missing)},
+		{"Late", fmt.Sprintf("%d", row.Late)},
+		{"Average", average},
+		{"Points", points},
+	})
+}
+
+// renderAssignments lists the student's result on each assignment, most
+// recently due last, fitting the screen.
+func (m *StudentProfileModel) renderAssignments() string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{muted.Render("Assignments:")}
+	type item struct {
+		title, status string
+		style         lipgloss.Style
+	}
+	var items []item
+	for i, col := range m.gradebook.Columns {
+		if i >= len(m.row.Cells) || !m.row.Cells[i].Assigned {
+			continue
+		}
+		status, style := describeCell(m.row.Cells[i], col.CourseWork.MaxPoints)
+		items = append(items, item{title: col.CourseWork.Title, status: status, style: style})
+	}
+	if len(items) == 0 {
+		return strings.Join(append(lines, muted.Render("  None assigned")), "\n")
+	}
+
+	visible := max(m.height-22, 5)
+	if len(items) > visible {
+		lines = append(lines, muted.Render(fmt.Sprintf("  %d earlier not shown", len(items)-visible)))
+		items = items[len(items)-visible:]
+	}
+	width := 0
+	for _, it := range items {
+		width = max(width, lipgloss.Width(it.title))
+	}
+	width = min(width, max(m.width-24, 20))
+	for _, it := range items {
+		title := it.title
+		if runes := []rune(title); len(runes) > width {
+			title = string(runes[:width-1]) + "…"
+		}
+		lines = append(lines, "  "+title+strings.Repeat(" ", width-lipgloss.Width(title))+"  "+it.style.Render(it.status))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeCell describes a gradebook cell in words, with the color it is
+// shown in.
+func describeCell(c report.GradebookCell, maxPoints int) (string, lipgloss.Style) {
+	style := lipgloss.NewStyle()
+	switch {
+	case c.Graded:
+		return fmt.Sprintf("%d/%d", c.Points, maxPoints), style.Foreground(lipgloss.Color("#50fa7b"))
+	case c.Missing:
+		return "Missing", style.Foreground(lipgloss.Color("#ff5555"))
+	case c.TurnedIn && c.Late:
+		return "Turned in late", style.Foreground(lipgloss.Color("#ffb86c"))
+	case c.TurnedIn:
+		return "Turned in", style.Foreground(lipgloss.Color("#8be9fd"))
+	}
+	return "Not turned in", style.Foreground(lipgloss.Color("#6272a4"))
+}
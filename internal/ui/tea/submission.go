@@ -3,36 +3,73 @@ package tea
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/confirm"
+	"github.com/user/google-classroom/internal/connectivity"
+	"github.com/user/google-classroom/internal/keymap"
+	"github.com/user/google-classroom/internal/outbox"
 )
 
 // SubmissionModel represents the submission TUI model.
 type SubmissionModel struct {
 	course      *api.Course
 	courseWork  *api.CourseWork
-	apiClient   *api.Client
+	apiClient   api.ClassroomClient
+	refresh     bool // next load skips the cache
 	submissions []*api.StudentSubmission
 	table       table.Model
+	isTeacher   bool
+	stateFilter int
+	actionErr   error
+	prompt      *confirmation
+	offline     bool
+	syncNotice  string
 	loading     bool
 	err         error
+	help        helpOverlay
 	width       int
 	height      int
+	translation translation
+	download    download
+	rubric      rubricView
+	addOns      []*api.AddOnAttachment
+	history     submissionHistory
+	recipients  recipientList
+	link        browserLink
+	bulk        bulkSelection
+}
+
+// submissionFilter is a teacher's view of submissions by state.
+type submissionFilter struct {
+	label  string
+	states []string
+}
+
+// submissionFilters are the state filters teachers cycle through.
+var submissionFilters = []submissionFilter{
+	{label: "All"},
+	{label: "Turned in", states: []string{api.SubmissionStateTurnedIn}},
+	{label: "Not turned in", states: []string{api.SubmissionStateNew, api.SubmissionStateCreated, api.SubmissionStateReclaimed}},
+	{label: "Returned", states: []string{api.SubmissionStateReturned}},
 }
 
 // NewSubmissionModel creates a new submission model.
-func NewSubmissionModel(course *api.Course, courseWork *api.CourseWork, apiClient *api.Client) *SubmissionModel {
-	t := table.New()
+func NewSubmissionModel(course *api.Course, courseWork *api.CourseWork, apiClient api.ClassroomClient) *SubmissionModel {
+	t := table.New(table.WithKeyMap(tableKeyMap()))
 	t.SetHeight(15)
 
 	return &SubmissionModel{
 		course:     course,
 		courseWork: courseWork,
-		apiClient:  apiClient,
+		apiClient:  cache.NewCachedClient(apiClient, options.Cache),
 		table:      t,
 		loading:    true,
 	}
@@ -47,19 +84,119 @@ func (m *SubmissionModel) Init() tea.Cmd {
 func (m *SubmissionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc", "b":
+		if m.prompt != nil {
+			done, cmd := m.prompt.handleKey(msg)
+			if done {
+				m.prompt = nil
+			}
+			return m, cmd
+		}
+		if m.rubric.grading != nil && msg.String() != "ctrl+c" {
+			return m, m.handleRubricKey(msg.String())
+		}
+		if m.bulk.active() && msg.String() != "ctrl+c" {
+			prompt, cmd := m.bulk.handleKey(msg, m.apiClient, m.courseWork, m.submissions)
+			m.prompt = prompt
+			return m, cmd
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
 			return m, func() tea.Msg { return NavigateBackMsg{} }
-		case "r":
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
 			m.loading = true
 			m.err = nil
 			return m, m.loadSubmissions()
-		case "t":
-			return m, m.handleTurnIn()
-		case "enter":
+		case key.Matches(msg, bound(keymap.Open)):
+			link := m.courseWork.AlternateLink
+			if sub := m.selectedSubmission(); sub != nil && sub.AlternateLink != "" {
+				link = sub.AlternateLink
+			}
+			return m, m.link.open(link)
+		case key.Matches(msg, bound(keymap.Select)):
 			return m, m.handleViewSubmission()
+		case key.Matches(msg, bound(keymap.TurnIn)):
+			return m, m.handleTurnIn()
+		case key.Matches(msg, bound(keymap.Translate)):
+			return m, m.translation.toggle(m.courseWork.Description)
+		case key.Matches(msg, bound(keymap.Download)):
+			return m, m.download.start(m.selectedAttachments())
+		case key.Matches(msg, bound(keymap.Read)):
+			return m, m.download.read(m.selectedAttachments())
+		case key.Matches(msg, bound(keymap.Recipients)):
+			if m.isTeacher && m.courseWork.IsIndividual() {
+				return m, m.recipients.toggle(m.apiClient, m.course.ID)
+			}
+		case key.Matches(msg, bound(keymap.History)):
+			return m, m.history.toggle(m.apiClient, m.selectedSubmission())
+		case key.Matches(msg, bound(keymap.Rubric)):
+			m.rubric.toggle()
+		case key.Matches(msg, bound(keymap.Grade)):
+			if m.isTeacher && m.rubric.rubric != nil {
+				if sub := m.selectedSubmission(); sub != nil {
+					m.actionErr = nil
+					m.rubric.startGrading(sub)
+				}
+			}
+		case key.Matches(msg, bound(keymap.Mark)):
+			if sub := m.selectedSubmission(); m.isTeacher && sub != nil {
+				m.bulk.toggle(sub.ID)
+				m.updateTable()
+			}
+			// Space also pages the table down; marking should not move.
+			return m, nil
+		case key.Matches(msg, bound(keymap.MarkAll)):
+			if m.isTeacher {
+				m.bulk.toggleAll(m.submissions)
+				m.updateTable()
+			}
+		case key.Matches(msg, bound(keymap.Bulk)):
+			if m.isTeacher && m.bulk.open(m.submissions) {
+				return m, nil
+			}
+		case key.Matches(msg, bound(keymap.GradeAll)):
+			if m.isTeacher && len(m.submissions) > 0 {
+				course, courseWork, submissions := m.course, m.courseWork, m.submissions
+				return m, func() tea.Msg {
+					return OpenGradingMsg{Course: course, CourseWork: courseWork, Submissions: submissions}
+				}
+			}
+		case key.Matches(msg, bound(keymap.Filter)):
+			if m.isTeacher {
+				m.stateFilter = (m.stateFilter + 1) % len(submissionFilters)
+				m.loading = true
+				return m, m.loadSubmissions()
+			}
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
 		}
 
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.loadSubmissions(), afterRecovery(msg))
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -67,8 +204,30 @@ func (m *SubmissionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.table.SetHeight(msg.Height - 15)
 		return m, nil
 
+	case translatedMsg:
+		m.translation.update(msg)
+		return m, nil
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case historyLoadedMsg:
+		m.history.update(msg)
+		return m, nil
+
+	case recipientsLoadedMsg:
+		m.recipients.update(msg)
+		return m, nil
+
+	case downloadProgressMsg, downloadDoneMsg, pagerReadyMsg, pagerDoneMsg, scopeGrantedMsg:
+		return m, m.download.update(msg)
+
 	case submissionsLoadedMsg:
+		m.isTeacher = msg.isTeacher
 		m.submissions = msg.submissions
+		m.rubric.rubric = msg.rubric
+		m.addOns = msg.addOns
 		m.loading = false
 		m.err = nil
 		m.updateTable()
@@ -77,12 +236,48 @@ func (m *SubmissionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case submissionsLoadErrorMsg:
 		m.loading = false
 		m.err = msg.err
+		reportError(msg.err)
 		return m, nil
 
+	case connectivityMsg:
+		wasOffline := m.offline
+		m.offline = msg.state == connectivity.Offline
+		if wasOffline && !m.offline {
+			return m, tea.Batch(watchConnectivity(), syncOutbox(m.apiClient))
+		}
+		return m, watchConnectivity()
+
+	case outboxSyncedMsg:
+		m.syncNotice = renderSyncResult(msg)
+		m.loading = true
+		return m, m.loadSubmissions()
+
+	case bulkDoneMsg:
+		m.bulk.update(msg)
+		m.loading = true
+		return m, m.loadSubmissions()
+
+	case bulkExportedMsg:
+		m.bulk.update(msg)
+		return m, nil
+
+	case submissionQueuedMsg:
+		m.actionErr = nil
+		m.updateTable()
+		return m, notify(toastInfo, msg.notice)
+
 	case submissionUpdatedMsg:
 		m.loading = true
 		m.err = nil
-		return m, m.loadSubmissions()
+		m.actionErr = nil
+		return m, tea.Batch(m.loadSubmissions(), notify(toastSuccess, msg.notice))
+
+	case errorMsg:
+		if sessionExpired(msg.err) {
+			m.err = msg.err
+			return m, nil
+		}
+		return m, notifyError(msg.err)
 	}
 
 	var cmd tea.Cmd
@@ -108,37 +303,98 @@ func (m *SubmissionModel) View() string {
 	}
 
 	if m.err != nil {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center).
-			Render(
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#ff5555")).
-						Bold(true).
-						Render("Error loading submissions"),
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#f8f8f2")).
-						Render(m.err.Error()),
-				),
-			)
+		return renderErrorView("Error loading submissions", m.err, m.width, m.height)
 	}
 
 	// Render header
+	title := m.courseWork.Title
+	if m.isTeacher {
+		title += " | " + submissionFilters[m.stateFilter].label
+	} else if badge := focusBadge(m.course.ID, m.courseWork.ID); badge != "" {
+		title += " | " + badge
+	}
 	header := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#ff79c6")).
 		Bold(true).
-		Render(m.courseWork.Title)
+		Render(title)
 
 	// Render table
 	tableView := m.table.View()
 
 	// Render footer
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272a4")).
-		Render("↑↓ navigate | enter view | t turn in | r refresh | b back | q quit")
+	keys := []key.Binding{navigateKey(), actionKey(keymap.Select, "view")}
+	if m.isTeacher {
+		keys = append(keys, actionKey(keymap.Mark, "mark"), actionKey(keymap.MarkAll, "mark all"), actionKey(keymap.Bulk, "bulk"), actionKey(keymap.GradeAll, "grade all"), actionKey(keymap.Filter, "filter"))
+	} else {
+		keys = append(keys, actionKey(keymap.TurnIn, "turn in"))
+	}
+	keys = append(keys, actionKey(keymap.History, "history"), actionKey(keymap.Open, "open"))
+	if options.Translator != nil && m.courseWork.Description != "" {
+		keys = append(keys, actionKey(keymap.Translate, "translate"))
+	}
+	if options.Drive != nil {
+		keys = append(keys, actionKey(keymap.Download, "download"), actionKey(keymap.Read, "read"))
+	}
+	if m.rubric.rubric != nil {
+		keys = append(keys, actionKey(keymap.Rubric, "rubric"))
+		if m.isTeacher {
+			keys = append(keys, actionKey(keymap.Grade, "grade"))
+		}
+	}
+	keys = append(keys, refreshKey(), backKey(), quitKey())
+	footer := m.help.render(m.width, keys...)
+
+	sections := []string{header, ""}
+	if desc := m.renderDescription(); desc != "" {
+		sections = append(sections, desc, "")
+	}
+	if question := m.renderQuestion(); question != "" {
+		sections = append(sections, question, "")
+	}
+	if materials := m.renderMaterials(); materials != "" {
+		sections = append(sections, materials, "")
+	}
+	if recipients := m.recipients.render(m.courseWork.AssigneeMode, m.courseWork.StudentIDs); m.isTeacher && recipients != "" {
+		sections = append(sections, recipients, "")
+	}
+	if folder := m.courseWork.WorkFolder; m.isTeacher && folder != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272a4")).
+			Render("Work folder: "+folder.Title), "")
+	}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	if badge := budgetBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, tableView, "")
+	if rubric := m.rubric.render(m.selectedSubmission()); rubric != "" {
+		sections = append(sections, rubric, "")
+	}
+	if history := m.history.render(m.selectedSubmission()); history != "" {
+		sections = append(sections, history, "")
+	}
+	if bulk := m.bulk.render(m.submissions, m.courseWork.MaxPoints); bulk != "" {
+		sections = append(sections, bulk, "")
+	}
+	if m.prompt != nil {
+		sections = append(sections, m.prompt.View())
+	} else if m.actionErr != nil {
+		sections = append(sections, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(errorText(m.actionErr)))
+	} else if status := m.download.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	} else if m.syncNotice != "" {
+		sections = append(sections, m.syncNotice)
+	}
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, footer)
 
 	return lipgloss.NewStyle().
 		Width(m.width).
@@ -147,36 +403,168 @@ func (m *SubmissionModel) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Left,
-				header,
-				"",
-				tableView,
-				"",
-				footer,
+				sections...,
 			),
 		)
 }
 
+// descriptionLines is how many lines of the coursework description are
+// shown above the submissions.
+const descriptionLines = 3
+
+// renderDescription renders the start of the coursework description, or
+// its translation after 'T'.
+func (m *SubmissionModel) renderDescription() string {
+	if m.courseWork.Description == "" {
+		return ""
+	}
+	text, status := m.translation.render(m.courseWork.Description)
+	lines := wrapText(text, m.width-4)
+	if len(lines) > descriptionLines {
+		lines = append(lines[:descriptionLines-1], lines[descriptionLines-1]+" ...")
+	}
+	desc := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f8f8f2")).
+		Render(strings.Join(lines, "\n"))
+	if status != "" {
+		desc = lipgloss.JoinVertical(lipgloss.Left, desc, status)
+	}
+	return desc
+}
+
+// attachmentKinds names attachment kinds in the materials list.
+var attachmentKinds = map[string]string{
+	api.AttachmentDriveFile: "Drive file",
+	api.AttachmentLink:      "link",
+	api.AttachmentYouTube:   "YouTube video",
+	api.AttachmentForm:      "form",
+}
+
+// renderMaterials lists the coursework's materials followed by its add-on
+// attachments, or returns "" when it has neither.
+func (m *SubmissionModel) renderMaterials() string {
+	return renderMaterials(m.courseWork.Materials, m.addOns)
+}
+
+// renderMaterials lists materials followed by add-on attachments, or
+// returns "" when there are neither.
+func renderMaterials(materials []api.Attachment, addOns []*api.AddOnAttachment) string {
+	if len(materials) == 0 && len(addOns) == 0 {
+		return ""
+	}
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{muted.Render("Materials:")}
+	for _, a := range materials {
+		line := "  " + a.Title
+		if kind := attachmentKinds[a.Kind]; kind != "" {
+			line += muted.Render(" (" + kind + ")")
+		}
+		lines = append(lines, line)
+	}
+	for _, a := range addOns {
+		kind := "add-on"
+		if a.MaxPoints > 0 {
+			kind += ", " + formatPoints(a.MaxPoints) + " pts"
+		}
+		lines = append(lines, "  "+a.Title+muted.Render(" ("+kind+")"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderQuestion renders a question's choices and the selected
+// submission's answer, or "" for other work types.
+func (m *SubmissionModel) renderQuestion() string {
+	if !m.courseWork.IsQuestion() {
+		return ""
+	}
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	chosen := lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true)
+
+	answer := ""
+	if sub := m.selectedSubmission(); sub != nil {
+		answer = sub.Answer
+	}
+
+	var lines []string
+	for _, choice := range m.courseWork.Choices {
+		if choice == answer {
+			lines = append(lines, chosen.Render("● "+choice))
+		} else {
+			lines = append(lines, muted.Render("○ "+choice))
+		}
+	}
+	if m.courseWork.WorkType == api.WorkTypeShortAnswer && answer != "" {
+		lines = append(lines, wrapText("Answer: "+answer, m.width-4)...)
+	}
+	switch {
+	case answer != "":
+	case m.isTeacher:
+		lines = append(lines, muted.Render("No answer yet"))
+	default:
+		// The API only lets teachers change submissions, so answers are
+		// given in Classroom.
+		lines = append(lines, muted.Render("Answer this question in Classroom"))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // loadSubmissions loads submissions from the API.
 func (m *SubmissionModel) loadSubmissions() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := loadContext(refresh)
 		defer cancel()
 
-		submissions, err := m.apiClient.ListStudentSubmissions(ctx, m.course.ID, m.courseWork.ID)
+		isTeacher, err := m.apiClient.IsTeacher(ctx, m.course.ID)
+		if err != nil {
+			return submissionsLoadErrorMsg{err: err}
+		}
+
+		// Students only ever see and act on their own submission.
+		opts := &api.ListStudentSubmissionsOptions{UserID: "me"}
+		if isTeacher {
+			opts = &api.ListStudentSubmissionsOptions{States: submissionFilters[m.stateFilter].states}
+		}
+
+		submissions, err := m.apiClient.ListStudentSubmissions(ctx, m.course.ID, m.courseWork.ID, opts)
 		if err != nil {
 			return submissionsLoadErrorMsg{err: err}
 		}
-		return submissionsLoadedMsg{submissions: submissions}
+
+		// Rubrics need a license the domain may not have, so the
+		// submissions are shown without one when it cannot be read.
+		rubric, err := m.apiClient.GetRubric(ctx, m.course.ID, m.courseWork.ID)
+		if err != nil {
+			rubric = nil
+		}
+		// Likewise, only users of the add-on that made them may list
+		// add-on attachments.
+		addOns, err := m.apiClient.ListAddOnAttachments(ctx, m.course.ID, m.courseWork.ID)
+		if err != nil {
+			addOns = nil
+		}
+		return submissionsLoadedMsg{submissions: submissions, isTeacher: isTeacher, rubric: rubric, addOns: addOns}
 	}
 }
 
 // updateTable updates the table with submission data.
 func (m *SubmissionModel) updateTable() {
-	columns := []table.Column{
+	var columns []table.Column
+	if m.isTeacher {
+		columns = append(columns, table.Column{Title: "", Width: 3})
+	}
+	columns = append(columns, []table.Column{
 		{Title: "State", Width: 15},
 		{Title: "Grade", Width: 10},
 		{Title: "Late", Width: 10},
 		{Title: "Updated", Width: 20},
+	}...)
+	// Teachers compare answers at a glance; the full answer is shown for
+	// the selected submission.
+	showAnswers := m.isTeacher && m.courseWork.IsQuestion()
+	if showAnswers {
+		columns = append(columns, table.Column{Title: "Answer", Width: 30})
 	}
 
 	rows := make([]table.Row, len(m.submissions))
@@ -189,11 +577,19 @@ func (m *SubmissionModel) updateTable() {
 		if s.Late {
 			late = "Yes"
 		}
-		rows[i] = table.Row{
-			s.State,
-			grade,
-			late,
-			s.UpdateTime[:19],
+		state := s.State
+		if pendingSync(outbox.KindTurnIn, s.ID) {
+			state = "Pending sync"
+		}
+		if len(m.submissions) > 1 && isMe(s.UserID) {
+			state += " (you)"
+		}
+		if m.isTeacher {
+			rows[i] = table.Row{m.bulk.mark(s)}
+		}
+		rows[i] = append(rows[i], state, grade, late, formatTime(s.UpdateTime, "2006-01-02 15:04"))
+		if showAnswers {
+			rows[i] = append(rows[i], s.Answer)
 		}
 	}
 
@@ -201,30 +597,131 @@ func (m *SubmissionModel) updateTable() {
 	m.table.SetRows(rows)
 }
 
-// handleTurnIn handles the turn-in action.
+// ownSubmission returns the signed-in user's submission, or nil if there
+// is none. The student listing is scoped to "me", so its first submission
+// is the user's even while the user is unknown.
+func (m *SubmissionModel) ownSubmission() *api.StudentSubmission {
+	for _, s := range m.submissions {
+		if isMe(s.UserID) {
+			return s
+		}
+	}
+	if len(m.submissions) == 0 {
+		return nil
+	}
+	return m.submissions[0]
+}
+
+// handleTurnIn turns in the current user's own submission.
 func (m *SubmissionModel) handleTurnIn() tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+	if m.isTeacher {
+		m.actionErr = fmt.Errorf("only students can turn in their own work")
+		return nil
+	}
 
-		// Find the current user's submission
-		// For simplicity, we'll turn in the first submission in the list
-		if len(m.submissions) == 0 {
-			return errorMsg{err: fmt.Errorf("no submissions found")}
-		}
+	sub := m.ownSubmission()
+	if sub == nil {
+		m.actionErr = fmt.Errorf("you have no submission for this coursework")
+		return nil
+	}
+	prompt, cmd, err := confirmTurnIn(m.apiClient, m.course.ID, m.courseWork, sub)
+	if err != nil {
+		m.actionErr = err
+		return nil
+	}
+	m.prompt = prompt
+	return cmd
+}
+
+// confirmTurnIn asks to turn in the user's own submission, then turns it
+// in, or queues the turn-in while offline. The result arrives as
+// submissionUpdatedMsg, submissionQueuedMsg, or errorMsg. It returns an
+// error when the submission cannot be turned in.
+func confirmTurnIn(client api.ClassroomClient, courseID string, courseWork *api.CourseWork, sub *api.StudentSubmission) (*confirmation, tea.Cmd, error) {
+	if pendingSync(outbox.KindTurnIn, sub.ID) {
+		return nil, nil, fmt.Errorf("turn-in is already waiting to sync")
+	}
+	if !sub.CanTurnIn() {
+		return nil, nil, fmt.Errorf("submission is %s and cannot be turned in", sub.State)
+	}
+
+	courseWorkID := courseWork.ID
+	entry := outbox.Entry{
+		Kind:           outbox.KindTurnIn,
+		CourseID:       courseID,
+		CourseWorkID:   courseWorkID,
+		TargetID:       sub.ID,
+		Label:          fmt.Sprintf("Turn in %q", courseWork.Title),
+		BaseUpdateTime: sub.UpdateTime,
+	}
+	commit := func(ctx context.Context) error {
+		return client.TurnIn(ctx, courseID, courseWorkID, sub.ID)
+	}
 
-		sub := m.submissions[0]
-		if sub.State != "NEW" && sub.State != "CREATED" {
-			return errorMsg{err: fmt.Errorf("submission cannot be turned in")}
+	queued := submissionQueuedMsg{notice: fmt.Sprintf("Turn-in of %q will sync when back online", courseWork.Title)}
+	turnIn := func() tea.Msg {
+		if isOffline() && queueOffline(entry, commit) {
+			return queued
 		}
 
-		err := m.apiClient.TurnIn(ctx, m.course.ID, m.courseWork.ID, sub.ID)
-		if err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := commit(ctx); err != nil {
+			if deferIfOffline(err, entry, commit) {
+				return queued
+			}
 			return errorMsg{err: err}
 		}
 
-		return submissionUpdatedMsg{}
+		return submissionUpdatedMsg{notice: fmt.Sprintf("Turned in %q", courseWork.Title)}
+	}
+
+	prompt, cmd := requireConfirmation(confirm.TurnIn,
+		fmt.Sprintf("Turn in your submission for %q?", courseWork.Title),
+		func() tea.Cmd { return turnIn })
+	return prompt, cmd, nil
+}
+
+// selectedSubmission returns the submission under the cursor, or nil.
+func (m *SubmissionModel) selectedSubmission() *api.StudentSubmission {
+	selected := m.table.Cursor()
+	if selected >= 0 && selected < len(m.submissions) {
+		return m.submissions[selected]
+	}
+	return nil
+}
+
+// handleRubricKey handles a key while grading with the rubric.
+func (m *SubmissionModel) handleRubricKey(key string) tea.Cmd {
+	save, done := m.rubric.handleKey(key)
+	if done {
+		m.actionErr = nil
+	}
+	if !save {
+		return nil
+	}
+	total, err := m.rubric.total()
+	if err != nil {
+		m.actionErr = err
+		return nil
+	}
+	sub := m.rubric.grading
+	m.rubric.grading = nil
+	m.actionErr = nil
+	return saveDraftGrade(m.apiClient, m.course, m.courseWork, sub, total)
+}
+
+// selectedAttachments returns the files attached to the selected
+// submission, or the coursework's materials when it has none.
+func (m *SubmissionModel) selectedAttachments() []api.Attachment {
+	selected := m.table.Cursor()
+	if selected >= 0 && selected < len(m.submissions) {
+		if attachments := m.submissions[selected].Attachments; len(attachments) > 0 {
+			return attachments
+		}
 	}
+	return m.courseWork.Materials
 }
 
 // handleViewSubmission handles viewing submission details.
@@ -250,6 +747,9 @@ func (m *SubmissionModel) handleViewSubmission() tea.Cmd {
 // submissionsLoadedMsg is sent when submissions are loaded.
 type submissionsLoadedMsg struct {
 	submissions []*api.StudentSubmission
+	isTeacher   bool
+	rubric      *api.Rubric
+	addOns      []*api.AddOnAttachment
 }
 
 // submissionsLoadErrorMsg is sent when submissions fail to load.
@@ -257,8 +757,17 @@ type submissionsLoadErrorMsg struct {
 	err error
 }
 
-// submissionUpdatedMsg is sent when a submission is updated.
-type submissionUpdatedMsg struct{}
+// submissionUpdatedMsg is sent when a submission is updated. notice
+// describes the change for a toast.
+type submissionUpdatedMsg struct {
+	notice string
+}
+
+// submissionQueuedMsg is sent when a change to a submission was queued
+// while offline. notice describes it for a toast.
+type submissionQueuedMsg struct {
+	notice string
+}
 
 // SubmissionDetailMsg is sent when a submission is selected.
 type SubmissionDetailMsg struct {
@@ -0,0 +1,340 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/cache"
+	"github.com/user/google-classroom/internal/keymap"
+)
+
+// SubmissionDetailModel shows one submission: who it is from, its grade,
+// the files attached to it, and its history. The arrow keys pick an
+// attachment to download, read, or open.
+type SubmissionDetailModel struct {
+	course     *api.Course
+	courseWork *api.CourseWork
+	// submission starts as the row selected on the submissions screen and
+	// is replaced by the full submission, with history, once loaded.
+	submission *api.StudentSubmission
+	apiClient  api.ClassroomClient
+	refresh    bool // next load skips the cache
+	isTeacher  bool
+	names      map[string]string
+	cursor     int // selected attachment
+	spinner    spinner.Model
+	loading    bool
+	err        error
+	download   download
+	link       browserLink
+	help       helpOverlay
+	width      int
+	height     int
+}
+
+// submissionDetailLoadedMsg carries the full submission and the names of
+// the people in the course.
+type submissionDetailLoadedMsg struct {
+	submission *api.StudentSubmission
+	isTeacher  bool
+	names      map[string]string
+	err        error
+}
+
+// NewSubmissionDetailModel creates the detail screen of sub.
+func NewSubmissionDetailModel(course *api.Course, courseWork *api.CourseWork, sub *api.StudentSubmission, apiClient api.ClassroomClient) *SubmissionDetailModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff79c6"))
+
+	return &SubmissionDetailModel{
+		course:     course,
+		courseWork: courseWork,
+		submission: sub,
+		apiClient:  cache.NewCachedClient(apiClient, options.Cache),
+		spinner:    s,
+		loading:    true,
+	}
+}
+
+// Init initializes the model.
+func (m *SubmissionDetailModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.load(), watchConnectivity())
+}
+
+// load fetches the submission with its history, which list calls leave
+// out, and the roster to name the people in it.
+func (m *SubmissionDetailModel) load() tea.Cmd {
+	refresh := m.refresh
+	m.refresh = false
+	client, sub, names := m.apiClient, m.submission, m.names
+	return func() tea.Msg {
+		ctx, cancel := loadContext(refresh)
+		defer cancel()
+
+		isTeacher, err := client.IsTeacher(ctx, sub.CourseID)
+		if err != nil {
+			return submissionDetailLoadedMsg{err: err}
+		}
+		full, err := client.GetStudentSubmission(ctx, sub.CourseID, sub.CourseWorkID, sub.ID)
+		if err != nil {
+			return submissionDetailLoadedMsg{err: err}
+		}
+		if names == nil {
+			names = rosterNames(ctx, client, sub.CourseID)
+		}
+		return submissionDetailLoadedMsg{submission: full, isTeacher: isTeacher, names: names}
+	}
+}
+
+// Update handles messages.
+func (m *SubmissionDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, bound(keymap.Quit), bound(keymap.Back)):
+			return m, func() tea.Msg { return NavigateBackMsg{} }
+		case key.Matches(msg, bound(keymap.Refresh)):
+			m.refresh = true
+			m.loading = true
+			m.err = nil
+			return m, m.load()
+		case key.Matches(msg, bound(keymap.Up)):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, bound(keymap.Down)):
+			if m.cursor < len(m.submission.Attachments)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, bound(keymap.Open)):
+			if a := m.selectedAttachments(); len(a) > 0 {
+				return m, m.link.openOr(a[0].URL, "this attachment has no link")
+			}
+			return m, m.link.open(m.submission.AlternateLink)
+		case key.Matches(msg, bound(keymap.Download)):
+			return m, m.download.start(m.selectedAttachments())
+		case key.Matches(msg, bound(keymap.DownloadAll)):
+			return m, m.download.start(m.submission.Attachments)
+		case key.Matches(msg, bound(keymap.Read)):
+			return m, m.download.read(m.selectedAttachments())
+		default:
+			switch msg.String() {
+			case "?":
+				m.help.toggle()
+				return m, nil
+			case "L", "C", "D", "P":
+				if m.err != nil {
+					return m, recoverFromError(m.err, msg.String())
+				}
+			}
+		}
+
+	case submissionDetailLoadedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err != nil {
+			reportError(msg.err)
+			return m, nil
+		}
+		m.submission = msg.submission
+		m.isTeacher = msg.isTeacher
+		m.names = msg.names
+		m.cursor = min(m.cursor, max(len(m.submission.Attachments)-1, 0))
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case reauthMsg:
+		m.loading = false
+		m.err = reauthError(msg)
+		return m, nil
+
+	case recoveryDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, tea.Batch(m.load(), afterRecovery(msg))
+
+	case downloadProgressMsg, downloadDoneMsg, pagerReadyMsg, pagerDoneMsg, scopeGrantedMsg:
+		return m, m.download.update(msg)
+
+	case linkOpenedMsg:
+		m.link.update(msg)
+		return m, nil
+
+	case toastMsg, toastExpiredMsg:
+		return m, updateToasts(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case connectivityMsg:
+		return m, watchConnectivity()
+	}
+	return m, nil
+}
+
+// selectedAttachments returns the attachment under the cursor, or none.
+func (m *SubmissionDetailModel) selectedAttachments() []api.Attachment {
+	if m.cursor < len(m.submission.Attachments) {
+		return m.submission.Attachments[m.cursor : m.cursor+1]
+	}
+	return nil
+}
+
+// name returns a user's full name, "you" for the user, or their ID when
+// it is unknown.
+func (m *SubmissionDetailModel) name(userID string) string {
+	if isMe(userID) {
+		return "you"
+	}
+	if name := m.names[userID]; name != "" {
+		return name
+	}
+	return userID
+}
+
+// View renders the model.
+func (m *SubmissionDetailModel) View() string {
+	if m.loading {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Align(lipgloss.Center).
+			Render(
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					m.spinner.View(),
+					lipgloss.NewStyle().
+						Foreground(lipgloss.Color("#f8f8f2")).
+						Render("Loading submission..."),
+				),
+			)
+	}
+
+	if m.err != nil {
+		return renderErrorView("Error loading submission", m.err, m.width, m.height)
+	}
+
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff79c6")).
+		Bold(true).
+		Render(m.courseWork.Title + " | " + m.name(m.submission.UserID))
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+
+	sections := []string{header, ""}
+	if badge := offlineBadge(); badge != "" {
+		sections = append(sections, badge, "")
+	}
+	sections = append(sections, m.renderGrade(), "")
+	if answer := m.submission.Answer; answer != "" {
+		sections = append(sections, muted.Render("Answer:"), strings.Join(wrapText(answer, max(m.width-4, 20)), "\n"), "")
+	}
+	sections = append(sections, m.renderAttachments(), "", m.renderHistory(), "")
+	// The Classroom API has no endpoint for private comments, so they can
+	// only be read and written in Classroom itself.
+	sections = append(sections, muted.Render("Private comments are only available in Classroom; press "+bound(keymap.Open).Help().Key+" to open it."), "")
+
+	if status := m.download.render(); status != "" {
+		sections = append(sections, status)
+	} else if status := m.link.render(); status != "" {
+		sections = append(sections, status)
+	}
+
+	keys := []key.Binding{actionPair(keymap.Up, keymap.Down, "attachment")}
+	if options.Drive != nil && len(m.submission.Attachments) > 0 {
+		keys = append(keys, actionKey(keymap.Download, "download"), actionKey(keymap.DownloadAll, "download all"), actionKey(keymap.Read, "read"))
+	}
+	keys = append(keys, actionKey(keymap.Open, "open"), refreshKey(), backKey())
+	if bar := statusBar(m.course, m.width); bar != "" {
+		sections = append(sections, bar)
+	}
+	sections = append(sections, m.help.render(m.width, keys...))
+	return lipgloss.NewStyle().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// renderGrade renders the submission's state, grades, and lateness.
+// Draft grades are only shown to teachers, as students never see them.
+func (m *SubmissionDetailModel) renderGrade() string {
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4")).Width(10)
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8f8f2"))
+	sub := m.submission
+
+	grade := "Not graded"
+	if sub.AssignedGrade > 0 {
+		grade = fmt.Sprintf("%d/%d", sub.AssignedGrade, m.courseWork.MaxPoints)
+	}
+	rows := [][2]string{{"State", describeEvent(api.HistoryEvent{State: sub.State})}, {"Grade", grade}}
+	if m.isTeacher && sub.DraftGrade > 0 {
+		rows = append(rows, [2]string{"Draft", fmt.Sprintf("%d/%d", sub.DraftGrade, m.courseWork.MaxPoints)})
+	}
+	late := "No"
+	if sub.Late {
+		late = "Yes"
+	}
+	rows = append(rows, [2]string{"Late", late}, [2]string{"Updated", historyTime(sub.UpdateTime)})
+
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		lines[i] = label.Render(r[0]) + value.Render(r[1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderAttachments lists the submission's attachments with the selected
+// one highlighted.
+func (m *SubmissionDetailModel) renderAttachments() string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{muted.Render("Attachments:")}
+	if len(m.submission.Attachments) == 0 {
+		return strings.Join(append(lines, muted.Render("  None")), "\n")
+	}
+	for i, a := range m.submission.Attachments {
+		line := a.Title
+		if kind := attachmentKinds[a.Kind]; kind != "" {
+			line += muted.Render(" (" + kind + ")")
+		}
+		if i == m.cursor {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#50fa7b")).Bold(true).Render("> ")+line)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderHistory renders the submission's history as a timeline, oldest
+// first.
+func (m *SubmissionDetailModel) renderHistory() string {
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	lines := []string{muted.Render("History:")}
+	if len(m.submission.History) == 0 {
+		return strings.Join(append(lines, muted.Render("  No changes yet")), "\n")
+	}
+	for i, e := range m.submission.History {
+		marker := "├"
+		if i == len(m.submission.History)-1 {
+			marker = "└"
+		}
+		line := fmt.Sprintf("  %s %-13s %s", marker, historyTime(e.Time), describeEvent(e))
+		if e.ActorUserID != "" {
+			line += " by " + m.name(e.ActorUserID)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,121 @@
+package tea
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxToasts is the number of toasts shown at once; older ones are dropped.
+const maxToasts = 3
+
+// toastKind is what a toast reports, which sets its color and how long it
+// stays.
+type toastKind int
+
+const (
+	toastSuccess toastKind = iota
+	toastInfo
+	toastError
+)
+
+// toastMsg shows a toast. Any model may emit it with notify; every screen
+// passes it to updateToasts, so a toast emitted as a screen closes shows
+// on the next one.
+type toastMsg struct {
+	kind toastKind
+	text string
+}
+
+// toastExpiredMsg dismisses the toast with id.
+type toastExpiredMsg struct {
+	id int
+}
+
+// toast is a shown toast.
+type toast struct {
+	id   int
+	kind toastKind
+	text string
+}
+
+// toasts are the toasts on screen, oldest first. They outlive screens, as
+// results often arrive after going back.
+var (
+	toasts    []toast
+	lastToast int
+)
+
+// notify returns a command showing a toast, or nil for empty text.
+func notify(kind toastKind, text string) tea.Cmd {
+	if text == "" {
+		return nil
+	}
+	return func() tea.Msg { return toastMsg{kind: kind, text: text} }
+}
+
+// notifyError returns a command showing err as an error toast.
+func notifyError(err error) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+	return notify(toastError, errorText(err))
+}
+
+// lifetime returns how long a toast of kind stays. Errors stay longer so
+// they can be read.
+func (k toastKind) lifetime() time.Duration {
+	if k == toastError {
+		return 8 * time.Second
+	}
+	return 4 * time.Second
+}
+
+// updateToasts shows or dismisses toasts, returning the command that
+// dismisses a new one once its time is up.
+func updateToasts(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case toastMsg:
+		lastToast++
+		id := lastToast
+		toasts = append(toasts, toast{id: id, kind: msg.kind, text: msg.text})
+		if len(toasts) > maxToasts {
+			toasts = toasts[len(toasts)-maxToasts:]
+		}
+		return tea.Tick(msg.kind.lifetime(), func(time.Time) tea.Msg { return toastExpiredMsg{id: id} })
+	case toastExpiredMsg:
+		for i, t := range toasts {
+			if t.id == msg.id {
+				toasts = append(toasts[:i], toasts[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// renderToasts renders the toasts on screen, newest last, or "" when there
+// are none.
+func renderToasts(width int) string {
+	if len(toasts) == 0 {
+		return ""
+	}
+	lines := make([]string, len(toasts))
+	for i, t := range toasts {
+		icon, color := "✓", "#50fa7b"
+		switch t.kind {
+		case toastInfo:
+			icon, color = "ℹ", "#8be9fd"
+		case toastError:
+			icon, color = "✗", "#ff5555"
+		}
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true)
+		if width > 4 {
+			style = style.MaxWidth(width - 4)
+		}
+		lines[i] = style.Render(icon + " " + t.text)
+	}
+	return strings.Join(lines, "\n")
+}
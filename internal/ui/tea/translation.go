@@ -0,0 +1,85 @@
+package tea
+
+import (
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// translatedMsg carries the translation of source.
+type translatedMsg struct {
+	source string
+	text   string
+	err    error
+}
+
+// translation holds the on-demand translation shown for one text. 'T'
+// toggles between the original and the translation.
+type translation struct {
+	source  string
+	text    string
+	err     error
+	pending bool
+	shown   bool
+}
+
+// toggle shows or hides the translation of source, starting a translation
+// when there is none yet. It returns nil when translation is not
+// configured.
+func (t *translation) toggle(source string) tea.Cmd {
+	if options.Translator == nil || source == "" {
+		return nil
+	}
+	if t.source != source {
+		*t = translation{source: source}
+	}
+	if t.shown {
+		t.shown = false
+		return nil
+	}
+	t.shown = true
+	if t.text != "" || t.pending {
+		return nil
+	}
+
+	t.pending = true
+	t.err = nil
+	translator := options.Translator
+	return func() tea.Msg {
+		ctx, cancel := loadContext(false)
+		defer cancel()
+		text, err := translator.Translate(ctx, source)
+		return translatedMsg{source: source, text: text, err: err}
+	}
+}
+
+// update applies a finished translation if it is still for the shown text.
+func (t *translation) update(msg translatedMsg) {
+	if msg.source != t.source {
+		return
+	}
+	t.pending = false
+	t.text, t.err = msg.text, msg.err
+	if msg.err != nil {
+		t.shown = false
+	}
+}
+
+// render returns source, or its translation while one is shown, and a
+// status line describing which is displayed.
+func (t *translation) render(source string) (string, string) {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	if t.source != source {
+		return source, ""
+	}
+	switch {
+	case t.err != nil:
+		return source, lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render("Translation failed: " + t.err.Error())
+	case t.shown && t.pending:
+		return source, style.Render("Translating...")
+	case t.shown:
+		return t.text, style.Render("Translated to " + options.Translator.Language() + " | T show original")
+	}
+	return source, ""
+}
@@ -0,0 +1,259 @@
+package tea
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/outbox"
+)
+
+// defaultUndoWindow is how long a deletion can be undone before it is sent.
+const defaultUndoWindow = 5 * time.Second
+
+// undoWindow returns the configured undo window.
+func undoWindow() time.Duration {
+	if options.UndoWindow > 0 {
+		return options.UndoWindow
+	}
+	return defaultUndoWindow
+}
+
+// pendingDeletion is a deletion waiting out its undo window.
+type pendingDeletion struct {
+	id       int
+	key      string
+	label    string
+	deadline time.Time
+	entry    outbox.Entry
+	commit   func(ctx context.Context) error
+	restore  func()
+}
+
+// deletionQueue holds deletions back for the undo window before calling the
+// API. The item is hidden immediately; restore puts it back on undo or when
+// the API call fails. Failures and offline deferrals are also toasted, as
+// deletions flushed on leaving the screen finish after it has closed.
+type deletionQueue struct {
+	pending []*pendingDeletion
+	nextID  int
+	ticking bool
+	failure string
+	notice  string
+}
+
+// deletionExpiredMsg is sent when a deletion's undo window closes.
+type deletionExpiredMsg struct {
+	id int
+}
+
+// deletionTickMsg refreshes the undo countdown.
+type deletionTickMsg struct{}
+
+// deletionDoneMsg is sent when a queued deletion has been sent to the API.
+type deletionDoneMsg struct {
+	label   string
+	entry   outbox.Entry
+	commit  func(ctx context.Context) error
+	restore func()
+	queued  bool
+	err     error
+}
+
+// Queue schedules a deletion. key identifies the item so reloads can keep it
+// hidden; commit runs once the undo window closes. entry describes the
+// deletion for the offline outbox.
+func (q *deletionQueue) Queue(key, label string, entry outbox.Entry, commit func(ctx context.Context) error, restore func()) tea.Cmd {
+	q.nextID++
+	entry.Label = "Delete " + label
+	d := &pendingDeletion{
+		id:       q.nextID,
+		key:      key,
+		label:    label,
+		deadline: time.Now().Add(undoWindow()),
+		entry:    entry,
+		commit:   commit,
+		restore:  restore,
+	}
+	q.pending = append(q.pending, d)
+	q.failure = ""
+	q.notice = ""
+
+	id := d.id
+	cmds := []tea.Cmd{tea.Tick(undoWindow(), func(time.Time) tea.Msg {
+		return deletionExpiredMsg{id: id}
+	})}
+	if !q.ticking {
+		q.ticking = true
+		cmds = append(cmds, deletionTick())
+	}
+	return tea.Batch(cmds...)
+}
+
+// Pending reports whether the item with key is waiting to be deleted.
+func (q *deletionQueue) Pending(key string) bool {
+	for _, d := range q.pending {
+		if d.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Undo restores the most recently queued deletion. It reports whether there
+// was anything to undo.
+func (q *deletionQueue) Undo() bool {
+	if len(q.pending) == 0 {
+		return false
+	}
+	d := q.pending[len(q.pending)-1]
+	q.pending = q.pending[:len(q.pending)-1]
+	d.restore()
+	return true
+}
+
+// Flush sends every pending deletion now, e.g. when leaving the screen.
+func (q *deletionQueue) Flush() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, d := range q.pending {
+		cmds = append(cmds, d.send())
+	}
+	q.pending = nil
+	return tea.Batch(cmds...)
+}
+
+// Update handles the queue's own messages. It reports whether msg was one.
+func (q *deletionQueue) Update(msg tea.Msg) (bool, tea.Cmd) {
+	switch msg := msg.(type) {
+	case deletionExpiredMsg:
+		for i, d := range q.pending {
+			if d.id == msg.id {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				return true, d.send()
+			}
+		}
+		return true, nil
+
+	case deletionTickMsg:
+		if len(q.pending) == 0 {
+			q.ticking = false
+			return true, nil
+		}
+		return true, deletionTick()
+
+	case deletionDoneMsg:
+		if msg.queued || deferIfOffline(msg.err, msg.entry, msg.commit) {
+			q.notice = fmt.Sprintf("Offline: %s will be deleted when the connection returns", msg.label)
+			return true, notify(toastInfo, q.notice)
+		}
+		if msg.err != nil {
+			msg.restore()
+			q.failure = fmt.Sprintf("Could not delete %s: %s", msg.label, errorText(msg.err))
+			return true, notify(toastError, q.failure)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// View renders the undo prompt for the latest pending deletion, or the last
+// failure. It is empty when there is nothing to show.
+func (q *deletionQueue) View() string {
+	if len(q.pending) > 0 {
+		d := q.pending[len(q.pending)-1]
+		remaining := time.Until(d.deadline).Round(time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+		text := fmt.Sprintf("Deleted %s | u undo (%s)", d.label, remaining)
+		if more := len(q.pending) - 1; more > 0 {
+			text += fmt.Sprintf(" | %d more pending", more)
+		}
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f1fa8c")).
+			Render(text)
+	}
+	if q.failure != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555")).
+			Render(q.failure)
+	}
+	if q.notice != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f1fa8c")).
+			Render(q.notice)
+	}
+	return ""
+}
+
+// send runs the deletion against the API, or queues it while offline.
+func (d *pendingDeletion) send() tea.Cmd {
+	return func() tea.Msg {
+		if isOffline() && queueOffline(d.entry, d.commit) {
+			return deletionDoneMsg{label: d.label, queued: true}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		return deletionDoneMsg{label: d.label, entry: d.entry, commit: d.commit, restore: d.restore, err: d.commit(ctx)}
+	}
+}
+
+// deletionTick schedules the next countdown refresh.
+func deletionTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return deletionTickMsg{}
+	})
+}
+
+// withoutPending returns items minus those waiting to be deleted.
+func withoutPending[T any](q *deletionQueue, items []T, key func(T) string) []T {
+	if len(q.pending) == 0 {
+		return items
+	}
+	kept := make([]T, 0, len(items))
+	for _, item := range items {
+		if !q.Pending(key(item)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// removeAt returns items without the element at index i.
+func removeAt[T any](items []T, i int) []T {
+	return append(items[:i:i], items[i+1:]...)
+}
+
+// keyAfter returns the key of the item following index i, or "" when i is
+// the last.
+func keyAfter[T any](items []T, i int, key func(T) string) string {
+	if i+1 >= len(items) {
+		return ""
+	}
+	return key(items[i+1])
+}
+
+// restoreItem returns items with item put back before the item keyed next,
+// or appended when next is gone. Positions are not kept, as reloads and other
+// deletions reorder the list while item is hidden; an item a reload already
+// brought back is left as it is.
+func restoreItem[T any](items []T, item T, next string, key func(T) string) []T {
+	id, at := key(item), len(items)
+	for i, it := range items {
+		k := key(it)
+		if k == id {
+			return items
+		}
+		if next != "" && k == next {
+			at = i
+		}
+	}
+	out := make([]T, 0, len(items)+1)
+	out = append(out, items[:at]...)
+	out = append(out, item)
+	return append(out, items[at:]...)
+}
@@ -0,0 +1,209 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/user/google-classroom/internal/outbox"
+)
+
+// deletionLog records the commits and restores of queued deletions.
+type deletionLog struct {
+	committed []string
+	restored  []string
+	fail      map[string]error
+}
+
+// queue queues the deletion of key on q, recording it in l.
+func (l *deletionLog) queue(q *deletionQueue, key string) {
+	q.Queue(key, key, outbox.Entry{},
+		func(context.Context) error {
+			l.committed = append(l.committed, key)
+			return l.fail[key]
+		},
+		func() { l.restored = append(l.restored, key) })
+}
+
+// expire closes the undo window of the deletion with id and delivers the
+// result of sending it.
+func expire(q *deletionQueue, id int) []any {
+	_, cmd := q.Update(deletionExpiredMsg{id: id})
+	var msgs []any
+	for _, msg := range results(cmd) {
+		_, reply := q.Update(msg)
+		for _, r := range results(reply) {
+			msgs = append(msgs, r)
+		}
+	}
+	return msgs
+}
+
+// TestDeletionQueue tests undo, expiry and failure in the order they
+// happen.
+func TestDeletionQueue(t *testing.T) {
+	tests := []struct {
+		name          string
+		queued        []string
+		fail          map[string]error
+		undo          int
+		expire        []int
+		wantPending   []string
+		wantCommitted []string
+		wantRestored  []string
+		wantToasts    int
+	}{
+		{
+			name:         "undo is last in first out",
+			queued:       []string{"a", "b", "c"},
+			undo:         2,
+			wantPending:  []string{"a"},
+			wantRestored: []string{"c", "b"},
+		},
+		{
+			name:         "undo with nothing pending",
+			queued:       []string{"a"},
+			undo:         3,
+			wantRestored: []string{"a"},
+		},
+		{
+			name:          "expiry sends only that deletion",
+			queued:        []string{"a", "b"},
+			expire:        []int{1},
+			wantPending:   []string{"b"},
+			wantCommitted: []string{"a"},
+		},
+		{
+			name:         "expiry after undo sends nothing",
+			queued:       []string{"a", "b"},
+			undo:         1,
+			expire:       []int{2},
+			wantPending:  []string{"a"},
+			wantRestored: []string{"b"},
+		},
+		{
+			name:          "failure restores and toasts",
+			queued:        []string{"a", "b"},
+			fail:          map[string]error{"b": errors.New("backend error")},
+			expire:        []int{2, 1},
+			wantCommitted: []string{"b", "a"},
+			wantRestored:  []string{"b"},
+			wantToasts:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q deletionQueue
+			log := &deletionLog{fail: tt.fail}
+			for _, key := range tt.queued {
+				log.queue(&q, key)
+			}
+			for range tt.undo {
+				q.Undo()
+			}
+			toasts := 0
+			for _, id := range tt.expire {
+				for _, msg := range expire(&q, id) {
+					if toast, ok := msg.(toastMsg); ok && toast.kind == toastError {
+						toasts++
+					}
+				}
+			}
+
+			var pending []string
+			for _, key := range []string{"a", "b", "c"} {
+				if q.Pending(key) {
+					pending = append(pending, key)
+				}
+			}
+			if !slices.Equal(pending, tt.wantPending) {
+				t.Errorf("Expected pending %v, got %v", tt.wantPending, pending)
+			}
+			if !slices.Equal(log.committed, tt.wantCommitted) {
+				t.Errorf("Expected committed %v, got %v", tt.wantCommitted, log.committed)
+			}
+			if !slices.Equal(log.restored, tt.wantRestored) {
+				t.Errorf("Expected restored %v, got %v", tt.wantRestored, log.restored)
+			}
+			if toasts != tt.wantToasts {
+				t.Errorf("Expected %d error toasts, got %d", tt.wantToasts, toasts)
+			}
+		})
+	}
+}
+
+// TestDeletionQueueView tests that a pending deletion's prompt is shown
+// over an earlier failure, which shows once nothing is pending.
+func TestDeletionQueueView(t *testing.T) {
+	var q deletionQueue
+	log := &deletionLog{fail: map[string]error{"a": errors.New("backend error")}}
+	log.queue(&q, "a")
+	expire(&q, 1)
+	if view := q.View(); !strings.Contains(view, "Could not delete a") {
+		t.Fatalf("Expected the failure, got %q", view)
+	}
+
+	log.queue(&q, "b")
+	if view := q.View(); !strings.Contains(view, "Deleted b") {
+		t.Errorf("Expected the undo prompt, got %q", view)
+	}
+	q.Undo()
+	if view := q.View(); view != "" {
+		t.Errorf("Expected a new deletion to clear the failure, got %q", view)
+	}
+}
+
+// TestWithoutPending tests that pending items are hidden from reloads.
+func TestWithoutPending(t *testing.T) {
+	var q deletionQueue
+	log := &deletionLog{}
+	log.queue(&q, "b")
+
+	got := withoutPending(&q, []string{"a", "b", "c"}, func(s string) string { return s })
+	if want := []string{"a", "c"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestRemoveAt tests that removing leaves the original slice intact.
+func TestRemoveAt(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	got := removeAt(items, 1)
+	if want := []string{"a", "c"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(items, want) {
+		t.Errorf("Expected the original to be kept, got %v", items)
+	}
+}
+
+// TestRestoreItem tests that an item goes back before the one that
+// followed it, wherever that is now.
+func TestRestoreItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		item  string
+		next  string
+		want  []string
+	}{
+		{"before its successor", []string{"a", "c"}, "b", "c", []string{"a", "b", "c"}},
+		{"successor moved", []string{"c", "a"}, "b", "c", []string{"b", "c", "a"}},
+		{"successor gone", []string{"a", "d"}, "b", "c", []string{"a", "d", "b"}},
+		{"was last", []string{"a", "c"}, "d", "", []string{"a", "c", "d"}},
+		{"into an empty list", nil, "a", "", []string{"a"}},
+		{"already reloaded", []string{"a", "b", "c"}, "b", "c", []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := restoreItem(tt.items, tt.item, tt.next, func(s string) string { return s })
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package tea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/api/drive"
+	"github.com/user/google-classroom/internal/auth"
+	"github.com/user/google-classroom/internal/humanize"
+)
+
+// uploadEvent is progress or the final result of an upload, sent from the
+// upload goroutine.
+type uploadEvent struct {
+	progress *drive.Progress
+	count    int
+	err      error
+	// pending starts the upload once its scope is granted.
+	pending func() tea.Cmd
+}
+
+// uploadProgressMsg reports progress and carries the channel to keep
+// listening on.
+type uploadProgressMsg struct {
+	events   <-chan uploadEvent
+	progress drive.Progress
+}
+
+// uploadDoneMsg is sent when every file is attached or one failed.
+type uploadDoneMsg struct {
+	count int
+	err   error
+}
+
+// upload tracks attaching local files to the user's submission: 'a' asks
+// for their paths, which are uploaded to Drive and attached together.
+type upload struct {
+	input    textinput.Model
+	editing  bool
+	active   bool
+	progress drive.Progress
+	count    int
+	err      error
+	// pending starts the upload once its scope is granted.
+	pending func() tea.Cmd
+}
+
+// begin asks for the files to attach. It returns false when Drive is not
+// configured or an upload is already running.
+func (u *upload) begin() bool {
+	if options.Drive == nil || u.active {
+		return false
+	}
+	*u = upload{input: textinput.New(), editing: true}
+	u.input.Placeholder = "~/report.pdf, ~/photo.jpg"
+	u.input.Prompt = "Attach: "
+	u.input.Width = 50
+	u.input.Focus()
+	return true
+}
+
+// handleKey edits the paths while asking for them: enter uploads the
+// files to sub, and esc cancels.
+func (u *upload) handleKey(msg tea.KeyMsg, client api.ClassroomClient, sub *api.StudentSubmission) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		u.editing = false
+		return nil
+	case "enter":
+		paths := uploadPaths(u.input.Value())
+		if len(paths) == 0 {
+			return nil
+		}
+		u.editing = false
+		return u.start(client, sub, paths)
+	}
+	var cmd tea.Cmd
+	u.input, cmd = u.input.Update(msg)
+	return cmd
+}
+
+// start uploads paths and attaches them to sub.
+func (u *upload) start(client api.ClassroomClient, sub *api.StudentSubmission, paths []string) tea.Cmd {
+	if isOffline() {
+		u.err = errors.New("files cannot be attached while offline")
+		return nil
+	}
+	u.active = true
+	send := func() tea.Cmd { return sendFiles(client, sub, paths) }
+	if !needsScopes(auth.ScopeDriveFile) {
+		return send()
+	}
+	// The first upload asks for the Drive file scope and starts once it
+	// is granted
+	u.pending = send
+	return requestScopes(auth.ScopeDriveFile)
+}
+
+// sendFiles uploads paths to Drive and attaches them to sub, reporting
+// progress.
+func sendFiles(client api.ClassroomClient, sub *api.StudentSubmission, paths []string) tea.Cmd {
+	// As with downloads, progress is sent without blocking; only the
+	// latest update matters.
+	events := make(chan uploadEvent, 1)
+	drv := options.Drive
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+		defer cancel()
+		_, err := drv.UploadToSubmission(ctx, client, sub.CourseID, sub.CourseWorkID, sub.ID, paths, func(p drive.Progress) {
+			select {
+			case events <- uploadEvent{progress: &p}:
+			default:
+			}
+		})
+		select {
+		case <-events:
+		default:
+		}
+		events <- uploadEvent{count: len(paths), err: err}
+	}()
+	return waitUpload(events)
+}
+
+// uploadPaths splits comma-separated paths, expanding a leading ~.
+func uploadPaths(value string) []string {
+	var paths []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				p = filepath.Join(home, rest)
+			}
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// waitUpload waits for the next upload event.
+func waitUpload(events <-chan uploadEvent) tea.Cmd {
+	return func() tea.Msg {
+		e := <-events
+		if e.progress != nil {
+			return uploadProgressMsg{events: events, progress: *e.progress}
+		}
+		return uploadDoneMsg{count: e.count, err: e.err}
+	}
+}
+
+// update applies an upload message, returning the command that keeps
+// listening for progress.
+func (u *upload) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case scopeGrantedMsg:
+		if u.pending == nil || !slices.Contains(msg.scopes, auth.ScopeDriveFile) {
+			return nil
+		}
+		next := u.pending
+		u.pending = nil
+		if msg.err != nil {
+			u.active = false
+			u.err = msg.err
+			return nil
+		}
+		return next()
+	case uploadProgressMsg:
+		u.progress = msg.progress
+		return waitUpload(msg.events)
+	case uploadDoneMsg:
+		u.active = false
+		u.count, u.err = msg.count, msg.err
+	}
+	return nil
+}
+
+// render returns the path prompt, or a status line for the current or
+// last upload.
+func (u *upload) render() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#8be9fd"))
+	switch {
+	case u.editing:
+		return u.input.View() + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272a4")).
+			Render("  enter upload | esc cancel")
+	case u.active:
+		p := u.progress
+		if p.Name == "" {
+			return style.Render("Uploading...")
+		}
+		status := fmt.Sprintf("Uploading %s (%d/%d) %s", p.Name, p.Index, p.Count, humanize.Bytes(p.Done))
+		if p.Total > 0 {
+			status += fmt.Sprintf(" of %s (%d%%)", humanize.Bytes(p.Total), p.Done*100/p.Total)
+		}
+		return style.Render(status)
+	case u.err != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render("Upload failed: " + errorText(u.err))
+	case u.count > 0:
+		return style.Render(fmt.Sprintf("Attached %d file(s)", u.count))
+	}
+	return ""
+}
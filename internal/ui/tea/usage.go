@@ -0,0 +1,15 @@
+package tea
+
+import "github.com/charmbracelet/lipgloss"
+
+// budgetBadge renders a warning once most of the daily API budget is used,
+// or "" otherwise.
+func budgetBadge() string {
+	if !options.Usage.Conserving() {
+		return ""
+	}
+	text := "▲ " + options.Usage.Status().String() + " - using cached data longer"
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ffb86c")).
+		Render(text)
+}
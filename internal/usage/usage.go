@@ -0,0 +1,242 @@
+// Package usage counts API calls against a soft daily budget. When most of
+// the budget is spent it warns once and asks the rest of the app to
+// conserve: cache entries live longer and background prefetch pauses.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+	"github.com/user/google-classroom/internal/schema"
+	"github.com/user/google-classroom/internal/secure"
+)
+
+// Configuration holds budget settings.
+type Configuration struct {
+	// DailyBudget is the number of API calls allowed per local day. Zero
+	// disables the budget; calls are still counted.
+	DailyBudget int
+	// WarnAt is the fraction of the budget at which the meter warns and
+	// starts conserving.
+	WarnAt float64
+	// TTLStretch multiplies cache lifetimes while conserving.
+	TTLStretch float64
+	// Path stores the day's count so it survives restarts. Empty keeps it
+	// in memory only.
+	Path string
+	// Log receives the warning. Nil discards it.
+	Log io.Writer
+	// OnWarn is called once per day when usage reaches WarnAt.
+	OnWarn func(Status)
+}
+
+// DefaultConfiguration returns the default budget configuration.
+func DefaultConfiguration() *Configuration {
+	return &Configuration{
+		WarnAt:     0.8,
+		TTLStretch: 4,
+	}
+}
+
+// DefaultPath returns the default location of the usage file.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "google-classroom", "usage.json"), nil
+}
+
+// fileSchema versions the usage file.
+var fileSchema = schema.Schema{Name: "usage", Version: 1}
+
+// file is the on-disk layout.
+type file struct {
+	Version int `json:"version"`
+	Status
+}
+
+// Status is the day's usage.
+type Status struct {
+	Day    string `json:"day"`
+	Calls  int    `json:"calls"`
+	Budget int    `json:"-"`
+	// Warned records that the day's warning was given.
+	Warned bool `json:"warned"`
+}
+
+// Fraction returns the share of the budget used, or 0 without a budget.
+func (s Status) Fraction() float64 {
+	if s.Budget <= 0 {
+		return 0
+	}
+	return float64(s.Calls) / float64(s.Budget)
+}
+
+// String describes the usage, e.g. "812/1000 API calls today (81%)".
+func (s Status) String() string {
+	if s.Budget <= 0 {
+		return fmt.Sprintf("%d API calls today", s.Calls)
+	}
+	return fmt.Sprintf("%d/%d API calls today (%.0f%%)", s.Calls, s.Budget, s.Fraction()*100)
+}
+
+// Meter counts API calls for the current day. It is safe for concurrent
+// use.
+type Meter struct {
+	cfg Configuration
+	now func() time.Time
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewMeter creates a meter, restoring today's count from cfg.Path.
+func NewMeter(cfg *Configuration) (*Meter, error) {
+	if cfg == nil {
+		cfg = DefaultConfiguration()
+	}
+	c := *cfg
+	defaults := DefaultConfiguration()
+	if c.WarnAt <= 0 || c.WarnAt > 1 {
+		c.WarnAt = defaults.WarnAt
+	}
+	if c.TTLStretch < 1 {
+		c.TTLStretch = defaults.TTLStretch
+	}
+
+	m := &Meter{cfg: c, now: time.Now}
+	m.status.Day = m.today()
+	if c.Path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+	// A count from a newer release is only a count; start afresh.
+	var saved file
+	if _, err := fileSchema.Decode(data, &saved); err != nil {
+		if errors.Is(err, schema.ErrTooNew) {
+			return m, nil
+		}
+		return nil, err
+	}
+	if saved.Day == m.status.Day {
+		m.status.Calls = saved.Calls
+		m.status.Warned = saved.Warned
+	}
+	return m, nil
+}
+
+// Record counts one API call, warning when the call reaches the
+// threshold.
+func (m *Meter) Record() {
+	m.mu.Lock()
+	m.rollover()
+	m.status.Calls++
+	st := m.snapshot()
+	warn := !m.status.Warned && m.cfg.DailyBudget > 0 && st.Fraction() >= m.cfg.WarnAt
+	if warn {
+		m.status.Warned = true
+	}
+	m.save()
+	m.mu.Unlock()
+
+	if warn {
+		if m.cfg.Log != nil {
+			fmt.Fprintf(m.cfg.Log, "%s usage: %s; extending cache lifetimes and pausing prefetch\n",
+				m.now().Format("2006-01-02T15:04:05.000"), st)
+		}
+		if m.cfg.OnWarn != nil {
+			m.cfg.OnWarn(st)
+		}
+	}
+}
+
+// Status returns today's usage.
+func (m *Meter) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollover()
+	return m.snapshot()
+}
+
+// Conserving reports whether usage has reached the warning threshold, so
+// background work such as prefetch should wait until tomorrow. A nil
+// meter never conserves.
+func (m *Meter) Conserving() bool {
+	if m == nil || m.cfg.DailyBudget <= 0 {
+		return false
+	}
+	return m.Status().Fraction() >= m.cfg.WarnAt
+}
+
+// TTLScale returns the factor to apply to cache lifetimes: TTLStretch
+// while conserving and 1 otherwise.
+func (m *Meter) TTLScale() float64 {
+	if m.Conserving() {
+		return m.cfg.TTLStretch
+	}
+	return 1
+}
+
+// Middleware returns middleware that records every request sent. Install
+// it inside any caching middleware so only requests that reach the
+// network are counted.
+func (m *Meter) Middleware() api.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return api.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			m.Record()
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func (m *Meter) today() string {
+	return m.now().Format("2006-01-02")
+}
+
+// rollover starts a new count when the day changes. Callers hold m.mu.
+func (m *Meter) rollover() {
+	if day := m.today(); day != m.status.Day {
+		m.status = Status{Day: day}
+	}
+}
+
+func (m *Meter) snapshot() Status {
+	st := m.status
+	st.Budget = m.cfg.DailyBudget
+	return st
+}
+
+// save writes the count to disk. A failed write only loses the count
+// across a restart, so it is ignored. Callers hold m.mu.
+func (m *Meter) save() {
+	if m.cfg.Path == "" {
+		return
+	}
+	data, err := json.Marshal(file{Version: fileSchema.Version, Status: m.status})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.cfg.Path), 0700); err != nil {
+		return
+	}
+	secure.WriteFile(m.cfg.Path, data)
+}
@@ -0,0 +1,130 @@
+package usage
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/google-classroom/internal/api"
+)
+
+// TestMeterWarns tests that the meter warns once at the threshold and
+// starts conserving.
+func TestMeterWarns(t *testing.T) {
+	var log bytes.Buffer
+	warnings := 0
+	m, err := NewMeter(&Configuration{
+		DailyBudget: 10,
+		Log:         &log,
+		OnWarn:      func(Status) { warnings++ },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create meter: %v", err)
+	}
+
+	for i := 0; i < 7; i++ {
+		m.Record()
+	}
+	if m.Conserving() || m.TTLScale() != 1 {
+		t.Error("Expected no conserving below 80%")
+	}
+
+	for i := 0; i < 3; i++ {
+		m.Record()
+	}
+	if warnings != 1 {
+		t.Errorf("Expected 1 warning, got %d", warnings)
+	}
+	if !m.Conserving() {
+		t.Error("Expected conserving at 80%")
+	}
+	if m.TTLScale() != 4 {
+		t.Errorf("Expected TTL scale 4, got %v", m.TTLScale())
+	}
+	if !strings.Contains(log.String(), "8/10 API calls today (80%)") {
+		t.Errorf("Expected warning in log, got %q", log.String())
+	}
+}
+
+// TestMeterPersists tests that the count survives a restart on the same
+// day and resets on a new one.
+func TestMeterPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	cfg := &Configuration{DailyBudget: 100, Path: path}
+
+	m, err := NewMeter(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create meter: %v", err)
+	}
+	m.Record()
+	m.Record()
+
+	m, err = NewMeter(cfg)
+	if err != nil {
+		t.Fatalf("Failed to reopen meter: %v", err)
+	}
+	if calls := m.Status().Calls; calls != 2 {
+		t.Errorf("Expected 2 calls after restart, got %d", calls)
+	}
+
+	m.now = func() time.Time { return time.Now().AddDate(0, 0, 1) }
+	if calls := m.Status().Calls; calls != 0 {
+		t.Errorf("Expected count to reset on a new day, got %d", calls)
+	}
+}
+
+// TestMeterVersions tests that an unversioned count is restored and one
+// from a newer release is dropped.
+func TestMeterVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	day := time.Now().Format("2006-01-02")
+
+	os.WriteFile(path, []byte(`{"day": "`+day+`", "calls": 5}`), 0600)
+	m, err := NewMeter(&Configuration{Path: path})
+	if err != nil {
+		t.Fatalf("Failed to create meter: %v", err)
+	}
+	if calls := m.Status().Calls; calls != 5 {
+		t.Errorf("Expected 5 calls from an unversioned file, got %d", calls)
+	}
+
+	os.WriteFile(path, []byte(`{"version": 99, "day": "`+day+`", "calls": 5}`), 0600)
+	m, err = NewMeter(&Configuration{Path: path})
+	if err != nil {
+		t.Fatalf("Failed to create meter: %v", err)
+	}
+	if calls := m.Status().Calls; calls != 0 {
+		t.Errorf("Expected a fresh count, got %d", calls)
+	}
+}
+
+// TestMiddleware tests that the middleware counts requests.
+func TestMiddleware(t *testing.T) {
+	m, err := NewMeter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create meter: %v", err)
+	}
+	rt := api.Chain(api.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}), m.Middleware())
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if calls := m.Status().Calls; calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if m.Conserving() {
+		t.Error("Expected no conserving without a budget")
+	}
+}
@@ -0,0 +1,193 @@
+// Package classroom is the public Go client for Google Classroom used by the
+// google-classroom TUI. It handles pagination, partial responses, client-side
+// rate limiting, and retries with backoff, and reports failures as typed
+// errors that can be checked with IsNotFound, IsRateLimited, and the other
+// Is helpers.
+//
+// A client is built from any oauth2.TokenSource:
+//
+//	client, err := classroom.NewClient(ctx, tokenSource, nil)
+//	if err != nil {
+//		return err
+//	}
+//	for course, err := range classroom.AllCourses(ctx, client, nil) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(course.Name)
+//	}
+//
+// The types in this package are the ones the TUI uses internally, so they
+// stay in step with the application.
+package classroom
+
+import (
+	"context"
+	"io"
+
+	"github.com/user/google-classroom/internal/api"
+	"golang.org/x/oauth2"
+)
+
+// Client is a Google Classroom API client. It is safe for concurrent use.
+type Client = api.Client
+
+// ClassroomClient is the set of operations implemented by *Client, so code
+// can accept a fake in tests.
+type ClassroomClient = api.ClassroomClient
+
+// Configuration holds client settings. A nil *Configuration means defaults.
+type Configuration = api.Configuration
+
+// Resource types.
+type (
+	Course            = api.Course
+	CoursePatch       = api.CoursePatch
+	CourseWork        = api.CourseWork
+	StudentSubmission = api.StudentSubmission
+	Announcement      = api.Announcement
+	Student           = api.Student
+	Teacher           = api.Teacher
+	UserProfile       = api.UserProfile
+	Invitation        = api.Invitation
+	Attachment        = api.Attachment
+	AddOnAttachment   = api.AddOnAttachment
+	DriveFolder       = api.DriveFolder
+	Topic             = api.Topic
+	HistoryEvent      = api.HistoryEvent
+	Rubric            = api.Rubric
+	Criterion         = api.Criterion
+	Level             = api.Level
+	RubricGrade       = api.RubricGrade
+)
+
+// List options. A nil options pointer means no filtering.
+type (
+	ListCoursesOptions            = api.ListCoursesOptions
+	ListCourseWorkOptions         = api.ListCourseWorkOptions
+	ListStudentSubmissionsOptions = api.ListStudentSubmissionsOptions
+	ListAnnouncementsOptions      = api.ListAnnouncementsOptions
+	ListRosterOptions             = api.ListRosterOptions
+)
+
+// Course states.
+const (
+	CourseStateActive      = api.CourseStateActive
+	CourseStateArchived    = api.CourseStateArchived
+	CourseStateProvisioned = api.CourseStateProvisioned
+	CourseStateDeclined    = api.CourseStateDeclined
+)
+
+// Attachment kinds, as in Attachment.Kind.
+const (
+	AttachmentDriveFile = api.AttachmentDriveFile
+	AttachmentLink      = api.AttachmentLink
+	AttachmentYouTube   = api.AttachmentYouTube
+	AttachmentForm      = api.AttachmentForm
+	AttachmentFolder    = api.AttachmentFolder
+)
+
+// Assignee modes, saying which students can see a post.
+const (
+	AssigneeModeAll        = api.AssigneeModeAll
+	AssigneeModeIndividual = api.AssigneeModeIndividual
+)
+
+// CourseWork types.
+const (
+	WorkTypeAssignment     = api.WorkTypeAssignment
+	WorkTypeShortAnswer    = api.WorkTypeShortAnswer
+	WorkTypeMultipleChoice = api.WorkTypeMultipleChoice
+	WorkTypeMaterial       = api.WorkTypeMaterial
+)
+
+// CourseWork states.
+const (
+	CourseWorkStatePublished = api.CourseWorkStatePublished
+	CourseWorkStateDraft     = api.CourseWorkStateDraft
+	CourseWorkStateDeleted   = api.CourseWorkStateDeleted
+)
+
+// Announcement states.
+const (
+	AnnouncementStatePublished = api.AnnouncementStatePublished
+	AnnouncementStateDraft     = api.AnnouncementStateDraft
+	AnnouncementStateDeleted   = api.AnnouncementStateDeleted
+)
+
+// CourseWork list orderings.
+const (
+	CourseWorkOrderDueDateAsc     = api.CourseWorkOrderDueDateAsc
+	CourseWorkOrderDueDateDesc    = api.CourseWorkOrderDueDateDesc
+	CourseWorkOrderUpdateTimeDesc = api.CourseWorkOrderUpdateTimeDesc
+	CourseWorkOrderUpdateTimeAsc  = api.CourseWorkOrderUpdateTimeAsc
+)
+
+// Submission states.
+const (
+	SubmissionStateNew       = api.SubmissionStateNew
+	SubmissionStateCreated   = api.SubmissionStateCreated
+	SubmissionStateTurnedIn  = api.SubmissionStateTurnedIn
+	SubmissionStateReturned  = api.SubmissionStateReturned
+	SubmissionStateReclaimed = api.SubmissionStateReclaimed
+	SubmissionStateEdited    = api.SubmissionStateEdited
+)
+
+// Grade changes in a submission's history.
+const (
+	GradeChangeDraft     = api.GradeChangeDraft
+	GradeChangeAssigned  = api.GradeChangeAssigned
+	GradeChangeMaxPoints = api.GradeChangeMaxPoints
+)
+
+// Lists whose page size can be set in Configuration.PageSizes.
+const (
+	PageCourses          = api.PageCourses
+	PageCourseWork       = api.PageCourseWork
+	PageSubmissions      = api.PageSubmissions
+	PageAnnouncements    = api.PageAnnouncements
+	PageStudents         = api.PageStudents
+	PageTeachers         = api.PageTeachers
+	PageInvitations      = api.PageInvitations
+	PageAddOnAttachments = api.PageAddOnAttachments
+)
+
+// Stats is a snapshot of a client's request metrics, from Client.Stats.
+type Stats = api.Stats
+
+// EndpointStats counts the requests sent to one API method.
+type EndpointStats = api.EndpointStats
+
+// FieldsAll requests every field in list calls instead of the default
+// partial response.
+const FieldsAll = api.FieldsAll
+
+// Middleware wraps the client's HTTP transport. Set it in
+// Configuration.Middleware to add logging, metrics, tracing, or headers.
+type Middleware = api.Middleware
+
+// RoundTripperFunc adapts a function to http.RoundTripper for use in
+// middleware.
+type RoundTripperFunc = api.RoundTripperFunc
+
+// DebugLogging returns middleware that logs one line per request to w.
+func DebugLogging(w io.Writer) Middleware {
+	return api.DebugLogging(w)
+}
+
+// ETagCache returns middleware that revalidates repeated GETs with
+// If-None-Match and serves the stored body on 304. Clients from NewClient
+// already use it unless Configuration.DisableETags is set.
+func ETagCache(maxEntries int) Middleware {
+	return api.ETagCache(maxEntries)
+}
+
+// NewClient creates a client that authenticates with ts. cfg may be nil.
+func NewClient(ctx context.Context, ts oauth2.TokenSource, cfg *Configuration) (*Client, error) {
+	return api.NewClient(ctx, ts, cfg)
+}
+
+// DefaultConfiguration returns the default client configuration.
+func DefaultConfiguration() *Configuration {
+	return api.DefaultConfiguration()
+}
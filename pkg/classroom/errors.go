@@ -0,0 +1,48 @@
+package classroom
+
+import (
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// IsNotFound reports whether err means the requested resource does not exist
+// or is not visible to the user.
+func IsNotFound(err error) bool {
+	return apperrors.IsNotFoundError(err)
+}
+
+// IsRateLimited reports whether err means the request was throttled after
+// the client's retries ran out.
+func IsRateLimited(err error) bool {
+	return apperrors.IsRateLimitError(err)
+}
+
+// IsAuthError reports whether err means the credentials expired or were
+// revoked and the user has to sign in again.
+func IsAuthError(err error) bool {
+	return apperrors.IsAuthError(err)
+}
+
+// IsForbidden reports whether err means the user lacks permission or the
+// token is missing a scope.
+func IsForbidden(err error) bool {
+	e, ok := apperrors.As(err)
+	return ok && e.Type == apperrors.ErrAPIForbidden
+}
+
+// IsTemporary reports whether retrying err later may succeed.
+func IsTemporary(err error) bool {
+	return err != nil && apperrors.IsRecoverable(err)
+}
+
+// UserMessage returns a short explanation of err suitable for end users,
+// followed by a suggestion when one is known.
+func UserMessage(err error) string {
+	e, ok := apperrors.As(err)
+	if !ok {
+		return err.Error()
+	}
+	if e.UserSuggestion != "" {
+		return e.UserMessage() + " " + e.UserSuggestion
+	}
+	return e.UserMessage()
+}
@@ -0,0 +1,35 @@
+package classroom
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	apperrors "github.com/user/google-classroom/internal/errors"
+)
+
+// TestErrorHelpers tests classifying client errors through wrapping.
+func TestErrorHelpers(t *testing.T) {
+	notFound := fmt.Errorf("loading: %w", apperrors.New(apperrors.ErrAPINotFound, "failed to get course").NotRecoverable())
+	if !IsNotFound(notFound) || IsTemporary(notFound) {
+		t.Error("Expected a permanent not found error")
+	}
+
+	limited := apperrors.New(apperrors.ErrAPIRateLimit, "failed to list courses")
+	if !IsRateLimited(limited) || !IsTemporary(limited) {
+		t.Error("Expected a temporary rate limit error")
+	}
+
+	forbidden := apperrors.New(apperrors.ErrAPIForbidden, "failed").WithSuggestion("Ask your admin.")
+	if !IsForbidden(forbidden) {
+		t.Error("Expected forbidden error")
+	}
+	if got := UserMessage(forbidden); got != "You don't have permission to access this resource. Ask your admin." {
+		t.Errorf("Unexpected user message %q", got)
+	}
+
+	plain := errors.New("boom")
+	if IsNotFound(plain) || IsAuthError(plain) || UserMessage(plain) != "boom" {
+		t.Error("Expected plain errors to match nothing")
+	}
+}
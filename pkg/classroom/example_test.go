@@ -0,0 +1,83 @@
+package classroom_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/user/google-classroom/pkg/classroom"
+	"golang.org/x/oauth2"
+)
+
+// tokenSource stands in for a real OAuth token source.
+var tokenSource oauth2.TokenSource
+
+func ExampleAllCourses() {
+	ctx := context.Background()
+	client, err := classroom.NewClient(ctx, tokenSource, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &classroom.ListCoursesOptions{
+		CourseStates: []string{classroom.CourseStateActive},
+		TeacherID:    "me",
+	}
+	for course, err := range classroom.AllCourses(ctx, client, opts) {
+		if err != nil {
+			log.Fatal(classroom.UserMessage(err))
+		}
+		fmt.Println(course.Name)
+	}
+}
+
+func ExampleAllCourseWork() {
+	ctx := context.Background()
+	client, err := classroom.NewClient(ctx, tokenSource, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &classroom.ListCourseWorkOptions{OrderBy: classroom.CourseWorkOrderDueDateAsc}
+	for cw, err := range classroom.AllCourseWork(ctx, client, "123456", opts) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cw.DueDate != "" {
+			fmt.Printf("%s due %s\n", cw.Title, cw.DueDate)
+			break
+		}
+	}
+}
+
+func ExampleIsNotFound() {
+	ctx := context.Background()
+	client, err := classroom.NewClient(ctx, tokenSource, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	course, err := client.GetCourse(ctx, "123456")
+	switch {
+	case classroom.IsNotFound(err):
+		fmt.Println("no such course")
+	case err != nil:
+		log.Fatal(err)
+	default:
+		fmt.Println(course.Name)
+	}
+}
+
+func ExampleNewClient() {
+	ctx := context.Background()
+
+	cfg := classroom.DefaultConfiguration()
+	cfg.QPS = 5
+	cfg.MaxRetries = 5
+
+	client, err := classroom.NewClient(ctx, tokenSource, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_ = client
+}
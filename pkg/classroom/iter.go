@@ -0,0 +1,60 @@
+package classroom
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// errStop ends paging early when the caller breaks out of a loop.
+var errStop = errors.New("stop iteration")
+
+// AllCourses iterates over the courses visible to the user, fetching pages as
+// the loop advances. A failed request yields the error once and ends the
+// iteration. opts may be nil.
+func AllCourses(ctx context.Context, c *Client, opts *ListCoursesOptions) iter.Seq2[*Course, error] {
+	return paginate(func(fn func([]*Course) error) error {
+		return c.CoursePages(ctx, opts, fn)
+	})
+}
+
+// AllCourseWork iterates over a course's coursework. opts may be nil.
+func AllCourseWork(ctx context.Context, c *Client, courseID string, opts *ListCourseWorkOptions) iter.Seq2[*CourseWork, error] {
+	return paginate(func(fn func([]*CourseWork) error) error {
+		return c.CourseWorkPages(ctx, courseID, opts, fn)
+	})
+}
+
+// AllStudentSubmissions iterates over the submissions for coursework. opts
+// may be nil.
+func AllStudentSubmissions(ctx context.Context, c *Client, courseID, courseWorkID string, opts *ListStudentSubmissionsOptions) iter.Seq2[*StudentSubmission, error] {
+	return paginate(func(fn func([]*StudentSubmission) error) error {
+		return c.StudentSubmissionPages(ctx, courseID, courseWorkID, opts, fn)
+	})
+}
+
+// AllAnnouncements iterates over a course's announcements. opts may be nil.
+func AllAnnouncements(ctx context.Context, c *Client, courseID string, opts *ListAnnouncementsOptions) iter.Seq2[*Announcement, error] {
+	return paginate(func(fn func([]*Announcement) error) error {
+		return c.AnnouncementPages(ctx, courseID, opts, fn)
+	})
+}
+
+// paginate turns a page walker into an iterator. Breaking out of the loop
+// stops the walk before the next page is requested.
+func paginate[T any](walk func(fn func([]T) error) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := walk(func(page []T) error {
+			for _, item := range page {
+				if !yield(item, nil) {
+					return errStop
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStop) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package classroom
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestPaginate tests iterating across pages and stopping early.
+func TestPaginate(t *testing.T) {
+	pages := [][]int{{1, 2}, {3}, {4, 5}}
+	fetched := 0
+	walk := func(fn func([]int) error) error {
+		for _, page := range pages {
+			fetched++
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var got []int
+	for n, err := range paginate(walk) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, n)
+	}
+	if len(got) != 5 || got[4] != 5 {
+		t.Errorf("Expected 5 items in order, got %v", got)
+	}
+
+	fetched = 0
+	for n := range paginate(walk) {
+		if n == 2 {
+			break
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("Expected breaking out to stop after 1 page, fetched %d", fetched)
+	}
+}
+
+// TestPaginateError tests that a failed page is yielded once.
+func TestPaginateError(t *testing.T) {
+	boom := errors.New("boom")
+	walk := func(fn func([]string) error) error {
+		if err := fn([]string{"a"}); err != nil {
+			return err
+		}
+		return boom
+	}
+
+	var items []string
+	var errs []error
+	for s, err := range paginate(walk) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		items = append(items, s)
+	}
+	if len(items) != 1 || len(errs) != 1 || !errors.Is(errs[0], boom) {
+		t.Errorf("Expected one item then one error, got %v and %v", items, errs)
+	}
+}
@@ -0,0 +1,115 @@
+package classroom
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// surface lists the exported API. Removing or renaming any of it fails the
+// build here before it breaks a caller.
+var surface = []any{
+	// Client and configuration
+	(*Client)(nil), (ClassroomClient)(nil), (*Configuration)(nil), NewClient,
+	(*Stats)(nil), (*EndpointStats)(nil), FieldsAll,
+	(Middleware)(nil), (RoundTripperFunc)(nil), DebugLogging, ETagCache,
+
+	// Resources
+	(*Course)(nil), (*CoursePatch)(nil), (*CourseWork)(nil), (*StudentSubmission)(nil),
+	(*Announcement)(nil), (*Student)(nil), (*Teacher)(nil), (*UserProfile)(nil),
+	(*Invitation)(nil), (*Attachment)(nil), (*AddOnAttachment)(nil), (*DriveFolder)(nil),
+	(*Topic)(nil), (*HistoryEvent)(nil), (*Rubric)(nil), (*Criterion)(nil), (*Level)(nil),
+	(*RubricGrade)(nil),
+
+	// List options and iterators
+	(*ListCoursesOptions)(nil), (*ListCourseWorkOptions)(nil), (*ListStudentSubmissionsOptions)(nil),
+	(*ListAnnouncementsOptions)(nil), (*ListRosterOptions)(nil),
+	AllCourses, AllCourseWork, AllStudentSubmissions, AllAnnouncements,
+
+	// Constants
+	CourseStateActive, CourseStateArchived, CourseStateProvisioned, CourseStateDeclined,
+	AttachmentDriveFile, AttachmentLink, AttachmentYouTube, AttachmentForm, AttachmentFolder,
+	AssigneeModeAll, AssigneeModeIndividual,
+	WorkTypeAssignment, WorkTypeShortAnswer, WorkTypeMultipleChoice, WorkTypeMaterial,
+	CourseWorkStatePublished, CourseWorkStateDraft, CourseWorkStateDeleted,
+	AnnouncementStatePublished, AnnouncementStateDraft, AnnouncementStateDeleted,
+	CourseWorkOrderDueDateAsc, CourseWorkOrderDueDateDesc, CourseWorkOrderUpdateTimeDesc, CourseWorkOrderUpdateTimeAsc,
+	SubmissionStateNew, SubmissionStateCreated, SubmissionStateTurnedIn, SubmissionStateReturned,
+	SubmissionStateReclaimed, SubmissionStateEdited,
+	GradeChangeDraft, GradeChangeAssigned, GradeChangeMaxPoints,
+	PageCourses, PageCourseWork, PageSubmissions, PageAnnouncements, PageStudents, PageTeachers,
+	PageInvitations, PageAddOnAttachments,
+
+	// Errors
+	IsNotFound, IsRateLimited, IsAuthError, IsForbidden, IsTemporary, UserMessage,
+}
+
+// internalOnly names what internal/api exports for the application alone,
+// such as wire formats and test fixtures.
+var internalOnly = []string{
+	"Cassette", "Interaction", "Replayer", "LoadCassette", "NewReplayer", "Recorder", "NewRecorder",
+	"ListCoursesResponse", "ListCourseWorkResponse", "ListStudentSubmissionsResponse",
+	"ListAnnouncementsResponse", "ListStudentsResponse", "ListTeachersResponse",
+	"Chain", "NewTransport", "WithTransport", "DefaultConfiguration",
+	"PageLists", "PrettyPrint", "ReadRetryPolicy", "SortCourseWork", "VisibleCourseWork", "WrapError",
+}
+
+// TestSurfaceMatchesAPI tests that everything internal/api exports is
+// re-exported here or listed in internalOnly, so the two cannot drift.
+func TestSurfaceMatchesAPI(t *testing.T) {
+	public := exportedNames(t, ".")
+	for _, name := range exportedNames(t, "../../internal/api") {
+		if !slices.Contains(public, name) && !slices.Contains(internalOnly, name) {
+			t.Errorf("internal/api exports %s; add it here, or to internalOnly if callers have no use for it", name)
+		}
+	}
+}
+
+// exportedNames returns the exported top-level names declared in the
+// non-test files of dir.
+func exportedNames(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatalf("Failed to list %s: %v", dir, err)
+	}
+
+	var names []string
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil && decl.Name.IsExported() {
+					names = append(names, decl.Name.Name)
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Name.IsExported() {
+							names = append(names, spec.Name.Name)
+						}
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							if name.IsExported() {
+								names = append(names, name.Name)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}